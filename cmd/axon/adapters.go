@@ -0,0 +1,375 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/registry/builtin"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/internal/registry/external"
+	"github.com/mlOS-foundation/axon/internal/rewrite"
+)
+
+// adaptersDir returns the directory external adapter executables are discovered in
+func adaptersDir() string {
+	return filepath.Join(cfg.HomeDir, "adapters")
+}
+
+// externalAdapters discovers the external adapters once per command, so batch installs
+// don't start every adapter for every model. Adapters that fail to start are reported.
+var externalAdapters = sync.OnceValue(func() []*external.Adapter {
+	adapters, errs := external.Discover(context.Background(), adaptersDir())
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: skipping external adapter: %v\n", err)
+	}
+	return adapters
+})
+
+// registerExternalAdapters registers the discovered external adapters. By default they come before
+// the builtin adapters so they can serve namespaces the builtins would otherwise claim
+// (the Hugging Face adapter accepts any namespace).
+func registerExternalAdapters(adapterRegistry *core.AdapterRegistry) {
+	for _, adapter := range externalAdapters() {
+		adapterRegistry.Register(adapter)
+	}
+}
+
+// registerConfiguredAdapters registers the adapters listed in the adapters: config
+// section, in order. An external adapter is used when one has the listed name, otherwise
+// the builtin adapter is created from its factory. Entries that can't be used are
+// returned as errors and skipped.
+func registerConfiguredAdapters(adapterRegistry *core.AdapterRegistry, configs []config.AdapterConfig) []error {
+	builtin.RegisterDefaultFactories(adapterRegistry)
+	externals := make(map[string]*external.Adapter)
+	for _, adapter := range externalAdapters() {
+		externals[adapter.Name()] = adapter
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, ac := range configs {
+		if !ac.IsEnabled() {
+			continue
+		}
+		if seen[ac.Name] {
+			errs = append(errs, fmt.Errorf("adapter %q is listed more than once", ac.Name))
+			continue
+		}
+		seen[ac.Name] = true
+
+		if adapter, ok := externals[ac.Name]; ok {
+			adapterRegistry.Register(adapter)
+			continue
+		}
+		if !adapterRegistry.HasFactory(ac.Name) {
+			errs = append(errs, fmt.Errorf("unknown adapter %q (builtin adapters: %s)", ac.Name, strings.Join(builtin.DefaultAdapterOrder, ", ")))
+			continue
+		}
+		credentials.RegisterSecret(ac.Token)
+		adapter, err := adapterRegistry.CreateAdapter(ac.Name, builtinAdapterConfig(ac))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("adapter %q: %w", ac.Name, err))
+			continue
+		}
+		adapterRegistry.Register(adapter)
+	}
+	return errs
+}
+
+// builtinAdapterConfig returns the factory configuration of a builtin adapter. Settings
+// not given fall back to the registry: section.
+func builtinAdapterConfig(ac config.AdapterConfig) core.AdapterConfig {
+	c := core.AdapterConfig{
+		BaseURL: ac.BaseURL,
+		Token:   ac.Token,
+		Timeout: ac.Timeout,
+		Options: make(map[string]interface{}),
+	}
+	switch ac.Name {
+	case "local":
+		if c.BaseURL == "" {
+			c.BaseURL = cfg.Registry.URL
+		}
+		c.Options["mirrors"] = cfg.Registry.Mirrors
+	case "huggingface":
+		if c.Token == "" {
+			c.Token = cfg.Registry.HuggingFaceToken
+		}
+	}
+	return c
+}
+
+// adapterNamespaces is the canonical namespace of the builtin adapters that only serve
+// their own namespaces
+var adapterNamespaces = map[string]string{
+	"pytorch":        "pytorch",
+	"tensorflow-hub": "tfhub",
+	"modelscope":     "ms",
+	"url":            "url",
+}
+
+// adapterHosts is the API host of the builtin adapters, which requests_per_second limits
+var adapterHosts = map[string]string{
+	"huggingface":    "huggingface.co",
+	"pytorch":        "api.github.com",
+	"tensorflow-hub": "tfhub.dev",
+	"modelscope":     "www.modelscope.cn",
+}
+
+// hostRateLimits returns the requests per second allowed to each host: download.host_rate_limits,
+// then the requests_per_second of each adapter for its API host (its base_url's, if set)
+func hostRateLimits() map[string]float64 {
+	limits := make(map[string]float64, len(cfg.Download.HostRateLimits))
+	for host, rps := range cfg.Download.HostRateLimits {
+		limits[strings.ToLower(host)] = rps
+	}
+	for _, ac := range cfg.Adapters {
+		if ac.RequestsPerSecond <= 0 {
+			continue
+		}
+		host := adapterHosts[ac.Name]
+		if ac.Name == "local" {
+			host = ""
+			if u, err := url.Parse(cfg.Registry.URL); err == nil {
+				host = u.Hostname()
+			}
+		}
+		if ac.BaseURL != "" {
+			if u, err := url.Parse(ac.BaseURL); err == nil {
+				host = u.Hostname()
+			}
+		}
+		if _, set := limits[host]; host != "" && !set {
+			limits[host] = ac.RequestsPerSecond
+		}
+	}
+	return limits
+}
+
+// findModelAdapter returns the adapter for namespace/name: the adapter named adapterName
+// (or an alias such as "hf"), bypassing namespace routing, or without a name the first
+// adapter that can handle the model. A named adapter that doesn't claim the namespace
+// gets the model under its own namespace (damo/cv_resnet50 becomes ms/damo/cv_resnet50
+// with modelscope), so the returned namespace and name may differ from the given ones.
+func findModelAdapter(adapterName, namespace, name string) (core.RepositoryAdapter, string, string, error) {
+	adapterRegistry := core.NewAdapterRegistry()
+	registerAdapters(adapterRegistry)
+
+	if adapterName == "" {
+		if target, ok := rewrite.Find(rewriteRules(), namespace, name); ok {
+			adapter, err := mirrorAdapter(adapterRegistry, target, namespace, name)
+			if err != nil {
+				return nil, "", "", err
+			}
+			return adapter, namespace, name, nil
+		}
+		adapter, err := adapterRegistry.FindAdapter(namespace, name)
+		if err != nil {
+			return nil, "", "", fmt.Errorf("no repository adapter found for %s/%s: %w", namespace, name, err)
+		}
+		return adapter, namespace, name, nil
+	}
+
+	adapter, err := adapterRegistry.GetAdapterByName(credentials.CanonicalAdapter(adapterName))
+	if err != nil {
+		var names []string
+		for _, a := range adapterRegistry.GetAllAdapters() {
+			names = append(names, a.Name())
+		}
+		return nil, "", "", fmt.Errorf("unknown adapter %q (registered adapters: %s)", adapterName, strings.Join(names, ", "))
+	}
+	if adapter.CanHandle(namespace, name) {
+		return adapter, namespace, name, nil
+	}
+
+	adapterNamespace := adapterNamespaces[adapter.Name()]
+	if ext, ok := adapter.(*external.Adapter); ok {
+		for _, ns := range ext.Namespaces() {
+			if ns != "*" {
+				adapterNamespace = ns
+				break
+			}
+		}
+	}
+	if adapterNamespace != "" {
+		name = namespace + "/" + name
+		namespace = adapterNamespace
+	}
+	return adapter, namespace, name, nil
+}
+
+// rewriteRules returns the valid rewrites: rules, warning about the others
+func rewriteRules() []rewrite.Rule {
+	var rules []rewrite.Rule
+	for _, rc := range cfg.Rewrites {
+		rule := rc.Rule()
+		if err := rule.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: skipping %v\n", err)
+			continue
+		}
+		credentials.RegisterSecret(rule.Token)
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// mirrorAdapter returns the adapter serving namespace/name from the mirror a rewrite rule
+// sends it to. A mirror URL gets a new instance of the model's adapter pointed at it,
+// with the rule's token only; a mirror spec gets the adapter of that spec, translating
+// back to the public spec so the model is cached under it.
+func mirrorAdapter(adapterRegistry *core.AdapterRegistry, target rewrite.Target, namespace, name string) (core.RepositoryAdapter, error) {
+	if target.BaseURL == "" {
+		adapter, err := adapterRegistry.FindAdapter(target.Namespace, target.Name)
+		if err != nil {
+			return nil, fmt.Errorf("no repository adapter found for %s/%s (mirror of %s/%s): %w", target.Namespace, target.Name, namespace, name, err)
+		}
+		if authenticator, ok := adapter.(core.TokenAuthenticator); ok && target.Rule.Token != "" {
+			authenticator.SetToken(target.Rule.Token)
+		}
+		return rewrite.NewRemappedAdapter(adapter, namespace, name, target), nil
+	}
+
+	public, err := adapterRegistry.FindAdapter(namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("no repository adapter found for %s/%s: %w", namespace, name, err)
+	}
+	factories := core.NewAdapterRegistry()
+	builtin.RegisterDefaultFactories(factories)
+	if !factories.HasFactory(public.Name()) {
+		return nil, fmt.Errorf("rewrite rule %q: the %s adapter can't be pointed at a mirror URL", target.Rule.String(), public.Name())
+	}
+	ac, _ := adapterConfig(public.Name())
+	ac.Name = public.Name()
+	c := builtinAdapterConfig(ac)
+	c.BaseURL, c.Token = target.BaseURL, target.Rule.Token
+	adapter, err := factories.CreateAdapter(public.Name(), c)
+	if err != nil {
+		return nil, fmt.Errorf("rewrite rule %q: %w", target.Rule.String(), err)
+	}
+	return adapter, nil
+}
+
+// registryAdapter returns an adapter for the Axon registry a spec names by host, e.g.
+// registry.example.com/nlp/bert. Registries on the local machine are reached over HTTP,
+// others over HTTPS; the token stored for the local adapter authenticates to them.
+func registryAdapter(host string) core.RepositoryAdapter {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	scheme := "https"
+	if ip := net.ParseIP(hostname); hostname == "localhost" || (ip != nil && ip.IsLoopback()) {
+		scheme = "http"
+	}
+	adapter := builtin.NewLocalRegistryAdapter(scheme+"://"+host, nil)
+	if token, err := newCredentialManager().Get("local"); err == nil {
+		adapter.SetToken(token)
+	}
+	return adapter
+}
+
+// adapterConfig returns the adapters: entry for the named adapter, if any
+func adapterConfig(name string) (config.AdapterConfig, bool) {
+	for _, ac := range cfg.Adapters {
+		if ac.Name == name {
+			return ac, true
+		}
+	}
+	return config.AdapterConfig{}, false
+}
+
+func adaptersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "adapters",
+		Short: "Manage repository adapters",
+	}
+	cmd.AddCommand(adaptersListCmd())
+	return cmd
+}
+
+func adaptersListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List repository adapters in resolution order",
+		Long: `List the repository adapters in the order they are consulted. The first adapter
+that can handle a model's namespace serves it.
+
+Without an adapters: section in the config, external adapters (executables in
+~/.axon/adapters that speak the Axon adapter protocol, see
+internal/registry/external) come first, then the builtin adapters. With one, only
+the enabled adapters it lists are used, in the listed order:
+
+  adapters:
+    - name: local
+    - name: pytorch
+      timeout: 60
+    - name: huggingface
+      base_url: https://hf-mirror.example.com
+    - name: modelscope
+      enabled: false
+
+Rewrite rules send public model specs to private mirrors, either a URL speaking the
+protocol of the model's repository or another spec (e.g. a mirror in an Axon
+registry). Models keep their public spec in the cache:
+
+  rewrites:
+    - hf/* -> https://hf-mirror.example.com
+    - match: pytorch/*
+      to: mirrors/pytorch/*
+      token: <registry token>`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			adapterRegistry := core.NewAdapterRegistry()
+			registerAdapters(adapterRegistry)
+
+			source := "default order"
+			if len(cfg.Adapters) > 0 {
+				source = "from config"
+			}
+			fmt.Printf("Repository adapters (%s):\n", source)
+			for i, adapter := range adapterRegistry.GetAllAdapters() {
+				kind := "builtin"
+				var details []string
+				if ext, ok := adapter.(*external.Adapter); ok {
+					kind = "external"
+					details = append(details, ext.Path(), "namespaces: "+strings.Join(ext.Namespaces(), ", "))
+				}
+				if ac, ok := adapterConfig(adapter.Name()); ok {
+					if ac.BaseURL != "" {
+						details = append(details, "base_url: "+ac.BaseURL)
+					}
+					if ac.Timeout > 0 {
+						details = append(details, fmt.Sprintf("timeout: %ds", ac.Timeout))
+					}
+					if ac.RequestsPerSecond > 0 {
+						details = append(details, fmt.Sprintf("requests: %g/s", ac.RequestsPerSecond))
+					}
+				}
+				line := fmt.Sprintf("  %d. %-14s %-8s %s", i+1, adapter.Name(), kind, strings.Join(details, "  "))
+				fmt.Println(strings.TrimRight(line, " "))
+			}
+			for _, ac := range cfg.Adapters {
+				if !ac.IsEnabled() {
+					fmt.Printf("  -  %-14s disabled\n", ac.Name)
+				}
+			}
+			if rules := rewriteRules(); len(rules) > 0 {
+				fmt.Println("\nRewrite rules (first match applies):")
+				for _, rule := range rules {
+					fmt.Printf("  %s\n", rule)
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+func TestRegisterConfiguredAdapters(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.Registry.URL = "http://registry.example.com"
+
+	disabled := false
+	configs := []config.AdapterConfig{
+		{Name: "pytorch", Timeout: 60},
+		{Name: "huggingface", Enabled: &disabled},
+		{Name: "nosuch"},
+		{Name: "local"},
+		{Name: "pytorch"},
+		{Name: "modelscope", BaseURL: "https://modelscope.example.com"},
+	}
+
+	adapterRegistry := core.NewAdapterRegistry()
+	errs := registerConfiguredAdapters(adapterRegistry, configs)
+
+	var names []string
+	for _, adapter := range adapterRegistry.GetAllAdapters() {
+		names = append(names, adapter.Name())
+	}
+	if got := strings.Join(names, ","); got != "pytorch,local,modelscope" {
+		t.Errorf("registered adapters = %s, want pytorch,local,modelscope", got)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("errors = %v, want the unknown and the duplicate adapter", errs)
+	}
+	if !strings.Contains(errs[0].Error(), `unknown adapter "nosuch"`) || !strings.Contains(errs[1].Error(), "more than once") {
+		t.Errorf("errors = %v", errs)
+	}
+}
+
+func TestBuiltinAdapterConfig(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.Registry.URL = "http://registry.example.com"
+	cfg.Registry.Mirrors = []string{"http://mirror.example.com"}
+	cfg.Registry.HuggingFaceToken = "hf_fromregistry"
+
+	local := builtinAdapterConfig(config.AdapterConfig{Name: "local"})
+	if local.BaseURL != cfg.Registry.URL || len(local.Options["mirrors"].([]string)) != 1 {
+		t.Errorf("local config = %+v, want the registry URL and mirrors", local)
+	}
+	hf := builtinAdapterConfig(config.AdapterConfig{Name: "huggingface"})
+	if hf.Token != "hf_fromregistry" {
+		t.Errorf("huggingface token = %q, want registry.huggingface_token", hf.Token)
+	}
+	hf = builtinAdapterConfig(config.AdapterConfig{Name: "huggingface", Token: "hf_fromadapters"})
+	if hf.Token != "hf_fromadapters" {
+		t.Errorf("huggingface token = %q, want the adapters: token", hf.Token)
+	}
+}
+
+func TestFindModelAdapter(t *testing.T) {
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, credentials.NewFileStore(cfg.HomeDir))
+	}
+
+	tests := []struct {
+		name          string
+		adapter       string
+		namespace     string
+		modelName     string
+		wantAdapter   string
+		wantNamespace string
+		wantName      string
+	}{
+		{name: "routed by namespace", namespace: "pytorch", modelName: "vision/resnet50", wantAdapter: "pytorch", wantNamespace: "pytorch", wantName: "vision/resnet50"},
+		{name: "forced adapter claims namespace", adapter: "modelscope", namespace: "ms", modelName: "damo/cv", wantAdapter: "modelscope", wantNamespace: "ms", wantName: "damo/cv"},
+		{name: "forced adapter moves model", adapter: "modelscope", namespace: "damo", modelName: "cv", wantAdapter: "modelscope", wantNamespace: "ms", wantName: "damo/cv"},
+		{name: "alias", adapter: "hf", namespace: "google", modelName: "bert", wantAdapter: "huggingface", wantNamespace: "google", wantName: "bert"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter, namespace, name, err := findModelAdapter(tt.adapter, tt.namespace, tt.modelName)
+			if err != nil {
+				t.Fatalf("findModelAdapter() error = %v", err)
+			}
+			if adapter.Name() != tt.wantAdapter || namespace != tt.wantNamespace || name != tt.wantName {
+				t.Errorf("findModelAdapter() = %s, %s/%s; want %s, %s/%s", adapter.Name(), namespace, name, tt.wantAdapter, tt.wantNamespace, tt.wantName)
+			}
+		})
+	}
+
+	_, _, _, err := findModelAdapter("nosuch", "hf", "bert")
+	if err == nil || !strings.Contains(err.Error(), "registered adapters: url, pytorch, tensorflow-hub, modelscope, huggingface") {
+		t.Errorf("findModelAdapter(nosuch) error = %v, want the registered adapters", err)
+	}
+}
+
+func TestInstallModel_RegistrySpec(t *testing.T) {
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, credentials.NewFileStore(cfg.HomeDir))
+	}
+
+	// Publish a model to a registry that isn't configured, then install it by host
+	dir := t.TempDir()
+	for path, content := range map[string]string{"model.onnx": "onnx weights", "config.json": `{"model_type": "bert"}`} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref, err := spec.Parse("myteam/mymodel@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+		t.Fatal(err)
+	}
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	modelPath := cacheMgr.GetModelPath(ref.Namespace, ref.Name, ref.Version)
+	m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registryDir := t.TempDir()
+	if err := publishToRegistry(registryDir, ref, modelPath, m, "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheMgr.RemoveModel(ref.Namespace, ref.Name, ref.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(registryDir)))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	opts := defaultInstallOptions()
+	opts.adapter = "hf"
+	if err := installModel(context.Background(), host+"/myteam/mymodel@1.0.0", opts); err == nil || !strings.Contains(err.Error(), "names a registry") {
+		t.Errorf("installModel() with --adapter error = %v, want a conflict", err)
+	}
+	if err := installModel(context.Background(), host+"/myteam/mymodel@1.0.0", defaultInstallOptions()); err != nil {
+		t.Fatalf("installModel() error = %v", err)
+	}
+	if !cacheMgr.IsModelCached("myteam", "mymodel", "1.0.0") {
+		t.Error("model from the registry in the spec is not installed")
+	}
+}
+
+func TestInstallModel_VersionRange(t *testing.T) {
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, credentials.NewFileStore(cfg.HomeDir))
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("onnx weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	registryDir := t.TempDir()
+	for _, version := range []string{"1.2.0", "1.0.0", "1.3.0-rc.1", "2.0.0"} {
+		ref, err := spec.Parse("myteam/mymodel@" + version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+			t.Fatal(err)
+		}
+		modelPath := cacheMgr.GetModelPath(ref.Namespace, ref.Name, ref.Version)
+		m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := publishToRegistry(registryDir, ref, modelPath, m, "", nil, ""); err != nil {
+			t.Fatal(err)
+		}
+		if err := cacheMgr.RemoveModel(ref.Namespace, ref.Name, ref.Version); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(registryDir, "api/v1/models/myteam/mymodel/versions.json"))
+	if err != nil || !strings.Contains(string(data), `"1.0.0",`) || !strings.Contains(string(data), `"2.0.0"`) {
+		t.Fatalf("published versions.json = %s, %v", data, err)
+	}
+
+	server := httptest.NewServer(http.FileServer(http.Dir(registryDir)))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	if err := installModel(context.Background(), host+"/myteam/mymodel@^1.0", defaultInstallOptions()); err != nil {
+		t.Fatalf("installModel() error = %v", err)
+	}
+	if !cacheMgr.IsModelCached("myteam", "mymodel", "1.2.0") {
+		t.Fatal("^1.0 did not install 1.2.0")
+	}
+	if constraint, err := cache.ReadConstraint(cacheMgr.GetModelPath("myteam", "mymodel", "1.2.0")); err != nil || constraint != "^1.0" {
+		t.Errorf("recorded constraint = %q, %v, want ^1.0", constraint, err)
+	}
+
+	opts := defaultInstallOptions()
+	opts.pre = true
+	if err := installModel(context.Background(), host+"/myteam/mymodel@>=1.0 <2.0", opts); err != nil {
+		t.Fatalf("installModel() with pre error = %v", err)
+	}
+	if !cacheMgr.IsModelCached("myteam", "mymodel", "1.3.0-rc.1") {
+		t.Error("a range with --pre did not install the prerelease")
+	}
+
+	err = installModel(context.Background(), host+"/myteam/mymodel@^3", defaultInstallOptions())
+	if !errors.Is(err, spec.ErrNoMatchingVersion) {
+		t.Errorf("installModel() of an unsatisfiable range error = %v, want ErrNoMatchingVersion", err)
+	}
+}
+
+func TestInstallModel_Rewrite(t *testing.T) {
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, credentials.NewFileStore(cfg.HomeDir))
+	}
+
+	// Publish a model to a registry that only the rewrite rules point at
+	dir := t.TempDir()
+	for path, content := range map[string]string{"model.onnx": "onnx weights", "config.json": `{"model_type": "bert"}`} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref, err := spec.Parse("mirror/mymodel@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+		t.Fatal(err)
+	}
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	modelPath := cacheMgr.GetModelPath(ref.Namespace, ref.Name, ref.Version)
+	m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	registryDir := t.TempDir()
+	if err := publishToRegistry(registryDir, ref, modelPath, m, "", nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheMgr.RemoveModel(ref.Namespace, ref.Name, ref.Version); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(registryDir)))
+	defer server.Close()
+
+	// The configured registry is unreachable: only the mirror URL serves the model
+	cfg.Registry.URL = "http://127.0.0.1:1"
+	cfg.Rewrites = []config.RewriteConfig{{Match: "mirror/*", To: server.URL}}
+	if err := installModel(context.Background(), "mirror/mymodel@1.0.0", defaultInstallOptions()); err != nil {
+		t.Fatalf("installModel() via a mirror URL error = %v", err)
+	}
+	if !cacheMgr.IsModelCached("mirror", "mymodel", "1.0.0") {
+		t.Error("model from the mirror URL is not installed")
+	}
+
+	// A public spec mapped onto the model in the registry is cached under the public spec
+	cfg.Registry.URL = server.URL
+	cfg.Rewrites = []config.RewriteConfig{{Match: "public/*", To: "mirror/*"}}
+	if err := installModel(context.Background(), "public/mymodel@1.0.0", defaultInstallOptions()); err != nil {
+		t.Fatalf("installModel() via a mirror spec error = %v", err)
+	}
+	installed, err := loadManifest(filepath.Join(cacheMgr.GetModelPath("public", "mymodel", "1.0.0"), "manifest.yaml"))
+	if err != nil {
+		t.Fatalf("model not installed under its public spec: %v", err)
+	}
+	if installed.Metadata.Namespace != "public" {
+		t.Errorf("installed manifest namespace = %q, want the public one", installed.Metadata.Namespace)
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/mlOS-foundation/axon/internal/attestation"
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/registry"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/internal/signing"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// attestedModel is the model identity recorded in attestations
+func attestedModel(m *types.Manifest) string {
+	return fmt.Sprintf("%s/%s@%s", m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version)
+}
+
+// packageProvenance reads the provenance.json inside a package
+func packageProvenance(packagePath string) (*types.Provenance, error) {
+	tmpDir, err := os.MkdirTemp(utils.TempDir(), "axon-provenance-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	path := filepath.Join(tmpDir, types.ProvenanceFileName)
+	if err := model.ExtractPackageFile(packagePath, types.ProvenanceFileName, path); err != nil {
+		return nil, fmt.Errorf("package has no readable %s (reinstall the model to record its build inputs): %w", types.ProvenanceFileName, err)
+	}
+	return core.ReadProvenance(path)
+}
+
+// registryManifestDir is where a file-based registry keeps the manifest of a version
+func registryManifestDir(registryDir, namespace, name, version string) string {
+	return spec.NewModelRef(namespace, name, version).Path(filepath.Join(registryDir, "api", "v1", "models"))
+}
+
+// addRegistryVersion adds the version of ref to the versions.json in modelDir, which
+// clients resolve latest and version ranges against
+func addRegistryVersion(modelDir string, ref spec.Spec) error {
+	path := filepath.Join(modelDir, "versions.json")
+	list := types.ModelVersions{Namespace: ref.Namespace, Name: ref.Name}
+	if data, err := os.ReadFile(path); err == nil {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if slices.Contains(list.Versions, ref.Version) {
+		return nil
+	}
+	list.Versions = append(list.Versions, ref.Version)
+	spec.SortVersions(list.Versions)
+	list.Latest = spec.LatestVersion(list.Versions)
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal versions: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// publishToRegistry publishes a cached model into the directory of a file-based Axon
+// registry (see test/registry): the package under packages/, and the manifest with an
+// attestation of the package's build inputs under api/v1/models/<ns>/<name>/<version>/.
+// The attestation is signed with the key at keyPath, unless keyPath is empty. The
+// manifest's package URL is relative to the registry, so it moves with it.
+func publishToRegistry(registryDir string, ref spec.Spec, sourcePath string, m *types.Manifest, keyPath string, delta *types.DeltaInfo, deltaPath string) error {
+	packagePath, err := modelPackage(sourcePath)
+	if err != nil {
+		return err
+	}
+	digest, size, err := core.ComputeChecksum(packagePath)
+	if err != nil {
+		return fmt.Errorf("failed to hash package: %w", err)
+	}
+	provenance, err := packageProvenance(packagePath)
+	if err != nil {
+		return fmt.Errorf("cannot attest %s: %w", ref, err)
+	}
+
+	var key ed25519.PrivateKey
+	if keyPath != "" {
+		if key, err = signing.LoadPrivateKey(keyPath); err != nil {
+			return err
+		}
+	}
+	packageName := safeTempFileName(ref.Namespace, ref.Name, ref.Version)
+	envelope, err := attestation.Seal(attestation.New(attestedModel(m), packageName, digest, provenance), key)
+	if err != nil {
+		return err
+	}
+
+	packagesDir := filepath.Join(registryDir, "packages")
+	manifestDir := registryManifestDir(registryDir, ref.Namespace, ref.Name, ref.Version)
+	for _, dir := range []string{packagesDir, manifestDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create registry directory: %w", err)
+		}
+	}
+	if err := copyFile(packagePath, filepath.Join(packagesDir, packageName)); err != nil {
+		return fmt.Errorf("failed to copy package: %w", err)
+	}
+
+	m.Distribution.Package.URL = "/packages/" + packageName
+	m.Distribution.Package.Mirrors = nil
+	m.Distribution.Package.SHA256 = digest
+	m.Distribution.Package.Size = size
+	if delta != nil {
+		// Delta URLs are relative to the package, so the delta goes next to it
+		if err := copyFile(deltaPath, filepath.Join(packagesDir, filepath.FromSlash(delta.URL))); err != nil {
+			return fmt.Errorf("failed to copy delta: %w", err)
+		}
+		m.Distribution.Deltas = withDelta(m.Distribution.Deltas, *delta)
+	}
+
+	if err := attestation.Write(filepath.Join(manifestDir, attestation.FileName), envelope); err != nil {
+		return err
+	}
+	// The manifest goes last, so clients never see it before its package
+	if err := saveManifest(m, filepath.Join(manifestDir, "manifest.yaml")); err != nil {
+		return fmt.Errorf("failed to write published manifest: %w", err)
+	}
+	if err := addRegistryVersion(filepath.Dir(manifestDir), ref); err != nil {
+		return err
+	}
+
+	fmt.Printf("🧾 Attested build inputs: %s", provenance.Source.Adapter)
+	if provenance.Source.Revision != "" {
+		fmt.Printf(" @ %s", provenance.Source.Revision)
+	}
+	if provenance.Converter != nil {
+		fmt.Printf(", converted with %s", provenance.Converter.Method)
+	}
+	if len(envelope.Signatures) > 0 {
+		fmt.Printf(" (signed, key %s)", envelope.Signatures[0].KeyID)
+	}
+	fmt.Printf("\n")
+	return nil
+}
+
+// uploadToRegistry publishes a cached model to an Axon registry server that accepts
+// uploads (see test/registry), authenticating with the "local" credential. The files
+// publishToRegistry would write are staged in a temp directory and uploaded in the same
+// order: packages, then the attestation, then the manifest that makes the version visible.
+func uploadToRegistry(ctx context.Context, registryURL string, ref spec.Spec, sourcePath string, m *types.Manifest, keyPath string, delta *types.DeltaInfo, deltaPath string) error {
+	stageDir, err := os.MkdirTemp(utils.TempDir(), "axon-publish-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(stageDir) }()
+	if err := publishToRegistry(stageDir, ref, sourcePath, m, keyPath, delta, deltaPath); err != nil {
+		return err
+	}
+
+	client := registry.NewClient(registryURL, nil)
+	if token, err := newCredentialManager().Get("local"); err == nil {
+		client.SetToken(token)
+	}
+	packages, err := os.ReadDir(filepath.Join(stageDir, "packages"))
+	if err != nil {
+		return fmt.Errorf("failed to read staged packages: %w", err)
+	}
+	for _, entry := range packages {
+		fmt.Printf("📤 Uploading %s...\n", entry.Name())
+		if err := client.UploadPackage(ctx, entry.Name(), filepath.Join(stageDir, "packages", entry.Name())); err != nil {
+			return err
+		}
+	}
+
+	manifestDir := registryManifestDir(stageDir, ref.Namespace, ref.Name, ref.Version)
+	data, err := os.ReadFile(filepath.Join(manifestDir, attestation.FileName))
+	if err != nil {
+		return fmt.Errorf("failed to read staged attestation: %w", err)
+	}
+	if err := client.UploadAttestation(ctx, ref.Namespace, ref.Name, ref.Version, data); err != nil {
+		return err
+	}
+	if data, err = os.ReadFile(filepath.Join(manifestDir, "manifest.yaml")); err != nil {
+		return fmt.Errorf("failed to read staged manifest: %w", err)
+	}
+	return client.UploadManifest(ctx, ref.Namespace, ref.Name, ref.Version, data)
+}
+
+// loadAttestation returns the attestation of an installed model: the one in its
+// directory, or else the one published with it in the configured registry
+func loadAttestation(ctx context.Context, modelPath string, m *types.Manifest) (*attestation.Envelope, string, error) {
+	localPath := filepath.Join(modelPath, attestation.FileName)
+	envelope, err := attestation.Read(localPath)
+	if err == nil {
+		return envelope, localPath, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return nil, "", err
+	}
+	if cfg.Registry.URL == "" {
+		return nil, "", fmt.Errorf("no %s in %s and no registry configured to fetch it from", attestation.FileName, modelPath)
+	}
+
+	client := registry.NewClient(cfg.Registry.URL, cfg.Registry.Mirrors)
+	data, err := client.GetAttestation(ctx, m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch attestation: %w", err)
+	}
+	envelope, err = attestation.Parse(data)
+	if err != nil {
+		return nil, "", err
+	}
+	return envelope, cfg.Registry.URL, nil
+}
+
+// verifyAttestation validates the chain from a model's attestation to its installed
+// package: the attestation is signed by a trusted key, its subject is the package, and
+// the build inputs it names are those in the package's provenance.json. Like package
+// signatures, unsigned attestations are accepted unless security.require_signatures is set.
+func verifyAttestation(ctx context.Context, modelPath string) error {
+	m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+	if err != nil {
+		return err
+	}
+	envelope, source, err := loadAttestation(ctx, modelPath, m)
+	if err != nil {
+		return err
+	}
+
+	trusted, err := trustedKeys(cfg.Security)
+	if err != nil {
+		return err
+	}
+	keyID, err := envelope.Verify(trusted)
+	switch {
+	case err == nil:
+	case errors.Is(err, attestation.ErrUnsigned) && !cfg.Security.RequireSignatures:
+		fmt.Printf("⚠️  Attestation is not signed; its origin is not checked\n")
+	case errors.Is(err, attestation.ErrUntrusted) && len(trusted) == 0 && !cfg.Security.RequireSignatures:
+		fmt.Printf("⚠️  Attestation is signed but no keys are trusted; signature not checked\n")
+	case errors.Is(err, attestation.ErrUnsigned):
+		return fmt.Errorf("%w (security.require_signatures is set)", err)
+	default:
+		return err
+	}
+
+	statement, err := envelope.Statement()
+	if err != nil {
+		return err
+	}
+	packagePath, err := modelPackage(modelPath)
+	if err != nil {
+		return err
+	}
+	digest, err := utils.ComputeSHA256(packagePath)
+	if err != nil {
+		return fmt.Errorf("failed to hash package: %w", err)
+	}
+	provenance, err := packageProvenance(packagePath)
+	if err != nil {
+		return err
+	}
+
+	// A package rebuilt after ONNX conversion is no longer the attested one. Its source
+	// files and their origin are unchanged; the converter is the local one.
+	err = attestation.CheckSubject(statement, digest)
+	rebuilt := m.Spec.Conversion != nil && m.Spec.Conversion.Status == converter.ConversionStatusConverted
+	switch {
+	case errors.Is(err, attestation.ErrMismatch) && rebuilt:
+		fmt.Printf("ℹ️  Package was rebuilt after ONNX conversion; checked the attested inputs against its source files instead\n")
+		local := *provenance
+		local.Converter = nil
+		err = attestation.CheckInputs(statement, attestedModel(m), &local)
+	case err == nil:
+		err = attestation.CheckInputs(statement, attestedModel(m), provenance)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Attestation verified (%s)\n", source)
+	if keyID != "" {
+		fmt.Printf("   Signed by key %s\n", keyID)
+	}
+	params := statement.Predicate.BuildDefinition.ExternalParameters
+	fmt.Printf("   Package: sha256:%s\n", statement.Subject[0].Digest["sha256"])
+	fmt.Printf("   Source: %s %s", params.Source.Adapter, params.Source.Model)
+	if params.Source.Revision != "" {
+		fmt.Printf(" @ %s", params.Source.Revision)
+	}
+	fmt.Printf("\n")
+	if params.Converter != nil {
+		fmt.Printf("   Converter: %s %s\n", params.Converter.Method, params.Converter.Image)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/attestation"
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/signing"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+func TestPublishToRegistryAndVerifyAttestation(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	dir := t.TempDir()
+	for path, content := range map[string]string{"model.onnx": "onnx weights", "config.json": `{"model_type": "bert"}`} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref, err := spec.Parse("myteam/mymodel@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+		t.Fatalf("importModel() error = %v", err)
+	}
+	modelPath := cache.NewManager(cfg.ModelCacheDir()).GetModelPath(ref.Namespace, ref.Name, ref.Version)
+
+	key, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "signing.pem")
+	if err := signing.WritePrivateKey(keyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registryDir := t.TempDir()
+	if err := publishToRegistry(registryDir, ref, modelPath, m, keyPath, nil, ""); err != nil {
+		t.Fatalf("publishToRegistry() error = %v", err)
+	}
+	if !pathExists(filepath.Join(registryDir, "packages", "myteam-mymodel-1.0.0.axon")) {
+		t.Error("package was not published")
+	}
+	published, err := loadManifest(filepath.Join(registryManifestDir(registryDir, "myteam", "mymodel", "1.0.0"), "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if published.Distribution.Package.URL != "/packages/myteam-mymodel-1.0.0.axon" {
+		t.Errorf("package URL = %q", published.Distribution.Package.URL)
+	}
+
+	// The registry serves the attestation next to the manifest
+	server := httptest.NewServer(http.FileServer(http.Dir(registryDir)))
+	defer server.Close()
+	cfg.Registry.URL = server.URL
+	public := key.Public().(ed25519.PublicKey)
+	cfg.Security.TrustedKeys = []string{signing.EncodePublicKey(public)}
+	if err := verifyAttestation(context.Background(), modelPath); err != nil {
+		t.Fatalf("verifyAttestation() error = %v", err)
+	}
+
+	other, _ := signing.GenerateKey()
+	cfg.Security.TrustedKeys = []string{signing.EncodePublicKey(other.Public().(ed25519.PublicKey))}
+	if err := verifyAttestation(context.Background(), modelPath); !errors.Is(err, attestation.ErrUntrusted) {
+		t.Errorf("verifyAttestation() with another trusted key error = %v, want ErrUntrusted", err)
+	}
+
+	// An attestation in the model directory takes precedence; this one is about another package
+	cfg.Security.TrustedKeys = []string{signing.EncodePublicKey(public)}
+	provenance, err := packageProvenance(filepath.Join(registryDir, "packages", "myteam-mymodel-1.0.0.axon"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	envelope, err := attestation.Seal(attestation.New("myteam/mymodel@1.0.0", "myteam-mymodel-1.0.0.axon", "0123abcd", provenance), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := attestation.Write(filepath.Join(modelPath, attestation.FileName), envelope); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAttestation(context.Background(), modelPath); !errors.Is(err, attestation.ErrMismatch) {
+		t.Errorf("verifyAttestation() of another package error = %v, want ErrMismatch", err)
+	}
+}
@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/registry/builtin"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+)
+
+// tokenAdapters are the adapters that accept a token, with what the token is used for
+var tokenAdapters = map[string]string{
+	"huggingface": "gated and private Hugging Face models",
+	"modelscope":  "private ModelScope models",
+	"pytorch":     "GitHub API rate limits for PyTorch Hub",
+	"local":       "Axon registries that require authentication",
+}
+
+// newCredentialManager returns the token store; replaced in tests
+var newCredentialManager = func() *credentials.Manager {
+	return credentials.NewManager(cfg.HomeDir)
+}
+
+// registerAdapters registers the adapters from the adapters: config section, or the
+// external and default adapters without one, and gives each one its stored token. With
+// registry.proxy the local registry handles every namespace.
+func registerAdapters(adapterRegistry *core.AdapterRegistry) {
+	credentials.RegisterSecret(cfg.Registry.HuggingFaceToken)
+	if len(cfg.Adapters) > 0 {
+		for _, err := range registerConfiguredAdapters(adapterRegistry, cfg.Adapters) {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: skipping adapter: %v\n", err)
+		}
+	} else {
+		registerExternalAdapters(adapterRegistry)
+		builtin.RegisterDefaultAdapters(adapterRegistry, cfg.Registry.URL, cfg.Registry.Mirrors, cfg.Registry.HuggingFaceToken, cfg.Registry.EnableHuggingFace)
+	}
+	if cfg.Registry.Proxy {
+		if adapter, err := adapterRegistry.GetAdapterByName("local"); err == nil {
+			if local, ok := adapter.(*builtin.LocalRegistryAdapter); ok {
+				local.SetProxy(true)
+			}
+		}
+	}
+	injectTokens(adapterRegistry, newCredentialManager())
+}
+
+// injectTokens sets the stored token on every adapter that accepts one. A stored token
+// takes precedence over tokens in the config file.
+func injectTokens(adapterRegistry *core.AdapterRegistry, manager *credentials.Manager) {
+	for _, adapter := range adapterRegistry.GetAllAdapters() {
+		authenticator, ok := adapter.(core.TokenAuthenticator)
+		if !ok {
+			continue
+		}
+		if token, err := manager.Get(adapter.Name()); err == nil {
+			authenticator.SetToken(token)
+		}
+	}
+}
+
+// tokenAdapterName resolves an adapter name or alias, checking it accepts a token
+func tokenAdapterName(name string) (string, error) {
+	adapter := credentials.CanonicalAdapter(name)
+	if _, ok := tokenAdapters[adapter]; !ok {
+		names := make([]string, 0, len(tokenAdapters))
+		for n := range tokenAdapters {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return "", fmt.Errorf("adapter %q does not use a token (supported: %s)", name, strings.Join(names, ", "))
+	}
+	return adapter, nil
+}
+
+// stdinIsTerminal reports whether standard input is interactive; replaced in tests
+var stdinIsTerminal = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// tokenInput is where prompted tokens are read from; replaced in tests
+var tokenInput io.Reader = os.Stdin
+
+// promptGatedToken explains a gated model to an interactive user without a token and
+// asks for one of an account that accepted the license. The token is stored like
+// 'axon login' stores it and set on adapter, so the caller can retry. Returns false
+// when there's nobody to ask or no token was given.
+func promptGatedToken(adapter core.RepositoryAdapter, gated *core.GatedModelError) bool {
+	if gated.HasToken || !stdinIsTerminal() {
+		return false
+	}
+	authenticator, ok := adapter.(core.TokenAuthenticator)
+	if !ok {
+		return false
+	}
+
+	fmt.Printf("🔒 %s is a gated model. Accept its license at %s while logged in,\n", gated.Model, gated.URL)
+	if gated.Approval == core.GatedManual {
+		fmt.Println("   and wait for its authors to approve the request. Then enter a token of that account.")
+	} else {
+		fmt.Println("   then enter a token of that account.")
+	}
+	fmt.Printf("🔑 Token for %s (empty to cancel): ", gated.Adapter)
+	token, err := readToken(tokenInput)
+	if err != nil {
+		return false
+	}
+	credentials.RegisterSecret(token)
+
+	if backend, err := newCredentialManager().Set(gated.Adapter, token); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to store token: %v\n", err)
+	} else {
+		fmt.Printf("✓ Stored %s token in %s\n", gated.Adapter, backend)
+	}
+	authenticator.SetToken(token)
+	return true
+}
+
+// readToken reads a token from the first line of in
+func readToken(in io.Reader) (string, error) {
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", fmt.Errorf("no token provided")
+	}
+	return token, nil
+}
+
+// checkTokenScope checks with Hugging Face that token has scope (read or write) before
+// it's stored, so a fine-grained token missing access to an organization's repositories
+// is caught at login rather than on the first install
+func checkTokenScope(ctx context.Context, adapter, token, scope string) error {
+	c := core.AdapterConfig{Token: token}
+	if ac, ok := adapterConfig(adapter); ok {
+		c.BaseURL = ac.BaseURL
+	}
+	created, err := builtin.NewHuggingFaceFactory().Create(c)
+	if err != nil {
+		return err
+	}
+	info, err := created.(*builtin.HuggingFaceAdapter).TokenInfo(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check token: %w", err)
+	}
+	if !info.Allows(scope) {
+		return fmt.Errorf("the token of %s (%s) has no %s access; create one at https://huggingface.co/settings/tokens", info.User, info, scope)
+	}
+	fmt.Printf("✓ Token of %s has %s access (%s)\n", info.User, scope, info)
+	return nil
+}
+
+// clearConfigToken removes the plaintext Hugging Face token from the config file
+func clearConfigToken() error {
+	if cfg.Registry.HuggingFaceToken == "" {
+		return nil
+	}
+	cfg.Registry.HuggingFaceToken = ""
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to remove huggingface_token from config: %w", err)
+	}
+	return nil
+}
+
+func loginCmd() *cobra.Command {
+	var token, scope string
+
+	cmd := &cobra.Command{
+		Use:   "login <adapter>",
+		Short: "Store an access token for a repository adapter",
+		Long: `Store an access token for a repository adapter (huggingface, modelscope, pytorch
+or local). Tokens are kept in the OS keychain (macOS Keychain, or the Secret Service
+on Linux) and otherwise in an encrypted file in ~/.axon. They are sent only to the
+adapter they belong to and are redacted from Axon's output.
+
+The token is read from standard input unless --token is given, which keeps it out of
+your shell history:
+
+  echo "$HF_TOKEN" | axon login huggingface
+
+Logging in to huggingface moves a token from registry.huggingface_token in
+config.yaml to the keychain. With --scope, a huggingface token is checked for read
+(or write) access first; fine-grained tokens need read access to the repositories of
+private organizations to install their models:
+
+  echo "$HF_TOKEN" | axon login hf --scope read`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			adapter, err := tokenAdapterName(args[0])
+			if err != nil {
+				return err
+			}
+			if scope != "" {
+				if adapter != "huggingface" {
+					return fmt.Errorf("--scope is only checked for huggingface tokens")
+				}
+				if scope != builtin.TokenScopeRead && scope != builtin.TokenScopeWrite {
+					return fmt.Errorf("invalid scope %q (expected %s or %s)", scope, builtin.TokenScopeRead, builtin.TokenScopeWrite)
+				}
+			}
+
+			if token == "" {
+				if stdinIsTerminal() {
+					fmt.Printf("🔑 Token for %s (%s): ", adapter, tokenAdapters[adapter])
+				}
+				if token, err = readToken(os.Stdin); err != nil {
+					return err
+				}
+			}
+			credentials.RegisterSecret(token)
+			if scope != "" {
+				if err := checkTokenScope(cmd.Context(), adapter, token, scope); err != nil {
+					return err
+				}
+			}
+
+			backend, err := newCredentialManager().Set(adapter, token)
+			if err != nil {
+				return fmt.Errorf("failed to store token: %w", err)
+			}
+			fmt.Printf("✓ Stored %s token in %s\n", adapter, backend)
+
+			if adapter == "huggingface" && cfg.Registry.HuggingFaceToken != "" {
+				if err := clearConfigToken(); err != nil {
+					return err
+				}
+				fmt.Println("✓ Removed plaintext huggingface_token from config.yaml")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&token, "token", "", "Token value (prefer standard input, which stays out of shell history)")
+	cmd.Flags().StringVar(&scope, "scope", "", "Check that a huggingface token has this access before storing it (read or write)")
+	return cmd
+}
+
+func logoutCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "logout [adapter]",
+		Short: "Remove the stored access token for a repository adapter",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var adapters []string
+			switch {
+			case all:
+				for adapter := range tokenAdapters {
+					adapters = append(adapters, adapter)
+				}
+				sort.Strings(adapters)
+			case len(args) == 1:
+				adapter, err := tokenAdapterName(args[0])
+				if err != nil {
+					return err
+				}
+				adapters = []string{adapter}
+			default:
+				return fmt.Errorf("specify an adapter or --all")
+			}
+
+			manager := newCredentialManager()
+			removed := 0
+			for _, adapter := range adapters {
+				err := manager.Delete(adapter)
+				if adapter == "huggingface" && cfg.Registry.HuggingFaceToken != "" {
+					if clearErr := clearConfigToken(); clearErr != nil {
+						return clearErr
+					}
+					err = nil
+				}
+				switch {
+				case errors.Is(err, credentials.ErrNotFound):
+					if !all {
+						fmt.Printf("ℹ️  No token stored for %s\n", adapter)
+					}
+				case err != nil:
+					return err
+				default:
+					fmt.Printf("✓ Removed %s token\n", adapter)
+					removed++
+				}
+			}
+			if all && removed == 0 {
+				fmt.Println("ℹ️  No tokens stored")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Remove the tokens of all adapters")
+	return cmd
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// tokenAdapter records the token it is given
+type tokenAdapter struct {
+	name  string
+	token string
+}
+
+func (a *tokenAdapter) Name() string               { return a.name }
+func (a *tokenAdapter) CanHandle(_, _ string) bool { return true }
+func (a *tokenAdapter) SetToken(token string)      { a.token = token }
+func (a *tokenAdapter) Search(context.Context, string) ([]types.SearchResult, error) {
+	return nil, nil
+}
+func (a *tokenAdapter) GetManifest(context.Context, string, string, string) (*types.Manifest, error) {
+	return nil, nil
+}
+func (a *tokenAdapter) DownloadPackage(context.Context, *types.Manifest, string, core.ProgressCallback) error {
+	return nil
+}
+
+func TestInjectTokens(t *testing.T) {
+	manager := credentials.NewManagerWithStores(nil, credentials.NewFileStore(t.TempDir()))
+	if _, err := manager.Set("hf", "hf_storedtoken123"); err != nil {
+		t.Fatal(err)
+	}
+
+	hf := &tokenAdapter{name: "huggingface", token: "hf_fromconfig456"}
+	ms := &tokenAdapter{name: "modelscope"}
+	adapterRegistry := core.NewAdapterRegistry()
+	adapterRegistry.Register(hf)
+	adapterRegistry.Register(ms)
+
+	injectTokens(adapterRegistry, manager)
+
+	if hf.token != "hf_storedtoken123" {
+		t.Errorf("huggingface token = %q, want the stored token", hf.token)
+	}
+	if ms.token != "" {
+		t.Errorf("modelscope token = %q, want none", ms.token)
+	}
+}
+
+func TestTokenAdapterName(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "hf", want: "huggingface"},
+		{in: "huggingface", want: "huggingface"},
+		{in: "ms", want: "modelscope"},
+		{in: "registry", want: "local"},
+		{in: "tensorflow-hub", wantErr: true},
+		{in: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := tokenAdapterName(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("tokenAdapterName(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("tokenAdapterName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadToken(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "hf_abc123\n", want: "hf_abc123"},
+		{in: "  hf_abc123  \r\nignored\n", want: "hf_abc123"},
+		{in: "hf_noeol", want: "hf_noeol"},
+		{in: "\n", wantErr: true},
+		{in: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := readToken(strings.NewReader(tt.in))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("readToken(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("readToken(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLoginLogout(t *testing.T) {
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	store := credentials.NewFileStore(cfg.HomeDir)
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, store)
+	}
+
+	login := loginCmd()
+	login.SetArgs([]string{"ms", "--token", "ms-secret-token"})
+	if err := login.Execute(); err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+	if token, err := store.Get("modelscope"); err != nil || token != "ms-secret-token" {
+		t.Fatalf("stored token = %q, %v", token, err)
+	}
+
+	logout := logoutCmd()
+	logout.SetArgs([]string{"modelscope"})
+	if err := logout.Execute(); err != nil {
+		t.Fatalf("logout error = %v", err)
+	}
+	if _, err := store.Get("modelscope"); err == nil {
+		t.Error("token still stored after logout")
+	}
+
+	logout = logoutCmd()
+	logout.SetArgs([]string{})
+	if err := logout.Execute(); err == nil {
+		t.Error("logout without an adapter or --all should fail")
+	}
+}
+
+func TestPromptGatedToken(t *testing.T) {
+	originalCfg, originalManager, originalTerminal, originalInput := cfg, newCredentialManager, stdinIsTerminal, tokenInput
+	defer func() {
+		cfg, newCredentialManager, stdinIsTerminal, tokenInput = originalCfg, originalManager, originalTerminal, originalInput
+	}()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	store := credentials.NewFileStore(cfg.HomeDir)
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, store)
+	}
+	gated := &core.GatedModelError{Adapter: "huggingface", Model: "meta-llama/Llama-3-8B", URL: "https://huggingface.co/meta-llama/Llama-3-8B", Approval: core.GatedAuto}
+
+	// Nobody to ask outside a terminal
+	stdinIsTerminal = func() bool { return false }
+	hf := &tokenAdapter{name: "huggingface"}
+	if promptGatedToken(hf, gated) {
+		t.Error("promptGatedToken() prompted without a terminal")
+	}
+
+	stdinIsTerminal = func() bool { return true }
+	tokenInput = strings.NewReader("hf_accepted\n")
+	if !promptGatedToken(hf, gated) {
+		t.Fatal("promptGatedToken() = false with a token entered")
+	}
+	if hf.token != "hf_accepted" {
+		t.Errorf("adapter token = %q, want the entered token", hf.token)
+	}
+	if token, err := store.Get("huggingface"); err != nil || token != "hf_accepted" {
+		t.Errorf("stored token = %q, %v", token, err)
+	}
+
+	// A token that was refused isn't asked for again
+	gated.HasToken = true
+	tokenInput = strings.NewReader("hf_other\n")
+	if promptGatedToken(hf, gated) {
+		t.Error("promptGatedToken() prompted although the token has no access")
+	}
+}
+
+func TestLogin_Scope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/whoami-v2" {
+			http.NotFound(w, r)
+			return
+		}
+		scoped := `"scoped": [{"entity": {"type": "org", "name": "acme"}, "permissions": ["repo.content.read"]}]`
+		if r.Header.Get("Authorization") == "Bearer hf_discussions" {
+			scoped = `"global": ["discussion.write"]`
+		}
+		_, _ = w.Write([]byte(`{"name": "alice", "auth": {"accessToken": {"role": "fineGrained", "fineGrained": {` + scoped + `}}}}`))
+	}))
+	defer server.Close()
+
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.Adapters = []config.AdapterConfig{{Name: "huggingface", BaseURL: server.URL}}
+	store := credentials.NewFileStore(cfg.HomeDir)
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, store)
+	}
+
+	login := loginCmd()
+	login.SetArgs([]string{"hf", "--scope", "read", "--token", "hf_discussions"})
+	if err := login.Execute(); err == nil || !strings.Contains(err.Error(), "no read access") {
+		t.Errorf("login with a token without read access: error = %v", err)
+	}
+	if _, err := store.Get("huggingface"); err == nil {
+		t.Error("token without the scope was stored")
+	}
+
+	login = loginCmd()
+	login.SetArgs([]string{"hf", "--scope", "read", "--token", "hf_acme_read"})
+	if err := login.Execute(); err != nil {
+		t.Fatalf("login error = %v", err)
+	}
+	if token, err := store.Get("huggingface"); err != nil || token != "hf_acme_read" {
+		t.Errorf("stored token = %q, %v", token, err)
+	}
+
+	login = loginCmd()
+	login.SetArgs([]string{"ms", "--scope", "read", "--token", "ms-token"})
+	if err := login.Execute(); err == nil {
+		t.Error("login --scope for modelscope should fail")
+	}
+}
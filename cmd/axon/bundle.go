@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/bundle"
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/history"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// defaultBundleName is the file 'axon export' writes to without --dest
+func defaultBundleName(namespace, name, version string) string {
+	return strings.TrimSuffix(safeTempFileName(namespace, name, version), ".axon") + bundle.Extension
+}
+
+// exportModel writes a bundle of an installed model to dest (the default bundle name if
+// empty) and returns its path and index. Encrypted packages are decrypted into the
+// bundle, since the machine it is carried to has its own key.
+func exportModel(modelSpec, dest string) (string, *bundle.Index, error) {
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return "", nil, err
+	}
+	cached, err := findCachedModel(cache.NewManager(cfg.ModelCacheDir()), ref.Namespace, ref.Name, ref.Version)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w\nInstall it first with 'axon install %s'", err, modelSpec)
+	}
+	if dest == "" {
+		dest = defaultBundleName(cached.Namespace, cached.Name, cached.Version)
+	}
+
+	tmpDir, err := os.MkdirTemp(utils.TempDir(), "axon-export-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	packagePath, err := exportPackagePath(cached.Path, tmpDir)
+	if err != nil {
+		return "", nil, err
+	}
+	sources := []bundle.Source{
+		{Role: bundle.RoleManifest, Path: filepath.Join(cached.Path, "manifest.yaml")},
+		{Role: bundle.RolePackage, Path: packagePath},
+	}
+	if provenancePath := filepath.Join(cached.Path, types.ProvenanceFileName); pathExists(provenancePath) {
+		sources = append(sources, bundle.Source{Role: bundle.RoleProvenance, Path: provenancePath})
+	}
+
+	modelID := fmt.Sprintf("%s/%s@%s", cached.Namespace, cached.Name, cached.Version)
+	index, err := bundle.Create(dest, modelID, version, sources)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to export %s: %w", modelID, err)
+	}
+	return dest, index, nil
+}
+
+// exportPackagePath returns the package of the model in modelPath, decrypting it into
+// tmpDir if the cache is encrypted
+func exportPackagePath(modelPath, tmpDir string) (string, error) {
+	if packages, _ := filepath.Glob(filepath.Join(modelPath, "*.axon")); len(packages) > 0 {
+		return packages[0], nil
+	}
+	encrypted, _ := filepath.Glob(filepath.Join(modelPath, "*.axon"+cachecrypt.Suffix))
+	if len(encrypted) == 0 {
+		return "", fmt.Errorf("no .axon package in %s to export", modelPath)
+	}
+	key, err := cacheEncryptionKey(false)
+	if err != nil {
+		return "", err
+	}
+	packagePath := filepath.Join(tmpDir, strings.TrimSuffix(filepath.Base(encrypted[0]), cachecrypt.Suffix))
+	if err := cachecrypt.DecryptFile(encrypted[0], packagePath, key); err != nil {
+		return "", err
+	}
+	return packagePath, nil
+}
+
+// importBundle checks a bundle and installs the model in it, subject to the same
+// signature, license and size checks as 'axon install'. It returns the model and its
+// cache path.
+func importBundle(bundlePath string, opts importOptions) (ref spec.Spec, cachePath string, err error) {
+	start := time.Now()
+	defer func() {
+		if ref.Name != "" {
+			recordHistory(history.ActionImport, ref.String(), cachedPackageDigest(ref.String()), err, start)
+		}
+	}()
+
+	tmpDir, err := os.MkdirTemp(utils.TempDir(), "axon-bundle-")
+	if err != nil {
+		return ref, "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	index, err := bundle.Extract(bundlePath, tmpDir)
+	if err != nil {
+		return ref, "", err
+	}
+	if ref, err = spec.Parse(index.Model); err != nil || !ref.HasVersion {
+		return spec.Spec{}, "", fmt.Errorf("%w: model %q is not namespace/name@version", bundle.ErrInvalid, index.Model)
+	}
+	manifestEntry, _ := index.File(bundle.RoleManifest)
+	packageEntry, _ := index.File(bundle.RolePackage)
+
+	manifest, err := loadManifest(filepath.Join(tmpDir, manifestEntry.Name))
+	if err != nil {
+		return ref, "", err
+	}
+	if m := manifest.Metadata; m.Namespace != ref.Namespace || m.Name != ref.Name || m.Version != ref.Version {
+		return ref, "", fmt.Errorf("%w: manifest is for %s/%s@%s, bundle is for %s", bundle.ErrInvalid, m.Namespace, m.Name, m.Version, ref)
+	}
+	packagePath := filepath.Join(tmpDir, packageEntry.Name)
+	if err := verifyModelSignature(manifest, packagePath); err != nil {
+		return ref, "", fmt.Errorf("signature verification failed for %s: %w", ref, err)
+	}
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	if err := prepareImport(cacheMgr, ref, manifest, opts); err != nil {
+		return ref, "", err
+	}
+	cachePath, err = cacheImportedPackage(cacheMgr, ref, manifest, packagePath)
+	return ref, cachePath, err
+}
+
+func exportCmd() *cobra.Command {
+	var dest string
+
+	cmd := &cobra.Command{
+		Use:   "export namespace/name[@version]",
+		Short: "Write an installed model to a portable bundle",
+		Long: `Write an installed model to a single self-contained file - its package, manifest and
+provenance - for carrying to machines without network access, such as air-gapped
+clusters. Install it there with 'axon import-bundle'.
+
+The bundle records the SHA-256 of every file, so a bundle damaged in transit is
+rejected. Signatures in the manifest are kept and checked on import. If the cache is
+encrypted, the package is decrypted into the bundle.
+
+Examples:
+  axon export hf/bert-base-uncased@latest
+  axon export myteam/mymodel@1.0.0 --dest /media/usb/mymodel.axon.bundle`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Printf("📦 Exporting %s...\n", args[0])
+			path, index, err := exportModel(args[0], dest)
+			if err != nil {
+				return err
+			}
+			var size int64
+			for _, entry := range index.Files {
+				size += entry.Size
+			}
+			fmt.Printf("✓ Exported %s to %s (%s)\n", index.Model, path, formatBytes(size))
+			fmt.Printf("   💡 Install it on another machine with 'axon import-bundle %s'\n", filepath.Base(path))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dest, "dest", "", "Bundle file to write (default: <namespace>-<name>-<version>"+bundle.Extension+")")
+	return cmd
+}
+
+func importBundleCmd() *cobra.Command {
+	var opts importOptions
+
+	cmd := &cobra.Command{
+		Use:   "import-bundle <bundle>",
+		Short: "Install a model from a bundle written by 'axon export'",
+		Long: `Install a model from a bundle written by 'axon export' on another machine, without
+network access.
+
+Every file is checked against the bundle's checksums, the manifest signature is checked
+against security.trusted_keys, and the license and size policies apply as they do for
+'axon install'.
+
+Examples:
+  axon import-bundle /media/usb/mymodel.axon.bundle
+  axon import-bundle hf-bert-base-uncased-latest.axon.bundle --force`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Printf("📥 Importing bundle %s...\n", args[0])
+			ref, cachePath, err := importBundle(args[0], opts)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n✓ Imported %s into %s\n", ref, cachePath)
+			fmt.Printf("   💡 Register it with MLOS Core with 'axon register %s'\n", ref)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Replace the model if it is already installed")
+	cmd.Flags().BoolVar(&opts.overrideLicensePolicy, overrideLicensePolicyFlag, false, "Import even if the license violates policy.allowed_licenses or policy.denied_licenses")
+	cmd.Flags().BoolVar(&opts.overrideSizePolicy, overrideSizePolicyFlag, false, "Import even if the model exceeds policy.max_model_size_gb or its namespace quota")
+	return cmd
+}
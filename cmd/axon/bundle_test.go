@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+func TestExportImportBundle(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	// The exporting machine encrypts its cache; the bundle must not depend on its key
+	key, err := cachecrypt.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(cacheKeyEnv, cachecrypt.EncodeKey(key))
+	cfg.Cache.Encryption.Enabled = true
+
+	dir := t.TempDir()
+	for path, content := range map[string]string{"model.onnx": "onnx weights", "config.json": `{"model_type": "bert"}`} {
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ref, err := spec.Parse("myteam/mymodel@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+		t.Fatalf("importModel() error = %v", err)
+	}
+
+	bundlePath := filepath.Join(t.TempDir(), "mymodel.axon.bundle")
+	path, index, err := exportModel("myteam/mymodel@1.0.0", bundlePath)
+	if err != nil {
+		t.Fatalf("exportModel() error = %v", err)
+	}
+	if path != bundlePath || index.Model != "myteam/mymodel@1.0.0" || len(index.Files) != 3 {
+		t.Errorf("exportModel() = %s, %+v", path, index)
+	}
+
+	// The importing machine has a fresh, unencrypted cache and no key
+	cfg.CacheDir = t.TempDir()
+	cfg.Cache.Encryption.Enabled = false
+	t.Setenv(cacheKeyEnv, "")
+
+	imported, cachePath, err := importBundle(bundlePath, importOptions{})
+	if err != nil {
+		t.Fatalf("importBundle() error = %v", err)
+	}
+	if imported.String() != "myteam/mymodel@1.0.0" {
+		t.Errorf("imported %s", imported)
+	}
+	data, err := os.ReadFile(filepath.Join(cachePath, model.ONNXFileName))
+	if err != nil || string(data) != "onnx weights" {
+		t.Errorf("model.onnx = %q, %v", data, err)
+	}
+	if problems, err := model.VerifyInventory(cachePath); err != nil || len(problems) > 0 {
+		t.Errorf("VerifyInventory() = %v, %v", problems, err)
+	}
+
+	if _, _, err := importBundle(bundlePath, importOptions{}); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("second importBundle() error = %v, want already installed", err)
+	}
+
+	cfg.Policy.DeniedLicenses = []string{"mit"}
+	if _, _, err := importBundle(bundlePath, importOptions{force: true}); err == nil || !strings.Contains(err.Error(), "refusing to import") {
+		t.Errorf("importBundle() of a denied license error = %v, want refusing to import", err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+)
+
+// modelDiskUsage is the disk usage of one cached model version
+type modelDiskUsage struct {
+	ID    string // namespace/name@version
+	Usage cache.DiskUsage
+}
+
+// namespaceDiskUsage is the disk usage of the cached models of a namespace
+type namespaceDiskUsage struct {
+	Namespace string
+	Models    int
+	Size      int64
+}
+
+// cacheStats is the disk usage of the cache, by kind of file, namespace and model
+type cacheStats struct {
+	Total      int64           // Every file in the cache, including those of no model
+	Models     cache.DiskUsage // The files of cached models, by kind
+	ByModel    []modelDiskUsage
+	Namespaces []namespaceDiskUsage
+}
+
+func cacheStatsCmd() *cobra.Command {
+	var top int
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Cache statistics",
+		Long: `Show the disk usage of the cache: in total, split into packages (.axon), files
+extracted from them and conversion artifacts (ONNX and GGUF files produced on install
+or by 'axon convert'), per namespace, and per model from the largest.
+
+Use --top N to only list the N largest models, e.g. to pick what to uninstall.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if top < 0 {
+				return fmt.Errorf("--top must be positive, got %d", top)
+			}
+			stats, err := collectCacheStats(cache.NewManager(cfg.ModelCacheDir()))
+			if err != nil {
+				return err
+			}
+			printCacheStats(stats, top)
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&top, "top", 0, "Only list the N largest models (default: all)")
+	return cmd
+}
+
+// collectCacheStats measures the disk usage of every cached model. Models and namespaces
+// are sorted from the largest.
+func collectCacheStats(cacheMgr *cache.Manager) (cacheStats, error) {
+	var stats cacheStats
+	total, err := cacheMgr.GetCacheSize()
+	if err != nil && !os.IsNotExist(err) {
+		return stats, fmt.Errorf("failed to get cache size: %w", err)
+	}
+	stats.Total = total
+
+	models, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		return stats, fmt.Errorf("failed to list models: %w", err)
+	}
+	namespaces := make(map[string]*namespaceDiskUsage)
+	for _, model := range models {
+		usage, err := cacheMgr.GetModelDiskUsage(model.Namespace, model.Name, model.Version)
+		if err != nil {
+			return stats, fmt.Errorf("failed to measure %s/%s@%s: %w", model.Namespace, model.Name, model.Version, err)
+		}
+		stats.Models.Add(usage)
+		stats.ByModel = append(stats.ByModel, modelDiskUsage{
+			ID:    fmt.Sprintf("%s/%s@%s", model.Namespace, filepath.ToSlash(model.Name), model.Version),
+			Usage: usage,
+		})
+
+		namespace, ok := namespaces[model.Namespace]
+		if !ok {
+			namespace = &namespaceDiskUsage{Namespace: model.Namespace}
+			namespaces[model.Namespace] = namespace
+		}
+		namespace.Models++
+		namespace.Size += usage.Total()
+	}
+	for _, namespace := range namespaces {
+		stats.Namespaces = append(stats.Namespaces, *namespace)
+	}
+
+	sort.SliceStable(stats.ByModel, func(i, j int) bool {
+		a, b := stats.ByModel[i], stats.ByModel[j]
+		if a.Usage.Total() != b.Usage.Total() {
+			return a.Usage.Total() > b.Usage.Total()
+		}
+		return a.ID < b.ID
+	})
+	sort.Slice(stats.Namespaces, func(i, j int) bool {
+		a, b := stats.Namespaces[i], stats.Namespaces[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.Namespace < b.Namespace
+	})
+	return stats, nil
+}
+
+// printCacheStats prints the disk usage of the cache, with the top largest models (all if 0)
+func printCacheStats(stats cacheStats, top int) {
+	fmt.Println("Cache statistics:")
+	fmt.Printf("  Total size: %s\n", formatBytes(stats.Total))
+	fmt.Printf("  Models: %d\n", len(stats.ByModel))
+	if len(stats.ByModel) == 0 {
+		return
+	}
+	fmt.Printf("    %-22s %10s\n", "Packages:", formatBytes(stats.Models.Package))
+	fmt.Printf("    %-22s %10s\n", "Extracted files:", formatBytes(stats.Models.Extracted))
+	fmt.Printf("    %-22s %10s\n", "Conversion artifacts:", formatBytes(stats.Models.Conversion))
+	// Conversion result caches, indexes and the like
+	if other := stats.Total - stats.Models.Total(); other > 0 {
+		fmt.Printf("    %-22s %10s\n", "Other files:", formatBytes(other))
+	}
+
+	fmt.Println()
+	fmt.Println("By namespace:")
+	for _, namespace := range stats.Namespaces {
+		fmt.Printf("  %-16s %4d model(s) %10s\n", namespace.Namespace, namespace.Models, formatBytes(namespace.Size))
+	}
+
+	models := stats.ByModel
+	fmt.Println()
+	if top > 0 && top < len(models) {
+		models = models[:top]
+		fmt.Printf("Largest models (top %d of %d):\n", top, len(stats.ByModel))
+	} else {
+		fmt.Println("Largest models:")
+	}
+	width := len("MODEL")
+	for _, model := range models {
+		width = max(width, len(model.ID))
+	}
+	fmt.Printf("  %-*s  %10s  %10s  %10s  %10s\n", width, "MODEL", "SIZE", "PACKAGE", "EXTRACTED", "CONVERTED")
+	for _, model := range models {
+		usage := model.Usage
+		fmt.Printf("  %-*s  %10s  %10s  %10s  %10s\n", width, model.ID, formatBytes(usage.Total()),
+			formatBytes(usage.Package), formatBytes(usage.Extracted), formatBytes(usage.Conversion))
+	}
+}
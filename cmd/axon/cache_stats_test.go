@@ -0,0 +1,51 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestCollectCacheStats(t *testing.T) {
+	cacheMgr := cache.NewManager(t.TempDir())
+	if stats, err := collectCacheStats(cacheMgr); err != nil || len(stats.ByModel) != 0 {
+		t.Fatalf("collectCacheStats() of an empty cache = %+v, %v", stats, err)
+	}
+
+	weights := map[string]int{"hf/bert@latest": 300, "hf/gpt2@latest": 700, "ollama/llama3@8b": 500}
+	for id, size := range weights {
+		ref, err := spec.Parse(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cacheMgr.CacheModel(ref.Namespace, ref.Name, ref.Version, &types.Manifest{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(cacheMgr.GetModelPath(ref.Namespace, ref.Name, ref.Version), "model.safetensors"), make([]byte, size<<10), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := collectCacheStats(cacheMgr)
+	if err != nil {
+		t.Fatalf("collectCacheStats() error = %v", err)
+	}
+	var ids []string
+	for _, model := range stats.ByModel {
+		ids = append(ids, model.ID)
+	}
+	if want := []string{"hf/gpt2@latest", "ollama/llama3@8b", "hf/bert@latest"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("models = %v, want %v (largest first)", ids, want)
+	}
+	if len(stats.Namespaces) != 2 || stats.Namespaces[0].Namespace != "hf" || stats.Namespaces[0].Models != 2 {
+		t.Errorf("namespaces = %+v, want hf with 2 models first", stats.Namespaces)
+	}
+	if stats.Models.Total() != stats.Total || stats.Models.Extracted != stats.Total {
+		t.Errorf("by kind = %+v, want every file extracted (total %d)", stats.Models, stats.Total)
+	}
+}
@@ -2,42 +2,50 @@
 package main
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
 	"github.com/mlOS-foundation/axon/internal/cache"
-	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
 	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/history"
+	"github.com/mlOS-foundation/axon/internal/hooks"
+	"github.com/mlOS-foundation/axon/internal/journal"
 	"github.com/mlOS-foundation/axon/internal/manifest"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/internal/model"
 	"github.com/mlOS-foundation/axon/internal/registry/builtin"
 	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/internal/telemetry"
+	"github.com/mlOS-foundation/axon/internal/tokenizer"
+	"github.com/mlOS-foundation/axon/pkg/spec"
 	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 )
 
-// safeTempFileName creates a safe filename for temp files by replacing path separators
-// This prevents issues with model IDs containing slashes (e.g., "hf/microsoft/resnet-50")
-// which would otherwise create nested directories in the temp path.
+// safeTempFileName is the file name of a model's package, in temp directories, the cache
+// and registries. Model IDs with slashes (e.g., "hf/microsoft/resnet-50") are flattened
+// into one path component.
 func safeTempFileName(namespace, name, version string) string {
-	// Replace path separators and other problematic characters
-	safeNamespace := strings.ReplaceAll(namespace, "/", "_")
-	safeNamespace = strings.ReplaceAll(safeNamespace, "\\", "_")
-	safeName := strings.ReplaceAll(name, "/", "_")
-	safeName = strings.ReplaceAll(safeName, "\\", "_")
-	safeVersion := strings.ReplaceAll(version, "/", "_")
-	safeVersion = strings.ReplaceAll(safeVersion, "\\", "_")
-	return fmt.Sprintf("%s-%s-%s.axon", safeNamespace, safeName, safeVersion)
+	return spec.NewModelRef(namespace, name, version).FileName(".axon")
 }
 
 // updateManifestAfterInstall updates manifest with execution format and I/O schema after model installation
@@ -54,8 +62,25 @@ func updateManifestAfterInstall(modelPath string, m *types.Manifest) error {
 		fmt.Printf("⚠️  Failed to populate execution files: %v\n", err)
 	}
 
+	// Adapters that don't report the task leave it to the architectures in config.json
+	if m.Spec.Task == "" {
+		m.Spec.Task = model.DetectDirTask(modelPath)
+	}
+
+	// Adapters that couldn't size the model declare placeholder requirements; size them
+	// from the installed files
+	if model.IsPlaceholderRequirements(m.Spec.Requirements) {
+		if requirements, ok := model.EstimateDirRequirements(modelPath); ok {
+			m.Spec.Requirements = requirements
+		}
+	}
+
+	// Quantized checkpoints require a GPU, and GPU requirements name the accelerators the
+	// execution format runs on, so Core can schedule the model on a suitable device
+	model.DetectDirGPURequirement(&m.Spec.Requirements, modelPath, m.Spec.Format.ExecutionFormat)
+
 	// Try to extract I/O schema from config.json if available
-	configPath := filepath.Join(modelPath, "config.json")
+	configPath := model.LayoutFile(modelPath, "config.json")
 	if _, err := os.Stat(configPath); err == nil {
 		inputs, outputs, err := builtin.ExtractIOSchemaFromConfig(configPath)
 		if err == nil && len(inputs) > 0 {
@@ -64,6 +89,19 @@ func updateManifestAfterInstall(modelPath string, m *types.Manifest) error {
 		}
 	}
 
+	// The ONNX graph, converted or shipped, is the ground truth for tensor specs
+	applyONNXIO(modelPath, m)
+
+	// Point tokenization hints at the tokenizer's place in the model directory
+	for _, input := range m.Spec.IO.Inputs {
+		if input.Preprocessing == nil || input.Preprocessing.Tokenizer == "" {
+			continue
+		}
+		if relPath, err := filepath.Rel(modelPath, model.LayoutFile(modelPath, filepath.Base(input.Preprocessing.Tokenizer))); err == nil {
+			input.Preprocessing.Tokenizer = filepath.ToSlash(relPath)
+		}
+	}
+
 	return nil
 }
 
@@ -155,14 +193,70 @@ func populateExecutionFiles(modelPath string, m *types.Manifest) error {
 	return nil
 }
 
+// applyONNXIO replaces the manifest's I/O with the inputs and outputs of the model's ONNX
+// graph, keeping the descriptions and preprocessing hints of tensors with the same names.
+// Models split across several ONNX files (encoder/decoder) keep the config-derived schema.
+func applyONNXIO(modelPath string, m *types.Manifest) {
+	var graphs []string
+	for _, file := range m.Spec.Format.ExecutionFiles {
+		if file.Format == "onnx" {
+			graphs = append(graphs, file.Path)
+		}
+	}
+	if len(graphs) != 1 {
+		return
+	}
+
+	info, err := converter.InspectONNXFile(filepath.Join(modelPath, filepath.FromSlash(graphs[0])))
+	if err != nil {
+		fmt.Printf("⚠️  Failed to read ONNX inputs and outputs: %v\n", err)
+		return
+	}
+	if len(info.InputTensors) == 0 || len(info.OutputTensors) == 0 {
+		return
+	}
+	m.Spec.IO.Inputs = onnxIOSpecs(info.InputTensors, m.Spec.IO.Inputs)
+	m.Spec.IO.Outputs = onnxIOSpecs(info.OutputTensors, m.Spec.IO.Outputs)
+}
+
+// onnxIOSpecs converts ONNX graph tensors to manifest I/O specs, carrying over what the
+// graph doesn't know (descriptions, preprocessing) from the specs it replaces
+func onnxIOSpecs(tensors []converter.ONNXTensorInfo, previous []types.IOSpec) []types.IOSpec {
+	byName := make(map[string]types.IOSpec, len(previous))
+	for _, spec := range previous {
+		byName[spec.Name] = spec
+	}
+
+	specs := make([]types.IOSpec, 0, len(tensors))
+	for _, tensor := range tensors {
+		spec := types.IOSpec{Name: tensor.Name, DType: tensor.DType, Shape: tensor.Shape}
+		if old, ok := byName[tensor.Name]; ok {
+			spec.Description = old.Description
+			spec.Preprocessing = old.Preprocessing
+			if spec.DType == "" {
+				spec.DType = old.DType
+			}
+			if spec.Shape == nil {
+				spec.Shape = old.Shape
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
 // determineONNXFileType determines the role of an ONNX file (single, encoder, decoder, etc.)
 func determineONNXFileType(relPath string, multiEncoderManifest *converter.MultiEncoderManifest, hasMultiEncoder bool) string {
 	baseName := strings.ToLower(filepath.Base(relPath))
 
 	// If we have a multi-encoder manifest, use it to determine type
 	if hasMultiEncoder && multiEncoderManifest != nil {
-		// Check architecture type
-		switch multiEncoderManifest.Architecture {
+		// The manifest names each component's file
+		if role := multiEncoderManifest.Role(relPath); role != "" {
+			return role
+		}
+		// Otherwise go by the encoder type
+		switch multiEncoderManifest.EncoderType {
 		case "seq2seq":
 			if strings.Contains(baseName, "encoder") {
 				return "encoder"
@@ -274,131 +368,46 @@ func copyFile(src, dst string) error {
 	return err
 }
 
-// parseModelSpec parses a model specification string (namespace/name[@version])
-// Supports both simple format (namespace/name) and multi-part format (namespace/repo/model)
-func parseModelSpec(spec string) (namespace, name, version string) {
-	parts := strings.Split(spec, "/")
-	if len(parts) < 2 {
-		return "", "", ""
-	}
-
-	namespace = parts[0]
-	// Join remaining parts as the name (supports multi-part names like "vision/resnet50")
-	nameVersion := strings.Join(parts[1:], "/")
-
-	// Check for version
-	if strings.Contains(nameVersion, "@") {
-		nameParts := strings.Split(nameVersion, "@")
-		name = nameParts[0]
-		version = nameParts[1]
-	} else {
-		name = nameVersion
-		version = "latest"
-	}
-
-	return namespace, name, version
-}
-
-// extractPackage extracts a .axon package (tar.gz) to the destination directory
-func extractPackage(packagePath, destDir string) error {
-	file, err := os.Open(packagePath)
-	if err != nil {
-		return fmt.Errorf("failed to open package: %w", err)
-	}
-	defer func() {
-		_ = file.Close()
-	}()
-
-	gzReader, err := gzip.NewReader(file)
+// rebuildPackageWithONNX rebuilds the .axon package including the ONNX file. The new
+// package keeps the source recorded in the original provenance.json and adds the converter.
+func rebuildPackageWithONNX(sourceDir, packagePath string, conversion *types.Conversion) (*model.PackageWrite, error) {
+	// Create new package builder
+	builder, err := core.NewPackageBuilder()
 	if err != nil {
-		return fmt.Errorf("failed to create gzip reader: %w", err)
+		return nil, fmt.Errorf("failed to create package builder: %w", err)
 	}
 	defer func() {
-		_ = gzReader.Close()
+		_ = builder.Cleanup()
 	}()
 
-	tarReader := tar.NewReader(gzReader)
-
-	// Resolve and clean destination directory to prevent path traversal
-	destDir, err = filepath.Abs(destDir)
-	if err != nil {
-		return fmt.Errorf("failed to resolve destination directory: %w", err)
-	}
-	destDir = filepath.Clean(destDir)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return fmt.Errorf("failed to read tar: %w", err)
-		}
-
-		// Sanitize archive entry name to prevent Zip Slip (path traversal) vulnerability
-		// Clean the path and ensure it doesn't contain ".." that would escape destDir
-		entryName := filepath.Clean(header.Name)
-		if strings.HasPrefix(entryName, "..") || strings.Contains(entryName, "..") {
-			return fmt.Errorf("invalid archive entry: path traversal detected in %s", header.Name)
-		}
-
-		// Join with destination directory
-		targetPath := filepath.Join(destDir, entryName)
-
-		// Verify the resolved path is still within destDir (prevent path traversal)
-		targetPath, err = filepath.Abs(targetPath)
-		if err != nil {
-			return fmt.Errorf("failed to resolve target path: %w", err)
-		}
-		if !strings.HasPrefix(targetPath, destDir) {
-			return fmt.Errorf("invalid archive entry: path traversal detected - %s would escape destination directory", header.Name)
-		}
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(targetPath, os.FileMode(header.Mode)); err != nil {
-				return fmt.Errorf("failed to create directory: %w", err)
-			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-				return fmt.Errorf("failed to create parent directory: %w", err)
-			}
-
-			outFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
-				return fmt.Errorf("failed to create file: %w", err)
-			}
-
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				_ = outFile.Close()
-				return fmt.Errorf("failed to extract file: %w", err)
+	if provenance, err := core.ReadProvenance(filepath.Join(sourceDir, types.ProvenanceFileName)); err == nil {
+		builder.SetSource(provenance.Source)
+		for _, file := range provenance.Files {
+			if file.URL != "" {
+				builder.SetFileURL(file.Path, file.URL)
 			}
-			_ = outFile.Close()
+			builder.SetFileETag(file.Path, file.ETag)
 		}
+		builder.SetWeightScan(provenance.WeightScan)
 	}
+	builder.SetConverter(provenanceConverter(conversion))
 
-	return nil
-}
-
-// rebuildPackageWithONNX rebuilds the .axon package including the ONNX file
-func rebuildPackageWithONNX(sourceDir, packagePath string) error {
-	// Create new package builder
-	builder, err := core.NewPackageBuilder()
-	if err != nil {
-		return fmt.Errorf("failed to create package builder: %w", err)
+	// A multi-encoder model is only usable with all of its components
+	if onnxManifest, ok := converter.CheckForMultiEncoderManifest(sourceDir); ok {
+		if missing := onnxManifest.MissingFiles(sourceDir); len(missing) > 0 {
+			return nil, fmt.Errorf("%s lists files that are missing: %s", model.ONNXManifestFileName, strings.Join(missing, ", "))
+		}
 	}
-	defer func() {
-		_ = builder.Cleanup()
-	}()
 
-	// Add all files from source directory (including model.onnx)
+	// Add all files from source directory (model.onnx, or onnx_manifest.json and the
+	// ONNX file of every component)
 	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
-		// Skip the package file itself
-		if info.Name() == filepath.Base(packagePath) {
+		// Skip the package file itself and the provenance being replaced
+		if info.Name() == filepath.Base(packagePath) || path == filepath.Join(sourceDir, types.ProvenanceFileName) {
 			return nil
 		}
 
@@ -412,26 +421,50 @@ func rebuildPackageWithONNX(sourceDir, packagePath string) error {
 		return nil
 	})
 	if err != nil {
-		return fmt.Errorf("failed to add files to package: %w", err)
+		return nil, fmt.Errorf("failed to add files to package: %w", err)
 	}
 
-	// Build new package (temporary location)
+	// Build new package (temporary location), copying the unchanged files of the old one
+	// rather than compressing the weights again
 	tmpPackage := packagePath + ".tmp"
-	if err := builder.Build(tmpPackage); err != nil {
-		return fmt.Errorf("failed to build package: %w", err)
+	written, err := builder.Rebuild(packagePath, tmpPackage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build package: %w", err)
 	}
 
 	// Replace old package with new one
-	if err := os.Rename(tmpPackage, packagePath); err != nil {
-		// If rename fails, try copy
-		if err := copyFile(tmpPackage, packagePath); err != nil {
-			_ = os.Remove(tmpPackage)
-			return fmt.Errorf("failed to replace package: %w", err)
-		}
+	if err := utils.MoveFile(tmpPackage, packagePath); err != nil {
 		_ = os.Remove(tmpPackage)
+		return nil, fmt.Errorf("failed to replace package: %w", err)
 	}
 
-	return nil
+	// Keep the extracted provenance.json in line with the package
+	if err := builder.SaveProvenance(filepath.Join(sourceDir, types.ProvenanceFileName)); err != nil {
+		return nil, err
+	}
+	return written, nil
+}
+
+// provenanceConverter describes a successful conversion for a package's provenance.json
+func provenanceConverter(conversion *types.Conversion) *types.ProvenanceConverter {
+	if conversion == nil || conversion.Status != converter.ConversionStatusConverted {
+		return nil
+	}
+	pc := &types.ProvenanceConverter{
+		Method:      conversion.Method,
+		Image:       conversion.Image,
+		ImageDigest: conversion.ImageDigest,
+		Opset:       conversion.Opset,
+		Precision:   conversion.Precision,
+		Parity:      conversion.Parity,
+	}
+	for _, artifact := range conversion.Artifacts {
+		pc.Files = append(pc.Files, artifact.Path)
+		if pc.Toolchain == nil {
+			pc.Toolchain = artifact.Toolchain
+		}
+	}
+	return pc
 }
 
 func initCmd() *cobra.Command {
@@ -464,26 +497,61 @@ func initCmd() *cobra.Command {
 }
 
 func searchCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "search [query]",
 		Short: "Search for models in the registry",
-		Long:  "Search the axon registry for available neural network models",
-		Args:  cobra.ExactArgs(1),
+		Long: `Search the axon registry for available neural network models.
+
+Use --adapter to search a repository through its adapter instead, e.g.
+  axon search --adapter huggingface bert
+
+Results are cached per adapter for cache.search_ttl seconds (an hour by default), so
+repeating a search doesn't query the repository again; --no-cache searches it anyway.
+Every model a search returns is also added to an offline index, which --offline
+searches together with the installed models without any network access.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			query := args[0]
+			adapterName, _ := cmd.Flags().GetString("adapter")
+			noCache, _ := cmd.Flags().GetBool("no-cache")
+			offline, _ := cmd.Flags().GetBool("offline")
 			fmt.Printf("Searching for models matching '%s'...\n", query)
 
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			searches := cacheMgr.SearchCache(time.Duration(cfg.Cache.SearchTTL) * time.Second)
+			if offline {
+				results, err := searches.SearchOffline(cacheMgr, query)
+				if err != nil {
+					return fmt.Errorf("offline search failed: %w", err)
+				}
+				fmt.Printf("(offline: models seen by earlier searches and installed models)\n")
+				printSearchResults(results)
+				return nil
+			}
+
+			if adapterName != "" {
+				adapter, _, _, err := findModelAdapter(adapterName, "", "")
+				if err != nil {
+					return err
+				}
+				results, err := cachedSearch(cmd.Context(), searches, adapter, query, noCache)
+				if err != nil {
+					return fmt.Errorf("%s search failed: %w", adapter.Name(), err)
+				}
+				printSearchResults(results)
+				return nil
+			}
+
 			// Use builtin local adapter for search
 			adapterRegistry := core.NewAdapterRegistry()
-			builtin.RegisterDefaultAdapters(adapterRegistry, cfg.Registry.URL, cfg.Registry.Mirrors, cfg.Registry.HuggingFaceToken, cfg.Registry.EnableHuggingFace)
+			registerAdapters(adapterRegistry)
 
 			// Try to find an adapter that supports search
 			// For now, use local registry if available
 			var results []types.SearchResult
 			var err error
-			if cfg.Registry.URL != "" {
-				localAdapter := builtin.NewLocalRegistryAdapter(cfg.Registry.URL, cfg.Registry.Mirrors)
-				results, err = localAdapter.Search(cmd.Context(), query)
+			if localAdapter, lookupErr := adapterRegistry.GetAdapterByName("local"); lookupErr == nil {
+				results, err = cachedSearch(cmd.Context(), searches, localAdapter, query, noCache)
 			} else {
 				fmt.Printf("⚠ Registry search not yet available (registry may not be configured)\n")
 				fmt.Printf("   Query: %s\n", query)
@@ -497,64 +565,94 @@ func searchCmd() *cobra.Command {
 				return nil
 			}
 
-			if len(results) == 0 {
-				fmt.Println("No models found.")
-				return nil
-			}
-
-			fmt.Printf("\nFound %d model(s):\n\n", len(results))
-			for _, result := range results {
-				fmt.Printf("  %s/%s@%s\n", result.Namespace, result.Name, result.Version)
-				if result.Description != "" {
-					fmt.Printf("    %s\n", result.Description)
-				}
-				fmt.Println()
-			}
-
+			printSearchResults(results)
 			return nil
 		},
 	}
+
+	cmd.Flags().String("adapter", "", "Search this repository adapter instead of the registry (see 'axon adapters list')")
+	cmd.Flags().Bool("no-cache", false, "Search the repository even if the results are cached")
+	cmd.Flags().Bool("offline", false, "Search the offline index of models seen before and the installed models")
+	return cmd
+}
+
+// cachedSearch searches a repository through its adapter, reusing cached results unless
+// noCache is set, and caches what it finds
+func cachedSearch(ctx context.Context, searches *cache.SearchCache, adapter core.RepositoryAdapter, query string, noCache bool) ([]types.SearchResult, error) {
+	if !noCache {
+		if results, fetchedAt, ok := searches.Lookup(adapter.Name(), query); ok {
+			fmt.Printf("(cached %s ago; --no-cache searches %s again)\n", time.Since(fetchedAt).Round(time.Second), adapter.Name())
+			return results, nil
+		}
+	}
+	results, err := adapter.Search(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if err := searches.Store(adapter.Name(), query, results); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+	return results, nil
+}
+
+// printSearchResults prints the models found by a search
+func printSearchResults(results []types.SearchResult) {
+	if len(results) == 0 {
+		fmt.Println("No models found.")
+		return
+	}
+
+	fmt.Printf("\nFound %d model(s):\n\n", len(results))
+	for _, result := range results {
+		fmt.Printf("  %s/%s@%s\n", result.Namespace, result.Name, result.Version)
+		if result.Description != "" {
+			fmt.Printf("    %s\n", result.Description)
+		}
+		fmt.Println()
+	}
 }
 
 func infoCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "info [namespace/name[@version]]",
 		Short: "Get model information",
-		Long:  "Display detailed information about a model",
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			modelSpec := args[0]
-			namespace, name, version := parseModelSpec(modelSpec)
-
-			if namespace == "" || name == "" {
-				return fmt.Errorf("invalid model specification: %s (expected: namespace/name[@version])", modelSpec)
-			}
-
-			if version == "latest" || version == "" {
-				version = "latest"
-			}
+		Long: `Display detailed information about a model.
 
-			fmt.Printf("Fetching info for %s/%s@%s...\n", namespace, name, version)
+For installed models, the conversion status is shown as well. Use --toolchain to
+list the converter package versions (torch, transformers, optimum, onnx, opset)
+that produced each converted artifact. Multi-encoder models (CLIP, encoder-decoder)
+also list the ONNX file of each component and its role.
 
-			// Try to find adapter for this model
-			adapterRegistry := core.NewAdapterRegistry()
+Use --provenance to show the package's supply-chain record (provenance.json): the
+source repository and revision, the URL and SHA256 of every file, the converter
+and the Axon version that built it.
 
-			// Register adapters using builtin registration
-			builtin.RegisterDefaultAdapters(adapterRegistry, cfg.Registry.URL, cfg.Registry.Mirrors, cfg.Registry.HuggingFaceToken, cfg.Registry.EnableHuggingFace)
+The Policy line shows whether the model passes the configured license and size
+policies, i.e. whether 'axon install' would accept it.
 
-			// Find the best adapter
-			adapter, err := adapterRegistry.FindAdapter(namespace, name)
+Installed models are shown from their cached manifest without contacting the
+repository; --remote fetches the manifest from the repository instead (as --adapter
+does). Use --files to list the files of an installed model as they are on disk and
+in its package, with their sizes and SHA256.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			showToolchain, _ := cmd.Flags().GetBool("toolchain")
+			showProvenance, _ := cmd.Flags().GetBool("provenance")
+			showFiles, _ := cmd.Flags().GetBool("files")
+			remote, _ := cmd.Flags().GetBool("remote")
+			adapterName, _ := cmd.Flags().GetString("adapter")
+			modelSpec := args[0]
+			ref, err := spec.Parse(modelSpec)
 			if err != nil {
-				return fmt.Errorf("no repository adapter found for %s/%s: %w", namespace, name, err)
+				return err
 			}
 
-			fmt.Printf("Using %s adapter\n", adapter.Name())
-
-			// Get manifest from adapter
-			manifest, err := adapter.GetManifest(cmd.Context(), namespace, name, version)
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			manifest, namespace, name, err := infoManifest(cmd.Context(), cacheMgr, ref, adapterName, remote)
 			if err != nil {
-				return fmt.Errorf("failed to get model information: %w", err)
+				return err
 			}
+			version := ref.Version
 
 			// Display model information
 			fmt.Printf("\n📦 Model Information\n")
@@ -573,6 +671,10 @@ func infoCmd() *cobra.Command {
 				fmt.Println()
 			}
 
+			if manifest.Spec.Task != "" {
+				fmt.Printf("Task:        %s\n", manifest.Spec.Task)
+			}
+
 			if manifest.Metadata.License != "" {
 				fmt.Printf("License:     %s\n", manifest.Metadata.License)
 			}
@@ -597,9 +699,277 @@ func infoCmd() *cobra.Command {
 				}
 			}
 
+			// Show whether the model could be installed under the configured policies
+			fmt.Println()
+			printPolicyStatus(cfg.Policy, cfg.Tenant, cacheMgr, namespace, manifest)
+
+			// Show how the execution format was produced if the model is installed
+			cached, err := findCachedModel(cacheMgr, namespace, name, version)
+			if err == nil {
+				if local, err := cacheMgr.GetCachedManifest(cached.Namespace, cached.Name, cached.Version); err == nil {
+					if local.Spec.Conversion != nil {
+						printConversion(local.Spec.Conversion)
+						if showToolchain {
+							printToolchain(local.Spec.Conversion)
+						}
+					}
+					if local.Spec.Format.MultiEncoder != "" {
+						printComponents(local)
+					}
+				}
+			}
+
+			if showProvenance {
+				if cached == nil {
+					return fmt.Errorf("%s/%s@%s is not installed; provenance is recorded when the package is built (run 'axon install' first)", namespace, name, version)
+				}
+				provenancePath := filepath.Join(cacheMgr.GetModelPath(cached.Namespace, cached.Name, cached.Version), types.ProvenanceFileName)
+				provenance, err := core.ReadProvenance(provenancePath)
+				if errors.Is(err, os.ErrNotExist) {
+					fmt.Printf("\nℹ️  No provenance recorded (installed before provenance.json was added; reinstall to record it)\n")
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+				printProvenance(provenance, provenancePath)
+			}
+
+			if showFiles {
+				if cached == nil {
+					return fmt.Errorf("%s/%s@%s is not installed; --files lists the files of installed models (run 'axon install' first)", namespace, name, version)
+				}
+				return printModelFiles(cacheMgr, cached)
+			}
+
 			return nil
 		},
 	}
+
+	cmd.Flags().Bool("toolchain", false, "Show converter toolchain versions for each converted artifact")
+	cmd.Flags().Bool("provenance", false, "Show the package's provenance (source, file URLs and hashes, converter, builder)")
+	cmd.Flags().Bool("files", false, "List the extracted and packaged files of an installed model with sizes and SHA256")
+	cmd.Flags().Bool("remote", false, "Fetch the manifest from the repository even if the model is installed")
+	cmd.Flags().String("adapter", "", "Repository adapter to use instead of routing by namespace (see 'axon adapters list')")
+	return cmd
+}
+
+// infoManifest returns the manifest 'axon info' shows, and the namespace and name it's
+// for: the cached manifest of an installed model, or else (and with remote or an
+// adapter name) the one in the repository
+func infoManifest(ctx context.Context, cacheMgr *cache.Manager, ref spec.Spec, adapterName string, remote bool) (*types.Manifest, string, string, error) {
+	if !remote && adapterName == "" {
+		if cached, err := findCachedModel(cacheMgr, ref.Namespace, ref.Name, ref.Version); err == nil {
+			if manifest, err := cacheMgr.GetCachedManifest(cached.Namespace, cached.Name, cached.Version); err == nil {
+				fmt.Printf("Showing installed %s/%s@%s (use --remote to fetch it from the repository)\n", cached.Namespace, cached.Name, cached.Version)
+				return manifest, ref.Namespace, ref.Name, nil
+			}
+		}
+	}
+
+	// Find the adapter for this model
+	adapter, namespace, name, err := findModelAdapter(adapterName, ref.Namespace, ref.Name)
+	if err != nil {
+		return nil, "", "", err
+	}
+	fmt.Printf("Fetching info for %s/%s@%s...\n", namespace, name, ref.Version)
+	fmt.Printf("Using %s adapter\n", adapter.Name())
+
+	manifest, err := core.ResolveManifest(ctx, adapter, namespace, name, ref.Version)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get model information: %w", err)
+	}
+	return manifest, namespace, name, nil
+}
+
+// printModelFiles lists the files of an installed model: those extracted in its
+// directory (from files.json, or hashed now if it has none) and those in its package
+func printModelFiles(cacheMgr *cache.Manager, cached *cache.CachedModel) error {
+	inventory, err := model.ReadInventory(cached.Path)
+	if err != nil {
+		if inventory, err = model.BuildInventory(cached.Path); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\nExtracted files (%s):\n", cached.Path)
+	var total int64
+	for _, entry := range inventory.Files {
+		fmt.Printf("%-10s  %-64s  %s\n", formatBytes(entry.Size), entry.SHA256, entry.Path)
+		total += entry.Size
+	}
+	fmt.Printf("%d file(s), %s\n", len(inventory.Files), formatBytes(total))
+
+	packagePath, err := cacheMgr.GetPackagePath(cached.Namespace, cached.Name, cached.Version)
+	if err != nil {
+		fmt.Printf("\nℹ️  No package in the cache\n")
+		return nil
+	}
+	fmt.Printf("\nPackage files (%s):\n", packagePath)
+	if cachecrypt.IsEncryptedDir(cached.Path) {
+		fmt.Printf("ℹ️  The package is encrypted at rest; its files are not listed\n")
+		return nil
+	}
+	return listPackage(packagePath)
+}
+
+// printComponents displays the ONNX files of an installed multi-encoder model and their roles
+func printComponents(m *types.Manifest) {
+	fmt.Printf("\nComponents (%s):\n", m.Spec.Format.MultiEncoder)
+	for _, component := range modelComponents(m) {
+		fmt.Printf("  %-16s %s\n", component.Role, component.Path)
+	}
+}
+
+// printConversion displays the conversion status and provenance of an installed model
+func printConversion(c *types.Conversion) {
+	fmt.Printf("\nConversion:\n")
+	fmt.Printf("  Status:    %s\n", c.Status)
+	if c.Target != "" {
+		fmt.Printf("  Target:    %s\n", c.Target)
+	}
+	if c.Method != "" {
+		fmt.Printf("  Method:    %s\n", c.Method)
+	}
+	if c.Image != "" {
+		fmt.Printf("  Image:     %s\n", c.Image)
+	}
+	if c.ImageDigest != "" {
+		fmt.Printf("  Digest:    %s\n", c.ImageDigest)
+	}
+	if c.Opset > 0 {
+		fmt.Printf("  Opset:     %d\n", c.Opset)
+	}
+	if c.Precision != "" {
+		fmt.Printf("  Precision: %s\n", c.Precision)
+	}
+	if c.DurationSec > 0 {
+		fmt.Printf("  Duration:  %.1fs\n", c.DurationSec)
+	}
+	if !c.ConvertedAt.IsZero() {
+		fmt.Printf("  Date:      %s\n", c.ConvertedAt.Format(time.RFC3339))
+	}
+	if c.Attempts > 1 || c.TimedOut || c.SafeMode {
+		fmt.Printf("  Attempts:  %d (timed out: %t, safe mode: %t)\n", c.Attempts, c.TimedOut, c.SafeMode)
+	}
+	if c.Cached {
+		fmt.Printf("  Cached:    outputs reused from the conversion result cache\n")
+	}
+	if c.SizeRatio > 0 {
+		fmt.Printf("  Size:      %.2fx source weights\n", c.SizeRatio)
+	}
+	if c.Parity != nil {
+		fmt.Printf("  Parity:    %s\n", parityDescription(c.Parity))
+	}
+	if c.Warning != "" {
+		fmt.Printf("  Warning:   %s\n", c.Warning)
+	}
+	if c.OOMKilled {
+		fmt.Printf("  Memory:    converter was killed for running out of memory\n")
+	}
+	if c.Error != "" {
+		fmt.Printf("  Error:     %s\n", c.Error)
+	}
+}
+
+// parityDescription summarizes a source vs converted output comparison
+func parityDescription(p *types.Parity) string {
+	switch {
+	case p.Skipped != "":
+		return fmt.Sprintf("not checked (%s)", p.Skipped)
+	case p.Passed:
+		return fmt.Sprintf("max deviation %g within tolerance %g", p.MaxAbsDiff, p.Tolerance)
+	default:
+		return fmt.Sprintf("max deviation %g exceeds tolerance %g", p.MaxAbsDiff, p.Tolerance)
+	}
+}
+
+// printProvenance displays the supply-chain record of an installed package
+func printProvenance(p *types.Provenance, path string) {
+	fmt.Printf("\nProvenance:\n")
+	fmt.Printf("  Built by:   %s %s", p.Builder.Name, p.Builder.Version)
+	if !p.BuiltAt.IsZero() {
+		fmt.Printf(" at %s", p.BuiltAt.Format(time.RFC3339))
+	}
+	fmt.Println()
+	if p.Source.Adapter != "" {
+		fmt.Printf("  Adapter:    %s\n", p.Source.Adapter)
+	}
+	if p.Source.Repository != "" {
+		fmt.Printf("  Repository: %s\n", p.Source.Repository)
+	}
+	if p.Source.Revision != "" {
+		fmt.Printf("  Revision:   %s\n", p.Source.Revision)
+	}
+	if c := p.Converter; c != nil {
+		fmt.Printf("  Converter:  %s", c.Method)
+		if c.Image != "" {
+			fmt.Printf(" (%s", c.Image)
+			if c.ImageDigest != "" {
+				fmt.Printf("@%s", c.ImageDigest)
+			}
+			fmt.Printf(")")
+		}
+		fmt.Println()
+		if c.Opset > 0 {
+			fmt.Printf("  Opset:      %d\n", c.Opset)
+		}
+		if c.Precision != "" {
+			fmt.Printf("  Precision:  %s\n", c.Precision)
+		}
+		if c.Parity != nil {
+			fmt.Printf("  Parity:     %s\n", parityDescription(c.Parity))
+		}
+	}
+	if scan := p.WeightScan; scan != nil {
+		fmt.Printf("  Weight scan: %d file(s) at %s", len(scan.Files), scan.ScannedAt.Format(time.RFC3339))
+		if len(scan.Findings) == 0 {
+			fmt.Printf(", no unsafe pickle imports")
+		}
+		fmt.Println()
+		for _, f := range scan.Findings {
+			fmt.Printf("    ⚠️  %s: %s (%s)\n", f.File, f.Import, f.Severity)
+		}
+	}
+	fmt.Printf("  Files:\n")
+	for _, file := range p.Files {
+		fmt.Printf("    - %s (%s, SHA256: %s)\n", file.Path, formatBytes(file.Size), file.SHA256)
+		if file.URL != "" {
+			fmt.Printf("      from %s\n", file.URL)
+		}
+	}
+	fmt.Printf("  Record:     %s\n", path)
+}
+
+// printToolchain displays the converter toolchain recorded for each converted artifact
+func printToolchain(c *types.Conversion) {
+	fmt.Printf("\nToolchain:\n")
+	if len(c.Artifacts) == 0 {
+		fmt.Printf("  (no converted artifacts recorded)\n")
+		return
+	}
+	for _, artifact := range c.Artifacts {
+		fmt.Printf("  %s\n", artifact.Path)
+		tc := artifact.Toolchain
+		if tc == nil {
+			fmt.Printf("    unknown (%s)\n", c.Method)
+			continue
+		}
+		for _, row := range []struct{ name, version string }{
+			{"python", tc.Python},
+			{"torch", tc.Torch},
+			{"transformers", tc.Transformers},
+			{"optimum", tc.Optimum},
+			{"onnx", tc.ONNX},
+		} {
+			if row.version == "" {
+				row.version = "not installed"
+			}
+			fmt.Printf("    %-13s %s\n", row.name+":", row.version)
+		}
+		if tc.Opset > 0 {
+			fmt.Printf("    %-13s %d\n", "opset:", tc.Opset)
+		}
+	}
 }
 
 // formatBytes formats bytes into human-readable format
@@ -616,9 +986,72 @@ func formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
+// addConversionFlags registers the ONNX export option flags on a command
+func addConversionFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("opset", 0, "ONNX opset version (default: converter default)")
+	cmd.Flags().String("task", "", "Export task, e.g. text-classification, token-classification, image-classification (default: auto-detect)")
+	cmd.Flags().Bool("dynamic-axes", true, "Export with dynamic batch and sequence axes")
+	cmd.Flags().String("precision", "", "ONNX weight precision: fp32, fp16 or int8 (default: fp32)")
+	cmd.Flags().Duration("timeout", 0, "Abort conversion after this long, e.g. 30m (default: conversion.timeout from config)")
+	cmd.Flags().String("memory", "", "Memory limit for the Docker converter, e.g. 8g (default: conversion.memory from config)")
+	cmd.Flags().String("cpus", "", "CPU limit for the Docker converter, e.g. 2 (default: conversion.cpus from config)")
+	cmd.Flags().Bool("no-conversion-cache", false, "Convert from scratch instead of reusing cached conversion outputs")
+	cmd.Flags().Bool("parity-check", false, "Compare source and ONNX outputs on a sample input and reject exports that deviate (default: conversion.parity_check from config)")
+	cmd.Flags().Float64("parity-tolerance", 0, "Largest accepted absolute output deviation for --parity-check (default: conversion.parity_tolerance from config, or 1e-3)")
+}
+
+// configuredConversionOptions returns the default conversion options with config settings applied
+func configuredConversionOptions() converter.Options {
+	opts := converter.DefaultOptions()
+	opts.Timeout = time.Duration(cfg.Conversion.Timeout) * time.Second
+	opts.RetryOnTimeout = cfg.Conversion.RetryOnTimeout
+	opts.Memory = cfg.Conversion.Memory
+	opts.CPUs = cfg.Conversion.CPUs
+	opts.ParityCheck = cfg.Conversion.ParityCheck
+	opts.ParityTolerance = cfg.Conversion.ParityTolerance
+	if cfg.Conversion.CacheResults {
+		opts.ResultCacheDir = cfg.Conversion.ResultCacheDir
+		if opts.ResultCacheDir == "" {
+			opts.ResultCacheDir = filepath.Join(cfg.CacheDir, "conversions")
+		}
+	}
+	return opts
+}
+
+// conversionOptionsFromFlags reads and validates the flags registered by addConversionFlags
+func conversionOptionsFromFlags(cmd *cobra.Command) (converter.Options, error) {
+	opts := configuredConversionOptions()
+	opts.Opset, _ = cmd.Flags().GetInt("opset")
+	opts.Task, _ = cmd.Flags().GetString("task")
+	opts.DynamicAxes, _ = cmd.Flags().GetBool("dynamic-axes")
+	opts.Precision, _ = cmd.Flags().GetString("precision")
+	if timeout, _ := cmd.Flags().GetDuration("timeout"); timeout > 0 {
+		opts.Timeout = timeout
+	}
+	if memory, _ := cmd.Flags().GetString("memory"); memory != "" {
+		opts.Memory = memory
+	}
+	if cpus, _ := cmd.Flags().GetString("cpus"); cpus != "" {
+		opts.CPUs = cpus
+	}
+	if noCache, _ := cmd.Flags().GetBool("no-conversion-cache"); noCache {
+		opts.ResultCacheDir = ""
+	}
+	if parity, _ := cmd.Flags().GetBool("parity-check"); parity {
+		opts.ParityCheck = true
+	}
+	if tolerance, _ := cmd.Flags().GetFloat64("parity-tolerance"); tolerance != 0 {
+		opts.ParityTolerance = tolerance
+	}
+	if err := opts.Validate(); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
 func installCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "install [namespace/name[@version]]",
+		Use:   "install [namespace/name[@version]...]",
 		Short: "Install a model",
 		Long: `Propagate a model through the axon pathway into your local system.
 
@@ -627,203 +1060,545 @@ The --format flag controls the target execution format:
   pytorch   Keep native PyTorch format (TorchScript .pt/.pth files)
   onnx      Convert to ONNX format
   gguf      Keep native GGUF format (for LLMs)
-  native    Skip conversion, use original format`,
-		Args: cobra.ExactArgs(1),
+  native    Skip conversion, use original format
+
+With --format auto, Axon asks MLOS Core ($MLOS_CORE_ENDPOINT) which execution formats
+it runs (see 'axon core status'). A format Core runs natively is not converted, and an
+execution-ready format Core has no runtime plugin for (e.g. TorchScript) is converted to
+ONNX. When Core isn't reachable, only formats that are not execution-ready are converted.
+
+The --onnx flag controls what happens when ONNX conversion is needed:
+  required  Fail the install if conversion fails (shows converter output)
+  prefer    Attempt conversion and record failures in the manifest (default)
+  skip      Bypass ONNX conversion entirely
+
+Use --opset, --task and --dynamic-axes to control how the model is exported to ONNX.
+Pre-converted ONNX downloads are only used when none of these are given.
+
+--precision fp16 or int8 installs smaller ONNX weights. A pre-converted file of that
+precision (model_fp16.onnx, model_quantized.onnx) is downloaded when the repository
+ships one; otherwise the export is converted to it (int8 by dynamic quantization).
+The precision is recorded in the manifest's conversion block.
+
+A version of the form sha256:<digest> pins the exact content to install, e.g.
+  axon install hf/bert-base-uncased@sha256:5546055f03398095e385d7dc625e636cc8910bf2
+The digest must match the downloaded package or its primary weight file, otherwise
+the install fails. 'axon list --format lock' prints installed models in this form.
+
+A version range installs the highest version the registry has that satisfies it:
+  axon install "myteam/bert@^1.2"
+  axon install "myteam/bert@>=2.0 <3.0"
+Prereleases (1.5.0-rc.1) are only chosen with --pre. The range is recorded with the
+installed model, and 'axon list --format lock' notes which version it resolved to.
+Ranges need a repository that lists its versions, such as an Axon registry.
+
+Models whose license is denied by policy.denied_licenses, or missing from
+policy.allowed_licenses when that is set, are refused unless --override-license-policy
+is given.
+
+Models larger than policy.max_model_size_gb, or that would take their namespace over
+its policy.namespace_quotas_gb quota, are refused before the download starts unless
+--override-size-policy is given. Sizes are those reported by the repository.
+
+Use --adapter to pick the repository adapter instead of routing by namespace, e.g.
+  axon install --adapter modelscope damo/cv_resnet50_image-classification
+A model outside the adapter's namespace is installed under it (here ms/damo/...).
+
+Several models can be installed at once by passing multiple specs and/or --file with
+one spec per line ('#' starts a comment). A batch keeps going when a model fails,
+prints a summary at the end (JSON with --json) and fails if any model failed.
+  axon install --file models.txt --concurrency 4 --json
+
+With --register (or core.auto_register in the config), each model is registered with
+MLOS Core once it is installed, as with 'axon register'. A model that is installed but
+fails to register counts as a failed install; it stays in the cache.`,
+		Args: cobra.ArbitraryArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			modelSpec := args[0]
-			namespace, name, version := parseModelSpec(modelSpec)
-			targetFormat, _ := cmd.Flags().GetString("format")
-
-			if namespace == "" || name == "" {
-				return fmt.Errorf("invalid model specification: %s (expected: namespace/name[@version])", modelSpec)
+			opts, err := installOptionsFromFlags(cmd)
+			if err != nil {
+				return err
 			}
-
-			if version == "latest" || version == "" {
-				version = "latest"
+			specs, err := installSpecs(cmd, args)
+			if err != nil {
+				return err
 			}
+			if len(specs) == 1 && !opts.json {
+				return installModel(cmd.Context(), specs[0], opts)
+			}
+			return runBatchInstall(cmd.Context(), specs, opts)
+		},
+	}
 
-			fmt.Printf("Propagating %s/%s@%s...\n", namespace, name, version)
+	cmd.Flags().StringP("format", "f", "auto", "Target format: auto, pytorch, onnx, gguf, native")
+	cmd.Flags().String("onnx", "", "ONNX conversion policy: required, prefer, skip (default: conversion.onnx_policy from config)")
+	cmd.Flags().String("file", "", "Install the model specs listed in this file (one per line)")
+	cmd.Flags().Int("concurrency", 1, "Number of models to install in parallel in a batch")
+	cmd.Flags().Bool("fail-fast", false, "Stop starting new installs after the first failure in a batch")
+	cmd.Flags().Bool("json", false, "Print the batch summary as JSON (progress goes to stderr)")
+	cmd.Flags().Bool(overrideLicensePolicyFlag, false, "Install even if the model's license violates policy.allowed_licenses or policy.denied_licenses")
+	cmd.Flags().Bool(overrideSizePolicyFlag, false, "Install even if the model exceeds policy.max_model_size_gb or its namespace quota")
+	cmd.Flags().String("adapter", "", "Repository adapter to use instead of routing by namespace (see 'axon adapters list')")
+	cmd.Flags().Bool("register", false, "Register the model with MLOS Core after installing it (default: core.auto_register from config)")
+	cmd.Flags().Bool("pre", false, "Let version ranges resolve to prerelease versions")
+	addConversionFlags(cmd)
+	return cmd
+}
 
-			// Check if already cached
-			cacheMgr := cache.NewManager(cfg.CacheDir)
-			if cacheMgr.IsModelCached(namespace, name, version) {
-				fmt.Printf("✓ Model %s/%s@%s already installed\n", namespace, name, version)
-				return nil
-			}
+// installModel installs a single model spec
+func installModel(ctx context.Context, modelSpec string, opts installOptions) (err error) {
+	start := time.Now()
+	ctx, span := telemetry.Start(ctx, "install", telemetry.String("model", modelSpec))
+	defer func() {
+		span.End(err)
+		telemetry.InstallDuration.Observe(time.Since(start).Seconds(), telemetry.Result(err))
+		recordHistory(history.ActionInstall, modelSpec, cachedPackageDigest(modelSpec), err, start)
+	}()
 
-			// Try to find adapter for this model
-			adapterRegistry := core.NewAdapterRegistry()
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return err
+	}
+	namespace, name, version := ref.Namespace, ref.Name, ref.Version
+	targetFormat, onnxPolicy, convOpts := opts.format, opts.onnxPolicy, opts.conversion
 
-			// Register adapters using builtin registration
-			builtin.RegisterDefaultAdapters(adapterRegistry, cfg.Registry.URL, cfg.Registry.Mirrors, cfg.Registry.HuggingFaceToken, cfg.Registry.EnableHuggingFace)
+	// A sha256: version pins content rather than a version: fetch latest and verify it
+	pin, pinned := ref.Digest, ref.Kind == spec.KindDigest
+	if pinned {
+		version = spec.Latest
+	}
 
-			// Find the best adapter
-			adapter, err := adapterRegistry.FindAdapter(namespace, name)
-			if err != nil {
-				return fmt.Errorf("no repository adapter found for %s/%s: %w", namespace, name, err)
+	// A forced adapter may move the model under its namespace, which decides where it's cached
+	var adapter core.RepositoryAdapter
+	if ref.Registry != "" {
+		if opts.adapter != "" {
+			return fmt.Errorf("%s names a registry; it can't be installed with --adapter %s", modelSpec, opts.adapter)
+		}
+		adapter = registryAdapter(ref.Registry)
+	} else if opts.adapter != "" {
+		if adapter, namespace, name, err = findModelAdapter(opts.adapter, namespace, name); err != nil {
+			return err
+		}
+	}
+
+	// A version range resolves to the highest matching version the repository has
+	if ref.Kind == spec.KindRange {
+		if adapter == nil {
+			if adapter, _, _, err = findModelAdapter("", namespace, name); err != nil {
+				return err
 			}
+		}
+		if version, err = resolveVersionRange(ctx, adapter, namespace, name, ref, opts.pre); err != nil {
+			return err
+		}
+		fmt.Printf("🔎 Resolved %s/%s@%s to %s\n", namespace, name, ref.Version, version)
+	}
 
-			fmt.Printf("Using %s adapter for %s/%s\n", adapter.Name(), namespace, name)
+	fmt.Printf("Propagating %s/%s@%s...\n", namespace, name, version)
+	if pinned {
+		fmt.Printf("📌 Pinned to digest %s%s\n", model.DigestPinPrefix, pin)
+	}
 
-			// Get manifest
-			manifest, err := adapter.GetManifest(cmd.Context(), namespace, name, version)
-			if err != nil {
-				return fmt.Errorf("failed to get manifest: %w", err)
+	// Check if already cached
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	if cacheMgr.IsModelCached(namespace, name, version) {
+		if pinned {
+			if err := verifyCachedDigestPin(cacheMgr.GetModelPath(namespace, name, version), pin); err != nil {
+				return fmt.Errorf("installed %s/%s@%s does not match pin: %w", namespace, name, version, err)
 			}
+		}
+		telemetry.CacheLookups.Inc(telemetry.ResultHit)
+		fmt.Printf("✓ Model %s/%s@%s already installed\n", namespace, name, version)
+		if opts.register {
+			return registerInstalled(ctx, fmt.Sprintf("%s/%s@%s", namespace, name, version))
+		}
+		return nil
+	}
 
-			// Download package to temp location first
-			// Use safeTempFileName to handle model IDs with slashes (e.g., "hf/microsoft/resnet-50")
-			tmpFile := filepath.Join(os.TempDir(), safeTempFileName(namespace, name, version))
-			fmt.Printf("📦 Package will be created at: %s\n", tmpFile)
+	telemetry.CacheLookups.Inc(telemetry.ResultMiss)
 
-			progress := func(downloaded, total int64) {
-				if total > 0 {
-					percent := float64(downloaded) / float64(total) * 100
-					fmt.Printf("\rDownloading... %.1f%% (%d/%d bytes)", percent, downloaded, total)
-				} else {
-					fmt.Printf("\rDownloading... %d bytes", downloaded)
-				}
-			}
+	// Find the best adapter for this model
+	if adapter == nil {
+		if adapter, _, _, err = findModelAdapter("", namespace, name); err != nil {
+			return err
+		}
+	}
 
-			fmt.Println("Downloading package...")
-			if err := adapter.DownloadPackage(cmd.Context(), manifest, tmpFile, progress); err != nil {
-				return fmt.Errorf("failed to download package: %w", err)
-			}
-			fmt.Println()
+	fmt.Printf("Using %s adapter for %s/%s\n", adapter.Name(), namespace, name)
 
-			// Verify package was created
-			if stat, err := os.Stat(tmpFile); err == nil {
-				fmt.Printf("✓ Package created: %s (size: %d bytes)\n", tmpFile, stat.Size())
-			}
+	// Get manifest (memoized, so parallel installs of shared dependencies fetch it once)
+	manifest, err := core.ResolveManifest(ctx, adapter, namespace, name, version)
+	// Gated models can be installed once the user gives a token with access
+	var gated *core.GatedModelError
+	if errors.As(err, &gated) && promptGatedToken(adapter, gated) {
+		manifest, err = core.ResolveManifest(ctx, adapter, namespace, name, version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get manifest: %w", err)
+	}
 
-			// Cache model (saves manifest and metadata, and moves package to cache)
-			cachePath := cacheMgr.GetModelPath(namespace, name, version)
-			fmt.Printf("📁 Cache directory: %s\n", cachePath)
+	// Block licenses the organization doesn't allow before downloading anything
+	if err := checkLicensePolicy(cfg.Policy, manifest, opts.overrideLicensePolicy); err != nil {
+		return fmt.Errorf("refusing to install %s/%s@%s: %w (use --%s to install anyway)", namespace, name, version, err, overrideLicensePolicyFlag)
+	}
 
-			if err := cacheMgr.CacheModel(namespace, name, version, manifest); err != nil {
-				return fmt.Errorf("failed to cache model: %w", err)
-			}
+	// Block models over the size limits before starting a download that can't finish
+	if err := checkSizePolicy(cfg.Policy, cfg.Tenant, cacheMgr, namespace, manifest, opts.overrideSizePolicy); err != nil {
+		return fmt.Errorf("refusing to install %s/%s@%s: %w (use --%s to install anyway)", namespace, name, version, err, overrideSizePolicyFlag)
+	}
 
-			// Move package from temp to cache
-			cachePackagePath := filepath.Join(cachePath, filepath.Base(tmpFile))
-			if err := os.Rename(tmpFile, cachePackagePath); err != nil {
-				// If rename fails (cross-device), try copy
-				if err := copyFile(tmpFile, cachePackagePath); err != nil {
-					return fmt.Errorf("failed to move package to cache: %w", err)
-				}
-				_ = os.Remove(tmpFile) // Clean up temp file after copy
+	// Download package to temp location first
+	// Use safeTempFileName to handle model IDs with slashes (e.g., "hf/microsoft/resnet-50")
+	tmpFile := filepath.Join(utils.TempDir(), safeTempFileName(namespace, name, version))
+	fmt.Printf("📦 Package will be created at: %s\n", tmpFile)
+
+	// Journal the install, so a failed or interrupted one is rolled back rather than
+	// leaving a model that looks installed
+	cachePath := cacheMgr.GetModelPath(namespace, name, version)
+	tx := beginJournal(journal.OpInstall, fmt.Sprintf("%s/%s@%s", namespace, name, version), cachePath)
+	defer func() { endInstallJournal(cacheMgr, tx, err) }()
+	journalTrack(tx, tmpFile, cachePath)
+
+	progress := func(downloaded, total int64) {
+		if opts.progress != nil {
+			opts.progress(downloaded, total)
+		}
+		if total > 0 {
+			percent := float64(downloaded) / float64(total) * 100
+			fmt.Printf("\rDownloading... %.1f%% (%d/%d bytes)", percent, downloaded, total)
+		} else {
+			fmt.Printf("\rDownloading... %d bytes", downloaded)
+		}
+	}
+
+	fmt.Println("Downloading package...")
+	downloadCtx, downloadSpan := telemetry.Start(ctx, "download", telemetry.String("adapter", adapter.Name()))
+	downloadStart := time.Now()
+	err = adapter.DownloadPackage(downloadCtx, manifest, tmpFile, progress)
+	downloadSpan.End(err)
+	if err != nil {
+		return fmt.Errorf("failed to download package: %w", err)
+	}
+	fmt.Println()
+	journalStep(tx, stepDownloaded)
+
+	// Verify package was created
+	if stat, err := os.Stat(tmpFile); err == nil {
+		telemetry.DownloadBytes.Add(float64(stat.Size()))
+		telemetry.DownloadDuration.Observe(time.Since(downloadStart).Seconds())
+		downloadSpan.SetAttributes(telemetry.Int("bytes", stat.Size()))
+		fmt.Printf("✓ Package created: %s (size: %d bytes)\n", tmpFile, stat.Size())
+	}
+
+	// Reject tampered packages and packages not signed by a trusted key
+	if err := checkPackageSignature(cfg.Security, manifest, tmpFile); err != nil {
+		_ = os.Remove(tmpFile)
+		return fmt.Errorf("refusing to install %s/%s: %w", namespace, name, err)
+	}
+
+	// Cache model (saves manifest and metadata, and moves package to cache)
+	fmt.Printf("📁 Cache directory: %s\n", cachePath)
+
+	if err := cacheMgr.CacheModel(namespace, name, version, manifest); err != nil {
+		return fmt.Errorf("failed to cache model: %w", err)
+	}
+	if ref.Kind == spec.KindRange {
+		if err := cache.WriteConstraint(cachePath, ref.Version); err != nil {
+			return fmt.Errorf("failed to cache model: %w", err)
+		}
+	}
+
+	// Move package from temp to cache
+	cachePackagePath := filepath.Join(cachePath, filepath.Base(tmpFile))
+	// A rename when the temp directory is on the cache's filesystem, else a copy
+	if err := utils.MoveFile(tmpFile, cachePackagePath); err != nil {
+		return fmt.Errorf("failed to move package to cache: %w", err)
+	}
+	fmt.Printf("✓ Package moved to cache: %s\n", cachePackagePath)
+	journalStep(tx, stepCached)
+
+	// Extract package to cache directory so Core (and ONNX conversion) can find model files
+	// The package is a tar.gz file - we need to extract it
+	if !cfg.Cache.AutoExtract {
+		if pinned {
+			// Without extracted files only the package digest can be checked
+			if err := checkDigestPin(pin, cachePackagePath, ""); err != nil {
+				_ = cacheMgr.RemoveModel(namespace, name, version)
+				return fmt.Errorf("refusing to install %s/%s: %w", namespace, name, err)
 			}
-			fmt.Printf("✓ Package moved to cache: %s\n", cachePackagePath)
+		}
+		// The weights aren't loaded until the package is extracted, but a blocked model
+		// shouldn't be in the cache at all
+		if err := checkPackageWeightScanPolicy(cfg.Security, cachePackagePath); err != nil {
+			_ = cacheMgr.RemoveModel(namespace, name, version)
+			return fmt.Errorf("refusing to install %s/%s@%s: %w", namespace, name, version, err)
+		}
+		journalStep(tx, stepInstalled)
+		fmt.Printf("✓ Skipping extraction (cache.auto_extract is disabled)\n")
+		fmt.Printf("   💡 Run 'axon extract %s/%s@%s' to unpack model files\n", namespace, name, version)
+		fmt.Printf("\n✓ Successfully propagated %s/%s@%s\n", namespace, name, version)
+		fireHooks(ctx, hooks.EventInstallComplete, fmt.Sprintf("%s/%s@%s", namespace, name, version), cachePath, manifest, nil)
+		if opts.register {
+			fmt.Printf("⚠️  Not registering with MLOS Core: model files are not extracted (cache.auto_extract is disabled)\n")
+		}
+		return nil
+	}
+	if err := model.ExtractPackage(cachePackagePath, cachePath); err != nil {
+		return fmt.Errorf("failed to extract package: %w", err)
+	}
+	fmt.Printf("✓ Package extracted to: %s\n", cachePath)
+	journalStep(tx, stepExtracted)
+
+	// Verify the pin before conversion rewrites the package
+	if pinned {
+		if err := checkDigestPin(pin, cachePackagePath, cachePath); err != nil {
+			_ = cacheMgr.RemoveModel(namespace, name, version)
+			return fmt.Errorf("refusing to install %s/%s: %w", namespace, name, err)
+		}
+	}
 
-			// Extract package to cache directory for ONNX conversion
-			// The package is a tar.gz file - we need to extract it
-			if err := extractPackage(cachePackagePath, cachePath); err != nil {
-				return fmt.Errorf("failed to extract package: %w", err)
+	// A truncated tokenizer.json passes install but breaks inference in Core; catch it now
+	if err := validateTokenizer(cachePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return fmt.Errorf("tokenizer validation failed for %s/%s (corrupt or truncated download?): %w", namespace, name, err)
+	}
+
+	// Models without the tokenizer or preprocessor config their task needs install fine
+	// but can't serve requests
+	if manifest.Spec.Task == "" {
+		manifest.Spec.Task = model.DetectDirTask(cachePath)
+	}
+	if err := checkAssetPolicy(cfg.Policy, cachePath, manifest.Spec.Task); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return fmt.Errorf("refusing to install %s/%s@%s: %w", namespace, name, version, err)
+	}
+
+	// Pickle-based weights run what they import when loaded, by the converter or by Core;
+	// scan them before either does
+	if err := checkWeightScanPolicy(cfg.Security, cachePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return fmt.Errorf("refusing to install %s/%s@%s: %w", namespace, name, version, err)
+	}
+
+	// Handle format conversion based on --format flag
+	// pytorch/native: skip conversion, use original format
+	// gguf: already execution-ready
+	// onnx: convert to ONNX if not already
+	// auto: auto-detect and convert if needed, asking a running MLOS Core what it executes
+	skipConversion := targetFormat == "pytorch" || targetFormat == "native"
+	if skipConversion {
+		fmt.Printf("✓ Format '%s' requested - skipping ONNX conversion\n", targetFormat)
+		// Set execution format to match the model's original format
+		if targetFormat == "pytorch" {
+			manifest.Spec.Format.ExecutionFormat = "pytorch"
+		} else {
+			// native - keep original format
+			if manifest.Spec.Format.ExecutionFormat == "" {
+				manifest.Spec.Format.ExecutionFormat = manifest.Spec.Format.Type
 			}
+		}
+	} else if onnxPolicy == converter.ONNXPolicySkip {
+		fmt.Printf("✓ ONNX policy 'skip' - bypassing ONNX conversion\n")
+		manifest.Spec.Conversion = &types.Conversion{Status: converter.ConversionStatusSkipped, Target: "onnx"}
+	} else if convert, reason := installConversionNeeded(ctx, targetFormat, manifest.Spec.Format.ExecutionFormat, cachePath); !convert {
+		fmt.Printf("✓ %s, skipping ONNX conversion\n", reason)
+		manifest.Spec.Conversion = &types.Conversion{Status: converter.ConversionStatusNotNeeded, Target: manifest.Spec.Format.ExecutionFormat}
+	} else {
+		if reason != "" {
+			fmt.Printf("ℹ️  %s\n", reason)
+		}
+		// Attempt ONNX conversion (pure Go first, Python optional)
+		// This adds model.onnx (or multiple ONNX files for multi-encoder models)
+		onnxPath := filepath.Join(cachePath, "model.onnx")
+		modelID := conversionModelID(namespace, name)
+
+		// Export for the model's task, so vision and speech models get the right head
+		if manifest.Spec.Task == "" {
+			manifest.Spec.Task = model.DetectDirTask(cachePath)
+		}
+		convOpts.ModelTask = manifest.Spec.Task
 
-			// Handle format conversion based on --format flag
-			// pytorch/native: skip conversion, use original format
-			// gguf: already execution-ready
-			// onnx: convert to ONNX if not already
-			// auto: auto-detect and convert if needed
-			skipConversion := targetFormat == "pytorch" || targetFormat == "native"
-			if skipConversion {
-				fmt.Printf("✓ Format '%s' requested - skipping ONNX conversion\n", targetFormat)
-				// Set execution format to match the model's original format
-				if targetFormat == "pytorch" {
-					manifest.Spec.Format.ExecutionFormat = "pytorch"
-				} else {
-					// native - keep original format
-					if manifest.Spec.Format.ExecutionFormat == "" {
-						manifest.Spec.Format.ExecutionFormat = manifest.Spec.Format.Type
-					}
-				}
-			} else if converter.IsExecutionReadyWithPath(manifest.Spec.Format.ExecutionFormat, cachePath) {
-				// Check if format is already execution-ready (GGUF, ONNX, PyTorch)
-				// These formats can be used directly by MLOS Core without conversion
-				// IMPORTANT: We verify actual files exist on disk, not just trust manifest
-				fmt.Printf("✓ Format '%s' is execution-ready (verified files exist), skipping ONNX conversion\n", manifest.Spec.Format.ExecutionFormat)
-			} else {
-				// Attempt ONNX conversion (pure Go first, Python optional)
-				// This adds model.onnx (or multiple ONNX files for multi-encoder models)
-				onnxPath := filepath.Join(cachePath, "model.onnx")
-				modelID := fmt.Sprintf("%s/%s", namespace, name)
-				if namespace == "hf" {
-					// For Hugging Face, use just the model name
-					modelID = name
+		convCtx, convSpan := telemetry.Start(ctx, "convert", telemetry.String("framework", manifest.Spec.Framework.Name))
+		convResult, err := converter.ConvertToONNXWithResult(convCtx, cachePath, manifest.Spec.Framework.Name, namespace, modelID, onnxPath, convOpts)
+		if err == nil && !convResult.Success {
+			err = fmt.Errorf("no ONNX converter available (install Docker or Python 3 with transformers and torch)")
+		}
+		if err != nil {
+			if onnxPolicy == converter.ONNXPolicyRequired {
+				conversionFinished(ctx, convSpan, fmt.Sprintf("%s/%s@%s", namespace, name, version), cachePath, manifest, err)
+				// Don't leave a half-installed model behind that looks installed
+				_ = cacheMgr.RemoveModel(namespace, name, version)
+				return fmt.Errorf("ONNX conversion required but failed for %s/%s@%s: %w", namespace, name, version, err)
+			}
+			// Conversion error - log but don't fail (model still works without ONNX)
+			fmt.Printf("⚠️  ONNX conversion failed: %v\n", err)
+			fmt.Printf("   Model will work with framework-specific plugins\n")
+			manifest.Spec.Conversion = conversionRecord(convResult, err, cachePath)
+		} else {
+			manifest.Spec.Conversion = conversionRecord(convResult, nil, cachePath)
+			if convResult.IsMultiEncoder {
+				fmt.Printf("✅ Multi-encoder ONNX conversion successful (%s)\n", convResult.Architecture)
+				fmt.Printf("   Created %d ONNX files:\n", len(convResult.AllFiles))
+				for _, f := range convResult.AllFiles {
+					fmt.Printf("     - %s\n", filepath.Base(f))
 				}
-
-				convResult, err := converter.ConvertToONNXWithResult(cmd.Context(), cachePath, manifest.Spec.Framework.Name, namespace, modelID, onnxPath)
-				if err != nil {
-					// Conversion error - log but don't fail (model still works without ONNX)
-					fmt.Printf("⚠️  ONNX conversion failed: %v\n", err)
-					fmt.Printf("   Model will work with framework-specific plugins\n")
-				} else if convResult.Success {
-					if convResult.IsMultiEncoder {
-						fmt.Printf("✅ Multi-encoder ONNX conversion successful (%s)\n", convResult.Architecture)
-						fmt.Printf("   Created %d ONNX files:\n", len(convResult.AllFiles))
-						for _, f := range convResult.AllFiles {
-							fmt.Printf("     - %s\n", filepath.Base(f))
-						}
-						// Update manifest to indicate multi-encoder architecture
-						if manifest.Spec.Format.ExecutionFormat == "" {
-							manifest.Spec.Format.ExecutionFormat = "onnx"
-						}
-						manifest.Spec.Format.MultiEncoder = convResult.Architecture
-					} else {
-						fmt.Printf("✅ ONNX conversion successful: %s\n", convResult.PrimaryFile)
-					}
-					// Rebuild package with all ONNX files included
-					if err := rebuildPackageWithONNX(cachePath, cachePackagePath); err != nil {
-						fmt.Printf("⚠️  Failed to rebuild package with ONNX: %v\n", err)
-						fmt.Printf("   ONNX files are available in cache directory\n")
-					} else {
-						fmt.Printf("✅ Package rebuilt with ONNX file(s) included\n")
-					}
+				// Update manifest to indicate multi-encoder architecture
+				if manifest.Spec.Format.ExecutionFormat == "" {
+					manifest.Spec.Format.ExecutionFormat = "onnx"
 				}
+				manifest.Spec.Format.MultiEncoder = convResult.Architecture
+			} else {
+				fmt.Printf("✅ ONNX conversion successful: %s\n", convResult.PrimaryFile)
 			}
-
-			// Update manifest with execution format and I/O schema after extraction/conversion
-			// This ensures manifest reflects actual model files
-			if err := updateManifestAfterInstall(cachePath, manifest); err != nil {
-				fmt.Printf("⚠️  Failed to update manifest: %v\n", err)
+			// Rebuild package with all ONNX files included
+			if _, err := rebuildPackageWithONNX(cachePath, cachePackagePath, manifest.Spec.Conversion); err != nil {
+				fmt.Printf("⚠️  Failed to rebuild package with ONNX: %v\n", err)
+				fmt.Printf("   ONNX files are available in cache directory\n")
 			} else {
-				// Save updated manifest
-				manifestPath := filepath.Join(cachePath, "manifest.yaml")
-				if err := saveManifest(manifest, manifestPath); err != nil {
-					fmt.Printf("⚠️  Failed to save updated manifest: %v\n", err)
-				} else {
-					fmt.Printf("✓ Manifest updated with execution_format: %s\n", manifest.Spec.Format.ExecutionFormat)
-				}
+				fmt.Printf("✅ Package rebuilt with ONNX file(s) included\n")
 			}
+		}
+		conversionFinished(ctx, convSpan, fmt.Sprintf("%s/%s@%s", namespace, name, version), cachePath, manifest, err)
+	}
 
-			fmt.Printf("\n✓ Successfully propagated %s/%s@%s\n", namespace, name, version)
-			return nil
-		},
+	// Rearrange the files into the canonical layout Core discovers model files in
+	if err := normalizeModelLayout(cachePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return fmt.Errorf("failed to install %s/%s@%s: %w", namespace, name, version, err)
 	}
 
-	cmd.Flags().StringP("format", "f", "auto", "Target format: auto, pytorch, onnx, gguf, native")
-	return cmd
+	// Update manifest with execution format and I/O schema after extraction/conversion
+	// This ensures manifest reflects actual model files
+	if err := updateManifestAfterInstall(cachePath, manifest); err != nil {
+		fmt.Printf("⚠️  Failed to update manifest: %v\n", err)
+	} else {
+		// Save updated manifest
+		manifestPath := filepath.Join(cachePath, "manifest.yaml")
+		if err := saveManifest(manifest, manifestPath); err != nil {
+			fmt.Printf("⚠️  Failed to save updated manifest: %v\n", err)
+		} else {
+			fmt.Printf("✓ Manifest updated with execution_format: %s\n", manifest.Spec.Format.ExecutionFormat)
+		}
+	}
+
+	// Record an integrity manifest of the extracted tree so 'axon verify' can check it
+	if inventory, err := model.WriteInventory(cachePath); err != nil {
+		fmt.Printf("⚠️  Failed to write %s: %v\n", model.InventoryFileName, err)
+	} else {
+		fmt.Printf("✓ Recorded %d extracted file(s) in %s\n", len(inventory.Files), model.InventoryFileName)
+		recordIntegrity(cacheMgr, cachePath, inventory)
+	}
+
+	// Encrypt weights at rest after the inventory, so it records plaintext digests
+	if cfg.Cache.Encryption.Enabled {
+		n, err := encryptModelFiles(cachePath)
+		if err != nil {
+			_ = cacheMgr.RemoveModel(namespace, name, version)
+			return fmt.Errorf("failed to encrypt %s/%s@%s (removed from cache): %w", namespace, name, version, err)
+		}
+		fmt.Printf("🔒 Encrypted %d weight file(s) at rest\n", n)
+	}
+	journalStep(tx, stepInstalled)
+
+	fmt.Printf("\n✓ Successfully propagated %s/%s@%s\n", namespace, name, version)
+	fireHooks(ctx, hooks.EventInstallComplete, fmt.Sprintf("%s/%s@%s", namespace, name, version), cachePath, manifest, nil)
+	if opts.register {
+		return registerInstalled(ctx, fmt.Sprintf("%s/%s@%s", namespace, name, version))
+	}
+	return nil
+}
+
+// registerInstallMu serializes registrations from concurrent batch installs and 'axon
+// serve' requests, which all update the same registrations file
+var registerInstallMu sync.Mutex
+
+// registerInstalled registers a model with MLOS Core right after 'axon install' cached
+// it (--register or core.auto_register). A failed registration fails the install, but
+// the model stays installed.
+func registerInstalled(ctx context.Context, modelID string) error {
+	registerInstallMu.Lock()
+	defer registerInstallMu.Unlock()
+
+	fmt.Printf("\n")
+	if err := modelRegistrar(ctx, modelID, false, false); err != nil {
+		return fmt.Errorf("%s is installed but could not be registered with MLOS Core: %w", modelID, err)
+	}
+	return nil
 }
 
 func listCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List installed models",
-		Long:  "List all active pathways (installed models)",
+		Long: `List all active pathways (installed models)
+
+The table shows each model's size on disk, execution format, the runtime files it has
+(ONNX, GGUF), whether it is registered with MLOS Core and when it was last used
+(registered or converted, or else installed).
+
+--sort orders the models by name (the default), size, last-used or format. --filter
+keeps the models matching key=value, and may be repeated:
+
+  namespace=hf       models in a namespace
+  name=bert          models whose name contains a string
+  format=onnx        models with an execution format
+  converted=gguf     models with ONNX or GGUF files on disk (none: neither)
+  registered=true    models registered with MLOS Core (false: not registered)
+
+With --registered, only the models registered with MLOS Core by 'axon register' are
+listed, with the Core endpoint, the model ID in Core and when they were registered.
+
+Examples:
+  axon list --sort size
+  axon list --filter namespace=hf --filter converted=none`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			format, _ := cmd.Flags().GetString("format")
-			cacheMgr := cache.NewManager(cfg.CacheDir)
+			registered, _ := cmd.Flags().GetBool("registered")
+			sortBy, _ := cmd.Flags().GetString("sort")
+			filterValues, _ := cmd.Flags().GetStringArray("filter")
+			if !slices.Contains(listSortKeys, sortBy) {
+				return fmt.Errorf("invalid sort key %q (expected one of %s)", sortBy, strings.Join(listSortKeys, ", "))
+			}
+			var filters []listFilter
+			for _, value := range filterValues {
+				filter, err := parseListFilter(value)
+				if err != nil {
+					return err
+				}
+				filters = append(filters, filter)
+			}
+
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
 			models, err := cacheMgr.ListCachedModels()
 			if err != nil {
 				return fmt.Errorf("failed to list models: %w", err)
 			}
+			if registered {
+				models = registeredModels(models)
+			}
 
-			if len(models) == 0 {
+			// Sizes and files are only read for the formats, sorts and filters using them
+			var listings []modelListing
+			if format == "default" || format == "json" || sortBy != "name" || len(filters) > 0 {
+				listings = describeModels(cacheMgr, models)
+			} else {
+				for _, model := range models {
+					listings = append(listings, modelListing{CachedModel: model})
+				}
+			}
+			listings = filterListings(listings, filters)
+			if err := sortListings(listings, sortBy); err != nil {
+				return err
+			}
+
+			if len(listings) == 0 {
 				if format == "json" {
 					fmt.Println("[]")
-				} else if format == "names" {
+				} else if format == "names" || format == "lock" {
 					// Empty output for names format
+				} else if len(filters) > 0 {
+					fmt.Println("No installed models match the filters.")
+				} else if registered {
+					fmt.Println("No models registered with MLOS Core.")
 				} else {
 					fmt.Println("No models installed.")
 				}
@@ -833,16 +1608,29 @@ func listCmd() *cobra.Command {
 			switch format {
 			case "json":
 				// Output as JSON array
-				jsonData, err := json.MarshalIndent(models, "", "  ")
+				jsonData, err := json.MarshalIndent(listings, "", "  ")
 				if err != nil {
 					return fmt.Errorf("failed to marshal models: %w", err)
 				}
 				fmt.Println(string(jsonData))
+			case "lock":
+				// Output digest-pinned specs suitable for an axon.lock file
+				for _, m := range listings {
+					spec, err := lockedModelSpec(m.CachedModel)
+					if err != nil {
+						return err
+					}
+					// Note the range a version was resolved from, as a comment
+					if constraint, _ := cache.ReadConstraint(m.Path); constraint != "" {
+						spec += fmt.Sprintf("  # %s -> %s", constraint, m.Version)
+					}
+					fmt.Println(spec)
+				}
 			case "names":
 				// Output just namespace/name (one per line, no version)
 				// Deduplicate by namespace/name combination
 				seen := make(map[string]bool)
-				for _, model := range models {
+				for _, model := range listings {
 					key := fmt.Sprintf("%s/%s", model.Namespace, model.Name)
 					if !seen[key] {
 						fmt.Printf("%s/%s\n", model.Namespace, model.Name)
@@ -850,129 +1638,649 @@ func listCmd() *cobra.Command {
 					}
 				}
 			default:
-				// Default format (original behavior)
+				if registered {
+					fmt.Println("Registered with MLOS Core:")
+					fmt.Println()
+					for _, model := range listings {
+						reg := model.Registration
+						fmt.Printf("  %s/%s@%s\n", model.Namespace, model.Name, model.Version)
+						fmt.Printf("     %s at %s (registered %s)\n", reg.ModelID, reg.Endpoint, reg.RegisteredAt)
+					}
+					break
+				}
 				fmt.Println("Active pathways:")
 				fmt.Println()
-				for _, model := range models {
-					fmt.Printf("  %s/%s@%s\n", model.Namespace, model.Name, model.Version)
-				}
+				printListingTable(listings, time.Now())
 			}
 
 			return nil
 		},
 	}
 
-	cmd.Flags().StringP("format", "f", "default", "Output format: default, names, or json")
+	cmd.Flags().StringP("format", "f", "default", "Output format: default, names, json, or lock (digest-pinned specs)")
+	cmd.Flags().Bool("registered", false, "Only list models registered with MLOS Core, with their registration")
+	cmd.Flags().String("sort", "name", "Sort by: "+strings.Join(listSortKeys, ", "))
+	cmd.Flags().StringArray("filter", nil, "Only list models matching key=value (keys: "+strings.Join(listFilterKeys, ", ")+"); repeatable")
 	return cmd
 }
 
+// registeredModels keeps the models with a recorded MLOS Core registration
+func registeredModels(models []cache.CachedModel) []cache.CachedModel {
+	var registered []cache.CachedModel
+	for _, model := range models {
+		if model.Registration != nil {
+			registered = append(registered, model)
+		}
+	}
+	return registered
+}
+
 func uninstallCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "uninstall [namespace/name]",
+	var all, dryRun, yes bool
+
+	cmd := &cobra.Command{
+		Use:   "uninstall [namespace/name[@version]]",
 		Short: "Uninstall a model",
-		Long:  "Prune a model pathway from your local system",
-		Args:  cobra.ExactArgs(1),
+		Long: `Prune a model pathway from your local system.
+
+Specify a version (namespace/name@version) to remove a single version, or --all to
+remove every installed version. A model with a single installed version can be
+removed without either.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			modelSpec := args[0]
-			namespace, name, _ := parseModelSpec(modelSpec)
-
-			if namespace == "" || name == "" {
-				return fmt.Errorf("invalid model specification: %s (expected: namespace/name)", modelSpec)
+			ref, err := spec.Parse(modelSpec)
+			if err != nil {
+				return err
+			}
+			namespace, name, version := ref.Namespace, ref.Name, ref.Version
+			if !ref.HasVersion {
+				version = ""
+			}
+			if version != "" && all {
+				return fmt.Errorf("--all cannot be combined with a version (%s)", modelSpec)
 			}
 
-			cacheMgr := cache.NewManager(cfg.CacheDir)
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
 
-			// List all versions if no version specified
 			models, err := cacheMgr.ListCachedModels()
 			if err != nil {
 				return fmt.Errorf("failed to list models: %w", err)
 			}
 
-			var toRemove []cache.CachedModel
-			for _, model := range models {
-				if model.Namespace == namespace && model.Name == name {
-					toRemove = append(toRemove, model)
+			toRemove, err := selectUninstallTargets(models, namespace, name, version, all)
+			if err != nil {
+				return err
+			}
+			if len(toRemove) == 0 {
+				fmt.Printf("Model %s not found\n", modelSpec)
+				return nil
+			}
+
+			var reclaimed int64
+			for _, model := range toRemove {
+				size, err := cacheMgr.GetModelSize(model.Namespace, model.Name, model.Version)
+				if err != nil {
+					return fmt.Errorf("failed to measure %s/%s@%s: %w", model.Namespace, model.Name, model.Version, err)
 				}
+				reclaimed += size
+				fmt.Printf("  %s/%s@%s (%s)\n", model.Namespace, model.Name, model.Version, formatBytes(size))
 			}
 
-			if len(toRemove) == 0 {
-				fmt.Printf("Model %s/%s not found\n", namespace, name)
+			if dryRun {
+				fmt.Printf("🔍 Dry run: would remove %d version(s), reclaiming %s\n", len(toRemove), formatBytes(reclaimed))
+				return nil
+			}
+			if !yes && !confirm(os.Stdin, fmt.Sprintf("Remove %d version(s), reclaiming %s?", len(toRemove), formatBytes(reclaimed))) {
+				fmt.Println("Aborted")
 				return nil
 			}
 
 			for _, model := range toRemove {
-				if err := cacheMgr.RemoveModel(model.Namespace, model.Name, model.Version); err != nil {
-					return fmt.Errorf("failed to remove %s/%s@%s: %w", model.Namespace, model.Name, model.Version, err)
+				if err := removeCachedModel(cacheMgr, model); err != nil {
+					return err
 				}
 				fmt.Printf("✓ Pruned pathway: %s/%s@%s\n", model.Namespace, model.Name, model.Version)
 			}
+			fmt.Printf("✓ Reclaimed %s\n", formatBytes(reclaimed))
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Remove all installed versions of the model")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be removed without removing anything")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+
+	return cmd
+}
+
+// removeCachedModel removes a cached model version, records it in the history and
+// forgets its registrations and decrypted copy
+func removeCachedModel(cacheMgr *cache.Manager, model cache.CachedModel) error {
+	ref := spec.NewModelRef(model.Namespace, filepath.ToSlash(model.Name), model.Version)
+	spec := fmt.Sprintf("%s/%s@%s", model.Namespace, model.Name, model.Version)
+	digest := cachedPackageDigest(spec)
+	m, _ := loadManifest(filepath.Join(model.Path, "manifest.yaml"))
+	tx := beginJournal(journal.OpUninstall, ref.String(), model.Path)
+	defer func() { _ = tx.Finish() }()
+	start := time.Now()
+	err := cacheMgr.RemoveModel(model.Namespace, model.Name, model.Version)
+	recordHistory(history.ActionUninstall, spec, digest, err, start)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", spec, err)
+	}
+	fireHooks(context.Background(), hooks.EventUninstall, spec, model.Path, m, nil)
+	forgetUninstalled(cacheMgr, ref, model.Path)
+	return nil
+}
+
+// selectUninstallTargets picks the cached versions of namespace/name to remove: the given
+// version, every version with all, or the only installed version. Removing one of several
+// versions without naming it is ambiguous and returns an error listing them.
+func selectUninstallTargets(models []cache.CachedModel, namespace, name, version string, all bool) ([]cache.CachedModel, error) {
+	var matches []cache.CachedModel
+	for _, model := range models {
+		if model.Namespace != namespace || model.Name != name {
+			continue
+		}
+		if version != "" && model.Version != version {
+			continue
+		}
+		matches = append(matches, model)
+	}
+
+	if version == "" && !all && len(matches) > 1 {
+		versions := make([]string, len(matches))
+		for i, model := range matches {
+			versions[i] = model.Version
+		}
+		return nil, fmt.Errorf("%s/%s has %d installed versions (%s); specify one with @version or use --all",
+			namespace, name, len(matches), strings.Join(versions, ", "))
+	}
+	return matches, nil
+}
+
+// confirm asks a yes/no question on stdout and reads the answer from in (default: no)
+func confirm(in io.Reader, question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
+// conversionRecord builds the manifest conversion block, including provenance,
+// from a conversion result. A non-nil err marks the conversion as failed.
+// Artifact paths are recorded relative to modelDir.
+func conversionRecord(result *converter.ConversionResult, err error, modelDir string) *types.Conversion {
+	c := &types.Conversion{
+		Status:      converter.ConversionStatusConverted,
+		Target:      "onnx",
+		ConvertedAt: time.Now().UTC(),
+	}
+	if err != nil {
+		c.Status = converter.ConversionStatusFailed
+		c.Error = err.Error()
+	}
+	if result != nil {
+		if result.Target != "" {
+			c.Target = result.Target
+		}
+		c.Method = result.Method
+		c.Image = result.Image
+		c.ImageDigest = result.ImageDigest
+		c.Opset = result.Opset
+		c.Precision = result.Precision
+		c.DurationSec = result.Duration.Round(time.Millisecond).Seconds()
+		c.Attempts = result.Attempts
+		c.TimedOut = result.TimedOut
+		c.OOMKilled = result.OOMKilled
+		c.SizeRatio = math.Round(result.SizeRatio*1000) / 1000
+		c.Warning = result.SizeWarning
+		c.Cached = result.Cached
+		c.SafeMode = result.SafeMode
+		if p := result.Parity; p != nil {
+			c.Parity = &types.Parity{
+				MaxAbsDiff:      p.MaxAbsDiff,
+				Tolerance:       p.Tolerance,
+				OutputsCompared: p.OutputsCompared,
+				Passed:          p.Passed,
+				Skipped:         p.Skipped,
+			}
+		}
+
+		var toolchain *types.Toolchain
+		if result.Toolchain != nil {
+			toolchain = &types.Toolchain{
+				Python:       result.Toolchain.Python,
+				Torch:        result.Toolchain.Torch,
+				Transformers: result.Toolchain.Transformers,
+				Optimum:      result.Toolchain.Optimum,
+				ONNX:         result.Toolchain.ONNX,
+				Opset:        result.Opset,
+			}
+		}
+		for _, file := range result.AllFiles {
+			relPath, relErr := filepath.Rel(modelDir, file)
+			if relErr != nil {
+				relPath = filepath.Base(file)
+			}
+			c.Artifacts = append(c.Artifacts, types.ConvertedArtifact{
+				Path:      filepath.ToSlash(relPath),
+				Toolchain: toolchain,
+			})
+		}
+	}
+	return c
+}
+
+// conversionModelID returns the model identifier passed to converters for repository lookup
+func conversionModelID(namespace, name string) string {
+	if namespace == "hf" {
+		// For Hugging Face, use just the model name
+		return name
+	}
+	return fmt.Sprintf("%s/%s", namespace, name)
+}
+
+// negotiateExecutionFormat makes sure MLOS Core has a runtime plugin loaded for the
+// model's execution format before registering it, converting to ONNX when allowed.
+// This prevents models that are registered but cannot be loaded by Core.
+func negotiateExecutionFormat(ctx context.Context, client *mlos.Client, m *types.Manifest, modelPath, namespace, name string, convert bool) error {
+	caps, err := client.Capabilities(ctx)
+	if errors.Is(err, mlos.ErrCapabilitiesUnsupported) {
+		fmt.Printf("⚠️  MLOS Core does not report runtime plugins - skipping execution format check\n")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query MLOS Core capabilities: %w\nMake sure MLOS Core is running: mlos_core", err)
+	}
+
+	format := m.Spec.Format.ExecutionFormat
+	if format != "" && caps.Supports(format) {
+		fmt.Printf("✓ MLOS Core supports execution format '%s'\n", format)
+		return nil
+	}
+
+	loaded := strings.Join(caps.RuntimePlugins, ", ")
+	if loaded == "" {
+		loaded = "none"
+	}
+	unsupported := fmt.Errorf("execution format '%s' of %s/%s is not supported by MLOS Core (loaded runtime plugins: %s)", format, namespace, name, loaded)
+
+	if !caps.Supports("onnx") || !converter.CanConvert(m.Spec.Framework.Name) {
+		return unsupported
+	}
+	if !convert {
+		return fmt.Errorf("%w\nRe-run with --convert to convert the model to ONNX before registering", unsupported)
+	}
+
+	if cachecrypt.IsEncryptedDir(modelPath) {
+		return fmt.Errorf("%w\nCannot convert to ONNX: %v", unsupported, errEncryptedModel(modelPath))
+	}
+
+	fmt.Printf("🔄 Converting %s/%s to ONNX for MLOS Core...\n", namespace, name)
+	if _, err := convertInstalledModel(ctx, modelPath, m, namespace, name, configuredConversionOptions()); err != nil {
+		return fmt.Errorf("%w\nONNX conversion failed: %v", unsupported, err)
+	}
+
+	fmt.Printf("✅ Converted to ONNX - execution format is now 'onnx'\n")
+	return nil
+}
+
+// validateTokenizer checks the model's tokenizer.json, if it has one. Corrupt or truncated
+// files fail; a sample string that doesn't round-trip only warns, since vocabularies for
+// other languages or domains can't encode it.
+func validateTokenizer(modelDir string) error {
+	path := model.LayoutFile(modelDir, tokenizer.FileName)
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	tok, err := tokenizer.Validate(path)
+	if err != nil {
+		return err
+	}
+	if err := tok.RoundTrip(tokenizer.SampleText); err != nil {
+		fmt.Printf("⚠️  Tokenizer round trip check: %v (expected if the vocabulary doesn't cover English text)\n", err)
+		return nil
+	}
+	fmt.Printf("✓ Tokenizer validated: %s\n", tokenizer.FileName)
+	return nil
+}
+
+// normalizeModelLayout rearranges an extracted model directory into the canonical layout
+// and reports what it changed
+func normalizeModelLayout(modelPath string) error {
+	changes, err := model.NormalizeLayout(modelPath)
+	if len(changes) > 0 {
+		fmt.Printf("✓ Normalized model layout:\n")
+		for _, change := range changes {
+			fmt.Printf("   - %s\n", change)
+		}
+	}
+	return err
+}
+
+// checkDigestPin verifies a pinned digest against a package and extracted model
+// directory (either may be empty) and reports what matched
+func checkDigestPin(pin, packagePath, modelDir string) error {
+	matched, err := model.VerifyDigestPin(pin, packagePath, modelDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Digest pin verified against %s\n", matched)
+	return nil
+}
+
+// verifyCachedDigestPin checks a pinned digest against an already installed model
+func verifyCachedDigestPin(modelPath, pin string) error {
+	var packagePath, modelDir string
+	if packages, _ := filepath.Glob(filepath.Join(modelPath, "*.axon")); len(packages) > 0 {
+		packagePath = packages[0]
+	}
+	if _, err := model.PrimaryWeightFile(modelPath); err == nil {
+		modelDir = modelPath
+	}
+	return checkDigestPin(pin, packagePath, modelDir)
+}
+
+// resolveVersionRange returns the version a spec's version range resolves to among the
+// versions the adapter's repository has of namespace/name
+func resolveVersionRange(ctx context.Context, adapter core.RepositoryAdapter, namespace, name string, ref spec.Spec, pre bool) (string, error) {
+	lister, ok := adapter.(core.VersionLister)
+	if !ok {
+		return "", fmt.Errorf("the %s adapter can't list versions to resolve %s; install a specific version", adapter.Name(), ref)
+	}
+	versions, err := lister.ListVersions(ctx, namespace, name)
+	if err != nil {
+		return "", fmt.Errorf("failed to list versions of %s/%s: %w", namespace, name, err)
+	}
+	return ref.Resolve(versions, pre)
+}
+
+// lockedModelSpec returns the digest-pinned spec for an installed model, preferring the
+// primary weight digest (stable across package rebuilds) over the package digest
+func lockedModelSpec(m cache.CachedModel) (string, error) {
+	if _, digest, err := model.PrimaryWeightDigest(m.Path); err == nil {
+		return model.PinnedSpec(m.Namespace, m.Name, digest), nil
+	}
+	packages, _ := filepath.Glob(filepath.Join(m.Path, "*.axon"))
+	if len(packages) == 0 {
+		return "", fmt.Errorf("no weight files or package found for %s/%s", m.Namespace, m.Name)
+	}
+	digest, err := utils.ComputeSHA256(packages[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to hash package: %w", err)
+	}
+	return model.PinnedSpec(m.Namespace, m.Name, digest), nil
+}
+
+// findCachedModel finds an installed model in the cache.
+// An empty or "latest" version matches the first cached version of the model.
+func findCachedModel(cacheMgr *cache.Manager, namespace, name, version string) (*cache.CachedModel, error) {
+	models, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	for i := range models {
+		m := models[i]
+		if m.Namespace == namespace && m.Name == name {
+			if version == "" || version == "latest" || m.Version == version {
+				return &m, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("model %s/%s not found", namespace, name)
+}
+
+func extractCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "extract [namespace/name[@version]]",
+		Short: "Extract an installed model package",
+		Long: `Unpack the .axon package of an installed model so its files are available on disk.
+
+By default files are extracted into the model's cache directory, next to the package.
+Use --dest to extract into a different directory.
+
+--list prints the files in the package and --file extracts just the named files (into
+the current directory unless --dest is given). Packages written by this version of
+Axon have an index, so both read only what they need instead of the whole archive,
+and extracted files are checked against their SHA-256 in the index.
+
+Examples:
+  axon extract hf/bert-base-uncased@latest
+  axon extract hf/bert-base-uncased@latest --list
+  axon extract hf/bert-base-uncased@latest --file config.json --dest /tmp/bert`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modelSpec := args[0]
+			ref, err := spec.Parse(modelSpec)
+			if err != nil {
+				return err
+			}
+			namespace, name, version := ref.Namespace, ref.Name, ref.Version
+
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			cached, err := findCachedModel(cacheMgr, namespace, name, version)
+			if err != nil {
+				return err
+			}
+
+			if cachecrypt.IsEncryptedDir(cached.Path) {
+				return errEncryptedModel(cached.Path)
+			}
+
+			packagePath, err := cacheMgr.GetPackagePath(cached.Namespace, cached.Name, cached.Version)
+			if err != nil {
+				return err
+			}
+
+			dest, _ := cmd.Flags().GetString("dest")
+			if list, _ := cmd.Flags().GetBool("list"); list {
+				return listPackage(packagePath)
+			}
+			if files, _ := cmd.Flags().GetStringSlice("file"); len(files) > 0 {
+				return extractPackageFiles(packagePath, files, dest)
+			}
+			if dest == "" {
+				dest = cached.Path
+			}
+
+			fmt.Printf("Extracting %s/%s@%s...\n", cached.Namespace, cached.Name, cached.Version)
+			fmt.Printf("   Package: %s\n", packagePath)
+			fmt.Printf("   Target:  %s\n", dest)
+
+			if err := model.ExtractPackage(packagePath, dest); err != nil {
+				return fmt.Errorf("failed to extract package: %w", err)
+			}
+			if err := normalizeModelLayout(dest); err != nil {
+				return err
+			}
+			if err := checkWeightScanPolicy(cfg.Security, dest); err != nil {
+				if dest == cached.Path {
+					_ = cacheMgr.RemoveModel(cached.Namespace, cached.Name, cached.Version)
+					return fmt.Errorf("refusing to keep %s/%s@%s (removed from cache): %w", cached.Namespace, cached.Name, cached.Version, err)
+				}
+				return fmt.Errorf("refusing to extract %s/%s@%s: %w", cached.Namespace, cached.Name, cached.Version, err)
+			}
+
+			// Refresh the integrity manifest when extracting into the cache itself
+			if dest == cached.Path {
+				if inventory, err := model.WriteInventory(dest); err != nil {
+					fmt.Printf("⚠️  Failed to write %s: %v\n", model.InventoryFileName, err)
+				} else {
+					recordIntegrity(cacheMgr, dest, inventory)
+				}
+			}
+
+			fmt.Printf("✓ Extracted %s/%s@%s to %s\n", cached.Namespace, cached.Name, cached.Version, dest)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("dest", "", "Destination directory (default: model cache directory, or the current directory with --file)")
+	cmd.Flags().Bool("list", false, "List the files in the package instead of extracting it")
+	cmd.Flags().StringSlice("file", nil, "Extract only this file of the package (repeatable)")
+	return cmd
+}
 
-			return nil
-		},
+// listPackage prints the files in a package
+func listPackage(packagePath string) error {
+	entries, err := model.ListPackage(packagePath)
+	if err != nil {
+		return fmt.Errorf("failed to list package: %w", err)
+	}
+	var total int64
+	for _, entry := range entries {
+		digest := entry.SHA256
+		if digest == "" {
+			digest = "-"
+		}
+		fmt.Printf("%-10s  %-64s  %s\n", formatBytes(entry.Size), digest, entry.Path)
+		total += entry.Size
 	}
+	fmt.Printf("%d file(s), %s\n", len(entries), formatBytes(total))
+	return nil
 }
 
-func updateCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "update [namespace/name]",
-		Short: "Update a model",
-		Long:  "Strengthen the pathway by updating to the latest version",
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			modelSpec := args[0]
-			fmt.Printf("Strengthening pathway for %s...\n", modelSpec)
-			fmt.Println("(Update not yet implemented)")
-			return nil
-		},
+// extractPackageFiles extracts the named files of a package into dest, keeping their
+// paths in the package
+func extractPackageFiles(packagePath string, files []string, dest string) error {
+	if dest == "" {
+		dest = "."
+	}
+	for _, name := range files {
+		// Rooting the name before cleaning it keeps the target inside dest
+		rel := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(name)), "/")
+		target := filepath.Join(dest, filepath.FromSlash(rel))
+		if err := model.ExtractPackageFile(packagePath, name, target); err != nil {
+			return fmt.Errorf("failed to extract %s: %w", name, err)
+		}
+		fmt.Printf("✓ Extracted %s to %s\n", name, target)
 	}
+	return nil
 }
 
 func verifyCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "verify [namespace/name]",
 		Short: "Verify installation",
-		Long:  "Check signal integrity for an installed model",
-		Args:  cobra.ExactArgs(1),
+		Long: `Check signal integrity for an installed model.
+
+--attestation also validates the model's attestation (attestation.json in the model
+directory, or else the one published with it in the registry): it must be signed by a
+key in security.trusted_keys, be about the installed package, and name the build inputs
+recorded in the package's provenance.json.
+
+--all checks every installed model in parallel against the cache's integrity index,
+which records the files and hashes of each model when it is installed, and reports
+corrupted files and models whose directory is gone. Models installed before the index
+existed are checked against their files.json and added to the index. --repair
+reinstalls the corrupted and missing models.`,
+		Args: cobra.RangeArgs(0, 1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			modelSpec := args[0]
-			namespace, name, version := parseModelSpec(modelSpec)
-
-			if namespace == "" || name == "" {
-				return fmt.Errorf("invalid model specification: %s", modelSpec)
+			checkAttestation, _ := cmd.Flags().GetBool("attestation")
+			all, _ := cmd.Flags().GetBool("all")
+			repair, _ := cmd.Flags().GetBool("repair")
+			if all {
+				if len(args) > 0 {
+					return fmt.Errorf("--all cannot be combined with a model")
+				}
+				if checkAttestation {
+					return fmt.Errorf("--attestation cannot be combined with --all")
+				}
+				concurrency, _ := cmd.Flags().GetInt("concurrency")
+				return verifyAll(cmd.Context(), concurrency, repair)
+			}
+			if repair {
+				return fmt.Errorf("--repair requires --all")
 			}
+			if len(args) == 0 {
+				return fmt.Errorf("specify a model to verify, or --all")
+			}
+			return verifyModel(cmd.Context(), args[0], checkAttestation)
+		},
+	}
 
-			cacheMgr := cache.NewManager(cfg.CacheDir)
+	cmd.Flags().Bool("attestation", false, "Also validate the model's attestation against its package and provenance")
+	cmd.Flags().Bool("all", false, "Verify every installed model against the integrity index")
+	cmd.Flags().Int("concurrency", 4, "Number of models to verify in parallel with --all")
+	cmd.Flags().Bool("repair", false, "With --all, reinstall corrupted and missing models")
 
-			// Find the model
-			models, err := cacheMgr.ListCachedModels()
-			if err != nil {
-				return fmt.Errorf("failed to list models: %w", err)
-			}
+	return cmd
+}
 
-			var model *cache.CachedModel
-			for _, m := range models {
-				if m.Namespace == namespace && m.Name == name {
-					if version == "" || version == "latest" || m.Version == version {
-						model = &m
-						break
-					}
-				}
-			}
+// verifyModel checks an installed model's extracted files against its integrity manifest,
+// its package signature and tokenizer, and with checkAttestation its attestation
+func verifyModel(ctx context.Context, modelSpec string, checkAttestation bool) (err error) {
+	ctx, span := telemetry.Start(ctx, "verify", telemetry.String("model", modelSpec))
+	defer func() { span.End(err) }()
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return err
+	}
+	namespace, name, version := ref.Namespace, ref.Name, ref.Version
 
-			if model == nil {
-				return fmt.Errorf("model %s/%s not found", namespace, name)
-			}
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
 
-			// Verify checksums
-			manifestPath := filepath.Join(model.Path, "manifest.yaml")
-			if _, err := os.Stat(manifestPath); err != nil {
-				return fmt.Errorf("manifest not found: %w", err)
-			}
+	// Find the model
+	cached, err := findCachedModel(cacheMgr, namespace, name, version)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			m, _ := loadManifest(filepath.Join(cached.Path, "manifest.yaml"))
+			fireHooks(ctx, hooks.EventVerifyFailure, fmt.Sprintf("%s/%s@%s", cached.Namespace, cached.Name, cached.Version), cached.Path, m, err)
+		}
+	}()
 
-			fmt.Printf("✓ Signal integrity verified for %s/%s@%s\n", model.Namespace, model.Name, model.Version)
-			return nil
-		},
+	manifestPath := filepath.Join(cached.Path, "manifest.yaml")
+	if _, err := os.Stat(manifestPath); err != nil {
+		return fmt.Errorf("manifest not found: %w", err)
+	}
+
+	// Verify the extracted tree against its integrity manifest
+	var key []byte
+	if cachecrypt.IsEncryptedDir(cached.Path) {
+		if key, err = cacheEncryptionKey(false); err != nil {
+			fmt.Printf("⚠️  Cannot check encrypted files: %v\n", err)
+		}
+	}
+	problems, err := model.VerifyEncryptedInventory(cached.Path, key)
+	if os.IsNotExist(err) {
+		fmt.Printf("⚠️  No %s found (installed before integrity tracking, or not extracted)\n", model.InventoryFileName)
+	} else if err != nil {
+		return fmt.Errorf("failed to verify extracted files: %w", err)
+	} else if len(problems) > 0 {
+		fmt.Printf("✗ Signal integrity check failed for %s/%s@%s:\n", cached.Namespace, cached.Name, cached.Version)
+		for _, problem := range problems {
+			fmt.Printf("  - %s\n", problem)
+		}
+		return fmt.Errorf("%d file(s) failed verification", len(problems))
+	}
+
+	if err := verifyInstalledSignature(cached.Path); err != nil {
+		return fmt.Errorf("signature verification failed for %s/%s@%s: %w", cached.Namespace, cached.Name, cached.Version, err)
+	}
+
+	if err := validateTokenizer(cached.Path); err != nil {
+		return fmt.Errorf("tokenizer validation failed for %s/%s@%s: %w", cached.Namespace, cached.Name, cached.Version, err)
+	}
+
+	if checkAttestation {
+		if err := verifyAttestation(ctx, cached.Path); err != nil {
+			return fmt.Errorf("attestation verification failed for %s/%s@%s: %w", cached.Namespace, cached.Name, cached.Version, err)
+		}
 	}
+
+	fmt.Printf("✓ Signal integrity verified for %s/%s@%s\n", cached.Namespace, cached.Name, cached.Version)
+	return nil
 }
 
 func publishCmd() *cobra.Command {
@@ -986,17 +2294,40 @@ Per architecture design:
 - Production: Models published to /var/lib/mlos/models/ (OS-managed)
 - Explicit promotion: Clear separation between dev and prod
 
+The model's license is checked against policy.allowed_licenses and
+policy.denied_licenses unless --override-license-policy is given.
+
+--delta-from writes a delta package next to the model, holding only the chunks of files
+that changed since the earlier version (installed or published), and lists it in the
+published manifest so 'axon update' from that version downloads just the delta. Serve
+the delta file next to the package.
+
+--registry-dir publishes to the directory of a file-based Axon registry (see
+test/registry) instead of MLOS Core. The manifest is accompanied by an in-toto
+attestation (attestation.json) linking the package hash to its build inputs: the
+source adapter and revision, the downloaded files and the converter image. With
+--sign the attestation is signed too; check it with 'axon verify --attestation'.
+
+--registry uploads the same files to an Axon registry server that accepts uploads
+(test/registry with AXON_REGISTRY_UPLOAD_TOKEN set), authenticating with the token
+stored by 'axon login local'. The registry verifies the package checksum and the
+namespace quota, and refuses to overwrite a published version.
+
 Examples:
   axon publish hf/bert-base-uncased@latest
-  axon publish hf/bert-base-uncased@latest --target localhost`,
+  axon publish hf/bert-base-uncased@latest --target localhost
+  axon publish hf/bert-base-uncased@latest --sign
+  axon publish myteam/bert-finetuned@2.0.0 --delta-from 1.0.0
+  axon publish myteam/bert-finetuned@2.0.0 --registry-dir /srv/axon-registry --sign
+  axon publish myteam/bert-finetuned@2.0.0 --registry https://registry.example.com`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			modelSpec := args[0]
-			namespace, name, version := parseModelSpec(modelSpec)
-
-			if namespace == "" || name == "" {
-				return fmt.Errorf("invalid model specification: %s", modelSpec)
+			ref, err := spec.Parse(modelSpec)
+			if err != nil {
+				return err
 			}
+			namespace, name, version := ref.Namespace, ref.Name, ref.Version
 
 			// Get target (default: localhost)
 			target, _ := cmd.Flags().GetString("target")
@@ -1004,12 +2335,7 @@ Examples:
 				target = "localhost"
 			}
 
-			// Get cache directory from config
-			cfg, err := config.Load()
-			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
-			}
-			cacheMgr := cache.NewManager(cfg.CacheDir)
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
 
 			// Check if model is cached
 			if !cacheMgr.IsModelCached(namespace, name, version) {
@@ -1021,10 +2347,19 @@ Examples:
 
 			// Determine target path (production repository)
 			// For now, support localhost only (local filesystem)
+			registryDir, _ := cmd.Flags().GetString("registry-dir")
+			registryURL, _ := cmd.Flags().GetString("registry")
+			if registryDir != "" && registryURL != "" {
+				return fmt.Errorf("--registry and --registry-dir can't be used together")
+			}
 			var targetPath string
-			if target == "localhost" || target == "127.0.0.1" || target == "" {
+			if registryDir != "" {
+				targetPath = registryManifestDir(registryDir, namespace, name, version)
+			} else if registryURL != "" {
+				targetPath = fmt.Sprintf("%s/api/v1/models/%s/%s/%s", strings.TrimRight(registryURL, "/"), namespace, name, version)
+			} else if target == "localhost" || target == "127.0.0.1" || target == "" {
 				// Local filesystem: /var/lib/mlos/models/
-				targetPath = filepath.Join("/var/lib/mlos/models", namespace, name, version)
+				targetPath = publishedModelPath(namespace, name, version)
 			} else {
 				// Future: Support remote targets (SSH, API upload, etc.)
 				return fmt.Errorf("remote targets not yet implemented. Use 'localhost' for now")
@@ -1039,6 +2374,60 @@ Examples:
 			if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
 				return fmt.Errorf("manifest.yaml not found in %s. Model may be corrupted", sourcePath)
 			}
+			manifest, err := loadManifest(manifestPath)
+			if err != nil {
+				return err
+			}
+			override, _ := cmd.Flags().GetBool(overrideLicensePolicyFlag)
+			if err := checkLicensePolicy(cfg.Policy, manifest, override); err != nil {
+				return fmt.Errorf("refusing to publish %s/%s@%s: %w (use --%s to publish anyway)", namespace, name, version, err, overrideLicensePolicyFlag)
+			}
+
+			// Sign before copying anything, so a missing key doesn't leave a partial publish
+			var signedManifest *types.Manifest
+			var keyPath string
+			if sign, _ := cmd.Flags().GetBool("sign"); sign {
+				key, _ := cmd.Flags().GetString("key")
+				keyPath = signingKeyPath(key)
+				var signature *types.PackageSignature
+				signedManifest, signature, err = signModel(sourcePath, keyPath)
+				if err != nil {
+					return fmt.Errorf("failed to sign package: %w", err)
+				}
+				fmt.Printf("🔏 Signed package (key %s, sha256:%s)\n", signature.KeyID, signedManifest.Distribution.Package.SHA256)
+			}
+
+			// Build the delta before copying too; it is the slowest step that can fail
+			var deltaInfo *types.DeltaInfo
+			var deltaPath string
+			if from, _ := cmd.Flags().GetString("delta-from"); from != "" {
+				deltaInfo, deltaPath, err = buildPublishDelta(cacheMgr, ref, from, sourcePath)
+				if err != nil {
+					return fmt.Errorf("failed to build delta from %s: %w", from, err)
+				}
+				defer func() { _ = os.Remove(deltaPath) }()
+			}
+
+			if registryDir != "" || registryURL != "" {
+				published := signedManifest
+				if published == nil {
+					published = manifest
+				}
+				if registryURL != "" {
+					if err := uploadToRegistry(cmd.Context(), registryURL, ref, sourcePath, published, keyPath, deltaInfo, deltaPath); err != nil {
+						return fmt.Errorf("failed to publish to %s: %w", registryURL, err)
+					}
+					fmt.Printf("✅ Model published to %s\n", registryURL)
+					return nil
+				}
+				if err := publishToRegistry(registryDir, ref, sourcePath, published, keyPath, deltaInfo, deltaPath); err != nil {
+					return err
+				}
+				fmt.Printf("✅ Model published to registry\n")
+				fmt.Printf("   Package: %s\n", filepath.Join(registryDir, "packages", safeTempFileName(namespace, name, version)))
+				fmt.Printf("   Manifest: %s\n", filepath.Join(targetPath, "manifest.yaml"))
+				return nil
+			}
 
 			// Create target directory
 			if err := os.MkdirAll(targetPath, 0755); err != nil {
@@ -1074,16 +2463,30 @@ Examples:
 				return fmt.Errorf("failed to copy model files: %w", err)
 			}
 
+			// The published manifest lists the delta, which is served next to the package
+			published := signedManifest
+			if deltaInfo != nil {
+				if err := copyFile(deltaPath, filepath.Join(targetPath, deltaInfo.URL)); err != nil {
+					return fmt.Errorf("failed to copy delta: %w", err)
+				}
+				if published == nil {
+					published = manifest
+				}
+				published.Distribution.Deltas = withDelta(published.Distribution.Deltas, *deltaInfo)
+			}
+			if published != nil {
+				if err := saveManifest(published, filepath.Join(targetPath, "manifest.yaml")); err != nil {
+					return fmt.Errorf("failed to write published manifest: %w", err)
+				}
+			}
+
 			// Set permissions (try to set ownership to mlos:mlos, but don't fail if not root)
 			// In production, this should be done by setup script or with proper permissions
 			fmt.Printf("✅ Model published successfully\n")
 			fmt.Printf("   Location: %s\n", targetPath)
 
 			// Notify MLOS Core (if running)
-			mlosEndpoint := os.Getenv("MLOS_CORE_ENDPOINT")
-			if mlosEndpoint == "" {
-				mlosEndpoint = "http://localhost:8080"
-			}
+			mlosEndpoint := mlos.EndpointFromEnv()
 
 			// Try to notify MLOS Core (non-blocking - it will auto-discover on next scan)
 			notifyURL := fmt.Sprintf("%s/models/scan", mlosEndpoint)
@@ -1102,155 +2505,338 @@ Examples:
 	}
 
 	cmd.Flags().String("target", "localhost", "Target MLOS Core instance (default: localhost)")
+	cmd.Flags().Bool("sign", false, "Sign the package and record the signature in the published manifest")
+	cmd.Flags().String("key", "", "Signing key name in ~/.axon/keys or private key path (default: security.signing_key or ~/.axon/keys/signing.pem)")
+	cmd.Flags().Bool(overrideLicensePolicyFlag, false, "Publish even if the model's license violates policy.allowed_licenses or policy.denied_licenses")
+	cmd.Flags().String("delta-from", "", "Also publish a delta package from this earlier version, for 'axon update'")
+	cmd.Flags().String("registry-dir", "", "Publish with an attestation to this file-based registry directory instead of MLOS Core")
+	cmd.Flags().String("registry", "", "Upload to the Axon registry server at this URL instead of MLOS Core")
 
 	return cmd
 }
 
+// checkRegistrationConflict refuses to replace a model registered with Core under
+// the same ID but with a different digest, unless force is set.
+func checkRegistrationConflict(ctx context.Context, client *mlos.Client, modelID, digest, modelPath string, force bool) error {
+	existing, err := client.GetModel(ctx, modelID)
+	if err != nil {
+		// Older Core releases may not support lookups; registration itself will surface real errors
+		fmt.Printf("⚠️  Could not check for existing registration: %v\n", err)
+		return nil
+	}
+	if existing == nil || existing.Digest == "" || existing.Digest == digest {
+		return nil
+	}
+
+	fmt.Printf("⚠️  %s is already registered with different content:\n", modelID)
+	fmt.Printf("   %-10s %-40s %s\n", "", "Registered", "Local")
+	fmt.Printf("   %-10s %-40s %s\n", "Digest:", existing.Digest, digest)
+	if existing.Version != "" {
+		fmt.Printf("   %-10s %-40s %s\n", "Version:", existing.Version, modelID[strings.LastIndex(modelID, "@")+1:])
+	}
+	if existing.Path != "" {
+		fmt.Printf("   %-10s %-40s %s\n", "Path:", existing.Path, modelPath)
+	}
+	if existing.RegisteredAt != "" {
+		fmt.Printf("   %-10s %s\n", "Since:", existing.RegisteredAt)
+	}
+
+	if !force {
+		return fmt.Errorf("refusing to overwrite registered model %s (use --force to replace it)", modelID)
+	}
+	fmt.Printf("🔁 Replacing registered model (--force)\n")
+	return nil
+}
+
 func registerCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "register [namespace/name[@version]]",
+	cmd := &cobra.Command{
+		Use:   "register [namespace/name[@version] | --all]",
 		Short: "Register model with MLOS Core",
-		Long:  "Register an installed model with MLOS Core for kernel-level execution",
-		Args:  cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			modelSpec := args[0]
-			namespace, name, version := parseModelSpec(modelSpec)
+		Long: `Register an installed model with MLOS Core for kernel-level execution.
 
-			if namespace == "" || name == "" {
-				return fmt.Errorf("invalid model specification: %s", modelSpec)
-			}
+Before registering, Axon asks Core which runtime plugins are loaded. If the model's
+execution format is not supported, registration fails; use --convert to convert the
+model to ONNX first when Core has the ONNX runtime plugin loaded.
 
-			// Get MLOS Core endpoint from config or environment
-			mlosEndpoint := os.Getenv("MLOS_CORE_ENDPOINT")
-			if mlosEndpoint == "" {
-				mlosEndpoint = "http://localhost:8080"
-			}
+If Core already has a model registered under the same ID with different content,
+registration is refused and the differences are shown; use --force to replace it.
 
-			fmt.Printf("🔌 Registering %s/%s@%s with MLOS Core...\n", namespace, name, version)
+--dry-run prints the JSON request that would be sent to Core, after checking it
+against Core's registration schema, and exits without contacting Core or changing
+anything. Capability negotiation and conversion are skipped, so the request shows the
+model's current execution format.
 
-			// Get cache directory from config
-			cfg, err := config.Load()
-			if err != nil {
-				return fmt.Errorf("failed to load config: %w", err)
-			}
-			cacheMgr := cache.NewManager(cfg.CacheDir)
-
-			// Per architecture: Check published models first, then cache
-			// Published models: /var/lib/mlos/models/namespace/name/version/
-			// Development cache: ~/.axon/cache/models/namespace/name/version/
-			var model *cache.CachedModel
-			var modelPath string
-
-			// First, check published models (production repository)
-			publishedPath := filepath.Join("/var/lib/mlos/models", namespace, name, version)
-			publishedManifest := filepath.Join(publishedPath, "manifest.yaml")
-			if _, err := os.Stat(publishedManifest); err == nil {
-				// Model is published - use published location
-				modelPath = publishedPath
-				fmt.Printf("📦 Using published model: %s\n", publishedPath)
-			} else {
-				// Fallback: Check development cache
-				models, err := cacheMgr.ListCachedModels()
-				if err != nil {
-					return fmt.Errorf("failed to list models: %w", err)
+--all registers every cached model instead of one, dependencies first. It keeps going
+when a model fails, skips the models that depend on it, prints a summary and fails if
+any model could not be registered. Use 'axon install --register' to register models
+as they are installed.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return cobra.ExactArgs(1)(cmd, args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			convert, _ := cmd.Flags().GetBool("convert")
+			force, _ := cmd.Flags().GetBool("force")
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+					return fmt.Errorf("--dry-run cannot be combined with --all")
 				}
+				return registerAllCached(cmd.Context(), convert, force)
+			}
+			if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+				return registrationDryRun(args[0], force, os.Stdout)
+			}
+			return registerModel(cmd.Context(), args[0], convert, force)
+		},
+	}
 
-				for _, m := range models {
-					if m.Namespace == namespace && m.Name == name {
-						if version == "" || version == "latest" || m.Version == version {
-							model = &m
-							modelPath = m.Path
-							fmt.Printf("📦 Using cached model: %s\n", modelPath)
-							break
-						}
-					}
-				}
+	cmd.Flags().Bool("convert", false, "Convert to a format supported by MLOS Core if needed")
+	cmd.Flags().Bool("force", false, "Replace a different model already registered under the same ID")
+	cmd.Flags().Bool("dry-run", false, "Print and validate the registration request without sending it")
+	cmd.Flags().Bool("all", false, "Register every cached model")
+	return cmd
+}
+
+// registrationTarget is an installed or published model to register with MLOS Core
+type registrationTarget struct {
+	modelID   string // namespace/name@version of the installed model
+	path      string
+	published bool
+	manifest  *types.Manifest
+}
+
+// findRegistrationTarget finds a model to register: published models first, then the cache.
+// Published models: /var/lib/mlos/models/namespace/name/version/
+// Development cache: ~/.axon/cache/models/namespace/name/version/
+func findRegistrationTarget(namespace, name, version string) (*registrationTarget, error) {
+	target := &registrationTarget{modelID: fmt.Sprintf("%s/%s@%s", namespace, name, version)}
 
-				if model == nil {
-					return fmt.Errorf("model %s/%s@%s not found. Install it first with 'axon install' or publish it with 'axon publish'", namespace, name, version)
+	publishedPath := publishedModelPath(namespace, name, version)
+	if _, err := os.Stat(filepath.Join(publishedPath, "manifest.yaml")); err == nil {
+		target.path = publishedPath
+		target.published = true
+	} else {
+		models, err := cache.NewManager(cfg.ModelCacheDir()).ListCachedModels()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list models: %w", err)
+		}
+		for _, m := range models {
+			if m.Namespace == namespace && m.Name == name {
+				if version == "" || version == "latest" || m.Version == version {
+					target.modelID = fmt.Sprintf("%s/%s@%s", namespace, name, m.Version)
+					target.path = m.Path
+					break
 				}
 			}
+		}
+		if target.path == "" {
+			return nil, fmt.Errorf("model %s/%s@%s not found. Install it first with 'axon install' or publish it with 'axon publish'", namespace, name, version)
+		}
+	}
 
-			// Read manifest
-			manifestPath := filepath.Join(modelPath, "manifest.yaml")
-			manifestData, err := os.ReadFile(manifestPath)
-			if err != nil {
-				return fmt.Errorf("failed to read manifest: %w", err)
-			}
+	manifestData, err := os.ReadFile(filepath.Join(target.path, "manifest.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if target.manifest, err = manifest.ParseBytes(manifestData); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return target, nil
+}
 
-			// Parse manifest
-			manifestObj, err := manifest.ParseBytes(manifestData)
-			if err != nil {
-				return fmt.Errorf("failed to parse manifest: %w", err)
-			}
-
-			// Register with MLOS Core via HTTP API
-			registerURL := fmt.Sprintf("%s/models/register", mlosEndpoint)
-
-			// Build registration payload
-			// Note: We send manifest_path instead of the full manifest JSON
-			// MLOS Core will read the manifest from the path
-			// execution_format tells Core which runtime plugin to use (onnx, gguf, tflite, etc.)
-			payload := fmt.Sprintf(`{
-				"model_id": "%s/%s@%s",
-				"name": "%s",
-				"framework": "%s",
-				"execution_format": "%s",
-				"path": "%s",
-				"description": "%s",
-				"manifest_path": "%s"
-			}`,
-				namespace, name, func() string {
-					if model != nil {
-						return model.Version
-					}
-					return version
-				}(),
-				manifestObj.Metadata.Name,
-				manifestObj.Spec.Framework.Name,
-				manifestObj.Spec.Format.ExecutionFormat,
-				modelPath,
-				manifestObj.Metadata.Description,
-				manifestPath,
-			)
-
-			// Make HTTP request
-			req, err := http.NewRequest("POST", registerURL, strings.NewReader(payload))
-			if err != nil {
-				return fmt.Errorf("failed to create request: %w", err)
-			}
-			req.Header.Set("Content-Type", "application/json")
+// String describes where the model is registered from
+func (t *registrationTarget) String() string {
+	if t.published {
+		return "published model: " + t.path
+	}
+	return "cached model: " + t.path
+}
 
-			client := &http.Client{Timeout: 30 * time.Second}
-			resp, err := client.Do(req)
-			if err != nil {
-				return fmt.Errorf("failed to connect to MLOS Core at %s: %w\nMake sure MLOS Core is running: mlos_core", mlosEndpoint, err)
-			}
-			defer func() {
-				_ = resp.Body.Close()
-			}()
+// registerRequest builds the registration request for a model Core loads from corePath.
+// Core reads the manifest from the path; execution_format tells it which runtime plugin
+// to use, digest lets it detect conflicting re-registrations and replace is set by --force.
+func (t *registrationTarget) registerRequest(corePath, digest string, force bool) *mlos.RegisterRequest {
+	request := &mlos.RegisterRequest{
+		SchemaVersion:   mlos.RegisterSchemaVersion,
+		ModelID:         coreModelID(t.modelID),
+		Tenant:          cfg.Tenant,
+		Name:            t.manifest.Metadata.Name,
+		Framework:       t.manifest.Spec.Framework.Name,
+		ExecutionFormat: t.manifest.Spec.Format.ExecutionFormat,
+		Task:            t.manifest.Spec.Task,
+		Path:            corePath,
+		Description:     t.manifest.Metadata.Description,
+		ManifestPath:    filepath.Join(corePath, "manifest.yaml"),
+		Digest:          digest,
+		Replace:         force,
+		Inputs:          tensorSpecs(t.manifest.Spec.IO.Inputs),
+		Outputs:         tensorSpecs(t.manifest.Spec.IO.Outputs),
+		GPU:             gpuRequirement(t.manifest.Spec.Requirements.Compute.GPU),
+	}
+	if t.manifest.Spec.Format.MultiEncoder != "" {
+		request.MultiEncoder = t.manifest.Spec.Format.MultiEncoder
+		request.ONNXManifestPath = filepath.Join(corePath, model.ONNXManifestFileName)
+		request.Components = modelComponents(t.manifest)
+	}
+	return request
+}
 
-			if resp.StatusCode != 200 {
-				body, _ := io.ReadAll(resp.Body)
-				return fmt.Errorf("MLOS Core registration failed (status %d): %s", resp.StatusCode, string(body))
-			}
+// tensorSpecs converts a manifest I/O schema to the tensors of a registration request
+func tensorSpecs(specs []types.IOSpec) []mlos.TensorSpec {
+	var tensors []mlos.TensorSpec
+	for _, spec := range specs {
+		tensors = append(tensors, mlos.TensorSpec{Name: spec.Name, DType: spec.DType, Shape: spec.Shape})
+	}
+	return tensors
+}
 
-			// Get version from model or use provided version
-			modelVersion := version
-			if model != nil {
-				modelVersion = model.Version
-			} else if version == "" || version == "latest" {
-				// Try to extract from manifest or path
-				modelVersion = "latest"
-			}
+// gpuRequirement converts a manifest's GPU requirement to that of a registration
+// request; nil when the model neither needs nor recommends a GPU
+func gpuRequirement(gpu *types.GPURequirement) *mlos.GPURequirement {
+	if gpu == nil || !gpu.Required && !gpu.Recommended {
+		return nil
+	}
+	return &mlos.GPURequirement{
+		Required:             gpu.Required,
+		Recommended:          gpu.Recommended,
+		MinVRAMGB:            gpu.MinVRAMGB,
+		MinComputeCapability: gpu.MinComputeCapability,
+		CUDAVersion:          gpu.CUDAVersion,
+		ROCmVersion:          gpu.ROCmVersion,
+		Accelerators:         gpu.Accelerators,
+	}
+}
 
-			fmt.Printf("✅ Model registered with MLOS Core\n")
-			fmt.Printf("   Model ID: %s/%s@%s\n", namespace, name, modelVersion)
-			fmt.Printf("   Framework: %s\n", manifestObj.Spec.Framework.Name)
-			fmt.Printf("   Ready for kernel-level execution\n")
-			return nil
-		},
+// modelComponents lists the ONNX files of a multi-encoder model with their roles
+func modelComponents(m *types.Manifest) []mlos.Component {
+	var components []mlos.Component
+	for _, file := range m.Spec.Format.ExecutionFiles {
+		if file.Format == "onnx" {
+			components = append(components, mlos.Component{Role: file.Type, Path: filepath.ToSlash(file.Path)})
+		}
+	}
+	return components
+}
+
+// registerModel registers an installed or published model with MLOS Core
+func registerModel(ctx context.Context, modelSpec string, convert, force bool) (err error) {
+	start := time.Now()
+	ctx, span := telemetry.Start(ctx, "register", telemetry.String("model", modelSpec))
+	defer func() {
+		span.End(err)
+		recordHistory(history.ActionRegister, modelSpec, cachedPackageDigest(modelSpec), err, start)
+	}()
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return err
+	}
+	namespace, name, version := ref.Namespace, ref.Name, ref.Version
+
+	// Get MLOS Core endpoint from config or environment
+	coreClient := mlos.NewClient(mlos.EndpointFromEnv())
+
+	fmt.Printf("🔌 Registering %s/%s@%s with MLOS Core...\n", namespace, name, version)
+
+	target, err := findRegistrationTarget(namespace, name, version)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📦 Using %s\n", target)
+
+	// Make sure Core can actually execute this model before registering it
+	if err := negotiateExecutionFormat(ctx, coreClient, target.manifest, target.path, namespace, name, convert); err != nil {
+		return err
+	}
+
+	// Refuse to silently overwrite a different model already registered under this ID
+	digest, err := model.ModelDigest(target.path)
+	if err != nil {
+		return fmt.Errorf("failed to compute model digest: %w", err)
+	}
+	if err := checkRegistrationConflict(ctx, coreClient, coreModelID(target.modelID), digest, target.path, force); err != nil {
+		return err
+	}
+
+	// Core loads encrypted models from a decrypted copy on memory-backed storage; the
+	// registration record keeps the cache path so restores decrypt again
+	corePath, err := resolveCorePath(target.path, namespace, name, filepath.Base(target.path))
+	if err != nil {
+		return err
+	}
+
+	if err := coreClient.RegisterModel(ctx, target.registerRequest(corePath, digest, force)); err != nil {
+		return err
+	}
+
+	// Remember the registration so it can be restored after Core restarts
+	if err := saveRegistration(mlos.Registration{
+		ModelID:   target.modelID,
+		Path:      target.path,
+		Digest:    digest,
+		Convert:   convert,
+		DependsOn: target.manifest.Spec.Dependencies.Models,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record registration: %v\n", err)
+	}
+	if !target.published {
+		state := &cache.CoreRegistration{
+			Endpoint:     coreClient.Endpoint(),
+			ModelID:      coreModelID(target.modelID),
+			RegisteredAt: time.Now().Format(time.RFC3339),
+		}
+		if err := cache.WriteRegistration(target.path, state); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record registration state: %v\n", err)
+		}
+		if err := cache.MarkUsed(target.path); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record the model's use: %v\n", err)
+		}
+	}
+
+	fmt.Printf("✅ Model registered with MLOS Core\n")
+	fmt.Printf("   Model ID: %s\n", coreModelID(target.modelID))
+	fmt.Printf("   Framework: %s\n", target.manifest.Spec.Framework.Name)
+	fmt.Printf("   Ready for kernel-level execution\n")
+	return nil
+}
+
+// registrationDryRun writes the request 'axon register' would send to MLOS Core to out,
+// after validating it, without contacting Core or changing anything: no conversion,
+// no decryption and no registration record. Status goes to stderr so out is just JSON.
+func registrationDryRun(modelSpec string, force bool, out io.Writer) error {
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return err
+	}
+	target, err := findRegistrationTarget(ref.Namespace, ref.Name, ref.Version)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "📦 Using %s\n", target)
+
+	digest, err := model.ModelDigest(target.path)
+	if err != nil {
+		return fmt.Errorf("failed to compute model digest: %w", err)
+	}
+	corePath := target.path
+	if cachecrypt.IsEncryptedDir(target.path) {
+		corePath = decryptedModelPath(ref.Namespace, ref.Name, filepath.Base(target.path))
+	}
+
+	request := target.registerRequest(corePath, digest, force)
+	data, err := json.MarshalIndent(request, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode registration request: %w", err)
+	}
+	if _, err := fmt.Fprintln(out, string(data)); err != nil {
+		return err
 	}
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ Payload for POST %s/models/register is valid (dry run, nothing sent)\n", mlos.EndpointFromEnv())
+	return nil
 }
 
 func cacheCmd() *cobra.Command {
@@ -1264,7 +2850,7 @@ func cacheCmd() *cobra.Command {
 		Use:   "list",
 		Short: "List cached models",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cacheMgr := cache.NewManager(cfg.CacheDir)
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
 			models, err := cacheMgr.ListCachedModels()
 			if err != nil {
 				return fmt.Errorf("failed to list cached models: %w", err)
@@ -1289,7 +2875,7 @@ func cacheCmd() *cobra.Command {
 		Short: "Clean cache",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fmt.Println("Cleaning myelin cache...")
-			_ = cache.NewManager(cfg.CacheDir)
+			_ = cache.NewManager(cfg.ModelCacheDir())
 
 			// TODO: Implement cleanup policy
 			fmt.Println("(Cache cleanup not yet implemented)")
@@ -1297,28 +2883,88 @@ func cacheCmd() *cobra.Command {
 		},
 	})
 
-	cmd.AddCommand(&cobra.Command{
-		Use:   "stats",
-		Short: "Cache statistics",
+	cmd.AddCommand(cacheStatsCmd())
+	cmd.AddCommand(cacheMigrateNamespaceCmd())
+	cmd.AddCommand(cacheEncryptCmd())
+	cmd.AddCommand(cacheDecryptCmd())
+	cmd.AddCommand(cacheServeCmd())
+
+	return cmd
+}
+
+func cacheMigrateNamespaceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-namespace",
+		Short: "Move cache entries to the canonical namespace layout",
+		Long: `Rewrite cache entries stored under a non-canonical namespace or a flattened model
+name so that they match the current layout, without re-downloading anything.
+
+Namespace aliases (huggingface, torch, modelscope, tf) are moved to their canonical
+form (hf, pytorch, ms, tfhub), and multi-part names stored in a single directory
+(e.g. hf/microsoft_resnet-50 or hf/models--microsoft--resnet-50) are moved to
+hf/microsoft/resnet-50. Use --from and --to to rename any other namespace.
+
+Example:
+  axon cache migrate-namespace --dry-run
+  axon cache migrate-namespace --from hugging --to hf`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cacheMgr := cache.NewManager(cfg.CacheDir)
-			size, err := cacheMgr.GetCacheSize()
-			if err != nil {
-				return fmt.Errorf("failed to get cache size: %w", err)
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+			if (from == "") != (to == "") {
+				return fmt.Errorf("--from and --to must be given together")
 			}
 
-			models, err := cacheMgr.ListCachedModels()
+			renames := map[string]string{}
+			if from != "" {
+				renames[from] = to
+			}
+
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			plan, err := cacheMgr.PlanNamespaceMigration(renames)
 			if err != nil {
-				return fmt.Errorf("failed to list models: %w", err)
+				return err
+			}
+			if len(plan) == 0 {
+				fmt.Println("✓ All cache entries already use the canonical layout")
+				return nil
+			}
+
+			var failed int
+			for _, migration := range plan {
+				src := fmt.Sprintf("%s/%s@%s", migration.From.Namespace, filepath.ToSlash(migration.From.Name), migration.From.Version)
+				dst := fmt.Sprintf("%s/%s@%s", migration.To.Namespace, migration.To.Name, migration.To.Version)
+				if dryRun {
+					note := ""
+					if migration.Conflict {
+						note = " (conflict: target already cached)"
+					}
+					fmt.Printf("  %s -> %s%s\n", src, dst, note)
+					continue
+				}
+				if err := cacheMgr.MigrateModel(migration); err != nil {
+					fmt.Printf("❌ %s: %v\n", src, err)
+					failed++
+					continue
+				}
+				fmt.Printf("✓ Migrated %s -> %s\n", src, dst)
 			}
 
-			fmt.Println("Cache statistics:")
-			fmt.Printf("  Total size: %.2f MB\n", float64(size)/(1024*1024))
-			fmt.Printf("  Models: %d\n", len(models))
+			if dryRun {
+				fmt.Printf("\n%d cache entries would be migrated (dry run)\n", len(plan))
+				return nil
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d cache entries could not be migrated", failed, len(plan))
+			}
 			return nil
 		},
-	})
+	}
 
+	cmd.Flags().Bool("dry-run", false, "Show what would be migrated without changing the cache")
+	cmd.Flags().String("from", "", "Namespace to rename (requires --to)")
+	cmd.Flags().String("to", "", "Canonical namespace for --from")
 	return cmd
 }
 
@@ -1347,7 +2993,7 @@ func configCmd() *cobra.Command {
 		Args:  cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			key, value := args[0], args[1]
-			fmt.Printf("Setting %s = %s\n", key, value)
+			fmt.Printf("Setting %s = %s\n", key, credentials.Redact(value))
 			fmt.Println("(Config set not yet implemented)")
 			return nil
 		},
@@ -1441,6 +3087,8 @@ func registryCmd() *cobra.Command {
 		},
 	})
 
+	cmd.AddCommand(registryExportCmd())
+
 	return cmd
 }
 
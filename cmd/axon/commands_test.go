@@ -1,7 +1,28 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
 )
 
 func TestSafeTempFileName(t *testing.T) {
@@ -73,3 +94,447 @@ func TestSafeTempFileName(t *testing.T) {
 		})
 	}
 }
+
+func TestConversionRecord(t *testing.T) {
+	result := &converter.ConversionResult{
+		Success:     true,
+		Method:      converter.ConversionMethodDocker,
+		Image:       converter.DefaultConverterImage,
+		ImageDigest: "ghcr.io/mlos-foundation/axon-converter@sha256:abc",
+		Opset:       converter.DockerOpsetVersion,
+		Duration:    2500 * time.Millisecond,
+		AllFiles:    []string{"/cache/hf/bert/latest/model.onnx"},
+		Toolchain:   &converter.Toolchain{Python: "3.11.4", Torch: "2.1.0", Transformers: "4.36.0"},
+		Parity:      &converter.ParityReport{MaxAbsDiff: 2e-5, Tolerance: 1e-3, OutputsCompared: 1, Passed: true},
+	}
+
+	c := conversionRecord(result, nil, "/cache/hf/bert/latest")
+	if c.Status != converter.ConversionStatusConverted {
+		t.Errorf("Status = %q, want %q", c.Status, converter.ConversionStatusConverted)
+	}
+	if c.Method != converter.ConversionMethodDocker || c.Image != result.Image || c.ImageDigest != result.ImageDigest {
+		t.Errorf("provenance not copied: %+v", c)
+	}
+	if c.Opset != converter.DockerOpsetVersion {
+		t.Errorf("Opset = %d, want %d", c.Opset, converter.DockerOpsetVersion)
+	}
+	if c.DurationSec != 2.5 {
+		t.Errorf("DurationSec = %v, want 2.5", c.DurationSec)
+	}
+	if c.ConvertedAt.IsZero() {
+		t.Error("ConvertedAt not set")
+	}
+	if len(c.Artifacts) != 1 || c.Artifacts[0].Path != "model.onnx" {
+		t.Fatalf("Artifacts = %+v, want [model.onnx]", c.Artifacts)
+	}
+	if tc := c.Artifacts[0].Toolchain; tc == nil || tc.Torch != "2.1.0" || tc.Opset != converter.DockerOpsetVersion {
+		t.Errorf("Toolchain = %+v, want torch 2.1.0 opset %d", tc, converter.DockerOpsetVersion)
+	}
+	if p := c.Parity; p == nil || p.MaxAbsDiff != 2e-5 || !p.Passed {
+		t.Errorf("Parity = %+v, want the max deviation recorded", p)
+	}
+	if pc := provenanceConverter(c); pc == nil || pc.Parity != c.Parity {
+		t.Errorf("provenanceConverter() = %+v, want the parity check recorded", pc)
+	}
+
+	failed := conversionRecord(&converter.ConversionResult{Method: converter.ConversionMethodPython}, errors.New("boom"), "/cache/hf/bert/latest")
+	if failed.Status != converter.ConversionStatusFailed || failed.Error != "boom" {
+		t.Errorf("failed record = %+v", failed)
+	}
+	if failed.Method != converter.ConversionMethodPython {
+		t.Errorf("Method = %q, want %q", failed.Method, converter.ConversionMethodPython)
+	}
+}
+
+func TestRebuildPackageWithONNX_Provenance(t *testing.T) {
+	// Install: build the original package and extract it into the model directory
+	srcDir := t.TempDir()
+	weights := filepath.Join(srcDir, "model.safetensors")
+	if err := os.WriteFile(weights, []byte("weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	builder, err := core.NewPackageBuilder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = builder.Cleanup() }()
+	if err := builder.AddFile(weights, "model.safetensors"); err != nil {
+		t.Fatal(err)
+	}
+	builder.SetSource(types.ProvenanceSource{Adapter: "huggingface", Model: "org/model", Revision: "abc123"})
+	builder.SetFileURL("model.safetensors", "https://example.com/model.safetensors")
+
+	modelDir := t.TempDir()
+	packagePath := filepath.Join(modelDir, "model.axon")
+	if err := builder.Build(packagePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.ExtractPackage(packagePath, modelDir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Convert, then rebuild
+	if err := os.WriteFile(filepath.Join(modelDir, "model.onnx"), []byte("onnx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conversion := &types.Conversion{
+		Status:    converter.ConversionStatusConverted,
+		Method:    converter.ConversionMethodDocker,
+		Image:     converter.DefaultConverterImage,
+		Opset:     17,
+		Artifacts: []types.ConvertedArtifact{{Path: "model.onnx", Toolchain: &types.Toolchain{Torch: "2.1.0"}}},
+	}
+	written, err := rebuildPackageWithONNX(modelDir, packagePath, conversion)
+	if err != nil {
+		t.Fatalf("rebuildPackageWithONNX() error = %v", err)
+	}
+	// The weights are copied from the old package rather than compressed again
+	if written.Reused != 1 {
+		t.Errorf("reused %d files, want model.safetensors", written.Reused)
+	}
+	if digest, size, err := core.ComputeChecksum(packagePath); err != nil || digest != written.SHA256 || size != written.Size {
+		t.Errorf("package digest = sha256:%s (%d bytes), want sha256:%s (%d bytes)", written.SHA256, written.Size, digest, size)
+	}
+
+	extracted := t.TempDir()
+	if err := model.ExtractPackage(packagePath, extracted); err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range []string{extracted, modelDir} {
+		p, err := core.ReadProvenance(filepath.Join(dir, types.ProvenanceFileName))
+		if err != nil {
+			t.Fatalf("ReadProvenance() error = %v", err)
+		}
+		if p.Source.Revision != "abc123" {
+			t.Errorf("Source = %+v, want revision abc123 kept", p.Source)
+		}
+		if p.Converter == nil || p.Converter.Method != converter.ConversionMethodDocker || p.Converter.Opset != 17 {
+			t.Fatalf("Converter = %+v, want docker opset 17", p.Converter)
+		}
+		if p.Converter.Toolchain == nil || p.Converter.Toolchain.Torch != "2.1.0" || !reflect.DeepEqual(p.Converter.Files, []string{"model.onnx"}) {
+			t.Errorf("Converter = %+v, want model.onnx built with torch 2.1.0", p.Converter)
+		}
+
+		urls := make(map[string]string)
+		for _, file := range p.Files {
+			urls[file.Path] = file.URL
+		}
+		if _, ok := urls[types.ProvenanceFileName]; ok {
+			t.Errorf("Files include %s itself", types.ProvenanceFileName)
+		}
+		if urls["model.safetensors"] != "https://example.com/model.safetensors" {
+			t.Errorf("model.safetensors URL = %q, want original URL kept", urls["model.safetensors"])
+		}
+		if url, ok := urls["model.onnx"]; !ok || url != "" {
+			t.Errorf("model.onnx = %q (listed: %t), want listed without URL", url, ok)
+		}
+	}
+}
+
+func TestProvenanceConverter(t *testing.T) {
+	if pc := provenanceConverter(nil); pc != nil {
+		t.Errorf("provenanceConverter(nil) = %+v, want nil", pc)
+	}
+	if pc := provenanceConverter(&types.Conversion{Status: converter.ConversionStatusFailed}); pc != nil {
+		t.Errorf("provenanceConverter(failed) = %+v, want nil", pc)
+	}
+}
+
+func TestONNXIOSpecs(t *testing.T) {
+	tokenization := &types.PreprocessingSpec{Type: "tokenization", Tokenizer: "tokenizer.json"}
+	previous := []types.IOSpec{
+		{Name: "input_ids", DType: "int64", Shape: []int{-1, -1}, Description: "Token IDs from tokenizer", Preprocessing: tokenization},
+		{Name: "token_type_ids", DType: "int64", Shape: []int{-1, -1}},
+	}
+	tensors := []converter.ONNXTensorInfo{
+		{Name: "input_ids", DType: "int64", Shape: []int{-1, 128}},
+		{Name: "attention_mask", DType: "int64", Shape: []int{-1, 128}},
+		{Name: "untyped"},
+	}
+
+	got := onnxIOSpecs(tensors, previous)
+	want := []types.IOSpec{
+		{Name: "input_ids", DType: "int64", Shape: []int{-1, 128}, Description: "Token IDs from tokenizer", Preprocessing: tokenization},
+		{Name: "attention_mask", DType: "int64", Shape: []int{-1, 128}},
+		{Name: "untyped"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("onnxIOSpecs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSelectUninstallTargets(t *testing.T) {
+	models := []cache.CachedModel{
+		{Namespace: "hf", Name: "bert-base-uncased", Version: "latest"},
+		{Namespace: "hf", Name: "bert-base-uncased", Version: "1.0.0"},
+		{Namespace: "hf", Name: "gpt2", Version: "latest"},
+	}
+
+	tests := []struct {
+		name    string
+		model   string
+		version string
+		all     bool
+		want    []string
+		wantErr bool
+	}{
+		{name: "single version", model: "bert-base-uncased", version: "1.0.0", want: []string{"1.0.0"}},
+		{name: "all versions", model: "bert-base-uncased", all: true, want: []string{"latest", "1.0.0"}},
+		{name: "ambiguous without version", model: "bert-base-uncased", wantErr: true},
+		{name: "only installed version", model: "gpt2", want: []string{"latest"}},
+		{name: "missing version", model: "gpt2", version: "2.0.0", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectUninstallTargets(models, "hf", tt.model, tt.version, tt.all)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("selectUninstallTargets() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var versions []string
+			for _, m := range got {
+				versions = append(versions, m.Version)
+			}
+			if !reflect.DeepEqual(versions, tt.want) {
+				t.Errorf("selectUninstallTargets() = %v, want %v", versions, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := confirm(strings.NewReader(tt.input), "Remove?"); got != tt.want {
+			t.Errorf("confirm(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestRegistrationDryRun(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+	cfg.Tenant = "team-a"
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	manifest := &types.Manifest{
+		Metadata: types.Metadata{Namespace: "hf", Name: "bert", Version: "latest", Description: "BERT \"base\",\nuncased"},
+		Spec: types.Spec{
+			Framework: types.Framework{Name: "PyTorch"},
+			Format:    types.Format{ExecutionFormat: "onnx"},
+			IO: types.IO{
+				Inputs:  []types.IOSpec{{Name: "input_ids", DType: "int64", Shape: []int{-1, 128}, Description: "Token IDs from tokenizer"}},
+				Outputs: []types.IOSpec{{Name: "logits", DType: "float32", Shape: []int{-1, 2}}},
+			},
+		},
+	}
+	if err := cacheMgr.CacheModel("hf", "bert", "latest", manifest); err != nil {
+		t.Fatal(err)
+	}
+	modelPath := cacheMgr.GetModelPath("hf", "bert", "latest")
+	if err := os.WriteFile(filepath.Join(modelPath, "model.onnx"), []byte("onnx"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := registrationDryRun("hf/bert", true, &out); err != nil {
+		t.Fatalf("registrationDryRun() error = %v", err)
+	}
+	var request mlos.RegisterRequest
+	if err := json.Unmarshal(out.Bytes(), &request); err != nil {
+		t.Fatalf("dry run printed invalid JSON: %v\n%s", err, out.String())
+	}
+	digest, _ := model.ModelDigest(modelPath)
+	want := mlos.RegisterRequest{
+		SchemaVersion:   mlos.RegisterSchemaVersion,
+		ModelID:         "team-a:hf/bert@latest",
+		Tenant:          "team-a",
+		Name:            "bert",
+		Framework:       "PyTorch",
+		ExecutionFormat: "onnx",
+		Path:            modelPath,
+		Description:     "BERT \"base\",\nuncased",
+		ManifestPath:    filepath.Join(modelPath, "manifest.yaml"),
+		Digest:          digest,
+		Replace:         true,
+		Inputs:          []mlos.TensorSpec{{Name: "input_ids", DType: "int64", Shape: []int{-1, 128}}},
+		Outputs:         []mlos.TensorSpec{{Name: "logits", DType: "float32", Shape: []int{-1, 2}}},
+	}
+	if !reflect.DeepEqual(request, want) {
+		t.Errorf("request = %+v, want %+v", request, want)
+	}
+	if _, err := os.Stat(registrationsPath()); !os.IsNotExist(err) {
+		t.Errorf("dry run recorded a registration (stat error %v)", err)
+	}
+
+	// A request Core would reject is printed for review but fails
+	manifest.Spec.Format.ExecutionFormat = ""
+	if err := saveManifest(manifest, filepath.Join(modelPath, "manifest.yaml")); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := registrationDryRun("hf/bert", false, &out); err == nil || !strings.Contains(err.Error(), "execution_format") {
+		t.Errorf("registrationDryRun() error = %v, want the missing execution format", err)
+	}
+	if out.Len() == 0 {
+		t.Error("invalid request was not printed")
+	}
+}
+
+func TestRegisterRequest_MultiEncoder(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+
+	target := &registrationTarget{
+		modelID: "hf/clip@latest",
+		manifest: &types.Manifest{
+			Metadata: types.Metadata{Namespace: "hf", Name: "clip", Version: "latest"},
+			Spec: types.Spec{Format: types.Format{
+				ExecutionFormat: "onnx",
+				MultiEncoder:    "multi-encoder",
+				ExecutionFiles: []types.ExecutionFile{
+					{Path: "text_model.onnx", Format: "onnx", Type: "text_encoder"},
+					{Path: "vision_model.onnx", Format: "onnx", Type: "vision_encoder"},
+					{Path: "model.gguf", Format: "gguf", Type: "single"},
+				},
+			}},
+		},
+	}
+
+	request := target.registerRequest("/models/hf/clip/latest", "sha256:"+strings.Repeat("ab", 32), false)
+	if request.MultiEncoder != "multi-encoder" || request.ONNXManifestPath != filepath.Join("/models/hf/clip/latest", model.ONNXManifestFileName) {
+		t.Errorf("request = %+v, want the multi-encoder architecture and onnx_manifest.json", request)
+	}
+	want := []mlos.Component{{Role: "text_encoder", Path: "text_model.onnx"}, {Role: "vision_encoder", Path: "vision_model.onnx"}}
+	if !reflect.DeepEqual(request.Components, want) {
+		t.Errorf("components = %+v, want %+v", request.Components, want)
+	}
+	if err := request.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if request.GPU != nil {
+		t.Errorf("GPU = %+v for a model without GPU requirements", request.GPU)
+	}
+
+	// GPU requirements are passed on for Core to schedule the model
+	target.manifest.Spec.Requirements.Compute.GPU = &types.GPURequirement{Recommended: true, MinVRAMGB: 12, Accelerators: []string{"cuda", "rocm"}}
+	request = target.registerRequest("/models/hf/clip/latest", "sha256:"+strings.Repeat("ab", 32), false)
+	if want := (&mlos.GPURequirement{Recommended: true, MinVRAMGB: 12, Accelerators: []string{"cuda", "rocm"}}); !reflect.DeepEqual(request.GPU, want) {
+		t.Errorf("GPU = %+v, want %+v", request.GPU, want)
+	}
+}
+
+func TestDetermineONNXFileType(t *testing.T) {
+	clip := &converter.MultiEncoderManifest{
+		EncoderType: "clip",
+		Components:  map[string]string{"text_encoder": "onnx/text_model.onnx", "vision_encoder": "onnx/vision_model.onnx"},
+	}
+	seq2seq := &converter.MultiEncoderManifest{Architecture: "encoder-decoder", EncoderType: "seq2seq"}
+
+	tests := []struct {
+		path     string
+		manifest *converter.MultiEncoderManifest
+		want     string
+	}{
+		{"text_model.onnx", clip, "text_encoder"},
+		{"onnx/vision_model.onnx", clip, "vision_encoder"},
+		{"encoder_model.onnx", seq2seq, "encoder"},
+		{"decoder_with_past_model.onnx", seq2seq, "decoder"},
+		{"model.onnx", nil, "single"},
+	}
+	for _, tt := range tests {
+		if got := determineONNXFileType(tt.path, tt.manifest, tt.manifest != nil); got != tt.want {
+			t.Errorf("determineONNXFileType(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestRebuildPackageWithONNX_MissingComponent(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json":              "{}",
+		"text_model.onnx":          "text",
+		model.ONNXManifestFileName: `{"architecture": "multi-encoder", "encoder_type": "clip", "components": {"text_encoder": "text_model.onnx", "vision_encoder": "vision_model.onnx"}, "files": ["text_model.onnx", "vision_model.onnx"]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packagePath := filepath.Join(dir, "hf-clip-latest.axon")
+	_, err := rebuildPackageWithONNX(dir, packagePath, nil)
+	if err == nil || !strings.Contains(err.Error(), "vision_model.onnx") {
+		t.Fatalf("rebuildPackageWithONNX() error = %v, want the missing vision_model.onnx", err)
+	}
+	if pathExists(packagePath) {
+		t.Error("package was rebuilt without a component")
+	}
+}
+
+func TestInfoManifest(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("onnx weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ref, err := spec.Parse("myteam/mymodel@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+		t.Fatal(err)
+	}
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+
+	// The repository is a mirror that has nothing
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	cfg.Rewrites = []config.RewriteConfig{{Match: "myteam/*", To: server.URL}}
+
+	// Installed models are shown from the cache without asking the repository
+	m, namespace, name, err := infoManifest(context.Background(), cacheMgr, ref, "", false)
+	if err != nil {
+		t.Fatalf("infoManifest() error = %v", err)
+	}
+	if namespace != "myteam" || name != "mymodel" || m.Metadata.Version != "1.0.0" || m.Metadata.License != "mit" {
+		t.Errorf("infoManifest() = %s/%s %+v, want the cached manifest", namespace, name, m.Metadata)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("%d requests to the repository for an installed model", n)
+	}
+	if _, _, _, err := infoManifest(context.Background(), cacheMgr, ref, "", true); err == nil || requests.Load() == 0 {
+		t.Errorf("infoManifest() with remote error = %v after %d requests, want the repository asked", err, requests.Load())
+	}
+
+	cached, err := findCachedModel(cacheMgr, "myteam", "mymodel", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := printModelFiles(cacheMgr, cached); err != nil {
+		t.Errorf("printModelFiles() error = %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/manifest"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/telemetry"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// convertTargets lists the execution formats accepted by `axon convert --to`
+var convertTargets = []string{"onnx", "gguf"}
+
+func convertCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "convert [namespace/name[@version] | path]",
+		Short: "Convert an installed model to another execution format",
+		Long: `Run the converter pipeline against a model that is already installed.
+
+The model can be given as a model spec (e.g., hf/bert-base-uncased@latest) or as the
+path to an installed model directory containing manifest.yaml. After conversion the
+manifest's execution format and files are updated and the .axon package is rebuilt.
+
+Supported targets:
+  onnx      Convert to ONNX (Docker converter image or local Python), in fp32, fp16
+            or int8 weights with --precision
+  gguf      Convert a Hugging Face LLM to GGUF with llama.cpp in the converter image,
+            quantized with --quant (models that already ship GGUF files are used as-is)
+
+Examples:
+  axon convert hf/distilbert-base-uncased --to onnx --precision int8
+  axon convert hf/TinyLlama/TinyLlama-1.1B-Chat-v1.0 --to gguf --quant q4_k_m`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, _ := cmd.Flags().GetString("to")
+			if !isConvertTarget(target) {
+				return fmt.Errorf("unsupported conversion target: %s (supported: %v)", target, convertTargets)
+			}
+			opts, err := conversionOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			if target == "gguf" && cmd.Flags().Changed("precision") {
+				return fmt.Errorf("--precision applies to ONNX conversion; use --quant for GGUF")
+			}
+
+			modelPath, err := resolveModelPath(args[0])
+			if err != nil {
+				return err
+			}
+			if cachecrypt.IsEncryptedDir(modelPath) {
+				return errEncryptedModel(modelPath)
+			}
+
+			m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+			if err != nil {
+				return err
+			}
+			namespace, name := m.Metadata.Namespace, m.Metadata.Name
+
+			// Converters load the weights, running whatever pickled weights import
+			if err := checkWeightScanPolicy(cfg.Security, modelPath); err != nil {
+				return fmt.Errorf("refusing to convert %s/%s: %w", namespace, name, err)
+			}
+
+			switch target {
+			case "onnx":
+				if !converter.CanConvert(m.Spec.Framework.Name) {
+					return fmt.Errorf("framework %q of %s/%s cannot be converted to ONNX", m.Spec.Framework.Name, namespace, name)
+				}
+				fmt.Printf("🔄 Converting %s/%s to ONNX...\n", namespace, name)
+				result, err := convertInstalledModel(cmd.Context(), modelPath, m, namespace, name, opts)
+				if err != nil {
+					return fmt.Errorf("ONNX conversion failed: %w", err)
+				}
+				if result.IsMultiEncoder {
+					fmt.Printf("✅ Multi-encoder ONNX conversion successful (%s)\n", result.Architecture)
+					for _, f := range result.AllFiles {
+						fmt.Printf("     - %s\n", filepath.Base(f))
+					}
+				}
+			case "gguf":
+				quant, _ := cmd.Flags().GetString("quant")
+				if err := converter.ValidateGGUFQuant(quant); err != nil {
+					return err
+				}
+				if files, _ := findGGUFFiles(modelPath); len(files) == 0 || cmd.Flags().Changed("quant") {
+					err := withNativeLayout(modelPath, func() error {
+						if !converter.CanConvertToGGUF(modelPath) {
+							return fmt.Errorf("%s/%s cannot be converted to GGUF (needs config.json and safetensors or PyTorch weights)", namespace, name)
+						}
+						result, err := converter.ConvertToGGUF(cmd.Context(), modelPath, namespace, quant, opts)
+						if err != nil {
+							return err
+						}
+						m.Spec.Conversion = conversionRecord(result, nil, modelPath)
+						return nil
+					})
+					if err != nil {
+						return err
+					}
+				}
+				if err := updateInstalledModel(modelPath, m, "gguf"); err != nil {
+					return err
+				}
+			}
+
+			if err := cache.MarkUsed(modelPath); err != nil {
+				fmt.Printf("⚠️  Failed to record the model's use: %v\n", err)
+			}
+			fmt.Printf("✅ %s/%s execution format is now '%s'\n", namespace, name, m.Spec.Format.ExecutionFormat)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("to", "onnx", "Target execution format: onnx, gguf")
+	cmd.Flags().String("quant", converter.DefaultGGUFQuant, "GGUF quantization: f16, q8_0, q6_k, q5_k_m, q5_k_s, q5_0, q4_k_m, q4_k_s, q4_0")
+	addConversionFlags(cmd)
+	return cmd
+}
+
+// isConvertTarget reports whether target is a supported `axon convert --to` value
+func isConvertTarget(target string) bool {
+	for _, t := range convertTargets {
+		if t == target {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveModelPath returns the directory of an installed model given either a
+// directory path or a namespace/name[@version] spec
+func resolveModelPath(arg string) (string, error) {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		if _, err := os.Stat(filepath.Join(arg, "manifest.yaml")); err != nil {
+			return "", fmt.Errorf("%s is not an installed model directory (no manifest.yaml)", arg)
+		}
+		return filepath.Abs(arg)
+	}
+
+	ref, err := spec.Parse(arg)
+	if err != nil {
+		return "", fmt.Errorf("%w (or a model directory)", err)
+	}
+
+	cached, err := findCachedModel(cache.NewManager(cfg.ModelCacheDir()), ref.Namespace, ref.Name, ref.Version)
+	if err != nil {
+		return "", fmt.Errorf("%w\nInstall it first with 'axon install %s'", err, arg)
+	}
+	return cached.Path, nil
+}
+
+// loadManifest reads a manifest.yaml from disk
+func loadManifest(path string) (*types.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	m, err := manifest.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return m, nil
+}
+
+// convertInstalledModel converts an installed model to ONNX in place, then updates its
+// manifest, package and inventory to match the new execution format
+func convertInstalledModel(ctx context.Context, modelPath string, m *types.Manifest, namespace, name string, opts converter.Options) (_ *converter.ConversionResult, err error) {
+	ctx, span := telemetry.Start(ctx, "convert", telemetry.String("framework", m.Spec.Framework.Name))
+	defer func() {
+		conversionFinished(ctx, span, fmt.Sprintf("%s/%s@%s", namespace, name, filepath.Base(modelPath)), modelPath, m, err)
+	}()
+	onnxPath := filepath.Join(modelPath, model.ONNXFileName)
+	if m.Spec.Task == "" {
+		m.Spec.Task = model.DetectDirTask(modelPath)
+	}
+	opts.ModelTask = m.Spec.Task
+	var result *converter.ConversionResult
+	err = withNativeLayout(modelPath, func() (err error) {
+		result, err = converter.ConvertToONNXWithResult(ctx, modelPath, m.Spec.Framework.Name, namespace, conversionModelID(namespace, name), onnxPath, opts)
+		return err
+	})
+	if err == nil && !result.Success {
+		err = fmt.Errorf("no ONNX converter available (install Docker or Python 3 with transformers and torch)")
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if result.IsMultiEncoder {
+		m.Spec.Format.MultiEncoder = result.Architecture
+	}
+	m.Spec.Conversion = conversionRecord(result, nil, modelPath)
+	if err := updateInstalledModel(modelPath, m, "onnx"); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// withNativeLayout runs a converter with the model's weights, tokenizer and config files
+// at the root of modelPath, where converters expect them, and restores the layout after
+func withNativeLayout(modelPath string, convert func() error) error {
+	restore, err := model.NativeLayout(modelPath)
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s for conversion: %w", modelPath, err)
+	}
+	return errors.Join(convert(), restore())
+}
+
+// updateInstalledModel sets the execution format of an installed model and brings its
+// manifest, .axon package and files.json in line with the files on disk
+func updateInstalledModel(modelPath string, m *types.Manifest, executionFormat string) error {
+	if err := normalizeModelLayout(modelPath); err != nil {
+		return err
+	}
+	if err := updateManifestAfterInstall(modelPath, m); err != nil {
+		return fmt.Errorf("failed to update manifest: %w", err)
+	}
+	m.Spec.Format.ExecutionFormat = executionFormat
+	if err := saveManifest(m, filepath.Join(modelPath, "manifest.yaml")); err != nil {
+		return fmt.Errorf("failed to save manifest: %w", err)
+	}
+
+	// Keep the cached package in sync with the converted files
+	if packages, _ := filepath.Glob(filepath.Join(modelPath, "*.axon")); len(packages) > 0 {
+		if _, err := rebuildPackageWithONNX(modelPath, packages[0], m.Spec.Conversion); err != nil {
+			fmt.Printf("⚠️  Failed to rebuild package: %v\n", err)
+		}
+	}
+
+	// Refresh the integrity manifest so verify doesn't flag the new files
+	if _, err := model.ReadInventory(modelPath); err == nil {
+		if inventory, err := model.WriteInventory(modelPath); err != nil {
+			fmt.Printf("⚠️  Failed to update %s: %v\n", model.InventoryFileName, err)
+		} else {
+			recordIntegrity(cache.NewManager(cfg.ModelCacheDir()), modelPath, inventory)
+		}
+	}
+
+	return nil
+}
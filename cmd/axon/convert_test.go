@@ -0,0 +1,37 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveModelPath_Directory(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := resolveModelPath(dir); err == nil {
+		t.Error("resolveModelPath() expected error for directory without manifest.yaml")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("apiVersion: v1\n"), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	got, err := resolveModelPath(dir)
+	if err != nil {
+		t.Fatalf("resolveModelPath() error = %v", err)
+	}
+	if got != dir {
+		t.Errorf("resolveModelPath() = %q, want %q", got, dir)
+	}
+}
+
+func TestIsConvertTarget(t *testing.T) {
+	for _, target := range []string{"onnx", "gguf"} {
+		if !isConvertTarget(target) {
+			t.Errorf("isConvertTarget(%q) = false, want true", target)
+		}
+	}
+	if isConvertTarget("tflite") {
+		t.Error("isConvertTarget(tflite) = true, want false")
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+// coreProbeTimeout bounds the capability probe on install, so a Core that isn't
+// running doesn't hold up installs
+const coreProbeTimeout = 3 * time.Second
+
+func coreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "core",
+		Short: "Inspect the MLOS Core instance models are registered with",
+	}
+	cmd.AddCommand(coreStatusCmd())
+	return cmd
+}
+
+func coreStatusCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Check MLOS Core health and what it can execute",
+		Long: `Check that MLOS Core at $MLOS_CORE_ENDPOINT (default http://localhost:8080) is
+healthy and show what it can execute: its version, the runtime plugins it has loaded
+(e.g. onnx, gguf) and the execution formats they accept.
+
+'axon install' asks Core the same question to decide whether a model needs ONNX
+conversion (see 'axon install --help'). Fails if Core is unreachable or unhealthy.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return coreStatus(cmd.Context(), mlos.NewClient(mlos.EndpointFromEnv()), os.Stdout)
+		},
+	}
+}
+
+// coreStatus writes the health and capabilities of Core to out
+func coreStatus(ctx context.Context, client *mlos.Client, out io.Writer) error {
+	fmt.Fprintf(out, "🔌 MLOS Core at %s\n", client.Endpoint())
+	if err := client.Health(ctx); err != nil {
+		return fmt.Errorf("%w\nMake sure MLOS Core is running: mlos_core", err)
+	}
+	fmt.Fprintf(out, "✓ Healthy\n")
+
+	caps, err := client.Capabilities(ctx)
+	if errors.Is(err, mlos.ErrCapabilitiesUnsupported) {
+		fmt.Fprintf(out, "⚠️  MLOS Core does not report its version or runtime plugins (older release)\n")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query MLOS Core capabilities: %w", err)
+	}
+
+	version := caps.Version
+	if version == "" {
+		version = "unknown"
+	}
+	fmt.Fprintf(out, "   Version: %s\n", version)
+	fmt.Fprintf(out, "   Runtime plugins: %s\n", joinOrNone(caps.RuntimePlugins))
+	fmt.Fprintf(out, "   Execution formats: %s\n", joinOrNone(caps.Formats()))
+	return nil
+}
+
+// joinOrNone joins values with commas, or returns "none" if there are none
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
+
+// coreCapabilities returns what the MLOS Core at $MLOS_CORE_ENDPOINT can execute, or nil
+// if Core isn't reachable or doesn't report it; replaced in tests
+var coreCapabilities = func(ctx context.Context) *mlos.Capabilities {
+	ctx, cancel := context.WithTimeout(ctx, coreProbeTimeout)
+	defer cancel()
+	caps, err := mlos.NewClient(mlos.EndpointFromEnv()).Capabilities(ctx)
+	if err != nil {
+		return nil
+	}
+	return caps
+}
+
+// installConversionNeeded decides whether install converts a model in cachePath to ONNX.
+// Formats that are execution-ready (GGUF, ONNX, PyTorch) can be used directly by MLOS
+// Core; their files are verified on disk rather than trusting the manifest. With
+// --format auto, a running Core's capabilities take precedence (onnxConversionNeeded).
+func installConversionNeeded(ctx context.Context, targetFormat, format, cachePath string) (bool, string) {
+	ready := converter.IsExecutionReadyWithPath(format, cachePath)
+	var caps *mlos.Capabilities
+	if targetFormat == "auto" || targetFormat == "" {
+		caps = coreCapabilities(ctx)
+	}
+	return onnxConversionNeeded(format, ready, caps)
+}
+
+// fileCheckedFormats are the execution formats whose files Axon checks before it
+// considers a model execution-ready; without those files Core can't run them either
+var fileCheckedFormats = map[string]bool{"onnx": true, "gguf": true, "pytorch": true, "torchscript": true}
+
+// onnxConversionNeeded decides whether 'axon install --format auto' converts a model
+// with the given execution format to ONNX, and why. Without Core capabilities, formats
+// that are execution-ready are kept. With them, Core decides: a format it executes
+// natively is kept (e.g. safetensors with a matching plugin), and an execution-ready
+// format it has no runtime plugin for is converted, when Core runs ONNX.
+func onnxConversionNeeded(format string, ready bool, caps *mlos.Capabilities) (bool, string) {
+	lower := strings.ToLower(format)
+	switch {
+	case caps == nil || lower == "" || lower == "onnx":
+	case caps.Supports(format) && (ready || !fileCheckedFormats[lower]):
+		return false, fmt.Sprintf("MLOS Core runs format '%s' natively", format)
+	case ready && caps.Supports("onnx"):
+		return true, fmt.Sprintf("MLOS Core has no runtime plugin for format '%s' (loaded: %s); converting to ONNX", format, joinOrNone(caps.RuntimePlugins))
+	}
+	if ready {
+		return false, fmt.Sprintf("Format '%s' is execution-ready (verified files exist)", format)
+	}
+	return true, ""
+}
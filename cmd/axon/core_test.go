@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+func TestCoreStatus(t *testing.T) {
+	capabilities := `{"version": "1.6.0", "runtime_plugins": ["onnx", "gguf"], "execution_formats": ["onnx", "gguf"]}`
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/capabilities":
+			_, _ = w.Write([]byte(capabilities))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer core.Close()
+
+	var out bytes.Buffer
+	if err := coreStatus(context.Background(), mlos.NewClient(core.URL), &out); err != nil {
+		t.Fatalf("coreStatus() error = %v", err)
+	}
+	for _, want := range []string{"✓ Healthy", "Version: 1.6.0", "Runtime plugins: onnx, gguf", "Execution formats: onnx, gguf"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out.String())
+		}
+	}
+
+	// A Core that isn't running is an error
+	core.Close()
+	if err := coreStatus(context.Background(), mlos.NewClient(core.URL), &bytes.Buffer{}); err == nil {
+		t.Error("coreStatus() of a stopped Core succeeded")
+	}
+}
+
+func TestONNXConversionNeeded(t *testing.T) {
+	onnxOnly := &mlos.Capabilities{RuntimePlugins: []string{"onnx"}}
+	withSafetensors := &mlos.Capabilities{RuntimePlugins: []string{"onnx", "candle"}, ExecutionFormats: []string{"onnx", "safetensors", "pytorch"}}
+
+	tests := []struct {
+		name   string
+		format string
+		ready  bool
+		caps   *mlos.Capabilities
+		want   bool
+	}{
+		{name: "ready without Core", format: "pytorch", ready: true, want: false},
+		{name: "not ready without Core", format: "safetensors", want: true},
+		{name: "onnx files missing", format: "onnx", caps: onnxOnly, want: true},
+		{name: "no execution format", caps: withSafetensors, want: true},
+		{name: "torchscript without a Core plugin", format: "pytorch", ready: true, caps: onnxOnly, want: true},
+		{name: "gguf without any matching plugin", format: "gguf", ready: true, caps: &mlos.Capabilities{}, want: false},
+		{name: "safetensors run natively", format: "safetensors", caps: withSafetensors, want: false},
+		{name: "pytorch without TorchScript files", format: "pytorch", caps: withSafetensors, want: true},
+		{name: "torchscript run natively", format: "pytorch", ready: true, caps: withSafetensors, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := onnxConversionNeeded(tt.format, tt.ready, tt.caps)
+			if got != tt.want {
+				t.Errorf("onnxConversionNeeded(%q, %t) = %t (%s), want %t", tt.format, tt.ready, got, reason, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("skipping conversion without a reason")
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/host"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// doctorResources names the resources of host checks in reports
+var doctorResources = map[string]string{
+	"cpu":    "CPU",
+	"memory": "Memory",
+	"gpu":    "GPU",
+	"disk":   "Disk",
+}
+
+func doctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor <namespace/name[@version]>",
+		Short: "Check whether this host can run a model",
+		Long: `Compare a model's hardware requirements with this host's CPU cores, memory, GPUs
+and free disk space. Each is reported as:
+
+  pass   meets the recommended hardware
+  warn   meets the minimum but not the recommended hardware: the model runs, slower
+  fail   below the minimum: the model won't run well, if at all
+
+The requirements are those of the installed model, or, for a model that isn't
+installed, of the repository's manifest; only then is the free space of the cache
+disk checked. Requirements a repository couldn't size are estimated from the model's
+weight files. Exits non-zero when a check fails.
+
+Examples:
+  axon doctor hf/bert-base-uncased
+  axon doctor hf/meta-llama/Llama-3.1-8B`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m, modelPath, err := doctorManifest(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			requirements := doctorRequirements(m, modelPath)
+
+			info := host.Detect(cmd.Context(), existingParent(cfg.ModelCacheDir()))
+			checks := info.CheckRequirements(requirements, modelPath != "")
+
+			ref := fmt.Sprintf("%s/%s@%s", m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version)
+			fmt.Printf("🩺 Checking %s against this host\n", ref)
+			for _, check := range checks {
+				fmt.Printf("   %s %-7s %s\n", doctorIcon(check.Status), doctorResources[check.Resource], check.Detail)
+			}
+			fmt.Println()
+
+			switch host.Worst(checks) {
+			case host.StatusFail:
+				cmd.SilenceUsage = true
+				return fmt.Errorf("this host does not meet the minimum requirements of %s", ref)
+			case host.StatusWarn:
+				fmt.Printf("⚠️  This host meets the minimum requirements of %s, but not all recommended ones\n", ref)
+			default:
+				fmt.Printf("✅ This host meets the requirements of %s\n", ref)
+			}
+			return nil
+		},
+	}
+}
+
+// doctorManifest returns the manifest of the installed model and its directory, or else
+// the repository's manifest and an empty path
+func doctorManifest(ctx context.Context, arg string) (*types.Manifest, string, error) {
+	if modelPath, err := resolveModelPath(arg); err == nil {
+		m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+		return m, modelPath, err
+	}
+
+	ref, err := spec.Parse(arg)
+	if err != nil {
+		return nil, "", err
+	}
+	adapter, namespace, name, err := findModelAdapter("", ref.Namespace, ref.Name)
+	if err != nil {
+		return nil, "", err
+	}
+	m, err := core.ResolveManifest(ctx, adapter, namespace, name, ref.Version)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get model information: %w", err)
+	}
+	return m, "", nil
+}
+
+// doctorRequirements returns the model's requirements, estimated from its files when the
+// manifest only has placeholders
+func doctorRequirements(m *types.Manifest, modelPath string) types.Requirements {
+	if !model.IsPlaceholderRequirements(m.Spec.Requirements) {
+		return m.Spec.Requirements
+	}
+	estimate, ok := model.EstimateRequirements(m.Spec.Format.Files, 0)
+	if modelPath != "" {
+		estimate, ok = model.EstimateDirRequirements(modelPath)
+	}
+	if !ok {
+		fmt.Printf("ℹ️  The model's size is unknown; checking the default requirements\n")
+		return model.DefaultRequirements()
+	}
+	return estimate
+}
+
+// doctorIcon marks a check status in reports
+func doctorIcon(status string) string {
+	switch status {
+	case host.StatusFail:
+		return "❌"
+	case host.StatusWarn:
+		return "⚠️ "
+	}
+	return "✅"
+}
+
+// existingParent returns path, or its closest ancestor that exists
+func existingParent(path string) string {
+	for !pathExists(path) && filepath.Dir(path) != path {
+		path = filepath.Dir(path)
+	}
+	return path
+}
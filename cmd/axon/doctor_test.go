@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestDoctorRequirements(t *testing.T) {
+	modelDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(modelDir, "model.safetensors"), make([]byte, 4<<20), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Placeholder requirements are estimated from the installed files
+	m := &types.Manifest{}
+	m.Spec.Requirements = model.DefaultRequirements()
+	got := doctorRequirements(m, modelDir)
+	if want, _ := model.EstimateDirRequirements(modelDir); !reflect.DeepEqual(got, want) {
+		t.Errorf("doctorRequirements() = %+v, want the estimate %+v", got, want)
+	}
+
+	// Requirements set by the publisher are checked as they are
+	m.Spec.Requirements.Compute.Memory.MinGB = 24
+	if got := doctorRequirements(m, modelDir); got.Compute.Memory.MinGB != 24 {
+		t.Errorf("doctorRequirements() = %+v, want the manifest's requirements", got)
+	}
+
+	// A model that isn't installed is estimated from the files its manifest lists
+	m = &types.Manifest{}
+	m.Spec.Format.Files = []types.ModelFile{{Path: "model.safetensors", Size: 8 << 30}}
+	if got := doctorRequirements(m, ""); got.Compute.GPU == nil {
+		t.Errorf("doctorRequirements() = %+v, want a GPU recommended for 8GB of weights", got)
+	}
+}
+
+func TestExistingParent(t *testing.T) {
+	dir := t.TempDir()
+	if got := existingParent(filepath.Join(dir, "cache", "models")); got != dir {
+		t.Errorf("existingParent() = %q, want %q", got, dir)
+	}
+}
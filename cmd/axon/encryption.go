@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// cacheKeyName is the keyring entry holding the cache encryption key
+const cacheKeyName = "cache-encryption"
+
+// cacheKeyEnv overrides the configured key source with a base64 key
+const cacheKeyEnv = "AXON_CACHE_KEY"
+
+// Cache encryption key sources
+const (
+	keySourceKeyring = "keyring"
+	keySourceCommand = "command"
+)
+
+// runKeyCommand runs the configured key command and returns its output; replaced in tests
+var runKeyCommand = func(command string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// cacheEncryptionKey returns the cache encryption key. With create set, a missing
+// keyring key is generated and stored; otherwise a missing key is an error.
+func cacheEncryptionKey(create bool) ([]byte, error) {
+	if encoded := os.Getenv(cacheKeyEnv); encoded != "" {
+		credentials.RegisterSecret(encoded)
+		return cachecrypt.ParseKey(encoded)
+	}
+
+	enc := cfg.Cache.Encryption
+	switch enc.KeySource {
+	case keySourceCommand:
+		if enc.KeyCommand == "" {
+			return nil, fmt.Errorf("cache.encryption.key_source is %q but cache.encryption.key_command is not set", keySourceCommand)
+		}
+		out, err := runKeyCommand(enc.KeyCommand)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run cache.encryption.key_command: %w", err)
+		}
+		encoded := strings.TrimSpace(string(out))
+		credentials.RegisterSecret(encoded)
+		return cachecrypt.ParseKey(encoded)
+	case "", keySourceKeyring:
+		manager := newCredentialManager()
+		if encoded, err := manager.Get(cacheKeyName); err == nil {
+			return cachecrypt.ParseKey(encoded)
+		}
+		if !create {
+			return nil, fmt.Errorf("no cache encryption key in the keyring (set %s or configure cache.encryption.key_command)", cacheKeyEnv)
+		}
+		key, err := cachecrypt.GenerateKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate cache encryption key: %w", err)
+		}
+		backend, err := manager.Set(cacheKeyName, cachecrypt.EncodeKey(key))
+		if err != nil {
+			return nil, fmt.Errorf("failed to store cache encryption key: %w", err)
+		}
+		fmt.Printf("🔑 Generated cache encryption key (stored in %s)\n", backend)
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unknown cache.encryption.key_source %q (expected %q or %q)", enc.KeySource, keySourceKeyring, keySourceCommand)
+	}
+}
+
+// isEncryptedFile reports whether an installed file is encrypted at rest: weight files and
+// packages. Manifests, configs and tokenizers stay readable.
+func isEncryptedFile(relPath string) bool {
+	return model.IsWeightFile(relPath) || strings.HasSuffix(relPath, ".axon")
+}
+
+// encryptModelFiles encrypts the weight files and package of an installed model in place
+func encryptModelFiles(modelPath string) (int, error) {
+	key, err := cacheEncryptionKey(true)
+	if err != nil {
+		return 0, err
+	}
+	n, err := cachecrypt.EncryptDir(modelPath, key, isEncryptedFile)
+	if err != nil {
+		return n, fmt.Errorf("failed to encrypt model files: %w", err)
+	}
+	return n, nil
+}
+
+// decryptedModelPath returns where the decrypted view of a model is placed for Core
+func decryptedModelPath(namespace, name, version string) string {
+	return spec.NewModelRef(namespace, name, version).Path(cfg.DecryptedModelsPath())
+}
+
+// resolveCorePath returns the directory MLOS Core should load a model from. Models with
+// encrypted files are decrypted into the runtime directory (unencrypted files are linked),
+// so weights are only ever in plaintext on memory-backed storage.
+func resolveCorePath(modelPath, namespace, name, version string) (string, error) {
+	if !cachecrypt.IsEncryptedDir(modelPath) {
+		return modelPath, nil
+	}
+	key, err := cacheEncryptionKey(false)
+	if err != nil {
+		return "", err
+	}
+	dest := decryptedModelPath(namespace, name, version)
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return "", fmt.Errorf("failed to create decrypted models directory: %w", err)
+	}
+	if err := cachecrypt.Materialize(modelPath, dest, key); err != nil {
+		return "", fmt.Errorf("failed to decrypt model for MLOS Core: %w", err)
+	}
+	fmt.Printf("🔓 Decrypted model for MLOS Core: %s\n", dest)
+	return dest, nil
+}
+
+// removeDecrypted removes the decrypted view of a model, if there is one
+func removeDecrypted(namespace, name, version string) error {
+	return os.RemoveAll(decryptedModelPath(namespace, name, version))
+}
+
+// errEncryptedModel explains that a command needs the plaintext files of a model
+func errEncryptedModel(modelPath string) error {
+	return fmt.Errorf("model files in %s are encrypted; decrypt them first with 'axon cache decrypt'", modelPath)
+}
+
+// selectCachedModels returns the cached models named by args, or every model with all set
+func selectCachedModels(cacheMgr *cache.Manager, args []string, all bool) ([]cache.CachedModel, error) {
+	if all == (len(args) > 0) {
+		return nil, fmt.Errorf("specify a model or --all")
+	}
+	if all {
+		return cacheMgr.ListCachedModels()
+	}
+	ref, err := spec.Parse(args[0])
+	if err != nil {
+		return nil, err
+	}
+	cached, err := findCachedModel(cacheMgr, ref.Namespace, ref.Name, ref.Version)
+	if err != nil {
+		return nil, err
+	}
+	return []cache.CachedModel{*cached}, nil
+}
+
+func cacheEncryptCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "encrypt [namespace/name[@version]]",
+		Short: "Encrypt cached model weights at rest",
+		Long: `Encrypt the weight files and package of installed models with the cache encryption key.
+
+The key is read from $AXON_CACHE_KEY, cache.encryption.key_command, or the OS keyring
+(where one is generated on first use). Set cache.encryption.enabled to encrypt models
+as they are installed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			models, err := selectCachedModels(cache.NewManager(cfg.ModelCacheDir()), args, all)
+			if err != nil {
+				return err
+			}
+			for _, m := range models {
+				n, err := encryptModelFiles(m.Path)
+				if err != nil {
+					return fmt.Errorf("failed to encrypt %s/%s@%s: %w", m.Namespace, m.Name, m.Version, err)
+				}
+				fmt.Printf("🔒 %s/%s@%s: encrypted %d file(s)\n", m.Namespace, m.Name, m.Version, n)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Encrypt every cached model")
+	return cmd
+}
+
+func cacheDecryptCmd() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "decrypt [namespace/name[@version]]",
+		Short: "Decrypt cached model weights in place",
+		Long: `Decrypt the encrypted files of installed models back into the cache, e.g. to convert
+or extract them. Models registered with MLOS Core don't need this: they are decrypted
+into the runtime directory on registration.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			models, err := selectCachedModels(cache.NewManager(cfg.ModelCacheDir()), args, all)
+			if err != nil {
+				return err
+			}
+			var key []byte
+			for _, m := range models {
+				if !cachecrypt.IsEncryptedDir(m.Path) {
+					continue
+				}
+				if key == nil {
+					if key, err = cacheEncryptionKey(false); err != nil {
+						return err
+					}
+				}
+				n, err := cachecrypt.DecryptDir(m.Path, key)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt %s/%s@%s: %w", m.Namespace, m.Name, m.Version, err)
+				}
+				fmt.Printf("🔓 %s/%s@%s: decrypted %d file(s)\n", m.Namespace, m.Name, m.Version, n)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Decrypt every cached model")
+	return cmd
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+)
+
+// withEncryptionConfig installs a default config and an empty file-backed keyring
+func withEncryptionConfig(t *testing.T) *credentials.FileStore {
+	t.Helper()
+	originalCfg, originalManager, originalRun := cfg, newCredentialManager, runKeyCommand
+	t.Cleanup(func() { cfg, newCredentialManager, runKeyCommand = originalCfg, originalManager, originalRun })
+
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.Cache.Encryption.DecryptedDir = t.TempDir()
+	store := credentials.NewFileStore(cfg.HomeDir)
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, store)
+	}
+	t.Setenv(cacheKeyEnv, "")
+	return store
+}
+
+func TestCacheEncryptionKey(t *testing.T) {
+	key, err := cachecrypt.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	encoded := cachecrypt.EncodeKey(key)
+
+	t.Run("keyring creates key on first use", func(t *testing.T) {
+		store := withEncryptionConfig(t)
+		if _, err := cacheEncryptionKey(false); err == nil {
+			t.Fatal("cacheEncryptionKey(false) expected error with empty keyring")
+		}
+		created, err := cacheEncryptionKey(true)
+		if err != nil {
+			t.Fatalf("cacheEncryptionKey(true) error = %v", err)
+		}
+		if stored, err := store.Get(cacheKeyName); err != nil || stored != cachecrypt.EncodeKey(created) {
+			t.Errorf("keyring holds %q, %v; want the generated key", stored, err)
+		}
+		again, err := cacheEncryptionKey(false)
+		if err != nil || !bytes.Equal(again, created) {
+			t.Errorf("cacheEncryptionKey(false) = %x, %v; want the stored key", again, err)
+		}
+	})
+
+	t.Run("key command", func(t *testing.T) {
+		withEncryptionConfig(t)
+		cfg.Cache.Encryption.KeySource = keySourceCommand
+		cfg.Cache.Encryption.KeyCommand = "kms-decrypt"
+		runKeyCommand = func(command string) ([]byte, error) {
+			if command != "kms-decrypt" {
+				t.Errorf("runKeyCommand(%q), want kms-decrypt", command)
+			}
+			return []byte(encoded + "\n"), nil
+		}
+		got, err := cacheEncryptionKey(true)
+		if err != nil || !bytes.Equal(got, key) {
+			t.Errorf("cacheEncryptionKey() = %x, %v; want the command's key", got, err)
+		}
+	})
+
+	t.Run("key command not set", func(t *testing.T) {
+		withEncryptionConfig(t)
+		cfg.Cache.Encryption.KeySource = keySourceCommand
+		if _, err := cacheEncryptionKey(true); err == nil {
+			t.Error("cacheEncryptionKey() expected error without key_command")
+		}
+	})
+
+	t.Run("environment overrides key source", func(t *testing.T) {
+		withEncryptionConfig(t)
+		cfg.Cache.Encryption.KeySource = "kms"
+		t.Setenv(cacheKeyEnv, encoded)
+		got, err := cacheEncryptionKey(false)
+		if err != nil || !bytes.Equal(got, key) {
+			t.Errorf("cacheEncryptionKey() = %x, %v; want $%s", got, err, cacheKeyEnv)
+		}
+	})
+
+	t.Run("unknown key source", func(t *testing.T) {
+		withEncryptionConfig(t)
+		cfg.Cache.Encryption.KeySource = "kms"
+		if _, err := cacheEncryptionKey(true); err == nil {
+			t.Error("cacheEncryptionKey() expected error for unknown key source")
+		}
+	})
+}
+
+func TestResolveCorePath(t *testing.T) {
+	withEncryptionConfig(t)
+
+	modelPath := t.TempDir()
+	files := map[string]string{
+		"manifest.yaml":     "kind: Model",
+		"config.json":       "{}",
+		"model.safetensors": "weights",
+		"model.axon":        "package",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(modelPath, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	if got, err := resolveCorePath(modelPath, "hf", "org/model", "1.0"); err != nil || got != modelPath {
+		t.Fatalf("resolveCorePath() unencrypted = %q, %v; want the cache path", got, err)
+	}
+
+	n, err := encryptModelFiles(modelPath)
+	if err != nil {
+		t.Fatalf("encryptModelFiles() error = %v", err)
+	}
+	if n != 2 {
+		t.Errorf("encryptModelFiles() = %d, want 2 (weights and package)", n)
+	}
+	if _, err := os.Stat(filepath.Join(modelPath, "model.safetensors")); !os.IsNotExist(err) {
+		t.Error("plaintext weights left in the cache after encryption")
+	}
+
+	got, err := resolveCorePath(modelPath, "hf", "org/model", "1.0")
+	if err != nil {
+		t.Fatalf("resolveCorePath() error = %v", err)
+	}
+	if want := decryptedModelPath("hf", "org/model", "1.0"); got != want {
+		t.Errorf("resolveCorePath() = %q, want %q", got, want)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(got, name))
+		if err != nil || string(data) != content {
+			t.Errorf("decrypted %s = %q, %v; want %q", name, data, err, content)
+		}
+	}
+
+	if err := removeDecrypted("hf", "org/model", "1.0"); err != nil {
+		t.Fatalf("removeDecrypted() error = %v", err)
+	}
+	if _, err := os.Stat(got); !os.IsNotExist(err) {
+		t.Error("removeDecrypted() left the decrypted copy behind")
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// modelInstaller installs a model; replaced in tests
+var modelInstaller = installModel
+
+// coreOrphan is a model registered with Core whose files are gone
+type coreOrphan struct {
+	ModelID string
+	Path    string // Where Core loads the model from; empty if Core doesn't say
+	Cached  bool   // The model is still in the cache, so re-registering fixes it
+}
+
+// findCoreOrphans returns the registrations whose path no longer exists. Registrations
+// without a path are orphans only if the model isn't in the cache either.
+func findCoreOrphans(models []mlos.RegisteredModel, cacheMgr *cache.Manager) []coreOrphan {
+	var orphans []coreOrphan
+	for _, m := range models {
+		if m.Path != "" && pathExists(m.Path) {
+			continue
+		}
+		cached := false
+		if ref, err := spec.Parse(m.ModelID); err == nil {
+			_, err := findCachedModel(cacheMgr, ref.Namespace, ref.Name, ref.Version)
+			cached = err == nil
+		}
+		if m.Path == "" && cached {
+			continue
+		}
+		orphans = append(orphans, coreOrphan{ModelID: m.ModelID, Path: m.Path, Cached: cached})
+	}
+	return orphans
+}
+
+// defaultInstallOptions returns the install options 'axon install' uses without flags
+func defaultInstallOptions() installOptions {
+	opts := installOptions{
+		format:      "auto",
+		onnxPolicy:  cfg.Conversion.ONNXPolicy,
+		conversion:  configuredConversionOptions(),
+		concurrency: 1,
+	}
+	if opts.onnxPolicy == "" {
+		opts.onnxPolicy = converter.ONNXPolicyPrefer
+	}
+	return opts
+}
+
+// fixCoreOrphan unregisters an orphan, or reinstalls it (if it isn't cached) and
+// registers it again
+func fixCoreOrphan(ctx context.Context, client *mlos.Client, orphan coreOrphan, unregister bool) error {
+	if unregister {
+		if err := client.UnregisterModel(ctx, coreModelID(orphan.ModelID)); err != nil {
+			return err
+		}
+		regs, err := mlos.LoadRegistrations(registrationsPath())
+		if err != nil {
+			return err
+		}
+		if regs.Remove(orphan.ModelID) {
+			return regs.Save()
+		}
+		return nil
+	}
+
+	if !orphan.Cached {
+		if err := modelInstaller(ctx, orphan.ModelID, defaultInstallOptions()); err != nil {
+			return fmt.Errorf("reinstall failed: %w", err)
+		}
+	}
+	var convert bool
+	if regs, err := mlos.LoadRegistrations(registrationsPath()); err == nil {
+		for _, reg := range regs.Models {
+			if reg.ModelID == orphan.ModelID {
+				convert = reg.Convert
+			}
+		}
+	}
+	return modelRegistrar(ctx, orphan.ModelID, convert, true)
+}
+
+func gcCmd() *cobra.Command {
+	var coreOrphans, unregister, reinstall bool
+
+	cmd := &cobra.Command{
+		Use:   "gc",
+		Short: "Find and clean up stale state",
+		Long: `Find state that no longer matches the local cache.
+
+--core-orphans lists the models registered with MLOS Core whose files are gone,
+e.g. after the cache was deleted by hand. Add --unregister to remove them from Core,
+or --reinstall to install them again (if they are no longer cached) and re-register
+them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !coreOrphans {
+				return fmt.Errorf("nothing to do: specify --core-orphans")
+			}
+			if unregister && reinstall {
+				return fmt.Errorf("--unregister and --reinstall cannot be combined")
+			}
+
+			client := mlos.NewClient(mlos.EndpointFromEnv())
+			models, err := client.ListModels(cmd.Context())
+			if err != nil {
+				return fmt.Errorf("failed to list models registered with MLOS Core: %w", err)
+			}
+
+			models = tenantRegisteredModels(models)
+			orphans := findCoreOrphans(models, cache.NewManager(cfg.ModelCacheDir()))
+			if len(orphans) == 0 {
+				fmt.Printf("✓ All %d model(s) registered with MLOS Core are backed by files\n", len(models))
+				return nil
+			}
+
+			fmt.Printf("Found %d orphaned registration(s) in MLOS Core:\n", len(orphans))
+			for _, orphan := range orphans {
+				state := "not in cache"
+				if orphan.Cached {
+					state = "still cached, re-register to fix"
+				}
+				path := orphan.Path
+				if path == "" {
+					path = "(no path reported)"
+				}
+				fmt.Printf("  %s  %s  [%s]\n", orphan.ModelID, path, state)
+			}
+			if !unregister && !reinstall {
+				fmt.Println("\nRun with --unregister to remove them from MLOS Core, or --reinstall to restore them")
+				return nil
+			}
+
+			var failures int
+			for _, orphan := range orphans {
+				if err := fixCoreOrphan(cmd.Context(), client, orphan, unregister); err != nil {
+					fmt.Printf("✗ %s: %v\n", orphan.ModelID, err)
+					failures++
+					continue
+				}
+				if unregister {
+					fmt.Printf("✓ Unregistered %s\n", orphan.ModelID)
+				} else {
+					fmt.Printf("✓ Restored %s\n", orphan.ModelID)
+				}
+			}
+			if failures > 0 {
+				return fmt.Errorf("%d of %d orphaned registration(s) could not be fixed", failures, len(orphans))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&coreOrphans, "core-orphans", false, "Find models registered with MLOS Core whose files are missing")
+	cmd.Flags().BoolVar(&unregister, "unregister", false, "Unregister the orphaned models from MLOS Core")
+	cmd.Flags().BoolVar(&reinstall, "reinstall", false, "Reinstall (if needed) and re-register the orphaned models")
+	return cmd
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestFindCoreOrphans(t *testing.T) {
+	cacheMgr := cache.NewManager(t.TempDir())
+	manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: "cached", Version: "latest"}}
+	if err := cacheMgr.CacheModel("hf", "cached", "latest", manifest); err != nil {
+		t.Fatal(err)
+	}
+	cachedPath := cacheMgr.GetModelPath("hf", "cached", "latest")
+
+	models := []mlos.RegisteredModel{
+		{ModelID: "hf/cached@latest", Path: cachedPath},
+		{ModelID: "hf/cached@latest", Path: "/run/axon/models/hf/cached/latest"}, // Stale decrypted copy
+		{ModelID: "hf/deleted@latest", Path: "/cache/hf/deleted/latest"},
+		{ModelID: "hf/cached@latest"},  // No path, but cached
+		{ModelID: "hf/unknown@latest"}, // No path and not cached
+	}
+
+	orphans := findCoreOrphans(models, cacheMgr)
+	want := []coreOrphan{
+		{ModelID: "hf/cached@latest", Path: "/run/axon/models/hf/cached/latest", Cached: true},
+		{ModelID: "hf/deleted@latest", Path: "/cache/hf/deleted/latest", Cached: false},
+		{ModelID: "hf/unknown@latest", Cached: false},
+	}
+	if len(orphans) != len(want) {
+		t.Fatalf("findCoreOrphans() = %+v, want %+v", orphans, want)
+	}
+	for i := range want {
+		if orphans[i] != want[i] {
+			t.Errorf("orphan %d = %+v, want %+v", i, orphans[i], want[i])
+		}
+	}
+}
+
+func TestFixCoreOrphan(t *testing.T) {
+	originalCfg, originalInstaller, originalRegistrar := cfg, modelInstaller, modelRegistrar
+	defer func() { cfg, modelInstaller, modelRegistrar = originalCfg, originalInstaller, originalRegistrar }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+
+	var deleted []string
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.EscapedPath())
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer core.Close()
+	client := mlos.NewClient(core.URL)
+
+	if err := saveRegistration(mlos.Registration{ModelID: "hf/deleted@latest", Path: "/cache/hf/deleted/latest", Convert: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	var installed, registered []string
+	modelInstaller = func(ctx context.Context, modelSpec string, opts installOptions) error {
+		installed = append(installed, modelSpec)
+		return nil
+	}
+	modelRegistrar = func(ctx context.Context, modelSpec string, convert, force bool) error {
+		if !convert || !force {
+			t.Errorf("registrar(%s, convert=%t, force=%t), want the recorded --convert and force", modelSpec, convert, force)
+		}
+		registered = append(registered, modelSpec)
+		return nil
+	}
+
+	orphan := coreOrphan{ModelID: "hf/deleted@latest", Path: "/cache/hf/deleted/latest"}
+	if err := fixCoreOrphan(context.Background(), client, orphan, false); err != nil {
+		t.Fatalf("fixCoreOrphan(reinstall) error = %v", err)
+	}
+	if len(installed) != 1 || len(registered) != 1 {
+		t.Errorf("reinstall installed %v and registered %v, want the orphan once each", installed, registered)
+	}
+
+	if err := fixCoreOrphan(context.Background(), client, orphan, true); err != nil {
+		t.Fatalf("fixCoreOrphan(unregister) error = %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/models/hf%2Fdeleted@latest" {
+		t.Errorf("unregister requests = %v", deleted)
+	}
+	regs, err := mlos.LoadRegistrations(registrationsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regs.Models) != 0 {
+		t.Errorf("registrations = %+v, want the orphan forgotten", regs.Models)
+	}
+}
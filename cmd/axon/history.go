@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/history"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// historyPath returns the location of the history log
+func historyPath() string {
+	return filepath.Join(cfg.HomeDir, history.FileName)
+}
+
+// recordHistory appends the outcome of an action on modelSpec to the history log.
+// Failing to write the log never fails the action itself.
+func recordHistory(action, modelSpec, digest string, err error, start time.Time) {
+	event := history.Event{
+		Action:      action,
+		Model:       modelSpec,
+		Result:      history.ResultSuccess,
+		Digest:      digest,
+		DurationSec: time.Since(start).Seconds(),
+	}
+	if err != nil {
+		event.Result = history.ResultFailure
+		event.Error = credentials.Redact(err.Error())
+	}
+
+	if err := os.MkdirAll(cfg.HomeDir, 0755); err == nil {
+		err = history.Append(historyPath(), event)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to record history: %v\n", err)
+	}
+}
+
+// cachedPackageDigest returns the package SHA256 of an installed model spec ("" if unknown)
+func cachedPackageDigest(modelSpec string) string {
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return ""
+	}
+	version := ref.Version
+	if ref.Kind == spec.KindDigest {
+		version = spec.Latest
+	}
+	manifest, err := cache.NewManager(cfg.ModelCacheDir()).GetCachedManifest(ref.Namespace, ref.Name, version)
+	if err != nil {
+		return ""
+	}
+	return manifest.Distribution.Package.SHA256
+}
+
+// parseSince parses a --since value: a duration ("24h", "7d") or a date/time
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (expected a duration like 24h or 7d, or a date like 2006-01-02)", value)
+}
+
+func historyCmd() *cobra.Command {
+	var filter history.Filter
+	var since string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the model install history",
+		Long: `Show the log of installs, uninstalls and registrations on this host.
+
+Every event records when it happened, the user, the model, the result and the
+package digest. The log is kept in ~/.axon/history.log, one JSON record per line.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Since = t
+			}
+
+			events, err := history.Read(historyPath(), filter)
+			if err != nil {
+				return err
+			}
+
+			if jsonOutput {
+				if events == nil {
+					events = []history.Event{}
+				}
+				data, err := json.MarshalIndent(events, "", "  ")
+				if err != nil {
+					return fmt.Errorf("failed to marshal history: %w", err)
+				}
+				fmt.Println(string(data))
+				return nil
+			}
+
+			if len(events) == 0 {
+				fmt.Println("No history recorded")
+				return nil
+			}
+			printHistory(events)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filter.Action, "action", "", "Only show this action (install, uninstall, register, import)")
+	cmd.Flags().StringVar(&filter.Model, "model", "", "Only show models containing this string")
+	cmd.Flags().StringVar(&since, "since", "", "Only show events since a duration ago (24h, 7d) or a date")
+	cmd.Flags().BoolVar(&filter.Failed, "failed", false, "Only show failures")
+	cmd.Flags().IntVarP(&filter.Limit, "limit", "n", 0, "Only show the most recent N events")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output events as JSON")
+
+	return cmd
+}
+
+// printHistory prints history events as a table
+func printHistory(events []history.Event) {
+	width := len("MODEL")
+	for _, e := range events {
+		if len(e.Model) > width {
+			width = len(e.Model)
+		}
+	}
+
+	fmt.Printf("%-19s  %-9s  %-*s  %-9s  %-12s  %s\n", "TIME", "ACTION", width, "MODEL", "RESULT", "DIGEST", "USER")
+	for _, e := range events {
+		icon := "✓"
+		if e.Result != history.ResultSuccess {
+			icon = "✗"
+		}
+		digest := e.Digest
+		if len(digest) > 12 {
+			digest = digest[:12]
+		}
+		user := e.User
+		if e.SudoUser != "" {
+			user = fmt.Sprintf("%s (sudo %s)", e.User, e.SudoUser)
+		}
+		fmt.Printf("%-19s  %-9s  %-*s  %s %-7s  %-12s  %s\n",
+			e.Time.Local().Format("2006-01-02 15:04:05"), e.Action, width, e.Model, icon, e.Result, digest, user)
+		if e.Error != "" {
+			errMsg := e.Error
+			if i := strings.Index(errMsg, "\n"); i >= 0 {
+				errMsg = errMsg[:i]
+			}
+			fmt.Printf("%19s  ↳ %s\n", "", errMsg)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/history"
+)
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "24h", want: now.Add(-24 * time.Hour)},
+		{value: "90m", want: now.Add(-90 * time.Minute)},
+		{value: "7d", want: now.AddDate(0, 0, -7)},
+		{value: "2026-03-01", want: time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)},
+		{value: "2026-03-01T08:30", want: time.Date(2026, 3, 1, 8, 30, 0, 0, time.Local)},
+		{value: "last tuesday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseSince(tt.value, now)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseSince(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !got.Equal(tt.want) {
+			t.Errorf("parseSince(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRecordHistory(t *testing.T) {
+	original := cfg
+	defer func() { cfg = original }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+
+	start := time.Now()
+	recordHistory(history.ActionInstall, "hf/gpt2@latest", "abc123", nil, start)
+	recordHistory(history.ActionInstall, "hf/missing@latest", "", errors.New("model not found"), start)
+
+	events, err := history.Read(historyPath(), history.Filter{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("recorded %d events, want 2", len(events))
+	}
+	if events[0].Result != history.ResultSuccess || events[0].Digest != "abc123" {
+		t.Errorf("events[0] = %+v, want a successful install with digest", events[0])
+	}
+	if events[1].Result != history.ResultFailure || events[1].Error != "model not found" {
+		t.Errorf("events[1] = %+v, want a failed install with the error", events[1])
+	}
+}
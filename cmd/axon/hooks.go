@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/mlOS-foundation/axon/internal/hooks"
+	"github.com/mlOS-foundation/axon/internal/manifest"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// lifecycleHooks returns the hooks in the config; invalid ones are skipped with a warning
+func lifecycleHooks() []hooks.Hook {
+	var valid []hooks.Hook
+	for _, hc := range cfg.Hooks {
+		hook := hc.Hook()
+		if err := hook.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: ignoring hook: %v\n", err)
+			continue
+		}
+		valid = append(valid, hook)
+	}
+	return valid
+}
+
+// fireHooks runs the configured hooks for a lifecycle event of the model at modelPath.
+// cause is the failure the event reports, if any. Hook failures are warnings; they never
+// fail the command.
+func fireHooks(ctx context.Context, event, modelID, modelPath string, m *types.Manifest, cause error) {
+	if len(cfg.Hooks) == 0 {
+		return
+	}
+	payload := hooks.Payload{Event: event, Model: modelID, Path: modelPath}
+	if cause != nil {
+		payload.Error = cause.Error()
+	}
+	if m != nil {
+		if data, err := manifest.ToJSON(m); err == nil {
+			payload.Manifest = data
+		}
+	}
+	for _, err := range hooks.Fire(ctx, lifecycleHooks(), payload) {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", err)
+	}
+}
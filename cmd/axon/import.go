@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/history"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// importAdapter is the provenance adapter of imported models
+const importAdapter = "import"
+
+// importOptions are the 'axon import' flags
+type importOptions struct {
+	description           string
+	license               string
+	framework             string
+	force                 bool
+	overrideLicensePolicy bool
+	overrideSizePolicy    bool
+}
+
+// executionFrameworks is the framework of models by execution format, for imported
+// models that don't say
+var executionFrameworks = map[string]string{
+	"onnx":        "ONNX",
+	"gguf":        "GGUF",
+	"pytorch":     "PyTorch",
+	"safetensors": "PyTorch",
+	"tensorflow":  "TensorFlow",
+}
+
+// importFiles lists the model files in dir: everything except hidden files (.git) and
+// files Axon writes itself
+func importFiles(dir string) ([]model.InventoryEntry, error) {
+	inventory, err := model.BuildInventory(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var files []model.InventoryEntry
+	for _, entry := range inventory.Files {
+		hidden := false
+		for _, segment := range strings.Split(entry.Path, "/") {
+			hidden = hidden || strings.HasPrefix(segment, ".")
+		}
+		if !hidden {
+			files = append(files, entry)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no model files in %s", dir)
+	}
+	return files, nil
+}
+
+// importManifest generates the manifest of a model imported from dir, detecting its
+// format from the files and its I/O schema from config.json
+func importManifest(dir string, ref spec.Spec, files []model.InventoryEntry, opts importOptions) (*types.Manifest, error) {
+	now := time.Now()
+	m := &types.Manifest{
+		APIVersion: "v1",
+		Kind:       "Model",
+		Metadata: types.Metadata{
+			Name:        ref.Name,
+			Namespace:   ref.Namespace,
+			Version:     ref.Version,
+			Description: opts.description,
+			License:     opts.license,
+			Created:     now,
+			Updated:     now,
+		},
+		Distribution: types.Distribution{
+			Registry: types.RegistryInfo{Namespace: ref.Namespace},
+		},
+	}
+	if m.Metadata.Description == "" {
+		m.Metadata.Description = fmt.Sprintf("Imported from %s", dir)
+	}
+	if m.Metadata.License == "" {
+		m.Metadata.License = "Unknown"
+	}
+	for _, file := range files {
+		m.Spec.Format.Files = append(m.Spec.Format.Files, types.ModelFile{Path: file.Path, Size: file.Size, SHA256: file.SHA256})
+	}
+
+	if err := updateManifestAfterInstall(dir, m); err != nil {
+		return nil, err
+	}
+	if m.Spec.Format.Type == "" {
+		m.Spec.Format.Type = m.Spec.Format.ExecutionFormat
+	}
+	m.Spec.Framework = types.Framework{Name: opts.framework, Version: "latest"}
+	if m.Spec.Framework.Name == "" {
+		m.Spec.Framework.Name = executionFrameworks[m.Spec.Format.ExecutionFormat]
+	}
+	if m.Spec.Framework.Name == "" {
+		return nil, fmt.Errorf("can't detect the framework of %s (execution format %q); set it with --framework", dir, m.Spec.Format.ExecutionFormat)
+	}
+	return m, nil
+}
+
+// importModel packages the model files in dir and adds them to the cache as ref, the
+// same way 'axon install' would. It returns the cache path.
+func importModel(dir string, ref spec.Spec, opts importOptions) (cachePath string, err error) {
+	start := time.Now()
+	defer func() {
+		recordHistory(history.ActionImport, ref.String(), cachedPackageDigest(ref.String()), err, start)
+	}()
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a model directory", dir)
+	}
+	if dir, err = filepath.Abs(dir); err != nil {
+		return "", err
+	}
+
+	files, err := importFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	manifest, err := importManifest(dir, ref, files, opts)
+	if err != nil {
+		return "", err
+	}
+	fmt.Printf("🔍 Detected %s model (%s, %d file(s))\n", manifest.Spec.Framework.Name, manifest.Spec.Format.ExecutionFormat, len(files))
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	if err := prepareImport(cacheMgr, ref, manifest, opts); err != nil {
+		return "", err
+	}
+
+	// Package the files like an adapter download, recording the directory as the source
+	builder, err := core.NewPackageBuilder()
+	if err != nil {
+		return "", fmt.Errorf("failed to create package builder: %w", err)
+	}
+	defer func() { _ = builder.Cleanup() }()
+	for _, file := range files {
+		if err := builder.AddFile(filepath.Join(dir, filepath.FromSlash(file.Path)), file.Path); err != nil {
+			return "", fmt.Errorf("failed to add %s to package: %w", file.Path, err)
+		}
+	}
+	builder.SetSource(types.ProvenanceSource{Adapter: importAdapter, Model: ref.ID(), Repository: dir})
+
+	tmpFile := filepath.Join(utils.TempDir(), safeTempFileName(ref.Namespace, ref.Name, ref.Version))
+	defer func() { _ = os.Remove(tmpFile) }()
+	if err := builder.Build(tmpFile); err != nil {
+		return "", fmt.Errorf("failed to build package: %w", err)
+	}
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
+		return "", fmt.Errorf("failed to update manifest checksum: %w", err)
+	}
+	fmt.Printf("📦 Packaged %d file(s) (%s)\n", len(files), formatBytes(manifest.Distribution.Package.Size))
+
+	return cacheImportedPackage(cacheMgr, ref, manifest, tmpFile)
+}
+
+// prepareImport checks that a model can be imported as ref: it must pass the license and
+// size policies and must not be installed already, unless opts.force is set, in which
+// case the installed copy is removed
+func prepareImport(cacheMgr *cache.Manager, ref spec.Spec, manifest *types.Manifest, opts importOptions) error {
+	if err := checkLicensePolicy(cfg.Policy, manifest, opts.overrideLicensePolicy); err != nil {
+		return fmt.Errorf("refusing to import %s: %w (use --%s to import anyway)", ref, err, overrideLicensePolicyFlag)
+	}
+	if err := checkSizePolicy(cfg.Policy, cfg.Tenant, cacheMgr, ref.Namespace, manifest, opts.overrideSizePolicy); err != nil {
+		return fmt.Errorf("refusing to import %s: %w (use --%s to import anyway)", ref, err, overrideSizePolicyFlag)
+	}
+
+	if !cacheMgr.IsModelCached(ref.Namespace, ref.Name, ref.Version) {
+		return nil
+	}
+	if !opts.force {
+		return fmt.Errorf("%s is already installed (use --force to replace it)", ref)
+	}
+	if err := cacheMgr.RemoveModel(ref.Namespace, ref.Name, ref.Version); err != nil {
+		return fmt.Errorf("failed to remove the installed %s: %w", ref, err)
+	}
+	return nil
+}
+
+// cacheImportedPackage adds a package that didn't come from an adapter to the cache as
+// ref, the same way 'axon install' does: it extracts and normalizes the files, updates
+// the manifest to match them, scans the weights, writes the integrity manifest and
+// encrypts the weights if the cache is encrypted. It returns the cache path.
+func cacheImportedPackage(cacheMgr *cache.Manager, ref spec.Spec, manifest *types.Manifest, packagePath string) (string, error) {
+	namespace, name, version := ref.Namespace, ref.Name, ref.Version
+	cachePath := cacheMgr.GetModelPath(namespace, name, version)
+	if err := cacheMgr.CacheModel(namespace, name, version, manifest); err != nil {
+		return "", fmt.Errorf("failed to cache model: %w", err)
+	}
+	cachePackagePath := filepath.Join(cachePath, safeTempFileName(namespace, name, version))
+	if err := copyFile(packagePath, cachePackagePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return "", fmt.Errorf("failed to copy package to cache: %w", err)
+	}
+	if err := model.ExtractPackage(cachePackagePath, cachePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return "", fmt.Errorf("failed to extract package: %w", err)
+	}
+	if err := normalizeModelLayout(cachePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return "", err
+	}
+	if err := checkWeightScanPolicy(cfg.Security, cachePath); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return "", fmt.Errorf("refusing to import %s: %w", ref, err)
+	}
+
+	// Execution files and the I/O schema are recorded relative to the cache copy
+	if err := updateManifestAfterInstall(cachePath, manifest); err != nil {
+		fmt.Printf("⚠️  Failed to update manifest: %v\n", err)
+	}
+	if err := saveManifest(manifest, filepath.Join(cachePath, "manifest.yaml")); err != nil {
+		_ = cacheMgr.RemoveModel(namespace, name, version)
+		return "", fmt.Errorf("failed to save manifest: %w", err)
+	}
+	if inventory, err := model.WriteInventory(cachePath); err != nil {
+		fmt.Printf("⚠️  Failed to write %s: %v\n", model.InventoryFileName, err)
+	} else {
+		recordIntegrity(cacheMgr, cachePath, inventory)
+	}
+
+	if cfg.Cache.Encryption.Enabled {
+		n, err := encryptModelFiles(cachePath)
+		if err != nil {
+			_ = cacheMgr.RemoveModel(namespace, name, version)
+			return "", fmt.Errorf("failed to encrypt %s (removed from cache): %w", ref, err)
+		}
+		fmt.Printf("🔒 Encrypted %d weight file(s) at rest\n", n)
+	}
+	return cachePath, nil
+}
+
+func importCmd() *cobra.Command {
+	var opts importOptions
+	var modelName, version string
+
+	cmd := &cobra.Command{
+		Use:   "import <model-dir> --name namespace/name",
+		Short: "Add a model from a local directory to the cache",
+		Long: `Add a model that is already on disk to the cache as if it had been installed.
+
+The format is detected from the files (GGUF, ONNX, SafeTensors, PyTorch, TensorFlow)
+and the I/O schema is read from config.json if there is one. The files are packaged
+with provenance pointing at the directory, checked against the license and size
+policies, and the directory itself is left untouched. Hidden files such as .git are
+skipped.
+
+The model is not converted; run 'axon convert' afterwards to add ONNX files.
+
+Examples:
+  axon import ./my-model-dir --name myteam/mymodel --version 1.0.0
+  axon import ./llama-gguf --name myteam/llama --license llama2 --description "Fine-tuned Llama 2"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if modelName == "" {
+				return fmt.Errorf("--name is required (e.g. --name myteam/mymodel)")
+			}
+			ref, err := spec.Parse(modelName + "@" + version)
+			if err != nil {
+				return err
+			}
+			if ref.Kind == spec.KindDigest {
+				return fmt.Errorf("--version can't be a digest pin")
+			}
+
+			fmt.Printf("📥 Importing %s as %s...\n", args[0], ref)
+			cachePath, err := importModel(args[0], ref, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("\n✓ Imported %s into %s\n", ref, cachePath)
+			fmt.Printf("   💡 Register it with MLOS Core with 'axon register %s'\n", ref)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&modelName, "name", "", "Name to install the model as (namespace/name)")
+	cmd.Flags().StringVar(&version, "version", spec.Latest, "Version to install the model as")
+	cmd.Flags().StringVar(&opts.description, "description", "", "Model description (default: where it was imported from)")
+	cmd.Flags().StringVar(&opts.license, "license", "", "Model license (default: Unknown)")
+	cmd.Flags().StringVar(&opts.framework, "framework", "", "Model framework, if it can't be detected (e.g. PyTorch)")
+	cmd.Flags().BoolVar(&opts.force, "force", false, "Replace the model if it is already installed")
+	cmd.Flags().BoolVar(&opts.overrideLicensePolicy, overrideLicensePolicyFlag, false, "Import even if the license violates policy.allowed_licenses or policy.denied_licenses")
+	cmd.Flags().BoolVar(&opts.overrideSizePolicy, overrideSizePolicyFlag, false, "Import even if the model exceeds policy.max_model_size_gb or its namespace quota")
+	return cmd
+}
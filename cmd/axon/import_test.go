@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+func TestImportModel(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"model.onnx":     "onnx weights",
+		"config.json":    `{"model_type": "bert"}`,
+		"tokenizer.json": "{}",
+		".git/HEAD":      "ref: refs/heads/main",
+	} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ref, err := spec.Parse("myteam/mymodel@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cachePath, err := importModel(dir, ref, importOptions{license: "mit"})
+	if err != nil {
+		t.Fatalf("importModel() error = %v", err)
+	}
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	if !cacheMgr.IsModelCached("myteam", "mymodel", "1.0.0") || cachePath != cacheMgr.GetModelPath("myteam", "mymodel", "1.0.0") {
+		t.Fatalf("model not cached at %s", cachePath)
+	}
+	manifest, err := loadManifest(filepath.Join(cachePath, "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Spec.Format.ExecutionFormat != "onnx" || manifest.Spec.Framework.Name != "ONNX" || manifest.Metadata.License != "mit" {
+		t.Errorf("manifest format = %s, framework = %s, license = %s; want onnx, ONNX, mit",
+			manifest.Spec.Format.ExecutionFormat, manifest.Spec.Framework.Name, manifest.Metadata.License)
+	}
+	if len(manifest.Spec.IO.Inputs) == 0 {
+		t.Fatal("I/O schema not read from config.json")
+	}
+	if tokenizer := manifest.Spec.IO.Inputs[0].Preprocessing.Tokenizer; tokenizer != "tokenizer/tokenizer.json" {
+		t.Errorf("tokenizer hint = %s, want the normalized tokenizer/tokenizer.json", tokenizer)
+	}
+	if len(manifest.Spec.Format.Files) != 3 || manifest.Distribution.Package.SHA256 == "" {
+		t.Errorf("manifest files = %+v, package sha256 = %q; want model.onnx, config.json and tokenizer.json, checksummed",
+			manifest.Spec.Format.Files, manifest.Distribution.Package.SHA256)
+	}
+	if _, err := os.Stat(filepath.Join(cachePath, ".git")); !os.IsNotExist(err) {
+		t.Error(".git was imported")
+	}
+	if _, err := model.ReadInventory(cachePath); err != nil {
+		t.Errorf("ReadInventory() error = %v", err)
+	}
+
+	if _, err := importModel(dir, ref, importOptions{}); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("re-import error = %v, want already installed", err)
+	}
+	if _, err := importModel(dir, ref, importOptions{force: true}); err != nil {
+		t.Errorf("re-import with force error = %v", err)
+	}
+	if _, err := importModel(filepath.Join(dir, "model.onnx"), ref, importOptions{force: true}); err == nil {
+		t.Error("importing a file succeeded, want an error")
+	}
+}
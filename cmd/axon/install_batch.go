@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/converter"
+)
+
+// installOptions holds the install flags shared by every model in a batch
+type installOptions struct {
+	format      string
+	onnxPolicy  string
+	conversion  converter.Options
+	json        bool
+	concurrency int
+	failFast    bool
+	adapter     string // Adapter to use instead of routing by namespace
+	register    bool   // Register with MLOS Core after a successful install
+	pre         bool   // Let version ranges resolve to prereleases
+
+	overrideLicensePolicy bool
+	overrideSizePolicy    bool
+
+	// Also told about download progress, e.g. by 'axon serve' jobs
+	progress func(downloaded, total int64)
+}
+
+// installOptionsFromFlags reads and validates the install command flags
+func installOptionsFromFlags(cmd *cobra.Command) (installOptions, error) {
+	var opts installOptions
+	opts.format, _ = cmd.Flags().GetString("format")
+	opts.onnxPolicy, _ = cmd.Flags().GetString("onnx")
+	if opts.onnxPolicy == "" {
+		opts.onnxPolicy = cfg.Conversion.ONNXPolicy
+	}
+	if opts.onnxPolicy == "" {
+		opts.onnxPolicy = converter.ONNXPolicyPrefer
+	}
+	if err := converter.ValidateONNXPolicy(opts.onnxPolicy); err != nil {
+		return opts, err
+	}
+
+	conversion, err := conversionOptionsFromFlags(cmd)
+	if err != nil {
+		return opts, err
+	}
+	opts.conversion = conversion
+
+	opts.json, _ = cmd.Flags().GetBool("json")
+	opts.failFast, _ = cmd.Flags().GetBool("fail-fast")
+	opts.overrideLicensePolicy, _ = cmd.Flags().GetBool(overrideLicensePolicyFlag)
+	opts.overrideSizePolicy, _ = cmd.Flags().GetBool(overrideSizePolicyFlag)
+	opts.adapter, _ = cmd.Flags().GetString("adapter")
+	opts.register = cfg.Core.AutoRegister
+	if cmd.Flags().Lookup("register") != nil { // Not a flag of 'axon update'
+		register, _ := cmd.Flags().GetBool("register")
+		opts.register = opts.register || register
+	}
+	if cmd.Flags().Lookup("pre") != nil { // Not a flag of 'axon update'
+		opts.pre, _ = cmd.Flags().GetBool("pre")
+	}
+	opts.concurrency = 1
+	if cmd.Flags().Lookup("concurrency") != nil { // Not a flag of 'axon update'
+		opts.concurrency, _ = cmd.Flags().GetInt("concurrency")
+	}
+	if opts.concurrency < 1 {
+		return opts, fmt.Errorf("--concurrency must be at least 1")
+	}
+	return opts, nil
+}
+
+// installSpecs collects the model specs given as arguments and in --file
+func installSpecs(cmd *cobra.Command, args []string) ([]string, error) {
+	specs := append([]string{}, args...)
+	if file, _ := cmd.Flags().GetString("file"); file != "" {
+		fromFile, err := readSpecFile(file)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, fromFile...)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no models to install (give namespace/name[@version] or --file)")
+	}
+	return specs, nil
+}
+
+// readSpecFile reads model specs, one per line; blank lines and '#' comments are ignored
+func readSpecFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spec file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var specs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			specs = append(specs, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+	return specs, nil
+}
+
+// installResult is the outcome of one model in a batch install
+type installResult struct {
+	Spec        string  `json:"spec"`
+	Status      string  `json:"status"` // "installed", "failed" or "skipped"
+	Error       string  `json:"error,omitempty"`
+	DurationSec float64 `json:"duration_seconds"`
+}
+
+// Batch install result statuses
+const (
+	installStatusInstalled = "installed"
+	installStatusFailed    = "failed"
+	installStatusSkipped   = "skipped"
+)
+
+// batchInstaller installs a single spec; replaced in tests
+var batchInstaller = installModel
+
+// runBatchInstall installs specs with at most opts.concurrency installs in flight,
+// continuing past failures unless opts.failFast is set, and prints a summary
+func runBatchInstall(ctx context.Context, specs []string, opts installOptions) error {
+	// Keep stdout clean for the JSON summary; install progress goes to stderr
+	stdout := os.Stdout
+	if opts.json {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = stdout }()
+	}
+
+	results := installBatch(ctx, specs, opts)
+
+	os.Stdout = stdout
+	var failed int
+	for _, r := range results {
+		if r.Status != installStatusInstalled {
+			failed++
+		}
+	}
+
+	if opts.json {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal summary: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		printInstallSummary(results)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d model(s) failed to install", failed, len(results))
+	}
+	return nil
+}
+
+// installBatch runs the installs and returns one result per spec, in input order
+func installBatch(ctx context.Context, specs []string, opts installOptions) []installResult {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]installResult, len(specs))
+	sem := make(chan struct{}, opts.concurrency)
+	var wg sync.WaitGroup
+
+	for i, spec := range specs {
+		sem <- struct{}{}
+		if ctx.Err() != nil {
+			<-sem
+			results[i] = installResult{Spec: spec, Status: installStatusSkipped, Error: "skipped after an earlier failure"}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, spec string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			err := batchInstaller(ctx, spec, opts)
+			results[i] = installResult{
+				Spec:        spec,
+				Status:      installStatusInstalled,
+				DurationSec: time.Since(start).Round(time.Millisecond).Seconds(),
+			}
+			if err != nil {
+				results[i].Status = installStatusFailed
+				results[i].Error = err.Error()
+				if opts.failFast {
+					cancel()
+				}
+			}
+		}(i, spec)
+	}
+	wg.Wait()
+	return results
+}
+
+// printInstallSummary prints a table of batch install results
+func printInstallSummary(results []installResult) {
+	width := len("MODEL")
+	for _, r := range results {
+		if len(r.Spec) > width {
+			width = len(r.Spec)
+		}
+	}
+
+	fmt.Printf("\n📋 Install summary:\n")
+	fmt.Printf("  %-*s  %-9s  %8s  %s\n", width, "MODEL", "STATUS", "TIME", "ERROR")
+	for _, r := range results {
+		icon := "✓"
+		if r.Status != installStatusInstalled {
+			icon = "✗"
+		}
+		errMsg := r.Error
+		if i := strings.Index(errMsg, "\n"); i >= 0 {
+			errMsg = errMsg[:i]
+		}
+		fmt.Printf("  %-*s  %s %-7s  %7.1fs  %s\n", width, r.Spec, icon, r.Status, r.DurationSec, errMsg)
+	}
+}
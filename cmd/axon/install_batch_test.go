@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestReadSpecFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "models.txt")
+	content := "# e2e models\nhf/bert-base-uncased\n\n  hf/gpt2@latest  # decoder\npytorch/vision/resnet50\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	specs, err := readSpecFile(path)
+	if err != nil {
+		t.Fatalf("readSpecFile() error = %v", err)
+	}
+	want := []string{"hf/bert-base-uncased", "hf/gpt2@latest", "pytorch/vision/resnet50"}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("readSpecFile() = %v, want %v", specs, want)
+	}
+}
+
+func TestInstallBatch(t *testing.T) {
+	original := batchInstaller
+	defer func() { batchInstaller = original }()
+
+	var inFlight, maxInFlight int32
+	batchInstaller = func(ctx context.Context, spec string, opts installOptions) error {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		if strings.Contains(spec, "broken") {
+			return fmt.Errorf("model not found")
+		}
+		return nil
+	}
+
+	specs := []string{"hf/a", "hf/broken", "hf/c", "hf/d"}
+	results := installBatch(context.Background(), specs, installOptions{concurrency: 2})
+
+	var statuses []string
+	for i, r := range results {
+		if r.Spec != specs[i] {
+			t.Errorf("results[%d].Spec = %q, want input order", i, r.Spec)
+		}
+		statuses = append(statuses, r.Status)
+	}
+	want := []string{installStatusInstalled, installStatusFailed, installStatusInstalled, installStatusInstalled}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v (batch continues past failures)", statuses, want)
+	}
+	if results[1].Error != "model not found" {
+		t.Errorf("results[1].Error = %q", results[1].Error)
+	}
+	if maxInFlight > 2 {
+		t.Errorf("max concurrent installs = %d, want <= 2", maxInFlight)
+	}
+}
+
+func TestInstallBatch_FailFast(t *testing.T) {
+	original := batchInstaller
+	defer func() { batchInstaller = original }()
+
+	batchInstaller = func(ctx context.Context, spec string, opts installOptions) error {
+		if spec == "hf/broken" {
+			return fmt.Errorf("download failed")
+		}
+		return nil
+	}
+
+	results := installBatch(context.Background(), []string{"hf/broken", "hf/b", "hf/c"}, installOptions{concurrency: 1, failFast: true})
+	if results[0].Status != installStatusFailed {
+		t.Errorf("results[0].Status = %q, want failed", results[0].Status)
+	}
+	for _, r := range results[1:] {
+		if r.Status != installStatusSkipped {
+			t.Errorf("%s status = %q, want skipped after fail-fast", r.Spec, r.Status)
+		}
+	}
+}
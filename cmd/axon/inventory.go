@@ -0,0 +1,249 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/model"
+)
+
+// inventoryTokenEnv holds the bearer token inventory scrapers must present
+const inventoryTokenEnv = "AXON_INVENTORY_TOKEN"
+
+// Verification status of a cached model in the inventory
+const (
+	inventoryVerified   = "verified"
+	inventoryFailed     = "failed"
+	inventoryUnverified = "unverified" // No files.json, or encrypted without a key
+)
+
+// inventoryModel is a cached model as reported by the inventory endpoint
+type inventoryModel struct {
+	Namespace    string   `json:"namespace"`
+	Name         string   `json:"name"`
+	Version      string   `json:"version"`
+	Digest       string   `json:"digest,omitempty"` // Content digest of the extracted files
+	SizeBytes    int64    `json:"size_bytes"`
+	Encrypted    bool     `json:"encrypted,omitempty"`
+	Verification string   `json:"verification"`
+	Problems     []string `json:"problems,omitempty"`
+}
+
+// cacheInventory is the document served to scrapers
+type cacheInventory struct {
+	Tenant      string           `json:"tenant,omitempty"`
+	GeneratedAt time.Time        `json:"generated_at"`
+	Models      []inventoryModel `json:"models"`
+}
+
+// buildCacheInventory lists the cached models with their digests and verifies each
+// against its files.json. key decrypts encrypted files; without it they are unverified.
+func buildCacheInventory(cacheMgr *cache.Manager, key []byte) (*cacheInventory, error) {
+	cached, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached models: %w", err)
+	}
+
+	inventory := &cacheInventory{GeneratedAt: time.Now().UTC(), Models: []inventoryModel{}}
+	for _, m := range cached {
+		entry := inventoryModel{
+			Namespace: m.Namespace,
+			Name:      m.Name,
+			Version:   m.Version,
+			Encrypted: cachecrypt.IsEncryptedDir(m.Path),
+		}
+		entry.SizeBytes, _ = cacheMgr.GetModelSize(m.Namespace, m.Name, m.Version)
+
+		files, err := model.ReadInventory(m.Path)
+		switch {
+		case err != nil:
+			entry.Verification = inventoryUnverified
+			entry.Problems = []string{fmt.Sprintf("no %s (installed before integrity tracking, or not extracted)", model.InventoryFileName)}
+		case entry.Encrypted && key == nil:
+			entry.Digest = files.Digest()
+			entry.Verification = inventoryUnverified
+			entry.Problems = []string{"encrypted at rest and no cache encryption key is available"}
+		default:
+			entry.Digest = files.Digest()
+			problems, err := model.VerifyEncryptedInventory(m.Path, key)
+			if err != nil {
+				problems = []string{err.Error()}
+			}
+			entry.Verification = inventoryVerified
+			if len(problems) > 0 {
+				entry.Verification = inventoryFailed
+				entry.Problems = problems
+			}
+		}
+		inventory.Models = append(inventory.Models, entry)
+	}
+	return inventory, nil
+}
+
+// inventoryServer serves the last cache inventory, read-only, to bearer-token holders
+type inventoryServer struct {
+	token string
+
+	mu        sync.RWMutex
+	inventory *cacheInventory
+}
+
+// set replaces the served inventory
+func (s *inventoryServer) set(inventory *cacheInventory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inventory = inventory
+}
+
+func (s *inventoryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "read-only endpoint", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == "/healthz" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.URL.Path != "/v1/inventory" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="axon-inventory"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.RLock()
+	inventory := s.inventory
+	s.mu.RUnlock()
+	if inventory == nil {
+		http.Error(w, "inventory not ready", http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(inventory)
+}
+
+// isLoopback reports whether a listen address only accepts local connections
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func cacheServeCmd() *cobra.Command {
+	var listen string
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a read-only inventory of the cache over HTTP",
+		Long: `Serve the cached models, their versions, content digests and verification
+status as JSON at GET /v1/inventory, for fleet inventory scrapers. The endpoint is
+read-only: nothing can be installed, removed or changed through it.
+
+Requests must carry "Authorization: Bearer <token>" with the token from
+$` + inventoryTokenEnv + `. Models are verified against their files.json when the server
+starts and every --interval after that; scrapes return the last result. GET /healthz
+answers without a token.
+
+Example:
+  ` + inventoryTokenEnv + `=$(openssl rand -hex 16) axon cache serve --listen 127.0.0.1:9464
+  curl -H "Authorization: Bearer $` + inventoryTokenEnv + `" http://127.0.0.1:9464/v1/inventory`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := os.Getenv(inventoryTokenEnv)
+			if token == "" {
+				return fmt.Errorf("set %s to the token inventory scrapers must present", inventoryTokenEnv)
+			}
+			credentials.RegisterSecret(token)
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if !isLoopback(listen) {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: %s accepts connections from other hosts\n", listen)
+			}
+
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			key, err := cacheEncryptionKey(false)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: encrypted models will be reported unverified: %v\n", err)
+			}
+			refresh := func() *cacheInventory {
+				inventory, err := buildCacheInventory(cacheMgr, key)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", err)
+					return nil
+				}
+				inventory.Tenant = cfg.Tenant
+				return inventory
+			}
+
+			server := &inventoryServer{token: token}
+			inventory := refresh()
+			if inventory == nil {
+				return fmt.Errorf("failed to build the cache inventory")
+			}
+			server.set(inventory)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if inventory := refresh(); inventory != nil {
+							server.set(inventory)
+						}
+					}
+				}
+			}()
+
+			httpServer := &http.Server{Addr: listen, Handler: server, ReadHeaderTimeout: 10 * time.Second}
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = httpServer.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Printf("📋 Serving the inventory of %d cached model(s) at http://%s/v1/inventory\n", len(inventory.Models), listen)
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("inventory server failed: %w", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&listen, "listen", "127.0.0.1:9464", "Address to listen on")
+	cmd.Flags().DurationVar(&interval, "interval", 15*time.Minute, "How often to re-verify the cache")
+	return cmd
+}
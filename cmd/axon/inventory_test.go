@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestBuildCacheInventory(t *testing.T) {
+	cacheMgr := cache.NewManager(t.TempDir())
+	for _, name := range []string{"intact", "tampered", "legacy"} {
+		manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: name, Version: "latest"}}
+		if err := cacheMgr.CacheModel("hf", name, "latest", manifest); err != nil {
+			t.Fatal(err)
+		}
+		dir := cacheMgr.GetModelPath("hf", name, "latest")
+		if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("weights"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name == "legacy" {
+			continue
+		}
+		if _, err := model.WriteInventory(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tampered := filepath.Join(cacheMgr.GetModelPath("hf", "tampered", "latest"), "model.onnx")
+	if err := os.WriteFile(tampered, []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inventory, err := buildCacheInventory(cacheMgr, nil)
+	if err != nil {
+		t.Fatalf("buildCacheInventory() error = %v", err)
+	}
+	got := make(map[string]inventoryModel)
+	for _, m := range inventory.Models {
+		got[m.Name] = m
+	}
+
+	tests := []struct {
+		name         string
+		verification string
+		digest       bool
+	}{
+		{"intact", inventoryVerified, true},
+		{"tampered", inventoryFailed, true},
+		{"legacy", inventoryUnverified, false},
+	}
+	for _, tt := range tests {
+		m, ok := got[tt.name]
+		if !ok {
+			t.Errorf("%s missing from inventory %+v", tt.name, inventory.Models)
+			continue
+		}
+		if m.Verification != tt.verification {
+			t.Errorf("%s verification = %q, want %q (problems %v)", tt.name, m.Verification, tt.verification, m.Problems)
+		}
+		if (m.Digest != "") != tt.digest {
+			t.Errorf("%s digest = %q, want digest %v", tt.name, m.Digest, tt.digest)
+		}
+		if m.SizeBytes == 0 {
+			t.Errorf("%s size_bytes = 0", tt.name)
+		}
+	}
+}
+
+func TestInventoryServer(t *testing.T) {
+	server := &inventoryServer{token: "secret"}
+	srv := httptest.NewServer(server)
+	defer srv.Close()
+
+	request := func(method, path, token string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Not ready until the first inventory is built
+	resp := request(http.MethodGet, "/v1/inventory", "secret")
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("before first inventory: status = %d, want 503", resp.StatusCode)
+	}
+
+	server.set(&cacheInventory{Models: []inventoryModel{{Namespace: "hf", Name: "bert", Version: "latest", Verification: inventoryVerified}}})
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"authorized", http.MethodGet, "/v1/inventory", "secret", http.StatusOK},
+		{"head", http.MethodHead, "/v1/inventory", "secret", http.StatusOK},
+		{"no token", http.MethodGet, "/v1/inventory", "", http.StatusUnauthorized},
+		{"wrong token", http.MethodGet, "/v1/inventory", "guess", http.StatusUnauthorized},
+		{"mutation", http.MethodPost, "/v1/inventory", "secret", http.StatusMethodNotAllowed},
+		{"delete", http.MethodDelete, "/v1/inventory", "secret", http.StatusMethodNotAllowed},
+		{"health without token", http.MethodGet, "/healthz", "", http.StatusOK},
+		{"unknown path", http.MethodGet, "/v1/models", "secret", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := request(tt.method, tt.path, tt.token)
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.name != "authorized" {
+				return
+			}
+			var inventory cacheInventory
+			if err := json.NewDecoder(resp.Body).Decode(&inventory); err != nil {
+				t.Fatalf("failed to decode inventory: %v", err)
+			}
+			if len(inventory.Models) != 1 || inventory.Models[0].Name != "bert" {
+				t.Errorf("inventory = %+v, want hf/bert", inventory)
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"127.0.0.1:9464", true},
+		{"localhost:9464", true},
+		{"[::1]:9464", true},
+		{"0.0.0.0:9464", false},
+		{":9464", false},
+		{"10.0.0.5:9464", false},
+	}
+	for _, tt := range tests {
+		if got := isLoopback(tt.addr); got != tt.want {
+			t.Errorf("isLoopback(%q) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/converter"
+)
+
+// modelListing is an installed model with the details 'axon list' shows
+type modelListing struct {
+	cache.CachedModel
+	Size            int64
+	ExecutionFormat string    `json:",omitempty"`
+	Converted       []string  `json:",omitempty"` // Runtime files on disk: "onnx", "gguf"
+	LastUsed        time.Time `json:",omitzero"`  // When last registered or converted, or else installed
+}
+
+// listSortKeys are the values of 'axon list --sort'
+var listSortKeys = []string{"name", "size", "last-used", "format"}
+
+// listFilterKeys are the keys of 'axon list --filter key=value'
+var listFilterKeys = []string{"namespace", "name", "format", "converted", "registered"}
+
+// ID returns the model's namespace/name@version
+func (l modelListing) ID() string {
+	return fmt.Sprintf("%s/%s@%s", l.Namespace, filepath.ToSlash(l.Name), l.Version)
+}
+
+// describeModels gathers the details of installed models. Details that can't be read are
+// left empty rather than hiding the model.
+func describeModels(cacheMgr *cache.Manager, models []cache.CachedModel) []modelListing {
+	listings := make([]modelListing, 0, len(models))
+	for _, model := range models {
+		listing := modelListing{CachedModel: model}
+		listing.Size, _ = cacheMgr.GetModelSize(model.Namespace, model.Name, model.Version)
+		if m, err := loadManifest(filepath.Join(model.Path, "manifest.yaml")); err == nil {
+			listing.ExecutionFormat = m.Spec.Format.ExecutionFormat
+		}
+		if files, _ := converter.FindONNXFiles(model.Path); len(files) > 0 {
+			listing.Converted = append(listing.Converted, "onnx")
+		}
+		if files, _ := findGGUFFiles(model.Path); len(files) > 0 {
+			listing.Converted = append(listing.Converted, "gguf")
+		}
+		listing.LastUsed, _ = cache.ReadLastUsed(model.Path)
+		listings = append(listings, listing)
+	}
+	return listings
+}
+
+// listFilter matches installed models against one --filter key=value
+type listFilter func(l modelListing) bool
+
+// parseListFilter parses a --filter value:
+//
+//	namespace=hf           models in a namespace
+//	name=bert              models whose name contains a string
+//	format=onnx            models with an execution format
+//	converted=gguf         models with ONNX or GGUF files on disk (none: neither)
+//	registered=true        models registered with MLOS Core (false: not registered)
+func parseListFilter(value string) (listFilter, error) {
+	key, want, ok := strings.Cut(value, "=")
+	key, want = strings.TrimSpace(key), strings.TrimSpace(want)
+	if !ok || want == "" {
+		return nil, fmt.Errorf("invalid filter %q: expected key=value with key one of %s", value, strings.Join(listFilterKeys, ", "))
+	}
+
+	switch key {
+	case "namespace":
+		return func(l modelListing) bool { return l.Namespace == want }, nil
+	case "name":
+		return func(l modelListing) bool { return strings.Contains(filepath.ToSlash(l.Name), want) }, nil
+	case "format":
+		return func(l modelListing) bool { return strings.EqualFold(l.ExecutionFormat, want) }, nil
+	case "converted":
+		if want == "none" {
+			return func(l modelListing) bool { return len(l.Converted) == 0 }, nil
+		}
+		return func(l modelListing) bool {
+			for _, format := range l.Converted {
+				if strings.EqualFold(format, want) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case "registered":
+		registered, err := strconv.ParseBool(want)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter %q: registered must be true or false", value)
+		}
+		return func(l modelListing) bool { return (l.Registration != nil) == registered }, nil
+	}
+	return nil, fmt.Errorf("invalid filter %q: unknown key %q (expected one of %s)", value, key, strings.Join(listFilterKeys, ", "))
+}
+
+// filterListings keeps the models matching every filter
+func filterListings(listings []modelListing, filters []listFilter) []modelListing {
+	var kept []modelListing
+	for _, listing := range listings {
+		matches := true
+		for _, filter := range filters {
+			if !filter(listing) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			kept = append(kept, listing)
+		}
+	}
+	return kept
+}
+
+// sortListings orders models by a --sort key: names alphabetically, the largest and the
+// most recently used first. Ties keep name order.
+func sortListings(listings []modelListing, key string) error {
+	var less func(a, b modelListing) bool
+	switch key {
+	case "name":
+	case "size":
+		less = func(a, b modelListing) bool { return a.Size > b.Size }
+	case "last-used":
+		less = func(a, b modelListing) bool { return a.LastUsed.After(b.LastUsed) }
+	case "format":
+		less = func(a, b modelListing) bool { return a.ExecutionFormat < b.ExecutionFormat }
+	default:
+		return fmt.Errorf("invalid sort key %q (expected one of %s)", key, strings.Join(listSortKeys, ", "))
+	}
+
+	sort.SliceStable(listings, func(i, j int) bool { return listings[i].ID() < listings[j].ID() })
+	if less != nil {
+		sort.SliceStable(listings, func(i, j int) bool { return less(listings[i], listings[j]) })
+	}
+	return nil
+}
+
+// printListingTable prints installed models as a table
+func printListingTable(listings []modelListing, now time.Time) {
+	width := len("MODEL")
+	for _, listing := range listings {
+		width = max(width, len(listing.ID()))
+	}
+
+	fmt.Printf("  %-*s  %10s  %-12s  %-10s  %-10s  %s\n", width, "MODEL", "SIZE", "FORMAT", "CONVERTED", "REGISTERED", "LAST USED")
+	for _, listing := range listings {
+		format := listing.ExecutionFormat
+		if format == "" {
+			format = "-"
+		}
+		converted := "-"
+		if len(listing.Converted) > 0 {
+			converted = strings.Join(listing.Converted, ",")
+		}
+		registered := "-"
+		if listing.Registration != nil {
+			registered = "yes"
+		}
+		fmt.Printf("  %-*s  %10s  %-12s  %-10s  %-10s  %s\n", width, listing.ID(), formatBytes(listing.Size), format, converted, registered, formatLastUsed(listing.LastUsed, now))
+	}
+}
+
+// formatLastUsed renders how long ago a model was used
+func formatLastUsed(used, now time.Time) string {
+	if used.IsZero() {
+		return "unknown"
+	}
+	age := now.Sub(used)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	case age < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+	return used.Local().Format("2006-01-02")
+}
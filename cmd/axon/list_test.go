@@ -0,0 +1,114 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+)
+
+func testListings() []modelListing {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	return []modelListing{
+		{CachedModel: cache.CachedModel{Namespace: "hf", Name: "gpt2", Version: "latest"}, Size: 500 << 20, ExecutionFormat: "onnx", Converted: []string{"onnx"}, LastUsed: now.Add(-48 * time.Hour)},
+		{CachedModel: cache.CachedModel{Namespace: "hf", Name: "bert", Version: "latest", Registration: &cache.CoreRegistration{ModelID: "hf/bert@latest"}}, Size: 400 << 20, ExecutionFormat: "onnx", Converted: []string{"onnx"}, LastUsed: now},
+		{CachedModel: cache.CachedModel{Namespace: "ollama", Name: "llama3", Version: "8b"}, Size: 4 << 30, ExecutionFormat: "gguf", Converted: []string{"gguf"}},
+		{CachedModel: cache.CachedModel{Namespace: "pytorch", Name: "resnet50", Version: "latest"}, Size: 100 << 20, ExecutionFormat: "pytorch"},
+	}
+}
+
+func listingIDs(listings []modelListing) []string {
+	var ids []string
+	for _, listing := range listings {
+		ids = append(ids, listing.ID())
+	}
+	return ids
+}
+
+func TestSortListings(t *testing.T) {
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"name", []string{"hf/bert@latest", "hf/gpt2@latest", "ollama/llama3@8b", "pytorch/resnet50@latest"}},
+		{"size", []string{"ollama/llama3@8b", "hf/gpt2@latest", "hf/bert@latest", "pytorch/resnet50@latest"}},
+		{"last-used", []string{"hf/bert@latest", "hf/gpt2@latest", "ollama/llama3@8b", "pytorch/resnet50@latest"}},
+		{"format", []string{"ollama/llama3@8b", "hf/bert@latest", "hf/gpt2@latest", "pytorch/resnet50@latest"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			listings := testListings()
+			if err := sortListings(listings, tt.key); err != nil {
+				t.Fatal(err)
+			}
+			if got := listingIDs(listings); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortListings(%s) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+	if err := sortListings(testListings(), "date"); err == nil {
+		t.Error("sortListings() accepted an unknown key")
+	}
+}
+
+func TestFilterListings(t *testing.T) {
+	tests := []struct {
+		filters []string
+		want    []string
+		wantErr string
+	}{
+		{filters: []string{"namespace=hf"}, want: []string{"hf/gpt2@latest", "hf/bert@latest"}},
+		{filters: []string{"name=llama"}, want: []string{"ollama/llama3@8b"}},
+		{filters: []string{"format=ONNX", "registered=false"}, want: []string{"hf/gpt2@latest"}},
+		{filters: []string{"converted=gguf"}, want: []string{"ollama/llama3@8b"}},
+		{filters: []string{"converted=none"}, want: []string{"pytorch/resnet50@latest"}},
+		{filters: []string{"registered=true"}, want: []string{"hf/bert@latest"}},
+		{filters: []string{"namespace=tf"}},
+		{filters: []string{"registered=maybe"}, wantErr: "true or false"},
+		{filters: []string{"size=1GB"}, wantErr: "unknown key"},
+		{filters: []string{"hf"}, wantErr: "expected key=value"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.filters, ","), func(t *testing.T) {
+			var filters []listFilter
+			for _, value := range tt.filters {
+				filter, err := parseListFilter(value)
+				if tt.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+						t.Errorf("parseListFilter(%q) error = %v, want %q", value, err, tt.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				filters = append(filters, filter)
+			}
+			if got := listingIDs(filterListings(testListings(), filters)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterListings() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLastUsed(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		used time.Time
+		want string
+	}{
+		{time.Time{}, "unknown"},
+		{now.Add(-10 * time.Second), "just now"},
+		{now.Add(-5 * time.Minute), "5m ago"},
+		{now.Add(-3 * time.Hour), "3h ago"},
+		{now.Add(-50 * time.Hour), "2d ago"},
+		{now.Add(-90 * 24 * time.Hour), now.Add(-90 * 24 * time.Hour).Local().Format("2006-01-02")},
+	}
+	for _, tt := range tests {
+		if got := formatLastUsed(tt.used, now); got != tt.want {
+			t.Errorf("formatLastUsed(%v) = %q, want %q", tt.used, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/mlOS-foundation/axon/internal/archive"
 	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 )
 
 var (
@@ -39,34 +44,114 @@ func main() {
 		Use:   "axon",
 		Short: "The Neural Pathway for ML Models",
 		Long:  "Axon is the transmission layer for ML models in MLOS. Signal. Propagate. Myelinate.",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Initialize global state - the axon hillock (initiation point)
 			var err error
 			cfg, err = config.Load()
 			if err != nil {
 				cfg = config.DefaultConfig()
 			}
+
+			// --tenant and $AXON_TENANT override the configured tenant
+			if tenant := os.Getenv(tenantEnv); tenant != "" {
+				cfg.Tenant = tenant
+			}
+			if cmd.Flags().Changed("tenant") {
+				cfg.Tenant, _ = cmd.Flags().GetString("tenant")
+			}
+			if cfg.Tenant != "" {
+				if err := config.ValidateTenant(cfg.Tenant); err != nil {
+					return err
+				}
+			}
+
+			// Stage temporary files on the cache volume rather than a (often small) tmpfs /tmp
+			if err := utils.SetTempDir(cfg.TempPath()); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Warning: %v, using %s\n", err, os.TempDir())
+			}
+
+			// Roll back installs a crash left half done before anything looks at the cache
+			if cmd.Name() != "repair" {
+				autoRepair()
+			}
+
+			// --limit-rate overrides download.rate_limit
+			rate := cfg.Download.RateLimit
+			if cmd.Flags().Changed("limit-rate") {
+				rate, _ = cmd.Flags().GetString("limit-rate")
+			}
+			bytesPerSecond, err := core.ParseRate(rate)
+			if err != nil {
+				return fmt.Errorf("invalid download rate limit: %w", err)
+			}
+			core.SetDownloadRateLimit(bytesPerSecond)
+			core.SetChunkedDownload(core.ChunkedDownload{
+				Chunks:    cfg.Download.Chunks,
+				Threshold: int64(cfg.Download.ChunkThresholdMB) << 20,
+				Retries:   cfg.Download.MaxRetries,
+			})
+			core.SetHostRateLimits(core.HostRateLimits{
+				RequestsPerSecond: hostRateLimits(),
+				Retries:           cfg.Download.MaxRetries,
+			})
+			archive.SetLimits(archive.Limits{
+				MaxSize:    int64(cfg.Cache.MaxExtractGB * (1 << 30)),
+				MaxEntries: cfg.Cache.MaxExtractEntries,
+			})
+			return nil
 		},
 	}
+	// Errors are printed below, after redacting tokens
+	rootCmd.SilenceErrors = true
+	rootCmd.PersistentFlags().String("limit-rate", "", "Limit the combined download bandwidth, e.g. 10MB/s")
+	rootCmd.PersistentFlags().String("tenant", "", "Tenant whose cache scope and MLOS Core registrations to use (default: $AXON_TENANT or tenant from config)")
 
 	// Add commands
 	rootCmd.AddCommand(initCmd())
 	rootCmd.AddCommand(searchCmd())
 	rootCmd.AddCommand(infoCmd())
 	rootCmd.AddCommand(installCmd())
+	rootCmd.AddCommand(importCmd())
+	rootCmd.AddCommand(exportCmd())
+	rootCmd.AddCommand(importBundleCmd())
+	rootCmd.AddCommand(extractCmd())
+	rootCmd.AddCommand(convertCmd())
 	rootCmd.AddCommand(uninstallCmd())
 	rootCmd.AddCommand(listCmd())
 	rootCmd.AddCommand(updateCmd())
 	rootCmd.AddCommand(verifyCmd())
+	rootCmd.AddCommand(doctorCmd())
+	rootCmd.AddCommand(repairCmd())
+	rootCmd.AddCommand(manifestCmd())
+	rootCmd.AddCommand(historyCmd())
 	rootCmd.AddCommand(publishCmd())
+	rootCmd.AddCommand(keysCmd())
 	rootCmd.AddCommand(registerCmd())
+	rootCmd.AddCommand(unregisterCmd())
+	rootCmd.AddCommand(restoreRegistrationsCmd())
+	rootCmd.AddCommand(coreCmd())
+	rootCmd.AddCommand(serveCmd())
+	rootCmd.AddCommand(queueCmd())
+	rootCmd.AddCommand(gcCmd())
+	rootCmd.AddCommand(suggestCmd())
 	rootCmd.AddCommand(cacheCmd())
+	rootCmd.AddCommand(loginCmd())
+	rootCmd.AddCommand(logoutCmd())
 	rootCmd.AddCommand(configCmd())
 	rootCmd.AddCommand(registryCmd())
+	rootCmd.AddCommand(adaptersCmd())
 	rootCmd.AddCommand(versionCmd())
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	// Packages record the Axon version that built them in provenance.json
+	core.SetBuilderVersion(version)
+
+	// Remote lookups are memoized for the duration of the command
+	ctx := core.WithResolution(context.Background(), core.NewResolution())
+	flushTraces := initTracing()
+	err := rootCmd.ExecuteContext(ctx)
+	flushTraces()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", credentials.Redact(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/manifest"
+)
+
+// setManifestFields applies field=value assignments to the manifest of the model in
+// modelPath and saves it, re-signing it with the key at keyPath if one is given. It
+// returns the new metadata revision.
+func setManifestFields(modelPath string, assignments []string, keyPath string, dryRun bool) (int, error) {
+	manifestPath := filepath.Join(modelPath, "manifest.yaml")
+	m, err := loadManifest(manifestPath)
+	if err != nil {
+		return 0, err
+	}
+	edited, err := manifest.Edit(m, assignments, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		return edited.Metadata.Revision, nil
+	}
+
+	if keyPath != "" {
+		signature, err := signManifest(edited, modelPath, keyPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to sign package: %w", err)
+		}
+		fmt.Printf("🔏 Signed package (key %s, sha256:%s)\n", signature.KeyID, edited.Distribution.Package.SHA256)
+	} else if len(edited.Distribution.Package.Signatures) > 0 {
+		fmt.Printf("ℹ️  Existing signatures still apply: they cover the package digest, which metadata edits don't change\n")
+	}
+
+	if err := saveManifest(edited, manifestPath); err != nil {
+		return 0, fmt.Errorf("failed to save manifest: %w", err)
+	}
+	return edited.Metadata.Revision, nil
+}
+
+func manifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "manifest",
+		Short: "Edit model manifests",
+	}
+	cmd.AddCommand(manifestSetCmd())
+	return cmd
+}
+
+func manifestSetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <namespace/name[@version] | model-dir> field=value...",
+		Short: "Edit the metadata of an installed or published model in place",
+		Long: `Edit the metadata of an installed model, or of a published model given its
+directory, instead of hand-editing manifest.yaml. Values are checked before anything
+is written, and each edit bumps metadata.revision and metadata.updated.
+
+Editable fields:
+  ` + strings.Join(manifest.EditableFields(), "\n  ") + `
+
+tags takes a comma-separated list that replaces the current tags. The package
+itself isn't changed, so existing signatures stay valid; use --sign to add or
+replace your own signature.
+
+Examples:
+  axon manifest set hf/bert-base-uncased@latest description="BERT base, uncased"
+  axon manifest set hf/bert-base-uncased@latest tags=nlp,fill-mask requirements.memory.min_gb=2
+  axon manifest set /var/lib/mlos/models/nlp/bert/1.0.0 requirements.gpu.required=false --sign`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			modelPath, err := resolveModelPath(args[0])
+			if err != nil {
+				return err
+			}
+
+			var keyPath string
+			if sign, _ := cmd.Flags().GetBool("sign"); sign {
+				key, _ := cmd.Flags().GetString("key")
+				keyPath = signingKeyPath(key)
+			}
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			revision, err := setManifestFields(modelPath, args[1:], keyPath, dryRun)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Printf("✓ Manifest of %s would be updated to revision %d (dry run, nothing written)\n", args[0], revision)
+				return nil
+			}
+			fmt.Printf("✓ Updated manifest of %s (revision %d)\n", args[0], revision)
+			for _, assignment := range args[1:] {
+				fmt.Printf("   %s\n", assignment)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("sign", false, "Re-sign the package with your signing key")
+	cmd.Flags().String("key", "", "Signing key name in ~/.axon/keys or private key path for --sign (default: security.signing_key or ~/.axon/keys/signing.pem)")
+	cmd.Flags().Bool("dry-run", false, "Check the edits without writing the manifest")
+	return cmd
+}
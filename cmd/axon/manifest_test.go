@@ -0,0 +1,55 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/signing"
+)
+
+func TestSetManifestFields(t *testing.T) {
+	modelDir, keyPath, public := writeSigningFixture(t)
+	trusted := []ed25519.PublicKey{public}
+	manifestPath := filepath.Join(modelDir, "manifest.yaml")
+
+	// A dry run checks the edit without writing it
+	revision, err := setManifestFields(modelDir, []string{"description=BERT"}, "", true)
+	if err != nil || revision != 1 {
+		t.Fatalf("dry run = revision %d, error %v", revision, err)
+	}
+	if m, _ := loadManifest(manifestPath); m.Metadata.Description != "" || m.Metadata.Revision != 0 {
+		t.Errorf("dry run wrote the manifest: %+v", m.Metadata)
+	}
+
+	// Signing with --sign
+	if _, err := setManifestFields(modelDir, []string{"description=BERT"}, keyPath, false); err != nil {
+		t.Fatalf("setManifestFields(sign) error = %v", err)
+	}
+	m, err := loadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Metadata.Description != "BERT" || m.Metadata.Revision != 1 {
+		t.Errorf("metadata = %+v, want the new description at revision 1", m.Metadata)
+	}
+	if _, err := signing.Verify(m, trusted); err != nil {
+		t.Errorf("signature after --sign does not verify: %v", err)
+	}
+
+	// A later edit without --sign keeps the signature valid
+	if revision, err := setManifestFields(modelDir, []string{"tags=nlp,fill-mask"}, "", false); err != nil || revision != 2 {
+		t.Fatalf("setManifestFields() = revision %d, error %v", revision, err)
+	}
+	m, err = loadManifest(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signing.Verify(m, trusted); err != nil {
+		t.Errorf("signature after a metadata edit does not verify: %v", err)
+	}
+
+	if _, err := setManifestFields(modelDir, []string{"requirements.cpu.min_cores=0"}, "", false); err == nil {
+		t.Error("setManifestFields() accepted an invalid value")
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/picklescan"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// overrideLicensePolicyFlag is the flag that lets install and publish ignore the license policy
+const overrideLicensePolicyFlag = "override-license-policy"
+
+// checkLicensePolicy checks a model's license against policy.allowed_licenses and
+// policy.denied_licenses. With override, a violation is only reported.
+func checkLicensePolicy(p config.PolicyConfig, m *types.Manifest, override bool) error {
+	err := p.LicensePolicy().Check(m.Metadata.License)
+	if err == nil {
+		return nil
+	}
+	if override {
+		fmt.Printf("⚠️  License policy overridden: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+// Modes of policy.missing_assets
+const (
+	missingAssetsWarn   = "warn"
+	missingAssetsFail   = "fail"
+	missingAssetsIgnore = "ignore"
+)
+
+// checkAssetPolicy checks that an extracted model has the preprocessing assets its task
+// needs (a tokenizer for text, a preprocessor config for vision and audio), which MLOS
+// Core can't run inference without. Under policy.missing_assets "fail" a missing asset
+// is an error, under "warn" (the default) it's reported.
+func checkAssetPolicy(p config.PolicyConfig, modelDir, task string) error {
+	mode := p.MissingAssets
+	switch mode {
+	case "":
+		mode = missingAssetsWarn
+	case missingAssetsWarn, missingAssetsFail:
+	case missingAssetsIgnore:
+		return nil
+	default:
+		return fmt.Errorf("invalid policy.missing_assets %q (expected %s, %s or %s)", mode, missingAssetsWarn, missingAssetsFail, missingAssetsIgnore)
+	}
+
+	missing := model.MissingAssets(modelDir, task)
+	if len(missing) == 0 {
+		return nil
+	}
+	var problems []string
+	for _, asset := range missing {
+		problems = append(problems, fmt.Sprintf("no %s (%s)", asset, model.AssetFiles(asset)))
+	}
+	err := fmt.Errorf("%s model has %s; inference will fail without it", task, strings.Join(problems, " and "))
+	if mode == missingAssetsFail {
+		return fmt.Errorf("%w (policy.missing_assets is %s)", err, mode)
+	}
+	fmt.Printf("⚠️  Incomplete model: %v\n", err)
+	return nil
+}
+
+// Modes of security.scan_weights
+const (
+	scanWeightsWarn  = "warn"
+	scanWeightsBlock = "block"
+	scanWeightsOff   = "off"
+)
+
+// checkWeightScanPolicy scans the pickle-based weight files of an extracted model for
+// imports that run code when they are loaded, and records the scan in its provenance.json.
+// Under security.scan_weights "block" a dangerous import is an error; imports that are
+// only unknown, such as the classes of a pickled model object, are reported either way.
+func checkWeightScanPolicy(security config.SecurityConfig, modelDir string) error {
+	mode, err := weightScanMode(security)
+	if err != nil || mode == scanWeightsOff {
+		return err
+	}
+
+	scan, err := picklescan.ScanDir(modelDir)
+	if err != nil {
+		if mode == scanWeightsBlock {
+			return fmt.Errorf("weight scan failed: %w (security.scan_weights is %s)", err, mode)
+		}
+		fmt.Printf("⚠️  Weight scan failed: %v\n", err)
+		return nil
+	}
+	if len(scan.Files) == 0 {
+		return nil
+	}
+
+	var dangerous []string
+	for _, f := range scan.Findings {
+		fmt.Printf("⚠️  Pickle import in %s is %s: %s\n", f.File, f.Severity, f.Import)
+		if f.Severity == picklescan.SeverityDangerous {
+			dangerous = append(dangerous, fmt.Sprintf("%s (%s)", f.Import, f.File))
+		}
+	}
+	if len(dangerous) > 0 && mode == scanWeightsBlock {
+		return fmt.Errorf("weights import %s, which can run code when loaded (security.scan_weights is %s)", strings.Join(dangerous, ", "), mode)
+	}
+	if len(scan.Findings) == 0 {
+		fmt.Printf("✓ Scanned %d weight file(s): no unsafe pickle imports\n", len(scan.Files))
+	}
+
+	provenancePath := filepath.Join(modelDir, types.ProvenanceFileName)
+	provenance, err := core.ReadProvenance(provenancePath)
+	if err != nil {
+		// Packages built before provenance.json have nowhere to record the scan
+		return nil
+	}
+	provenance.WeightScan = scan
+	if err := core.WriteProvenance(provenancePath, provenance); err != nil {
+		fmt.Printf("⚠️  Failed to record the weight scan: %v\n", err)
+	}
+	return nil
+}
+
+// checkPackageWeightScanPolicy applies security.scan_weights to the weights of a package
+// that is cached without being extracted: its pickle-based weight files are unpacked to
+// a temporary directory and scanned there.
+func checkPackageWeightScanPolicy(security config.SecurityConfig, packagePath string) error {
+	mode, err := weightScanMode(security)
+	if err != nil || mode == scanWeightsOff {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(utils.TempDir(), "axon-scan-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+	if err := model.ExtractPackageMatching(packagePath, tmpDir, picklescan.IsWeightFile); err != nil {
+		return fmt.Errorf("failed to read the package's weight files: %w", err)
+	}
+	return checkWeightScanPolicy(security, tmpDir)
+}
+
+// weightScanMode returns the security.scan_weights mode, defaulting to warn
+func weightScanMode(security config.SecurityConfig) (string, error) {
+	switch mode := security.ScanWeights; mode {
+	case "":
+		return scanWeightsWarn, nil
+	case scanWeightsWarn, scanWeightsBlock, scanWeightsOff:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid security.scan_weights %q (expected %s, %s or %s)", mode, scanWeightsWarn, scanWeightsBlock, scanWeightsOff)
+	}
+}
+
+// overrideSizePolicyFlag is the flag that lets install ignore the model size policy
+const overrideSizePolicyFlag = "override-size-policy"
+
+// manifestSize returns the download size declared by a manifest: the total of its file
+// sizes, or the package size. It is 0 when the repository doesn't report sizes.
+func manifestSize(m *types.Manifest) int64 {
+	var size int64
+	for _, file := range m.Spec.Format.Files {
+		size += file.Size
+	}
+	if size == 0 {
+		size = m.Distribution.Package.Size
+	}
+	return size
+}
+
+// checkSizePolicy checks a model's declared size against policy.max_model_size_gb and
+// the quota of its namespace. With override, a violation is only reported.
+func checkSizePolicy(p config.PolicyConfig, tenant string, cacheMgr *cache.Manager, namespace string, m *types.Manifest, override bool) error {
+	err := sizePolicyError(p, tenant, cacheMgr, namespace, m)
+	if err == nil {
+		return nil
+	}
+	if override {
+		fmt.Printf("⚠️  Size policy overridden: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+// sizePolicyError returns why a model violates the size policy, or nil. cacheMgr is the
+// tenant's cache.
+func sizePolicyError(p config.PolicyConfig, tenant string, cacheMgr *cache.Manager, namespace string, m *types.Manifest) error {
+	sp := p.SizePolicy(tenant)
+	size := manifestSize(m)
+	var used int64
+	if sp.QuotaBytes[namespace] > 0 {
+		var err error
+		if used, err = cacheMgr.GetNamespaceSize(namespace); err != nil {
+			return fmt.Errorf("failed to measure namespace %s: %w", namespace, err)
+		}
+	}
+	if err := sp.Check(namespace, size, used); err != nil {
+		return err
+	}
+	if sp.TenantQuotaBytes > 0 {
+		used, err := cacheMgr.GetModelsSize()
+		if err != nil {
+			return fmt.Errorf("failed to measure tenant %s: %w", tenant, err)
+		}
+		return sp.CheckTenant(tenant, size, used)
+	}
+	return nil
+}
+
+// printPolicyStatus shows whether a model passes the configured license and size policies
+func printPolicyStatus(p config.PolicyConfig, tenant string, cacheMgr *cache.Manager, namespace string, m *types.Manifest) {
+	var violations []error
+	if err := p.LicensePolicy().Check(m.Metadata.License); err != nil {
+		violations = append(violations, err)
+	}
+	if err := sizePolicyError(p, tenant, cacheMgr, namespace, m); err != nil {
+		violations = append(violations, err)
+	}
+
+	if len(violations) == 0 {
+		fmt.Printf("Policy:      ✓ passes")
+		if manifestSize(m) == 0 && (p.MaxModelSizeGB > 0 || len(p.NamespaceQuotasGB) > 0 || p.TenantQuotasGB[tenant] > 0) {
+			fmt.Printf(" (size unknown, not checked)")
+		}
+		fmt.Println()
+		return
+	}
+	fmt.Printf("Policy:      ✗ violates policy\n")
+	for _, err := range violations {
+		fmt.Printf("  - %v\n", err)
+	}
+}
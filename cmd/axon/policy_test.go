@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/policy"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestCheckLicensePolicy(t *testing.T) {
+	p := config.PolicyConfig{DeniedLicenses: []string{"agpl-*", "*rail*"}}
+	m := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: "model", License: "creativeml-openrail-m"}}
+
+	if err := checkLicensePolicy(p, m, false); !errors.Is(err, policy.ErrLicenseDenied) {
+		t.Errorf("checkLicensePolicy() error = %v, want ErrLicenseDenied", err)
+	}
+	if err := checkLicensePolicy(p, m, true); err != nil {
+		t.Errorf("checkLicensePolicy() with override error = %v, want nil", err)
+	}
+
+	m.Metadata.License = "apache-2.0"
+	if err := checkLicensePolicy(p, m, false); err != nil {
+		t.Errorf("checkLicensePolicy() error = %v for an allowed license", err)
+	}
+}
+
+func TestCheckSizePolicy(t *testing.T) {
+	cacheMgr := cache.NewManager(t.TempDir())
+	installed := cacheMgr.GetModelPath("hf", "big-model", "1.0")
+	if err := os.MkdirAll(installed, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(installed, "model.safetensors"), make([]byte, 3000), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &types.Manifest{Spec: types.Spec{Format: types.Format{Files: []types.ModelFile{
+		{Path: "model.safetensors", Size: 1500},
+		{Path: "config.json", Size: 500},
+	}}}}
+	if got := manifestSize(m); got != 2000 {
+		t.Errorf("manifestSize() = %d, want 2000", got)
+	}
+
+	tests := []struct {
+		name      string
+		policy    config.PolicyConfig
+		tenant    string
+		namespace string
+		wantErr   bool
+	}{
+		{"no policy", config.PolicyConfig{}, "", "hf", false},
+		{"over max model size", config.PolicyConfig{MaxModelSizeGB: 1500.0 / policy.GB}, "", "hf", true},
+		{"over namespace quota", config.PolicyConfig{NamespaceQuotasGB: map[string]float64{"hf": 4000.0 / policy.GB}}, "", "hf", true},
+		{"empty namespace within quota", config.PolicyConfig{NamespaceQuotasGB: map[string]float64{"tfhub": 4000.0 / policy.GB}}, "", "tfhub", false},
+		{"over tenant quota", config.PolicyConfig{TenantQuotasGB: map[string]float64{"team-a": 4000.0 / policy.GB}}, "team-a", "tfhub", true},
+		{"other tenant's quota", config.PolicyConfig{TenantQuotasGB: map[string]float64{"team-b": 4000.0 / policy.GB}}, "team-a", "tfhub", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSizePolicy(tt.policy, tt.tenant, cacheMgr, tt.namespace, m, false)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkSizePolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, policy.ErrSizeExceeded) {
+				t.Errorf("checkSizePolicy() error = %v, want ErrSizeExceeded", err)
+			}
+			if err := checkSizePolicy(tt.policy, tt.tenant, cacheMgr, tt.namespace, m, true); err != nil {
+				t.Errorf("checkSizePolicy() with override error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestCheckAssetPolicy(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		mode    string
+		task    string
+		wantErr bool
+	}{
+		{"", "text-classification", false},
+		{"warn", "text-classification", false},
+		{"ignore", "text-classification", false},
+		{"fail", "text-classification", true},
+		{"fail", "feature-extraction", false},
+		{"sometimes", "text-classification", true},
+	}
+	for _, tt := range tests {
+		err := checkAssetPolicy(config.PolicyConfig{MissingAssets: tt.mode}, dir, tt.task)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkAssetPolicy(%q, %s) error = %v, wantErr %t", tt.mode, tt.task, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCheckWeightScanPolicy(t *testing.T) {
+	tests := []struct {
+		mode       string
+		wantErr    bool
+		wantRecord bool
+	}{
+		{"", false, true},
+		{"warn", false, true},
+		{"block", true, false},
+		{"off", false, false},
+		{"sometimes", true, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		// posix.system("id"), as a pickle
+		if err := os.WriteFile(filepath.Join(dir, "pytorch_model.bin"), []byte("\x80\x02cposix\nsystem\nq\x00X\x02\x00\x00\x00idq\x01\x85q\x02Rq\x03."), 0644); err != nil {
+			t.Fatal(err)
+		}
+		provenancePath := filepath.Join(dir, types.ProvenanceFileName)
+		if err := core.WriteProvenance(provenancePath, &types.Provenance{SchemaVersion: types.ProvenanceSchemaVersion}); err != nil {
+			t.Fatal(err)
+		}
+
+		err := checkWeightScanPolicy(config.SecurityConfig{ScanWeights: tt.mode}, dir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkWeightScanPolicy(%q) error = %v, wantErr %t", tt.mode, err, tt.wantErr)
+		}
+		provenance, err := core.ReadProvenance(provenancePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if recorded := provenance.WeightScan != nil; recorded != tt.wantRecord {
+			t.Errorf("checkWeightScanPolicy(%q) recorded the scan: %t, want %t", tt.mode, recorded, tt.wantRecord)
+		} else if recorded && (len(provenance.WeightScan.Findings) != 1 || provenance.WeightScan.Findings[0].Import != "posix.system") {
+			t.Errorf("checkWeightScanPolicy(%q) recorded %+v, want the posix.system import", tt.mode, provenance.WeightScan)
+		}
+	}
+}
+
+func TestWeightScanBeforeCaching(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+	cfg.Security.ScanWeights = scanWeightsBlock
+
+	dir := t.TempDir()
+	// posix.system("id"), as a pickle
+	if err := os.WriteFile(filepath.Join(dir, "pytorch_model.bin"), []byte("\x80\x02cposix\nsystem\nq\x00X\x02\x00\x00\x00idq\x01\x85q\x02Rq\x03."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"model_type": "bert"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Imports (and import-bundle and delta updates, which cache the same way) are refused
+	ref, err := spec.Parse("myteam/unsafe@1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := importModel(dir, ref, importOptions{license: "mit"}); err == nil {
+		t.Fatal("importModel() of a dangerous pickle succeeded under scan_weights block")
+	}
+	if cache.NewManager(cfg.ModelCacheDir()).IsModelCached("myteam", "unsafe", "1.0.0") {
+		t.Error("importModel() left the refused model in the cache")
+	}
+
+	// Packages cached without extraction are scanned from the package
+	builder, err := core.NewPackageBuilder()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = builder.Cleanup() }()
+	for _, name := range []string{"pytorch_model.bin", "config.json"} {
+		if err := builder.AddFile(filepath.Join(dir, name), name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	packagePath := filepath.Join(t.TempDir(), "unsafe.axon")
+	if err := builder.Build(packagePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkPackageWeightScanPolicy(cfg.Security, packagePath); err == nil {
+		t.Error("checkPackageWeightScanPolicy() of a dangerous pickle succeeded under scan_weights block")
+	}
+	if err := checkPackageWeightScanPolicy(config.SecurityConfig{ScanWeights: scanWeightsWarn}, packagePath); err != nil {
+		t.Errorf("checkPackageWeightScanPolicy() under scan_weights warn error = %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/daemon"
+)
+
+// queueRequestTimeout bounds how long 'axon queue' waits for the daemon
+const queueRequestTimeout = 10 * time.Second
+
+func queueCmd() *cobra.Command {
+	var addr, socket string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Show the jobs of a running 'axon serve'",
+		Long: `Show what a running 'axon serve' daemon is doing: the running jobs, then the queued
+jobs in the order they will start, with their priority class (urgent, normal or
+background), download progress and how often urgent jobs preempted them.
+
+Use the --addr or --socket the daemon listens on. If the daemon requires a token, set
+$` + serveTokenEnv + ` to it.
+
+Examples:
+  axon queue
+  axon queue --socket ~/.axon/axon.sock --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			jobs, err := fetchQueue(cmd.Context(), addr, socket)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(jobs)
+			}
+			printQueue(jobs)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", defaultServeAddr, "TCP address of the daemon")
+	cmd.Flags().StringVar(&socket, "socket", "", "Unix socket of the daemon instead of TCP")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the jobs as JSON")
+	return cmd
+}
+
+// fetchQueue returns the running and queued jobs of the daemon at addr or socket
+func fetchQueue(ctx context.Context, addr, socket string) ([]daemon.Job, error) {
+	client := &http.Client{Timeout: queueRequestTimeout}
+	baseURL, where := "http://"+addr, addr
+	if socket != "" {
+		client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+			},
+		}
+		baseURL, where = "http://axon", socket
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+daemon.APIVersion+"/queue", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv(serveTokenEnv); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach the Axon API at %s (is 'axon serve' running?): %w", where, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("the Axon API answered %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var jobs []daemon.Job
+	if err := json.NewDecoder(resp.Body).Decode(&jobs); err != nil {
+		return nil, fmt.Errorf("failed to parse the queue: %w", err)
+	}
+	return jobs, nil
+}
+
+// printQueue prints the running and queued jobs as a table
+func printQueue(jobs []daemon.Job) {
+	if len(jobs) == 0 {
+		fmt.Println("No jobs running or queued")
+		return
+	}
+	width := len("MODEL")
+	for _, job := range jobs {
+		if len(job.Model) > width {
+			width = len(job.Model)
+		}
+	}
+
+	fmt.Printf("%-16s  %-8s  %-10s  %-*s  %s\n", "ID", "STATUS", "PRIORITY", width, "MODEL", "PROGRESS")
+	for _, job := range jobs {
+		progress := "-"
+		switch {
+		case job.Total > 0:
+			progress = fmt.Sprintf("%.1f%% of %s", float64(job.Downloaded)/float64(job.Total)*100, formatBytes(job.Total))
+		case job.Downloaded > 0:
+			progress = formatBytes(job.Downloaded)
+		}
+		if job.Preemptions > 0 {
+			progress += fmt.Sprintf(" (preempted %d×)", job.Preemptions)
+		}
+		fmt.Printf("%-16s  %-8s  %-10s  %-*s  %s\n", job.ID, job.Status, job.Priority, width, job.Model, progress)
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+// registerStatusRegistered is the outcome of 'axon register --all' for a model it registered
+const registerStatusRegistered = "registered"
+
+// cachedRegistrations returns a registration for every cached model, with the model
+// dependencies from its manifest, in dependency order
+func cachedRegistrations() ([]mlos.Registration, error) {
+	models, err := cache.NewManager(cfg.ModelCacheDir()).ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	regs := &mlos.Registrations{}
+	for _, cached := range models {
+		reg := mlos.Registration{
+			ModelID: fmt.Sprintf("%s/%s@%s", cached.Namespace, cached.Name, cached.Version),
+			Path:    cached.Path,
+		}
+		if m, err := loadManifest(filepath.Join(cached.Path, "manifest.yaml")); err == nil {
+			reg.DependsOn = m.Spec.Dependencies.Models
+		}
+		regs.Models = append(regs.Models, reg)
+	}
+	return regs.InDependencyOrder()
+}
+
+// registerAll registers ordered with MLOS Core one by one, continuing past failures.
+// Models whose dependencies failed are skipped.
+func registerAll(ctx context.Context, ordered []mlos.Registration, convert, force bool) []restoreResult {
+	results := make([]restoreResult, 0, len(ordered))
+	var failed []string
+
+	for _, reg := range ordered {
+		result := restoreResult{ModelID: reg.ModelID, Status: registerStatusRegistered}
+
+		var failedDeps []string
+		for _, id := range failed {
+			if reg.DependsOnModel(id) {
+				failedDeps = append(failedDeps, id)
+			}
+		}
+
+		if len(failedDeps) > 0 {
+			result.Status = restoreStatusSkipped
+			result.Error = "dependency failed: " + strings.Join(failedDeps, ", ")
+		} else if err := modelRegistrar(ctx, reg.ModelID, convert, force); err != nil {
+			result.Status = restoreStatusFailed
+			result.Error = err.Error()
+		}
+
+		if result.Status != registerStatusRegistered {
+			failed = append(failed, reg.ModelID)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// registerAllCached registers every cached model with MLOS Core and prints a summary
+func registerAllCached(ctx context.Context, convert, force bool) error {
+	ordered, err := cachedRegistrations()
+	if err != nil {
+		return err
+	}
+	if len(ordered) == 0 {
+		fmt.Println("No models installed")
+		return nil
+	}
+
+	fmt.Printf("📦 Registering %d cached model(s) with MLOS Core\n", len(ordered))
+	results := registerAll(ctx, ordered, convert, force)
+
+	var failures int
+	fmt.Printf("\n📋 Registration summary:\n")
+	for _, r := range results {
+		icon := "✓"
+		if r.Status != registerStatusRegistered {
+			icon = "✗"
+			failures++
+		}
+		fmt.Printf("  %s %-10s %s", icon, r.Status, r.ModelID)
+		if r.Error != "" {
+			fmt.Printf("  (%s)", strings.SplitN(r.Error, "\n", 2)[0])
+		}
+		fmt.Println()
+	}
+
+	if failures > 0 {
+		return fmt.Errorf("%d of %d models could not be registered", failures, len(results))
+	}
+	fmt.Printf("✅ Registered %d model(s)\n", len(results))
+	return nil
+}
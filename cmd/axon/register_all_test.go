@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestRegisterAllCached(t *testing.T) {
+	originalCfg, originalRegistrar := cfg, modelRegistrar
+	defer func() { cfg, modelRegistrar = originalCfg, originalRegistrar }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	for name, deps := range map[string][]string{
+		"a-pipeline": {"hf/z-encoder"},
+		"broken":     nil,
+		"needs-fix":  {"hf/broken@latest"},
+		"z-encoder":  nil,
+	} {
+		manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: name, Version: "latest"}}
+		manifest.Spec.Dependencies.Models = deps
+		if err := cacheMgr.CacheModel("hf", name, "latest", manifest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var registered []string
+	modelRegistrar = func(ctx context.Context, modelSpec string, convert, force bool) error {
+		if !convert || force {
+			t.Errorf("registrar(%s, convert=%t, force=%t), want --convert passed through", modelSpec, convert, force)
+		}
+		if strings.Contains(modelSpec, "broken") {
+			return errors.New("unsupported execution format")
+		}
+		registered = append(registered, modelSpec)
+		return nil
+	}
+
+	err := registerAllCached(context.Background(), true, false)
+	if err == nil || !strings.Contains(err.Error(), "2 of 4") {
+		t.Errorf("registerAllCached() error = %v, want 2 of 4 models failed", err)
+	}
+	want := []string{"hf/z-encoder@latest", "hf/a-pipeline@latest"}
+	if !reflect.DeepEqual(registered, want) {
+		t.Errorf("registered %v, want dependencies first and dependents of hf/broken skipped: %v", registered, want)
+	}
+}
+
+func TestInstallModel_Register(t *testing.T) {
+	originalCfg, originalRegistrar := cfg, modelRegistrar
+	defer func() { cfg, modelRegistrar = originalCfg, originalRegistrar }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: "bert", Version: "latest"}}
+	if err := cache.NewManager(cfg.ModelCacheDir()).CacheModel("hf", "bert", "latest", manifest); err != nil {
+		t.Fatal(err)
+	}
+
+	var registered []string
+	var registerErr error
+	modelRegistrar = func(ctx context.Context, modelSpec string, convert, force bool) error {
+		registered = append(registered, modelSpec)
+		return registerErr
+	}
+
+	if err := installModel(context.Background(), "hf/bert", installOptions{}); err != nil || len(registered) != 0 {
+		t.Fatalf("installModel() = %v and registered %v, want no registration without --register", err, registered)
+	}
+	if err := installModel(context.Background(), "hf/bert", installOptions{register: true}); err != nil {
+		t.Fatalf("installModel(register) error = %v", err)
+	}
+	if !reflect.DeepEqual(registered, []string{"hf/bert@latest"}) {
+		t.Errorf("registered %v, want hf/bert@latest", registered)
+	}
+
+	registerErr = errors.New("MLOS Core is not reachable")
+	err := installModel(context.Background(), "hf/bert", installOptions{register: true})
+	if err == nil || !strings.Contains(err.Error(), "is installed but could not be registered") {
+		t.Errorf("installModel(register) with Core down error = %v", err)
+	}
+}
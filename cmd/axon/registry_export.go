@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/attestation"
+	"github.com/mlOS-foundation/axon/internal/registry"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func registryExportCmd() *cobra.Command {
+	var from, dest string
+
+	cmd := &cobra.Command{
+		Use:   "export --dest <dir>",
+		Short: "Export a registry as static files",
+		Long: `Export an Axon registry as a static file layout that can be hosted on object storage
+(S3, GCS) or any static web host (Netlify, GitHub Pages, nginx):
+
+  api/v1/models/<ns>/<name>/<version>/manifest.yaml   manifests (and attestation.json)
+  api/v1/models/<ns>/<name>/versions.json             versions, with the latest pointer
+  api/v1/index.json                                   every model, for update checks
+  api/v1/search.json                                  search index, searched by clients
+  packages/                                           packages and deltas
+
+The registry is read from --from: the URL of a registry server, or the directory of a
+file-based registry such as one 'axon publish --registry-dir' writes. It defaults to the
+configured registry. Package URLs are made relative to the registry, so the export can
+be hosted at any URL; set registry.url to that URL to install from it. Exporting into
+an existing export updates it, and packages already there are not downloaded again.
+
+Examples:
+  axon registry export --dest ./static
+  axon registry export --from /srv/axon-registry --dest ./static
+  aws s3 sync ./static s3://my-bucket/axon`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" {
+				from = cfg.Registry.URL
+			}
+			if from == "" {
+				return fmt.Errorf("no registry to export: pass --from or set registry.url")
+			}
+
+			manifests, err := exportRegistry(cmd.Context(), from, dest)
+			if err != nil {
+				return err
+			}
+			if err := writeStaticIndexes(dest, manifests); err != nil {
+				return err
+			}
+			fmt.Printf("✅ Exported %d model versions from %s to %s\n", len(manifests), from, dest)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&from, "from", "", "Registry URL or directory to export (default: the configured registry)")
+	cmd.Flags().StringVar(&dest, "dest", "", "Directory to write the static registry to")
+	_ = cmd.MarkFlagRequired("dest")
+	return cmd
+}
+
+// exportRegistry copies every model version of the registry at from, a URL or a
+// directory, into dest, and returns their manifests
+func exportRegistry(ctx context.Context, from, dest string) ([]*types.Manifest, error) {
+	if info, err := os.Stat(from); err == nil && info.IsDir() {
+		return exportRegistryDir(from, dest)
+	}
+	if !strings.HasPrefix(from, "http://") && !strings.HasPrefix(from, "https://") {
+		return nil, fmt.Errorf("%s is neither a registry URL nor a directory", from)
+	}
+	return exportRegistryURL(ctx, strings.TrimRight(from, "/"), dest)
+}
+
+// exportRegistryDir copies the manifests, attestations and packages of a file-based
+// registry, as they are
+func exportRegistryDir(srcDir, dest string) ([]*types.Manifest, error) {
+	manifestsDir := filepath.Join(srcDir, "api", "v1", "models")
+	var manifests []*types.Manifest
+	err := filepath.WalkDir(manifestsDir, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || d.Name() != "manifest.yaml" {
+			return nil
+		}
+		m, err := loadManifest(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		// Manifests without metadata are identified by their path
+		if m.Metadata.Namespace == "" || m.Metadata.Name == "" || m.Metadata.Version == "" {
+			rel, _ := filepath.Rel(manifestsDir, filepath.Dir(p))
+			parts := strings.Split(filepath.ToSlash(rel), "/")
+			if len(parts) < 3 {
+				return fmt.Errorf("%s: manifest is not in <namespace>/<name>/<version>", p)
+			}
+			m.Metadata.Namespace, m.Metadata.Version = parts[0], parts[len(parts)-1]
+			m.Metadata.Name = strings.Join(parts[1:len(parts)-1], "/")
+		}
+
+		manifestDir := registryManifestDir(dest, m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version)
+		if err := os.MkdirAll(manifestDir, 0755); err != nil {
+			return fmt.Errorf("failed to create registry directory: %w", err)
+		}
+		if err := copyFile(p, filepath.Join(manifestDir, "manifest.yaml")); err != nil {
+			return fmt.Errorf("failed to copy manifest: %w", err)
+		}
+		attestationPath := filepath.Join(filepath.Dir(p), attestation.FileName)
+		if _, err := os.Stat(attestationPath); err == nil {
+			if err := copyFile(attestationPath, filepath.Join(manifestDir, attestation.FileName)); err != nil {
+				return fmt.Errorf("failed to copy attestation: %w", err)
+			}
+		}
+
+		// Packages served by the registry are copied with their deltas; others stay where
+		// they are
+		if packageName, ok := strings.CutPrefix(m.Distribution.Package.URL, "/packages/"); ok {
+			files := []string{packageName}
+			for _, delta := range m.Distribution.Deltas {
+				files = append(files, path.Join(path.Dir(packageName), delta.URL))
+			}
+			for _, file := range files {
+				src := filepath.Join(srcDir, "packages", filepath.FromSlash(file))
+				dst := filepath.Join(dest, "packages", filepath.FromSlash(file))
+				if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+					return fmt.Errorf("failed to create packages directory: %w", err)
+				}
+				if err := copyFile(src, dst); err != nil {
+					return fmt.Errorf("failed to copy package of %s/%s@%s: %w", m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version, err)
+				}
+			}
+		}
+		fmt.Printf("  ✓ %s/%s@%s\n", m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version)
+		manifests = append(manifests, m)
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) && len(manifests) == 0 {
+		return nil, fmt.Errorf("%s is not an Axon registry: no %s", srcDir, manifestsDir)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return manifests, nil
+}
+
+// exportRegistryURL downloads every version of every model a registry server lists.
+// Packages it serves are downloaded, and verified against their checksums; deltas are
+// not, so clients of the export download full packages.
+func exportRegistryURL(ctx context.Context, baseURL, dest string) ([]*types.Manifest, error) {
+	client := registry.NewClient(baseURL, nil)
+	if token, err := newCredentialManager().Get("local"); err == nil {
+		client.SetToken(token)
+	}
+	index, err := client.GetIndex(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get registry index: %w", err)
+	}
+
+	// Registries that can't list versions get the versions in their index
+	type model struct{ namespace, name string }
+	var models []model
+	indexed := make(map[model][]string)
+	for _, entry := range index.Models {
+		m := model{entry.Namespace, entry.Name}
+		if _, ok := indexed[m]; !ok {
+			models = append(models, m)
+		}
+		if entry.LatestVersion != "" {
+			indexed[m] = append(indexed[m], entry.LatestVersion)
+		}
+	}
+
+	var manifests []*types.Manifest
+	for _, model := range models {
+		versions, err := client.ListVersions(ctx, model.namespace, model.name)
+		if errors.Is(err, os.ErrNotExist) {
+			versions, err = indexed[model], nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of %s/%s: %w", model.namespace, model.name, err)
+		}
+		for _, version := range versions {
+			m, err := exportRegistryVersion(ctx, client, baseURL, dest, model.namespace, model.name, version)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export %s/%s@%s: %w", model.namespace, model.name, version, err)
+			}
+			fmt.Printf("  ✓ %s/%s@%s\n", model.namespace, model.name, version)
+			manifests = append(manifests, m)
+		}
+	}
+	return manifests, nil
+}
+
+// exportRegistryVersion downloads a model version's manifest, attestation and package
+// from a registry server into dest
+func exportRegistryVersion(ctx context.Context, client *registry.Client, baseURL, dest, namespace, name, version string) (*types.Manifest, error) {
+	m, err := client.GetManifest(ctx, namespace, name, version)
+	if err != nil {
+		return nil, err
+	}
+	m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version = namespace, name, version
+
+	if packageName, ok := strings.CutPrefix(m.Distribution.Package.URL, baseURL+"/packages/"); ok && packageName == path.Base(packageName) {
+		packagesDir := filepath.Join(dest, "packages")
+		if err := os.MkdirAll(packagesDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create packages directory: %w", err)
+		}
+		packagePath := filepath.Join(packagesDir, packageName)
+		if digest, _, err := core.ComputeChecksum(packagePath); err != nil || !strings.EqualFold(digest, m.Distribution.Package.SHA256) {
+			if err := client.DownloadPackage(ctx, m, packagePath, nil); err != nil {
+				return nil, err
+			}
+		}
+		m.Distribution.Package.URL = "/packages/" + packageName
+		m.Distribution.Package.Mirrors = nil
+		m.Distribution.Deltas = nil
+	}
+
+	manifestDir := registryManifestDir(dest, namespace, name, version)
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create registry directory: %w", err)
+	}
+	data, err := client.GetAttestation(ctx, namespace, name, version)
+	switch {
+	case err == nil:
+		if err := os.WriteFile(filepath.Join(manifestDir, attestation.FileName), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write attestation: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to get attestation: %w", err)
+	}
+	// The manifest goes last, so a partial export never lists a version without its files
+	if err := saveManifest(m, filepath.Join(manifestDir, "manifest.yaml")); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return m, nil
+}
+
+// writeStaticIndexes writes the files a registry server generates: the versions.json of
+// every model, the registry index and the search index
+func writeStaticIndexes(dest string, manifests []*types.Manifest) error {
+	type model struct{ namespace, name string }
+	versions := make(map[model]map[string]*types.Manifest)
+	for _, m := range manifests {
+		key := model{m.Metadata.Namespace, m.Metadata.Name}
+		if versions[key] == nil {
+			versions[key] = make(map[string]*types.Manifest)
+		}
+		versions[key][m.Metadata.Version] = m
+	}
+	models := make([]model, 0, len(versions))
+	for key := range versions {
+		models = append(models, key)
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].namespace != models[j].namespace {
+			return models[i].namespace < models[j].namespace
+		}
+		return models[i].name < models[j].name
+	})
+
+	index := types.RegistryIndex{
+		Version:    "1",
+		Generated:  time.Now().UTC().Format(time.RFC3339),
+		Models:     []types.IndexModelEntry{},
+		Namespaces: make(map[string]types.NamespaceInfo),
+	}
+	search := []types.SearchResult{}
+	for _, key := range models {
+		list := types.ModelVersions{Namespace: key.namespace, Name: key.name}
+		for version := range versions[key] {
+			list.Versions = append(list.Versions, version)
+		}
+		spec.SortVersions(list.Versions)
+		list.Latest = spec.LatestVersion(list.Versions)
+		modelDir := filepath.Dir(registryManifestDir(dest, key.namespace, key.name, list.Latest))
+		if err := writeJSONFile(filepath.Join(modelDir, "versions.json"), list); err != nil {
+			return err
+		}
+
+		latest := versions[key][list.Latest]
+		entry := types.IndexModelEntry{
+			Name:          key.name,
+			Namespace:     key.namespace,
+			LatestVersion: list.Latest,
+			Description:   latest.Metadata.Description,
+			Framework:     latest.Spec.Framework.Name,
+			Tags:          latest.Metadata.Tags,
+		}
+		if !latest.Metadata.Updated.IsZero() {
+			entry.Updated = latest.Metadata.Updated.UTC().Format(time.RFC3339)
+		}
+		index.Models = append(index.Models, entry)
+		namespace := index.Namespaces[key.namespace]
+		namespace.ModelCount++
+		index.Namespaces[key.namespace] = namespace
+		search = append(search, types.SearchResult{
+			Name:        key.name,
+			Namespace:   key.namespace,
+			Version:     list.Latest,
+			Description: latest.Metadata.Description,
+			Framework:   latest.Spec.Framework.Name,
+			Tags:        latest.Metadata.Tags,
+		})
+	}
+	index.Statistics = types.Statistics{TotalModels: len(index.Models), TotalNamespaces: len(index.Namespaces)}
+
+	apiDir := filepath.Join(dest, "api", "v1")
+	if err := writeJSONFile(filepath.Join(apiDir, "index.json"), index); err != nil {
+		return err
+	}
+	return writeJSONFile(filepath.Join(apiDir, "search.json"), search)
+}
+
+// writeJSONFile writes v as indented JSON, creating the file's directory
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", filepath.Base(path), err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/registry/builtin"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// writeRegistryVersion publishes a model version with a package into a file-based registry
+func writeRegistryVersion(t *testing.T, registryDir, namespace, name, version, description string) {
+	t.Helper()
+	packageName := safeTempFileName(namespace, name, version)
+	content := []byte("package " + version)
+	if err := os.MkdirAll(filepath.Join(registryDir, "packages"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(registryDir, "packages", packageName), content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+
+	m := &types.Manifest{APIVersion: "v1", Kind: "Model"}
+	m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version = namespace, name, version
+	m.Metadata.Description = description
+	m.Metadata.Tags = []string{"text"}
+	m.Spec.Framework.Name = "pytorch"
+	m.Distribution.Package.URL = "/packages/" + packageName
+	m.Distribution.Package.SHA256 = hex.EncodeToString(sum[:])
+	m.Distribution.Package.Size = int64(len(content))
+	manifestDir := registryManifestDir(registryDir, namespace, name, version)
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveManifest(m, filepath.Join(manifestDir, "manifest.yaml")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryExport(t *testing.T) {
+	originalCfg, originalManager := cfg, newCredentialManager
+	defer func() { cfg, newCredentialManager = originalCfg, originalManager }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	newCredentialManager = func() *credentials.Manager {
+		return credentials.NewManagerWithStores(nil, credentials.NewFileStore(cfg.HomeDir))
+	}
+
+	registryDir := t.TempDir()
+	writeRegistryVersion(t, registryDir, "nlp", "bert", "1.0.0", "Bidirectional encoder")
+	writeRegistryVersion(t, registryDir, "nlp", "bert", "1.10.0", "Bidirectional encoder v1.10")
+	writeRegistryVersion(t, registryDir, "vision", "resnet", "2.0.0", "Residual network")
+
+	// Export a registry directory, and host the export on a static file server
+	static := t.TempDir()
+	manifests, err := exportRegistry(context.Background(), registryDir, static)
+	if err != nil {
+		t.Fatalf("exportRegistry() error = %v", err)
+	}
+	if len(manifests) != 3 {
+		t.Fatalf("exported %d versions, want 3", len(manifests))
+	}
+	if err := writeStaticIndexes(static, manifests); err != nil {
+		t.Fatalf("writeStaticIndexes() error = %v", err)
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(static)))
+	defer server.Close()
+
+	ctx := context.Background()
+	adapter := builtin.NewLocalRegistryAdapter(server.URL, nil)
+	m, err := adapter.GetManifest(ctx, "nlp", "bert", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest(latest) error = %v", err)
+	}
+	if m.Metadata.Version != "1.10.0" {
+		t.Errorf("latest = %s, want 1.10.0", m.Metadata.Version)
+	}
+	if err := adapter.DownloadPackage(ctx, m, filepath.Join(t.TempDir(), "bert.axon"), nil); err != nil {
+		t.Errorf("DownloadPackage() error = %v", err)
+	}
+	results, err := adapter.Search(ctx, "residual")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Name != "resnet" || results[0].Version != "2.0.0" {
+		t.Errorf("Search(residual) = %+v", results)
+	}
+
+	// A registry server, here the static export itself, can be exported over HTTP
+	mirror := t.TempDir()
+	manifests, err = exportRegistry(ctx, server.URL, mirror)
+	if err != nil {
+		t.Fatalf("exportRegistry(%s) error = %v", server.URL, err)
+	}
+	if len(manifests) != 3 {
+		t.Fatalf("exported %d versions over HTTP, want 3", len(manifests))
+	}
+	exported, err := loadManifest(filepath.Join(registryManifestDir(mirror, "nlp", "bert", "1.0.0"), "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "/packages/" + safeTempFileName("nlp", "bert", "1.0.0"); exported.Distribution.Package.URL != want {
+		t.Errorf("exported package URL = %q, want %q", exported.Distribution.Package.URL, want)
+	}
+	if !pathExists(filepath.Join(mirror, "packages", safeTempFileName("vision", "resnet", "2.0.0"))) {
+		t.Error("package was not downloaded")
+	}
+
+	if _, err := exportRegistry(ctx, t.TempDir(), t.TempDir()); err == nil {
+		t.Error("exportRegistry() of an empty directory succeeded")
+	}
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/journal"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// Steps of an install recorded in the journal. Only stepInstalled decides anything: an
+// install interrupted before it is rolled back, one interrupted after it is complete.
+const (
+	stepDownloaded = "downloaded"
+	stepCached     = "cached"
+	stepExtracted  = "extracted"
+	stepInstalled  = "installed"
+)
+
+// journalDir returns where the tenant's running installs and uninstalls are journaled
+func journalDir() string {
+	return filepath.Join(cfg.TenantHomeDir(), journal.DirName)
+}
+
+// beginJournal journals an operation. Failing to write the journal never fails the
+// operation: it runs unjournaled (nil entry).
+func beginJournal(operation, modelID, path string) *journal.Entry {
+	e, err := journal.Begin(journalDir(), operation, modelID, path)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to journal %s of %s: %v\n", operation, modelID, err)
+		return nil
+	}
+	return e
+}
+
+// journalTrack records files an operation is about to create
+func journalTrack(e *journal.Entry, paths ...string) {
+	if err := e.Track(paths...); err != nil {
+		fmt.Printf("⚠️  Failed to update journal: %v\n", err)
+	}
+}
+
+// journalStep records that a step of an operation completed
+func journalStep(e *journal.Entry, step string) {
+	if err := e.Step(step); err != nil {
+		fmt.Printf("⚠️  Failed to update journal: %v\n", err)
+	}
+}
+
+// endInstallJournal closes the journal entry of an install, rolling back what a failed
+// install created unless the model was already complete (e.g. only registering failed)
+func endInstallJournal(cacheMgr *cache.Manager, e *journal.Entry, installErr error) {
+	if installErr != nil && !e.Done(stepInstalled) {
+		if err := rollbackInstall(cacheMgr, e); err != nil {
+			fmt.Printf("⚠️  Failed to clean up after the failed install: %v\n", err)
+		}
+		return
+	}
+	if err := e.Finish(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+}
+
+// rollbackInstall removes what an unfinished install created
+func rollbackInstall(cacheMgr *cache.Manager, e *journal.Entry) error {
+	if err := e.RemoveArtifacts(); err != nil {
+		return err
+	}
+	if err := cacheMgr.ForgetIntegrity(e.Model); err != nil {
+		return err
+	}
+	return e.Finish()
+}
+
+// forgetUninstalled drops the state that refers to a removed model: its integrity
+// record, its registrations and its decrypted copy. Failures are warnings, since the
+// model itself is gone.
+func forgetUninstalled(cacheMgr *cache.Manager, ref spec.ModelRef, path string) {
+	if err := cacheMgr.ForgetIntegrity(ref.String()); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to update the integrity index: %v\n", err)
+	}
+	if err := forgetRegistrations(path); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to update registrations: %v\n", err)
+	}
+	if err := removeDecrypted(ref.Namespace, ref.Name, ref.Version); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to remove decrypted copy: %v\n", err)
+	}
+}
+
+// repairEntry finishes or rolls back an interrupted operation, returning what it did:
+// incomplete installs are rolled back, complete installs and uninstalls are finished
+func repairEntry(cacheMgr *cache.Manager, e *journal.Entry, dryRun bool) (string, error) {
+	switch {
+	case e.Operation == journal.OpInstall && !e.Done(stepInstalled):
+		if dryRun {
+			return "would roll back interrupted install of " + e.Model, nil
+		}
+		return "rolled back interrupted install of " + e.Model, rollbackInstall(cacheMgr, e)
+	case e.Operation == journal.OpInstall:
+		if dryRun {
+			return "would finish interrupted install of " + e.Model, nil
+		}
+		return "finished interrupted install of " + e.Model, e.Finish()
+	case e.Operation == journal.OpUninstall:
+		if dryRun {
+			return "would finish interrupted uninstall of " + e.Model, nil
+		}
+		if err := os.RemoveAll(e.Path); err != nil {
+			return "", fmt.Errorf("failed to remove %s: %w", e.Path, err)
+		}
+		if ref, err := spec.Parse(e.Model); err == nil {
+			forgetUninstalled(cacheMgr, ref.Ref(), e.Path)
+		}
+		return "finished interrupted uninstall of " + e.Model, e.Finish()
+	}
+	return "", fmt.Errorf("unknown operation %q for %s", e.Operation, e.Model)
+}
+
+// repairInterrupted finishes or rolls back the journaled operations whose process is
+// gone (every one with force), printing what it did to out. It returns the number of
+// operations repaired.
+func repairInterrupted(cacheMgr *cache.Manager, out io.Writer, force, dryRun bool) (int, error) {
+	entries, err := journal.List(journalDir())
+	if err != nil {
+		return 0, err
+	}
+	repaired := 0
+	for _, e := range entries {
+		if !force && !e.Interrupted() {
+			continue
+		}
+		action, err := repairEntry(cacheMgr, e, dryRun)
+		if err != nil {
+			return repaired, fmt.Errorf("failed to repair %s of %s: %w", e.Operation, e.Model, err)
+		}
+		_, _ = fmt.Fprintf(out, "🔧 %s%s\n", strings.ToUpper(action[:1]), action[1:])
+		repaired++
+	}
+	return repaired, nil
+}
+
+// repairBackups deals with the copies 'axon verify --all --repair' moves aside while it
+// reinstalls a model: a copy whose model is gone is restored, any other one removed
+func repairBackups(cacheDir string, dryRun bool) (int, error) {
+	var backups []string
+	err := filepath.Walk(cacheDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() && strings.HasSuffix(path, repairBackupSuffix) && pathExists(filepath.Join(path, "manifest.yaml")) {
+			backups = append(backups, path)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan cache: %w", err)
+	}
+
+	for _, backup := range backups {
+		original := strings.TrimSuffix(backup, repairBackupSuffix)
+		if !pathExists(filepath.Join(original, "manifest.yaml")) {
+			fmt.Printf("🔧 Restoring %s from the copy left by an interrupted repair\n", original)
+			if dryRun {
+				continue
+			}
+			if err := os.RemoveAll(original); err != nil {
+				return 0, fmt.Errorf("failed to remove %s: %w", original, err)
+			}
+			if err := os.Rename(backup, original); err != nil {
+				return 0, fmt.Errorf("failed to restore %s: %w", original, err)
+			}
+			continue
+		}
+		fmt.Printf("🔧 Removing %s left by an interrupted repair\n", backup)
+		if dryRun {
+			continue
+		}
+		if err := os.RemoveAll(backup); err != nil {
+			return 0, fmt.Errorf("failed to remove %s: %w", backup, err)
+		}
+	}
+	return len(backups), nil
+}
+
+// autoRepair rolls back or finishes interrupted operations when Axon starts, so a crashed
+// install doesn't leave a model that looks installed. It reports on stderr, keeping
+// machine-readable output on stdout intact.
+func autoRepair() {
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	if _, err := repairInterrupted(cacheMgr, os.Stderr, false, false); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: %v (run 'axon repair')\n", err)
+	}
+}
+
+func repairCmd() *cobra.Command {
+	var force, dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "repair",
+		Short: "Finish or roll back interrupted operations",
+		Long: `Clean up after installs and uninstalls that were interrupted, e.g. by a crash,
+a kill or a full disk.
+
+Installs and uninstalls are journaled step by step while they run. An install that
+was interrupted before the model was complete is rolled back: its partial download and
+cache directory are removed. An install that was interrupted after that, and an
+uninstall, are finished. Axon also does this automatically when it starts.
+
+'axon repair' also scans the cache for copies left by an interrupted
+'axon verify --all --repair', restoring them if their model is gone.
+
+Operations of running Axon processes are left alone. Use --force to repair them too,
+e.g. after a reboot when the journal's process IDs were reused.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			operations, err := repairInterrupted(cacheMgr, os.Stdout, force, dryRun)
+			if err != nil {
+				return err
+			}
+			backups, err := repairBackups(cfg.ModelCacheDir(), dryRun)
+			if err != nil {
+				return err
+			}
+			if operations+backups == 0 {
+				fmt.Println("✓ Nothing to repair")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Also repair operations whose process still seems to be running")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be repaired without changing anything")
+	return cmd
+}
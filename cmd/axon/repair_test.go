@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/journal"
+)
+
+func TestRepairInterrupted(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = filepath.Join(cfg.HomeDir, "cache")
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+
+	// An install that died while extracting: partial download and cache directory
+	partial := cacheVerifiableModel(t, cacheMgr, "partial", true)
+	tmpFile := filepath.Join(t.TempDir(), "hf-partial-latest.axon")
+	if err := os.WriteFile(tmpFile, []byte("partial package"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	install := beginJournal(journal.OpInstall, "hf/partial@latest", partial)
+	journalTrack(install, tmpFile, partial)
+	journalStep(install, stepDownloaded)
+
+	// An install that completed but didn't get to finish its entry
+	complete := cacheVerifiableModel(t, cacheMgr, "complete", true)
+	finished := beginJournal(journal.OpInstall, "hf/complete@latest", complete)
+	journalTrack(finished, complete)
+	journalStep(finished, stepInstalled)
+
+	// An uninstall that died halfway through removing the model
+	removing := cacheVerifiableModel(t, cacheMgr, "removing", true)
+	beginJournal(journal.OpUninstall, "hf/removing@latest", removing)
+
+	// Entries of this (running) process are left alone without --force
+	var out bytes.Buffer
+	if n, err := repairInterrupted(cacheMgr, &out, false, false); err != nil || n != 0 {
+		t.Fatalf("repairInterrupted() = %d, %v, want nothing repaired for a running process", n, err)
+	}
+
+	if n, err := repairInterrupted(cacheMgr, &out, true, true); err != nil || n != 3 {
+		t.Fatalf("repairInterrupted(dry run) = %d, %v", n, err)
+	}
+	if !pathExists(partial) || !pathExists(removing) {
+		t.Fatal("dry run changed the cache")
+	}
+
+	out.Reset()
+	if n, err := repairInterrupted(cacheMgr, &out, true, false); err != nil || n != 3 {
+		t.Fatalf("repairInterrupted() = %d, %v\n%s", n, err, out.String())
+	}
+	for _, want := range []string{"Rolled back interrupted install of hf/partial@latest", "Finished interrupted install of hf/complete@latest", "Finished interrupted uninstall of hf/removing@latest"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	for _, path := range []string{partial, tmpFile, removing} {
+		if pathExists(path) {
+			t.Errorf("%s not removed", path)
+		}
+	}
+	if !pathExists(filepath.Join(complete, "model.safetensors")) {
+		t.Error("completed install was rolled back")
+	}
+	index, err := cacheMgr.LoadIntegrityIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, model := range []string{"hf/partial@latest", "hf/removing@latest"} {
+		if index.Lookup(model) != nil {
+			t.Errorf("%s still in the integrity index", model)
+		}
+	}
+	if entries, _ := journal.List(journalDir()); len(entries) != 0 {
+		t.Errorf("journal not emptied: %+v", entries)
+	}
+}
+
+func TestRepairBackups(t *testing.T) {
+	cacheDir := t.TempDir()
+	cacheMgr := cache.NewManager(cacheDir)
+	kept := cacheVerifiableModel(t, cacheMgr, "kept", false)
+	lost := cacheVerifiableModel(t, cacheMgr, "lost", false)
+
+	// A backup next to a reinstalled model is stale; one whose model is gone is restored
+	if err := os.MkdirAll(kept+repairBackupSuffix, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(kept+repairBackupSuffix, "manifest.yaml"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(lost, lost+repairBackupSuffix); err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := repairBackups(cacheDir, false)
+	if err != nil || n != 2 {
+		t.Fatalf("repairBackups() = %d, %v", n, err)
+	}
+	if pathExists(kept+repairBackupSuffix) || pathExists(lost+repairBackupSuffix) {
+		t.Error("backups left behind")
+	}
+	if !pathExists(filepath.Join(lost, "model.safetensors")) {
+		t.Error("model not restored from its backup")
+	}
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+// Restore outcomes for a registration
+const (
+	restoreStatusRestored = "restored"
+	restoreStatusFailed   = "failed"
+	restoreStatusSkipped  = "skipped"
+)
+
+// registrationsPath returns the location of the persisted Core registrations
+func registrationsPath() string {
+	return filepath.Join(cfg.TenantHomeDir(), mlos.RegistrationsFileName)
+}
+
+// saveRegistration records a successful registration with Core
+func saveRegistration(reg mlos.Registration) error {
+	regs, err := mlos.LoadRegistrations(registrationsPath())
+	if err != nil {
+		return err
+	}
+	regs.Record(reg)
+	return regs.Save()
+}
+
+// forgetRegistrations drops recorded registrations of the model at path, e.g. after it is
+// uninstalled, so restoring doesn't try to register a model that no longer exists
+func forgetRegistrations(path string) error {
+	regs, err := mlos.LoadRegistrations(registrationsPath())
+	if err != nil {
+		return err
+	}
+	var removed bool
+	for _, reg := range append([]mlos.Registration(nil), regs.Models...) {
+		if reg.Path == path {
+			removed = regs.Remove(reg.ModelID) || removed
+		}
+	}
+	if !removed {
+		return nil
+	}
+	return regs.Save()
+}
+
+// restoreResult is the outcome of restoring one registration, or of registering one
+// model with 'axon register --all'
+type restoreResult struct {
+	ModelID string
+	Status  string
+	Error   string
+}
+
+// modelRegistrar registers a model with Core; replaced in tests
+var modelRegistrar = registerModel
+
+// restoreRegistrations registers ordered with Core and checks each one is then reported by
+// Core with the recorded digest. Models whose dependencies failed are skipped.
+func restoreRegistrations(ctx context.Context, client *mlos.Client, ordered []mlos.Registration) []restoreResult {
+	results := make([]restoreResult, 0, len(ordered))
+	var failed []string
+
+	for _, reg := range ordered {
+		result := restoreResult{ModelID: reg.ModelID, Status: restoreStatusRestored}
+
+		var failedDeps []string
+		for _, id := range failed {
+			if reg.DependsOnModel(id) {
+				failedDeps = append(failedDeps, id)
+			}
+		}
+
+		var err error
+		switch {
+		case len(failedDeps) > 0:
+			result.Status = restoreStatusSkipped
+			result.Error = "dependency failed: " + strings.Join(failedDeps, ", ")
+		case !pathExists(reg.Path):
+			err = fmt.Errorf("model is no longer at %s", reg.Path)
+		default:
+			err = modelRegistrar(ctx, reg.ModelID, reg.Convert, false)
+			if err == nil {
+				err = checkRestoredModel(ctx, client, reg)
+			}
+		}
+
+		if err != nil {
+			result.Status = restoreStatusFailed
+			result.Error = err.Error()
+		}
+		if result.Status != restoreStatusRestored {
+			failed = append(failed, reg.ModelID)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// checkRestoredModel is the post-registration health check: Core must report the model
+// with the digest that was registered before
+func checkRestoredModel(ctx context.Context, client *mlos.Client, reg mlos.Registration) error {
+	registered, err := client.GetModel(ctx, coreModelID(reg.ModelID))
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	if registered == nil {
+		return fmt.Errorf("health check failed: MLOS Core does not report the model after registration")
+	}
+	if reg.Digest != "" && registered.Digest != "" && registered.Digest != reg.Digest {
+		return fmt.Errorf("health check failed: MLOS Core reports digest %s, expected %s", registered.Digest, reg.Digest)
+	}
+	return nil
+}
+
+// pathExists reports whether path exists
+func pathExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// systemdUnit returns a oneshot systemd unit that restores registrations at boot, for
+// the given tenant if one is set
+func systemdUnit(axonPath, tenant string) string {
+	command := axonPath
+	if tenant != "" {
+		command += " --tenant " + tenant
+	}
+	return fmt.Sprintf(`[Unit]
+Description=Restore Axon model registrations with MLOS Core
+After=network-online.target mlos-core.service
+Wants=network-online.target
+
+[Service]
+Type=oneshot
+ExecStart=%s restore-registrations --wait 5m
+RemainAfterExit=yes
+
+[Install]
+WantedBy=multi-user.target
+`, command)
+}
+
+func restoreRegistrationsCmd() *cobra.Command {
+	var wait time.Duration
+	var dryRun, printUnit bool
+
+	cmd := &cobra.Command{
+		Use:   "restore-registrations",
+		Short: "Re-register models with MLOS Core after a restart",
+		Long: `Register every model that was registered with MLOS Core again, e.g. after a host reboot.
+
+Axon remembers successful registrations in ~/.axon/registrations.json (or
+~/.axon/tenants/<tenant>/registrations.json with --tenant). Models are
+restored in dependency order; a model whose dependency fails is skipped. After each
+registration Core is asked for the model to confirm it is loaded with the same digest.
+
+For boot-time use, --wait polls MLOS Core until it is healthy before restoring, and
+--print-unit prints a systemd unit that runs this command at startup.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if printUnit {
+				axonPath, err := os.Executable()
+				if err != nil {
+					axonPath = "/usr/local/bin/axon"
+				}
+				fmt.Print(systemdUnit(axonPath, cfg.Tenant))
+				return nil
+			}
+
+			regs, err := mlos.LoadRegistrations(registrationsPath())
+			if err != nil {
+				return err
+			}
+			if len(regs.Models) == 0 {
+				fmt.Println("No registrations to restore")
+				return nil
+			}
+			ordered, err := regs.InDependencyOrder()
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				fmt.Printf("🔍 Dry run: would restore %d registration(s) in this order:\n", len(ordered))
+				for i, reg := range ordered {
+					fmt.Printf("  %d. %s\n", i+1, reg.ModelID)
+				}
+				return nil
+			}
+
+			client := mlos.NewClient(mlos.EndpointFromEnv())
+			if wait > 0 {
+				fmt.Printf("⏳ Waiting up to %s for MLOS Core at %s...\n", wait, client.Endpoint())
+				waitCtx, cancel := context.WithTimeout(cmd.Context(), wait)
+				err := client.WaitHealthy(waitCtx, 2*time.Second)
+				cancel()
+				if err != nil {
+					return err
+				}
+			}
+
+			results := restoreRegistrations(cmd.Context(), client, ordered)
+
+			var failures int
+			fmt.Printf("\n📋 Restore summary:\n")
+			for _, r := range results {
+				icon := "✓"
+				if r.Status != restoreStatusRestored {
+					icon = "✗"
+					failures++
+				}
+				fmt.Printf("  %s %-8s %s", icon, r.Status, r.ModelID)
+				if r.Error != "" {
+					fmt.Printf("  (%s)", strings.SplitN(r.Error, "\n", 2)[0])
+				}
+				fmt.Println()
+			}
+
+			if failures > 0 {
+				return fmt.Errorf("%d of %d registrations could not be restored", failures, len(results))
+			}
+			fmt.Printf("✅ Restored %d registration(s)\n", len(results))
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&wait, "wait", 0, "Wait up to this long for MLOS Core to become healthy (e.g. 5m)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the restore order without registering")
+	cmd.Flags().BoolVar(&printUnit, "print-unit", false, "Print a systemd unit that restores registrations at boot")
+
+	return cmd
+}
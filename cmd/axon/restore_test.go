@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+func TestRestoreRegistrations(t *testing.T) {
+	original, originalCfg := modelRegistrar, cfg
+	defer func() { modelRegistrar, cfg = original, originalCfg }()
+	cfg = config.DefaultConfig()
+
+	var registered []string
+	modelRegistrar = func(ctx context.Context, modelSpec string, convert, force bool) error {
+		if force {
+			t.Errorf("restore must not force registration of %s", modelSpec)
+		}
+		if strings.Contains(modelSpec, "broken") {
+			return errors.New("unsupported execution format")
+		}
+		registered = append(registered, modelSpec)
+		return nil
+	}
+
+	// Core reports every model except hf/unhealthy, and hf/stale with another digest
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.EscapedPath(), "unhealthy"):
+			http.NotFound(w, r)
+		case strings.Contains(r.URL.EscapedPath(), "stale"):
+			_, _ = w.Write([]byte(`{"digest": "sha256:other"}`))
+		default:
+			_, _ = w.Write([]byte(`{"digest": "sha256:abc"}`))
+		}
+	}))
+	defer core.Close()
+
+	dir := t.TempDir()
+	ordered := []mlos.Registration{
+		{ModelID: "hf/encoder@latest", Path: dir, Digest: "sha256:abc"},
+		{ModelID: "hf/broken@latest", Path: dir},
+		{ModelID: "hf/pipeline@latest", Path: dir, DependsOn: []string{"hf/broken"}},
+		{ModelID: "hf/unhealthy@latest", Path: dir},
+		{ModelID: "hf/stale@latest", Path: dir, Digest: "sha256:abc"},
+		{ModelID: "hf/removed@latest", Path: dir + "/missing"},
+	}
+
+	results := restoreRegistrations(context.Background(), mlos.NewClient(core.URL), ordered)
+
+	want := []string{restoreStatusRestored, restoreStatusFailed, restoreStatusSkipped, restoreStatusFailed, restoreStatusFailed, restoreStatusFailed}
+	for i, r := range results {
+		if r.Status != want[i] {
+			t.Errorf("%s: status = %s (%s), want %s", r.ModelID, r.Status, r.Error, want[i])
+		}
+	}
+	if len(registered) != 3 {
+		t.Errorf("registered %v, want encoder, unhealthy and stale (not skipped or missing models)", registered)
+	}
+}
+
+func TestForgetRegistrations(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+
+	for _, reg := range []mlos.Registration{
+		{ModelID: "hf/bert@latest", Path: "/cache/hf/bert/latest"},
+		{ModelID: "hf/gpt2@latest", Path: "/cache/hf/gpt2/latest"},
+	} {
+		if err := saveRegistration(reg); err != nil {
+			t.Fatalf("saveRegistration() error = %v", err)
+		}
+	}
+
+	if err := forgetRegistrations("/cache/hf/bert/latest"); err != nil {
+		t.Fatalf("forgetRegistrations() error = %v", err)
+	}
+
+	regs, err := mlos.LoadRegistrations(registrationsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regs.Models) != 1 || regs.Models[0].ModelID != "hf/gpt2@latest" {
+		t.Errorf("registrations = %+v, want only hf/gpt2@latest", regs.Models)
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/credentials"
+	"github.com/mlOS-foundation/axon/internal/daemon"
+	"github.com/mlOS-foundation/axon/internal/telemetry"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// defaultServeAddr is where 'axon serve' listens without --addr or --socket
+const defaultServeAddr = "127.0.0.1:7878"
+
+// serveTokenEnv optionally holds the bearer token API clients must present
+const serveTokenEnv = "AXON_API_TOKEN"
+
+// serveShutdownTimeout bounds how long in-flight requests may take on shutdown
+const serveShutdownTimeout = 10 * time.Second
+
+func serveCmd() *cobra.Command {
+	var addr, socket string
+	var maxJobs int
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the Axon API locally",
+		Long: `Run Axon as a daemon serving its model operations over a local HTTP API, so MLOS
+Core, IDE plugins and the web UI can drive Axon without shelling out to the CLI.
+
+The API listens on ` + defaultServeAddr + ` by default, or on a Unix socket with --socket
+(readable and writable by the current user only). Endpoints, all JSON:
+
+  GET  /v1/health                 Axon version
+  GET  /v1/models                 Installed models, with their MLOS Core registration
+  GET  /v1/models/<ns>/<name>[@<version>]
+                                  An installed model with its manifest
+  POST /v1/install   {"model": "hf/bert-base-uncased", "register": false,
+                      "priority": "normal"}
+                                  Queue an install job (202, Location: /v1/jobs/<id>)
+  POST /v1/verify    {"model": "...", "attestation": false}
+  POST /v1/register  {"model": "...", "convert": false, "force": false}
+  GET  /v1/jobs                   Every job
+  GET  /v1/jobs/<id>              A job's status (queued, running, succeeded, failed),
+                                  error and downloaded_bytes/total_bytes for progress
+                                  polling
+  GET  /v1/queue                  Running jobs, then queued jobs in the order they start
+                                  (what 'axon queue' shows)
+  GET  /metrics                   Prometheus metrics: download bytes and durations,
+                                  install durations, conversion results, cache hits
+
+POST bodies must be sent as application/json. Requests carrying an Origin header, and
+requests to a loopback listener whose Host isn't a loopback name, are refused so web
+pages can't drive the API. With $` + serveTokenEnv + ` set, every request but the health
+check must carry "Authorization: Bearer <token>".
+
+Installs use the defaults of 'axon install' and the config. Installing a model that is
+already queued or being installed returns that job. Jobs have a priority class:
+urgent (MLOS Core installing a model a live workload waits for) starts at once and
+preempts background jobs, which are requeued and restart their download; normal (the
+default) and background jobs share --max-jobs slots, normal first, and background jobs
+wait while urgent ones run. An urgent request for a queued or running job raises it.
+Jobs are kept in memory until the daemon stops; stopping it (SIGINT or SIGTERM)
+cancels running jobs. With ` + telemetry.EndpointEnv + ` set, every job is traced to that
+OTLP/HTTP collector.
+
+Example:
+  axon serve --socket ~/.axon/axon.sock
+  curl --unix-socket ~/.axon/axon.sock -H 'Content-Type: application/json' \
+    -d '{"model": "hf/bert-base-uncased"}' http://axon/v1/install`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			listener, err := serveListener(addr, socket)
+			if err != nil {
+				return err
+			}
+			return serve(ctx, listener, maxJobs)
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", defaultServeAddr, "TCP address to listen on")
+	cmd.Flags().StringVar(&socket, "socket", "", "Listen on this Unix socket instead of TCP")
+	cmd.Flags().IntVar(&maxJobs, "max-jobs", daemon.DefaultMaxRunning, "Normal and background jobs run at once (urgent jobs don't wait)")
+	return cmd
+}
+
+// serveListener listens on the Unix socket if one is given, else on the TCP address
+func serveListener(addr, socket string) (net.Listener, error) {
+	if socket != "" {
+		// A socket left behind by a daemon that didn't shut down cleanly
+		if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			_ = os.Remove(socket)
+		}
+		if err := os.MkdirAll(filepath.Dir(socket), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create socket directory: %w", err)
+		}
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to listen on %s: %w", socket, err)
+		}
+		if err := os.Chmod(socket, 0600); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+		}
+		return listener, nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --addr %q: %w", addr, err)
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) && os.Getenv(serveTokenEnv) == "" {
+		fmt.Printf("⚠️  Listening on %s: the API is reachable from other hosts; set %s to require a token\n", addr, serveTokenEnv)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return listener, nil
+}
+
+// serve serves the API on listener until ctx is done, running up to maxJobs normal and
+// background jobs at once, then shuts down and waits for the (cancelled) jobs to finish
+func serve(ctx context.Context, listener net.Listener, maxJobs int) error {
+	server := daemon.NewServer(ctx, daemonBackend{}, version)
+	server.Jobs().SetMaxRunning(maxJobs)
+	if token := os.Getenv(serveTokenEnv); token != "" {
+		credentials.RegisterSecret(token)
+		server.RequireToken(token)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/", server.Handler())
+	mux.Handle("GET /metrics", telemetry.Default.Handler())
+	httpServer := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	fmt.Printf("🚀 Axon API listening on %s\n", listener.Addr())
+	errs := make(chan error, 1)
+	go func() { errs <- httpServer.Serve(listener) }()
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("axon API stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	fmt.Printf("\n🛑 Shutting down...\n")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	err := httpServer.Shutdown(shutdownCtx)
+	server.Jobs().Wait()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to shut down: %w", err)
+	}
+	return nil
+}
+
+// daemonBackend performs the API's operations with the CLI's implementations
+type daemonBackend struct{}
+
+// Install installs a model with the defaults of 'axon install'
+func (daemonBackend) Install(ctx context.Context, req daemon.InstallRequest, progress daemon.ProgressFunc) error {
+	opts := defaultInstallOptions()
+	opts.register = req.Register || cfg.Core.AutoRegister
+	opts.progress = progress
+	return modelInstaller(ctx, req.Model, opts)
+}
+
+// List returns the installed models
+func (daemonBackend) List() ([]daemon.Model, error) {
+	models, err := cache.NewManager(cfg.ModelCacheDir()).ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	result := make([]daemon.Model, 0, len(models))
+	for _, m := range models {
+		result = append(result, daemonModel(m))
+	}
+	return result, nil
+}
+
+// Info returns an installed model with its manifest
+func (daemonBackend) Info(modelSpec string) (*daemon.Model, error) {
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return nil, err
+	}
+	cached, err := findCachedModel(cache.NewManager(cfg.ModelCacheDir()), ref.Namespace, ref.Name, ref.Version)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", daemon.ErrNotFound, err)
+	}
+	m, err := loadManifest(filepath.Join(cached.Path, "manifest.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	model := daemonModel(*cached)
+	model.Manifest = m
+	return &model, nil
+}
+
+// Verify checks an installed model as 'axon verify' does
+func (daemonBackend) Verify(ctx context.Context, req daemon.VerifyRequest) error {
+	return verifyModel(ctx, req.Model, req.Attestation)
+}
+
+// Register registers a model with MLOS Core as 'axon register' does
+func (daemonBackend) Register(ctx context.Context, req daemon.RegisterRequest) error {
+	// Registrations from concurrent requests and install jobs update the same file
+	registerInstallMu.Lock()
+	defer registerInstallMu.Unlock()
+	return modelRegistrar(ctx, req.Model, req.Convert, req.Force)
+}
+
+// daemonModel describes a cached model in the API
+func daemonModel(m cache.CachedModel) daemon.Model {
+	return daemon.Model{
+		ID:           fmt.Sprintf("%s/%s@%s", m.Namespace, m.Name, m.Version),
+		Namespace:    m.Namespace,
+		Name:         m.Name,
+		Version:      m.Version,
+		Path:         m.Path,
+		Registration: m.Registration,
+	}
+}
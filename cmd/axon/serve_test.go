@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/daemon"
+)
+
+func TestServe_UnixSocket(t *testing.T) {
+	originalCfg, originalInstaller := cfg, modelInstaller
+	defer func() { cfg, modelInstaller = originalCfg, originalInstaller }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	installed := make(chan installOptions, 1)
+	modelInstaller = func(ctx context.Context, modelSpec string, opts installOptions) error {
+		opts.progress(10, 20)
+		installed <- opts
+		return nil
+	}
+
+	// Socket paths are limited to about 100 bytes, so don't nest them in the test's temp dir
+	dir, err := os.MkdirTemp("", "axon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	socket := filepath.Join(dir, "axon.sock")
+	listener, err := serveListener("", socket)
+	if err != nil {
+		t.Fatalf("serveListener() error = %v", err)
+	}
+	if info, err := os.Stat(socket); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("socket = %v, %v; want mode 0600", info, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, listener, 0) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Post("http://axon/v1/install", "application/json", strings.NewReader(`{"model": "hf/bert-base-uncased", "register": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job daemon.Job
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || job.Model != "hf/bert-base-uncased" {
+		t.Errorf("install = %d %+v", resp.StatusCode, job)
+	}
+	if opts := <-installed; !opts.register || opts.format != "auto" {
+		t.Errorf("install options = %+v, want the install defaults with register", opts)
+	}
+
+	resp, err = client.Get("http://axon/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if !strings.Contains(string(metrics), "# TYPE axon_install_duration_seconds histogram") {
+		t.Errorf("GET /metrics = %d %s, want the Prometheus metrics", resp.StatusCode, metrics)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve() error = %v", err)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	originalCfg, originalInstaller := cfg, modelInstaller
+	defer func() { cfg, modelInstaller = originalCfg, originalInstaller }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	release := make(chan struct{})
+	modelInstaller = func(ctx context.Context, modelSpec string, opts installOptions) error {
+		opts.progress(256, 1024)
+		select {
+		case <-release:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	listener, err := serveListener("127.0.0.1:0", "")
+	if err != nil {
+		t.Fatalf("serveListener() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- serve(ctx, listener, 1) }()
+
+	for _, body := range []string{`{"model": "hf/prefetch", "priority": "background"}`, `{"model": "hf/urgent", "priority": "urgent"}`} {
+		resp, err := http.Post("http://"+addr+"/v1/install", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusAccepted {
+			t.Fatalf("install %s = %d, want 202", body, resp.StatusCode)
+		}
+	}
+
+	// The urgent install preempts the prefetch, which goes back to the queue
+	var jobs []daemon.Job
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if jobs, err = fetchQueue(context.Background(), addr, ""); err != nil {
+			t.Fatalf("fetchQueue() error = %v", err)
+		}
+		if len(jobs) == 2 && jobs[1].Status == daemon.JobQueued {
+			break
+		}
+	}
+	if len(jobs) != 2 || jobs[0].Model != "hf/urgent" || jobs[0].Status != daemon.JobRunning ||
+		jobs[1].Model != "hf/prefetch" || jobs[1].Preemptions != 1 {
+		t.Errorf("queue = %+v, want the urgent install running and the prefetch requeued", jobs)
+	}
+
+	close(release)
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("serve() error = %v", err)
+	}
+}
@@ -0,0 +1,449 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/signing"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// checkPackageSignature verifies a downloaded package's signature against the trusted
+// keys. Unsigned packages are accepted unless security.require_signatures is set.
+func checkPackageSignature(security config.SecurityConfig, m *types.Manifest, packagePath string) error {
+	keyID, err := verifyManifestSignature(security, m)
+	if err != nil || keyID == "" {
+		return err
+	}
+	if err := signing.CheckPackage(m, packagePath); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Signature verified (key %s)\n", keyID)
+	return nil
+}
+
+// verifyManifestSignature checks the manifest's signatures and returns the ID of the
+// trusted key that signed it, or "" when the package is accepted without a check
+func verifyManifestSignature(security config.SecurityConfig, m *types.Manifest) (string, error) {
+	if len(m.Distribution.Package.Signatures) == 0 {
+		if security.RequireSignatures {
+			return "", fmt.Errorf("%w (security.require_signatures is set)", signing.ErrUnsigned)
+		}
+		return "", nil
+	}
+
+	trusted, err := trustedKeys(security)
+	if err != nil {
+		return "", err
+	}
+	if len(trusted) == 0 {
+		if security.RequireSignatures {
+			return "", fmt.Errorf("package is signed but no keys are trusted (security.trusted_keys or 'axon keys add')")
+		}
+		fmt.Printf("⚠️  Package is signed but no keys are trusted; signature not checked\n")
+		return "", nil
+	}
+	return signing.Verify(m, trusted)
+}
+
+// trustedKeys returns the keys whose signatures are accepted: security.trusted_keys and
+// the trusted keys in the key store
+func trustedKeys(security config.SecurityConfig) ([]ed25519.PublicKey, error) {
+	trusted, err := signing.ParsePublicKeys(security.TrustedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("invalid security.trusted_keys: %w", err)
+	}
+	if cfg == nil {
+		return trusted, nil
+	}
+	stored, err := keyStore().TrustedKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trusted keys: %w", err)
+	}
+	return append(trusted, stored...), nil
+}
+
+// keyStore returns the key store managed by 'axon keys'
+func keyStore() *signing.KeyStore {
+	return signing.NewKeyStore(cfg.KeysDir())
+}
+
+// signingKeyPath returns the private key a --key flag names: a signing key in the key
+// store by name, or a path. Without a flag it is the configured signing key.
+func signingKeyPath(value string) string {
+	if value == "" {
+		return cfg.SigningKeyPath()
+	}
+	if signing.ValidateKeyName(value) == nil && !pathExists(value) {
+		if path := keyStore().SigningKeyPath(value); pathExists(path) {
+			return path
+		}
+	}
+	return value
+}
+
+// verifyInstalledSignature checks the signature recorded for an installed model. A
+// package rebuilt after ONNX conversion no longer matches the signed digest; its
+// extracted files are covered by the integrity manifest instead.
+func verifyInstalledSignature(modelPath string) error {
+	m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+	if err != nil {
+		return err
+	}
+	packages, _ := filepath.Glob(filepath.Join(modelPath, "*.axon"))
+	if len(packages) == 0 {
+		return verifyModelSignature(m, "")
+	}
+	return verifyModelSignature(m, packages[0])
+}
+
+// verifyModelSignature checks the signature recorded in an installed model's manifest
+// and, if packagePath is set, that the package matches it (see verifyInstalledSignature)
+func verifyModelSignature(m *types.Manifest, packagePath string) error {
+	keyID, err := verifyManifestSignature(cfg.Security, m)
+	if err != nil || keyID == "" {
+		return err
+	}
+
+	if packagePath != "" {
+		err := signing.CheckPackage(m, packagePath)
+		rebuilt := m.Spec.Conversion != nil && m.Spec.Conversion.Status == converter.ConversionStatusConverted
+		switch {
+		case errors.Is(err, signing.ErrDigestMismatch) && rebuilt:
+			fmt.Printf("ℹ️  Package was rebuilt after ONNX conversion; extracted files were checked instead\n")
+		case err != nil:
+			return err
+		}
+	}
+	fmt.Printf("✓ Signature verified (key %s)\n", keyID)
+	return nil
+}
+
+// signModel returns the manifest of a model directory signed with the key at keyPath,
+// with the package digest and size updated to match the package being published
+func signModel(modelPath, keyPath string) (*types.Manifest, *types.PackageSignature, error) {
+	m, err := loadManifest(filepath.Join(modelPath, "manifest.yaml"))
+	if err != nil {
+		return nil, nil, err
+	}
+	signature, err := signManifest(m, modelPath, keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, signature, nil
+}
+
+// signManifest signs m with the key at keyPath for the package in modelPath, updating
+// the package digest and size in m first
+func signManifest(m *types.Manifest, modelPath, keyPath string) (*types.PackageSignature, error) {
+	key, err := signing.LoadPrivateKey(keyPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("no signing key at %s (create one with 'axon keys generate')", keyPath)
+		}
+		return nil, err
+	}
+
+	packagePath, err := modelPackage(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat package: %w", err)
+	}
+	digest, err := utils.ComputeSHA256(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash package: %w", err)
+	}
+	m.Distribution.Package.SHA256 = digest
+	m.Distribution.Package.Size = stat.Size()
+
+	return signing.Sign(m, key)
+}
+
+// modelPackage returns the package of a model directory, which must hold exactly one
+func modelPackage(modelPath string) (string, error) {
+	packages, _ := filepath.Glob(filepath.Join(modelPath, "*.axon"))
+	if len(packages) != 1 {
+		return "", fmt.Errorf("expected one package in %s, found %d", modelPath, len(packages))
+	}
+	return packages[0], nil
+}
+
+func keysCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "keys",
+		Aliases: []string{"key"},
+		Short:   "Manage package signing and verification keys",
+		Long: `Manage the keys in ~/.axon/keys: private keys that sign packages with
+'axon publish --sign', and public keys whose signatures 'axon install' and 'axon verify'
+accept, on top of those in security.trusted_keys.
+
+Share your public keys with 'axon keys export' and trust a teammate's with
+'axon keys import', so a team verifies each other's packages without editing configs.
+
+Examples:
+  axon keys generate release
+  axon publish myteam/bert@1.0.0 --sign --key release
+  axon keys export release --output release.pub
+  axon keys import team-keys.pem
+  axon keys add alice ed25519:MCowBQYDK2VwAyEA...
+  axon keys list`,
+	}
+
+	generate := &cobra.Command{
+		Use:   "generate [name]",
+		Short: "Create a signing key for 'axon publish --sign'",
+		Long: `Create a signing key, named signing unless a name is given. Its public key is
+trusted, so packages signed with it verify on this machine.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := keyStore()
+			name := "signing"
+			if len(args) > 0 {
+				name = args[0]
+			}
+			path, _ := cmd.Flags().GetString("output")
+			if path == "" && len(args) == 0 {
+				path = cfg.SigningKeyPath()
+			}
+
+			var key ed25519.PrivateKey
+			var err error
+			if path == "" || path == store.SigningKeyPath(name) {
+				path = store.SigningKeyPath(name)
+				if key, err = store.Generate(name); err != nil {
+					return err
+				}
+			} else {
+				if key, err = signing.GenerateKey(); err != nil {
+					return err
+				}
+				if err := signing.WritePrivateKey(path, key); err != nil {
+					return err
+				}
+				if err := store.Trust(name, key.Public().(ed25519.PublicKey)); err != nil {
+					return err
+				}
+			}
+			fmt.Printf("✓ Signing key written to %s\n", path)
+			printPublicKey(key)
+			return nil
+		},
+	}
+	generate.Flags().String("output", "", "Private key path (default: security.signing_key or ~/.axon/keys/<name>.pem)")
+
+	show := &cobra.Command{
+		Use:   "show",
+		Short: "Print the public key to add to security.trusted_keys",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("key")
+			key, err := signing.LoadPrivateKey(signingKeyPath(path))
+			if err != nil {
+				return err
+			}
+			printPublicKey(key)
+			return nil
+		},
+	}
+	show.Flags().String("key", "", "Signing key name or private key path (default: security.signing_key or ~/.axon/keys/signing.pem)")
+
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List signing keys and trusted keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys, err := keyStore().List()
+			if err != nil {
+				return err
+			}
+			configured, err := signing.ParsePublicKeys(cfg.Security.TrustedKeys)
+			if err != nil {
+				return fmt.Errorf("invalid security.trusted_keys: %w", err)
+			}
+			if len(keys) == 0 && len(configured) == 0 {
+				fmt.Println("No keys. Create a signing key with 'axon keys generate' or trust one with 'axon keys add'.")
+				return nil
+			}
+
+			fmt.Printf("%-24s %-8s %s\n", "NAME", "TYPE", "KEY ID")
+			for _, key := range keys {
+				fmt.Printf("%-24s %-8s %s\n", key.Name, key.Type, key.ID())
+			}
+			for _, key := range configured {
+				fmt.Printf("%-24s %-8s %s\n", "(security.trusted_keys)", signing.KeyTypeTrusted, signing.KeyID(key))
+			}
+			return nil
+		},
+	}
+
+	add := &cobra.Command{
+		Use:   "add <name> <public-key>",
+		Short: "Trust a public key",
+		Long: `Trust signatures made with a public key: an inline ed25519:<base64> key, as
+'axon keys show' prints, or a PEM public key file.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := signing.ParsePublicKey(args[1])
+			if err != nil {
+				return err
+			}
+			if err := keyStore().Trust(args[0], key); err != nil {
+				return err
+			}
+			fmt.Printf("✓ Trusted key %s (%s)\n", args[0], signing.KeyID(key))
+			return nil
+		},
+	}
+
+	remove := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop trusting a key, or delete a signing key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			store := keyStore()
+			if deleteSigning, _ := cmd.Flags().GetBool("signing-key"); deleteSigning {
+				if err := store.RemoveSigningKey(args[0]); err != nil {
+					return err
+				}
+				fmt.Printf("✓ Deleted signing key %s\n", args[0])
+				return nil
+			}
+			err := store.Untrust(args[0])
+			if errors.Is(err, signing.ErrKeyNotFound) && pathExists(store.SigningKeyPath(args[0])) {
+				return fmt.Errorf("%s is a signing key and isn't trusted; delete it with --signing-key", args[0])
+			}
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✓ No longer trusting key %s\n", args[0])
+			return nil
+		},
+	}
+	remove.Flags().Bool("signing-key", false, "Delete the private signing key with this name (it can't be recovered)")
+
+	export := &cobra.Command{
+		Use:   "export [name...]",
+		Short: "Export public keys for teammates to import",
+		Long: `Write the public keys of the named keys as a PEM keyring, by default those of every
+signing key, for teammates to trust with 'axon keys import'.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keys, err := exportableKeys(keyStore(), args)
+			if err != nil {
+				return err
+			}
+			data, err := signing.EncodeKeyring(keys)
+			if err != nil {
+				return err
+			}
+			output, _ := cmd.Flags().GetString("output")
+			if output == "" {
+				_, err := os.Stdout.Write(data)
+				return err
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				return fmt.Errorf("failed to write keyring: %w", err)
+			}
+			fmt.Printf("✓ Exported %d public keys to %s\n", len(keys), output)
+			return nil
+		},
+	}
+	export.Flags().String("output", "", "File to write the keyring to (default: stdout)")
+
+	importKeys := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Trust the public keys in a keyring or PEM file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read keys: %w", err)
+			}
+			keys, err := signing.ParseKeyring(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", args[0], err)
+			}
+			name, _ := cmd.Flags().GetString("name")
+			if name != "" && len(keys) > 1 {
+				return fmt.Errorf("--name can only be used with a file holding one key, %s has %d", args[0], len(keys))
+			}
+
+			store := keyStore()
+			for _, key := range keys {
+				keyName := key.Name
+				switch {
+				case name != "":
+					keyName = name
+				case keyName == "" && len(keys) == 1:
+					keyName = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+				case keyName == "":
+					return fmt.Errorf("%s has unnamed keys; import them one at a time with --name", args[0])
+				}
+				if err := store.Trust(keyName, key.Key); err != nil {
+					return err
+				}
+				fmt.Printf("✓ Trusted key %s (%s)\n", keyName, signing.KeyID(key.Key))
+			}
+			return nil
+		},
+	}
+	importKeys.Flags().String("name", "", "Name for the key (default: its name in the keyring, or the file name)")
+
+	cmd.AddCommand(generate, show, list, add, remove, export, importKeys)
+	return cmd
+}
+
+// exportableKeys returns the public keys of the named keys in the store, or without
+// names those of every signing key (the configured one if the store has none)
+func exportableKeys(store *signing.KeyStore, names []string) ([]signing.NamedPublicKey, error) {
+	var keys []signing.NamedPublicKey
+	for _, name := range names {
+		key, err := store.Find(name)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, signing.NamedPublicKey{Name: key.Name, Key: key.Public})
+	}
+	if len(names) > 0 {
+		return keys, nil
+	}
+
+	stored, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range stored {
+		if key.Type == signing.KeyTypeSigning {
+			keys = append(keys, signing.NamedPublicKey{Name: key.Name, Key: key.Public})
+		}
+	}
+	if len(keys) == 0 {
+		key, err := signing.LoadPrivateKey(cfg.SigningKeyPath())
+		if err != nil {
+			return nil, fmt.Errorf("no signing keys to export (create one with 'axon keys generate'): %w", err)
+		}
+		keys = append(keys, signing.NamedPublicKey{Name: "signing", Key: key.Public().(ed25519.PublicKey)})
+	}
+	return keys, nil
+}
+
+// printPublicKey prints the public half of a signing key with the config that trusts it
+func printPublicKey(key ed25519.PrivateKey) {
+	public := key.Public().(ed25519.PublicKey)
+	fmt.Printf("🔑 Key ID: %s\n", signing.KeyID(public))
+	fmt.Printf("   Public key: %s\n", signing.EncodePublicKey(public))
+	fmt.Printf("\n   To trust packages signed with this key on another machine, run there:\n")
+	fmt.Printf("     axon keys add <name> %s\n", signing.EncodePublicKey(public))
+	fmt.Printf("   or add to security.trusted_keys in ~/.axon/config.yaml:\n")
+	fmt.Printf("     - %s\n", signing.EncodePublicKey(public))
+}
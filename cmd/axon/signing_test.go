@@ -0,0 +1,214 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/converter"
+	"github.com/mlOS-foundation/axon/internal/signing"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// writeSigningFixture creates a model directory with a package and manifest, and a
+// signing key, returning the model directory, key path and public key
+func writeSigningFixture(t *testing.T) (string, string, ed25519.PublicKey) {
+	t.Helper()
+	dir := t.TempDir()
+	modelDir := filepath.Join(dir, "model")
+	if err := os.MkdirAll(modelDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(modelDir, "nlp-bert-1.0.0.axon"), []byte("package contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &types.Manifest{APIVersion: "v1", Kind: "Model"}
+	m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version = "nlp", "bert", "1.0.0"
+	m.Distribution.Package.SHA256 = "stale"
+	if err := saveManifest(m, filepath.Join(modelDir, "manifest.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "signing.pem")
+	if err := signing.WritePrivateKey(keyPath, key); err != nil {
+		t.Fatal(err)
+	}
+	return modelDir, keyPath, key.Public().(ed25519.PublicKey)
+}
+
+func TestCheckPackageSignature(t *testing.T) {
+	modelDir, keyPath, public := writeSigningFixture(t)
+	signed, _, err := signModel(modelDir, keyPath)
+	if err != nil {
+		t.Fatalf("signModel() error = %v", err)
+	}
+	packagePath := filepath.Join(modelDir, "nlp-bert-1.0.0.axon")
+	other, _ := signing.GenerateKey()
+	trusted := []string{signing.EncodePublicKey(public)}
+	untrusted := []string{signing.EncodePublicKey(other.Public().(ed25519.PublicKey))}
+
+	tests := []struct {
+		name     string
+		security config.SecurityConfig
+		unsigned bool
+		tamper   bool
+		wantErr  error
+		anyErr   bool
+	}{
+		{name: "trusted", security: config.SecurityConfig{TrustedKeys: trusted}},
+		{name: "untrusted", security: config.SecurityConfig{TrustedKeys: untrusted}, wantErr: signing.ErrUntrusted},
+		{name: "tampered package", security: config.SecurityConfig{TrustedKeys: trusted}, tamper: true, wantErr: signing.ErrDigestMismatch},
+		{name: "no trusted keys", security: config.SecurityConfig{}},
+		{name: "no trusted keys but required", security: config.SecurityConfig{RequireSignatures: true}, anyErr: true},
+		{name: "unsigned", security: config.SecurityConfig{TrustedKeys: trusted}, unsigned: true},
+		{name: "unsigned but required", security: config.SecurityConfig{TrustedKeys: trusted, RequireSignatures: true}, unsigned: true, wantErr: signing.ErrUnsigned},
+		{name: "bad trusted key", security: config.SecurityConfig{TrustedKeys: []string{"ed25519:AAAA"}}, anyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := *signed
+			if tt.unsigned {
+				m.Distribution.Package.Signatures = nil
+			}
+			path := packagePath
+			if tt.tamper {
+				path = filepath.Join(t.TempDir(), "tampered.axon")
+				if err := os.WriteFile(path, []byte("tampered contents"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := checkPackageSignature(tt.security, &m, path)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("checkPackageSignature() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Error("checkPackageSignature() succeeded, want error")
+				}
+			case err != nil:
+				t.Errorf("checkPackageSignature() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestSignModel(t *testing.T) {
+	modelDir, keyPath, _ := writeSigningFixture(t)
+	m, signature, err := signModel(modelDir, keyPath)
+	if err != nil {
+		t.Fatalf("signModel() error = %v", err)
+	}
+	if m.Distribution.Package.SHA256 == "stale" || m.Distribution.Package.Size != int64(len("package contents")) {
+		t.Errorf("package digest/size not updated: %+v", m.Distribution.Package)
+	}
+	if len(m.Distribution.Package.Signatures) != 1 || m.Distribution.Package.Signatures[0] != *signature {
+		t.Errorf("signatures = %+v", m.Distribution.Package.Signatures)
+	}
+
+	if _, _, err := signModel(modelDir, filepath.Join(t.TempDir(), "missing.pem")); err == nil {
+		t.Error("signModel() with a missing key succeeded")
+	}
+}
+
+func TestVerifyInstalledSignature(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+
+	modelDir, keyPath, public := writeSigningFixture(t)
+	cfg.Security.TrustedKeys = []string{signing.EncodePublicKey(public)}
+	m, _, err := signModel(modelDir, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifestPath := filepath.Join(modelDir, "manifest.yaml")
+	if err := saveManifest(m, manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyInstalledSignature(modelDir); err != nil {
+		t.Fatalf("verifyInstalledSignature() error = %v", err)
+	}
+
+	// A package modified without conversion fails
+	packagePath := filepath.Join(modelDir, "nlp-bert-1.0.0.axon")
+	if err := os.WriteFile(packagePath, []byte("rebuilt package"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyInstalledSignature(modelDir); !errors.Is(err, signing.ErrDigestMismatch) {
+		t.Errorf("verifyInstalledSignature() on modified package error = %v, want ErrDigestMismatch", err)
+	}
+
+	// A package rebuilt after ONNX conversion is expected to differ
+	m.Spec.Conversion = &types.Conversion{Status: converter.ConversionStatusConverted, Target: "onnx"}
+	if err := saveManifest(m, manifestPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyInstalledSignature(modelDir); err != nil {
+		t.Errorf("verifyInstalledSignature() on converted model error = %v", err)
+	}
+}
+
+func TestKeyStoreTrust(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+
+	// A teammate's exported key, imported into the key store, verifies their packages
+	modelDir, keyPath, public := writeSigningFixture(t)
+	m, _, err := signModel(modelDir, keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Security.RequireSignatures = true
+	if _, err := verifyManifestSignature(cfg.Security, m); err == nil {
+		t.Fatal("verifyManifestSignature() without trusted keys succeeded")
+	}
+	keyring, err := signing.EncodeKeyring([]signing.NamedPublicKey{{Name: "alice", Key: public}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := signing.ParseKeyring(keyring)
+	if err != nil || len(keys) != 1 {
+		t.Fatalf("ParseKeyring() = %v, %v", keys, err)
+	}
+	if err := keyStore().Trust(keys[0].Name, keys[0].Key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := verifyManifestSignature(cfg.Security, m); err != nil {
+		t.Errorf("verifyManifestSignature() with a key store key error = %v", err)
+	}
+
+	// --key takes a key name or a path
+	if _, err := keyStore().Generate("release"); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", cfg.SigningKeyPath()},
+		{"release", keyStore().SigningKeyPath("release")},
+		{keyPath, keyPath},
+		{"missing", "missing"},
+	}
+	for _, tt := range tests {
+		if got := signingKeyPath(tt.value); got != tt.want {
+			t.Errorf("signingKeyPath(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+	exported, err := exportableKeys(keyStore(), nil)
+	if err != nil || len(exported) != 1 || exported[0].Name != "release" {
+		t.Errorf("exportableKeys() = %+v, %v", exported, err)
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/internal/registry"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+const (
+	suggestPrefetch = "prefetch"
+	suggestEvict    = "evict"
+)
+
+// suggestion is a model worth prefetching to, or evicting from, this node
+type suggestion struct {
+	Action string
+	Spec   string
+	Reason string
+	Size   int64             // Cache space an eviction reclaims
+	Model  cache.CachedModel // The cached model to evict
+}
+
+// suggestOptions tunes which models are suggested
+type suggestOptions struct {
+	minRequests int64 // Core requests that make an uncached model worth prefetching
+	top         int   // Most downloaded registry models to keep cached
+}
+
+// cachedVersion reports whether namespace/name is cached; "latest" or no version matches
+// any cached version
+func cachedVersion(cached []cache.CachedModel, namespace, name, version string) bool {
+	for _, m := range cached {
+		if m.Namespace == namespace && m.Name == name && (version == "" || version == "latest" || m.Version == version) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSuggestions suggests prefetching the uncached models Core is asked for and the
+// most downloaded models in the registry index, and evicting the other cached models
+// Core hasn't been asked for. Usage or index may be nil when unavailable; evictions are
+// only suggested with usage statistics.
+func buildSuggestions(usage []mlos.ModelUsage, index *types.RegistryIndex, cached []cache.CachedModel, modelSize func(cache.CachedModel) int64, opts suggestOptions) []suggestion {
+	var suggestions []suggestion
+	suggested := make(map[string]bool)
+	requested := make(map[string]bool)
+
+	sortedUsage := append([]mlos.ModelUsage(nil), usage...)
+	sort.SliceStable(sortedUsage, func(i, j int) bool { return sortedUsage[i].Requests > sortedUsage[j].Requests })
+	for _, u := range sortedUsage {
+		ref, err := spec.Parse(u.ModelID)
+		if err != nil {
+			continue
+		}
+		if u.Requests > 0 {
+			requested[ref.ID()] = true
+		}
+		if u.Requests < opts.minRequests || cachedVersion(cached, ref.Namespace, ref.Name, ref.Version) || suggested[ref.String()] {
+			continue
+		}
+		suggested[ref.String()] = true
+		suggestions = append(suggestions, suggestion{
+			Action: suggestPrefetch,
+			Spec:   ref.String(),
+			Reason: fmt.Sprintf("requested %d time(s) by MLOS Core", u.Requests),
+		})
+	}
+
+	if index != nil && opts.top > 0 {
+		popular := append([]types.IndexModelEntry(nil), index.Models...)
+		sort.SliceStable(popular, func(i, j int) bool { return popular[i].Downloads > popular[j].Downloads })
+		if len(popular) > opts.top {
+			popular = popular[:opts.top]
+		}
+		for _, entry := range popular {
+			requested[entry.Namespace+"/"+entry.Name] = true
+			version := entry.LatestVersion
+			if version == "" {
+				version = "latest"
+			}
+			modelSpec := fmt.Sprintf("%s/%s@%s", entry.Namespace, entry.Name, version)
+			if cachedVersion(cached, entry.Namespace, entry.Name, version) || suggested[modelSpec] {
+				continue
+			}
+			suggested[modelSpec] = true
+			suggestions = append(suggestions, suggestion{
+				Action: suggestPrefetch,
+				Spec:   modelSpec,
+				Reason: fmt.Sprintf("popular in the registry (%d downloads)", entry.Downloads),
+			})
+		}
+	}
+
+	if usage == nil {
+		return suggestions
+	}
+	var evictions []suggestion
+	for _, m := range cached {
+		if requested[m.Namespace+"/"+m.Name] {
+			continue
+		}
+		evictions = append(evictions, suggestion{
+			Action: suggestEvict,
+			Spec:   fmt.Sprintf("%s/%s@%s", m.Namespace, m.Name, m.Version),
+			Reason: "not requested by MLOS Core",
+			Size:   modelSize(m),
+			Model:  m,
+		})
+	}
+	sort.SliceStable(evictions, func(i, j int) bool { return evictions[i].Size > evictions[j].Size })
+	return append(suggestions, evictions...)
+}
+
+// applySuggestion installs a model to prefetch or removes a model to evict
+func applySuggestion(ctx context.Context, cacheMgr *cache.Manager, s suggestion) error {
+	if s.Action == suggestEvict {
+		return removeCachedModel(cacheMgr, s.Model)
+	}
+	return modelInstaller(ctx, s.Spec, defaultInstallOptions())
+}
+
+func suggestCmd() *cobra.Command {
+	var apply bool
+	opts := suggestOptions{}
+
+	cmd := &cobra.Command{
+		Use:   "suggest",
+		Short: "Suggest models to prefetch or evict on this node",
+		Long: `Suggest models to prefetch or evict, based on MLOS Core usage statistics and the
+registry index.
+
+Models Core was asked for that aren't cached, and the most downloaded models in the
+registry index (if a registry is configured), are suggested for prefetching. Cached
+models Core hasn't been asked for are suggested for eviction. Use --apply to install
+and remove them.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			usage, err := mlos.NewClient(mlos.EndpointFromEnv()).ModelUsage(ctx)
+			if err != nil {
+				if !errors.Is(err, mlos.ErrUsageUnsupported) {
+					fmt.Fprintf(os.Stderr, "⚠️  Warning: no MLOS Core usage statistics: %v\n", err)
+				}
+				usage = nil
+			}
+			usage = tenantModelUsage(usage)
+
+			var index *types.RegistryIndex
+			if cfg.Registry.URL != "" {
+				index, err = registry.NewClient(cfg.Registry.URL, cfg.Registry.Mirrors).GetIndex(ctx)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to fetch the registry index: %v\n", err)
+					index = nil
+				}
+			}
+			if usage == nil && index == nil {
+				return fmt.Errorf("no MLOS Core usage statistics or registry index available to base suggestions on")
+			}
+
+			cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+			cached, err := cacheMgr.ListCachedModels()
+			if err != nil {
+				return fmt.Errorf("failed to list models: %w", err)
+			}
+			modelSize := func(m cache.CachedModel) int64 {
+				size, _ := cacheMgr.GetModelSize(m.Namespace, m.Name, m.Version)
+				return size
+			}
+
+			suggestions := buildSuggestions(usage, index, cached, modelSize, opts)
+			if len(suggestions) == 0 {
+				fmt.Println("✓ Nothing to suggest: the cache matches what this node uses")
+				return nil
+			}
+
+			fmt.Println("Suggestions for this node:")
+			for _, s := range suggestions {
+				reason := s.Reason
+				if s.Action == suggestEvict {
+					reason = fmt.Sprintf("%s, %s", reason, formatBytes(s.Size))
+				}
+				fmt.Printf("  %-8s  %-40s  %s\n", s.Action, s.Spec, reason)
+			}
+			if !apply {
+				fmt.Println("\nRun with --apply to prefetch and evict these models")
+				return nil
+			}
+
+			var failures int
+			for _, s := range suggestions {
+				if err := applySuggestion(ctx, cacheMgr, s); err != nil {
+					fmt.Printf("✗ %s %s: %v\n", s.Action, s.Spec, err)
+					failures++
+					continue
+				}
+				if s.Action == suggestEvict {
+					fmt.Printf("✓ Evicted %s\n", s.Spec)
+				} else {
+					fmt.Printf("✓ Prefetched %s\n", s.Spec)
+				}
+			}
+			if failures > 0 {
+				return fmt.Errorf("%d of %d suggestion(s) could not be applied", failures, len(suggestions))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&apply, "apply", false, "Prefetch and evict the suggested models")
+	cmd.Flags().Int64Var(&opts.minRequests, "min-requests", 1, "Core requests that make an uncached model worth prefetching")
+	cmd.Flags().IntVar(&opts.top, "top", 5, "Number of most downloaded registry models to keep cached (0 to ignore the registry)")
+	return cmd
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestBuildSuggestions(t *testing.T) {
+	cached := []cache.CachedModel{
+		{Namespace: "hf", Name: "bert", Version: "latest"},
+		{Namespace: "hf", Name: "gpt2", Version: "latest"},
+		{Namespace: "nlp", Name: "tiny", Version: "1.0.0"},
+	}
+	sizes := map[string]int64{"gpt2": 500, "tiny": 10}
+	modelSize := func(m cache.CachedModel) int64 { return sizes[m.Name] }
+
+	usage := []mlos.ModelUsage{
+		{ModelID: "hf/bert@latest", Requests: 100},
+		{ModelID: "hf/llama@latest", Requests: 3},
+		{ModelID: "hf/t5@latest", Requests: 30},
+		{ModelID: "hf/rare@latest", Requests: 1},
+	}
+	index := &types.RegistryIndex{Models: []types.IndexModelEntry{
+		{Namespace: "nlp", Name: "tiny", LatestVersion: "1.0.0", Downloads: 900},
+		{Namespace: "nlp", Name: "big", LatestVersion: "2.0.0", Downloads: 500},
+		{Namespace: "nlp", Name: "niche", LatestVersion: "1.0.0", Downloads: 5},
+	}}
+
+	tests := []struct {
+		name  string
+		usage []mlos.ModelUsage
+		index *types.RegistryIndex
+		want  []string
+	}{
+		{
+			name:  "usage and index",
+			usage: usage,
+			index: index,
+			want: []string{
+				"prefetch hf/t5@latest", "prefetch hf/llama@latest", "prefetch nlp/big@2.0.0",
+				"evict hf/gpt2@latest",
+			},
+		},
+		{
+			name:  "index only suggests no evictions",
+			index: index,
+			want:  []string{"prefetch nlp/big@2.0.0"},
+		},
+		{
+			name:  "no requests evicts everything",
+			usage: []mlos.ModelUsage{},
+			want:  []string{"evict hf/gpt2@latest", "evict nlp/tiny@1.0.0", "evict hf/bert@latest"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suggestions := buildSuggestions(tt.usage, tt.index, cached, modelSize, suggestOptions{minRequests: 2, top: 2})
+			var got []string
+			for _, s := range suggestions {
+				got = append(got, s.Action+" "+s.Spec)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("buildSuggestions() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("buildSuggestions() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
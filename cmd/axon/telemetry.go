@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/hooks"
+	"github.com/mlOS-foundation/axon/internal/telemetry"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// traceShutdownTimeout bounds how long exiting commands wait to export their traces
+const traceShutdownTimeout = 5 * time.Second
+
+// initTracing exports traces to $AXON_OTEL_ENDPOINT, if set. The returned function
+// exports the spans still pending; commands call it before exiting.
+func initTracing() func() {
+	endpoint := os.Getenv(telemetry.EndpointEnv)
+	if endpoint == "" {
+		return func() {}
+	}
+	tracer := telemetry.NewTracer(endpoint, version, func(err error) {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: %v\n", err)
+	})
+	telemetry.SetTracer(tracer)
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), traceShutdownTimeout)
+		defer cancel()
+		tracer.Shutdown(ctx)
+	}
+}
+
+// conversionFinished records the outcome of converting the model at modelPath and fires
+// the conversion-complete hooks
+func conversionFinished(ctx context.Context, span *telemetry.Span, modelID, modelPath string, m *types.Manifest, err error) {
+	span.End(err)
+	telemetry.Conversions.Inc(telemetry.Result(err))
+	fireHooks(ctx, hooks.EventConversionComplete, modelID, modelPath, m, err)
+}
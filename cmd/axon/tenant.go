@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+// tenantEnv selects the tenant when --tenant isn't given
+const tenantEnv = "AXON_TENANT"
+
+// tenantSeparator separates the tenant from the model in Core model IDs
+const tenantSeparator = mlos.TenantSeparator
+
+// coreModelID returns the ID a model is registered with in Core. Models of a tenant are
+// prefixed with it (team-a:hf/bert@latest), so tenants can register the same model
+// without replacing each other's registrations.
+func coreModelID(modelID string) string {
+	if cfg.Tenant == "" {
+		return modelID
+	}
+	return cfg.Tenant + tenantSeparator + modelID
+}
+
+// tenantModelID returns the model ID without the tenant prefix, and whether the Core
+// model ID belongs to the current tenant
+func tenantModelID(coreID string) (string, bool) {
+	tenant, modelID, found := strings.Cut(coreID, tenantSeparator)
+	if !found {
+		return coreID, cfg.Tenant == ""
+	}
+	return modelID, tenant == cfg.Tenant
+}
+
+// tenantRegisteredModels returns the current tenant's models, with the tenant prefix
+// removed from their IDs
+func tenantRegisteredModels(models []mlos.RegisteredModel) []mlos.RegisteredModel {
+	var own []mlos.RegisteredModel
+	for _, m := range models {
+		if id, ok := tenantModelID(m.ModelID); ok {
+			m.ModelID = id
+			own = append(own, m)
+		}
+	}
+	return own
+}
+
+// tenantModelUsage returns the usage of the current tenant's models, with the tenant
+// prefix removed from their IDs
+func tenantModelUsage(usage []mlos.ModelUsage) []mlos.ModelUsage {
+	if usage == nil {
+		return nil
+	}
+	own := []mlos.ModelUsage{}
+	for _, u := range usage {
+		if id, ok := tenantModelID(u.ModelID); ok {
+			u.ModelID = id
+			own = append(own, u)
+		}
+	}
+	return own
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+)
+
+func TestTenantRegisteredModels(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+
+	models := []mlos.RegisteredModel{
+		{ModelID: "hf/shared@latest"},
+		{ModelID: "team-a:hf/bert@latest"},
+		{ModelID: "team-b:hf/gpt2@latest"},
+	}
+
+	tests := []struct {
+		tenant string
+		want   string
+	}{
+		{tenant: "", want: "hf/shared@latest"},
+		{tenant: "team-a", want: "hf/bert@latest"},
+		{tenant: "team-c", want: ""},
+	}
+	for _, tt := range tests {
+		cfg.Tenant = tt.tenant
+		own := tenantRegisteredModels(models)
+		if tt.want == "" {
+			if len(own) != 0 {
+				t.Errorf("tenant %q sees %+v, want nothing", tt.tenant, own)
+			}
+			continue
+		}
+		if len(own) != 1 || own[0].ModelID != tt.want {
+			t.Errorf("tenant %q sees %+v, want only %s", tt.tenant, own, tt.want)
+		}
+		if got := coreModelID(tt.want); (tt.tenant == "" && got != tt.want) || (tt.tenant != "" && got != tt.tenant+":"+tt.want) {
+			t.Errorf("coreModelID(%s) = %s for tenant %q", tt.want, got, tt.tenant)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/history"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+func unregisterCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unregister namespace/name[@version]",
+		Short: "Remove a model from MLOS Core",
+		Long: `Unregister an installed model from MLOS Core. The model stays installed.
+
+The model is removed from the MLOS Core endpoint it was registered with, as recorded by
+'axon register' (see 'axon list --registered'), or else from $MLOS_CORE_ENDPOINT.
+Axon then forgets the registration, so 'axon restore-registrations' doesn't register
+the model again, and removes the decrypted copy Core loaded an encrypted model from.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return unregisterModel(cmd.Context(), args[0])
+		},
+	}
+}
+
+// unregisterModel removes an installed or published model from MLOS Core and forgets
+// its registration
+func unregisterModel(ctx context.Context, modelSpec string) (err error) {
+	start := time.Now()
+	defer func() { recordHistory(history.ActionUnregister, modelSpec, cachedPackageDigest(modelSpec), err, start) }()
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return err
+	}
+	target, err := findRegistrationTarget(ref.Namespace, ref.Name, ref.Version)
+	if err != nil {
+		return err
+	}
+
+	// Published models have no registration state; they are unregistered from the
+	// configured endpoint
+	var state *cache.CoreRegistration
+	if !target.published {
+		if state, err = cache.ReadRegistration(target.path); err != nil {
+			return err
+		}
+	}
+	endpoint, coreID := mlos.EndpointFromEnv(), coreModelID(target.modelID)
+	if state != nil && state.Endpoint != "" {
+		endpoint, coreID = state.Endpoint, state.ModelID
+	}
+
+	fmt.Printf("🔌 Unregistering %s from MLOS Core at %s...\n", coreID, endpoint)
+	if err := mlos.NewClient(endpoint).UnregisterModel(ctx, coreID); err != nil {
+		return err
+	}
+
+	if err := forgetRegistrations(target.path); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to update registrations: %v\n", err)
+	}
+	if state != nil {
+		if err := cache.WriteRegistration(target.path, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to clear registration state: %v\n", err)
+		}
+	}
+	if err := removeDecrypted(ref.Namespace, ref.Name, filepath.Base(target.path)); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Warning: failed to remove decrypted copy: %v\n", err)
+	}
+
+	fmt.Printf("✅ Model unregistered from MLOS Core\n")
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/mlos"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestUnregisterModel(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	var deleted []string
+	core := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deleted = append(deleted, r.URL.EscapedPath())
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer core.Close()
+	// The recorded endpoint wins over the environment
+	t.Setenv("MLOS_CORE_ENDPOINT", "http://127.0.0.1:1")
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	for _, name := range []string{"bert", "gpt2"} {
+		manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: name, Version: "latest"}}
+		if err := cacheMgr.CacheModel("hf", name, "latest", manifest); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := cacheMgr.GetModelPath("hf", "bert", "latest")
+	state := &cache.CoreRegistration{Endpoint: core.URL, ModelID: "team-a/hf/bert@latest", RegisteredAt: "2026-05-01T12:00:00Z"}
+	if err := cache.WriteRegistration(path, state); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveRegistration(mlos.Registration{ModelID: "hf/bert@latest", Path: path}); err != nil {
+		t.Fatal(err)
+	}
+
+	models, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registered := registeredModels(models); len(registered) != 1 || registered[0].Name != "bert" {
+		t.Errorf("registeredModels() = %+v, want hf/bert", registered)
+	}
+
+	if err := unregisterModel(context.Background(), "hf/bert"); err != nil {
+		t.Fatalf("unregisterModel() error = %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/models/team-a%2Fhf%2Fbert@latest" {
+		t.Errorf("unregister requests = %v, want the recorded Core model ID", deleted)
+	}
+	if reg, err := cache.ReadRegistration(path); err != nil || reg != nil {
+		t.Errorf("registration state after unregister = %+v, %v", reg, err)
+	}
+	regs, err := mlos.LoadRegistrations(registrationsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(regs.Models) != 0 {
+		t.Errorf("registrations = %+v, want hf/bert forgotten", regs.Models)
+	}
+}
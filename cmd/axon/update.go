@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/delta"
+	"github.com/mlOS-foundation/axon/internal/history"
+	"github.com/mlOS-foundation/axon/internal/manifest"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// publishedModelPath returns where 'axon publish' puts a model for MLOS Core
+func publishedModelPath(namespace, name, version string) string {
+	return spec.NewModelRef(namespace, name, version).Path("/var/lib/mlos/models")
+}
+
+// buildPublishDelta writes a delta from version from (installed, or else published) to
+// the model being published from sourcePath into the temp directory, and returns its
+// manifest entry and path
+func buildPublishDelta(cacheMgr *cache.Manager, ref spec.Spec, from, sourcePath string) (*types.DeltaInfo, string, error) {
+	if from == ref.Version {
+		return nil, "", fmt.Errorf("a delta needs an earlier version than %s", ref.Version)
+	}
+	basePath := cacheMgr.GetModelPath(ref.Namespace, ref.Name, from)
+	if !cacheMgr.IsModelCached(ref.Namespace, ref.Name, from) {
+		basePath = publishedModelPath(ref.Namespace, ref.Name, from)
+		if !pathExists(basePath) {
+			return nil, "", fmt.Errorf("%s@%s is neither installed nor published", ref.ID(), from)
+		}
+	}
+	for _, dir := range []string{basePath, sourcePath} {
+		if cachecrypt.IsEncryptedDir(dir) {
+			return nil, "", errEncryptedModel(dir)
+		}
+	}
+
+	name := strings.TrimSuffix(safeTempFileName(ref.Namespace, ref.Name, from), ".axon") + "-to-" + ref.Version + delta.Extension
+	deltaPath := filepath.Join(utils.TempDir(), name)
+	index, err := delta.Create(deltaPath, ref.ID(), from, ref.Version, basePath, sourcePath)
+	if err != nil {
+		return nil, "", err
+	}
+	digest, size, err := core.ComputeChecksum(deltaPath)
+	if err != nil {
+		_ = os.Remove(deltaPath)
+		return nil, "", fmt.Errorf("failed to hash delta: %w", err)
+	}
+
+	chunks := 0
+	for _, file := range index.Files {
+		chunks += len(file.Chunks)
+	}
+	fmt.Printf("🧩 Built delta from %s: %d of %d chunk(s) changed (%s of %s)\n", from, len(index.Chunks), chunks, formatBytes(size), formatBytes(index.Size()))
+	return &types.DeltaInfo{From: from, URL: name, Size: size, SHA256: digest}, deltaPath, nil
+}
+
+// withDelta adds a delta to a manifest's list, replacing any earlier one from the same version
+func withDelta(deltas []types.DeltaInfo, info types.DeltaInfo) []types.DeltaInfo {
+	var kept []types.DeltaInfo
+	for _, d := range deltas {
+		if d.From != info.From {
+			kept = append(kept, d)
+		}
+	}
+	return append(kept, info)
+}
+
+// installedVersions returns the cached versions of namespace/name
+func installedVersions(cacheMgr *cache.Manager, namespace, name string) ([]cache.CachedModel, error) {
+	models, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	var versions []cache.CachedModel
+	for _, m := range models {
+		if m.Namespace == namespace && m.Name == name {
+			versions = append(versions, m)
+		}
+	}
+	return versions, nil
+}
+
+// selectDelta picks a delta listed in the manifest that applies to an installed version.
+// If there is none, it returns why, or "" when the manifest lists no deltas at all.
+func selectDelta(m *types.Manifest, installed []cache.CachedModel) (*types.DeltaInfo, cache.CachedModel, string) {
+	if len(m.Distribution.Deltas) == 0 {
+		return nil, cache.CachedModel{}, ""
+	}
+	// A package rebuilt from a delta can't be checked against the publisher's signature
+	if len(m.Distribution.Package.Signatures) > 0 || cfg.Security.RequireSignatures {
+		return nil, cache.CachedModel{}, "the package is signed and only the full package can be checked against its signature"
+	}
+
+	reason := "no delta applies to an installed version"
+	for i := range m.Distribution.Deltas {
+		info := &m.Distribution.Deltas[i]
+		for _, base := range installed {
+			switch {
+			case base.Version != info.From:
+				continue
+			case cachecrypt.IsEncryptedDir(base.Path):
+				// Applying it would write the installed weights to disk in plaintext
+				reason = fmt.Sprintf("installed version %s is encrypted at rest", base.Version)
+			case !pathExists(filepath.Join(base.Path, model.InventoryFileName)):
+				reason = fmt.Sprintf("installed version %s is not extracted", base.Version)
+			default:
+				return info, base, ""
+			}
+		}
+	}
+	return nil, cache.CachedModel{}, reason
+}
+
+// resolveDeltaURL resolves the URL of a delta, which may be relative to the package URL
+func resolveDeltaURL(packageURL, deltaURL string) string {
+	ref, err := url.Parse(deltaURL)
+	if err != nil || ref.IsAbs() || filepath.IsAbs(deltaURL) {
+		return deltaURL
+	}
+	if base, err := url.Parse(packageURL); err == nil && base.IsAbs() {
+		return base.ResolveReference(ref).String()
+	}
+	return filepath.Join(filepath.Dir(packageURL), filepath.FromSlash(deltaURL))
+}
+
+// fetchDelta downloads a delta, or copies it if it is a local file, to dest
+func fetchDelta(ctx context.Context, src, dest string) error {
+	if u, err := url.Parse(src); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return core.DownloadFile(ctx, &http.Client{Timeout: 10 * time.Minute, Transport: core.Transport}, src, dest, nil)
+	}
+	return copyFile(strings.TrimPrefix(src, "file://"), dest)
+}
+
+// updateFromDelta installs the version of ref described by the manifest by applying a
+// delta to the installed base version. The delta is checked against the manifest's
+// checksum and every rebuilt file against the delta's, and the rebuilt files are cached
+// the same way 'axon import' caches them.
+func updateFromDelta(ctx context.Context, cacheMgr *cache.Manager, ref spec.Spec, published *types.Manifest, info types.DeltaInfo, base cache.CachedModel, opts installOptions) error {
+	// The package checksum and schema change below; a full download needs the original
+	m, err := manifest.Clone(published)
+	if err != nil {
+		return err
+	}
+	if err := checkLicensePolicy(cfg.Policy, m, opts.overrideLicensePolicy); err != nil {
+		return fmt.Errorf("refusing to update to %s: %w (use --%s to update anyway)", ref, err, overrideLicensePolicyFlag)
+	}
+	if err := checkSizePolicy(cfg.Policy, cfg.Tenant, cacheMgr, ref.Namespace, m, opts.overrideSizePolicy); err != nil {
+		return fmt.Errorf("refusing to update to %s: %w (use --%s to update anyway)", ref, err, overrideSizePolicyFlag)
+	}
+	if info.SHA256 == "" {
+		return fmt.Errorf("the manifest lists no checksum for the delta from %s", info.From)
+	}
+
+	tmpDir, err := os.MkdirTemp(utils.TempDir(), "axon-update-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	deltaPath := filepath.Join(tmpDir, "update"+delta.Extension)
+	if err := fetchDelta(ctx, resolveDeltaURL(m.Distribution.Package.URL, info.URL), deltaPath); err != nil {
+		return fmt.Errorf("failed to download delta: %w", err)
+	}
+	digest, err := utils.ComputeSHA256(deltaPath)
+	if err != nil {
+		return fmt.Errorf("failed to hash delta: %w", err)
+	}
+	if !strings.EqualFold(digest, info.SHA256) {
+		return fmt.Errorf("delta checksum mismatch (expected sha256:%s, got sha256:%s)", info.SHA256, digest)
+	}
+	fmt.Printf("✓ Downloaded delta from %s (%s, checksum verified)\n", base.Version, formatBytes(info.Size))
+
+	filesDir := filepath.Join(tmpDir, "files")
+	index, err := delta.Apply(deltaPath, base.Path, filesDir)
+	if err != nil {
+		return err
+	}
+	if index.Model != ref.ID() || index.From != base.Version || index.To != ref.Version {
+		return fmt.Errorf("%w: delta is for %s %s -> %s", delta.ErrInvalid, index.Model, index.From, index.To)
+	}
+	fmt.Printf("✓ Rebuilt %d file(s) (%s) from %s, checksums verified\n", len(index.Files), formatBytes(index.Size()), base.Version)
+
+	// The package is rebuilt from the files, keeping their provenance
+	packagePath := filepath.Join(tmpDir, safeTempFileName(ref.Namespace, ref.Name, ref.Version))
+	written, err := rebuildPackageWithONNX(filesDir, packagePath, m.Spec.Conversion)
+	if err != nil {
+		return err
+	}
+	m.Distribution.Package.SHA256 = written.SHA256
+	m.Distribution.Package.Size = written.Size
+	_, err = cacheImportedPackage(cacheMgr, ref, m, packagePath)
+	return err
+}
+
+// updateModel installs the latest version of an installed model (or the version in
+// modelSpec), from a delta against an installed version when the manifest lists one
+// and from the full package otherwise
+func updateModel(ctx context.Context, modelSpec string, opts installOptions) (err error) {
+	ref, err := spec.Parse(modelSpec)
+	if err != nil {
+		return err
+	}
+	if ref.Kind == spec.KindDigest {
+		return fmt.Errorf("can't update to a digest pin; use 'axon install %s'", modelSpec)
+	}
+	namespace, name := ref.Namespace, ref.Name
+
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	installed, err := installedVersions(cacheMgr, namespace, name)
+	if err != nil {
+		return err
+	}
+	if len(installed) == 0 {
+		return fmt.Errorf("%s is not installed (install it with 'axon install %s')", ref.ID(), ref.ID())
+	}
+
+	adapter, _, _, err := findModelAdapter("", namespace, name)
+	if err != nil {
+		return err
+	}
+	target := spec.Latest
+	if ref.HasVersion {
+		target = ref.Version
+	}
+	m, err := core.ResolveManifest(ctx, adapter, namespace, name, target)
+	if err != nil {
+		return fmt.Errorf("failed to get manifest: %w", err)
+	}
+	if m.Metadata.Version != "" {
+		target = m.Metadata.Version
+	}
+	newRef, err := spec.Parse(fmt.Sprintf("%s/%s@%s", namespace, name, target))
+	if err != nil {
+		return err
+	}
+	if cacheMgr.IsModelCached(namespace, name, target) {
+		fmt.Printf("✓ %s is up to date\n", newRef)
+		return nil
+	}
+
+	start := time.Now()
+	defer func() {
+		recordHistory(history.ActionUpdate, newRef.String(), cachedPackageDigest(newRef.String()), err, start)
+	}()
+
+	info, base, reason := selectDelta(m, installed)
+	if info != nil {
+		fmt.Printf("🧩 Updating %s to %s with a delta from %s...\n", ref.ID(), target, base.Version)
+		err := updateFromDelta(ctx, cacheMgr, newRef, m, *info, base, opts)
+		if err == nil {
+			fmt.Printf("\n✓ Successfully updated %s to %s\n", ref.ID(), target)
+			return nil
+		}
+		fmt.Printf("⚠️  Delta update failed: %v\n", err)
+		reason = "the delta could not be applied"
+	}
+	if reason != "" {
+		fmt.Printf("   Downloading the full package (%s)\n", reason)
+	}
+	return installModel(ctx, newRef.String(), opts)
+}
+
+func updateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update namespace/name[@version] | --check [namespace/name | --all]",
+		Short: "Update a model",
+		Long: `Strengthen the pathway by updating an installed model to its latest version (or the
+given version). Installed versions are kept; remove them with 'axon uninstall'.
+
+If the new version's manifest lists a delta from an installed version (see 'axon publish
+--delta-from'), only the chunks of the files that changed are downloaded and the new
+files are rebuilt from the installed ones. The delta is checked against the manifest's
+SHA-256 and every rebuilt file against the delta's. The full package is downloaded
+instead when no delta applies, the installed version is encrypted at rest or its files
+were modified, or the package is signed (so its signature can be checked).
+
+The install flags (--format, --onnx, conversion flags) apply to full downloads.
+
+With --check nothing is downloaded: installed models (one, or every one with --all) are
+checked for newer upstream versions. Versioned installs are compared with the
+repository's latest version, Hugging Face installs with the repository's latest commit,
+and other installs by asking the servers whether the files' ETags still match.
+
+Examples:
+  axon update myteam/bert-finetuned
+  axon update myteam/bert-finetuned@2.0.0
+  axon update --check hf/bert-base-uncased
+  axon update --check --all --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			check, _ := cmd.Flags().GetBool("check")
+			all, _ := cmd.Flags().GetBool("all")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			if check {
+				if all == (len(args) == 1) {
+					return fmt.Errorf("--check takes a model or --all")
+				}
+				modelSpec := ""
+				if len(args) == 1 {
+					modelSpec = args[0]
+				}
+				checks, err := checkUpdates(cmd.Context(), modelSpec, all)
+				if err != nil {
+					return err
+				}
+				return printUpdateChecks(checks, jsonOutput)
+			}
+			if all || jsonOutput {
+				return fmt.Errorf("--all and --json require --check")
+			}
+			if len(args) != 1 {
+				return fmt.Errorf("update takes a model (or --check --all)")
+			}
+
+			opts, err := installOptionsFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Strengthening pathway for %s...\n", args[0])
+			return updateModel(cmd.Context(), args[0], opts)
+		},
+	}
+
+	cmd.Flags().Bool("check", false, "Only report whether newer upstream versions exist, without downloading")
+	cmd.Flags().Bool("all", false, "With --check, check every installed model")
+	cmd.Flags().Bool("json", false, "With --check, print the results as JSON")
+	cmd.Flags().StringP("format", "f", "auto", "Target format: auto, pytorch, onnx, gguf, native")
+	cmd.Flags().String("onnx", "", "ONNX conversion policy: required, prefer, skip (default: conversion.onnx_policy from config)")
+	cmd.Flags().Bool(overrideLicensePolicyFlag, false, "Update even if the model's license violates policy.allowed_licenses or policy.denied_licenses")
+	cmd.Flags().Bool(overrideSizePolicyFlag, false, "Update even if the model exceeds policy.max_model_size_gb or its namespace quota")
+	addConversionFlags(cmd)
+	return cmd
+}
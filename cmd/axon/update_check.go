@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Results of an update check
+const (
+	updateUpToDate  = "up-to-date"
+	updateAvailable = "update-available"
+	updateUnknown   = "unknown"
+)
+
+// How an update check found its result
+const (
+	checkByVersion  = "version"  // The repository's latest version
+	checkByRevision = "revision" // The repository's latest commit, against the one installed
+	checkByETag     = "etag"     // Conditional requests for the installed files
+)
+
+// updateCheck is whether an installed model has a newer upstream version
+type updateCheck struct {
+	Model     string `json:"model"`     // namespace/name
+	Installed string `json:"installed"` // Installed version
+	Latest    string `json:"latest,omitempty"`
+	Status    string `json:"status"`
+	Method    string `json:"method,omitempty"`
+	Detail    string `json:"detail,omitempty"` // Why the status is unknown, or what changed
+}
+
+// etagClient sends the conditional requests of update checks
+var etagClient = &http.Client{Timeout: 30 * time.Second, Transport: core.Transport}
+
+// installedProvenance reads the provenance of an installed model, from its extracted
+// files or else from its package
+func installedProvenance(modelPath string) (*types.Provenance, error) {
+	if provenance, err := core.ReadProvenance(filepath.Join(modelPath, types.ProvenanceFileName)); err == nil {
+		return provenance, nil
+	}
+	packages, _ := filepath.Glob(filepath.Join(modelPath, "*.axon"))
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("no %s or package found", types.ProvenanceFileName)
+	}
+	return packageProvenance(packages[0])
+}
+
+// checkUpdate checks whether an installed model has a newer upstream version without
+// downloading it. Versioned installs are compared with the repository's latest version;
+// "latest" installs with the repository's latest revision when the adapter reports one,
+// and else by asking the servers whether the installed files' ETags still match.
+func checkUpdate(ctx context.Context, cacheMgr *cache.Manager, adapter core.RepositoryAdapter, m cache.CachedModel) updateCheck {
+	check := updateCheck{Model: m.Namespace + "/" + m.Name, Installed: m.Version, Status: updateUnknown}
+
+	if m.Version != spec.Latest {
+		check.Method = checkByVersion
+		latest, err := core.ResolveManifest(ctx, adapter, m.Namespace, m.Name, spec.Latest)
+		if err != nil {
+			check.Detail = fmt.Sprintf("failed to get the latest manifest: %v", err)
+			return check
+		}
+		check.Latest = latest.Metadata.Version
+		if check.Latest == "" || check.Latest == spec.Latest {
+			check.Detail = "the repository doesn't report versions"
+			return check
+		}
+		check.Status = updateAvailable
+		if cacheMgr.IsModelCached(m.Namespace, m.Name, check.Latest) {
+			check.Status = updateUpToDate
+		}
+		return check
+	}
+
+	provenance, err := installedProvenance(m.Path)
+	if err != nil {
+		check.Detail = fmt.Sprintf("no provenance to compare (reinstall to enable checks): %v", err)
+		return check
+	}
+
+	if resolver, ok := adapter.(core.RevisionResolver); ok && provenance.Source.Revision != "" {
+		check.Method = checkByRevision
+		revision, err := resolver.LatestRevision(ctx, m.Namespace, m.Name)
+		if err != nil {
+			check.Detail = fmt.Sprintf("failed to get the latest revision: %v", err)
+			return check
+		}
+		check.Latest = revision
+		check.Status = updateUpToDate
+		if revision != provenance.Source.Revision {
+			check.Status = updateAvailable
+			check.Detail = fmt.Sprintf("installed revision %s", provenance.Source.Revision)
+		}
+		return check
+	}
+
+	checked := 0
+	for _, file := range provenance.Files {
+		if file.URL == "" || file.ETag == "" {
+			continue
+		}
+		check.Method = checkByETag
+		changed, err := etagChanged(ctx, file.URL, file.ETag)
+		if err != nil {
+			check.Detail = fmt.Sprintf("failed to check %s: %v", file.Path, err)
+			return check
+		}
+		if changed {
+			check.Status = updateAvailable
+			check.Detail = fmt.Sprintf("%s changed", file.Path)
+			return check
+		}
+		checked++
+	}
+	if checked == 0 {
+		check.Detail = "no revision or ETags recorded (reinstall to enable checks)"
+		return check
+	}
+	check.Status = updateUpToDate
+	return check
+}
+
+// etagChanged asks the server whether the file at url still has the given ETag
+func etagChanged(ctx context.Context, url, etag string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("If-None-Match", etag)
+	resp, err := etagClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return false, nil
+	case http.StatusOK:
+		// Not every server answers conditional HEAD requests with 304
+		return resp.Header.Get("ETag") != etag, nil
+	}
+	return false, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+}
+
+// checkUpdates checks installed models for newer upstream versions: every one with all,
+// else the versions of the model in modelSpec
+func checkUpdates(ctx context.Context, modelSpec string, all bool) ([]updateCheck, error) {
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	models, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	if !all {
+		ref, err := spec.Parse(modelSpec)
+		if err != nil {
+			return nil, err
+		}
+		var matches []cache.CachedModel
+		for _, m := range models {
+			if m.Namespace == ref.Namespace && m.Name == ref.Name && (!ref.HasVersion || m.Version == ref.Version) {
+				matches = append(matches, m)
+			}
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("%s is not installed (install it with 'axon install %s')", ref.ID(), ref.ID())
+		}
+		models = matches
+	}
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].Namespace+"/"+models[i].Name != models[j].Namespace+"/"+models[j].Name {
+			return models[i].Namespace+"/"+models[i].Name < models[j].Namespace+"/"+models[j].Name
+		}
+		return models[i].Version < models[j].Version
+	})
+
+	checks := make([]updateCheck, 0, len(models))
+	for _, m := range models {
+		adapter, _, _, err := findModelAdapter("", m.Namespace, m.Name)
+		if err != nil {
+			checks = append(checks, updateCheck{Model: m.Namespace + "/" + m.Name, Installed: m.Version, Status: updateUnknown, Detail: err.Error()})
+			continue
+		}
+		checks = append(checks, checkUpdate(ctx, cacheMgr, adapter, m))
+	}
+	return checks, nil
+}
+
+// printUpdateChecks prints update checks one model per line, or as JSON
+func printUpdateChecks(checks []updateCheck, jsonOutput bool) error {
+	if jsonOutput {
+		data, err := json.MarshalIndent(checks, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal update checks: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(checks) == 0 {
+		fmt.Println("No models installed")
+		return nil
+	}
+	available := 0
+	for _, c := range checks {
+		icon := "✓"
+		switch c.Status {
+		case updateAvailable:
+			icon = "⬆️ "
+			available++
+		case updateUnknown:
+			icon = "?"
+		}
+		line := fmt.Sprintf("%s %s@%s: %s", icon, c.Model, c.Installed, c.Status)
+		if c.Status == updateAvailable && c.Latest != "" && c.Method == checkByVersion {
+			line += " (" + c.Latest + ")"
+		}
+		if c.Detail != "" {
+			line += " - " + c.Detail
+		}
+		fmt.Println(line)
+	}
+	if available > 0 {
+		fmt.Printf("\n%d model(s) can be updated with 'axon update <model>'\n", available)
+	}
+	return nil
+}
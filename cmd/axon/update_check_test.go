@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// checkAdapter reports fixed latest versions and revisions for update checks
+type checkAdapter struct {
+	latestVersion  string
+	latestRevision string
+}
+
+func (a *checkAdapter) Name() string                          { return "check" }
+func (a *checkAdapter) CanHandle(namespace, name string) bool { return true }
+func (a *checkAdapter) GetManifest(ctx context.Context, namespace, name, version string) (*types.Manifest, error) {
+	return &types.Manifest{Metadata: types.Metadata{Namespace: namespace, Name: name, Version: a.latestVersion}}, nil
+}
+func (a *checkAdapter) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress core.ProgressCallback) error {
+	return nil
+}
+func (a *checkAdapter) Search(ctx context.Context, query string) ([]types.SearchResult, error) {
+	return nil, nil
+}
+
+// revisionAdapter is a checkAdapter whose repository has revisions
+type revisionAdapter struct{ checkAdapter }
+
+func (a *revisionAdapter) LatestRevision(ctx context.Context, namespace, name string) (string, error) {
+	return a.latestRevision, nil
+}
+
+// cacheWithProvenance caches a model version with the given provenance
+func cacheWithProvenance(t *testing.T, cacheMgr *cache.Manager, name, version string, provenance *types.Provenance) cache.CachedModel {
+	t.Helper()
+	if err := cacheMgr.CacheModel("hf", name, version, &types.Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	path := cacheMgr.GetModelPath("hf", name, version)
+	if provenance != nil {
+		data, err := json.Marshal(provenance)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(path, types.ProvenanceFileName), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return cache.CachedModel{Namespace: "hf", Name: name, Version: version, Path: path}
+}
+
+func TestCheckUpdate(t *testing.T) {
+	cacheMgr := cache.NewManager(t.TempDir())
+	ctx := core.WithResolution(context.Background(), core.NewResolution())
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"v1"`
+		if r.URL.Path == "/changed.onnx" {
+			etag = `"v2"`
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+	}))
+	defer server.Close()
+
+	versioned := cacheWithProvenance(t, cacheMgr, "versioned", "1.0.0", nil)
+	revised := cacheWithProvenance(t, cacheMgr, "revised", "latest", &types.Provenance{Source: types.ProvenanceSource{Revision: "abc123"}})
+	current := cacheWithProvenance(t, cacheMgr, "current", "latest", &types.Provenance{Source: types.ProvenanceSource{Revision: "def456"}})
+	unchanged := cacheWithProvenance(t, cacheMgr, "unchanged", "latest", &types.Provenance{Files: []types.ProvenanceFile{
+		{Path: "model.onnx", URL: server.URL + "/model.onnx", ETag: `"v1"`},
+	}})
+	changed := cacheWithProvenance(t, cacheMgr, "changed", "latest", &types.Provenance{Files: []types.ProvenanceFile{
+		{Path: "config.json", URL: server.URL + "/config.json", ETag: `"v1"`},
+		{Path: "model.onnx", URL: server.URL + "/changed.onnx", ETag: `"v1"`},
+	}})
+	legacy := cacheWithProvenance(t, cacheMgr, "legacy", "latest", &types.Provenance{Files: []types.ProvenanceFile{{Path: "model.onnx"}}})
+
+	plain := &checkAdapter{latestVersion: "2.0.0"}
+	withRevisions := &revisionAdapter{checkAdapter{latestRevision: "def456"}}
+	tests := []struct {
+		model      cache.CachedModel
+		adapter    core.RepositoryAdapter
+		wantStatus string
+		wantMethod string
+	}{
+		{versioned, plain, updateAvailable, checkByVersion},
+		{revised, withRevisions, updateAvailable, checkByRevision},
+		{current, withRevisions, updateUpToDate, checkByRevision},
+		{unchanged, plain, updateUpToDate, checkByETag},
+		{changed, plain, updateAvailable, checkByETag},
+		{legacy, plain, updateUnknown, ""},
+	}
+	for _, tt := range tests {
+		got := checkUpdate(ctx, cacheMgr, tt.adapter, tt.model)
+		if got.Status != tt.wantStatus || got.Method != tt.wantMethod {
+			t.Errorf("checkUpdate(%s) = %+v, want %s by %q", tt.model.Name, got, tt.wantStatus, tt.wantMethod)
+		}
+	}
+
+	// An update that is already installed leaves nothing to update
+	cacheWithProvenance(t, cacheMgr, "versioned", "2.0.0", nil)
+	if got := checkUpdate(ctx, cacheMgr, plain, versioned); got.Status != updateUpToDate || got.Latest != "2.0.0" {
+		t.Errorf("checkUpdate() with the latest version installed = %+v", got)
+	}
+}
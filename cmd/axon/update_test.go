@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestUpdateFromDelta(t *testing.T) {
+	originalCfg := cfg
+	defer func() { cfg = originalCfg }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = t.TempDir()
+
+	importVersion := func(version, weights string) spec.Spec {
+		t.Helper()
+		dir := t.TempDir()
+		for path, content := range map[string]string{"model.onnx": weights, "config.json": `{"model_type": "bert"}`} {
+			if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ref, err := spec.Parse("myteam/mymodel@" + version)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := importModel(dir, ref, importOptions{license: "mit"}); err != nil {
+			t.Fatalf("importModel(%s) error = %v", version, err)
+		}
+		return ref
+	}
+	importVersion("1.0.0", "onnx weights v1")
+	newRef := importVersion("2.0.0", "onnx weights v2")
+
+	// Publish 2.0.0 with a delta from 1.0.0, then drop it from the cache
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	newPath := cacheMgr.GetModelPath(newRef.Namespace, newRef.Name, newRef.Version)
+	info, deltaPath, err := buildPublishDelta(cacheMgr, newRef, "1.0.0", newPath)
+	if err != nil {
+		t.Fatalf("buildPublishDelta() error = %v", err)
+	}
+	defer func() { _ = os.Remove(deltaPath) }()
+	published, err := loadManifest(filepath.Join(newPath, "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishDir := t.TempDir()
+	published.Distribution.Package.URL = filepath.Join(publishDir, "mymodel.axon")
+	published.Distribution.Deltas = withDelta(nil, *info)
+	if err := copyFile(deltaPath, filepath.Join(publishDir, info.URL)); err != nil {
+		t.Fatal(err)
+	}
+	if err := cacheMgr.RemoveModel(newRef.Namespace, newRef.Name, newRef.Version); err != nil {
+		t.Fatal(err)
+	}
+
+	installed, err := installedVersions(cacheMgr, newRef.Namespace, newRef.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	selected, base, reason := selectDelta(published, installed)
+	if selected == nil || base.Version != "1.0.0" {
+		t.Fatalf("selectDelta() = %v, %+v, %q; want the delta from 1.0.0", selected, base, reason)
+	}
+
+	signed := *published
+	signed.Distribution.Package.Signatures = []types.PackageSignature{{KeyID: "k"}}
+	if selected, _, reason := selectDelta(&signed, installed); selected != nil || !strings.Contains(reason, "signed") {
+		t.Errorf("selectDelta() of a signed package = %v, %q", selected, reason)
+	}
+
+	tampered := *info
+	tampered.SHA256 = strings.Repeat("0", 64)
+	if err := updateFromDelta(context.Background(), cacheMgr, newRef, published, tampered, base, installOptions{}); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("updateFromDelta() with a wrong checksum error = %v", err)
+	}
+
+	if err := updateFromDelta(context.Background(), cacheMgr, newRef, published, *selected, base, installOptions{}); err != nil {
+		t.Fatalf("updateFromDelta() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(newPath, model.ONNXFileName))
+	if err != nil || string(data) != "onnx weights v2" {
+		t.Errorf("model.onnx = %q, %v", data, err)
+	}
+	if problems, err := model.VerifyInventory(newPath); err != nil || len(problems) > 0 {
+		t.Errorf("VerifyInventory() = %v, %v", problems, err)
+	}
+	if published.Distribution.Package.URL != filepath.Join(publishDir, "mymodel.axon") {
+		t.Error("updateFromDelta() modified the published manifest")
+	}
+}
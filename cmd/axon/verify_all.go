@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/internal/model"
+)
+
+// integrityStatus is the outcome of checking one model with 'axon verify --all'
+type integrityStatus string
+
+const (
+	integrityOK        integrityStatus = "ok"
+	integrityCorrupted integrityStatus = "corrupted"
+	integrityMissing   integrityStatus = "missing"   // Indexed, but its directory is gone
+	integrityUnchecked integrityStatus = "unchecked" // Neither indexed nor with a files.json
+	integrityError     integrityStatus = "error"
+)
+
+// integrityTarget is a model to check: indexed, cached, or both
+type integrityTarget struct {
+	ModelID string
+	Path    string
+	Record  *cache.IntegrityRecord // nil if the model isn't in the integrity index
+}
+
+// integrityResult is what checking a model found
+type integrityResult struct {
+	integrityTarget
+	Status   integrityStatus
+	Problems []string
+	Indexed  bool // The model wasn't in the index and was added to it
+	Repair   string
+}
+
+// repairBackupSuffix names the copy of a model moved aside while 'axon verify --all
+// --repair' reinstalls it
+const repairBackupSuffix = ".repair"
+
+// recordIntegrity records a model's freshly written inventory in the cache's integrity index
+func recordIntegrity(cacheMgr *cache.Manager, modelPath string, inventory *model.Inventory) {
+	if err := cacheMgr.RecordIntegrity(modelPath, inventory); err != nil {
+		fmt.Printf("⚠️  Failed to update the integrity index: %v\n", err)
+	}
+}
+
+// integrityTargets lists the models of the integrity index and the cache, by model ID
+func integrityTargets(cacheMgr *cache.Manager) ([]integrityTarget, error) {
+	index, err := cacheMgr.LoadIntegrityIndex()
+	if err != nil {
+		return nil, err
+	}
+	models, err := cacheMgr.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+
+	targets := make(map[string]*integrityTarget)
+	for i := range index.Models {
+		rec := &index.Models[i]
+		targets[rec.ModelID] = &integrityTarget{ModelID: rec.ModelID, Path: rec.Path, Record: rec}
+	}
+	for _, m := range models {
+		id := fmt.Sprintf("%s/%s@%s", m.Namespace, filepath.ToSlash(m.Name), m.Version)
+		if target, ok := targets[id]; ok {
+			target.Path = m.Path
+			continue
+		}
+		targets[id] = &integrityTarget{ModelID: id, Path: m.Path}
+	}
+
+	list := make([]integrityTarget, 0, len(targets))
+	for _, target := range targets {
+		list = append(list, *target)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ModelID < list[j].ModelID })
+	return list, nil
+}
+
+// checkIntegrity checks a model's files against its integrity record, or against its
+// files.json if it isn't indexed yet. A files.json that no longer matches the index is a
+// problem in itself: it is what 'axon verify' trusts.
+func checkIntegrity(target integrityTarget, key []byte) integrityResult {
+	result := integrityResult{integrityTarget: target, Status: integrityOK}
+	if _, err := os.Stat(target.Path); os.IsNotExist(err) {
+		result.Status = integrityMissing
+		return result
+	}
+
+	entries := []model.InventoryEntry(nil)
+	inventory, err := model.ReadInventory(target.Path)
+	switch {
+	case target.Record != nil:
+		entries = target.Record.Files
+		if os.IsNotExist(err) {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: missing", model.InventoryFileName))
+		} else if err != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: %v", model.InventoryFileName, err))
+		} else if inventory.Digest() != target.Record.Digest {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: does not match the integrity index", model.InventoryFileName))
+		}
+	case os.IsNotExist(err):
+		result.Status = integrityUnchecked
+		return result
+	case err != nil:
+		result.Status = integrityError
+		result.Problems = []string{err.Error()}
+		return result
+	default:
+		entries = inventory.Files
+	}
+
+	result.Problems = append(result.Problems, model.VerifyEntries(target.Path, entries, key)...)
+	if len(result.Problems) > 0 {
+		result.Status = integrityCorrupted
+	}
+	return result
+}
+
+// verifyAll checks every model of the cache in parallel, indexing the intact models that
+// weren't indexed, and with repair reinstalls the corrupted and missing ones
+func verifyAll(ctx context.Context, concurrency int, repair bool) error {
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+	}
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+	targets, err := integrityTargets(cacheMgr)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("No models installed")
+		return nil
+	}
+
+	var key []byte
+	for _, target := range targets {
+		if cachecrypt.IsEncryptedDir(target.Path) {
+			if key, err = cacheEncryptionKey(false); err != nil {
+				fmt.Printf("⚠️  Cannot check encrypted files: %v\n", err)
+			}
+			break
+		}
+	}
+
+	fmt.Printf("🔍 Verifying %d model(s)...\n", len(targets))
+	results := make([]integrityResult, len(targets))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, target integrityTarget) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = checkIntegrity(target, key)
+		}(i, target)
+	}
+	wg.Wait()
+
+	for i := range results {
+		result := &results[i]
+		if result.Status == integrityOK && result.Record == nil {
+			if inventory, err := model.ReadInventory(result.Path); err == nil {
+				if err := cacheMgr.RecordIntegrity(result.Path, inventory); err != nil {
+					fmt.Printf("⚠️  Failed to update the integrity index: %v\n", err)
+				} else {
+					result.Indexed = true
+				}
+			}
+		}
+		if repair && (result.Status == integrityCorrupted || result.Status == integrityMissing) {
+			if err := repairModel(ctx, result.integrityTarget); err != nil {
+				result.Repair = fmt.Sprintf("repair failed: %v", err)
+			} else {
+				result.Repair = "repaired"
+			}
+		}
+	}
+
+	return printIntegrityResults(results)
+}
+
+// repairModel reinstalls a corrupted or missing model. The corrupted copy is moved aside
+// and restored if the reinstall fails; its registration carries over to the new copy.
+func repairModel(ctx context.Context, target integrityTarget) error {
+	backup := target.Path + repairBackupSuffix
+	var reg *cache.CoreRegistration
+	if pathExists(target.Path) {
+		reg, _ = cache.ReadRegistration(target.Path)
+		if err := os.RemoveAll(backup); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", backup, err)
+		}
+		if err := os.Rename(target.Path, backup); err != nil {
+			return fmt.Errorf("failed to move aside %s: %w", target.Path, err)
+		}
+	}
+
+	if err := modelInstaller(ctx, target.ModelID, defaultInstallOptions()); err != nil {
+		if pathExists(backup) {
+			_ = os.RemoveAll(target.Path)
+			if restoreErr := os.Rename(backup, target.Path); restoreErr != nil {
+				return fmt.Errorf("%w (and failed to restore %s: %v)", err, target.Path, restoreErr)
+			}
+		}
+		return err
+	}
+
+	if reg != nil {
+		if err := cache.WriteRegistration(target.Path, reg); err != nil {
+			fmt.Printf("⚠️  Failed to keep the registration of %s: %v\n", target.ModelID, err)
+		}
+	}
+	if err := os.RemoveAll(backup); err != nil {
+		fmt.Printf("⚠️  Failed to remove %s: %v\n", backup, err)
+	}
+	return nil
+}
+
+// printIntegrityResults prints what 'axon verify --all' found, failing if any model is
+// corrupted or missing and wasn't repaired
+func printIntegrityResults(results []integrityResult) error {
+	var ok, repaired, unchecked, failed int
+	for _, result := range results {
+		switch result.Status {
+		case integrityOK:
+			ok++
+			if result.Indexed {
+				fmt.Printf("✓ %s (added to the integrity index)\n", result.ModelID)
+			} else {
+				fmt.Printf("✓ %s\n", result.ModelID)
+			}
+			continue
+		case integrityUnchecked:
+			unchecked++
+			fmt.Printf("⚠️  %s: not indexed and no %s (installed before integrity tracking, or not extracted)\n", result.ModelID, model.InventoryFileName)
+			continue
+		case integrityMissing:
+			fmt.Printf("✗ %s: missing (%s no longer exists)\n", result.ModelID, result.Path)
+		default:
+			fmt.Printf("✗ %s: %s\n", result.ModelID, result.Status)
+			for _, problem := range result.Problems {
+				fmt.Printf("  - %s\n", problem)
+			}
+		}
+		if result.Repair == "repaired" {
+			repaired++
+			fmt.Printf("  🔧 Repaired by reinstalling\n")
+			continue
+		}
+		if result.Repair != "" {
+			fmt.Printf("  ✗ %s\n", result.Repair)
+		}
+		failed++
+	}
+
+	fmt.Printf("\n%d intact, %d repaired, %d failed, %d unchecked\n", ok, repaired, failed, unchecked)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d model(s) failed verification", failed, len(results))
+	}
+	return nil
+}
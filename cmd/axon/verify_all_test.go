@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/internal/config"
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// cacheVerifiableModel caches a model with one file and records it in the integrity index
+func cacheVerifiableModel(t *testing.T, cacheMgr *cache.Manager, name string, index bool) string {
+	t.Helper()
+	if err := cacheMgr.CacheModel("hf", name, "latest", &types.Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	path := cacheMgr.GetModelPath("hf", name, "latest")
+	if err := os.WriteFile(filepath.Join(path, "model.safetensors"), []byte("weights of "+name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	inventory, err := model.WriteInventory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index {
+		if err := cacheMgr.RecordIntegrity(path, inventory); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path
+}
+
+func TestVerifyAll(t *testing.T) {
+	originalCfg, originalInstaller := cfg, modelInstaller
+	defer func() { cfg, modelInstaller = originalCfg, originalInstaller }()
+	cfg = config.DefaultConfig()
+	cfg.HomeDir = t.TempDir()
+	cfg.CacheDir = filepath.Join(cfg.HomeDir, "cache")
+	cacheMgr := cache.NewManager(cfg.ModelCacheDir())
+
+	cacheVerifiableModel(t, cacheMgr, "intact", true)
+	legacy := cacheVerifiableModel(t, cacheMgr, "legacy", false)
+	corrupted := cacheVerifiableModel(t, cacheMgr, "corrupted", true)
+	deleted := cacheVerifiableModel(t, cacheMgr, "deleted", true)
+	tampered := cacheVerifiableModel(t, cacheMgr, "tampered", true)
+
+	if err := os.WriteFile(filepath.Join(corrupted, "model.safetensors"), []byte("bit rot"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(deleted); err != nil {
+		t.Fatal(err)
+	}
+	// Rewriting files.json after changing the weights doesn't hide it from the index
+	if err := os.WriteFile(filepath.Join(tampered, "model.safetensors"), []byte("backdoored weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := model.WriteInventory(tampered); err != nil {
+		t.Fatal(err)
+	}
+
+	targets, err := integrityTargets(cacheMgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statuses := make(map[string]integrityStatus)
+	for _, target := range targets {
+		result := checkIntegrity(target, nil)
+		statuses[result.ModelID] = result.Status
+		if result.ModelID == "hf/tampered@latest" && len(result.Problems) != 2 {
+			t.Errorf("tampered problems = %v, want files.json and the weights", result.Problems)
+		}
+	}
+	want := map[string]integrityStatus{
+		"hf/intact@latest":    integrityOK,
+		"hf/legacy@latest":    integrityOK,
+		"hf/corrupted@latest": integrityCorrupted,
+		"hf/deleted@latest":   integrityMissing,
+		"hf/tampered@latest":  integrityCorrupted,
+	}
+	for id, status := range want {
+		if statuses[id] != status {
+			t.Errorf("%s status = %q, want %q", id, statuses[id], status)
+		}
+	}
+
+	if err := verifyAll(context.Background(), 2, false); err == nil {
+		t.Error("verifyAll() = nil, want an error for the corrupted and missing models")
+	}
+	// Intact models that weren't indexed are indexed
+	index, err := cacheMgr.LoadIntegrityIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := index.Lookup("hf/legacy@latest"); rec == nil || rec.Path != legacy {
+		t.Errorf("legacy record = %+v, want it indexed", rec)
+	}
+
+	// Repair reinstalls; a failed reinstall restores the corrupted copy
+	var installed []string
+	modelInstaller = func(ctx context.Context, modelSpec string, opts installOptions) error {
+		installed = append(installed, modelSpec)
+		if modelSpec == "hf/corrupted@latest" {
+			return errors.New("registry unreachable")
+		}
+		ref, err := spec.Parse(modelSpec)
+		if err != nil {
+			return err
+		}
+		cacheVerifiableModel(t, cacheMgr, ref.Name, true)
+		return nil
+	}
+	if err := verifyAll(context.Background(), 2, true); err == nil {
+		t.Error("verifyAll(repair) = nil, want an error for the model that failed to reinstall")
+	}
+	if len(installed) != 3 {
+		t.Errorf("reinstalled %v, want the corrupted, deleted and tampered models", installed)
+	}
+	if _, err := os.Stat(filepath.Join(corrupted, "model.safetensors")); err != nil {
+		t.Errorf("corrupted copy not restored after a failed repair: %v", err)
+	}
+	if _, err := os.Stat(corrupted + repairBackupSuffix); !os.IsNotExist(err) {
+		t.Errorf("backup left behind: %v", err)
+	}
+
+	// Uninstalling the model that couldn't be repaired leaves an intact cache
+	if err := removeCachedModel(cacheMgr, cache.CachedModel{Namespace: "hf", Name: "corrupted", Version: "latest", Path: corrupted}); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifyAll(context.Background(), 2, false); err != nil {
+		t.Errorf("verifyAll() after repair = %v, want every model intact", err)
+	}
+}
@@ -0,0 +1,266 @@
+// Package archive unpacks tar archives without trusting them. Packages come from
+// registries and mirrors, and TensorFlow Hub and ModelScope models are archives the
+// repository built, so an entry may try to write outside the destination (../ or absolute
+// names, links, or symlinks already in the destination) or decompress to far more than
+// the download (a decompression bomb). ExtractTarGz rejects the former and stops at the
+// configured size, entry count and compression ratio limits.
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+// Defaults of Limits
+const (
+	DefaultMaxSize    = 1 << 40 // 1 TiB
+	DefaultMaxEntries = 100_000
+	DefaultMaxRatio   = 100
+)
+
+// ratioGrace is how much an archive may unpack to before its compression ratio is checked,
+// since small archives of text compress well
+var ratioGrace int64 = 256 << 20
+
+// Errors of ExtractTarGz
+var (
+	ErrUnsafeEntry   = errors.New("unsafe archive entry")
+	ErrLimitExceeded = errors.New("archive exceeds extraction limits")
+)
+
+// Limits bound what extracting one archive may produce
+type Limits struct {
+	MaxSize    int64   // Bytes of all files together (0 = DefaultMaxSize)
+	MaxEntries int     // Entries, including directories (0 = DefaultMaxEntries)
+	MaxRatio   float64 // Bytes unpacked per compressed byte read (0 = DefaultMaxRatio)
+}
+
+// limits holds the settings from SetLimits
+var limits atomic.Pointer[Limits]
+
+// SetLimits configures the limits of extraction, filling in defaults
+func SetLimits(l Limits) {
+	if l.MaxSize == 0 {
+		l.MaxSize = DefaultMaxSize
+	}
+	if l.MaxEntries == 0 {
+		l.MaxEntries = DefaultMaxEntries
+	}
+	if l.MaxRatio == 0 {
+		l.MaxRatio = DefaultMaxRatio
+	}
+	limits.Store(&l)
+}
+
+// Settings returns the configured limits, or the defaults
+func Settings() Limits {
+	if l := limits.Load(); l != nil {
+		return *l
+	}
+	return Limits{MaxSize: DefaultMaxSize, MaxEntries: DefaultMaxEntries, MaxRatio: DefaultMaxRatio}
+}
+
+// ExtractTarGz extracts the regular files and directories of the tar.gz archive at
+// archivePath into destDir, skipping the entries (by cleaned slash-separated name) skip
+// reports. Entries with absolute names or .. components, links, and entries that would
+// be written through a symlink leaving destDir fail with ErrUnsafeEntry; archives over
+// the limits fail with ErrLimitExceeded. What was extracted before an error is left in
+// place.
+func ExtractTarGz(archivePath, destDir string, skip func(name string) bool) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	compressed := &countingReader{r: f}
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer func() {
+		_ = gz.Close()
+	}()
+	return extract(tar.NewReader(gz), destDir, skip, compressed)
+}
+
+func extract(tr *tar.Reader, destDir string, skip func(name string) bool, compressed *countingReader) error {
+	destDir, err := filepath.Abs(destDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination directory: %w", err)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	// Symlinks in the path leading to destDir are fine; only those below it are checked
+	realDest, err := filepath.EvalSymlinks(destDir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination directory: %w", err)
+	}
+
+	b := &budget{limits: Settings(), compressed: compressed}
+	for entries := 0; ; entries++ {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+		if entries >= b.limits.MaxEntries {
+			return fmt.Errorf("%w: more than %d entries", ErrLimitExceeded, b.limits.MaxEntries)
+		}
+
+		name, err := EntryName(header.Name)
+		if err != nil {
+			return err
+		}
+		if name == "." || (skip != nil && skip(name)) {
+			continue
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			target, err := safeTarget(destDir, realDest, name)
+			if err != nil {
+				return err
+			}
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if header.Size > b.limits.MaxSize-b.written {
+				return fmt.Errorf("%w: %s would unpack to more than %d bytes", ErrLimitExceeded, name, b.limits.MaxSize)
+			}
+			target, err := safeTarget(destDir, realDest, name)
+			if err != nil {
+				return err
+			}
+			if err := b.writeFile(tr, target, header.Mode); err != nil {
+				return fmt.Errorf("failed to extract %s: %w", name, err)
+			}
+		case tar.TypeSymlink, tar.TypeLink:
+			return fmt.Errorf("%w: %s is a link to %s", ErrUnsafeEntry, name, header.Linkname)
+		default:
+			// Devices, FIFOs and PAX records carry no model files
+		}
+	}
+}
+
+// EntryName returns the cleaned, slash-separated form of an archive entry name, failing
+// with ErrUnsafeEntry for absolute names and names with .. components
+func EntryName(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(slashed, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %s is an absolute path", ErrUnsafeEntry, name)
+	}
+	for _, part := range strings.Split(slashed, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %s leaves the destination directory", ErrUnsafeEntry, name)
+		}
+	}
+	return path.Clean(slashed), nil
+}
+
+// safeTarget returns where an entry goes in destDir, checking that no symlink already
+// in destDir leads it outside. Symlinks at the target itself are removed: the entry
+// replaces the link rather than writing through it.
+func safeTarget(destDir, realDest, name string) (string, error) {
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if err := os.Remove(target); err != nil {
+			return "", fmt.Errorf("failed to replace symlink %s: %w", name, err)
+		}
+	}
+
+	// The deepest existing ancestor must resolve inside destDir
+	dir := filepath.Dir(target)
+	for {
+		if _, err := os.Lstat(dir); err == nil {
+			break
+		}
+		dir = filepath.Dir(dir)
+	}
+	real, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", name, err)
+	}
+	if real != realDest && !strings.HasPrefix(real, realDest+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s would be written through a symlink to %s", ErrUnsafeEntry, name, real)
+	}
+	return target, nil
+}
+
+// budget tracks what an extraction has written against its limits
+type budget struct {
+	limits     Limits
+	compressed *countingReader
+	written    int64
+}
+
+// writeFile writes one file, keeping only the permission bits of its mode
+func (b *budget) writeFile(r io.Reader, target string, mode int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(mode)&os.ModePerm|0600)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		_ = out.Close()
+	}()
+	if _, err := io.Copy(&limitedWriter{w: out, b: b}, r); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// check fails once the extraction is over its size or compression ratio limit
+func (b *budget) check() error {
+	if b.written > b.limits.MaxSize {
+		return fmt.Errorf("%w: unpacks to more than %d bytes", ErrLimitExceeded, b.limits.MaxSize)
+	}
+	if b.written > ratioGrace && b.compressed.n > 0 {
+		if ratio := float64(b.written) / float64(b.compressed.n); ratio > b.limits.MaxRatio {
+			return fmt.Errorf("%w: compression ratio over %g (decompression bomb?)", ErrLimitExceeded, b.limits.MaxRatio)
+		}
+	}
+	return nil
+}
+
+// limitedWriter counts what is written against the budget
+type limitedWriter struct {
+	w io.Writer
+	b *budget
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	lw.b.written += int64(len(p))
+	if err := lw.b.check(); err != nil {
+		return 0, err
+	}
+	return lw.w.Write(p)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
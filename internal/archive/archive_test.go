@@ -0,0 +1,161 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name     string
+	typeflag byte
+	content  string
+	linkname string
+}
+
+// writeTarGz writes a tar.gz archive of the entries and returns its path
+func writeTarGz(t *testing.T, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0644, Size: int64(len(e.content)), Linkname: e.linkname}
+		if e.typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "archive.tar.gz")
+	if err := os.WriteFile(p, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestExtractTarGz(t *testing.T) {
+	archivePath := writeTarGz(t, []entry{
+		{name: "./", typeflag: tar.TypeDir},
+		{name: "variables/", typeflag: tar.TypeDir},
+		{name: "saved_model.pb", typeflag: tar.TypeReg, content: "graph"},
+		{name: "variables/variables.index", typeflag: tar.TypeReg, content: "index"},
+		{name: "skipped.json", typeflag: tar.TypeReg, content: "{}"},
+	})
+	dest := t.TempDir()
+	err := ExtractTarGz(archivePath, dest, func(name string) bool { return name == "skipped.json" })
+	if err != nil {
+		t.Fatalf("ExtractTarGz() error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, "variables", "variables.index")); err != nil || string(data) != "index" {
+		t.Errorf("variables/variables.index = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "skipped.json")); !os.IsNotExist(err) {
+		t.Errorf("skipped entry was extracted: %v", err)
+	}
+}
+
+func TestExtractTarGz_UnsafeEntries(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []entry
+	}{
+		{"parent directory", []entry{{name: "../escape.txt", typeflag: tar.TypeReg, content: "x"}}},
+		{"nested parent directory", []entry{{name: "variables/../../escape.txt", typeflag: tar.TypeReg, content: "x"}}},
+		{"absolute path", []entry{{name: "/tmp/escape.txt", typeflag: tar.TypeReg, content: "x"}}},
+		{"backslashes", []entry{{name: `..\escape.txt`, typeflag: tar.TypeReg, content: "x"}}},
+		{"symlink", []entry{{name: "link", typeflag: tar.TypeSymlink, linkname: "/etc"}}},
+		{"hard link", []entry{{name: "link", typeflag: tar.TypeLink, linkname: "/etc/passwd"}}},
+	}
+	for _, tt := range tests {
+		parent := t.TempDir()
+		dest := filepath.Join(parent, "model")
+		err := ExtractTarGz(writeTarGz(t, tt.entries), dest, nil)
+		if !errors.Is(err, ErrUnsafeEntry) {
+			t.Errorf("%s: ExtractTarGz() error = %v, want ErrUnsafeEntry", tt.name, err)
+		}
+		if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !os.IsNotExist(err) {
+			t.Errorf("%s: an entry was written outside the destination", tt.name)
+		}
+	}
+}
+
+func TestExtractTarGz_ExistingSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	dest := t.TempDir()
+	// A symlink left in the destination, e.g. by an earlier extraction
+	if err := os.Symlink(outside, filepath.Join(dest, "variables")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	err := ExtractTarGz(writeTarGz(t, []entry{{name: "variables/variables.index", typeflag: tar.TypeReg, content: "x"}}), dest, nil)
+	if !errors.Is(err, ErrUnsafeEntry) {
+		t.Errorf("ExtractTarGz() through a symlink error = %v, want ErrUnsafeEntry", err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "variables.index")); !os.IsNotExist(err) {
+		t.Error("an entry was written through the symlink")
+	}
+
+	// A symlink at the entry's own path is replaced, not written through
+	target := filepath.Join(outside, "target.txt")
+	if err := os.WriteFile(target, []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(dest, "config.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractTarGz(writeTarGz(t, []entry{{name: "config.json", typeflag: tar.TypeReg, content: "{}"}}), dest, nil); err != nil {
+		t.Fatalf("ExtractTarGz() error = %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "original" {
+		t.Errorf("file behind the symlink = %q, want it untouched", data)
+	}
+}
+
+func TestExtractTarGz_Limits(t *testing.T) {
+	defer limits.Store(nil)
+	defer func(grace int64) { ratioGrace = grace }(ratioGrace)
+	ratioGrace = 1 << 10
+
+	many := []entry{
+		{name: "a", typeflag: tar.TypeReg, content: "1"},
+		{name: "b", typeflag: tar.TypeReg, content: "2"},
+		{name: "c", typeflag: tar.TypeReg, content: "3"},
+	}
+	bomb := []entry{{name: "zeros.bin", typeflag: tar.TypeReg, content: strings.Repeat("\x00", 1<<20)}}
+	tests := []struct {
+		name    string
+		limits  Limits
+		entries []entry
+	}{
+		{"entries", Limits{MaxEntries: 2}, many},
+		{"size", Limits{MaxSize: 2}, many},
+		{"ratio", Limits{}, bomb},
+	}
+	for _, tt := range tests {
+		SetLimits(tt.limits)
+		err := ExtractTarGz(writeTarGz(t, tt.entries), t.TempDir(), nil)
+		if !errors.Is(err, ErrLimitExceeded) {
+			t.Errorf("%s: ExtractTarGz() error = %v, want ErrLimitExceeded", tt.name, err)
+		}
+	}
+
+	SetLimits(Limits{MaxEntries: 3, MaxSize: 3})
+	if err := ExtractTarGz(writeTarGz(t, many), t.TempDir(), nil); err != nil {
+		t.Errorf("ExtractTarGz() within the limits error = %v", err)
+	}
+}
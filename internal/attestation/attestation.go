@@ -0,0 +1,381 @@
+// Package attestation links a published package to the inputs it was built from.
+//
+// An attestation is an in-toto Statement whose subject is the package file and its
+// SHA256, with a SLSA provenance predicate naming the build inputs recorded in the
+// package's provenance.json: the source adapter, model and revision, the URL and digest
+// of every downloaded file, and the converter image. The statement is wrapped in a DSSE
+// envelope, signed with the same Ed25519 keys as packages (see internal/signing), so
+// it can be checked with the keys in security.trusted_keys.
+package attestation
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/signing"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// FileName is the attestation stored next to a published manifest
+const FileName = "attestation.json"
+
+// Formats of the statement and its envelope
+const (
+	StatementType = "https://in-toto.io/Statement/v1"
+	PredicateType = "https://slsa.dev/provenance/v1"
+	PayloadType   = "application/vnd.in-toto+json"
+	MediaType     = "application/vnd.dsse.envelope.v1+json"
+	BuildType     = "https://github.com/mlOS-foundation/axon/package/v1"
+	BuilderID     = "https://github.com/mlOS-foundation/axon"
+)
+
+// converterDependency names the converter image among the resolved dependencies
+const converterDependency = "converter"
+
+// Verification errors
+var (
+	ErrInvalid      = errors.New("invalid attestation")
+	ErrUnsigned     = errors.New("attestation is not signed")
+	ErrUntrusted    = errors.New("attestation is not signed by a trusted key")
+	ErrBadSignature = errors.New("attestation signature is invalid")
+	ErrMismatch     = errors.New("attestation does not match the package")
+)
+
+// Statement is an in-toto statement about a package
+type Statement struct {
+	Type          string     `json:"_type"`
+	Subject       []Subject  `json:"subject"`
+	PredicateType string     `json:"predicateType"`
+	Predicate     Provenance `json:"predicate"`
+}
+
+// Subject is an artifact the statement is about
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Provenance is the SLSA provenance predicate
+type Provenance struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+// BuildDefinition describes the inputs of the build
+type BuildDefinition struct {
+	BuildType            string               `json:"buildType"`
+	ExternalParameters   Parameters           `json:"externalParameters"`
+	ResolvedDependencies []ResourceDescriptor `json:"resolvedDependencies,omitempty"`
+}
+
+// Parameters are the build inputs: the model, where it came from and how it was converted
+type Parameters struct {
+	Model     string                 `json:"model"` // namespace/name@version
+	Source    types.ProvenanceSource `json:"source"`
+	Converter *ConverterParameters   `json:"converter,omitempty"`
+}
+
+// ConverterParameters identify the conversion of a converted package
+type ConverterParameters struct {
+	Method string `json:"method"`
+	Image  string `json:"image,omitempty"`
+	Opset  int    `json:"opset,omitempty"`
+}
+
+// ResourceDescriptor is an artifact the build used: a downloaded file or the converter image
+type ResourceDescriptor struct {
+	Name   string            `json:"name"`
+	URI    string            `json:"uri,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// RunDetails identify the builder
+type RunDetails struct {
+	Builder  Builder        `json:"builder"`
+	Metadata *BuildMetadata `json:"metadata,omitempty"`
+}
+
+// Builder is the tool that built the package
+type Builder struct {
+	ID      string            `json:"id"`
+	Version map[string]string `json:"version,omitempty"`
+}
+
+// BuildMetadata records when the package was built
+type BuildMetadata struct {
+	FinishedOn string `json:"finishedOn,omitempty"`
+}
+
+// Envelope is a DSSE envelope carrying a statement
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     string      `json:"payload"` // Base64 of the statement
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is a signature of an envelope
+type Signature struct {
+	KeyID string `json:"keyid"`
+	Sig   string `json:"sig"`
+}
+
+// New returns the statement that the package (by file name and SHA256) of model
+// (namespace/name@version) was built from the inputs in its provenance.json
+func New(model, packageName, packageSHA256 string, provenance *types.Provenance) *Statement {
+	predicate := Provenance{
+		BuildDefinition: BuildDefinition{
+			BuildType:            BuildType,
+			ExternalParameters:   parameters(model, provenance),
+			ResolvedDependencies: dependencies(provenance),
+		},
+		RunDetails: RunDetails{
+			Builder: Builder{ID: BuilderID, Version: map[string]string{provenance.Builder.Name: provenance.Builder.Version}},
+		},
+	}
+	if !provenance.BuiltAt.IsZero() {
+		predicate.RunDetails.Metadata = &BuildMetadata{FinishedOn: provenance.BuiltAt.UTC().Format("2006-01-02T15:04:05Z")}
+	}
+	return &Statement{
+		Type:          StatementType,
+		Subject:       []Subject{{Name: packageName, Digest: map[string]string{"sha256": strings.ToLower(packageSHA256)}}},
+		PredicateType: PredicateType,
+		Predicate:     predicate,
+	}
+}
+
+// parameters are the build inputs recorded in provenance
+func parameters(model string, provenance *types.Provenance) Parameters {
+	params := Parameters{Model: model, Source: provenance.Source}
+	if c := provenance.Converter; c != nil {
+		params.Converter = &ConverterParameters{Method: c.Method, Image: c.Image, Opset: c.Opset}
+	}
+	return params
+}
+
+// dependencies are the downloaded files and the converter image recorded in provenance
+func dependencies(provenance *types.Provenance) []ResourceDescriptor {
+	var deps []ResourceDescriptor
+	for _, file := range provenance.Files {
+		if file.URL == "" {
+			continue
+		}
+		deps = append(deps, ResourceDescriptor{Name: file.Path, URI: file.URL, Digest: map[string]string{"sha256": file.SHA256}})
+	}
+	if c := provenance.Converter; c != nil && (c.Image != "" || c.ImageDigest != "") {
+		image := ResourceDescriptor{Name: converterDependency, URI: c.Image}
+		// The image digest is a repository digest, e.g. "onnx-converter@sha256:<hex>"
+		if repo, digest, ok := strings.Cut(c.ImageDigest, "@"); ok {
+			image.URI = repo
+			if alg, hex, ok := strings.Cut(digest, ":"); ok {
+				image.Digest = map[string]string{alg: hex}
+			}
+		}
+		deps = append(deps, image)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+	return deps
+}
+
+// pae is the DSSE pre-authentication encoding of a payload: what is actually signed
+func pae(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// Seal wraps the statement in an envelope signed with key, or unsigned if key is nil
+func Seal(s *Statement, key ed25519.PrivateKey) (*Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement: %w", err)
+	}
+	envelope := &Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []Signature{},
+	}
+	if key == nil {
+		return envelope, nil
+	}
+	public, ok := key.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid signing key")
+	}
+	envelope.Signatures = append(envelope.Signatures, Signature{
+		KeyID: signing.KeyID(public),
+		Sig:   base64.StdEncoding.EncodeToString(ed25519.Sign(key, pae(PayloadType, payload))),
+	})
+	return envelope, nil
+}
+
+// Verify checks the envelope's signatures against the trusted keys and returns the ID of
+// the key that verified
+func (e *Envelope) Verify(trusted []ed25519.PublicKey) (string, error) {
+	if len(e.Signatures) == 0 {
+		return "", ErrUnsigned
+	}
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: payload is not base64", ErrInvalid)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(trusted))
+	for _, key := range trusted {
+		keys[signing.KeyID(key)] = key
+	}
+	message := pae(e.PayloadType, payload)
+	for _, signature := range e.Signatures {
+		key, ok := keys[signature.KeyID]
+		if !ok {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(signature.Sig)
+		if err != nil || !ed25519.Verify(key, message, raw) {
+			return "", fmt.Errorf("%w (key %s)", ErrBadSignature, signature.KeyID)
+		}
+		return signature.KeyID, nil
+	}
+	return "", ErrUntrusted
+}
+
+// Statement decodes the statement carried by the envelope. It doesn't check signatures.
+func (e *Envelope) Statement() (*Statement, error) {
+	if e.PayloadType != PayloadType {
+		return nil, fmt.Errorf("%w: payload type %q is not %s", ErrInvalid, e.PayloadType, PayloadType)
+	}
+	payload, err := base64.StdEncoding.DecodeString(e.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload is not base64", ErrInvalid)
+	}
+	var s Statement
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if s.Type != StatementType || s.PredicateType != PredicateType {
+		return nil, fmt.Errorf("%w: unsupported statement %s with predicate %s", ErrInvalid, s.Type, s.PredicateType)
+	}
+	if len(s.Subject) != 1 || s.Subject[0].Digest["sha256"] == "" {
+		return nil, fmt.Errorf("%w: statement must have one subject with a sha256 digest", ErrInvalid)
+	}
+	return &s, nil
+}
+
+// Parse decodes an envelope
+func Parse(data []byte) (*Envelope, error) {
+	var e Envelope
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	return &e, nil
+}
+
+// Read reads an envelope from a file
+func Read(path string) (*Envelope, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Write saves an envelope to a file
+func Write(path string, e *Envelope) error {
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode attestation: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write attestation: %w", err)
+	}
+	return nil
+}
+
+// Check validates the chain from the statement to a package: the subject is the package
+// digest, and the build inputs are the ones recorded in the package's provenance.json
+func Check(s *Statement, model, packageSHA256 string, provenance *types.Provenance) error {
+	if err := CheckSubject(s, packageSHA256); err != nil {
+		return err
+	}
+	return CheckInputs(s, model, provenance)
+}
+
+// CheckSubject checks that the statement is about the package with the given digest
+func CheckSubject(s *Statement, packageSHA256 string) error {
+	if got := s.Subject[0].Digest["sha256"]; !strings.EqualFold(got, packageSHA256) {
+		return fmt.Errorf("%w: attested sha256:%s, package is sha256:%s", ErrMismatch, got, packageSHA256)
+	}
+	return nil
+}
+
+// CheckInputs checks that the statement's build inputs are those of model in provenance
+func CheckInputs(s *Statement, model string, provenance *types.Provenance) error {
+	got, want := s.Predicate.BuildDefinition.ExternalParameters, parameters(model, provenance)
+	if got.Model != want.Model {
+		return fmt.Errorf("%w: attested model %s, expected %s", ErrMismatch, got.Model, want.Model)
+	}
+	if got.Source != want.Source {
+		return fmt.Errorf("%w: attested source %s, provenance records %s", ErrMismatch, describeSource(got.Source), describeSource(want.Source))
+	}
+	switch {
+	case got.Converter == nil && want.Converter == nil:
+	case got.Converter == nil || want.Converter == nil || *got.Converter != *want.Converter:
+		return fmt.Errorf("%w: attested converter %s, provenance records %s", ErrMismatch, describeConverter(got.Converter), describeConverter(want.Converter))
+	}
+
+	attested := make(map[string]ResourceDescriptor)
+	for _, dep := range s.Predicate.BuildDefinition.ResolvedDependencies {
+		attested[dep.Name] = dep
+	}
+	expected := dependencies(provenance)
+	for _, dep := range expected {
+		other, ok := attested[dep.Name]
+		if !ok {
+			return fmt.Errorf("%w: %s is not attested", ErrMismatch, dep.Name)
+		}
+		if other.URI != dep.URI || !sameDigest(other.Digest, dep.Digest) {
+			return fmt.Errorf("%w: attested %s is %s %v, provenance records %s %v", ErrMismatch, dep.Name, other.URI, other.Digest, dep.URI, dep.Digest)
+		}
+	}
+	if len(attested) != len(expected) {
+		return fmt.Errorf("%w: attestation lists %d build dependencies, provenance records %d", ErrMismatch, len(attested), len(expected))
+	}
+	return nil
+}
+
+// sameDigest reports whether two digest sets are equal, ignoring hex case
+func sameDigest(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for alg, value := range a {
+		if !strings.EqualFold(value, b[alg]) {
+			return false
+		}
+	}
+	return true
+}
+
+func describeSource(source types.ProvenanceSource) string {
+	if source.Adapter == "" && source.Model == "" {
+		return "(none)"
+	}
+	desc := source.Adapter + ":" + source.Model
+	if source.Revision != "" {
+		desc += "@" + source.Revision
+	}
+	return desc
+}
+
+func describeConverter(c *ConverterParameters) string {
+	if c == nil {
+		return "(none)"
+	}
+	if c.Image != "" {
+		return c.Method + " " + c.Image
+	}
+	return c.Method
+}
@@ -0,0 +1,150 @@
+package attestation
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/signing"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+const testDigest = "4f2e8a1c0b9d7e6f5a4b3c2d1e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f"
+
+func testProvenance() *types.Provenance {
+	return &types.Provenance{
+		SchemaVersion: types.ProvenanceSchemaVersion,
+		BuiltAt:       time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+		Builder:       types.ProvenanceBuilder{Name: "axon", Version: "1.4.0"},
+		Source:        types.ProvenanceSource{Adapter: "huggingface", Model: "bert-base-uncased", Repository: "https://huggingface.co", Revision: "86b5e09"},
+		Files: []types.ProvenanceFile{
+			{Path: "config.json", Size: 570, SHA256: "aa11", URL: "https://huggingface.co/bert-base-uncased/resolve/86b5e09/config.json"},
+			{Path: "model.safetensors", Size: 440, SHA256: "bb22", URL: "https://huggingface.co/bert-base-uncased/resolve/86b5e09/model.safetensors"},
+			{Path: "model.onnx", Size: 438, SHA256: "cc33"},
+		},
+		Converter: &types.ProvenanceConverter{Method: "docker", Image: "axon-converter:latest", ImageDigest: "axon-converter@sha256:dd44", Opset: 17},
+	}
+}
+
+func TestSealVerify(t *testing.T) {
+	key, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := signing.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := []ed25519.PublicKey{key.Public().(ed25519.PublicKey)}
+
+	envelope, err := Seal(New("hf/bert@1.0.0", "hf-bert-1.0.0.axon", testDigest, testProvenance()), key)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := Write(path, envelope); err != nil {
+		t.Fatal(err)
+	}
+	envelope, err = Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	keyID, err := envelope.Verify(trusted)
+	if err != nil || keyID != signing.KeyID(trusted[0]) {
+		t.Errorf("Verify() = %q, %v", keyID, err)
+	}
+	if _, err := envelope.Verify([]ed25519.PublicKey{other.Public().(ed25519.PublicKey)}); !errors.Is(err, ErrUntrusted) {
+		t.Errorf("Verify() with another key error = %v, want ErrUntrusted", err)
+	}
+
+	s, err := envelope.Statement()
+	if err != nil {
+		t.Fatalf("Statement() error = %v", err)
+	}
+	if s.Subject[0].Name != "hf-bert-1.0.0.axon" || s.Subject[0].Digest["sha256"] != testDigest {
+		t.Errorf("subject = %+v", s.Subject)
+	}
+
+	// Changing the statement, even with a valid encoding, breaks the signature
+	tampered := *envelope
+	payload, _ := base64.StdEncoding.DecodeString(envelope.Payload)
+	tampered.Payload = base64.StdEncoding.EncodeToString([]byte(strings.Replace(string(payload), "86b5e09", "0000000", 1)))
+	if _, err := tampered.Verify(trusted); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify() of a modified statement error = %v, want ErrBadSignature", err)
+	}
+
+	unsigned, err := Seal(s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := unsigned.Verify(trusted); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Verify() of an unsigned attestation error = %v, want ErrUnsigned", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	s := New("hf/bert@1.0.0", "hf-bert-1.0.0.axon", testDigest, testProvenance())
+	if deps := s.Predicate.BuildDefinition.ResolvedDependencies; len(deps) != 3 || deps[0].Name != "config.json" || deps[1].Name != converterDependency || deps[1].Digest["sha256"] != "dd44" {
+		t.Errorf("resolved dependencies = %+v; want the downloaded files and the converter image", deps)
+	}
+
+	tests := []struct {
+		name       string
+		model      string
+		digest     string
+		provenance func(*types.Provenance)
+		wantErr    string
+	}{
+		{name: "matching", model: "hf/bert@1.0.0", digest: strings.ToUpper(testDigest)},
+		{name: "other package", model: "hf/bert@1.0.0", digest: strings.Repeat("0", 64), wantErr: "attested sha256:"},
+		{name: "other model", model: "hf/bert@2.0.0", digest: testDigest, wantErr: "attested model hf/bert@1.0.0"},
+		{name: "other revision", model: "hf/bert@1.0.0", digest: testDigest, provenance: func(p *types.Provenance) { p.Source.Revision = "f00d" }, wantErr: "attested source huggingface:bert-base-uncased@86b5e09"},
+		{name: "other converter image", model: "hf/bert@1.0.0", digest: testDigest, provenance: func(p *types.Provenance) { p.Converter.ImageDigest = "axon-converter@sha256:ee55" }, wantErr: "attested converter"},
+		{name: "not converted", model: "hf/bert@1.0.0", digest: testDigest, provenance: func(p *types.Provenance) { p.Converter = nil }, wantErr: "attested converter docker"},
+		{name: "other file", model: "hf/bert@1.0.0", digest: testDigest, provenance: func(p *types.Provenance) { p.Files[1].SHA256 = "ff66" }, wantErr: "attested model.safetensors"},
+		{name: "extra file", model: "hf/bert@1.0.0", digest: testDigest, provenance: func(p *types.Provenance) {
+			p.Files = append(p.Files, types.ProvenanceFile{Path: "vocab.txt", SHA256: "0123", URL: "https://huggingface.co/vocab.txt"})
+		}, wantErr: "vocab.txt is not attested"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provenance := testProvenance()
+			if tt.provenance != nil {
+				tt.provenance(provenance)
+			}
+			err := Check(s, tt.model, tt.digest, provenance)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrMismatch) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Check() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStatement_Invalid(t *testing.T) {
+	s := New("hf/bert@1.0.0", "hf-bert-1.0.0.axon", testDigest, testProvenance())
+	s.PredicateType = "https://example.com/other"
+	envelope, err := Seal(s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := envelope.Statement(); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Statement() with another predicate error = %v, want ErrInvalid", err)
+	}
+
+	envelope.PayloadType = "text/plain"
+	if _, err := envelope.Statement(); !errors.Is(err, ErrInvalid) {
+		t.Errorf("Statement() with another payload type error = %v, want ErrInvalid", err)
+	}
+}
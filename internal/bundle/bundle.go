@@ -0,0 +1,237 @@
+// Package bundle reads and writes portable model bundles: self-contained archives of an
+// installed model's package, manifest and provenance that can be carried to machines
+// without network access (air-gapped clusters) and installed there.
+//
+// A bundle is an uncompressed tar archive (the package inside is already compressed).
+// Its first entry is bundle.json, an index naming the model and listing every other
+// entry with its size and SHA-256, so a bundle damaged in transit is rejected before
+// anything is installed.
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// FormatVersion is the bundle format written by this version of Axon
+const FormatVersion = 1
+
+// IndexFileName is the name of the bundle index, always the first entry
+const IndexFileName = "bundle.json"
+
+// Extension is the conventional file extension of bundles
+const Extension = ".axon.bundle"
+
+// Roles of the files in a bundle
+const (
+	RolePackage    = "package"
+	RoleManifest   = "manifest"
+	RoleProvenance = "provenance"
+)
+
+// ErrInvalid is returned for archives that are not well-formed bundles
+var ErrInvalid = errors.New("invalid bundle")
+
+// Entry describes a file in a bundle
+type Entry struct {
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Index is the contents of bundle.json
+type Index struct {
+	FormatVersion int       `json:"format_version"`
+	Model         string    `json:"model"` // namespace/name@version
+	CreatedAt     time.Time `json:"created_at"`
+	AxonVersion   string    `json:"axon_version,omitempty"`
+	Files         []Entry   `json:"files"`
+}
+
+// File returns the entry with the given role, if the bundle has one
+func (idx *Index) File(role string) (Entry, bool) {
+	for _, entry := range idx.Files {
+		if entry.Role == role {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Source is a file to add to a bundle
+type Source struct {
+	Role string
+	Path string // File on disk; its base name is the name in the bundle
+}
+
+// Create writes a bundle of the model's files to dest. The bundle is written to a
+// temporary file first, so dest is either complete or untouched.
+func Create(dest, model, axonVersion string, sources []Source) (*Index, error) {
+	index := &Index{
+		FormatVersion: FormatVersion,
+		Model:         model,
+		CreatedAt:     time.Now().UTC(),
+		AxonVersion:   axonVersion,
+	}
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		name := filepath.Base(source.Path)
+		if name == IndexFileName || seen[name] {
+			return nil, fmt.Errorf("duplicate bundle entry %s", name)
+		}
+		seen[name] = true
+		digest, err := utils.ComputeSHA256(source.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		info, err := os.Stat(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		index.Files = append(index.Files, Entry{Name: name, Role: source.Role, Size: info.Size(), SHA256: digest})
+	}
+	if _, ok := index.File(RoleManifest); !ok {
+		return nil, fmt.Errorf("a bundle needs a manifest")
+	}
+	if _, ok := index.File(RolePackage); !ok {
+		return nil, fmt.Errorf("a bundle needs a package")
+	}
+
+	tmp := dest + ".tmp"
+	if err := writeArchive(tmp, index, sources); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return index, nil
+}
+
+// writeArchive writes the index and the source files to a new tar archive at path
+func writeArchive(path string, index *Index, sources []Source) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	tw := tar.NewWriter(f)
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle index: %w", err)
+	}
+	if err := model.WriteTarEntry(tw, IndexFileName, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return err
+	}
+	for i, source := range sources {
+		if err := addFile(tw, index.Files[i], source.Path); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return f.Close()
+}
+
+// addFile copies a source file into the archive
+func addFile(tw *tar.Writer, entry Entry, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", entry.Name, err)
+	}
+	defer func() { _ = src.Close() }()
+	return model.WriteTarEntry(tw, entry.Name, entry.Size, src)
+}
+
+// Extract checks a bundle and unpacks its files into destDir, verifying each against the
+// index. It returns the index; the files are at destDir/<entry name>.
+func Extract(path, destDir string) (*Index, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundle: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	tr := tar.NewReader(f)
+	header, err := tr.Next()
+	if err != nil || header.Name != IndexFileName {
+		return nil, fmt.Errorf("%w: %s is not an Axon bundle (no %s)", ErrInvalid, filepath.Base(path), IndexFileName)
+	}
+	var index Index
+	if err := json.NewDecoder(io.LimitReader(tr, 1<<20)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("%w: unreadable %s: %v", ErrInvalid, IndexFileName, err)
+	}
+	if index.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("%w: format version %d is not supported (this Axon reads version %d)", ErrInvalid, index.FormatVersion, FormatVersion)
+	}
+
+	expected := make(map[string]Entry, len(index.Files))
+	for _, entry := range index.Files {
+		// Entries are plain file names, which also rules out path traversal
+		if entry.Name == "" || entry.Name != filepath.Base(entry.Name) || strings.HasPrefix(entry.Name, ".") {
+			return nil, fmt.Errorf("%w: bad entry name %q", ErrInvalid, entry.Name)
+		}
+		expected[entry.Name] = entry
+	}
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read bundle: %v", ErrInvalid, err)
+		}
+		entry, ok := expected[header.Name]
+		if !ok || header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalid, header.Name)
+		}
+		delete(expected, header.Name)
+		if err := extractEntry(tr, entry, filepath.Join(destDir, entry.Name)); err != nil {
+			return nil, err
+		}
+	}
+	for name := range expected {
+		return nil, fmt.Errorf("%w: %s is missing (truncated bundle?)", ErrInvalid, name)
+	}
+	return &index, nil
+}
+
+// extractEntry writes an entry to dest, checking its size and digest
+func extractEntry(r io.Reader, entry Entry, dest string) error {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+	}
+	defer func() { _ = out.Close() }()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hasher), io.LimitReader(r, entry.Size+1))
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+	}
+	if size != entry.Size {
+		return fmt.Errorf("%w: %s is %d bytes, index says %d", ErrInvalid, entry.Name, size, entry.Size)
+	}
+	if digest := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(digest, entry.SHA256) {
+		return fmt.Errorf("%w: %s checksum mismatch (expected sha256:%s, got sha256:%s)", ErrInvalid, entry.Name, entry.SHA256, digest)
+	}
+	return out.Close()
+}
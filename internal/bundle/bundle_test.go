@@ -0,0 +1,116 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSources creates the files of a test bundle and returns them as sources
+func writeSources(t *testing.T, dir string) []Source {
+	t.Helper()
+	files := []struct{ role, name, content string }{
+		{RoleManifest, "manifest.yaml", "kind: Model\n"},
+		{RolePackage, "hf-bert-latest.axon", "package bytes"},
+		{RoleProvenance, "provenance.json", "{}"},
+	}
+	var sources []Source
+	for _, file := range files {
+		path := filepath.Join(dir, file.name)
+		if err := os.WriteFile(path, []byte(file.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, Source{Role: file.role, Path: path})
+	}
+	return sources
+}
+
+// writeArchiveEntries writes a tar archive with the given entries in order
+func writeArchiveEntries(t *testing.T, path string, entries [][2]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: entry[0], Mode: 0644, Size: int64(len(entry[1])), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry[1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateExtract(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bert"+Extension)
+	created, err := Create(path, "hf/bert@latest", "1.7.0", writeSources(t, t.TempDir()))
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary bundle left behind")
+	}
+
+	dest := t.TempDir()
+	index, err := Extract(path, dest)
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if index.Model != "hf/bert@latest" || index.AxonVersion != "1.7.0" || len(index.Files) != len(created.Files) {
+		t.Errorf("index = %+v", index)
+	}
+	entry, ok := index.File(RolePackage)
+	if !ok {
+		t.Fatal("no package in index")
+	}
+	if data, err := os.ReadFile(filepath.Join(dest, entry.Name)); err != nil || string(data) != "package bytes" {
+		t.Errorf("package = %q, %v", data, err)
+	}
+
+	if _, err := Create(path, "hf/bert@latest", "", writeSources(t, t.TempDir())[:1]); err == nil {
+		t.Error("Create() without a package succeeded")
+	}
+}
+
+func TestExtract_Invalid(t *testing.T) {
+	index := func(files ...Entry) string {
+		data, _ := json.Marshal(Index{FormatVersion: FormatVersion, Model: "hf/bert@latest", Files: files})
+		return string(data)
+	}
+	pkg := Entry{Name: "model.axon", Role: RolePackage, Size: 3, SHA256: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"} // sha256("abc")
+
+	tests := []struct {
+		name    string
+		entries [][2]string
+		wantErr string
+	}{
+		{name: "no index", entries: [][2]string{{"model.axon", "abc"}}, wantErr: "not an Axon bundle"},
+		{name: "future format", entries: [][2]string{{IndexFileName, `{"format_version": 99}`}}, wantErr: "not supported"},
+		{name: "corrupted file", entries: [][2]string{{IndexFileName, index(pkg)}, {"model.axon", "abd"}}, wantErr: "checksum mismatch"},
+		{name: "truncated", entries: [][2]string{{IndexFileName, index(pkg)}}, wantErr: "missing"},
+		{name: "unlisted file", entries: [][2]string{{IndexFileName, index(pkg)}, {"model.axon", "abc"}, {"extra", "x"}}, wantErr: "unexpected entry"},
+		{name: "path traversal", entries: [][2]string{{IndexFileName, index(Entry{Name: "../model.axon", Role: RolePackage})}}, wantErr: "bad entry name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bad"+Extension)
+			writeArchiveEntries(t, path, tt.entries)
+			_, err := Extract(path, t.TempDir())
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Extract() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// constraintKey is the key of the version range a model was installed from in
+// .axon_metadata.json
+const constraintKey = "version_constraint"
+
+// ReadConstraint returns the version range, such as ^1.2, that the cached model at
+// modelPath was resolved from ("" if it was installed by version)
+func ReadConstraint(modelPath string) (string, error) {
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return "", err
+	}
+	raw, ok := metadata[constraintKey]
+	if !ok {
+		return "", nil
+	}
+	var constraint string
+	if err := json.Unmarshal(raw, &constraint); err != nil {
+		return "", fmt.Errorf("failed to parse version constraint in %s: %w", modelPath, err)
+	}
+	return constraint, nil
+}
+
+// WriteConstraint records in the metadata of the cached model at modelPath the version
+// range it was resolved from
+func WriteConstraint(modelPath, constraint string) error {
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(constraint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal version constraint: %w", err)
+	}
+	metadata[constraintKey] = raw
+	return writeMetadata(modelPath, metadata)
+}
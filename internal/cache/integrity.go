@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+)
+
+// IntegrityIndexFileName is the cache-wide integrity index, in the cache directory
+const IntegrityIndexFileName = "integrity.json"
+
+// integrityMu serializes updates of the integrity index by concurrent installs
+var integrityMu sync.Mutex
+
+// IntegrityRecord is what the integrity index knows about an installed model: its files
+// and their hashes when it was installed, independent of the files.json next to them
+type IntegrityRecord struct {
+	ModelID   string                 `json:"model_id"` // namespace/name@version
+	Path      string                 `json:"path"`
+	Digest    string                 `json:"digest"` // Content digest of Files
+	UpdatedAt string                 `json:"updated_at"`
+	Files     []model.InventoryEntry `json:"files"`
+}
+
+// IntegrityIndex records the files and hashes of every installed model, so a model can be
+// verified even when its directory or files.json was deleted or tampered with
+type IntegrityIndex struct {
+	path   string
+	Models []IntegrityRecord `json:"models"` // Sorted by model ID
+}
+
+// LoadIntegrityIndex reads the cache's integrity index (empty if it doesn't exist)
+func (cm *Manager) LoadIntegrityIndex() (*IntegrityIndex, error) {
+	index := &IntegrityIndex{path: filepath.Join(cm.cacheDir, IntegrityIndexFileName)}
+	data, err := os.ReadFile(index.path)
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read integrity index: %w", err)
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse integrity index: %w", err)
+	}
+	return index, nil
+}
+
+// Lookup returns the record of modelID, or nil
+func (x *IntegrityIndex) Lookup(modelID string) *IntegrityRecord {
+	for i := range x.Models {
+		if x.Models[i].ModelID == modelID {
+			return &x.Models[i]
+		}
+	}
+	return nil
+}
+
+// Record adds or replaces the record of rec.ModelID
+func (x *IntegrityIndex) Record(rec IntegrityRecord) {
+	if rec.UpdatedAt == "" {
+		rec.UpdatedAt = time.Now().Format(time.RFC3339)
+	}
+	if existing := x.Lookup(rec.ModelID); existing != nil {
+		*existing = rec
+		return
+	}
+	x.Models = append(x.Models, rec)
+	sort.Slice(x.Models, func(i, j int) bool { return x.Models[i].ModelID < x.Models[j].ModelID })
+}
+
+// Remove drops the record of modelID, reporting whether there was one
+func (x *IntegrityIndex) Remove(modelID string) bool {
+	for i := range x.Models {
+		if x.Models[i].ModelID == modelID {
+			x.Models = append(x.Models[:i], x.Models[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the integrity index atomically
+func (x *IntegrityIndex) Save() error {
+	data, err := json.MarshalIndent(x, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal integrity index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(x.path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp := x.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write integrity index: %w", err)
+	}
+	if err := os.Rename(tmp, x.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write integrity index: %w", err)
+	}
+	return nil
+}
+
+// RecordIntegrity records the inventory of the cached model at modelPath in the integrity
+// index. Directories that aren't models of this cache, such as copies, aren't recorded.
+func (cm *Manager) RecordIntegrity(modelPath string, inventory *model.Inventory) error {
+	ref, ok := readModelRef(modelPath)
+	if !ok || filepath.Clean(modelPath) != cm.GetModelPath(ref.Namespace, ref.Name, ref.Version) {
+		return nil
+	}
+	return cm.updateIntegrityIndex(func(index *IntegrityIndex) bool {
+		index.Record(IntegrityRecord{
+			ModelID: ref.String(),
+			Path:    modelPath,
+			Digest:  inventory.Digest(),
+			Files:   inventory.Files,
+		})
+		return true
+	})
+}
+
+// ForgetIntegrity drops a model from the integrity index, e.g. once it is uninstalled
+func (cm *Manager) ForgetIntegrity(modelID string) error {
+	return cm.updateIntegrityIndex(func(index *IntegrityIndex) bool {
+		return index.Remove(modelID)
+	})
+}
+
+// updateIntegrityIndex loads the integrity index, applies update and saves the index if
+// update changed it
+func (cm *Manager) updateIntegrityIndex(update func(index *IntegrityIndex) bool) error {
+	integrityMu.Lock()
+	defer integrityMu.Unlock()
+	index, err := cm.LoadIntegrityIndex()
+	if err != nil {
+		return err
+	}
+	if !update(index) {
+		return nil
+	}
+	return index.Save()
+}
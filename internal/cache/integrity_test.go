@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestIntegrityIndex(t *testing.T) {
+	cm := NewManager(t.TempDir())
+	for _, name := range []string{"gpt2", "bert"} {
+		if err := cm.CacheModel("hf", name, "latest", &types.Manifest{}); err != nil {
+			t.Fatal(err)
+		}
+		path := cm.GetModelPath("hf", name, "latest")
+		if err := os.WriteFile(filepath.Join(path, "model.bin"), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+		inventory, err := model.WriteInventory(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cm.RecordIntegrity(path, inventory); err != nil {
+			t.Fatalf("RecordIntegrity() error = %v", err)
+		}
+	}
+
+	// Copies of cached models aren't recorded
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, ".axon_metadata.json"), []byte(`{"namespace":"hf","name":"t5","version":"latest"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.RecordIntegrity(outside, &model.Inventory{}); err != nil {
+		t.Fatalf("RecordIntegrity() of a copy error = %v", err)
+	}
+
+	index, err := cm.LoadIntegrityIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Models) != 2 || index.Models[0].ModelID != "hf/bert@latest" || index.Models[1].ModelID != "hf/gpt2@latest" {
+		t.Fatalf("index models = %+v, want bert and gpt2 in order", index.Models)
+	}
+	rec := index.Lookup("hf/gpt2@latest")
+	if rec.Path != cm.GetModelPath("hf", "gpt2", "latest") || len(rec.Files) != 1 || rec.Files[0].Path != "model.bin" || rec.Digest == "" || rec.UpdatedAt == "" {
+		t.Errorf("gpt2 record = %+v", rec)
+	}
+
+	// The index outlives files.json
+	if err := os.Remove(filepath.Join(rec.Path, model.InventoryFileName)); err != nil {
+		t.Fatal(err)
+	}
+	if problems := model.VerifyEntries(rec.Path, rec.Files, nil); len(problems) != 0 {
+		t.Errorf("VerifyEntries() of intact files = %v", problems)
+	}
+
+	if err := cm.ForgetIntegrity("hf/gpt2@latest"); err != nil {
+		t.Fatalf("ForgetIntegrity() error = %v", err)
+	}
+	if err := cm.ForgetIntegrity("hf/unknown@latest"); err != nil {
+		t.Fatalf("ForgetIntegrity() of an unindexed model error = %v", err)
+	}
+	index, err = cm.LoadIntegrityIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Models) != 1 || index.Lookup("hf/gpt2@latest") != nil {
+		t.Errorf("index models after forgetting gpt2 = %+v", index.Models)
+	}
+}
+
+func TestLoadIntegrityIndex_Missing(t *testing.T) {
+	index, err := NewManager(t.TempDir()).LoadIntegrityIndex()
+	if err != nil || len(index.Models) != 0 {
+		t.Errorf("LoadIntegrityIndex() = %+v, %v, want an empty index", index, err)
+	}
+}
@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/mlOS-foundation/axon/pkg/spec"
 	"github.com/mlOS-foundation/axon/pkg/types"
 )
 
@@ -27,7 +29,12 @@ func NewManager(cacheDir string) *Manager {
 
 // GetModelPath returns the cached path for a model
 func (cm *Manager) GetModelPath(namespace, name, version string) string {
-	return filepath.Join(cm.cacheDir, "models", namespace, name, version)
+	return spec.NewModelRef(namespace, name, version).Path(cm.modelsDir())
+}
+
+// modelsDir is the directory the model tree is under
+func (cm *Manager) modelsDir() string {
+	return filepath.Join(cm.cacheDir, "models")
 }
 
 // IsModelCached checks if a model is already cached
@@ -47,17 +54,16 @@ func (cm *Manager) GetCachedManifest(namespace, name, version string) (*types.Ma
 	path := cm.GetModelPath(namespace, name, version)
 	manifestPath := filepath.Join(path, "manifest.yaml")
 
-	// Import here to avoid circular dependency
-	// We'll use a simple approach for now
 	data, err := os.ReadFile(manifestPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read manifest: %w", err)
 	}
 
-	// Parse using the manifest package
-	// For now, return error - this will be implemented when we wire everything together
-	_ = data
-	return nil, fmt.Errorf("manifest parsing not yet integrated")
+	var manifest types.Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
 }
 
 // CacheModel caches a model package
@@ -83,7 +89,7 @@ func (cm *Manager) CacheModel(namespace, name, version string, manifest *types.M
 	// Save metadata
 	metadataPath := filepath.Join(path, ".axon_metadata.json")
 	metadata := map[string]interface{}{
-		"installed_at": time.Now().Format(time.RFC3339),
+		installedAtKey: time.Now().Format(time.RFC3339),
 		"namespace":    namespace,
 		"name":         name,
 		"version":      version,
@@ -101,6 +107,23 @@ func (cm *Manager) CacheModel(namespace, name, version string, manifest *types.M
 	return nil
 }
 
+// GetPackagePath returns the path to the .axon package stored for a cached model
+func (cm *Manager) GetPackagePath(namespace, name, version string) (string, error) {
+	path := cm.GetModelPath(namespace, name, version)
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read model directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".axon") {
+			return filepath.Join(path, entry.Name()), nil
+		}
+	}
+
+	return "", fmt.Errorf("no .axon package found for %s/%s@%s", namespace, name, version)
+}
+
 // RemoveModel removes a cached model
 func (cm *Manager) RemoveModel(namespace, name, version string) error {
 	path := cm.GetModelPath(namespace, name, version)
@@ -109,8 +132,36 @@ func (cm *Manager) RemoveModel(namespace, name, version string) error {
 
 // GetCacheSize returns total cache size in bytes
 func (cm *Manager) GetCacheSize() (int64, error) {
+	return dirSize(cm.cacheDir)
+}
+
+// GetModelSize returns the disk usage of a cached model version in bytes
+func (cm *Manager) GetModelSize(namespace, name, version string) (int64, error) {
+	return dirSize(cm.GetModelPath(namespace, name, version))
+}
+
+// GetNamespaceSize returns the disk usage of every cached model in a namespace in bytes
+func (cm *Manager) GetNamespaceSize(namespace string) (int64, error) {
+	size, err := dirSize(filepath.Join(cm.modelsDir(), spec.PathSegment(namespace)))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// GetModelsSize returns the disk usage of every cached model in bytes
+func (cm *Manager) GetModelsSize() (int64, error) {
+	size, err := dirSize(cm.modelsDir())
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	return size, err
+}
+
+// dirSize returns the total size of the files under dir
+func dirSize(dir string) (int64, error) {
 	var size int64
-	err := filepath.Walk(cm.cacheDir, func(path string, info os.FileInfo, err error) error {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -124,7 +175,7 @@ func (cm *Manager) GetCacheSize() (int64, error) {
 
 // ListCachedModels lists all cached models
 func (cm *Manager) ListCachedModels() ([]CachedModel, error) {
-	modelsDir := filepath.Join(cm.cacheDir, "models")
+	modelsDir := cm.modelsDir()
 	var models []CachedModel
 
 	if _, err := os.Stat(modelsDir); os.IsNotExist(err) {
@@ -172,11 +223,21 @@ func (cm *Manager) ListCachedModels() ([]CachedModel, error) {
 				// Join all parts between namespace and version as the name
 				name := filepath.Join(parts[1 : len(parts)-1]...)
 
+				// The metadata records the model's ID, which the path encodes. Entries whose
+				// path doesn't match it (older layouts, moved by hand) go by their path.
+				ref := spec.NewModelRef(namespace, filepath.ToSlash(name), version)
+				if recorded, ok := readModelRef(filepath.Dir(path)); ok && cm.GetModelPath(recorded.Namespace, recorded.Name, recorded.Version) == filepath.Dir(path) {
+					ref = recorded
+				}
+
+				// Unreadable registration state doesn't hide the model
+				registration, _ := ReadRegistration(filepath.Dir(path))
 				models = append(models, CachedModel{
-					Namespace: namespace,
-					Name:      name,
-					Version:   version,
-					Path:      filepath.Dir(path),
+					Namespace:    ref.Namespace,
+					Name:         filepath.FromSlash(ref.Name),
+					Version:      ref.Version,
+					Path:         filepath.Dir(path),
+					Registration: registration,
 				})
 			}
 		}
@@ -189,10 +250,11 @@ func (cm *Manager) ListCachedModels() ([]CachedModel, error) {
 
 // CachedModel represents a cached model
 type CachedModel struct {
-	Namespace string
-	Name      string
-	Version   string
-	Path      string
+	Namespace    string
+	Name         string
+	Version      string
+	Path         string
+	Registration *CoreRegistration `json:",omitempty"` // nil when not registered with MLOS Core
 }
 
 // CleanPolicy defines cache cleanup policies
@@ -0,0 +1,211 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// namespaceAliases maps alternate namespace spellings accepted by the adapters
+// to the canonical namespace used in the cache layout
+var namespaceAliases = map[string]string{
+	"huggingface": "hf",
+	"torch":       "pytorch",
+	"modelscope":  "ms",
+	"tf":          "tfhub",
+	"tensorflow":  "tfhub",
+}
+
+// CanonicalNamespace returns the canonical form of a repository namespace
+func CanonicalNamespace(namespace string) string {
+	lower := strings.ToLower(namespace)
+	if canonical, ok := namespaceAliases[lower]; ok {
+		return canonical
+	}
+	return namespace
+}
+
+// CanonicalModelName undoes the ways older layouts flattened multi-part model names
+// into a single directory: URL-encoded slashes (microsoft%2Fresnet-50) and the
+// Hugging Face hub cache form (models--microsoft--resnet-50).
+func CanonicalModelName(name string) string {
+	if decoded := strings.ReplaceAll(strings.ReplaceAll(name, "%2F", "/"), "%2f", "/"); decoded != name {
+		return decoded
+	}
+	if strings.Contains(name, "--") {
+		return strings.TrimPrefix(strings.ReplaceAll(name, "--", "/"), "models/")
+	}
+	return name
+}
+
+// Migration describes moving a cached model to its canonical location
+type Migration struct {
+	From     CachedModel
+	To       CachedModel
+	Conflict bool // A model is already cached at the target location
+}
+
+// PlanNamespaceMigration lists the cached models that are not stored under their canonical
+// namespace/name. renames maps additional namespaces to rename (old -> new), applied
+// before the built-in aliases.
+func (cm *Manager) PlanNamespaceMigration(renames map[string]string) ([]Migration, error) {
+	models, err := cm.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cached models: %w", err)
+	}
+
+	var plan []Migration
+	for _, m := range models {
+		namespace := m.Namespace
+		if renamed, ok := renames[namespace]; ok {
+			namespace = renamed
+		}
+		namespace = CanonicalNamespace(namespace)
+		name := cm.canonicalNameFor(m)
+
+		if namespace == m.Namespace && name == m.Name {
+			continue
+		}
+
+		target := CachedModel{
+			Namespace: namespace,
+			Name:      name,
+			Version:   m.Version,
+			Path:      cm.GetModelPath(namespace, name, m.Version),
+		}
+		_, statErr := os.Stat(target.Path)
+		plan = append(plan, Migration{From: m, To: target, Conflict: statErr == nil})
+	}
+	return plan, nil
+}
+
+// canonicalNameFor returns the canonical name of a cached model, preferring the
+// multi-part name recorded in its manifest over what can be decoded from the path
+func (cm *Manager) canonicalNameFor(m CachedModel) string {
+	name := filepath.ToSlash(m.Name)
+	if manifest, err := readManifest(filepath.Join(m.Path, "manifest.yaml")); err == nil {
+		if recorded := manifest.Metadata.Name; recorded != name && isFlattenedName(name, recorded) {
+			return recorded
+		}
+	}
+	return CanonicalModelName(name)
+}
+
+// isFlattenedName reports whether name is the multi-part name recorded with its
+// slashes replaced by one of the separators older layouts used
+func isFlattenedName(name, recorded string) bool {
+	if !strings.Contains(recorded, "/") {
+		return false
+	}
+	for _, sep := range []string{"_", "--", "%2F", "%2f", "-"} {
+		if strings.ReplaceAll(recorded, "/", sep) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// MigrateModel moves a cached model to its canonical location without re-downloading it,
+// rewriting the namespace and name recorded in its manifest and metadata
+func (cm *Manager) MigrateModel(migration Migration) error {
+	if migration.Conflict {
+		return fmt.Errorf("%s/%s@%s is already cached; remove one copy first", migration.To.Namespace, migration.To.Name, migration.To.Version)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(migration.To.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := os.Rename(migration.From.Path, migration.To.Path); err != nil {
+		return fmt.Errorf("failed to move cache entry: %w", err)
+	}
+
+	if err := rewriteMetadata(migration); err != nil {
+		return err
+	}
+	if err := rewriteManifest(migration); err != nil {
+		return err
+	}
+
+	cm.removeEmptyParents(filepath.Dir(migration.From.Path))
+	return nil
+}
+
+// rewriteMetadata updates .axon_metadata.json in the migrated directory, keeping other fields
+func rewriteMetadata(migration Migration) error {
+	metadataPath := filepath.Join(migration.To.Path, ".axon_metadata.json")
+	metadata := map[string]interface{}{}
+	if data, err := os.ReadFile(metadataPath); err == nil {
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return fmt.Errorf("failed to parse metadata: %w", err)
+		}
+	}
+
+	metadata["namespace"] = migration.To.Namespace
+	metadata["name"] = migration.To.Name
+	metadata["migrated_from"] = fmt.Sprintf("%s/%s", migration.From.Namespace, filepath.ToSlash(migration.From.Name))
+	metadata["migrated_at"] = time.Now().Format(time.RFC3339)
+
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.WriteFile(metadataPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// rewriteManifest updates the namespace and name in the migrated manifest.yaml, if any
+func rewriteManifest(migration Migration) error {
+	manifestPath := filepath.Join(migration.To.Path, "manifest.yaml")
+	manifest, err := readManifest(manifestPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	manifest.Metadata.Namespace = migration.To.Namespace
+	manifest.Metadata.Name = migration.To.Name
+
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// readManifest parses a manifest.yaml from disk
+func readManifest(path string) (*types.Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest types.Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// removeEmptyParents removes dir and its ancestors while they are empty, stopping at the models directory
+func (cm *Manager) removeEmptyParents(dir string) {
+	modelsDir := filepath.Join(cm.cacheDir, "models")
+	for dir != modelsDir && strings.HasPrefix(dir, modelsDir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
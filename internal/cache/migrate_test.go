@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestCanonicalModelName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"bert-base-uncased", "bert-base-uncased"},
+		{"microsoft/resnet-50", "microsoft/resnet-50"},
+		{"microsoft%2Fresnet-50", "microsoft/resnet-50"},
+		{"models--microsoft--resnet-50", "microsoft/resnet-50"},
+		{"microsoft--resnet-50", "microsoft/resnet-50"},
+	}
+
+	for _, tt := range tests {
+		if got := CanonicalModelName(tt.name); got != tt.want {
+			t.Errorf("CanonicalModelName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateNamespace(t *testing.T) {
+	cacheDir := t.TempDir()
+	mgr := NewManager(cacheDir)
+
+	// An alias namespace with a name flattened by an older layout
+	mustCache := func(namespace, name, recordedName string) {
+		t.Helper()
+		manifest := &types.Manifest{Metadata: types.Metadata{Namespace: namespace, Name: recordedName}}
+		if err := mgr.CacheModel(namespace, name, "latest", manifest); err != nil {
+			t.Fatal(err)
+		}
+		pkg := filepath.Join(mgr.GetModelPath(namespace, name, "latest"), "model.axon")
+		if err := os.WriteFile(pkg, []byte("package"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustCache("huggingface", "microsoft_resnet-50", "microsoft/resnet-50")
+	mustCache("hf", "bert-base-uncased", "bert-base-uncased")
+	mustCache("torch", "resnet50", "resnet50")
+	mustCache("pytorch", "resnet50", "resnet50")
+
+	plan, err := mgr.PlanNamespaceMigration(nil)
+	if err != nil {
+		t.Fatalf("PlanNamespaceMigration() error = %v", err)
+	}
+	if len(plan) != 2 {
+		t.Fatalf("PlanNamespaceMigration() = %d migrations, want 2: %+v", len(plan), plan)
+	}
+
+	for _, migration := range plan {
+		err := mgr.MigrateModel(migration)
+		switch migration.From.Namespace {
+		case "huggingface":
+			if err != nil {
+				t.Fatalf("MigrateModel() error = %v", err)
+			}
+		case "torch":
+			if !migration.Conflict || err == nil {
+				t.Errorf("MigrateModel() onto an existing entry should fail, conflict=%v err=%v", migration.Conflict, err)
+			}
+		}
+	}
+
+	if !mgr.IsModelCached("hf", "microsoft/resnet-50", "latest") {
+		t.Fatal("model not found at canonical location after migration")
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "models", "huggingface")); !os.IsNotExist(err) {
+		t.Errorf("old namespace directory should be removed, stat err = %v", err)
+	}
+	if _, err := mgr.GetPackagePath("hf", "microsoft/resnet-50", "latest"); err != nil {
+		t.Errorf("package should move with the model: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(mgr.GetModelPath("hf", "microsoft/resnet-50", "latest"), "manifest.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var manifest types.Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Metadata.Namespace != "hf" || manifest.Metadata.Name != "microsoft/resnet-50" {
+		t.Errorf("manifest metadata = %s/%s, want hf/microsoft/resnet-50", manifest.Metadata.Namespace, manifest.Metadata.Name)
+	}
+
+	// Explicit renames are applied on top of the built-in aliases
+	mustCache("hugging", "gpt2", "gpt2")
+	plan, err = mgr.PlanNamespaceMigration(map[string]string{"hugging": "hf"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var renamed bool
+	for _, migration := range plan {
+		if migration.From.Namespace == "hugging" && migration.To.Namespace == "hf" && migration.To.Name == "gpt2" {
+			renamed = true
+		}
+	}
+	if !renamed {
+		t.Errorf("PlanNamespaceMigration() with rename = %+v, want hugging/gpt2 -> hf/gpt2", plan)
+	}
+}
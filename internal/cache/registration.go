@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// registrationKey is the key of the MLOS Core registration in .axon_metadata.json
+const registrationKey = "registration"
+
+// CoreRegistration records that a cached model is registered with MLOS Core
+type CoreRegistration struct {
+	Endpoint     string `json:"endpoint"`
+	ModelID      string `json:"model_id"` // ID of the model in Core, with the tenant prefix if any
+	RegisteredAt string `json:"registered_at"`
+}
+
+// ReadRegistration returns the MLOS Core registration recorded in the metadata of the
+// cached model at modelPath (nil if it is not registered)
+func ReadRegistration(modelPath string) (*CoreRegistration, error) {
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := metadata[registrationKey]
+	if !ok || string(raw) == "null" {
+		return nil, nil
+	}
+	var reg CoreRegistration
+	if err := json.Unmarshal(raw, &reg); err != nil {
+		return nil, fmt.Errorf("failed to parse registration in %s: %w", modelPath, err)
+	}
+	return &reg, nil
+}
+
+// WriteRegistration records the MLOS Core registration of the cached model at modelPath
+// in its metadata, or removes the record when reg is nil
+func WriteRegistration(modelPath string, reg *CoreRegistration) error {
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return err
+	}
+	if reg == nil {
+		delete(metadata, registrationKey)
+	} else {
+		raw, err := json.Marshal(reg)
+		if err != nil {
+			return fmt.Errorf("failed to marshal registration: %w", err)
+		}
+		metadata[registrationKey] = raw
+	}
+	return writeMetadata(modelPath, metadata)
+}
+
+// readMetadata reads .axon_metadata.json of the cached model at modelPath, keeping every
+// field as is (empty if the file doesn't exist)
+func readMetadata(modelPath string) (map[string]json.RawMessage, error) {
+	metadata := map[string]json.RawMessage{}
+	data, err := os.ReadFile(filepath.Join(modelPath, ".axon_metadata.json"))
+	if os.IsNotExist(err) {
+		return metadata, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// writeMetadata writes .axon_metadata.json of the cached model at modelPath
+func writeMetadata(modelPath string, metadata map[string]json.RawMessage) error {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(modelPath, ".axon_metadata.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to write metadata: %w", err)
+	}
+	return nil
+}
+
+// readModelRef returns the model ID recorded in the metadata of the cached model at
+// modelPath
+func readModelRef(modelPath string) (spec.ModelRef, bool) {
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return spec.ModelRef{}, false
+	}
+	var ref spec.ModelRef
+	for key, field := range map[string]*string{"namespace": &ref.Namespace, "name": &ref.Name, "version": &ref.Version} {
+		if json.Unmarshal(metadata[key], field) != nil || *field == "" {
+			return spec.ModelRef{}, false
+		}
+	}
+	return ref, true
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestRegistrationState(t *testing.T) {
+	cm := NewManager(t.TempDir())
+	if err := cm.CacheModel("hf", "bert", "latest", &types.Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	path := cm.GetModelPath("hf", "bert", "latest")
+
+	if reg, err := ReadRegistration(path); err != nil || reg != nil {
+		t.Fatalf("ReadRegistration() of an unregistered model = %+v, %v", reg, err)
+	}
+
+	want := CoreRegistration{Endpoint: "http://localhost:8080", ModelID: "hf/bert@latest", RegisteredAt: "2026-05-01T12:00:00Z"}
+	if err := WriteRegistration(path, &want); err != nil {
+		t.Fatalf("WriteRegistration() error = %v", err)
+	}
+	models, err := cm.ListCachedModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Registration == nil || *models[0].Registration != want {
+		t.Errorf("ListCachedModels() = %+v, want the registration", models)
+	}
+
+	// The other metadata is kept
+	data, err := os.ReadFile(filepath.Join(path, ".axon_metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata["installed_at"] == nil || metadata["namespace"] != "hf" {
+		t.Errorf("metadata = %v, want the install metadata kept", metadata)
+	}
+
+	if err := WriteRegistration(path, nil); err != nil {
+		t.Fatalf("WriteRegistration(nil) error = %v", err)
+	}
+	if reg, err := ReadRegistration(path); err != nil || reg != nil {
+		t.Errorf("ReadRegistration() after clearing = %+v, %v", reg, err)
+	}
+}
+
+func TestListCachedModels_RecordedRef(t *testing.T) {
+	cm := NewManager(t.TempDir())
+	// Spaces and colons aren't kept in the path; the metadata maps it back
+	if err := cm.CacheModel("url", "https/models.internal/my model.onnx", "v1:beta", &types.Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	path := cm.GetModelPath("url", "https/models.internal/my model.onnx", "v1:beta")
+	if want := filepath.Join(cm.modelsDir(), "url", "https", "models.internal", "my_model.onnx", "v1_beta"); path != want {
+		t.Errorf("GetModelPath() = %s, want %s", path, want)
+	}
+
+	models, err := cm.ListCachedModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Name != filepath.FromSlash("https/models.internal/my model.onnx") || models[0].Version != "v1:beta" || models[0].Path != path {
+		t.Errorf("ListCachedModels() = %+v, want the recorded name and version", models)
+	}
+	if !cm.IsModelCached(models[0].Namespace, models[0].Name, models[0].Version) {
+		t.Error("listed model is not cached under its own ID")
+	}
+}
@@ -0,0 +1,244 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// DefaultSearchTTL is how long cached search results are used before searching again
+const DefaultSearchTTL = time.Hour
+
+// SearchIndexFileName is the offline index of the models searches have seen, in the
+// search cache directory
+const SearchIndexFileName = "index.json"
+
+// maxSearchIndexEntries caps the offline index; the models seen longest ago are dropped
+const maxSearchIndexEntries = 50000
+
+// searchIndexMu serializes updates of the offline index by concurrent searches
+var searchIndexMu sync.Mutex
+
+// SearchCache keeps the results of repository searches so repeated searches don't go
+// to the repository each time, and indexes every model they have seen for offline
+// searches.
+//
+// Layout:
+//
+//	<cache>/search/results/<key>.json   the results of one query to one adapter
+//	<cache>/search/index.json           every model seen, by adapter
+type SearchCache struct {
+	dir string
+	ttl time.Duration
+}
+
+// SearchCache returns the search cache of the cache directory, whose results are used
+// for ttl (0 = DefaultSearchTTL, negative = not at all)
+func (cm *Manager) SearchCache(ttl time.Duration) *SearchCache {
+	if ttl == 0 {
+		ttl = DefaultSearchTTL
+	}
+	return &SearchCache{dir: filepath.Join(cm.cacheDir, "search"), ttl: ttl}
+}
+
+// cachedSearch is the file of one cached query
+type cachedSearch struct {
+	Adapter   string               `json:"adapter"`
+	Query     string               `json:"query"`
+	FetchedAt time.Time            `json:"fetched_at"`
+	Results   []types.SearchResult `json:"results"`
+}
+
+// SearchIndexEntry is a model in the offline index
+type SearchIndexEntry struct {
+	Adapter string             `json:"adapter"`
+	Model   types.SearchResult `json:"model"`
+	SeenAt  time.Time          `json:"seen_at"`
+}
+
+// searchIndex is the contents of the offline index
+type searchIndex struct {
+	Models []SearchIndexEntry `json:"models"`
+}
+
+// resultPath returns the file of the results of query to adapter
+func (sc *SearchCache) resultPath(adapter, query string) string {
+	sum := sha256.Sum256([]byte(adapter + "\x00" + strings.TrimSpace(query)))
+	return filepath.Join(sc.dir, "results", hex.EncodeToString(sum[:16])+".json")
+}
+
+// Lookup returns the cached results of query to adapter and when they were fetched, if
+// they are younger than the TTL
+func (sc *SearchCache) Lookup(adapter, query string) ([]types.SearchResult, time.Time, bool) {
+	if sc.ttl < 0 {
+		return nil, time.Time{}, false
+	}
+	data, err := os.ReadFile(sc.resultPath(adapter, query))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var cached cachedSearch
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Adapter != adapter || cached.Query != strings.TrimSpace(query) {
+		return nil, time.Time{}, false
+	}
+	if time.Since(cached.FetchedAt) > sc.ttl {
+		return nil, time.Time{}, false
+	}
+	return cached.Results, cached.FetchedAt, true
+}
+
+// Store caches the results of query to adapter and adds the models to the offline index
+func (sc *SearchCache) Store(adapter, query string, results []types.SearchResult) error {
+	now := time.Now().UTC()
+	if sc.ttl >= 0 {
+		cached := cachedSearch{Adapter: adapter, Query: strings.TrimSpace(query), FetchedAt: now, Results: results}
+		if cached.Results == nil {
+			cached.Results = []types.SearchResult{}
+		}
+		if err := writeJSONAtomic(sc.resultPath(adapter, query), &cached); err != nil {
+			return fmt.Errorf("failed to cache search results: %w", err)
+		}
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	searchIndexMu.Lock()
+	defer searchIndexMu.Unlock()
+	index, err := sc.readIndex()
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]int, len(index.Models))
+	for i, entry := range index.Models {
+		seen[indexKey(entry.Adapter, entry.Model)] = i
+	}
+	for _, result := range results {
+		entry := SearchIndexEntry{Adapter: adapter, Model: result, SeenAt: now}
+		if i, ok := seen[indexKey(adapter, result)]; ok {
+			index.Models[i] = entry
+			continue
+		}
+		seen[indexKey(adapter, result)] = len(index.Models)
+		index.Models = append(index.Models, entry)
+	}
+	if len(index.Models) > maxSearchIndexEntries {
+		sort.SliceStable(index.Models, func(i, j int) bool { return index.Models[i].SeenAt.After(index.Models[j].SeenAt) })
+		index.Models = index.Models[:maxSearchIndexEntries]
+	}
+	sort.SliceStable(index.Models, func(i, j int) bool {
+		return indexKey(index.Models[i].Adapter, index.Models[i].Model) < indexKey(index.Models[j].Adapter, index.Models[j].Model)
+	})
+	if err := writeJSONAtomic(filepath.Join(sc.dir, SearchIndexFileName), index); err != nil {
+		return fmt.Errorf("failed to write search index: %w", err)
+	}
+	return nil
+}
+
+// indexKey identifies a model of an adapter in the offline index
+func indexKey(adapter string, result types.SearchResult) string {
+	return adapter + "\x00" + result.Namespace + "/" + result.Name
+}
+
+// readIndex reads the offline index (empty if it doesn't exist)
+func (sc *SearchCache) readIndex() (*searchIndex, error) {
+	index := &searchIndex{Models: []SearchIndexEntry{}}
+	data, err := os.ReadFile(filepath.Join(sc.dir, SearchIndexFileName))
+	if os.IsNotExist(err) {
+		return index, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search index: %w", err)
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse search index: %w", err)
+	}
+	return index, nil
+}
+
+// SearchOffline searches the models earlier searches have seen, and the installed
+// models, without contacting any repository. Every word of the query must appear in a
+// model's namespace/name, description, framework or tags (case-insensitively).
+func (sc *SearchCache) SearchOffline(cm *Manager, query string) ([]types.SearchResult, error) {
+	index, err := sc.readIndex()
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]types.SearchResult, 0, len(index.Models))
+	for _, entry := range index.Models {
+		candidates = append(candidates, entry.Model)
+	}
+
+	installed, err := cm.ListCachedModels()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	for _, m := range installed {
+		result := types.SearchResult{Namespace: m.Namespace, Name: m.Name, Version: m.Version}
+		if manifest, err := cm.GetCachedManifest(m.Namespace, m.Name, m.Version); err == nil {
+			result.Description = manifest.Metadata.Description
+			result.Framework = manifest.Spec.Framework.Name
+			result.Tags = manifest.Metadata.Tags
+		}
+		candidates = append(candidates, result)
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	seen := make(map[string]bool)
+	var results []types.SearchResult
+	for _, result := range candidates {
+		key := result.Namespace + "/" + result.Name + "@" + result.Version
+		if seen[key] || !matchesTerms(result, terms) {
+			continue
+		}
+		seen[key] = true
+		results = append(results, result)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Namespace+"/"+results[i].Name != results[j].Namespace+"/"+results[j].Name {
+			return results[i].Namespace+"/"+results[i].Name < results[j].Namespace+"/"+results[j].Name
+		}
+		return results[i].Version < results[j].Version
+	})
+	return results, nil
+}
+
+// matchesTerms reports whether every term appears in what describes the model
+func matchesTerms(result types.SearchResult, terms []string) bool {
+	text := strings.ToLower(strings.Join(append([]string{result.Namespace + "/" + result.Name, result.Description, result.Framework}, result.Tags...), " "))
+	for _, term := range terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeJSONAtomic writes v as indented JSON to path through a temporary file
+func writeJSONAtomic(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestSearchCache(t *testing.T) {
+	cm := NewManager(t.TempDir())
+	searches := cm.SearchCache(time.Hour)
+
+	if _, _, ok := searches.Lookup("huggingface", "bert"); ok {
+		t.Fatal("Lookup() found results before any search")
+	}
+	results := []types.SearchResult{
+		{Namespace: "hf", Name: "bert-base-uncased", Version: "latest", Description: "BERT base model", Tags: []string{"fill-mask"}},
+		{Namespace: "hf", Name: "distilbert-base-uncased", Version: "latest", Framework: "pytorch"},
+	}
+	if err := searches.Store("huggingface", "bert", results); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	got, fetchedAt, ok := searches.Lookup("huggingface", " bert ")
+	if !ok || len(got) != 2 || time.Since(fetchedAt) > time.Minute {
+		t.Errorf("Lookup() = %v, %s, %v, want the stored results", got, fetchedAt, ok)
+	}
+	if _, _, ok := searches.Lookup("modelscope", "bert"); ok {
+		t.Error("Lookup() returned the results of another adapter")
+	}
+	if _, _, ok := cm.SearchCache(time.Nanosecond).Lookup("huggingface", "bert"); ok {
+		t.Error("Lookup() returned expired results")
+	}
+	if _, _, ok := cm.SearchCache(-1).Lookup("huggingface", "bert"); ok {
+		t.Error("Lookup() returned results with caching off")
+	}
+
+	// The offline index has every model seen, and the installed models
+	if err := searches.Store("huggingface", "gpt", []types.SearchResult{{Namespace: "hf", Name: "gpt2", Version: "latest"}}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	manifest := &types.Manifest{}
+	manifest.Metadata.Description = "A BERT fine-tuned for sentiment"
+	if err := cm.CacheModel("myteam", "sentiment", "1.0.0", manifest); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"bert", []string{"hf/bert-base-uncased", "hf/distilbert-base-uncased", "myteam/sentiment"}},
+		{"GPT", []string{"hf/gpt2"}},
+		{"bert fill-mask", []string{"hf/bert-base-uncased"}},
+		{"pytorch distil", []string{"hf/distilbert-base-uncased"}},
+		{"llama", nil},
+	}
+	for _, tt := range tests {
+		found, err := searches.SearchOffline(cm, tt.query)
+		if err != nil {
+			t.Fatalf("SearchOffline(%q) error = %v", tt.query, err)
+		}
+		var names []string
+		for _, result := range found {
+			names = append(names, result.Namespace+"/"+result.Name)
+		}
+		if !slices.Equal(names, tt.want) {
+			t.Errorf("SearchOffline(%q) = %v, want %v", tt.query, names, tt.want)
+		}
+	}
+}
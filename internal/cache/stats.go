@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+)
+
+// DiskUsage splits the disk usage of cached models by where the files came from, in bytes
+type DiskUsage struct {
+	Package    int64 // .axon packages
+	Extracted  int64 // Files extracted from packages, with Axon's metadata
+	Conversion int64 // Files conversion produced (ONNX, GGUF), as recorded in the manifest
+}
+
+// Total returns the disk usage of every file
+func (u DiskUsage) Total() int64 {
+	return u.Package + u.Extracted + u.Conversion
+}
+
+// Add adds the disk usage of other files
+func (u *DiskUsage) Add(other DiskUsage) {
+	u.Package += other.Package
+	u.Extracted += other.Extracted
+	u.Conversion += other.Conversion
+}
+
+// GetModelDiskUsage returns the disk usage of a cached model version, split into its
+// package, extracted files and conversion artifacts. Models without a readable manifest
+// count every file that isn't a package as extracted.
+func (cm *Manager) GetModelDiskUsage(namespace, name, version string) (DiskUsage, error) {
+	artifacts := make(map[string]bool)
+	if m, err := cm.GetCachedManifest(namespace, name, version); err == nil && m.Spec.Conversion != nil {
+		for _, artifact := range m.Spec.Conversion.Artifacts {
+			artifacts[filepath.FromSlash(artifact.Path)] = true
+		}
+	}
+
+	var usage DiskUsage
+	path := cm.GetModelPath(namespace, name, version)
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+		// Encrypted files count as the files they encrypt
+		rel = strings.TrimSuffix(rel, cachecrypt.Suffix)
+		switch {
+		case strings.HasSuffix(rel, ".axon"):
+			usage.Package += info.Size()
+		case artifacts[rel]:
+			usage.Conversion += info.Size()
+		default:
+			usage.Extracted += info.Size()
+		}
+		return nil
+	})
+	return usage, err
+}
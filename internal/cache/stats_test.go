@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestGetModelDiskUsage(t *testing.T) {
+	cm := NewManager(t.TempDir())
+	manifest := &types.Manifest{}
+	manifest.Spec.Conversion = &types.Conversion{
+		Status:    "converted",
+		Artifacts: []types.ConvertedArtifact{{Path: "model.onnx"}, {Path: "onnx/model.onnx_data"}},
+	}
+	if err := cm.CacheModel("hf", "bert", "latest", manifest); err != nil {
+		t.Fatal(err)
+	}
+	path := cm.GetModelPath("hf", "bert", "latest")
+
+	files := map[string]int{
+		"hf_bert_latest.axon":                           1000,
+		"weights/model.safetensors" + cachecrypt.Suffix: 400,
+		"model.onnx":           300,
+		"onnx/model.onnx_data": 200,
+	}
+	for name, size := range files {
+		file := filepath.Join(path, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	usage, err := cm.GetModelDiskUsage("hf", "bert", "latest")
+	if err != nil {
+		t.Fatalf("GetModelDiskUsage() error = %v", err)
+	}
+	size, _ := cm.GetModelSize("hf", "bert", "latest")
+	if usage.Package != 1000 || usage.Conversion != 500 || usage.Extracted < 400 || usage.Total() != size {
+		t.Errorf("GetModelDiskUsage() = %+v (total %d), want 1000 package, 500 conversion and the rest of %d extracted", usage, usage.Total(), size)
+	}
+}
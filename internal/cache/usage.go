@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Keys of when a model was installed and last used in .axon_metadata.json
+const (
+	installedAtKey = "installed_at"
+	lastUsedKey    = "last_used_at"
+)
+
+// ReadLastUsed returns when the cached model at modelPath was last used, e.g. registered
+// with MLOS Core or converted, or else when it was installed (zero if neither is known)
+func ReadLastUsed(modelPath string) (time.Time, error) {
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, key := range []string{lastUsedKey, installedAtKey} {
+		var value string
+		if json.Unmarshal(metadata[key], &value) != nil || value == "" {
+			continue
+		}
+		used, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse %s in %s: %w", key, modelPath, err)
+		}
+		return used, nil
+	}
+	return time.Time{}, nil
+}
+
+// MarkUsed records in the metadata of the cached model at modelPath that it was used now.
+// Directories without metadata aren't cached models and are left as they are.
+func MarkUsed(modelPath string) error {
+	if _, err := os.Stat(filepath.Join(modelPath, ".axon_metadata.json")); err != nil {
+		return nil
+	}
+	metadata, err := readMetadata(modelPath)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to marshal last use: %w", err)
+	}
+	metadata[lastUsedKey] = raw
+	return writeMetadata(modelPath, metadata)
+}
@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestLastUsed(t *testing.T) {
+	cm := NewManager(t.TempDir())
+	if err := cm.CacheModel("hf", "bert", "latest", &types.Manifest{}); err != nil {
+		t.Fatal(err)
+	}
+	path := cm.GetModelPath("hf", "bert", "latest")
+
+	// Until the model is used, the install time counts
+	installed, err := ReadLastUsed(path)
+	if err != nil || installed.IsZero() {
+		t.Fatalf("ReadLastUsed() = %v, %v, want the install time", installed, err)
+	}
+
+	before := time.Now().Add(-time.Second)
+	if err := MarkUsed(path); err != nil {
+		t.Fatalf("MarkUsed() error = %v", err)
+	}
+	used, err := ReadLastUsed(path)
+	if err != nil || used.Before(before) {
+		t.Errorf("ReadLastUsed() = %v, %v, want now", used, err)
+	}
+	if ref, ok := readModelRef(path); !ok || ref.Name != "bert" {
+		t.Errorf("MarkUsed() lost the other metadata: %+v", ref)
+	}
+
+	// Directories that aren't cached models are left alone
+	dir := t.TempDir()
+	if err := MarkUsed(dir); err != nil {
+		t.Fatalf("MarkUsed() of a plain directory error = %v", err)
+	}
+	if used, err := ReadLastUsed(dir); err != nil || !used.IsZero() {
+		t.Errorf("ReadLastUsed() of a plain directory = %v, %v", used, err)
+	}
+}
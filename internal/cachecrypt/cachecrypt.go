@@ -0,0 +1,431 @@
+// Package cachecrypt encrypts cached model files at rest.
+//
+// Encrypted files are stored next to where the plaintext would be, with Suffix appended.
+// The format is a header (magic and a random salt) followed by 64 KiB chunks, each
+// sealed with AES-256-GCM under a per-file key derived from the cache key with HKDF.
+// The chunk nonce holds a counter and a final-chunk flag, so chunks can't be reordered,
+// dropped or truncated without failing authentication. Files are encrypted and
+// decrypted as streams, so multi-GB weights never need to fit in memory.
+package cachecrypt
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Suffix is appended to the name of an encrypted file
+const Suffix = ".axonenc"
+
+// KeySize is the size of a cache key in bytes
+const KeySize = 32
+
+const (
+	magic     = "AXONENC1"
+	saltSize  = 32
+	chunkSize = 64 * 1024
+	tagSize   = 16
+	hkdfInfo  = "axon-cache-encryption-v1"
+)
+
+// Errors
+var (
+	ErrNotEncrypted = errors.New("not an encrypted cache file")
+	ErrDecrypt      = errors.New("decryption failed (wrong key or corrupted file)")
+	ErrInvalidKey   = errors.New("invalid cache encryption key")
+)
+
+// GenerateKey returns a new random cache key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// EncodeKey encodes a cache key as base64
+func EncodeKey(key []byte) string {
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// ParseKey decodes a base64 cache key, as printed by EncodeKey or by KMS decrypt commands
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		key, err = base64.RawStdEncoding.DecodeString(encoded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: not base64", ErrInvalidKey)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrInvalidKey, len(key), KeySize)
+	}
+	return key, nil
+}
+
+// newAEAD derives the per-file cipher from the cache key and the file's salt
+func newAEAD(key, salt []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("%w: %d bytes, want %d", ErrInvalidKey, len(key), KeySize)
+	}
+	fileKey, err := hkdf.Key(sha256.New, key, salt, hkdfInfo, KeySize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkNonce returns the nonce of chunk n: an 11-byte big-endian counter and a final flag
+func chunkNonce(n uint64, last bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[3:11], n)
+	if last {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// writer encrypts a stream chunk by chunk
+type writer struct {
+	dst     io.Writer
+	aead    cipher.AEAD
+	buf     []byte
+	out     []byte
+	counter uint64
+	closed  bool
+}
+
+// NewWriter returns a writer that encrypts to dst. Close must be called to write the
+// final chunk; it does not close dst.
+func NewWriter(dst io.Writer, key []byte) (io.WriteCloser, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	aead, err := newAEAD(key, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dst.Write(append([]byte(magic), salt...)); err != nil {
+		return nil, err
+	}
+	return &writer{
+		dst:  dst,
+		aead: aead,
+		buf:  make([]byte, 0, chunkSize),
+		out:  make([]byte, 0, chunkSize+tagSize),
+	}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed cachecrypt writer")
+	}
+	n := 0
+	for len(p) > 0 {
+		// A full chunk is only written once more data arrives, so the last one is flagged
+		if len(w.buf) == chunkSize {
+			if err := w.flush(false); err != nil {
+				return n, err
+			}
+		}
+		k := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+k]
+		p = p[k:]
+		n += k
+	}
+	return n, nil
+}
+
+func (w *writer) flush(last bool) error {
+	w.out = w.aead.Seal(w.out[:0], chunkNonce(w.counter, last), w.buf, nil)
+	w.counter++
+	w.buf = w.buf[:0]
+	_, err := w.dst.Write(w.out)
+	return err
+}
+
+// Close writes the final chunk
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+// reader decrypts a stream chunk by chunk
+type reader struct {
+	src     *bufio.Reader
+	aead    cipher.AEAD
+	enc     []byte
+	buf     []byte
+	counter uint64
+	done    bool
+}
+
+// NewReader returns a reader that decrypts src. Read returns ErrDecrypt if the data was
+// not encrypted with key or has been modified or truncated.
+func NewReader(src io.Reader, key []byte) (io.Reader, error) {
+	br := bufio.NewReaderSize(src, chunkSize+tagSize)
+	header := make([]byte, len(magic)+saltSize)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrNotEncrypted
+	}
+	aead, err := newAEAD(key, header[len(magic):])
+	if err != nil {
+		return nil, err
+	}
+	return &reader{src: br, aead: aead, enc: make([]byte, chunkSize+tagSize)}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *reader) readChunk() error {
+	n, err := io.ReadFull(r.src, r.enc)
+	last := false
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+		last = true
+	case err != nil:
+		return err
+	default:
+		_, peekErr := r.src.Peek(1)
+		last = errors.Is(peekErr, io.EOF)
+	}
+	if n < tagSize {
+		return ErrDecrypt
+	}
+
+	plaintext, err := r.aead.Open(r.enc[:0], chunkNonce(r.counter, last), r.enc[:n], nil)
+	if err != nil {
+		return ErrDecrypt
+	}
+	r.counter++
+	r.buf = plaintext
+	r.done = last
+	return nil
+}
+
+// EncryptFile replaces the file at path with an encrypted copy at path+Suffix
+func EncryptFile(path string, key []byte) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(path), err)
+	}
+	defer func() { _ = src.Close() }()
+
+	err = writeAtomic(path+Suffix, func(dst io.Writer) error {
+		w, err := NewWriter(dst, key)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(w, src); err != nil {
+			return err
+		}
+		return w.Close()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encrypt %s: %w", filepath.Base(path), err)
+	}
+	return os.Remove(path)
+}
+
+// DecryptFile decrypts the file at encPath to destPath, leaving encPath in place
+func DecryptFile(encPath, destPath string, key []byte) error {
+	src, err := os.Open(encPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", filepath.Base(encPath), err)
+	}
+	defer func() { _ = src.Close() }()
+
+	err = writeAtomic(destPath, func(dst io.Writer) error {
+		r, err := NewReader(src, key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, r)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to decrypt %s: %w", filepath.Base(encPath), err)
+	}
+	return nil
+}
+
+// writeAtomic writes a 0600 file through a temporary file, so a failure never leaves a
+// partial file at path
+func writeAtomic(path string, write func(io.Writer) error) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// EncryptDir encrypts, in place, the files in dir for which match (given the path
+// relative to dir) returns true. It returns the number of files encrypted.
+func EncryptDir(dir string, key []byte, match func(relPath string) bool) (int, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || strings.HasSuffix(path, Suffix) {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if match(filepath.ToSlash(relPath)) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for i, path := range paths {
+		if err := EncryptFile(path, key); err != nil {
+			return i, err
+		}
+	}
+	return len(paths), nil
+}
+
+// DecryptDir decrypts, in place, every encrypted file in dir. It returns the number of
+// files decrypted.
+func DecryptDir(dir string, key []byte) (int, error) {
+	paths, err := encryptedFiles(dir)
+	if err != nil {
+		return 0, err
+	}
+	for i, path := range paths {
+		if err := DecryptFile(path, strings.TrimSuffix(path, Suffix), key); err != nil {
+			return i, err
+		}
+		if err := os.Remove(path); err != nil {
+			return i, err
+		}
+	}
+	return len(paths), nil
+}
+
+// IsEncryptedDir reports whether dir holds any encrypted files
+func IsEncryptedDir(dir string) bool {
+	paths, err := encryptedFiles(dir)
+	return err == nil && len(paths) > 0
+}
+
+// Materialize builds a plaintext view of dir at dest: encrypted files are decrypted
+// there and every other file is symlinked. dest is replaced if it exists.
+func Materialize(dir, dest string, key []byte) error {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(dest); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", dest, err)
+	}
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, relPath)
+		switch {
+		case info.IsDir():
+			return os.MkdirAll(target, 0700)
+		case strings.HasSuffix(path, Suffix):
+			return DecryptFile(path, strings.TrimSuffix(target, Suffix), key)
+		default:
+			return os.Symlink(path, target)
+		}
+	})
+	if err != nil {
+		_ = os.RemoveAll(dest)
+		return err
+	}
+	return nil
+}
+
+// HashFile returns the SHA256 (hex) and size of the plaintext of an encrypted file
+func HashFile(encPath string, key []byte) (string, int64, error) {
+	f, err := os.Open(encPath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r, err := NewReader(f, key)
+	if err != nil {
+		return "", 0, err
+	}
+	h := sha256.New()
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return "", 0, err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), size, nil
+}
+
+// encryptedFiles lists the encrypted files in dir
+func encryptedFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, Suffix) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
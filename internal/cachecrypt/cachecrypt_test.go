@@ -0,0 +1,185 @@
+package cachecrypt
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testKey(t *testing.T) []byte {
+	t.Helper()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func encrypt(t *testing.T, key, plaintext []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Write in odd-sized pieces to exercise chunk boundaries
+	for len(plaintext) > 0 {
+		n := min(len(plaintext), 10000)
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatal(err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func decrypt(key, ciphertext []byte) ([]byte, error) {
+	r, err := NewReader(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := testKey(t)
+	for _, size := range []int{0, 1, chunkSize - 1, chunkSize, chunkSize + 1, 3*chunkSize + 123} {
+		plaintext := make([]byte, size)
+		_, _ = rand.Read(plaintext)
+
+		got, err := decrypt(key, encrypt(t, key, plaintext))
+		if err != nil {
+			t.Fatalf("size %d: decrypt error = %v", size, err)
+		}
+		if !bytes.Equal(got, plaintext) {
+			t.Errorf("size %d: round trip mismatch", size)
+		}
+	}
+}
+
+func TestReader_RejectsTampering(t *testing.T) {
+	key := testKey(t)
+	plaintext := make([]byte, 2*chunkSize+10)
+	ciphertext := encrypt(t, key, plaintext)
+	header := len(magic) + saltSize
+	fullChunk := chunkSize + tagSize
+
+	flipped := bytes.Clone(ciphertext)
+	flipped[header+5] ^= 1
+
+	tests := []struct {
+		name       string
+		key        []byte
+		ciphertext []byte
+		want       error
+	}{
+		{"wrong key", testKey(t), ciphertext, ErrDecrypt},
+		{"modified", key, flipped, ErrDecrypt},
+		{"truncated at chunk boundary", key, ciphertext[:header+2*fullChunk], ErrDecrypt},
+		{"truncated mid chunk", key, ciphertext[:len(ciphertext)-3], ErrDecrypt},
+		{"missing final chunk", key, ciphertext[:header+fullChunk], ErrDecrypt},
+		{"plaintext file", key, []byte("not encrypted at all, just some bytes here"), ErrNotEncrypted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decrypt(tt.key, tt.ciphertext); !errors.Is(err, tt.want) {
+				t.Errorf("decrypt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	key := testKey(t)
+	got, err := ParseKey(" " + EncodeKey(key) + "\n")
+	if err != nil || !bytes.Equal(got, key) {
+		t.Errorf("ParseKey(EncodeKey()) = %x, %v", got, err)
+	}
+	for _, bad := range []string{"", "not base64!", EncodeKey(key[:16])} {
+		if _, err := ParseKey(bad); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("ParseKey(%q) error = %v, want ErrInvalidKey", bad, err)
+		}
+	}
+}
+
+func TestEncryptDir_Materialize(t *testing.T) {
+	key := testKey(t)
+	dir := t.TempDir()
+	files := map[string]string{
+		"model.safetensors":    "weights",
+		"sub/model.onnx":       "onnx weights",
+		"config.json":          `{"model_type": "bert"}`,
+		"tokenizer/vocab.json": "{}",
+	}
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	weights := func(relPath string) bool {
+		return strings.HasSuffix(relPath, ".safetensors") || strings.HasSuffix(relPath, ".onnx")
+	}
+	n, err := EncryptDir(dir, key, weights)
+	if err != nil || n != 2 {
+		t.Fatalf("EncryptDir() = %d, %v, want 2 files", n, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "model.safetensors")); !os.IsNotExist(err) {
+		t.Error("plaintext model.safetensors left behind")
+	}
+	if !IsEncryptedDir(dir) {
+		t.Error("IsEncryptedDir() = false after EncryptDir")
+	}
+	want := fmt.Sprintf("%x", sha256.Sum256([]byte("weights")))
+	if sum, size, err := HashFile(filepath.Join(dir, "model.safetensors"+Suffix), key); err != nil || size != 7 || sum != want {
+		t.Errorf("HashFile() = %s, %d, %v, want %s, 7", sum, size, err, want)
+	}
+
+	dest := filepath.Join(t.TempDir(), "plain")
+	if err := Materialize(dir, dest, key); err != nil {
+		t.Fatalf("Materialize() error = %v", err)
+	}
+	for path, content := range files {
+		data, err := os.ReadFile(filepath.Join(dest, path))
+		if err != nil || string(data) != content {
+			t.Errorf("materialized %s = %q, %v, want %q", path, data, err, content)
+		}
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "config.json")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Error("unencrypted config.json was not symlinked")
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "model.safetensors")); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("decrypted model.safetensors mode = %v, want 0600", info.Mode())
+	}
+
+	if err := Materialize(dir, dest, testKey(t)); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("Materialize() with the wrong key error = %v, want ErrDecrypt", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("failed Materialize() left a partial view behind")
+	}
+
+	if n, err := DecryptDir(dir, key); err != nil || n != 2 {
+		t.Fatalf("DecryptDir() = %d, %v, want 2 files", n, err)
+	}
+	if IsEncryptedDir(dir) {
+		t.Error("IsEncryptedDir() = true after DecryptDir")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "sub/model.onnx")); string(data) != "onnx weights" {
+		t.Errorf("decrypted sub/model.onnx = %q", data)
+	}
+}
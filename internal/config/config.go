@@ -5,7 +5,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"time"
 
+	"github.com/mlOS-foundation/axon/internal/hooks"
+	"github.com/mlOS-foundation/axon/internal/policy"
+	"github.com/mlOS-foundation/axon/internal/rewrite"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,12 +24,46 @@ type Config struct {
 	// Cache directory
 	CacheDir string `yaml:"cache_dir"`
 
+	// Tenant whose cache scope and Core registrations commands use (empty = the shared
+	// default scope). Overridden by --tenant and $AXON_TENANT.
+	Tenant string `yaml:"tenant,omitempty"`
+
+	// Directory for downloads, package builds and conversion staging (default:
+	// <cache_dir>/tmp, overridden by $AXON_TMPDIR). Keep it on the cache's filesystem
+	// so finished files are renamed into the cache instead of copied.
+	TempDir string `yaml:"temp_dir,omitempty"`
+
 	// Registry configuration
 	Registry RegistryConfig `yaml:"registry"`
 
+	// Repository adapters in the order they are consulted. Empty means the default
+	// adapters in the default order; when set, only the listed adapters are used.
+	Adapters []AdapterConfig `yaml:"adapters,omitempty"`
+
 	// Download settings
 	Download DownloadConfig `yaml:"download"`
 
+	// Cache settings
+	Cache CacheConfig `yaml:"cache"`
+
+	// Conversion settings
+	Conversion ConversionConfig `yaml:"conversion"`
+
+	// Package signing settings
+	Security SecurityConfig `yaml:"security"`
+
+	// Organization policies on installed and published models
+	Policy PolicyConfig `yaml:"policy"`
+
+	// MLOS Core integration
+	Core CoreConfig `yaml:"core"`
+
+	// Shell commands and webhooks run on model lifecycle events
+	Hooks []HookConfig `yaml:"hooks,omitempty"`
+
+	// Rules sending public model specs to private mirrors; the first match applies
+	Rewrites []RewriteConfig `yaml:"rewrites,omitempty"`
+
 	// Logging
 	LogLevel string `yaml:"log_level"`
 }
@@ -45,6 +85,37 @@ type RegistryConfig struct {
 
 	// Timeout settings
 	Timeout int `yaml:"timeout"` // seconds
+
+	// The registry proxies upstream repositories (see test/registry), so models from
+	// every namespace, such as hf/bert-base-uncased, are installed through it
+	Proxy bool `yaml:"proxy,omitempty"`
+}
+
+// AdapterConfig configures one repository adapter
+type AdapterConfig struct {
+	// Adapter name as shown by 'axon adapters list', e.g. "huggingface", "pytorch" or
+	// the name of an external adapter
+	Name string `yaml:"name"`
+
+	// Set to false to disable the adapter while keeping its settings
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// Overrides the repository API URL (for the local adapter: the registry URL)
+	BaseURL string `yaml:"base_url,omitempty"`
+
+	// Access token; a token stored with 'axon login' takes precedence
+	Token string `yaml:"token,omitempty"`
+
+	// Request timeout in seconds (0 = the adapter's default)
+	Timeout int `yaml:"timeout,omitempty"`
+
+	// Requests per second to the adapter's API host (0 = unlimited)
+	RequestsPerSecond float64 `yaml:"requests_per_second,omitempty"`
+}
+
+// IsEnabled reports whether the adapter is enabled (the default)
+func (a AdapterConfig) IsEnabled() bool {
+	return a.Enabled == nil || *a.Enabled
 }
 
 // DownloadConfig contains download settings
@@ -57,6 +128,209 @@ type DownloadConfig struct {
 
 	// Verify checksums
 	VerifyChecksums bool `yaml:"verify_checksums"`
+
+	// Combined bandwidth limit for all downloads, e.g. "10MB/s" (empty = unlimited)
+	RateLimit string `yaml:"rate_limit"`
+
+	// Byte ranges fetched in parallel per large file (0 = 8, 1 disables chunking)
+	Chunks int `yaml:"chunks,omitempty"`
+
+	// Files at least this many MB are downloaded in chunks (0 = 64)
+	ChunkThresholdMB int `yaml:"chunk_threshold_mb,omitempty"`
+
+	// Requests per second to each host, e.g. huggingface.co: 5 (missing = unlimited).
+	// Rate limited requests are retried max_retries times after the wait the host asks for.
+	HostRateLimits map[string]float64 `yaml:"host_rate_limits,omitempty"`
+
+	// Deprecated: use temp_dir. Load moves a tmp_dir set here to temp_dir when temp_dir
+	// isn't set.
+	TmpDir string `yaml:"tmp_dir,omitempty"`
+}
+
+// CacheConfig contains cache settings
+type CacheConfig struct {
+	// Extract .axon packages into the model cache directory after install
+	AutoExtract bool `yaml:"auto_extract"`
+
+	// Encryption of cached weight files at rest
+	Encryption EncryptionConfig `yaml:"encryption"`
+
+	// Largest size one package or model archive may unpack to, in GB (0 = 1024), and
+	// most entries it may have (0 = 100000), against decompression bombs
+	MaxExtractGB      float64 `yaml:"max_extract_gb,omitempty"`
+	MaxExtractEntries int     `yaml:"max_extract_entries,omitempty"`
+
+	// How long search results are reused before searching the repository again, in
+	// seconds (0 = 3600, -1 = don't reuse them)
+	SearchTTL int `yaml:"search_ttl,omitempty"`
+}
+
+// EncryptionConfig contains settings for encrypting cached weights at rest
+type EncryptionConfig struct {
+	// Encrypt weight files and packages of newly installed models
+	Enabled bool `yaml:"enabled"`
+
+	// Where the key comes from: "keyring" (the OS keychain, created on first use) or
+	// "command" (the base64 key printed by key_command). $AXON_CACHE_KEY overrides both.
+	KeySource string `yaml:"key_source"`
+
+	// Command that prints the base64 key, e.g. a KMS decrypt of a wrapped data key
+	KeyCommand string `yaml:"key_command,omitempty"`
+
+	// Where decrypted models are placed for MLOS Core (default: a tmpfs such as
+	// $XDG_RUNTIME_DIR/axon/models or /dev/shm/axon-<uid>/models)
+	DecryptedDir string `yaml:"decrypted_dir,omitempty"`
+}
+
+// ConversionConfig contains model conversion settings
+type ConversionConfig struct {
+	// ONNX conversion policy on install: required, prefer, or skip
+	ONNXPolicy string `yaml:"onnx_policy"`
+
+	// Abort a conversion attempt after this many seconds (0 = no limit)
+	Timeout int `yaml:"timeout"`
+
+	// Retry once in safe mode (no constant folding, shorter sequences) after a timeout
+	RetryOnTimeout bool `yaml:"retry_on_timeout"`
+
+	// Resource limits for the Docker converter container (empty = unlimited), e.g. "8g" and "2"
+	Memory string `yaml:"memory"`
+	CPUs   string `yaml:"cpus"`
+
+	// Reuse conversion outputs keyed by source digest, converter and export options
+	CacheResults bool `yaml:"cache_results"`
+
+	// Where cached conversion outputs are stored (default: <cache_dir>/conversions).
+	// Point several machines at a shared volume to reuse conversions between them.
+	ResultCacheDir string `yaml:"result_cache_dir"`
+
+	// Compare source and ONNX outputs on a sample input after export, rejecting exports
+	// whose outputs deviate by more than ParityTolerance (0 = converter default, 1e-3)
+	ParityCheck     bool    `yaml:"parity_check"`
+	ParityTolerance float64 `yaml:"parity_tolerance"`
+}
+
+// SecurityConfig contains package signing settings
+type SecurityConfig struct {
+	// Public keys whose package signatures are accepted: inline "ed25519:<base64>" keys
+	// or paths to PEM public key files. Keys added with 'axon keys add' are trusted too.
+	TrustedKeys []string `yaml:"trusted_keys,omitempty"`
+
+	// Reject packages without a signature from a trusted key
+	RequireSignatures bool `yaml:"require_signatures"`
+
+	// Private key used by 'axon publish --sign' (default: <home_dir>/keys/signing.pem)
+	SigningKey string `yaml:"signing_key,omitempty"`
+
+	// What installing, extracting, importing, updating or converting a model whose
+	// pickle-based weights (.bin, .pt, .ckpt) import code execution, process, file or
+	// network APIs does: warn (default), block, or off
+	ScanWeights string `yaml:"scan_weights,omitempty"`
+}
+
+// PolicyConfig contains organization policies checked on install and publish
+type PolicyConfig struct {
+	// Licenses models may have, e.g. ["mit", "apache-2.0", "bsd-*"] (empty = any license
+	// not denied). A model that declares no license is rejected when this is set.
+	AllowedLicenses []string `yaml:"allowed_licenses,omitempty"`
+
+	// Licenses models may not have, e.g. ["agpl-*", "*rail*"]
+	DeniedLicenses []string `yaml:"denied_licenses,omitempty"`
+
+	// Largest model that may be installed, in GB (0 = no limit)
+	MaxModelSizeGB float64 `yaml:"max_model_size_gb,omitempty"`
+
+	// Cache space the models of a namespace may use together, in GB, e.g. {"hf": 200}.
+	// Each tenant has its own namespace quotas.
+	NamespaceQuotasGB map[string]float64 `yaml:"namespace_quotas_gb,omitempty"`
+
+	// Cache space each tenant's models may use together, in GB, e.g. {"team-a": 500}
+	TenantQuotasGB map[string]float64 `yaml:"tenant_quotas_gb,omitempty"`
+
+	// What installing a model without the tokenizer or preprocessor config its task
+	// needs does: warn (default), fail, or ignore
+	MissingAssets string `yaml:"missing_assets,omitempty"`
+}
+
+// CoreConfig contains MLOS Core integration settings
+type CoreConfig struct {
+	// Register models with MLOS Core as soon as 'axon install' caches them
+	AutoRegister bool `yaml:"auto_register"`
+}
+
+// HookConfig is a shell command or HTTP webhook run on model lifecycle events, given
+// the event, the model and its manifest as JSON
+type HookConfig struct {
+	// Events that run the hook: install-complete, conversion-complete, verify-failure
+	// and/or uninstall
+	Events []string `yaml:"events"`
+
+	// Shell command (run with sh -c) that reads the event from stdin
+	Command string `yaml:"command,omitempty"`
+
+	// URL the event is POSTed to
+	URL string `yaml:"url,omitempty"`
+
+	// Stop the hook after this many seconds (0 = 10)
+	Timeout int `yaml:"timeout,omitempty"`
+}
+
+// RewriteConfig sends the models it matches to a private mirror. It can be written as
+// "hf/* -> https://hf-mirror.example.com" or as a mapping with a token for the mirror.
+type RewriteConfig struct {
+	// Models the rule applies to: namespace/name, or a prefix ending in /* (hf/*)
+	Match string `yaml:"match"`
+
+	// Mirror base URL speaking the protocol of the models' repository, or the spec the
+	// models are fetched as, with * for the rest of the match (corp/hf/*)
+	To string `yaml:"to"`
+
+	// Token sent to the mirror; tokens of the public repository aren't
+	Token string `yaml:"token,omitempty"`
+}
+
+// UnmarshalYAML accepts the "match -> to" shorthand as well as a mapping
+func (r *RewriteConfig) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		rule, err := rewrite.ParseRule(value.Value)
+		if err != nil {
+			return err
+		}
+		*r = RewriteConfig{Match: rule.Match, To: rule.To}
+		return nil
+	}
+	type plain RewriteConfig
+	return value.Decode((*plain)(r))
+}
+
+// Rule returns the configured rewrite rule
+func (r RewriteConfig) Rule() rewrite.Rule {
+	return rewrite.Rule{Match: r.Match, To: r.To, Token: r.Token}
+}
+
+// Hook returns the configured hook
+func (h HookConfig) Hook() hooks.Hook {
+	return hooks.Hook{Events: h.Events, Command: h.Command, URL: h.URL, Timeout: time.Duration(h.Timeout) * time.Second}
+}
+
+// LicensePolicy returns the configured license policy
+func (p PolicyConfig) LicensePolicy() policy.LicensePolicy {
+	return policy.LicensePolicy{Allowed: p.AllowedLicenses, Denied: p.DeniedLicenses}
+}
+
+// SizePolicy returns the configured model size policy for a tenant ("" for the default scope)
+func (p PolicyConfig) SizePolicy(tenant string) policy.SizePolicy {
+	sp := policy.SizePolicy{
+		MaxModelBytes:    int64(p.MaxModelSizeGB * policy.GB),
+		TenantQuotaBytes: int64(p.TenantQuotasGB[tenant] * policy.GB),
+	}
+	if len(p.NamespaceQuotasGB) > 0 {
+		sp.QuotaBytes = make(map[string]int64, len(p.NamespaceQuotasGB))
+		for namespace, gb := range p.NamespaceQuotasGB {
+			sp.QuotaBytes[namespace] = int64(gb * policy.GB)
+		}
+	}
+	return sp
 }
 
 // DefaultConfig returns the default configuration
@@ -78,10 +352,98 @@ func DefaultConfig() *Config {
 			MaxRetries:      3,
 			VerifyChecksums: true,
 		},
+		Cache: CacheConfig{
+			AutoExtract: true,
+			Encryption: EncryptionConfig{
+				KeySource: "keyring",
+			},
+		},
+		Conversion: ConversionConfig{
+			ONNXPolicy:     "prefer",
+			Timeout:        1800,
+			RetryOnTimeout: true,
+			CacheResults:   true,
+		},
 		LogLevel: "info",
 	}
 }
 
+// tenantPattern matches valid tenant names
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// ValidateTenant checks that a tenant name is usable as a directory and model ID prefix
+func ValidateTenant(tenant string) error {
+	if !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("invalid tenant %q: use lowercase letters, digits, '-' and '_'", tenant)
+	}
+	return nil
+}
+
+// tenantPath scopes dir to the tenant, if one is set
+func (c *Config) tenantPath(dir string) string {
+	if c.Tenant == "" {
+		return dir
+	}
+	return filepath.Join(dir, "tenants", c.Tenant)
+}
+
+// ModelCacheDir returns the cache directory of the tenant's models. Tenants each have
+// their own directory under <cache_dir>/tenants, so they can't see or evict each
+// other's models.
+func (c *Config) ModelCacheDir() string {
+	return c.tenantPath(c.CacheDir)
+}
+
+// TenantHomeDir returns the directory for the tenant's state, such as its registrations
+func (c *Config) TenantHomeDir() string {
+	return c.tenantPath(c.HomeDir)
+}
+
+// KeysDir returns the key store managed by 'axon keys'
+func (c *Config) KeysDir() string {
+	return filepath.Join(c.HomeDir, "keys")
+}
+
+// SigningKeyPath returns the private key used to sign packages
+func (c *Config) SigningKeyPath() string {
+	if c.Security.SigningKey != "" {
+		return c.Security.SigningKey
+	}
+	return filepath.Join(c.KeysDir(), "signing.pem")
+}
+
+// TempPath returns the directory for temporary files: $AXON_TMPDIR, then temp_dir, then
+// a directory on the cache volume. /tmp is often a small tmpfs that can't hold multi-GB
+// packages, and staging on the cache volume lets installs move files into place cheaply.
+func (c *Config) TempPath() string {
+	if dir := os.Getenv(utils.TempDirEnv); dir != "" {
+		return dir
+	}
+	if c.TempDir != "" {
+		return c.TempDir
+	}
+	return filepath.Join(c.CacheDir, "tmp")
+}
+
+// DecryptedModelsPath returns where decrypted models are placed for MLOS Core. It
+// prefers memory-backed filesystems so decrypted weights don't land on node disks.
+func (c *Config) DecryptedModelsPath() string {
+	return c.tenantPath(c.decryptedModelsRoot())
+}
+
+func (c *Config) decryptedModelsRoot() string {
+	if c.Cache.Encryption.DecryptedDir != "" {
+		return c.Cache.Encryption.DecryptedDir
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "axon", "models")
+	}
+	if info, err := os.Stat("/dev/shm"); err == nil && info.IsDir() {
+		return filepath.Join("/dev/shm", fmt.Sprintf("axon-%d", os.Getuid()), "models")
+	}
+	return filepath.Join(c.TempPath(), "decrypted")
+}
+
 // Path returns the path to the Axon configuration file.
 func Path() string {
 	homeDir, _ := os.UserHomeDir()
@@ -104,12 +466,21 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	// Start from defaults so settings missing from older config files keep their default values
+	cfg := DefaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
-	return &cfg, nil
+	// download.tmp_dir is the deprecated spelling of temp_dir
+	if cfg.Download.TmpDir != "" {
+		if cfg.TempDir == "" {
+			cfg.TempDir = cfg.Download.TmpDir
+		}
+		cfg.Download.TmpDir = ""
+	}
+
+	return cfg, nil
 }
 
 // Save saves configuration to file
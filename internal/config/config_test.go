@@ -4,6 +4,8 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -76,3 +78,112 @@ func TestSave(t *testing.T) {
 		t.Error("Save() should create .axon directory")
 	}
 }
+
+func TestTempPath(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CacheDir = "/data/axon/cache"
+
+	t.Setenv("AXON_TMPDIR", "")
+	if got, want := cfg.TempPath(), filepath.Join("/data/axon/cache", "tmp"); got != want {
+		t.Errorf("TempPath() = %q, want cache volume default %q", got, want)
+	}
+
+	cfg.TempDir = "/scratch/axon"
+	if got := cfg.TempPath(); got != "/scratch/axon" {
+		t.Errorf("TempPath() = %q, want temp_dir %q", got, "/scratch/axon")
+	}
+
+	t.Setenv("AXON_TMPDIR", "/mnt/fast")
+	if got := cfg.TempPath(); got != "/mnt/fast" {
+		t.Errorf("TempPath() = %q, want AXON_TMPDIR %q", got, "/mnt/fast")
+	}
+}
+
+func TestDecryptedModelsPath(t *testing.T) {
+	cfg := DefaultConfig()
+
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	if got, want := cfg.DecryptedModelsPath(), filepath.Join("/run/user/1000", "axon", "models"); got != want {
+		t.Errorf("DecryptedModelsPath() = %q, want runtime dir %q", got, want)
+	}
+
+	cfg.Cache.Encryption.DecryptedDir = "/mnt/tmpfs/axon"
+	if got := cfg.DecryptedModelsPath(); got != "/mnt/tmpfs/axon" {
+		t.Errorf("DecryptedModelsPath() = %q, want decrypted_dir %q", got, "/mnt/tmpfs/axon")
+	}
+}
+
+func TestTenantScope(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.HomeDir, cfg.CacheDir = "/home/axon", "/cache"
+	cfg.Cache.Encryption.DecryptedDir = "/run/axon"
+
+	if cfg.ModelCacheDir() != "/cache" || cfg.TenantHomeDir() != "/home/axon" || cfg.DecryptedModelsPath() != "/run/axon" {
+		t.Errorf("default scope = %s, %s, %s; want the unscoped directories", cfg.ModelCacheDir(), cfg.TenantHomeDir(), cfg.DecryptedModelsPath())
+	}
+
+	cfg.Tenant = "team-a"
+	if got, want := cfg.ModelCacheDir(), filepath.Join("/cache", "tenants", "team-a"); got != want {
+		t.Errorf("ModelCacheDir() = %s, want %s", got, want)
+	}
+	if got, want := cfg.TenantHomeDir(), filepath.Join("/home/axon", "tenants", "team-a"); got != want {
+		t.Errorf("TenantHomeDir() = %s, want %s", got, want)
+	}
+	if got, want := cfg.DecryptedModelsPath(), filepath.Join("/run/axon", "tenants", "team-a"); got != want {
+		t.Errorf("DecryptedModelsPath() = %s, want %s", got, want)
+	}
+}
+
+func TestValidateTenant(t *testing.T) {
+	for _, tenant := range []string{"team-a", "ml_platform", "42"} {
+		if err := ValidateTenant(tenant); err != nil {
+			t.Errorf("ValidateTenant(%q) error = %v", tenant, err)
+		}
+	}
+	for _, tenant := range []string{"", "Team", "../other", "a:b", "-lead"} {
+		if err := ValidateTenant(tenant); err == nil {
+			t.Errorf("ValidateTenant(%q) succeeded, want an error", tenant)
+		}
+	}
+}
+
+func TestRewriteConfig_UnmarshalYAML(t *testing.T) {
+	data := []byte(`rewrites:
+  - hf/* -> https://hf-mirror.example.com
+  - match: pytorch/*
+    to: corp/pytorch/*
+    token: secret
+`)
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := []RewriteConfig{
+		{Match: "hf/*", To: "https://hf-mirror.example.com"},
+		{Match: "pytorch/*", To: "corp/pytorch/*", Token: "secret"},
+	}
+	if len(cfg.Rewrites) != len(want) || cfg.Rewrites[0] != want[0] || cfg.Rewrites[1] != want[1] {
+		t.Errorf("rewrites = %+v, want %+v", cfg.Rewrites, want)
+	}
+
+	if err := yaml.Unmarshal([]byte("rewrites:\n  - hf/* to corp/*\n"), &cfg); err == nil {
+		t.Error("Unmarshal() of a rule without -> succeeded")
+	}
+}
+
+func TestLoad_DeprecatedTmpDir(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(Path()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(Path(), []byte("download:\n  tmp_dir: /data/axon/staging\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.TempDir != "/data/axon/staging" || cfg.Download.TmpDir != "" {
+		t.Errorf("Load() temp_dir = %q, download.tmp_dir = %q, want download.tmp_dir moved to temp_dir", cfg.TempDir, cfg.Download.TmpDir)
+	}
+}
@@ -4,11 +4,13 @@ package converter
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 // DockerConverter handles ONNX conversion using Docker containers.
@@ -105,9 +107,21 @@ func getConversionScript(namespace, framework string) string {
 	}
 }
 
+// containerCacheDir is where converter containers see the host's model directory
+const containerCacheDir = "/axon/cache"
+
+// bindMountArgs mounts hostDir at containerCacheDir. --mount takes a CSV list of fields,
+// so the source is quoted: unlike with -v, colons and commas in the path (from model
+// IDs, versions or Windows drive letters) can't change the mount.
+func bindMountArgs(hostDir string) []string {
+	source := `"source=` + strings.ReplaceAll(hostDir, `"`, `""`) + `"`
+	return []string{"--mount", "type=bind," + source + ",target=" + containerCacheDir}
+}
+
 // ConvertToONNXWithDocker converts a model to ONNX using Docker.
 // This eliminates the need for Python on the host machine.
-func ConvertToONNXWithDocker(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string) (bool, error) {
+// Export options are passed to the conversion scripts as AXON_ONNX_* environment variables.
+func ConvertToONNXWithDocker(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string, opts Options) (bool, error) {
 	// Check Docker availability
 	if !IsDockerAvailable() {
 		return false, fmt.Errorf("Docker is not available - cannot perform conversion")
@@ -147,26 +161,42 @@ func ConvertToONNXWithDocker(ctx context.Context, modelPath, framework, namespac
 	// Working directory: /axon/cache (so relative paths work)
 	// IMPORTANT: Use absolute container paths to avoid Optimum/HuggingFace
 	// misinterpreting relative paths like "latest" as model IDs
-	containerModelPath := "/axon/cache/" + relModelPath
-	containerOutputPath := "/axon/cache/" + relOutputPath
+	containerModelPath := containerCacheDir + "/" + filepath.ToSlash(relModelPath)
+	containerOutputPath := containerCacheDir + "/" + filepath.ToSlash(relOutputPath)
+	// Name the container so it can be killed if ctx is cancelled (e.g., conversion timeout);
+	// killing the docker CLI alone leaves the container running
+	containerName := fmt.Sprintf("axon-convert-%d-%d", os.Getpid(), time.Now().UnixNano())
 	dockerArgs := []string{
 		"run", "--rm",
-		"-v", fmt.Sprintf("%s:/axon/cache", absCacheDir),
-		"-w", "/axon/cache",
+		"--name", containerName,
+	}
+	dockerArgs = append(dockerArgs, bindMountArgs(absCacheDir)...)
+	dockerArgs = append(dockerArgs, "-w", containerCacheDir)
+	dockerArgs = append(dockerArgs, opts.dockerResourceArgs()...)
+	for _, env := range opts.env() {
+		dockerArgs = append(dockerArgs, "-e", env)
+	}
+	dockerArgs = append(dockerArgs,
 		imageName,
 		fmt.Sprintf("/axon/scripts/%s", scriptName),
 		containerModelPath,  // Absolute container path to model
 		containerOutputPath, // Absolute container path for output
 		modelID,             // Model ID for repository lookup (e.g., "microsoft/resnet-50")
-	}
+	)
 
 	fmt.Printf("🐳 Converting model using Docker (%s)...\n", imageName)
 	fmt.Printf("   Image: %s\n", imageName)
 	fmt.Printf("   Script: %s\n", scriptName)
 	fmt.Printf("   Model: %s\n", modelPath)
 	fmt.Printf("   Output: %s\n", outputPath)
+	if !opts.isDefault() {
+		fmt.Printf("   Options: %s\n", strings.Join(opts.env(), " "))
+	}
+	if limits := opts.dockerResourceArgs(); len(limits) > 0 {
+		fmt.Printf("   Limits: %s\n", strings.Join(limits, " "))
+	}
 
-	cmd := exec.CommandContext(ctx, "docker", dockerArgs...)
+	cmd := dockerRunCommand(ctx, containerName, dockerArgs)
 	output, err := cmd.CombinedOutput()
 
 	if err != nil {
@@ -178,12 +208,12 @@ func ConvertToONNXWithDocker(ctx context.Context, modelPath, framework, namespac
 				return false, fmt.Errorf("failed to pull Docker image: %w", pullErr)
 			}
 			// Retry conversion after pulling
-			cmd = exec.CommandContext(ctx, "docker", dockerArgs...)
+			cmd = dockerRunCommand(ctx, containerName, dockerArgs)
 			output, err = cmd.CombinedOutput()
 		}
 
 		if err != nil {
-			return false, fmt.Errorf("docker conversion failed: %w\nOutput: %s", err, string(output))
+			return false, conversionRunError(ctx, "docker conversion", err, output, opts)
 		}
 	}
 
@@ -198,48 +228,78 @@ func ConvertToONNXWithDocker(ctx context.Context, modelPath, framework, namespac
 		return false, fmt.Errorf("conversion output file too small (%d bytes), likely corrupted: %s", fileInfo.Size(), outputPath)
 	}
 
-	// Validate ONNX file magic bytes (protobuf starts with valid field tags)
-	if !ValidateONNXFile(outputPath) {
-		return false, fmt.Errorf("conversion output file appears corrupted (invalid ONNX format): %s", outputPath)
+	// Validate the ONNX graph structure so truncated exports are caught before packaging
+	if err := CheckONNXFile(outputPath); err != nil {
+		return false, fmt.Errorf("conversion output file appears corrupted (invalid ONNX format): %w", err)
 	}
 
 	fmt.Printf("✅ Model converted to ONNX using Docker: %s (%d bytes)\n", outputPath, fileInfo.Size())
 	return true, nil
 }
 
-// ValidateONNXFile performs basic validation of an ONNX file.
-// Checks protobuf structure without fully parsing the model.
-func ValidateONNXFile(path string) bool {
-	f, err := os.Open(path)
-	if err != nil {
-		return false
+// dockerRunCommand builds a `docker run` command that kills its container when ctx is cancelled
+func dockerRunCommand(ctx context.Context, containerName string, dockerArgs []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "docker", dockerArgs...)
+	cmd.Cancel = func() error {
+		_ = exec.Command("docker", "kill", containerName).Run()
+		return cmd.Process.Kill()
 	}
-	defer func() { _ = f.Close() }()
-
-	// Read first few bytes to check protobuf structure
-	// ONNX files are Protocol Buffer format
-	// A valid protobuf starts with field tags (wire type + field number)
-	header := make([]byte, 16)
-	n, err := f.Read(header)
-	if err != nil || n < 16 {
-		return false
+	return cmd
+}
+
+// ErrConversionOOM is returned when the converter process is killed for running out of memory
+var ErrConversionOOM = errors.New("converter ran out of memory")
+
+// oomExitCode is the exit status of a process (or container) killed with SIGKILL,
+// which is how both the kernel and Docker's cgroup OOM killer terminate it
+const oomExitCode = 137
+
+// conversionRunError describes a failed converter run, distinguishing an OOM kill from a
+// failure of the conversion script itself. Kills caused by ctx (timeouts, Ctrl-C) are not OOM.
+func conversionRunError(ctx context.Context, what string, err error, output []byte, opts Options) error {
+	if ctx.Err() == nil && isKilled(err) {
+		hint := "the host likely ran out of memory"
+		if opts.Memory != "" {
+			hint = fmt.Sprintf("memory limit was %s; raise it with --memory or conversion.memory", opts.Memory)
+		}
+		return fmt.Errorf("%s failed: %w (process was killed, %s)\nOutput: %s", what, ErrConversionOOM, hint, string(output))
 	}
 
-	// Check for valid protobuf wire types in the first bytes
-	// Wire types: 0=varint, 1=64-bit, 2=length-delimited, 5=32-bit
-	// First byte should be a valid field tag (field_number << 3 | wire_type)
-	// Common valid first bytes for ONNX: 0x08 (field 1, varint), 0x0a (field 1, length-delimited)
-	firstByte := header[0]
-	wireType := firstByte & 0x07
-	if wireType > 5 {
-		// Invalid wire type
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("%s failed: conversion script exited with code %d\nOutput: %s", what, exitErr.ExitCode(), string(output))
+	}
+	return fmt.Errorf("%s failed: %w\nOutput: %s", what, err, string(output))
+}
+
+// isKilled reports whether a command exited because it was sent SIGKILL
+func isKilled(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
 		return false
 	}
+	if exitErr.ExitCode() == oomExitCode {
+		return true
+	}
+	// A signalled process has no exit code; its state reads "signal: killed"
+	return exitErr.ExitCode() == -1 && strings.Contains(exitErr.String(), "killed")
+}
 
-	// Note: Common ONNX patterns are 0x08, 0x0a, 0x10, 0x12
-	// but other valid protobuf field tags are also acceptable
-	// File passed basic checks
-	return true
+// ValidateONNXFile reports whether path is a structurally valid ONNX model.
+// See CheckONNXFile for the reason a file is rejected.
+func ValidateONNXFile(path string) bool {
+	return CheckONNXFile(path) == nil
+}
+
+// DockerImageDigest returns the repository digest of a local Docker image
+// (e.g., "ghcr.io/mlos-foundation/axon-converter@sha256:..."), or "" if unknown.
+func DockerImageDigest(ctx context.Context, imageName string) string {
+	cmd := exec.CommandContext(ctx, "docker", "image", "inspect", "--format", "{{index .RepoDigests 0}}", imageName)
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
 }
 
 // EnsureDockerImage ensures the Docker image is available locally.
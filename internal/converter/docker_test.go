@@ -0,0 +1,66 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestConversionRunError(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	tests := []struct {
+		name     string
+		script   string
+		wantOOM  bool
+		wantText string
+	}{
+		{name: "script failure", script: "exit 1", wantText: "exited with code 1"},
+		{name: "container OOM exit code", script: "exit 137", wantOOM: true, wantText: "memory limit was 4g"},
+		{name: "killed process", script: "kill -9 $$", wantOOM: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			output, runErr := exec.Command("sh", "-c", tt.script).CombinedOutput()
+			if runErr == nil {
+				t.Fatal("command should fail")
+			}
+
+			err := conversionRunError(ctx, "docker conversion", runErr, output, Options{Memory: "4g"})
+			if got := errors.Is(err, ErrConversionOOM); got != tt.wantOOM {
+				t.Errorf("errors.Is(err, ErrConversionOOM) = %v, want %v (err: %v)", got, tt.wantOOM, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantText) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestConversionRunError_CancelledIsNotOOM(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, runErr := exec.Command("sh", "-c", "kill -9 $$").CombinedOutput()
+
+	if err := conversionRunError(ctx, "docker conversion", runErr, nil, Options{}); errors.Is(err, ErrConversionOOM) {
+		t.Errorf("conversionRunError() = %v, a cancelled run should not be reported as OOM", err)
+	}
+}
+
+func TestBindMountArgs(t *testing.T) {
+	got := bindMountArgs(`/data/axon,cache/models/hf/"x":y`)
+	want := `type=bind,"source=/data/axon,cache/models/hf/""x"":y",target=/axon/cache`
+	if len(got) != 2 || got[0] != "--mount" || got[1] != want {
+		t.Errorf("bindMountArgs() = %q, want [--mount %s]", got, want)
+	}
+}
@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultGGUFQuant is the quantization used when none is requested.
+// Q4_K_M is a good balance of quality and size, and is what selectBestGGUF prefers on download.
+const DefaultGGUFQuant = "q4_k_m"
+
+// ConversionMethodDockerLlamaCpp means the converter image ran llama.cpp to produce a GGUF file
+const ConversionMethodDockerLlamaCpp = "docker-llama.cpp"
+
+// ggufQuantTypes lists the llama-quantize types accepted by --quant
+var ggufQuantTypes = []string{"f16", "q8_0", "q6_k", "q5_k_m", "q5_k_s", "q5_0", "q4_k_m", "q4_k_s", "q4_0"}
+
+// ValidateGGUFQuant checks that quant is a supported GGUF quantization type
+func ValidateGGUFQuant(quant string) error {
+	for _, q := range ggufQuantTypes {
+		if strings.EqualFold(q, quant) {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported GGUF quantization %q (supported: %s)", quant, strings.Join(ggufQuantTypes, ", "))
+}
+
+// GGUFFileName returns the output file name for a quantization (e.g., "model.Q4_K_M.gguf")
+func GGUFFileName(quant string) string {
+	return fmt.Sprintf("model.%s.gguf", strings.ToUpper(quant))
+}
+
+// CanConvertToGGUF reports whether modelPath looks like a Hugging Face LLM directory
+// that llama.cpp can convert (config.json plus safetensors or PyTorch weights)
+func CanConvertToGGUF(modelPath string) bool {
+	if _, err := os.Stat(filepath.Join(modelPath, "config.json")); err != nil {
+		return false
+	}
+	for _, pattern := range []string{"*.safetensors", "*.bin"} {
+		if matches, _ := filepath.Glob(filepath.Join(modelPath, pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ConvertToGGUF converts a Hugging Face model directory to a quantized GGUF file using the
+// converter Docker image (llama.cpp convert_hf_to_gguf.py + llama-quantize).
+// The GGUF file is written into modelPath. opts.Timeout bounds the conversion.
+func ConvertToGGUF(ctx context.Context, modelPath, namespace, quant string, opts Options) (*ConversionResult, error) {
+	if err := ValidateGGUFQuant(quant); err != nil {
+		return &ConversionResult{}, err
+	}
+	if !IsDockerAvailable() {
+		return &ConversionResult{}, fmt.Errorf("Docker is not available - GGUF conversion requires the converter image")
+	}
+	if err := EnsureDockerImage(ctx, namespace); err != nil {
+		return &ConversionResult{}, err
+	}
+
+	attemptCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	absModelPath, err := filepath.Abs(modelPath)
+	if err != nil {
+		return &ConversionResult{}, fmt.Errorf("failed to resolve model path: %w", err)
+	}
+	outputPath := filepath.Join(absModelPath, GGUFFileName(quant))
+	imageName := getDockerImageForRepository(namespace)
+	containerName := fmt.Sprintf("axon-convert-%d-%d", os.Getpid(), time.Now().UnixNano())
+
+	// Mount the model directory itself; the script reads config/weights and writes the GGUF next to them
+	dockerArgs := []string{
+		"run", "--rm",
+		"--name", containerName,
+	}
+	dockerArgs = append(dockerArgs, bindMountArgs(absModelPath)...)
+	dockerArgs = append(dockerArgs, "-w", containerCacheDir)
+	dockerArgs = append(dockerArgs, opts.dockerResourceArgs()...)
+	dockerArgs = append(dockerArgs,
+		imageName,
+		"/axon/scripts/convert_gguf.py",
+		containerCacheDir,
+		containerCacheDir+"/"+filepath.Base(outputPath),
+		strings.ToLower(quant),
+	)
+
+	fmt.Printf("🐳 Converting model to GGUF using Docker (%s)...\n", imageName)
+	fmt.Printf("   Model: %s\n", modelPath)
+	fmt.Printf("   Quantization: %s\n", strings.ToUpper(quant))
+
+	result := &ConversionResult{
+		Method:   ConversionMethodDockerLlamaCpp,
+		Target:   "gguf",
+		Image:    imageName,
+		Attempts: 1,
+	}
+
+	start := time.Now()
+	output, err := dockerRunCommand(attemptCtx, containerName, dockerArgs).CombinedOutput()
+	result.Duration = time.Since(start)
+	result.ImageDigest = DockerImageDigest(ctx, imageName)
+
+	if attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		_ = os.Remove(outputPath)
+		result.TimedOut = true
+		return result, fmt.Errorf("%w after %s", ErrConversionTimeout, opts.Timeout)
+	}
+	if err != nil {
+		_ = os.Remove(outputPath)
+		err = conversionRunError(attemptCtx, "GGUF conversion", err, output, opts)
+		result.OOMKilled = errors.Is(err, ErrConversionOOM)
+		return result, err
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		return result, fmt.Errorf("GGUF output file not created: %s\nConversion output: %s", outputPath, string(output))
+	}
+
+	result.Success = true
+	result.PrimaryFile = outputPath
+	result.AllFiles = []string{outputPath}
+	result.Architecture = "single"
+	fmt.Printf("✅ Model converted to GGUF: %s\n", outputPath)
+	return result, nil
+}
@@ -0,0 +1,45 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateGGUFQuant(t *testing.T) {
+	for _, quant := range []string{"q4_k_m", "Q8_0", "f16"} {
+		if err := ValidateGGUFQuant(quant); err != nil {
+			t.Errorf("ValidateGGUFQuant(%q) error = %v", quant, err)
+		}
+	}
+	if err := ValidateGGUFQuant("q3_xs"); err == nil {
+		t.Error("ValidateGGUFQuant(q3_xs) expected error")
+	}
+}
+
+func TestGGUFFileName(t *testing.T) {
+	if got := GGUFFileName("q4_k_m"); got != "model.Q4_K_M.gguf" {
+		t.Errorf("GGUFFileName() = %q, want model.Q4_K_M.gguf", got)
+	}
+}
+
+func TestCanConvertToGGUF(t *testing.T) {
+	dir := t.TempDir()
+	if CanConvertToGGUF(dir) {
+		t.Error("CanConvertToGGUF() = true for empty directory")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte("{}"), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if CanConvertToGGUF(dir) {
+		t.Error("CanConvertToGGUF() = true without weights")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "model.safetensors"), []byte("w"), 0644); err != nil {
+		t.Fatalf("failed to write weights: %v", err)
+	}
+	if !CanConvertToGGUF(dir) {
+		t.Error("CanConvertToGGUF() = false for config.json + safetensors")
+	}
+}
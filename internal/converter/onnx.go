@@ -22,16 +22,49 @@ package converter
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+)
+
+// ONNX conversion policies applied during install
+const (
+	// ONNXPolicyRequired fails the install when ONNX conversion fails
+	ONNXPolicyRequired = "required"
+	// ONNXPolicyPrefer attempts conversion and records failures in the manifest
+	ONNXPolicyPrefer = "prefer"
+	// ONNXPolicySkip bypasses ONNX conversion entirely
+	ONNXPolicySkip = "skip"
 )
 
+// Conversion statuses recorded in the manifest
+const (
+	ConversionStatusConverted = "converted"
+	ConversionStatusFailed    = "failed"
+	ConversionStatusSkipped   = "skipped"
+	ConversionStatusNotNeeded = "not_needed"
+)
+
+// ValidateONNXPolicy checks that policy is one of the supported ONNX conversion policies
+func ValidateONNXPolicy(policy string) error {
+	switch policy {
+	case ONNXPolicyRequired, ONNXPolicyPrefer, ONNXPolicySkip:
+		return nil
+	default:
+		return fmt.Errorf("invalid ONNX policy: %s (expected: required, prefer, or skip)", policy)
+	}
+}
+
 // MultiEncoderManifest describes the structure of a multi-encoder model
 type MultiEncoderManifest struct {
 	Architecture string            `json:"architecture"` // "multi-encoder", "encoder-decoder", "multi-model"
@@ -41,6 +74,24 @@ type MultiEncoderManifest struct {
 	Files        []string          `json:"files"`
 }
 
+// Conversion methods recorded as provenance for produced ONNX files
+const (
+	// ConversionMethodPreConverted means the ONNX file was downloaded from the repository
+	ConversionMethodPreConverted = "pre-converted"
+	// ConversionMethodDocker means the converter Docker image produced the ONNX file
+	ConversionMethodDocker = "docker"
+	// ConversionMethodPython means local Python produced the ONNX file
+	ConversionMethodPython = "local-python"
+)
+
+// Default ONNX opset versions used by each conversion method
+const (
+	// DockerOpsetVersion is the opset used by the converter image scripts
+	DockerOpsetVersion = 14
+	// PythonOpsetVersion is the opset used by the local Python fallback
+	PythonOpsetVersion = 12
+)
+
 // ConversionResult contains information about a successful conversion
 type ConversionResult struct {
 	Success        bool
@@ -49,6 +100,43 @@ type ConversionResult struct {
 	AllFiles       []string // All ONNX files created
 	ManifestPath   string   // Path to onnx_manifest.json if multi-encoder
 	Architecture   string   // "single", "multi-encoder", "encoder-decoder"
+
+	// Provenance
+	Target      string        // Target execution format ("" means "onnx")
+	Method      string        // "pre-converted", "docker", "local-python", or "docker-llama.cpp"
+	Image       string        // Converter image (docker method only)
+	ImageDigest string        // Converter image digest (docker method only)
+	Opset       int           // ONNX opset version (0 if unknown)
+	Precision   string        // Weight precision of the ONNX files (fp32, fp16 or int8)
+	Duration    time.Duration // Wall-clock conversion time
+	Toolchain   *Toolchain    // Converter package versions (nil if unknown)
+
+	Attempts  int  // Number of conversion attempts (2 if retried after a timeout)
+	TimedOut  bool // The last attempt was aborted by the conversion timeout
+	OOMKilled bool // The last attempt was killed for running out of memory
+	SafeMode  bool // The last attempt ran in safe mode
+
+	SizeRatio   float64 // ONNX output size / source weight size (0 if not checked)
+	SizeWarning string  // Set when the output is suspiciously large for its source
+
+	Parity *ParityReport // Source vs ONNX output comparison (nil if not requested)
+
+	Cached bool // Outputs were restored from the conversion result cache
+}
+
+// ErrConversionTimeout is returned when a conversion exceeds Options.Timeout
+var ErrConversionTimeout = errors.New("conversion timed out")
+
+// huggingFaceURL is where pre-converted ONNX files are downloaded from
+var huggingFaceURL = "https://huggingface.co"
+
+// preConvertedONNXFiles are the file names repositories ship pre-converted ONNX models
+// under, by precision. Optimum and transformers.js name quantized exports
+// model_quantized.onnx.
+var preConvertedONNXFiles = map[string][]string{
+	PrecisionFP32: {"model.onnx"},
+	PrecisionFP16: {"model_fp16.onnx"},
+	PrecisionINT8: {"model_quantized.onnx", "model_int8.onnx"},
 }
 
 // DownloadPreConvertedONNX attempts to download a pre-converted ONNX file
@@ -65,6 +153,12 @@ type ConversionResult struct {
 //   - bool: true if ONNX file was successfully downloaded, false otherwise
 //   - error: error if download failed (not found is not an error)
 func DownloadPreConvertedONNX(ctx context.Context, namespace, modelID, outputPath string) (bool, error) {
+	return downloadPreConvertedONNX(ctx, namespace, modelID, outputPath, PrecisionFP32)
+}
+
+// downloadPreConvertedONNX downloads the pre-converted ONNX file of the given precision
+// to outputPath, if the repository ships one
+func downloadPreConvertedONNX(ctx context.Context, namespace, modelID, outputPath, precision string) (bool, error) {
 	// Only Hugging Face currently provides ONNX files directly
 	if namespace != "hf" {
 		return false, nil
@@ -73,10 +167,12 @@ func DownloadPreConvertedONNX(ctx context.Context, namespace, modelID, outputPat
 	// Hugging Face ONNX files are typically at:
 	// https://huggingface.co/{model_id}/resolve/main/model.onnx
 	// or https://huggingface.co/{model_id}/resolve/main/onnx/model.onnx
-	baseURL := "https://huggingface.co"
-	urls := []string{
-		fmt.Sprintf("%s/%s/resolve/main/model.onnx", baseURL, modelID),
-		fmt.Sprintf("%s/%s/resolve/main/onnx/model.onnx", baseURL, modelID),
+	var urls []string
+	for _, name := range preConvertedONNXFiles[precision] {
+		urls = append(urls,
+			fmt.Sprintf("%s/%s/resolve/main/%s", huggingFaceURL, modelID, name),
+			fmt.Sprintf("%s/%s/resolve/main/onnx/%s", huggingFaceURL, modelID, name),
+		)
 	}
 
 	client := &http.Client{Timeout: 5 * time.Minute}
@@ -111,7 +207,7 @@ func DownloadPreConvertedONNX(ctx context.Context, namespace, modelID, outputPat
 			_ = outFile.Close()
 		}()
 
-		if _, err := io.Copy(outFile, resp.Body); err != nil {
+		if _, err := io.Copy(outFile, core.LimitDownload(ctx, resp.Body)); err != nil {
 			_ = os.Remove(outputPath)
 			return false, fmt.Errorf("failed to download ONNX file: %w", err)
 		}
@@ -142,23 +238,35 @@ func DownloadPreConvertedONNX(ctx context.Context, namespace, modelID, outputPat
 //   - bool: true if ONNX file was created, false if conversion skipped (Python unavailable)
 //   - error: nil on success, error on failure
 func ConvertToONNX(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string) (bool, error) {
+	converted, _, err := convertToONNX(ctx, modelPath, framework, namespace, modelID, outputPath, DefaultOptions())
+	return converted, err
+}
+
+// convertToONNX implements ConvertToONNX with the given export options and additionally
+// reports which conversion method produced (or last attempted to produce) the ONNX file.
+func convertToONNX(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string, opts Options) (bool, string, error) {
 	// Step 1: Try to download pre-converted ONNX from repository (pure Go, no Python needed)
-	if namespace != "" && modelID != "" {
-		downloaded, err := DownloadPreConvertedONNX(ctx, namespace, modelID, outputPath)
+	// Pre-converted files have a fixed opset and task, so skip them when options were customized
+	// A pre-converted file of the requested precision is used as it is (model_fp16.onnx,
+	// model_quantized.onnx); without one the export below is converted to that precision
+	if namespace != "" && modelID != "" && opts.isDefault() {
+		downloaded, err := downloadPreConvertedONNX(ctx, namespace, modelID, outputPath, opts.precision())
 		if err != nil {
-			return false, fmt.Errorf("failed to download pre-converted ONNX: %w", err)
+			return false, ConversionMethodPreConverted, fmt.Errorf("failed to download pre-converted ONNX: %w", err)
 		}
 		if downloaded {
-			return true, nil // Success - pure Go, no Python needed!
+			return true, ConversionMethodPreConverted, nil // Success - pure Go, no Python needed!
 		}
 	}
 
 	// Step 2: Fall back to conversion (Docker first, then local Python)
 	if modelPath == "" || framework == "" || outputPath == "" {
-		return false, fmt.Errorf("modelPath, framework, and outputPath are required")
+		return false, "", fmt.Errorf("modelPath, framework, and outputPath are required")
 	}
 
 	// Try Docker-based conversion first (no Python needed on host)
+	// Remember the Docker error so it isn't lost if no local fallback exists
+	var dockerErr error
 	if IsDockerAvailable() {
 		// Ensure Docker image is available
 		if err := EnsureDockerImage(ctx, namespace); err != nil {
@@ -166,12 +274,13 @@ func ConvertToONNX(ctx context.Context, modelPath, framework, namespace, modelID
 			fmt.Printf("   💡 Falling back to local Python (if available)\n")
 		} else {
 			// Try Docker conversion
-			converted, err := ConvertToONNXWithDocker(ctx, modelPath, framework, namespace, modelID, outputPath)
+			converted, err := ConvertToONNXWithDocker(ctx, modelPath, framework, namespace, modelID, outputPath, opts)
 			if err == nil && converted {
-				return true, nil // Success with Docker!
+				return true, ConversionMethodDocker, nil // Success with Docker!
 			}
 			// If Docker conversion fails, fall through to local Python
 			if err != nil {
+				dockerErr = err
 				fmt.Printf("⚠️  Docker conversion failed: %v\n", err)
 				fmt.Printf("   💡 Falling back to local Python (if available)\n")
 			}
@@ -189,7 +298,11 @@ func ConvertToONNX(ctx context.Context, modelPath, framework, namespace, modelID
 			fmt.Printf("      - Install Docker: https://docs.docker.com/get-docker/\n")
 			fmt.Printf("      - Or install Python 3 and: pip install transformers torch\n")
 		}
-		return false, nil // Not an error - just skipped
+		if dockerErr != nil {
+			// Docker was the only converter and it failed - surface its output
+			return false, ConversionMethodDocker, dockerErr
+		}
+		return false, "", nil // Not an error - just skipped
 	}
 
 	// Normalize framework name for comparison
@@ -214,7 +327,7 @@ func ConvertToONNX(ctx context.Context, modelPath, framework, namespace, modelID
 import sys
 import os
 try:
-    from transformers import AutoModel, AutoTokenizer
+    from transformers import %s as AutoModel, AutoTokenizer
     import torch
     model_path = '%s'
     output_path = '%s'
@@ -237,7 +350,7 @@ try:
     model.eval()
     # Get model config for input shape
     config = model.config
-    seq_len = min(128, getattr(config, 'max_position_embeddings', 128))
+    seq_len = min(%d, getattr(config, 'max_position_embeddings', %d))
     vocab_size = getattr(config, 'vocab_size', 30522)
     # Create dummy input
     dummy_input = torch.randint(0, vocab_size, (1, seq_len))
@@ -245,9 +358,9 @@ try:
     torch.onnx.export(model, dummy_input, output_path,
         input_names=['input_ids'],
         output_names=['output'],
-        dynamic_axes={'input_ids': {0: 'batch_size'}, 'output': {0: 'batch_size'}},
-        opset_version=12,
-        do_constant_folding=True)
+        dynamic_axes=%s,
+        opset_version=%d,
+        do_constant_folding=%s)
     print('SUCCESS')
 except ImportError as e:
     print('ERROR: Missing dependency:', str(e))
@@ -258,7 +371,8 @@ except Exception as e:
     import traceback
     traceback.print_exc()
     sys.exit(1)
-"`, modelPath, outputPath, modelName)
+"`, opts.modelClass(), modelPath, outputPath, modelName, opts.maxSeqLen(), opts.maxSeqLen(),
+				pythonDynamicAxes(opts, "{'input_ids': {0: 'batch_size'}, 'output': {0: 'batch_size'}}"), opts.opsetOr(PythonOpsetVersion), pythonBool(!opts.SafeMode))
 		} else {
 			// PyTorch conversion
 			pythonCmd = fmt.Sprintf(`python3 -c "
@@ -278,7 +392,7 @@ try:
         if isinstance(model, torch.nn.Module):
             model.eval()
             dummy_input = torch.randn(1, 3, 224, 224)
-            torch.onnx.export(model, dummy_input, output_path, opset_version=12)
+            torch.onnx.export(model, dummy_input, output_path, opset_version=%d)
             print('SUCCESS')
         else:
             print('ERROR: Model file is not a PyTorch Module')
@@ -288,7 +402,7 @@ except Exception as e:
     import traceback
     traceback.print_exc()
     sys.exit(1)
-"`, modelPath, outputPath)
+"`, modelPath, outputPath, opts.opsetOr(PythonOpsetVersion))
 		}
 
 	case frameworkLower == "tensorflow" || frameworkLower == "tf":
@@ -315,7 +429,7 @@ except Exception as e:
 "`, modelPath, outputPath)
 
 	default:
-		return false, fmt.Errorf("unsupported framework for ONNX conversion: %s", framework)
+		return false, ConversionMethodPython, fmt.Errorf("unsupported framework for ONNX conversion: %s", framework)
 	}
 
 	// Execute Python conversion
@@ -323,19 +437,82 @@ except Exception as e:
 	fmt.Printf("   Source: %s\n", modelPath)
 	fmt.Printf("   Target: %s\n", outputPath)
 
-	cmd := exec.Command("sh", "-c", pythonCmd)
+	// exec replaces the shell so that cancelling ctx kills python itself, not just sh
+	cmd := exec.CommandContext(ctx, "sh", "-c", "exec "+pythonCmd)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		return false, fmt.Errorf("conversion failed: %w\nOutput: %s", err, string(output))
+		return false, ConversionMethodPython, conversionRunError(ctx, "local Python conversion", err, output, opts)
 	}
 
 	// Verify output file was created
 	if _, err := os.Stat(outputPath); os.IsNotExist(err) {
-		return false, fmt.Errorf("conversion output file not created: %s\nConversion output: %s", outputPath, string(output))
+		return false, ConversionMethodPython, fmt.Errorf("conversion output file not created: %s\nConversion output: %s", outputPath, string(output))
+	}
+
+	if err := convertPrecisionPython(ctx, outputPath, opts); err != nil {
+		return false, ConversionMethodPython, err
 	}
 
 	fmt.Printf("✅ Model converted to ONNX: %s\n", outputPath)
-	return true, nil
+	return true, ConversionMethodPython, nil
+}
+
+// convertPrecisionPython converts a local Python export to the requested precision, as
+// the converter image scripts do: fp16 with onnxconverter-common, int8 by dynamic
+// weight quantization with onnxruntime
+func convertPrecisionPython(ctx context.Context, outputPath string, opts Options) error {
+	precision := opts.precision()
+	if precision == PrecisionFP32 {
+		return nil
+	}
+
+	fmt.Printf("🔄 Converting ONNX weights to %s...\n", precision)
+	pythonCmd := fmt.Sprintf(`python3 -c "
+import os
+import sys
+path = '%s'
+precision = '%s'
+try:
+    if precision == 'fp16':
+        import onnx
+        from onnxconverter_common import float16
+        onnx.save(float16.convert_float_to_float16(onnx.load(path), keep_io_types=True), path)
+    else:
+        from onnxruntime.quantization import quantize_dynamic, QuantType
+        quantize_dynamic(path, path + '.tmp', weight_type=QuantType.QInt8)
+        os.replace(path + '.tmp', path)
+    print('SUCCESS')
+except ImportError as e:
+    print('ERROR: Missing dependency:', str(e))
+    print('Install with: pip install onnx onnxconverter-common onnxruntime')
+    sys.exit(1)
+except Exception as e:
+    print('ERROR:', str(e))
+    sys.exit(1)
+"`, outputPath, precision)
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", "exec "+pythonCmd)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return conversionRunError(ctx, precision+" conversion", err, output, opts)
+	}
+	return nil
+}
+
+// pythonBool formats a Go bool as a Python literal
+func pythonBool(b bool) string {
+	if b {
+		return "True"
+	}
+	return "False"
+}
+
+// pythonDynamicAxes returns the dynamic_axes argument for the inline Python exporter
+func pythonDynamicAxes(opts Options, axes string) string {
+	if !opts.DynamicAxes {
+		return "None"
+	}
+	return axes
 }
 
 // extractModelNameFromPath extracts model name from Axon cache path
@@ -518,6 +695,43 @@ func ReadMultiEncoderManifest(dir string) (*MultiEncoderManifest, error) {
 	return &manifest, nil
 }
 
+// Role returns the component role of an ONNX file ("" if the manifest doesn't name it).
+// Files are matched by name, so the role survives moving them out of onnx/.
+func (m *MultiEncoderManifest) Role(file string) string {
+	base := path.Base(filepath.ToSlash(file))
+	for role, component := range m.Components {
+		if path.Base(filepath.ToSlash(component)) == base {
+			return role
+		}
+	}
+	return ""
+}
+
+// MissingFiles returns the component and listed files that are neither in dir nor in
+// its onnx/ subdirectory
+func (m *MultiEncoderManifest) MissingFiles(dir string) []string {
+	var missing []string
+	seen := make(map[string]bool)
+	names := append([]string{}, m.Files...)
+	for _, component := range m.Components {
+		names = append(names, component)
+	}
+	for _, name := range names {
+		base := path.Base(filepath.ToSlash(name))
+		if seen[base] {
+			continue
+		}
+		seen[base] = true
+		_, rootErr := os.Stat(filepath.Join(dir, base))
+		_, subErr := os.Stat(filepath.Join(dir, "onnx", base))
+		if rootErr != nil && subErr != nil {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
 // CheckForMultiEncoderManifest checks if a directory contains a multi-encoder manifest
 // and returns the manifest if found
 func CheckForMultiEncoderManifest(dir string) (*MultiEncoderManifest, bool) {
@@ -626,20 +840,181 @@ func CheckConversionResult(modelDir, expectedOutput string) *ConversionResult {
 	return result
 }
 
-// ConvertToONNXWithResult converts a model and returns detailed results
-// including information about multi-encoder models
-func ConvertToONNXWithResult(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string) (*ConversionResult, error) {
+// ConvertToONNXWithResult converts a model with the given export options and returns
+// detailed results including information about multi-encoder models.
+// If opts.Timeout is set and the conversion times out, partial outputs are removed and,
+// if opts.RetryOnTimeout is set, the conversion is retried once in safe mode.
+func ConvertToONNXWithResult(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string, opts Options) (*ConversionResult, error) {
+	// Reuse the outputs of an identical earlier conversion if the result cache has them
+	resultCache, cacheKey := resultCacheFor(ctx, modelPath, namespace, opts)
+	if resultCache != nil {
+		if entry, ok := resultCache.Lookup(cacheKey); ok && !entry.parityChecked(opts) {
+			fmt.Println("🔍 Cached ONNX conversion was not parity checked, converting again")
+		} else if ok {
+			result, err := restoreCachedConversion(resultCache, entry, outputPath)
+			if err == nil {
+				fmt.Printf("♻️  Reusing cached ONNX conversion (%s)\n", cacheKey[:12])
+				return result, nil
+			}
+			fmt.Printf("⚠️  Cached conversion unusable, converting again: %v\n", err)
+		}
+	}
+
+	result, err := convertAttempt(ctx, modelPath, framework, namespace, modelID, outputPath, opts)
+	result.Attempts = 1
+	if errors.Is(err, ErrConversionTimeout) && opts.RetryOnTimeout && !opts.SafeMode {
+		fmt.Printf("⏱️  Conversion timed out after %s - retrying once in safe mode (no constant folding, shorter sequences)\n", opts.Timeout)
+		safe := opts
+		safe.SafeMode = true
+		result, err = convertAttempt(ctx, modelPath, framework, namespace, modelID, outputPath, safe)
+		result.Attempts = 2
+	}
+
+	if err == nil && result.Success && resultCache != nil {
+		if storeErr := resultCache.Store(cacheKey, filepath.Dir(outputPath), result); storeErr != nil {
+			fmt.Printf("⚠️  Failed to cache conversion result: %v\n", storeErr)
+		}
+	}
+	return result, err
+}
+
+// convertAttempt runs a single, optionally time-boxed, conversion attempt
+func convertAttempt(ctx context.Context, modelPath, framework, namespace, modelID, outputPath string, opts Options) (*ConversionResult, error) {
+	modelDir := filepath.Dir(outputPath)
+	before := snapshotONNXOutputs(modelDir)
+
+	attemptCtx := ctx
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
 	// Run the standard conversion
-	converted, err := ConvertToONNX(ctx, modelPath, framework, namespace, modelID, outputPath)
-	if err != nil {
-		return &ConversionResult{Success: false}, err
+	start := time.Now()
+	converted, method, err := convertToONNX(attemptCtx, modelPath, framework, namespace, modelID, outputPath, opts)
+	duration := time.Since(start)
+	parity, parityErr := parityResult(modelDir, method, opts)
+
+	if attemptCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+		// The converter was killed mid-export; don't leave truncated files behind
+		removeNewONNXOutputs(modelDir, before)
+		if err == nil {
+			err = errors.New("converter did not finish")
+		}
+		err = fmt.Errorf("%w after %s: %v", ErrConversionTimeout, opts.Timeout, err)
+		converted = false
 	}
 
-	if !converted {
-		return &ConversionResult{Success: false}, nil
+	if err != nil || !converted {
+		result := &ConversionResult{
+			Success:   false,
+			TimedOut:  errors.Is(err, ErrConversionTimeout),
+			OOMKilled: errors.Is(err, ErrConversionOOM),
+			SafeMode:  opts.SafeMode,
+		}
+		recordProvenance(ctx, result, method, namespace, duration, opts)
+		return result, err
 	}
 
 	// Check what was actually created
-	modelDir := filepath.Dir(outputPath)
-	return CheckConversionResult(modelDir, outputPath), nil
+	result := CheckConversionResult(modelDir, outputPath)
+	result.SafeMode = opts.SafeMode
+
+	// Reject corrupt or truncated outputs before they are packaged
+	for _, file := range result.AllFiles {
+		if err := CheckONNXFile(file); err != nil {
+			removeNewONNXOutputs(modelDir, before)
+			failed := &ConversionResult{Success: false, SafeMode: opts.SafeMode}
+			recordProvenance(ctx, failed, method, namespace, duration, opts)
+			return failed, fmt.Errorf("ONNX validation failed: %w", err)
+		}
+	}
+
+	// A tiny output from large source weights is a broken export even if it parses
+	sizeCheck, err := CheckOutputSize(modelDir, result.AllFiles, opts.precision())
+	if err != nil {
+		removeNewONNXOutputs(modelDir, before)
+		failed := &ConversionResult{Success: false, SafeMode: opts.SafeMode}
+		recordProvenance(ctx, failed, method, namespace, duration, opts)
+		return failed, fmt.Errorf("ONNX size check failed: %w", err)
+	}
+	result.SizeRatio = sizeCheck.Ratio
+	result.SizeWarning = sizeCheck.Warning
+	if sizeCheck.Warning != "" {
+		fmt.Printf("⚠️  %s\n", sizeCheck.Warning)
+	}
+
+	// A file that loads can still compute something different from the source model
+	if parityErr == nil && parity != nil {
+		parityErr = parity.check()
+	}
+	if parityErr != nil {
+		removeNewONNXOutputs(modelDir, before)
+		failed := &ConversionResult{Success: false, SafeMode: opts.SafeMode, Parity: parity}
+		recordProvenance(ctx, failed, method, namespace, duration, opts)
+		return failed, fmt.Errorf("ONNX parity check failed: %w", parityErr)
+	}
+	result.Parity = parity
+	if parity != nil {
+		if parity.Skipped != "" {
+			fmt.Printf("⚠️  Parity check skipped: %s\n", parity.Skipped)
+		} else {
+			fmt.Printf("✅ Parity check passed: max deviation %g (tolerance %g)\n", parity.MaxAbsDiff, parity.Tolerance)
+		}
+	}
+	recordProvenance(ctx, result, method, namespace, duration, opts)
+	return result, nil
+}
+
+// snapshotONNXOutputs records the conversion outputs that already exist in dir
+func snapshotONNXOutputs(dir string) map[string]bool {
+	existing := make(map[string]bool)
+	for _, path := range onnxOutputs(dir) {
+		existing[path] = true
+	}
+	return existing
+}
+
+// removeNewONNXOutputs deletes conversion outputs in dir that are not in before
+func removeNewONNXOutputs(dir string, before map[string]bool) {
+	for _, path := range onnxOutputs(dir) {
+		if !before[path] {
+			fmt.Printf("🧹 Removing partial conversion output: %s\n", path)
+			_ = os.Remove(path)
+		}
+	}
+}
+
+// onnxOutputs lists files a conversion may produce: ONNX models, their external data and the multi-encoder manifest
+func onnxOutputs(dir string) []string {
+	var outputs []string
+	for _, sub := range []string{dir, filepath.Join(dir, "onnx")} {
+		for _, pattern := range []string{"*.onnx", "*.onnx_data", "*.onnx.data", "onnx_manifest.json"} {
+			matches, _ := filepath.Glob(filepath.Join(sub, pattern))
+			outputs = append(outputs, matches...)
+		}
+	}
+	return outputs
+}
+
+// recordProvenance fills in how a conversion was performed
+func recordProvenance(ctx context.Context, result *ConversionResult, method, namespace string, duration time.Duration, opts Options) {
+	result.Method = method
+	result.Duration = duration
+	result.Precision = opts.precision()
+
+	switch method {
+	case ConversionMethodDocker:
+		result.Image = getDockerImageForRepository(namespace)
+		result.ImageDigest = DockerImageDigest(ctx, result.Image)
+		result.Opset = opts.opsetOr(DockerOpsetVersion)
+	case ConversionMethodPython:
+		result.Opset = opts.opsetOr(PythonOpsetVersion)
+	}
+
+	// Best effort: a missing toolchain record must not fail the conversion
+	if toolchain, err := ProbeToolchain(ctx, method, result.Image); err == nil {
+		result.Toolchain = toolchain
+	}
 }
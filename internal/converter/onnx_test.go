@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRemoveNewONNXOutputs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel string) {
+		t.Helper()
+		path := filepath.Join(dir, rel)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", rel, err)
+		}
+	}
+
+	write("existing.onnx")
+	write("config.json")
+	before := snapshotONNXOutputs(dir)
+
+	// Simulate partial outputs left by a killed converter
+	write("model.onnx")
+	write("onnx/encoder_model.onnx")
+	write("onnx_manifest.json")
+
+	removeNewONNXOutputs(dir, before)
+
+	for _, rel := range []string{"model.onnx", "onnx/encoder_model.onnx", "onnx_manifest.json"} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); !os.IsNotExist(err) {
+			t.Errorf("%s should have been removed", rel)
+		}
+	}
+	for _, rel := range []string{"existing.onnx", "config.json"} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("%s should have been kept: %v", rel, err)
+		}
+	}
+}
+
+func TestMultiEncoderManifest_Components(t *testing.T) {
+	m := &MultiEncoderManifest{
+		Architecture: "multi-encoder",
+		EncoderType:  "clip",
+		Components:   map[string]string{"text_encoder": "onnx/text_model.onnx", "vision_encoder": "vision_model.onnx"},
+		Files:        []string{"onnx/text_model.onnx", "vision_model.onnx", "vision_model.onnx_data"},
+	}
+
+	for file, want := range map[string]string{"text_model.onnx": "text_encoder", "onnx/vision_model.onnx": "vision_encoder", "model.onnx": ""} {
+		if got := m.Role(file); got != want {
+			t.Errorf("Role(%q) = %q, want %q", file, got, want)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "onnx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, rel := range []string{"text_model.onnx", "onnx/vision_model.onnx"} {
+		if err := os.WriteFile(filepath.Join(dir, rel), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if missing := m.MissingFiles(dir); len(missing) != 1 || missing[0] != "vision_model.onnx_data" {
+		t.Errorf("MissingFiles() = %v, want [vision_model.onnx_data]", missing)
+	}
+}
+
+func TestDownloadPreConvertedONNX_Precision(t *testing.T) {
+	// The repository ships fp32 and int8 exports, but no fp16 one
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/org/model/resolve/main/onnx/model.onnx":
+			_, _ = w.Write([]byte("fp32"))
+		case "/org/model/resolve/main/onnx/model_quantized.onnx":
+			_, _ = w.Write([]byte("int8"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	originalURL := huggingFaceURL
+	defer func() { huggingFaceURL = originalURL }()
+	huggingFaceURL = server.URL
+
+	for _, precision := range []string{PrecisionFP32, PrecisionINT8} {
+		outputPath := filepath.Join(t.TempDir(), "model.onnx")
+		downloaded, err := downloadPreConvertedONNX(context.Background(), "hf", "org/model", outputPath, precision)
+		if err != nil || !downloaded {
+			t.Fatalf("downloadPreConvertedONNX(%s) = %v, %v", precision, downloaded, err)
+		}
+		if data, _ := os.ReadFile(outputPath); string(data) != precision {
+			t.Errorf("downloadPreConvertedONNX(%s) downloaded %q", precision, data)
+		}
+	}
+
+	// Without an fp16 file the model is converted instead
+	outputPath := filepath.Join(t.TempDir(), "model.onnx")
+	if downloaded, err := downloadPreConvertedONNX(context.Background(), "hf", "org/model", outputPath, PrecisionFP16); err != nil || downloaded {
+		t.Errorf("downloadPreConvertedONNX(fp16) = %v, %v, want not found", downloaded, err)
+	}
+}
@@ -0,0 +1,684 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// ONNXModelInfo summarizes the structure of an ONNX model file
+type ONNXModelInfo struct {
+	IRVersion    int64
+	Opsets       map[string]int64 // Operator set version by domain ("" is the default ai.onnx domain)
+	Nodes        int
+	Initializers int
+	Inputs       []string
+	Outputs      []string
+	ExternalData []ExternalTensorData // Initializers stored outside the .onnx file
+	// The graph's input and output tensors. Unlike Inputs, InputTensors leaves out
+	// inputs that are initializers (weights with defaults in older IR versions).
+	InputTensors  []ONNXTensorInfo
+	OutputTensors []ONNXTensorInfo
+}
+
+// ONNXTensorInfo describes a graph input or output
+type ONNXTensorInfo struct {
+	Name  string
+	DType string // Element type in manifest form ("float32", "int64", ...), "" if not a tensor
+	Shape []int  // -1 for symbolic or unknown dimensions; nil if the rank is unknown
+}
+
+// ExternalTensorData locates an initializer stored in an external data file
+type ExternalTensorData struct {
+	Location string
+	Offset   int64
+	Length   int64 // 0 means "to the end of the file"
+}
+
+// Protobuf field numbers from onnx.proto
+const (
+	modelIRVersionField     = 1
+	modelGraphField         = 7
+	modelOpsetImportField   = 8
+	opsetDomainField        = 1
+	opsetVersionField       = 2
+	graphNodeField          = 1
+	graphInitializerField   = 5
+	graphInputField         = 11
+	graphOutputField        = 12
+	nodeOpTypeField         = 4
+	valueInfoNameField      = 1
+	valueInfoTypeField      = 2
+	typeTensorField         = 1
+	tensorTypeElemField     = 1
+	tensorTypeShapeField    = 2
+	shapeDimField           = 1
+	dimValueField           = 1
+	tensorNameField         = 8
+	tensorExternalField     = 13
+	tensorDataLocationField = 14
+	entryKeyField           = 1
+	entryValueField         = 2
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// onnxElemTypes maps TensorProto.DataType to manifest dtypes
+var onnxElemTypes = map[uint64]string{
+	1:  "float32",
+	2:  "uint8",
+	3:  "int8",
+	4:  "uint16",
+	5:  "int16",
+	6:  "int32",
+	7:  "int64",
+	8:  "string",
+	9:  "bool",
+	10: "float16",
+	11: "float64",
+	12: "uint32",
+	13: "uint64",
+	16: "bfloat16",
+}
+
+// maxInspectedMessage caps the size of nested messages read into memory; larger
+// ones (weights, big Constant nodes) are skipped after checking they are complete
+const maxInspectedMessage = 1 << 20
+
+// errTruncated reports a message that ends before its declared length
+var errTruncated = errors.New("file is truncated")
+
+// wireReader reads protobuf fields from a bounded region of a stream
+type wireReader struct {
+	r         *bufio.Reader
+	remaining int64
+}
+
+func (w *wireReader) readByte() (byte, error) {
+	if w.remaining <= 0 {
+		return 0, errTruncated
+	}
+	b, err := w.r.ReadByte()
+	if err != nil {
+		return 0, errTruncated
+	}
+	w.remaining--
+	return b, nil
+}
+
+func (w *wireReader) readVarint() (uint64, error) {
+	var value uint64
+	for shift := uint(0); shift < 64; shift += 7 {
+		b, err := w.readByte()
+		if err != nil {
+			return 0, err
+		}
+		value |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("malformed varint")
+}
+
+// readTag returns the next field number and wire type
+func (w *wireReader) readTag() (int, int, error) {
+	tag, err := w.readVarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	field, wireType := int(tag>>3), int(tag&0x07)
+	if field == 0 {
+		return 0, 0, fmt.Errorf("invalid field number 0")
+	}
+	return field, wireType, nil
+}
+
+// readLength reads the length prefix of a length-delimited field and checks it fits
+func (w *wireReader) readLength() (int64, error) {
+	n, err := w.readVarint()
+	if err != nil {
+		return 0, err
+	}
+	if int64(n) < 0 || int64(n) > w.remaining {
+		return 0, errTruncated
+	}
+	return int64(n), nil
+}
+
+// discard skips n bytes, failing if the stream ends first
+func (w *wireReader) discard(n int64) error {
+	if n > w.remaining {
+		return errTruncated
+	}
+	for n > 0 {
+		chunk := n
+		if chunk > 1<<30 {
+			chunk = 1 << 30
+		}
+		skipped, err := w.r.Discard(int(chunk))
+		w.remaining -= int64(skipped)
+		n -= int64(skipped)
+		if err != nil {
+			return errTruncated
+		}
+	}
+	return nil
+}
+
+// readBytes reads a length-delimited field into memory
+func (w *wireReader) readBytes() ([]byte, error) {
+	n, err := w.readLength()
+	if err != nil {
+		return nil, err
+	}
+	return w.readN(n)
+}
+
+// readN reads the next n bytes into memory
+func (w *wireReader) readN(n int64) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(w.r, buf); err != nil {
+		return nil, errTruncated
+	}
+	w.remaining -= n
+	return buf, nil
+}
+
+// sub returns a reader over the next n bytes, which the caller must fully consume
+func (w *wireReader) sub(n int64) *wireReader {
+	return &wireReader{r: w.r, remaining: n}
+}
+
+// skip skips a field value of the given wire type
+func (w *wireReader) skip(wireType int) error {
+	switch wireType {
+	case wireVarint:
+		_, err := w.readVarint()
+		return err
+	case wireFixed64:
+		return w.discard(8)
+	case wireFixed32:
+		return w.discard(4)
+	case wireBytes:
+		n, err := w.readLength()
+		if err != nil {
+			return err
+		}
+		return w.discard(n)
+	default:
+		return fmt.Errorf("unsupported protobuf wire type %d", wireType)
+	}
+}
+
+// bytesReader returns a wireReader over an in-memory message
+func bytesReader(data []byte) *wireReader {
+	return &wireReader{r: bufio.NewReader(bytes.NewReader(data)), remaining: int64(len(data))}
+}
+
+// InspectONNXFile walks the protobuf structure of an ONNX model without loading
+// weights into memory, failing on malformed or truncated data
+func InspectONNXFile(path string) (*ONNXModelInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ONNX file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat ONNX file: %w", err)
+	}
+
+	info := &ONNXModelInfo{Opsets: make(map[string]int64)}
+	w := &wireReader{r: bufio.NewReaderSize(f, 1<<20), remaining: stat.Size()}
+	var hasGraph bool
+
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == modelIRVersionField && wireType == wireVarint:
+			v, err := w.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			info.IRVersion = int64(v)
+		case field == modelOpsetImportField && wireType == wireBytes:
+			data, err := w.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			domain, version, err := parseOpsetImport(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid opset import: %w", err)
+			}
+			info.Opsets[domain] = version
+		case field == modelGraphField && wireType == wireBytes:
+			n, err := w.readLength()
+			if err != nil {
+				return nil, err
+			}
+			if err := parseGraph(w.sub(n), info); err != nil {
+				return nil, fmt.Errorf("invalid graph: %w", err)
+			}
+			w.remaining -= n
+			hasGraph = true
+		default:
+			if err := w.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !hasGraph {
+		return nil, fmt.Errorf("model has no graph")
+	}
+	return info, nil
+}
+
+// parseOpsetImport reads an OperatorSetIdProto
+func parseOpsetImport(data []byte) (string, int64, error) {
+	w := bytesReader(data)
+	var domain string
+	var version int64
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return "", 0, err
+		}
+		switch {
+		case field == opsetDomainField && wireType == wireBytes:
+			b, err := w.readBytes()
+			if err != nil {
+				return "", 0, err
+			}
+			domain = string(b)
+		case field == opsetVersionField && wireType == wireVarint:
+			v, err := w.readVarint()
+			if err != nil {
+				return "", 0, err
+			}
+			version = int64(v)
+		default:
+			if err := w.skip(wireType); err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	return domain, version, nil
+}
+
+// parseGraph reads a GraphProto, streaming over large initializers
+func parseGraph(w *wireReader, info *ONNXModelInfo) error {
+	initializers := make(map[string]bool)
+	defer func() { info.InputTensors = withoutInitializers(info.InputTensors, initializers) }()
+
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return err
+		}
+		if wireType != wireBytes {
+			if err := w.skip(wireType); err != nil {
+				return err
+			}
+			continue
+		}
+
+		n, err := w.readLength()
+		if err != nil {
+			return err
+		}
+		if n > maxInspectedMessage && field == graphInitializerField {
+			// Weights stored inline: only the name is needed
+			name, err := streamTensorName(w.sub(n))
+			if err != nil {
+				return fmt.Errorf("initializer %d: %w", info.Initializers, err)
+			}
+			w.remaining -= n
+			initializers[name] = true
+			info.Initializers++
+			continue
+		}
+		if n > maxInspectedMessage || (field != graphNodeField && field != graphInitializerField && field != graphInputField && field != graphOutputField) {
+			if field == graphNodeField {
+				info.Nodes++
+			}
+			if err := w.discard(n); err != nil {
+				return err
+			}
+			continue
+		}
+
+		data, err := w.readN(n)
+		if err != nil {
+			return err
+		}
+		switch field {
+		case graphNodeField:
+			opType, err := stringField(data, nodeOpTypeField)
+			if err != nil {
+				return fmt.Errorf("node %d: %w", info.Nodes, err)
+			}
+			if opType == "" {
+				return fmt.Errorf("node %d has no op_type", info.Nodes)
+			}
+			info.Nodes++
+		case graphInitializerField:
+			external, err := parseExternalData(data)
+			if err != nil {
+				return fmt.Errorf("initializer %d: %w", info.Initializers, err)
+			}
+			if external != nil {
+				info.ExternalData = append(info.ExternalData, *external)
+			}
+			name, _ := stringField(data, tensorNameField)
+			initializers[name] = true
+			info.Initializers++
+		case graphInputField, graphOutputField:
+			tensor, err := parseValueInfo(data)
+			if err != nil {
+				return err
+			}
+			if field == graphInputField {
+				info.Inputs = append(info.Inputs, tensor.Name)
+				info.InputTensors = append(info.InputTensors, tensor)
+			} else {
+				info.Outputs = append(info.Outputs, tensor.Name)
+				info.OutputTensors = append(info.OutputTensors, tensor)
+			}
+		}
+	}
+	return nil
+}
+
+// streamTensorName reads the name of a TensorProto too large to load, discarding its data
+func streamTensorName(w *wireReader) (string, error) {
+	var name string
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return "", err
+		}
+		if field == tensorNameField && wireType == wireBytes {
+			b, err := w.readBytes()
+			if err != nil {
+				return "", err
+			}
+			name = string(b)
+			continue
+		}
+		if err := w.skip(wireType); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// withoutInitializers drops the graph inputs that are initializers
+func withoutInitializers(tensors []ONNXTensorInfo, initializers map[string]bool) []ONNXTensorInfo {
+	var inputs []ONNXTensorInfo
+	for _, tensor := range tensors {
+		if !initializers[tensor.Name] {
+			inputs = append(inputs, tensor)
+		}
+	}
+	return inputs
+}
+
+// parseValueInfo reads a ValueInfoProto: the name, and for tensors the element type and shape
+func parseValueInfo(data []byte) (ONNXTensorInfo, error) {
+	var tensor ONNXTensorInfo
+	w := bytesReader(data)
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return tensor, err
+		}
+		if wireType != wireBytes || (field != valueInfoNameField && field != valueInfoTypeField) {
+			if err := w.skip(wireType); err != nil {
+				return tensor, err
+			}
+			continue
+		}
+		b, err := w.readBytes()
+		if err != nil {
+			return tensor, err
+		}
+		if field == valueInfoNameField {
+			tensor.Name = string(b)
+			continue
+		}
+		// TypeProto: only tensor types have an element type and shape
+		tensorType, err := bytesField(b, typeTensorField)
+		if err != nil {
+			return tensor, fmt.Errorf("value %q: %w", tensor.Name, err)
+		}
+		if tensorType != nil {
+			if tensor.DType, tensor.Shape, err = parseTensorType(tensorType); err != nil {
+				return tensor, fmt.Errorf("value %q: %w", tensor.Name, err)
+			}
+		}
+	}
+	return tensor, nil
+}
+
+// parseTensorType reads a TypeProto.Tensor
+func parseTensorType(data []byte) (string, []int, error) {
+	var dtype string
+	var shape []int
+	w := bytesReader(data)
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return "", nil, err
+		}
+		switch {
+		case field == tensorTypeElemField && wireType == wireVarint:
+			v, err := w.readVarint()
+			if err != nil {
+				return "", nil, err
+			}
+			dtype = onnxElemTypes[v]
+			if dtype == "" {
+				dtype = fmt.Sprintf("onnx_type_%d", v)
+			}
+		case field == tensorTypeShapeField && wireType == wireBytes:
+			b, err := w.readBytes()
+			if err != nil {
+				return "", nil, err
+			}
+			if shape, err = parseShape(b); err != nil {
+				return "", nil, err
+			}
+		default:
+			if err := w.skip(wireType); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	return dtype, shape, nil
+}
+
+// parseShape reads a TensorShapeProto; symbolic dimensions ("batch_size") become -1
+func parseShape(data []byte) ([]int, error) {
+	shape := []int{}
+	w := bytesReader(data)
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return nil, err
+		}
+		if field != shapeDimField || wireType != wireBytes {
+			if err := w.skip(wireType); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		dim, err := w.readBytes()
+		if err != nil {
+			return nil, err
+		}
+		size := -1
+		d := bytesReader(dim)
+		for d.remaining > 0 {
+			field, wireType, err := d.readTag()
+			if err != nil {
+				return nil, err
+			}
+			if field == dimValueField && wireType == wireVarint {
+				v, err := d.readVarint()
+				if err != nil {
+					return nil, err
+				}
+				if int64(v) >= 0 {
+					size = int(v)
+				}
+				continue
+			}
+			if err := d.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+		shape = append(shape, size)
+	}
+	return shape, nil
+}
+
+// bytesField returns the first length-delimited value of field in a message, or nil
+func bytesField(data []byte, want int) ([]byte, error) {
+	w := bytesReader(data)
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return nil, err
+		}
+		if field == want && wireType == wireBytes {
+			return w.readBytes()
+		}
+		if err := w.skip(wireType); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// stringField returns the first string value of field in a message
+func stringField(data []byte, want int) (string, error) {
+	w := bytesReader(data)
+	var value string
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return "", err
+		}
+		if field == want && wireType == wireBytes && value == "" {
+			b, err := w.readBytes()
+			if err != nil {
+				return "", err
+			}
+			value = string(b)
+			continue
+		}
+		if err := w.skip(wireType); err != nil {
+			return "", err
+		}
+	}
+	return value, nil
+}
+
+// parseExternalData returns where an initializer's data is stored, or nil if it is inline
+func parseExternalData(data []byte) (*ExternalTensorData, error) {
+	w := bytesReader(data)
+	var external ExternalTensorData
+	var isExternal bool
+	for w.remaining > 0 {
+		field, wireType, err := w.readTag()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case field == tensorDataLocationField && wireType == wireVarint:
+			v, err := w.readVarint()
+			if err != nil {
+				return nil, err
+			}
+			isExternal = v == 1
+		case field == tensorExternalField && wireType == wireBytes:
+			entry, err := w.readBytes()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := stringField(entry, entryKeyField)
+			value, _ := stringField(entry, entryValueField)
+			switch key {
+			case "location":
+				external.Location = value
+			case "offset":
+				_, _ = fmt.Sscan(value, &external.Offset)
+			case "length":
+				_, _ = fmt.Sscan(value, &external.Length)
+			}
+		default:
+			if err := w.skip(wireType); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !isExternal {
+		return nil, nil
+	}
+	if external.Location == "" {
+		return nil, fmt.Errorf("external data has no location")
+	}
+	return &external, nil
+}
+
+// CheckONNXFile validates the structure of an ONNX model: the protobuf must parse to the
+// end of the file, the graph must have nodes and outputs, the default opset must be
+// declared and any external data files must exist and be large enough.
+func CheckONNXFile(path string) error {
+	info, err := InspectONNXFile(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	if info.IRVersion <= 0 {
+		return fmt.Errorf("%s: missing ir_version", filepath.Base(path))
+	}
+	if _, ok := info.Opsets[""]; !ok {
+		if _, ok := info.Opsets["ai.onnx"]; !ok {
+			return fmt.Errorf("%s: no default-domain opset import", filepath.Base(path))
+		}
+	}
+	if info.Nodes == 0 {
+		return fmt.Errorf("%s: graph has no nodes", filepath.Base(path))
+	}
+	if len(info.Outputs) == 0 {
+		return fmt.Errorf("%s: graph has no outputs", filepath.Base(path))
+	}
+
+	dir := filepath.Dir(path)
+	for _, external := range info.ExternalData {
+		stat, err := os.Stat(filepath.Join(dir, filepath.FromSlash(external.Location)))
+		if err != nil {
+			return fmt.Errorf("%s: external data file %s is missing", filepath.Base(path), external.Location)
+		}
+		if external.Offset+external.Length > stat.Size() {
+			return fmt.Errorf("%s: external data file %s is truncated (%d bytes, need %d)", filepath.Base(path), external.Location, stat.Size(), external.Offset+external.Length)
+		}
+	}
+	return nil
+}
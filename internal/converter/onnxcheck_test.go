@@ -0,0 +1,196 @@
+package converter
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// protobuf encoding helpers for building minimal ONNX models
+
+func pbVarint(v uint64) []byte {
+	return binary.AppendUvarint(nil, v)
+}
+
+func pbTag(field, wireType int) []byte {
+	return pbVarint(uint64(field<<3 | wireType))
+}
+
+func pbBytes(field int, data []byte) []byte {
+	out := append(pbTag(field, wireBytes), pbVarint(uint64(len(data)))...)
+	return append(out, data...)
+}
+
+func pbInt(field int, v uint64) []byte {
+	return append(pbTag(field, wireVarint), pbVarint(v)...)
+}
+
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+// testONNXModel builds a model with one Relu node; initializer is an optional TensorProto
+func testONNXModel(initializer []byte) []byte {
+	node := concat(pbBytes(1, []byte("x")), pbBytes(2, []byte("y")), pbBytes(nodeOpTypeField, []byte("Relu")))
+	graph := concat(
+		pbBytes(graphNodeField, node),
+		pbBytes(2, []byte("test-graph")),
+		pbBytes(graphInputField, pbBytes(valueInfoNameField, []byte("x"))),
+		pbBytes(graphOutputField, pbBytes(valueInfoNameField, []byte("y"))),
+	)
+	if initializer != nil {
+		graph = append(graph, pbBytes(graphInitializerField, initializer)...)
+	}
+	return concat(
+		pbInt(modelIRVersionField, 8),
+		pbBytes(2, []byte("axon-test")),
+		pbBytes(modelGraphField, graph),
+		pbBytes(modelOpsetImportField, concat(pbBytes(opsetDomainField, nil), pbInt(opsetVersionField, 14))),
+	)
+}
+
+// externalTensor builds a TensorProto whose data lives in an external file
+func externalTensor(location string, length int) []byte {
+	entry := func(key, value string) []byte {
+		return pbBytes(tensorExternalField, concat(pbBytes(entryKeyField, []byte(key)), pbBytes(entryValueField, []byte(value))))
+	}
+	return concat(
+		pbBytes(8, []byte("weight")),
+		entry("location", location),
+		entry("offset", "0"),
+		entry("length", strconv.Itoa(length)),
+		pbInt(tensorDataLocationField, 1),
+	)
+}
+
+func TestInspectONNXFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	if err := os.WriteFile(path, testONNXModel(nil), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := InspectONNXFile(path)
+	if err != nil {
+		t.Fatalf("InspectONNXFile() error = %v", err)
+	}
+	if info.IRVersion != 8 || info.Opsets[""] != 14 || info.Nodes != 1 {
+		t.Errorf("InspectONNXFile() = %+v", info)
+	}
+	if len(info.Inputs) != 1 || info.Inputs[0] != "x" || len(info.Outputs) != 1 || info.Outputs[0] != "y" {
+		t.Errorf("inputs = %v, outputs = %v", info.Inputs, info.Outputs)
+	}
+}
+
+// valueInfo builds a ValueInfoProto of a tensor; dims are sizes, or names of symbolic dimensions
+func valueInfo(name string, elemType uint64, dims ...interface{}) []byte {
+	var shape []byte
+	for _, dim := range dims {
+		switch d := dim.(type) {
+		case int:
+			shape = append(shape, pbBytes(shapeDimField, pbInt(dimValueField, uint64(d)))...)
+		case string:
+			shape = append(shape, pbBytes(shapeDimField, pbBytes(2, []byte(d)))...)
+		}
+	}
+	tensorType := concat(pbInt(tensorTypeElemField, elemType), pbBytes(tensorTypeShapeField, shape))
+	return concat(pbBytes(valueInfoNameField, []byte(name)), pbBytes(valueInfoTypeField, pbBytes(typeTensorField, tensorType)))
+}
+
+func TestInspectONNXFile_Tensors(t *testing.T) {
+	// A large inline initializer that is also listed as a graph input, as in IR version 3
+	weight := concat(pbBytes(1, pbVarint(4)), pbBytes(tensorNameField, []byte("weight")), pbBytes(9, make([]byte, maxInspectedMessage+1)))
+	graph := concat(
+		pbBytes(graphNodeField, pbBytes(nodeOpTypeField, []byte("Gemm"))),
+		pbBytes(graphInputField, valueInfo("pixel_values", 1, "batch_size", 3, 224, 224)),
+		pbBytes(graphInputField, valueInfo("weight", 1, 4)),
+		pbBytes(graphInputField, pbBytes(valueInfoNameField, []byte("untyped"))),
+		pbBytes(graphOutputField, valueInfo("logits", 1, "batch_size", 1000)),
+		pbBytes(graphOutputField, valueInfo("mask", 9)),
+		pbBytes(graphInitializerField, weight),
+	)
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	if err := os.WriteFile(path, concat(pbInt(modelIRVersionField, 3), pbBytes(modelGraphField, graph)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := InspectONNXFile(path)
+	if err != nil {
+		t.Fatalf("InspectONNXFile() error = %v", err)
+	}
+	wantInputs := []ONNXTensorInfo{
+		{Name: "pixel_values", DType: "float32", Shape: []int{-1, 3, 224, 224}},
+		{Name: "untyped"},
+	}
+	wantOutputs := []ONNXTensorInfo{
+		{Name: "logits", DType: "float32", Shape: []int{-1, 1000}},
+		{Name: "mask", DType: "bool", Shape: []int{}},
+	}
+	if !reflect.DeepEqual(info.InputTensors, wantInputs) {
+		t.Errorf("InputTensors = %+v, want %+v", info.InputTensors, wantInputs)
+	}
+	if !reflect.DeepEqual(info.OutputTensors, wantOutputs) {
+		t.Errorf("OutputTensors = %+v, want %+v", info.OutputTensors, wantOutputs)
+	}
+	if len(info.Inputs) != 3 || info.Initializers != 1 {
+		t.Errorf("Inputs = %v, Initializers = %d; want all 3 graph inputs and 1 initializer", info.Inputs, info.Initializers)
+	}
+}
+
+func TestCheckONNXFile(t *testing.T) {
+	valid := testONNXModel(nil)
+	noNodes := concat(
+		pbInt(modelIRVersionField, 8),
+		pbBytes(modelGraphField, pbBytes(graphOutputField, pbBytes(valueInfoNameField, []byte("y")))),
+		pbBytes(modelOpsetImportField, pbInt(opsetVersionField, 14)),
+	)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		extra   map[string]int // external data files and their sizes
+		wantErr string         // "" means the file must pass
+	}{
+		{name: "valid", data: valid},
+		{name: "truncated", data: valid[:len(valid)-10], wantErr: "truncated"},
+		{name: "garbage", data: []byte(strings.Repeat("\xff", 64)), wantErr: "varint"},
+		{name: "no graph", data: pbInt(modelIRVersionField, 8), wantErr: "no graph"},
+		{name: "no nodes", data: noNodes, wantErr: "no nodes"},
+		{name: "external data present", data: testONNXModel(externalTensor("model.onnx_data", 1024)), extra: map[string]int{"model.onnx_data": 1024}},
+		{name: "external data missing", data: testONNXModel(externalTensor("model.onnx_data", 1024)), wantErr: "missing"},
+		{name: "external data truncated", data: testONNXModel(externalTensor("model.onnx_data", 1024)), extra: map[string]int{"model.onnx_data": 100}, wantErr: "truncated"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "model.onnx")
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			for name, size := range tt.extra {
+				if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := CheckONNXFile(path)
+			if (err != nil) != (tt.wantErr != "") {
+				t.Fatalf("CheckONNXFile() error = %v, want error containing %q", err, tt.wantErr)
+			}
+			if tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckONNXFile() error = %v, want %q", err, tt.wantErr)
+			}
+			if ValidateONNXFile(path) != (err == nil) {
+				t.Error("ValidateONNXFile() disagrees with CheckONNXFile()")
+			}
+		})
+	}
+}
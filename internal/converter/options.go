@@ -0,0 +1,259 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options controls how a model is exported to ONNX
+type Options struct {
+	Opset       int    // ONNX opset version (0 = converter default)
+	Task        string // Export task (e.g., "text-classification"); empty = auto-detect
+	ModelTask   string // The model's task (spec.task); picks the export task when Task is empty
+	DynamicAxes bool   // Export with dynamic batch/sequence axes
+
+	Timeout        time.Duration // Abort a conversion attempt after this long (0 = no limit)
+	RetryOnTimeout bool          // Retry once in safe mode after a timeout
+	SafeMode       bool          // Disable constant folding and use shorter dummy sequences
+
+	Memory string // Docker converter memory limit, e.g. "8g" (empty = unlimited)
+	CPUs   string // Docker converter CPU limit, e.g. "2" or "1.5" (empty = unlimited)
+
+	ResultCacheDir string // Reuse conversion outputs from this result cache (empty = disabled)
+
+	ParityCheck     bool    // Compare source and ONNX outputs on a sample input after export
+	ParityTolerance float64 // Largest accepted absolute output deviation (0 = DefaultParityTolerance)
+
+	Precision string // Weight precision of the ONNX files: PrecisionFP32 (default), PrecisionFP16 or PrecisionINT8
+}
+
+// ONNX weight precisions accepted by --precision
+const (
+	PrecisionFP32 = "fp32"
+	PrecisionFP16 = "fp16"
+	PrecisionINT8 = "int8" // Dynamic quantization of the weights
+)
+
+// precisionWeightBytes is the bytes per weight of each precision
+var precisionWeightBytes = map[string]float64{
+	PrecisionFP32: 4,
+	PrecisionFP16: 2,
+	PrecisionINT8: 1,
+}
+
+// DefaultOptions returns the options used when no conversion flags are given
+func DefaultOptions() Options {
+	return Options{DynamicAxes: true}
+}
+
+// Minimum and maximum opset versions accepted by --opset
+const (
+	MinOpsetVersion = 7
+	MaxOpsetVersion = 21
+)
+
+// memoryLimitPattern matches Docker memory sizes such as 512m, 8g or 1.5G
+var memoryLimitPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?[bkmgBKMG]?$`)
+
+// taskModelClasses maps supported export tasks to the transformers AutoModel class used for them
+var taskModelClasses = map[string]string{
+	"feature-extraction":             "AutoModel",
+	"text-classification":            "AutoModelForSequenceClassification",
+	"token-classification":           "AutoModelForTokenClassification",
+	"question-answering":             "AutoModelForQuestionAnswering",
+	"fill-mask":                      "AutoModelForMaskedLM",
+	"text-generation":                "AutoModelForCausalLM",
+	"text2text-generation":           "AutoModelForSeq2SeqLM",
+	"image-classification":           "AutoModelForImageClassification",
+	"object-detection":               "AutoModelForObjectDetection",
+	"semantic-segmentation":          "AutoModelForSemanticSegmentation",
+	"zero-shot-image-classification": "AutoModel",
+	"automatic-speech-recognition":   "AutoModelForSpeechSeq2Seq",
+	"audio-classification":           "AutoModelForAudioClassification",
+}
+
+// taskAliases maps transformers-style task names to their Optimum equivalents
+var taskAliases = map[string]string{
+	"sequence-classification": "text-classification",
+	"masked-lm":               "fill-mask",
+	"causal-lm":               "text-generation",
+	"seq2seq-lm":              "text2text-generation",
+	"default":                 "feature-extraction",
+}
+
+// modelTaskExports maps manifest tasks that aren't export tasks to the export task that
+// produces a model for them
+var modelTaskExports = map[string]string{
+	"summarization":            "text2text-generation",
+	"translation":              "text2text-generation",
+	"image-segmentation":       "semantic-segmentation",
+	"sentence-similarity":      "feature-extraction",
+	"zero-shot-classification": "text-classification",
+}
+
+// ExportTask returns the export task for a model's task (spec.task), or "" if no
+// supported export task fits it
+func ExportTask(modelTask string) string {
+	task := strings.ToLower(modelTask)
+	if canonical, ok := modelTaskExports[task]; ok {
+		task = canonical
+	}
+	if canonical, ok := taskAliases[task]; ok {
+		task = canonical
+	}
+	if _, ok := taskModelClasses[task]; !ok {
+		return ""
+	}
+	return task
+}
+
+// SupportedTasks returns the export tasks accepted by --task
+func SupportedTasks() []string {
+	tasks := make([]string, 0, len(taskModelClasses)+len(taskAliases))
+	for task := range taskModelClasses {
+		tasks = append(tasks, task)
+	}
+	for alias := range taskAliases {
+		tasks = append(tasks, alias)
+	}
+	sort.Strings(tasks)
+	return tasks
+}
+
+// Validate checks the options and normalizes task aliases
+func (o *Options) Validate() error {
+	if o.Opset != 0 && (o.Opset < MinOpsetVersion || o.Opset > MaxOpsetVersion) {
+		return fmt.Errorf("invalid opset %d (must be between %d and %d)", o.Opset, MinOpsetVersion, MaxOpsetVersion)
+	}
+
+	if o.ParityTolerance < 0 {
+		return fmt.Errorf("invalid parity tolerance %g (must not be negative)", o.ParityTolerance)
+	}
+
+	if o.Memory != "" && !memoryLimitPattern.MatchString(o.Memory) {
+		return fmt.Errorf("invalid memory limit %q (expected a size such as 512m or 8g)", o.Memory)
+	}
+	if o.CPUs != "" {
+		if cpus, err := strconv.ParseFloat(o.CPUs, 64); err != nil || cpus <= 0 {
+			return fmt.Errorf("invalid CPU limit %q (expected a positive number such as 2 or 1.5)", o.CPUs)
+		}
+	}
+
+	if o.Precision != "" {
+		precision := strings.ToLower(o.Precision)
+		if _, ok := precisionWeightBytes[precision]; !ok {
+			return fmt.Errorf("unsupported precision %q (supported: %s, %s, %s)", o.Precision, PrecisionFP32, PrecisionFP16, PrecisionINT8)
+		}
+		o.Precision = precision
+	}
+
+	if o.Task != "" {
+		task := strings.ToLower(o.Task)
+		if canonical, ok := taskAliases[task]; ok {
+			task = canonical
+		}
+		if _, ok := taskModelClasses[task]; !ok {
+			return fmt.Errorf("unsupported task %q (supported: %s)", o.Task, strings.Join(SupportedTasks(), ", "))
+		}
+		o.Task = task
+	}
+
+	return nil
+}
+
+// isDefault reports whether no conversion options were customized.
+// Pre-converted downloads are only used in that case, since their opset and task are fixed
+// and there is no source run to check their parity against.
+func (o Options) isDefault() bool {
+	return o.Opset == 0 && o.Task == "" && o.DynamicAxes && !o.ParityCheck
+}
+
+// precision returns the requested weight precision, PrecisionFP32 if none was requested
+func (o Options) precision() string {
+	if o.Precision == "" {
+		return PrecisionFP32
+	}
+	return o.Precision
+}
+
+// opsetOr returns the requested opset, or def if none was requested
+func (o Options) opsetOr(def int) int {
+	if o.Opset > 0 {
+		return o.Opset
+	}
+	return def
+}
+
+// parityTolerance returns the parity check tolerance, or DefaultParityTolerance if none was given
+func (o Options) parityTolerance() float64 {
+	if o.ParityTolerance > 0 {
+		return o.ParityTolerance
+	}
+	return DefaultParityTolerance
+}
+
+// maxSeqLen returns the dummy input sequence length used for export
+func (o Options) maxSeqLen() int {
+	if o.SafeMode {
+		return 32
+	}
+	return 128
+}
+
+// exportTask returns the requested export task, or else the one for the model's task
+func (o Options) exportTask() string {
+	if o.Task != "" {
+		return o.Task
+	}
+	return ExportTask(o.ModelTask)
+}
+
+// modelClass returns the AutoModel class to load for the export task
+func (o Options) modelClass() string {
+	if class, ok := taskModelClasses[o.exportTask()]; ok {
+		return class
+	}
+	return "AutoModel"
+}
+
+// env returns the environment variables read by the conversion scripts
+func (o Options) env() []string {
+	var env []string
+	if o.Opset > 0 {
+		env = append(env, "AXON_ONNX_OPSET="+strconv.Itoa(o.Opset))
+	}
+	if task := o.exportTask(); task != "" {
+		env = append(env, "AXON_ONNX_TASK="+task)
+	}
+	if !o.DynamicAxes {
+		env = append(env, "AXON_ONNX_DYNAMIC_AXES=0")
+	}
+	if o.SafeMode {
+		env = append(env, "AXON_ONNX_SAFE_MODE=1")
+	}
+	if precision := o.precision(); precision != PrecisionFP32 {
+		env = append(env, "AXON_ONNX_PRECISION="+precision)
+	}
+	if o.ParityCheck {
+		env = append(env, "AXON_ONNX_PARITY=1", "AXON_ONNX_PARITY_TOLERANCE="+strconv.FormatFloat(o.parityTolerance(), 'g', -1, 64))
+	}
+	return env
+}
+
+// dockerResourceArgs returns the `docker run` flags that apply the resource limits.
+// Swap is capped at the memory limit so an oversized conversion is OOM-killed
+// instead of thrashing the host.
+func (o Options) dockerResourceArgs() []string {
+	var args []string
+	if o.Memory != "" {
+		args = append(args, "--memory", o.Memory, "--memory-swap", o.Memory)
+	}
+	if o.CPUs != "" {
+		args = append(args, "--cpus", o.CPUs)
+	}
+	return args
+}
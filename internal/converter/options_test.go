@@ -0,0 +1,122 @@
+package converter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     Options
+		wantTask string
+		wantErr  bool
+	}{
+		{name: "defaults", opts: DefaultOptions()},
+		{name: "valid opset", opts: Options{Opset: 17, DynamicAxes: true}},
+		{name: "opset too low", opts: Options{Opset: 3}, wantErr: true},
+		{name: "opset too high", opts: Options{Opset: 99}, wantErr: true},
+		{name: "known task", opts: Options{Task: "token-classification"}, wantTask: "token-classification"},
+		{name: "task alias", opts: Options{Task: "Sequence-Classification"}, wantTask: "text-classification"},
+		{name: "unknown task", opts: Options{Task: "dance"}, wantErr: true},
+		{name: "resource limits", opts: Options{Memory: "8g", CPUs: "1.5"}},
+		{name: "bad memory", opts: Options{Memory: "lots"}, wantErr: true},
+		{name: "zero cpus", opts: Options{CPUs: "0"}, wantErr: true},
+		{name: "negative parity tolerance", opts: Options{ParityCheck: true, ParityTolerance: -1}, wantErr: true},
+		{name: "int8 precision", opts: Options{Precision: "INT8"}},
+		{name: "unknown precision", opts: Options{Precision: "int4"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			err := opts.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && opts.Task != tt.wantTask {
+				t.Errorf("Task = %q, want %q", opts.Task, tt.wantTask)
+			}
+		})
+	}
+}
+
+func TestOptions_Env(t *testing.T) {
+	if env := DefaultOptions().env(); len(env) != 0 {
+		t.Errorf("DefaultOptions().env() = %v, want empty", env)
+	}
+
+	opts := Options{Opset: 17, Task: "image-classification", DynamicAxes: false}
+	want := []string{"AXON_ONNX_OPSET=17", "AXON_ONNX_TASK=image-classification", "AXON_ONNX_DYNAMIC_AXES=0"}
+	if got := opts.env(); !reflect.DeepEqual(got, want) {
+		t.Errorf("env() = %v, want %v", got, want)
+	}
+	if safe := (Options{DynamicAxes: true, SafeMode: true}); !reflect.DeepEqual(safe.env(), []string{"AXON_ONNX_SAFE_MODE=1"}) || safe.maxSeqLen() != 32 {
+		t.Errorf("safe mode env() = %v, maxSeqLen() = %d", safe.env(), safe.maxSeqLen())
+	}
+	if opts.isDefault() {
+		t.Error("isDefault() = true for customized options")
+	}
+	parity := Options{DynamicAxes: true, ParityCheck: true, ParityTolerance: 1e-4}
+	if got, want := parity.env(), []string{"AXON_ONNX_PARITY=1", "AXON_ONNX_PARITY_TOLERANCE=0.0001"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parity env() = %v, want %v", got, want)
+	}
+	int8 := Options{DynamicAxes: true, Precision: PrecisionINT8}
+	if got := int8.env(); !reflect.DeepEqual(got, []string{"AXON_ONNX_PRECISION=int8"}) || !int8.isDefault() {
+		t.Errorf("int8 env() = %v, isDefault() = %v, want pre-converted int8 files to stay usable", got, int8.isDefault())
+	}
+	if got := DefaultOptions().precision(); got != PrecisionFP32 {
+		t.Errorf("DefaultOptions().precision() = %q, want fp32", got)
+	}
+	if parity.isDefault() {
+		t.Error("isDefault() = true with a parity check, pre-converted downloads can't be checked")
+	}
+	if got := opts.modelClass(); got != "AutoModelForImageClassification" {
+		t.Errorf("modelClass() = %q", got)
+	}
+	if got := opts.opsetOr(PythonOpsetVersion); got != 17 {
+		t.Errorf("opsetOr() = %d, want 17", got)
+	}
+}
+
+func TestExportTask(t *testing.T) {
+	tests := map[string]string{
+		"image-classification":         "image-classification",
+		"automatic-speech-recognition": "automatic-speech-recognition",
+		"summarization":                "text2text-generation",
+		"image-segmentation":           "semantic-segmentation",
+		"Sentence-Similarity":          "feature-extraction",
+		"image-to-text":                "",
+		"":                             "",
+	}
+	for task, want := range tests {
+		if got := ExportTask(task); got != want {
+			t.Errorf("ExportTask(%q) = %q, want %q", task, got, want)
+		}
+	}
+
+	// The model's task picks the export without counting as a customized option
+	opts := Options{DynamicAxes: true, ModelTask: "image-classification"}
+	if got := opts.modelClass(); got != "AutoModelForImageClassification" {
+		t.Errorf("modelClass() = %q", got)
+	}
+	if !reflect.DeepEqual(opts.env(), []string{"AXON_ONNX_TASK=image-classification"}) || !opts.isDefault() {
+		t.Errorf("env() = %v, isDefault() = %v", opts.env(), opts.isDefault())
+	}
+	opts.Task = "feature-extraction"
+	if got := opts.exportTask(); got != "feature-extraction" {
+		t.Errorf("exportTask() = %q, want --task to override the model's task", got)
+	}
+}
+
+func TestOptions_DockerResourceArgs(t *testing.T) {
+	if args := DefaultOptions().dockerResourceArgs(); len(args) != 0 {
+		t.Errorf("DefaultOptions().dockerResourceArgs() = %v, want empty", args)
+	}
+
+	opts := Options{Memory: "8g", CPUs: "2"}
+	want := []string{"--memory", "8g", "--memory-swap", "8g", "--cpus", "2"}
+	if got := opts.dockerResourceArgs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("dockerResourceArgs() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// DefaultParityTolerance is the largest absolute output deviation accepted by the parity
+// check when no tolerance is given. It allows for float32 reordering and fused operators
+// while catching exports that compute something different.
+const DefaultParityTolerance = 1e-3
+
+// parityReportFileName is written next to the ONNX output by conversion scripts that ran
+// the parity check; Axon reads and removes it so it is never packaged
+const parityReportFileName = "parity.json"
+
+// ErrParityMismatch is returned when the exported ONNX model's outputs deviate from the
+// source model's by more than the tolerance
+var ErrParityMismatch = errors.New("ONNX outputs differ from the source model")
+
+// ParityReport is the outcome of running a sample input through the source model and
+// the exported ONNX model
+type ParityReport struct {
+	MaxAbsDiff      float64 `json:"max_abs_diff"`
+	Tolerance       float64 `json:"tolerance"`
+	OutputsCompared int     `json:"outputs_compared,omitempty"`
+	Passed          bool    `json:"passed"`
+	Skipped         string  `json:"skipped,omitempty"` // Why the check could not run
+	Error           string  `json:"error,omitempty"`   // Why the outputs could not be compared (e.g., NaN outputs)
+}
+
+// readParityReport reads and removes the parity report a conversion script left in dir.
+// It returns nil if the script didn't write one.
+func readParityReport(dir string) (*ParityReport, error) {
+	path := filepath.Join(dir, parityReportFileName)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parity report: %w", err)
+	}
+	_ = os.Remove(path)
+
+	var report ParityReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse parity report: %w", err)
+	}
+	return &report, nil
+}
+
+// check returns ErrParityMismatch if the check ran and the outputs deviate too much.
+// A skipped check is not an error.
+func (r *ParityReport) check() error {
+	if r.Skipped != "" || r.Passed {
+		return nil
+	}
+	if r.Error != "" {
+		return fmt.Errorf("%w: %s", ErrParityMismatch, r.Error)
+	}
+	return fmt.Errorf("%w: max deviation %g exceeds tolerance %g", ErrParityMismatch, r.MaxAbsDiff, r.Tolerance)
+}
+
+// parityResult returns the parity outcome of a conversion in modelDir, or nil if the
+// check wasn't requested. Converters that don't run the check (the local Python
+// fallback) produce a skipped report.
+func parityResult(modelDir, method string, opts Options) (*ParityReport, error) {
+	report, err := readParityReport(modelDir)
+	if !opts.ParityCheck {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		report = &ParityReport{
+			Tolerance: opts.parityTolerance(),
+			Skipped:   fmt.Sprintf("not supported by the %s converter", method),
+		}
+	}
+	return report, nil
+}
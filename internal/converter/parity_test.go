@@ -0,0 +1,87 @@
+package converter
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParityResult(t *testing.T) {
+	tests := []struct {
+		name        string
+		report      string // parity.json written by the script ("" = none)
+		opts        Options
+		wantNil     bool
+		wantSkipped bool
+		wantErr     error
+	}{
+		{name: "not requested", report: `{"max_abs_diff": 1, "tolerance": 0.001, "passed": false}`, opts: DefaultOptions(), wantNil: true},
+		{name: "passed", report: `{"max_abs_diff": 0.00002, "tolerance": 0.001, "outputs_compared": 1, "passed": true}`, opts: Options{ParityCheck: true}},
+		{name: "deviates", report: `{"max_abs_diff": 0.5, "tolerance": 0.001, "outputs_compared": 1, "passed": false}`, opts: Options{ParityCheck: true}, wantErr: ErrParityMismatch},
+		{name: "non-finite outputs", report: `{"tolerance": 0.001, "passed": false, "error": "outputs contain NaN or Inf"}`, opts: Options{ParityCheck: true}, wantErr: ErrParityMismatch},
+		{name: "skipped by script", report: `{"tolerance": 0.001, "skipped": "multi-file exports are not compared"}`, opts: Options{ParityCheck: true}, wantSkipped: true},
+		{name: "no report", opts: Options{ParityCheck: true}, wantSkipped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			reportPath := filepath.Join(dir, parityReportFileName)
+			if tt.report != "" {
+				if err := os.WriteFile(reportPath, []byte(tt.report), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := parityResult(dir, ConversionMethodPython, tt.opts)
+			if err != nil {
+				t.Fatalf("parityResult() error = %v", err)
+			}
+			if _, err := os.Stat(reportPath); !os.IsNotExist(err) {
+				t.Error("parity report left in the model directory, it would be packaged")
+			}
+			if tt.wantNil {
+				if report != nil {
+					t.Errorf("parityResult() = %+v, want nil", report)
+				}
+				return
+			}
+			if report == nil {
+				t.Fatal("parityResult() = nil")
+			}
+			if (report.Skipped != "") != tt.wantSkipped {
+				t.Errorf("Skipped = %q, want skipped %t", report.Skipped, tt.wantSkipped)
+			}
+			if err := report.check(); !errors.Is(err, tt.wantErr) || (err == nil) != (tt.wantErr == nil) {
+				t.Errorf("check() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCachedConversion_ParityChecked(t *testing.T) {
+	passed := &ParityReport{MaxAbsDiff: 1e-4, Tolerance: 1e-3, Passed: true}
+	tests := []struct {
+		name   string
+		parity *ParityReport
+		opts   Options
+		want   bool
+	}{
+		{name: "not requested", opts: DefaultOptions(), want: true},
+		{name: "never checked", opts: Options{ParityCheck: true}, want: false},
+		{name: "checked", parity: passed, opts: Options{ParityCheck: true}, want: true},
+		{name: "within a stricter tolerance", parity: passed, opts: Options{ParityCheck: true, ParityTolerance: 5e-4}, want: true},
+		{name: "outside a stricter tolerance", parity: passed, opts: Options{ParityCheck: true, ParityTolerance: 1e-5}, want: false},
+		{name: "skipped", parity: &ParityReport{Skipped: "multi-file exports are not compared"}, opts: Options{ParityCheck: true}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &cachedConversion{Parity: tt.parity}
+			if got := entry.parityChecked(tt.opts); got != tt.want {
+				t.Errorf("parityChecked() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
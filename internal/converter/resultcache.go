@@ -0,0 +1,351 @@
+package converter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// ResultCache stores conversion outputs in a content-addressed blob store so that
+// converting the same source with the same converter and options can be skipped.
+//
+// Layout:
+//
+//	<dir>/blobs/sha256/<hex>   converted files, named by their SHA256
+//	<dir>/index/<key>.json     the files produced for a conversion key
+//
+// Blobs are immutable, so the directory can be shared between machines (e.g., on a
+// network volume) without coordination.
+type ResultCache struct {
+	dir string
+}
+
+// NewResultCache returns a result cache rooted at dir
+func NewResultCache(dir string) *ResultCache {
+	return &ResultCache{dir: dir}
+}
+
+// cachedFile is a conversion output stored in the blob store
+type cachedFile struct {
+	Path   string `json:"path"` // Relative to the model directory
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// cachedConversion is an index entry of the result cache
+type cachedConversion struct {
+	Key          string        `json:"key"`
+	CreatedAt    string        `json:"created_at"`
+	Method       string        `json:"method"`
+	Image        string        `json:"image,omitempty"`
+	ImageDigest  string        `json:"image_digest,omitempty"`
+	Opset        int           `json:"opset,omitempty"`
+	Toolchain    *Toolchain    `json:"toolchain,omitempty"`
+	SafeMode     bool          `json:"safe_mode,omitempty"`
+	Precision    string        `json:"precision,omitempty"`
+	Architecture string        `json:"architecture,omitempty"`
+	Parity       *ParityReport `json:"parity,omitempty"`
+	Files        []cachedFile  `json:"files"`
+}
+
+// parityChecked reports whether the cached outputs passed a parity check at least as
+// strict as the one opts ask for
+func (e *cachedConversion) parityChecked(opts Options) bool {
+	if !opts.ParityCheck {
+		return true
+	}
+	return e.Parity != nil && e.Parity.Skipped == "" && e.Parity.Error == "" && e.Parity.MaxAbsDiff <= opts.parityTolerance()
+}
+
+// ConversionCacheKey derives the result cache key from the source model digest, the
+// converter identity (image digest or local toolchain) and the export options
+func ConversionCacheKey(sourceDigest, converterID string, opts Options) string {
+	h := sha256.New()
+	_, _ = fmt.Fprintf(h, "source=%s\nconverter=%s\nopset=%d\ntask=%s\ndynamic_axes=%t\n",
+		sourceDigest, converterID, opts.Opset, opts.exportTask(), opts.DynamicAxes)
+	// Keys of fp32 conversions predate --precision and stay as they were
+	if precision := opts.precision(); precision != PrecisionFP32 {
+		_, _ = fmt.Fprintf(h, "precision=%s\n", precision)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isConversionOutput reports whether a file in a model directory is produced by conversion
+func isConversionOutput(relPath string) bool {
+	base := filepath.Base(relPath)
+	return strings.HasSuffix(base, ".onnx") ||
+		strings.HasSuffix(base, ".onnx_data") ||
+		strings.HasSuffix(base, ".onnx.data") ||
+		base == "onnx_manifest.json"
+}
+
+// isAxonManaged reports whether a file is written by Axon itself rather than part of the model
+func isAxonManaged(relPath string) bool {
+	base := filepath.Base(relPath)
+	return base == "manifest.yaml" || base == ".axon_metadata.json" || base == "files.json" || base == "provenance.json" || strings.HasSuffix(base, ".axon")
+}
+
+// SourceDigest returns a digest of the source model files in dir, ignoring conversion
+// outputs and files managed by Axon, so it is the same before and after conversion
+func SourceDigest(dir string) (string, error) {
+	var entries []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if isConversionOutput(relPath) || isAxonManaged(relPath) {
+			return nil
+		}
+		hash, err := utils.ComputeSHA256(path)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, filepath.ToSlash(relPath)+" "+hash)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash source model: %w", err)
+	}
+	sort.Strings(entries)
+
+	h := sha256.New()
+	for _, entry := range entries {
+		_, _ = fmt.Fprintln(h, entry)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// converterIdentity identifies the converter that would run for namespace: the Docker
+// image digest when Docker is available, otherwise the local Python toolchain versions.
+// It returns "" when neither can be determined, which disables the result cache.
+func converterIdentity(ctx context.Context, namespace string) string {
+	if IsDockerAvailable() {
+		if digest := DockerImageDigest(ctx, getDockerImageForRepository(namespace)); digest != "" {
+			return digest
+		}
+	}
+	if toolchain, err := ProbeToolchain(ctx, ConversionMethodPython, ""); err == nil && toolchain != nil && toolchain.Torch != "" {
+		return fmt.Sprintf("local-python python=%s torch=%s transformers=%s optimum=%s onnx=%s",
+			toolchain.Python, toolchain.Torch, toolchain.Transformers, toolchain.Optimum, toolchain.ONNX)
+	}
+	return ""
+}
+
+func (c *ResultCache) indexPath(key string) string {
+	return filepath.Join(c.dir, "index", key+".json")
+}
+
+func (c *ResultCache) blobPath(sha string) string {
+	return filepath.Join(c.dir, "blobs", "sha256", sha)
+}
+
+// Lookup returns the cached conversion for key, if all of its blobs are present
+func (c *ResultCache) Lookup(key string) (*cachedConversion, bool) {
+	data, err := os.ReadFile(c.indexPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedConversion
+	if err := json.Unmarshal(data, &entry); err != nil || len(entry.Files) == 0 {
+		return nil, false
+	}
+	for _, file := range entry.Files {
+		stat, err := os.Stat(c.blobPath(file.SHA256))
+		if err != nil || stat.Size() != file.Size {
+			return nil, false
+		}
+	}
+	return &entry, true
+}
+
+// Restore places the cached conversion outputs into modelDir
+func (c *ResultCache) Restore(entry *cachedConversion, modelDir string) error {
+	for _, file := range entry.Files {
+		dest := filepath.Join(modelDir, filepath.FromSlash(file.Path))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+		_ = os.Remove(dest)
+		// Blobs are immutable, so a hard link is safe and avoids copying multi-GB files
+		if err := os.Link(c.blobPath(file.SHA256), dest); err != nil {
+			if err := copyBlob(c.blobPath(file.SHA256), dest); err != nil {
+				return fmt.Errorf("failed to restore %s: %w", file.Path, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Store records the outputs of a successful conversion under key
+func (c *ResultCache) Store(key, modelDir string, result *ConversionResult) error {
+	entry := cachedConversion{
+		Key:          key,
+		CreatedAt:    time.Now().Format(time.RFC3339),
+		Method:       result.Method,
+		Image:        result.Image,
+		ImageDigest:  result.ImageDigest,
+		Opset:        result.Opset,
+		Toolchain:    result.Toolchain,
+		SafeMode:     result.SafeMode,
+		Precision:    result.Precision,
+		Architecture: result.Architecture,
+		Parity:       result.Parity,
+	}
+
+	for _, path := range conversionOutputFiles(modelDir, result) {
+		relPath, err := filepath.Rel(modelDir, path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve output path: %w", err)
+		}
+		sha, err := utils.ComputeSHA256(path)
+		if err != nil {
+			return err
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("failed to stat output: %w", err)
+		}
+
+		blob := c.blobPath(sha)
+		if _, err := os.Stat(blob); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(blob), 0755); err != nil {
+				return fmt.Errorf("failed to create blob store: %w", err)
+			}
+			if err := copyBlob(path, blob); err != nil {
+				return fmt.Errorf("failed to store %s: %w", relPath, err)
+			}
+			_ = os.Chmod(blob, 0444)
+		}
+		entry.Files = append(entry.Files, cachedFile{Path: filepath.ToSlash(relPath), SHA256: sha, Size: stat.Size()})
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.indexPath(key)), 0755); err != nil {
+		return fmt.Errorf("failed to create cache index: %w", err)
+	}
+	if err := os.WriteFile(c.indexPath(key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// conversionOutputFiles lists the files a conversion produced: the ONNX models, the external
+// data they reference and the multi-encoder manifest
+func conversionOutputFiles(modelDir string, result *ConversionResult) []string {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		if _, err := os.Stat(path); err == nil {
+			seen[path] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, file := range result.AllFiles {
+		add(file)
+		if info, err := InspectONNXFile(file); err == nil {
+			for _, external := range info.ExternalData {
+				add(filepath.Join(filepath.Dir(file), filepath.FromSlash(external.Location)))
+			}
+		}
+	}
+	if result.ManifestPath != "" {
+		add(result.ManifestPath)
+	}
+	return files
+}
+
+// resultCacheFor returns the result cache and key for a conversion, or nil if caching is
+// disabled or the source or converter can't be identified
+func resultCacheFor(ctx context.Context, modelPath, namespace string, opts Options) (*ResultCache, string) {
+	if opts.ResultCacheDir == "" {
+		return nil, ""
+	}
+	sourceDigest, err := SourceDigest(modelPath)
+	if err != nil {
+		return nil, ""
+	}
+	converterID := converterIdentity(ctx, namespace)
+	if converterID == "" {
+		return nil, ""
+	}
+	return NewResultCache(opts.ResultCacheDir), ConversionCacheKey(sourceDigest, converterID, opts)
+}
+
+// restoreCachedConversion restores a cached conversion into modelDir and checks the result
+func restoreCachedConversion(c *ResultCache, entry *cachedConversion, outputPath string) (*ConversionResult, error) {
+	modelDir := filepath.Dir(outputPath)
+	if err := c.Restore(entry, modelDir); err != nil {
+		return nil, err
+	}
+
+	result := CheckConversionResult(modelDir, outputPath)
+	if !result.Success {
+		return nil, fmt.Errorf("cached conversion did not restore any ONNX files")
+	}
+	for _, file := range result.AllFiles {
+		if err := CheckONNXFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Method = entry.Method
+	result.Image = entry.Image
+	result.ImageDigest = entry.ImageDigest
+	result.Opset = entry.Opset
+	result.Toolchain = entry.Toolchain
+	result.SafeMode = entry.SafeMode
+	result.Precision = entry.Precision
+	result.Parity = entry.Parity
+	result.Attempts = 1
+	result.Cached = true
+	return result, nil
+}
+
+// copyBlob copies src to dst via a temporary file so readers never see a partial blob
+func copyBlob(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+
+	tmp := dst + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,116 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceDigest_IgnoresConversionOutputs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("config.json", `{"model_type": "bert"}`)
+	write("model.safetensors", "weights")
+
+	before, err := SourceDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("model.onnx", "converted")
+	write("manifest.yaml", "kind: Model")
+	after, err := SourceDigest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before != after {
+		t.Errorf("SourceDigest() changed after conversion: %s -> %s", before, after)
+	}
+
+	write("model.safetensors", "other weights")
+	if changed, _ := SourceDigest(dir); changed == before {
+		t.Error("SourceDigest() should change when source weights change")
+	}
+}
+
+func TestConversionCacheKey(t *testing.T) {
+	base := ConversionCacheKey("sha256:src", "image@sha256:abc", DefaultOptions())
+	if base != ConversionCacheKey("sha256:src", "image@sha256:abc", DefaultOptions()) {
+		t.Error("ConversionCacheKey() is not deterministic")
+	}
+
+	variants := []string{
+		ConversionCacheKey("sha256:other", "image@sha256:abc", DefaultOptions()),
+		ConversionCacheKey("sha256:src", "image@sha256:def", DefaultOptions()),
+		ConversionCacheKey("sha256:src", "image@sha256:abc", Options{Opset: 17, DynamicAxes: true}),
+		ConversionCacheKey("sha256:src", "image@sha256:abc", Options{Task: "fill-mask", DynamicAxes: true}),
+		ConversionCacheKey("sha256:src", "image@sha256:abc", Options{}),
+		ConversionCacheKey("sha256:src", "image@sha256:abc", Options{Precision: PrecisionINT8, DynamicAxes: true}),
+	}
+	for i, key := range variants {
+		if key == base {
+			t.Errorf("variant %d has the same key as the base conversion", i)
+		}
+	}
+	if key := ConversionCacheKey("sha256:src", "image@sha256:abc", Options{Precision: PrecisionFP32, DynamicAxes: true}); key != base {
+		t.Error("an explicit fp32 precision changed the key of cached fp32 conversions")
+	}
+}
+
+func TestResultCache_StoreAndRestore(t *testing.T) {
+	cache := NewResultCache(filepath.Join(t.TempDir(), "conversions"))
+	key := ConversionCacheKey("sha256:src", "image@sha256:abc", DefaultOptions())
+
+	if _, ok := cache.Lookup(key); ok {
+		t.Fatal("Lookup() hit on an empty cache")
+	}
+
+	// A converted model with external data
+	srcDir := t.TempDir()
+	onnxPath := filepath.Join(srcDir, "model.onnx")
+	if err := os.WriteFile(onnxPath, testONNXModel(externalTensor("model.onnx_data", 64)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "model.onnx_data"), make([]byte, 64), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result := CheckConversionResult(srcDir, onnxPath)
+	result.Method = ConversionMethodDocker
+	result.ImageDigest = "image@sha256:abc"
+	result.Opset = DockerOpsetVersion
+
+	if err := cache.Store(key, srcDir, result); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	entry, ok := cache.Lookup(key)
+	if !ok {
+		t.Fatal("Lookup() missed after Store()")
+	}
+	if len(entry.Files) != 2 {
+		t.Errorf("cached files = %+v, want model.onnx and its external data", entry.Files)
+	}
+
+	destDir := t.TempDir()
+	restored, err := restoreCachedConversion(cache, entry, filepath.Join(destDir, "model.onnx"))
+	if err != nil {
+		t.Fatalf("restoreCachedConversion() error = %v", err)
+	}
+	if !restored.Success || !restored.Cached || restored.Method != ConversionMethodDocker || restored.ImageDigest != "image@sha256:abc" {
+		t.Errorf("restored result = %+v", restored)
+	}
+	if _, err := os.Stat(filepath.Join(destDir, "model.onnx_data")); err != nil {
+		t.Errorf("external data not restored: %v", err)
+	}
+
+	// A missing blob invalidates the entry
+	if err := os.Remove(cache.blobPath(entry.Files[1].SHA256)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.Lookup(key); ok {
+		t.Error("Lookup() should miss when a blob is missing")
+	}
+}
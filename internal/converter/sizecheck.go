@@ -0,0 +1,192 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SizeBounds are the accepted ratios of ONNX output size to source weight size
+type SizeBounds struct {
+	Min float64 // Below this the conversion is rejected (weights were lost or the file is truncated)
+	Max float64 // Above this the conversion is flagged (e.g., weights duplicated across files)
+}
+
+// defaultSizeBounds apply to architectures without specific bounds.
+// A float32 export of float32 weights lands close to 1.0; float16 sources export at ~2.0.
+var defaultSizeBounds = SizeBounds{Min: 0.3, Max: 4.0}
+
+// architectureSizeBounds hold bounds for model types whose exports legitimately deviate:
+// encoder-decoder models may export only part of the network, and decoders exported
+// with and without past key values store the weights twice.
+var architectureSizeBounds = map[string]SizeBounds{
+	"t5":       {Min: 0.15, Max: 6.0},
+	"mt5":      {Min: 0.15, Max: 6.0},
+	"bart":     {Min: 0.15, Max: 6.0},
+	"mbart":    {Min: 0.15, Max: 6.0},
+	"marian":   {Min: 0.15, Max: 6.0},
+	"pegasus":  {Min: 0.15, Max: 6.0},
+	"whisper":  {Min: 0.15, Max: 6.0},
+	"gpt2":     {Min: 0.3, Max: 6.0},
+	"gpt_neo":  {Min: 0.3, Max: 6.0},
+	"llama":    {Min: 0.3, Max: 6.0},
+	"mistral":  {Min: 0.3, Max: 6.0},
+	"clip":     {Min: 0.2, Max: 4.0},
+	"siglip":   {Min: 0.2, Max: 4.0},
+	"blip":     {Min: 0.2, Max: 4.0},
+	"owlvit":   {Min: 0.2, Max: 4.0},
+	"wav2vec2": {Min: 0.3, Max: 4.0},
+}
+
+// minSizeCheckBytes is the smallest source size worth checking; tiny test models
+// are dominated by graph overhead and would trip the ratio bounds
+const minSizeCheckBytes = 1 << 20
+
+// weightFamilies groups source weight files by serialization format. Repositories often
+// ship the same weights in several formats, so only the largest family is counted.
+var weightFamilies = map[string]string{
+	".safetensors": "safetensors",
+	".bin":         "pytorch",
+	".pt":          "pytorch",
+	".pth":         "pytorch",
+	".ckpt":        "pytorch",
+	".h5":          "tensorflow",
+	".msgpack":     "flax",
+}
+
+// SizeCheck is the outcome of comparing conversion output size with the source weights
+type SizeCheck struct {
+	SourceBytes int64
+	OutputBytes int64
+	Ratio       float64 // OutputBytes / SourceBytes (0 if the check was skipped)
+	Bounds      SizeBounds
+	Warning     string // Set when the output is suspiciously large
+}
+
+// SourceWeightSize returns the size of the model's source weights in dir
+func SourceWeightSize(dir string) (int64, error) {
+	totals := make(map[string]int64)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if family, ok := weightFamilies[strings.ToLower(filepath.Ext(path))]; ok {
+			totals[family] += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to scan source weights: %w", err)
+	}
+
+	var largest int64
+	for _, total := range totals {
+		if total > largest {
+			largest = total
+		}
+	}
+	return largest, nil
+}
+
+// onnxOutputSize returns the size of the ONNX files plus the external data files they reference
+func onnxOutputSize(files []string) int64 {
+	var total int64
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		if stat, err := os.Stat(path); err == nil {
+			total += stat.Size()
+		}
+	}
+
+	for _, file := range files {
+		add(file)
+		if info, err := InspectONNXFile(file); err == nil {
+			for _, external := range info.ExternalData {
+				add(filepath.Join(filepath.Dir(file), filepath.FromSlash(external.Location)))
+			}
+		}
+	}
+	return total
+}
+
+// modelType reads model_type from the model's config.json ("" if unavailable)
+func modelType(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return ""
+	}
+	var config struct {
+		ModelType string `json:"model_type"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return ""
+	}
+	return strings.ToLower(config.ModelType)
+}
+
+// boundsFor returns the size ratio bounds for a model type
+func boundsFor(modelType string) SizeBounds {
+	if bounds, ok := architectureSizeBounds[modelType]; ok {
+		return bounds
+	}
+	return defaultSizeBounds
+}
+
+// CheckOutputSize compares the size of converted ONNX files with the source weights in
+// modelDir. It returns an error when the output is far smaller than the source (a broken
+// export), and a warning in the result when it is far larger. The bounds are scaled to
+// the output precision ("" for fp32), since fp16 and int8 exports are smaller by design.
+func CheckOutputSize(modelDir string, files []string, precision string) (*SizeCheck, error) {
+	source, err := SourceWeightSize(modelDir)
+	if err != nil {
+		return nil, err
+	}
+
+	bounds := boundsFor(modelType(modelDir))
+	if weightBytes, ok := precisionWeightBytes[precision]; ok {
+		scale := weightBytes / precisionWeightBytes[PrecisionFP32]
+		bounds.Min *= scale
+		bounds.Max *= scale
+	}
+	check := &SizeCheck{
+		SourceBytes: source,
+		OutputBytes: onnxOutputSize(files),
+		Bounds:      bounds,
+	}
+	if source < minSizeCheckBytes {
+		return check, nil
+	}
+
+	check.Ratio = float64(check.OutputBytes) / float64(source)
+	if check.Ratio < check.Bounds.Min {
+		return check, fmt.Errorf("ONNX output is %s but source weights are %s (ratio %.3f, expected at least %.2f) - conversion likely lost weights",
+			formatBytes(check.OutputBytes), formatBytes(source), check.Ratio, check.Bounds.Min)
+	}
+	if check.Ratio > check.Bounds.Max {
+		check.Warning = fmt.Sprintf("ONNX output is %.1fx the source weight size (%s vs %s)",
+			check.Ratio, formatBytes(check.OutputBytes), formatBytes(source))
+	}
+	return check, nil
+}
+
+// formatBytes renders a byte count for messages
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
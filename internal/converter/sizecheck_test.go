@@ -0,0 +1,76 @@
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckOutputSize(t *testing.T) {
+	const mb = 1 << 20
+
+	tests := []struct {
+		name        string
+		modelType   string
+		sourceBytes int // written as both model.safetensors and pytorch_model.bin
+		outputBytes int // external data referenced by model.onnx
+		precision   string
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "matching size", modelType: "bert", sourceBytes: 4 * mb, outputBytes: 4 * mb},
+		{name: "truncated export", modelType: "albert", sourceBytes: 4 * mb, outputBytes: 0, wantErr: true},
+		{name: "duplicated weights", modelType: "bert", sourceBytes: 2 * mb, outputBytes: 10 * mb, wantWarning: true},
+		{name: "decoder with past", modelType: "gpt2", sourceBytes: 2 * mb, outputBytes: 10 * mb},
+		{name: "encoder-only export", modelType: "t5", sourceBytes: 4 * mb, outputBytes: 1 * mb},
+		{name: "tiny source skipped", modelType: "bert", sourceBytes: 1024, outputBytes: 0},
+		{name: "quarter size export", modelType: "bert", sourceBytes: 4 * mb, outputBytes: 1 * mb, wantErr: true},
+		{name: "int8 export", modelType: "bert", sourceBytes: 4 * mb, outputBytes: 1 * mb, precision: PrecisionINT8},
+		{name: "truncated fp16 export", modelType: "bert", sourceBytes: 8 * mb, outputBytes: 1 * mb, precision: PrecisionFP16, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			write := func(name string, size int) {
+				t.Helper()
+				if err := os.WriteFile(filepath.Join(dir, name), make([]byte, size), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			write("model.safetensors", tt.sourceBytes)
+			write("pytorch_model.bin", tt.sourceBytes)
+			if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"model_type": "`+tt.modelType+`"}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			var initializer []byte
+			if tt.outputBytes > 0 {
+				initializer = externalTensor("model.onnx_data", tt.outputBytes)
+				write("model.onnx_data", tt.outputBytes)
+			}
+			onnxPath := filepath.Join(dir, "model.onnx")
+			if err := os.WriteFile(onnxPath, testONNXModel(initializer), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			check, err := CheckOutputSize(dir, []string{onnxPath}, tt.precision)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckOutputSize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "lost weights") {
+					t.Errorf("error = %v, want it to mention lost weights", err)
+				}
+				return
+			}
+			if check.SourceBytes != int64(tt.sourceBytes) {
+				t.Errorf("SourceBytes = %d, want %d (duplicate formats counted once)", check.SourceBytes, tt.sourceBytes)
+			}
+			if (check.Warning != "") != tt.wantWarning {
+				t.Errorf("Warning = %q, wantWarning %v", check.Warning, tt.wantWarning)
+			}
+		})
+	}
+}
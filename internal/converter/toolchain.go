@@ -0,0 +1,67 @@
+package converter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Toolchain records the converter package versions that produced an ONNX artifact.
+// Versions are empty when a package is not installed in the converter environment.
+type Toolchain struct {
+	Python       string `json:"python"`
+	Torch        string `json:"torch"`
+	Transformers string `json:"transformers"`
+	Optimum      string `json:"optimum"`
+	ONNX         string `json:"onnx"`
+}
+
+// toolchainProbeScript prints the versions of the conversion packages as JSON
+const toolchainProbeScript = `
+import json, platform
+versions = {"python": platform.python_version()}
+for name in ("torch", "transformers", "optimum", "onnx"):
+    try:
+        if name == "optimum":
+            from importlib.metadata import version
+            versions[name] = version("optimum")
+        else:
+            versions[name] = __import__(name).__version__
+    except Exception:
+        versions[name] = ""
+print(json.dumps(versions))
+`
+
+// ProbeToolchain reports the converter toolchain used by a conversion method.
+// Pre-converted downloads have no local toolchain, so nil is returned for them.
+func ProbeToolchain(ctx context.Context, method, image string) (*Toolchain, error) {
+	var cmd *exec.Cmd
+	switch method {
+	case ConversionMethodDocker:
+		// The converter image uses python3 as its entrypoint
+		cmd = exec.CommandContext(ctx, "docker", "run", "--rm", image, "-c", toolchainProbeScript)
+	case ConversionMethodPython:
+		cmd = exec.CommandContext(ctx, "python3", "-c", toolchainProbeScript)
+	default:
+		return nil, nil
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to probe converter toolchain: %w", err)
+	}
+	return parseToolchain(output)
+}
+
+// parseToolchain parses the JSON printed by toolchainProbeScript.
+// Only the last line is used so that warnings printed on import are ignored.
+func parseToolchain(output []byte) (*Toolchain, error) {
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	var toolchain Toolchain
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &toolchain); err != nil {
+		return nil, fmt.Errorf("failed to parse converter toolchain: %w", err)
+	}
+	return &toolchain, nil
+}
@@ -0,0 +1,33 @@
+package converter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseToolchain(t *testing.T) {
+	output := []byte("UserWarning: something noisy on import\n" +
+		`{"python": "3.11.4", "torch": "2.1.0", "transformers": "4.36.0", "optimum": "", "onnx": "1.15.0"}` + "\n")
+
+	toolchain, err := parseToolchain(output)
+	if err != nil {
+		t.Fatalf("parseToolchain() error = %v", err)
+	}
+	if toolchain.Torch != "2.1.0" || toolchain.Transformers != "4.36.0" || toolchain.ONNX != "1.15.0" {
+		t.Errorf("parseToolchain() = %+v", toolchain)
+	}
+	if toolchain.Optimum != "" {
+		t.Errorf("Optimum = %q, want empty for missing package", toolchain.Optimum)
+	}
+
+	if _, err := parseToolchain([]byte("not json")); err == nil {
+		t.Error("parseToolchain() expected error for invalid output")
+	}
+}
+
+func TestProbeToolchain_PreConverted(t *testing.T) {
+	toolchain, err := ProbeToolchain(context.Background(), ConversionMethodPreConverted, "")
+	if err != nil || toolchain != nil {
+		t.Errorf("ProbeToolchain(pre-converted) = %v, %v; want nil, nil", toolchain, err)
+	}
+}
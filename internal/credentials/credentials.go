@@ -0,0 +1,121 @@
+// Package credentials stores access tokens for repository adapters. Tokens are kept in
+// the OS keychain when one is available (macOS Keychain, or the Secret Service via
+// secret-tool on Linux), and otherwise in an encrypted file in the Axon home directory.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned when no token is stored for an adapter
+var ErrNotFound = errors.New("no token stored")
+
+// Store persists tokens by adapter name
+type Store interface {
+	// Name describes the backend, for messages
+	Name() string
+	Get(adapter string) (string, error)
+	Set(adapter, token string) error
+	Delete(adapter string) error
+}
+
+// adapterAliases maps short names accepted by login/logout to adapter names
+var adapterAliases = map[string]string{
+	"hf":       "huggingface",
+	"ms":       "modelscope",
+	"registry": "local",
+}
+
+// CanonicalAdapter returns the adapter name for a name or alias
+func CanonicalAdapter(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if canonical, ok := adapterAliases[name]; ok {
+		return canonical
+	}
+	return name
+}
+
+// Manager stores tokens in the keychain, falling back to the encrypted file when the
+// keychain is unavailable or fails (e.g., no D-Bus session on a headless host)
+type Manager struct {
+	keychain Store // nil if no keychain is available
+	file     Store
+}
+
+// NewManager returns a manager using the OS keychain if available and an encrypted file
+// in homeDir otherwise
+func NewManager(homeDir string) *Manager {
+	return &Manager{
+		keychain: systemKeychain(),
+		file:     NewFileStore(homeDir),
+	}
+}
+
+// NewManagerWithStores returns a manager over the given stores (keychain may be nil)
+func NewManagerWithStores(keychain, file Store) *Manager {
+	return &Manager{keychain: keychain, file: file}
+}
+
+// Set stores the token for adapter and returns the name of the backend that holds it
+func (m *Manager) Set(adapter, token string) (string, error) {
+	adapter = CanonicalAdapter(adapter)
+	if token == "" {
+		return "", fmt.Errorf("token is empty")
+	}
+	if m.keychain != nil {
+		if err := m.keychain.Set(adapter, token); err == nil {
+			// Don't leave an older copy behind in the file
+			_ = m.file.Delete(adapter)
+			return m.keychain.Name(), nil
+		}
+	}
+	if err := m.file.Set(adapter, token); err != nil {
+		return "", err
+	}
+	return m.file.Name(), nil
+}
+
+// Get returns the token stored for adapter, or ErrNotFound. The token is registered
+// for redaction.
+func (m *Manager) Get(adapter string) (string, error) {
+	adapter = CanonicalAdapter(adapter)
+	for _, store := range m.stores() {
+		token, err := store.Get(adapter)
+		if err == nil && token != "" {
+			RegisterSecret(token)
+			return token, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+// Delete removes the token for adapter from every backend; ErrNotFound if there was none
+func (m *Manager) Delete(adapter string) error {
+	adapter = CanonicalAdapter(adapter)
+	deleted := false
+	var lastErr error
+	for _, store := range m.stores() {
+		err := store.Delete(adapter)
+		if err == nil {
+			deleted = true
+		} else if !errors.Is(err, ErrNotFound) {
+			lastErr = fmt.Errorf("failed to delete token from %s: %w", store.Name(), err)
+		}
+	}
+	switch {
+	case deleted:
+		return nil
+	case lastErr != nil:
+		return lastErr
+	}
+	return ErrNotFound
+}
+
+func (m *Manager) stores() []Store {
+	if m.keychain != nil {
+		return []Store{m.keychain, m.file}
+	}
+	return []Store{m.file}
+}
@@ -0,0 +1,234 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// memoryStore is an in-memory Store, optionally failing every call
+type memoryStore struct {
+	tokens map[string]string
+	fail   bool
+}
+
+func newMemoryStore(fail bool) *memoryStore {
+	return &memoryStore{tokens: make(map[string]string), fail: fail}
+}
+
+func (m *memoryStore) Name() string { return "memory" }
+
+func (m *memoryStore) Get(adapter string) (string, error) {
+	if m.fail {
+		return "", errors.New("keychain locked")
+	}
+	token, ok := m.tokens[adapter]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (m *memoryStore) Set(adapter, token string) error {
+	if m.fail {
+		return errors.New("keychain locked")
+	}
+	m.tokens[adapter] = token
+	return nil
+}
+
+func (m *memoryStore) Delete(adapter string) error {
+	if m.fail {
+		return errors.New("keychain locked")
+	}
+	if _, ok := m.tokens[adapter]; !ok {
+		return ErrNotFound
+	}
+	delete(m.tokens, adapter)
+	return nil
+}
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+
+	if _, err := store.Get("huggingface"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get() on empty store error = %v, want ErrNotFound", err)
+	}
+	if err := store.Set("huggingface", "hf_secretvalue123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := store.Set("modelscope", "ms-token-456"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	// A fresh store reads the same file
+	token, err := NewFileStore(dir).Get("huggingface")
+	if err != nil || token != "hf_secretvalue123" {
+		t.Fatalf("Get() = %q, %v", token, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "hf_secretvalue123") {
+		t.Error("credentials file contains the plaintext token")
+	}
+	for _, name := range []string{FileName, KeyFileName} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("%s mode = %v, want 0600", name, info.Mode().Perm())
+		}
+	}
+
+	if err := store.Delete("huggingface"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := store.Delete("huggingface"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+	}
+	if token, _ := store.Get("modelscope"); token != "ms-token-456" {
+		t.Errorf("Get(modelscope) = %q after deleting another adapter", token)
+	}
+}
+
+func TestFileStoreWrongKey(t *testing.T) {
+	dir := t.TempDir()
+	store := NewFileStore(dir)
+	if err := store.Set("huggingface", "hf_secretvalue123"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, KeyFileName), make([]byte, 32), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get("huggingface"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() with wrong key error = %v, want decryption error", err)
+	}
+}
+
+func TestManager(t *testing.T) {
+	tests := []struct {
+		name        string
+		keychain    *memoryStore
+		wantBackend string
+	}{
+		{name: "keychain", keychain: newMemoryStore(false), wantBackend: "memory"},
+		{name: "keychain failing falls back to file", keychain: newMemoryStore(true), wantBackend: "encrypted file"},
+		{name: "no keychain", keychain: nil, wantBackend: "encrypted file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var keychain Store
+			if tt.keychain != nil {
+				keychain = tt.keychain
+			}
+			m := NewManagerWithStores(keychain, NewFileStore(t.TempDir()))
+
+			backend, err := m.Set("hf", "hf_managertoken1")
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if !strings.HasPrefix(backend, tt.wantBackend) {
+				t.Errorf("Set() backend = %q, want prefix %q", backend, tt.wantBackend)
+			}
+			token, err := m.Get("huggingface")
+			if err != nil || token != "hf_managertoken1" {
+				t.Errorf("Get() = %q, %v", token, err)
+			}
+			if err := m.Delete("huggingface"); err != nil {
+				t.Errorf("Delete() error = %v", err)
+			}
+			if _, err := m.Get("huggingface"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestManagerMovesFileTokenToKeychain(t *testing.T) {
+	file := NewFileStore(t.TempDir())
+	if err := file.Set("huggingface", "hf_oldfiletoken1"); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManagerWithStores(newMemoryStore(false), file)
+	if _, err := m.Set("huggingface", "hf_newkeychaintoken"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := file.Get("huggingface"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("file still holds the old token after storing in the keychain")
+	}
+}
+
+func TestSecretServiceKeychain(t *testing.T) {
+	var calls [][]string
+	var stdins []string
+	orig := runCommand
+	defer func() { runCommand = orig }()
+	runCommand = func(stdin string, name string, args ...string) (string, error) {
+		calls = append(calls, append([]string{name}, args...))
+		stdins = append(stdins, stdin)
+		return "hf_fromkeyring1\n", nil
+	}
+
+	k := secretServiceKeychain{}
+	if err := k.Set("huggingface", "hf_fromkeyring1"); err != nil {
+		t.Fatal(err)
+	}
+	if stdins[0] != "hf_fromkeyring1" {
+		t.Errorf("token not passed on stdin")
+	}
+	for _, arg := range calls[0] {
+		if arg == "hf_fromkeyring1" {
+			t.Errorf("token passed as an argument: %v", calls[0])
+		}
+	}
+	if token, err := k.Get("huggingface"); err != nil || token != "hf_fromkeyring1" {
+		t.Errorf("Get() = %q, %v", token, err)
+	}
+}
+
+func TestCanonicalAdapter(t *testing.T) {
+	tests := map[string]string{
+		"hf":          "huggingface",
+		"HuggingFace": "huggingface",
+		"ms":          "modelscope",
+		"registry":    "local",
+		"pytorch":     "pytorch",
+	}
+	for in, want := range tests {
+		if got := CanonicalAdapter(in); got != want {
+			t.Errorf("CanonicalAdapter(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRedact(t *testing.T) {
+	RegisterSecret("registered-secret-value")
+	RegisterSecret("short")
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "registered", in: "token=registered-secret-value failed", want: "token=[REDACTED] failed"},
+		{name: "short values ignored", in: "a short string", want: "a short string"},
+		{name: "hf token", in: "401 for hf_abcdefghijklmnopqrstuvwxyz", want: "401 for [REDACTED]"},
+		{name: "bearer", in: "Authorization: Bearer abc.def-123456", want: "Authorization: Bearer [REDACTED]"},
+		{name: "nothing to redact", in: "model not found", want: "model not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Redact(tt.in); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
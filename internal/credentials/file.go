@@ -0,0 +1,161 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// File names of the encrypted token store in the Axon home directory
+const (
+	FileName    = "credentials.enc"
+	KeyFileName = "credentials.key"
+)
+
+// FileStore keeps tokens encrypted with AES-256-GCM. The key lives in a separate
+// owner-only file, so copying the credentials file alone (e.g., in a backup or a
+// shared dotfiles repo) doesn't reveal the tokens.
+type FileStore struct {
+	path    string
+	keyPath string
+}
+
+// NewFileStore returns the encrypted file store in homeDir
+func NewFileStore(homeDir string) *FileStore {
+	return &FileStore{
+		path:    filepath.Join(homeDir, FileName),
+		keyPath: filepath.Join(homeDir, KeyFileName),
+	}
+}
+
+// Name describes the backend
+func (f *FileStore) Name() string { return "encrypted file " + f.path }
+
+// Get returns the token for adapter
+func (f *FileStore) Get(adapter string) (string, error) {
+	tokens, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[adapter]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+// Set stores the token for adapter
+func (f *FileStore) Set(adapter, token string) error {
+	tokens, err := f.load()
+	if err != nil {
+		return err
+	}
+	tokens[adapter] = token
+	return f.save(tokens)
+}
+
+// Delete removes the token for adapter
+func (f *FileStore) Delete(adapter string) error {
+	tokens, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := tokens[adapter]; !ok {
+		return ErrNotFound
+	}
+	delete(tokens, adapter)
+	return f.save(tokens)
+}
+
+// load decrypts the token map (empty if the file doesn't exist)
+func (f *FileStore) load() (map[string]string, error) {
+	tokens := make(map[string]string)
+	data, err := os.ReadFile(f.path)
+	if os.IsNotExist(err) {
+		return tokens, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	gcm, err := f.cipher(false)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("failed to decode credentials: file is corrupt")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials: wrong key or corrupt file")
+	}
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	return tokens, nil
+}
+
+// save encrypts and writes the token map
+func (f *FileStore) save(tokens map[string]string) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	gcm, err := f.cipher(true)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0700); err != nil {
+		return fmt.Errorf("failed to create credentials directory: %w", err)
+	}
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(base64.StdEncoding.EncodeToString(sealed)), 0600); err != nil {
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write credentials: %w", err)
+	}
+	return nil
+}
+
+// cipher returns the AES-GCM cipher, creating the key if create is set and there is none
+func (f *FileStore) cipher(create bool) (cipher.AEAD, error) {
+	key, err := os.ReadFile(f.keyPath)
+	if os.IsNotExist(err) && create {
+		key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, key); err != nil {
+			return nil, fmt.Errorf("failed to generate credentials key: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(f.keyPath), 0700); err != nil {
+			return nil, fmt.Errorf("failed to create credentials directory: %w", err)
+		}
+		if err := os.WriteFile(f.keyPath, key, 0600); err != nil {
+			return nil, fmt.Errorf("failed to write credentials key: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read credentials key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("credentials key %s is invalid", f.keyPath)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,92 @@
+package credentials
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// keychainService is the service name tokens are stored under
+const keychainService = "axon"
+
+// runCommand runs a keychain tool and returns its stdout; replaced in tests
+var runCommand = func(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// systemKeychain returns the keychain of this OS, or nil if none is available
+func systemKeychain() Store {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := exec.LookPath("security"); err == nil {
+			return macKeychain{}
+		}
+	case "linux":
+		if _, err := exec.LookPath("secret-tool"); err == nil {
+			return secretServiceKeychain{}
+		}
+	}
+	return nil
+}
+
+// macKeychain stores tokens as generic passwords in the macOS login keychain
+type macKeychain struct{}
+
+func (macKeychain) Name() string { return "macOS Keychain" }
+
+func (macKeychain) Get(adapter string) (string, error) {
+	out, err := runCommand("", "security", "find-generic-password", "-s", keychainService, "-a", adapter, "-w")
+	if err != nil {
+		return "", ErrNotFound
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (macKeychain) Set(adapter, token string) error {
+	_, err := runCommand("", "security", "add-generic-password", "-U", "-s", keychainService, "-a", adapter, "-w", token)
+	return err
+}
+
+func (macKeychain) Delete(adapter string) error {
+	if _, err := runCommand("", "security", "delete-generic-password", "-s", keychainService, "-a", adapter); err != nil {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// secretServiceKeychain stores tokens in the Secret Service (GNOME Keyring, KWallet)
+// through secret-tool. The token is passed on stdin so it never shows up in ps.
+type secretServiceKeychain struct{}
+
+func (secretServiceKeychain) Name() string { return "Secret Service keyring" }
+
+func (secretServiceKeychain) Get(adapter string) (string, error) {
+	out, err := runCommand("", "secret-tool", "lookup", "service", keychainService, "account", adapter)
+	if err != nil || out == "" {
+		return "", ErrNotFound
+	}
+	return strings.TrimRight(out, "\n"), nil
+}
+
+func (secretServiceKeychain) Set(adapter, token string) error {
+	_, err := runCommand(token, "secret-tool", "store", "--label", "Axon "+adapter+" token", "service", keychainService, "account", adapter)
+	return err
+}
+
+func (s secretServiceKeychain) Delete(adapter string) error {
+	if _, err := s.Get(adapter); err != nil {
+		return err
+	}
+	_, err := runCommand("", "secret-tool", "clear", "service", keychainService, "account", adapter)
+	return err
+}
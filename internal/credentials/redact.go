@@ -0,0 +1,53 @@
+package credentials
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redacted replaces secrets in output
+const redacted = "[REDACTED]"
+
+// minSecretLength avoids redacting short strings that would mangle unrelated output
+const minSecretLength = 8
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = make(map[string]struct{})
+)
+
+// tokenPatterns match well-known token formats even when they weren't registered
+var tokenPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`hf_[A-Za-z0-9]{20,}`),
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]{8,}`),
+}
+
+// RegisterSecret marks a value to be redacted from output
+func RegisterSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+	secretsMu.Lock()
+	secrets[secret] = struct{}{}
+	secretsMu.Unlock()
+}
+
+// Redact replaces registered secrets and well-known token formats in s
+func Redact(s string) string {
+	secretsMu.RLock()
+	for secret := range secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	secretsMu.RUnlock()
+
+	for _, pattern := range tokenPatterns {
+		s = pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if sub := pattern.FindStringSubmatch(match); len(sub) > 1 {
+				return sub[1] + redacted
+			}
+			return redacted
+		})
+	}
+	return s
+}
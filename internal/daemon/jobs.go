@@ -0,0 +1,312 @@
+// Package daemon serves Axon's model operations over a local HTTP API ('axon serve'), so
+// MLOS Core, IDE plugins and the web UI can drive Axon without shelling out to the CLI.
+// Installs run as background jobs, scheduled by priority class, whose progress clients
+// poll.
+package daemon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job states
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Priority classes. Urgent jobs, such as MLOS Core installing a model a live workload is
+// waiting for, start at once and preempt running background jobs, such as prefetches,
+// which wait while any urgent job is queued or running. Normal and background jobs
+// share the runner's slots, normal first.
+const (
+	PriorityUrgent     = "urgent"
+	PriorityNormal     = "normal"
+	PriorityBackground = "background"
+)
+
+// DefaultMaxRunning is how many normal and background jobs run at once by default
+const DefaultMaxRunning = 2
+
+// ParsePriority checks a priority class; "" is normal
+func ParsePriority(priority string) (string, error) {
+	switch priority {
+	case "":
+		return PriorityNormal, nil
+	case PriorityUrgent, PriorityNormal, PriorityBackground:
+		return priority, nil
+	default:
+		return "", fmt.Errorf("invalid priority %q (expected %s, %s or %s)", priority, PriorityUrgent, PriorityNormal, PriorityBackground)
+	}
+}
+
+// priorityRank orders priority classes, most urgent first
+func priorityRank(priority string) int {
+	switch priority {
+	case PriorityUrgent:
+		return 0
+	case PriorityBackground:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// Job is an operation running in the background
+type Job struct {
+	ID          string     `json:"id"`
+	Kind        string     `json:"kind"` // e.g. "install"
+	Model       string     `json:"model"`
+	Priority    string     `json:"priority"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	Downloaded  int64      `json:"downloaded_bytes"`
+	Total       int64      `json:"total_bytes,omitempty"` // 0 when the size isn't known
+	Preemptions int        `json:"preemptions,omitempty"` // Times an urgent job sent it back to the queue
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+}
+
+// ProgressFunc reports the bytes a job has downloaded so far
+type ProgressFunc func(downloaded, total int64)
+
+// JobFunc is the work of a job. A preempted job's context is cancelled and the function
+// runs again from the start when the job is rescheduled.
+type JobFunc func(ctx context.Context, progress ProgressFunc) error
+
+// jobState is a job with what the runner needs to schedule it
+type jobState struct {
+	Job
+	fn        JobFunc
+	cancel    context.CancelFunc
+	preempted bool
+	seq       uint64 // Queueing order, for first-in first-out within a class
+}
+
+// Jobs queues and runs jobs by priority class and keeps their state in memory for
+// polling
+type Jobs struct {
+	ctx        context.Context
+	mu         sync.Mutex
+	jobs       map[string]*jobState
+	maxRunning int
+	seq        uint64
+	wg         sync.WaitGroup
+}
+
+// NewJobs creates a job runner; jobs are cancelled when ctx is done
+func NewJobs(ctx context.Context) *Jobs {
+	return &Jobs{ctx: ctx, jobs: make(map[string]*jobState), maxRunning: DefaultMaxRunning}
+}
+
+// SetMaxRunning sets how many normal and background jobs run at once (0 = default).
+// Urgent jobs don't wait for a slot.
+func (j *Jobs) SetMaxRunning(n int) {
+	if n <= 0 {
+		n = DefaultMaxRunning
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.maxRunning = n
+	j.schedule()
+}
+
+// Start queues fn as a job of kind on model at priority ("" = normal) and returns it. If
+// a job of the same kind is already queued or running for model, that job is returned
+// instead and started is false, so concurrent installs of a model don't race on its
+// cache directory; a more urgent request raises the job's priority.
+func (j *Jobs) Start(kind, model, priority string, fn JobFunc) (job Job, started bool) {
+	if priority == "" {
+		priority = PriorityNormal
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, existing := range j.jobs {
+		if existing.Kind == kind && existing.Model == model && (existing.Status == JobQueued || existing.Status == JobRunning) {
+			if priorityRank(priority) < priorityRank(existing.Priority) {
+				existing.Priority = priority
+				j.schedule()
+			}
+			return existing.Job, false
+		}
+	}
+
+	j.seq++
+	state := &jobState{
+		Job: Job{ID: newJobID(), Kind: kind, Model: model, Priority: priority, Status: JobQueued, CreatedAt: time.Now().UTC()},
+		fn:  fn,
+		seq: j.seq,
+	}
+	j.jobs[state.ID] = state
+	j.wg.Add(1)
+	j.schedule()
+	return state.Job, true
+}
+
+// schedule starts the queued jobs that may run, most urgent and oldest first, and
+// preempts running background jobs while urgent jobs are queued or running. j.mu is held.
+func (j *Jobs) schedule() {
+	if err := j.ctx.Err(); err != nil {
+		for _, state := range j.jobs {
+			if state.Status == JobQueued {
+				j.finish(state, err)
+			}
+		}
+		return
+	}
+
+	urgent, running := false, 0
+	for _, state := range j.jobs {
+		if state.Status != JobQueued && state.Status != JobRunning {
+			continue
+		}
+		if state.Priority == PriorityUrgent {
+			urgent = true
+		} else if state.Status == JobRunning {
+			running++
+		}
+	}
+	if urgent {
+		for _, state := range j.jobs {
+			if state.Status == JobRunning && state.Priority == PriorityBackground && !state.preempted {
+				state.preempted = true
+				state.cancel()
+			}
+		}
+	}
+
+	for _, state := range j.queued() {
+		switch {
+		case state.Priority == PriorityUrgent:
+		case state.Priority == PriorityBackground && urgent:
+			continue
+		case running >= j.maxRunning:
+			continue
+		default:
+			running++
+		}
+		j.run(state)
+	}
+}
+
+// queued returns the queued jobs in the order they start, most urgent and oldest first.
+// j.mu is held.
+func (j *Jobs) queued() []*jobState {
+	var queued []*jobState
+	for _, state := range j.jobs {
+		if state.Status == JobQueued {
+			queued = append(queued, state)
+		}
+	}
+	sort.Slice(queued, func(a, b int) bool {
+		if ra, rb := priorityRank(queued[a].Priority), priorityRank(queued[b].Priority); ra != rb {
+			return ra < rb
+		}
+		return queued[a].seq < queued[b].seq
+	})
+	return queued
+}
+
+// run starts a queued job. A preempted job goes back to the queue when its function
+// returns. j.mu is held.
+func (j *Jobs) run(state *jobState) {
+	ctx, cancel := context.WithCancel(j.ctx)
+	startedAt := time.Now().UTC()
+	state.Status, state.StartedAt = JobRunning, &startedAt
+	state.Downloaded, state.Total = 0, 0
+	state.cancel, state.preempted = cancel, false
+
+	go func() {
+		err := state.fn(ctx, func(downloaded, total int64) {
+			j.mu.Lock()
+			state.Downloaded, state.Total = downloaded, total
+			j.mu.Unlock()
+		})
+		cancel()
+
+		j.mu.Lock()
+		defer j.mu.Unlock()
+		if err != nil && state.preempted && j.ctx.Err() == nil {
+			state.Status, state.StartedAt = JobQueued, nil
+			state.Downloaded, state.Total = 0, 0
+			state.Preemptions++
+		} else {
+			j.finish(state, err)
+		}
+		j.schedule()
+	}()
+}
+
+// finish records the outcome of a job. j.mu is held.
+func (j *Jobs) finish(state *jobState, err error) {
+	finished := time.Now().UTC()
+	state.FinishedAt = &finished
+	state.Status = JobSucceeded
+	if err != nil {
+		state.Status = JobFailed
+		state.Error = err.Error()
+	}
+	state.fn, state.cancel = nil, nil
+	j.wg.Done()
+}
+
+// Get returns the current state of a job
+func (j *Jobs) Get(id string) (Job, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	state, ok := j.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return state.Job, true
+}
+
+// List returns every job, oldest first
+func (j *Jobs) List() []Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jobs := make([]Job, 0, len(j.jobs))
+	for _, state := range j.jobs {
+		jobs = append(jobs, state.Job)
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].CreatedAt.Before(jobs[b].CreatedAt) })
+	return jobs
+}
+
+// Queue returns the running jobs, longest running first, then the queued jobs in the
+// order they will start
+func (j *Jobs) Queue() []Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	jobs := []Job{}
+	for _, state := range j.jobs {
+		if state.Status == JobRunning {
+			jobs = append(jobs, state.Job)
+		}
+	}
+	sort.Slice(jobs, func(a, b int) bool { return jobs[a].StartedAt.Before(*jobs[b].StartedAt) })
+	for _, state := range j.queued() {
+		jobs = append(jobs, state.Job)
+	}
+	return jobs
+}
+
+// Wait blocks until every job has finished
+func (j *Jobs) Wait() {
+	j.wg.Wait()
+}
+
+// newJobID returns a random job ID
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,264 @@
+package daemon
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/cache"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// APIVersion is the path prefix of the API
+const APIVersion = "/v1"
+
+// ErrNotFound is returned by backends for models that aren't installed
+var ErrNotFound = errors.New("model not found")
+
+// Model describes an installed model
+type Model struct {
+	ID           string                  `json:"id"` // namespace/name@version
+	Namespace    string                  `json:"namespace"`
+	Name         string                  `json:"name"`
+	Version      string                  `json:"version"`
+	Path         string                  `json:"path"`
+	Registration *cache.CoreRegistration `json:"registration,omitempty"`
+	Manifest     *types.Manifest         `json:"manifest,omitempty"` // Only in model details
+}
+
+// InstallRequest asks for a model to be installed
+type InstallRequest struct {
+	Model    string `json:"model"`
+	Register bool   `json:"register,omitempty"` // Register with MLOS Core once installed
+	Priority string `json:"priority,omitempty"` // urgent, normal (default) or background
+}
+
+// RegisterRequest asks for an installed model to be registered with MLOS Core
+type RegisterRequest struct {
+	Model   string `json:"model"`
+	Convert bool   `json:"convert,omitempty"`
+	Force   bool   `json:"force,omitempty"`
+}
+
+// VerifyRequest asks for an installed model to be verified
+type VerifyRequest struct {
+	Model       string `json:"model"`
+	Attestation bool   `json:"attestation,omitempty"`
+}
+
+// Backend performs the operations the API exposes
+type Backend interface {
+	Install(ctx context.Context, req InstallRequest, progress ProgressFunc) error
+	List() ([]Model, error)
+	Info(model string) (*Model, error)
+	Verify(ctx context.Context, req VerifyRequest) error
+	Register(ctx context.Context, req RegisterRequest) error
+}
+
+// Server is the local Axon API
+type Server struct {
+	backend Backend
+	jobs    *Jobs
+	version string
+	token   string
+}
+
+// NewServer creates the API for backend; jobs run until ctx is done
+func NewServer(ctx context.Context, backend Backend, version string) *Server {
+	return &Server{backend: backend, jobs: NewJobs(ctx), version: version}
+}
+
+// RequireToken makes every request but the health check carry token as a bearer token
+func (s *Server) RequireToken(token string) {
+	s.token = token
+}
+
+// Jobs returns the server's background jobs
+func (s *Server) Jobs() *Jobs {
+	return s.jobs
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+APIVersion+"/health", s.health)
+	mux.HandleFunc("GET "+APIVersion+"/models", s.listModels)
+	mux.HandleFunc("GET "+APIVersion+"/models/{model...}", s.modelInfo)
+	mux.HandleFunc("POST "+APIVersion+"/install", s.install)
+	mux.HandleFunc("POST "+APIVersion+"/verify", s.verify)
+	mux.HandleFunc("POST "+APIVersion+"/register", s.register)
+	mux.HandleFunc("GET "+APIVersion+"/jobs", s.listJobs)
+	mux.HandleFunc("GET "+APIVersion+"/jobs/{id}", s.job)
+	mux.HandleFunc("GET "+APIVersion+"/queue", s.queue)
+	return s.guard(mux)
+}
+
+// guard keeps web pages from driving the API: browsers send an Origin header with
+// cross-site requests, and a DNS-rebound name pointing at the loopback listener arrives
+// with a Host that isn't a loopback name. With a token set, requests must also carry it.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Origin") != "" {
+			writeError(w, http.StatusForbidden, errors.New("cross-origin requests are not allowed"))
+			return
+		}
+		if local, ok := r.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr); ok && local.IP.IsLoopback() && !isLoopbackHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q is not a loopback address", r.Host))
+			return
+		}
+		if s.token != "" && r.URL.Path != APIVersion+"/health" {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="axon"`)
+				writeError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost reports whether a Host header names the local machine
+func isLoopbackHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "version": s.version})
+}
+
+func (s *Server) listModels(w http.ResponseWriter, r *http.Request) {
+	models, err := s.backend.List()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if models == nil {
+		models = []Model{}
+	}
+	writeJSON(w, http.StatusOK, models)
+}
+
+func (s *Server) modelInfo(w http.ResponseWriter, r *http.Request) {
+	model, err := s.backend.Info(r.PathValue("model"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, model)
+}
+
+// install queues an install job and answers 202 with it, or 200 with the install of the
+// model that is already queued or running
+func (s *Server) install(w http.ResponseWriter, r *http.Request) {
+	var req InstallRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	priority, err := ParsePriority(req.Priority)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	job, started := s.jobs.Start("install", req.Model, priority, func(ctx context.Context, progress ProgressFunc) error {
+		return s.backend.Install(ctx, req, progress)
+	})
+	w.Header().Set("Location", APIVersion+"/jobs/"+job.ID)
+	status := http.StatusAccepted
+	if !started {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, job)
+}
+
+func (s *Server) verify(w http.ResponseWriter, r *http.Request) {
+	var req VerifyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := s.backend.Verify(r.Context(), req); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"model": req.Model, "verified": true})
+}
+
+func (s *Server) register(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	if err := s.backend.Register(r.Context(), req); err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"model": req.Model, "registered": true})
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.jobs.List())
+}
+
+func (s *Server) queue(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.jobs.Queue())
+}
+
+func (s *Server) job(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.jobs.Get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("job %s not found", r.PathValue("id")))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// decodeRequest decodes a JSON request body naming a model into v, answering 415 if it
+// isn't sent as JSON (which HTML forms can't do without a CORS preflight) and 400 if it
+// is invalid
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{ model() string }) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, errors.New("request body must be application/json"))
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	if v.model() == "" {
+		writeError(w, http.StatusBadRequest, errors.New("model is required"))
+		return false
+	}
+	return true
+}
+
+func (r *InstallRequest) model() string  { return r.Model }
+func (r *RegisterRequest) model() string { return r.Model }
+func (r *VerifyRequest) model() string   { return r.Model }
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers with err; errors about models that aren't installed are 404s
+func writeError(w http.ResponseWriter, status int, err error) {
+	if errors.Is(err, ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
@@ -0,0 +1,293 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeBackend installs models after release is closed, reporting progress first
+type fakeBackend struct {
+	release  chan struct{}
+	installs chan string
+}
+
+func (b *fakeBackend) Install(ctx context.Context, req InstallRequest, progress ProgressFunc) error {
+	b.installs <- req.Model
+	progress(512, 1024)
+	<-b.release
+	if strings.Contains(req.Model, "broken") {
+		return errors.New("download failed")
+	}
+	return nil
+}
+
+func (b *fakeBackend) List() ([]Model, error) {
+	return []Model{{ID: "hf/bert@latest", Namespace: "hf", Name: "bert", Version: "latest"}}, nil
+}
+
+func (b *fakeBackend) Info(model string) (*Model, error) {
+	if model != "hf/bert@latest" {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, model)
+	}
+	return &Model{ID: model}, nil
+}
+
+func (b *fakeBackend) Verify(ctx context.Context, req VerifyRequest) error {
+	return errors.New("1 file(s) failed verification")
+}
+
+func (b *fakeBackend) Register(ctx context.Context, req RegisterRequest) error {
+	return nil
+}
+
+func TestServer(t *testing.T) {
+	backend := &fakeBackend{release: make(chan struct{}), installs: make(chan string, 2)}
+	server := NewServer(context.Background(), backend, "1.7.0")
+	api := httptest.NewServer(server.Handler())
+	defer api.Close()
+
+	request := func(method, path, body string, wantStatus int, v interface{}) {
+		t.Helper()
+		req, err := http.NewRequest(method, api.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != wantStatus {
+			t.Errorf("%s %s = %d, want %d", method, path, resp.StatusCode, wantStatus)
+		}
+		if v != nil {
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				t.Fatalf("%s %s: %v", method, path, err)
+			}
+		}
+	}
+
+	var health map[string]string
+	request("GET", "/v1/health", "", http.StatusOK, &health)
+	if health["version"] != "1.7.0" {
+		t.Errorf("health = %v", health)
+	}
+	var models []Model
+	request("GET", "/v1/models", "", http.StatusOK, &models)
+	if len(models) != 1 || models[0].ID != "hf/bert@latest" {
+		t.Errorf("models = %+v", models)
+	}
+	request("GET", "/v1/models/hf/bert@latest", "", http.StatusOK, nil)
+	request("GET", "/v1/models/hf/missing", "", http.StatusNotFound, nil)
+	request("POST", "/v1/verify", `{"model": "hf/bert"}`, http.StatusUnprocessableEntity, nil)
+	request("POST", "/v1/register", `{"model": "hf/bert", "convert": true}`, http.StatusOK, nil)
+	request("POST", "/v1/install", `{}`, http.StatusBadRequest, nil)
+	request("POST", "/v1/install", `not json`, http.StatusBadRequest, nil)
+	request("POST", "/v1/install", `{"model": "hf/bert", "priority": "asap"}`, http.StatusBadRequest, nil)
+
+	// Installs run as jobs; installing the same model again returns the running job
+	var job, again, broken Job
+	request("POST", "/v1/install", `{"model": "hf/bert"}`, http.StatusAccepted, &job)
+	<-backend.installs
+	request("POST", "/v1/install", `{"model": "hf/bert"}`, http.StatusOK, &again)
+	if again.ID != job.ID {
+		t.Errorf("second install started job %s, want the running job %s", again.ID, job.ID)
+	}
+	request("POST", "/v1/install", `{"model": "hf/broken"}`, http.StatusAccepted, &broken)
+	<-backend.installs
+
+	var polled Job
+	request("GET", "/v1/jobs/"+job.ID, "", http.StatusOK, &polled)
+	if polled.Status != JobRunning || polled.Downloaded != 512 || polled.Total != 1024 {
+		t.Errorf("running job = %+v, want 512 of 1024 bytes downloaded", polled)
+	}
+	var queue []Job
+	request("GET", "/v1/queue", "", http.StatusOK, &queue)
+	if len(queue) != 2 || queue[0].ID != job.ID || queue[0].Priority != PriorityNormal {
+		t.Errorf("queue = %+v, want the two running installs", queue)
+	}
+
+	close(backend.release)
+	server.Jobs().Wait()
+	request("GET", "/v1/jobs/"+job.ID, "", http.StatusOK, &polled)
+	if polled.Status != JobSucceeded || polled.FinishedAt == nil {
+		t.Errorf("finished job = %+v", polled)
+	}
+	request("GET", "/v1/jobs/"+broken.ID, "", http.StatusOK, &polled)
+	if polled.Status != JobFailed || polled.Error != "download failed" {
+		t.Errorf("failed job = %+v", polled)
+	}
+	var jobs []Job
+	request("GET", "/v1/jobs", "", http.StatusOK, &jobs)
+	if len(jobs) != 2 {
+		t.Errorf("jobs = %+v, want 2", jobs)
+	}
+	request("GET", "/v1/jobs/unknown", "", http.StatusNotFound, nil)
+}
+
+func TestServer_Guard(t *testing.T) {
+	server := NewServer(context.Background(), &fakeBackend{}, "1.7.0")
+	api := httptest.NewServer(server.Handler())
+	defer api.Close()
+
+	do := func(method, path string, header map[string]string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, api.URL+path, strings.NewReader(`{"model": "hf/bert"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for key, value := range header {
+			req.Header.Set(key, value)
+		}
+		if host := header["Host"]; host != "" {
+			req.Host = host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"json", map[string]string{"Content-Type": "application/json; charset=utf-8"}, http.StatusOK},
+		{"form", map[string]string{"Content-Type": "application/x-www-form-urlencoded"}, http.StatusUnsupportedMediaType},
+		{"text", map[string]string{"Content-Type": "text/plain"}, http.StatusUnsupportedMediaType},
+		{"no content type", nil, http.StatusUnsupportedMediaType},
+		{"origin", map[string]string{"Content-Type": "application/json", "Origin": "https://evil.example"}, http.StatusForbidden},
+		{"rebound host", map[string]string{"Content-Type": "application/json", "Host": "evil.example:7878"}, http.StatusForbidden},
+		{"localhost", map[string]string{"Content-Type": "application/json", "Host": "localhost:7878"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := do("POST", "/v1/register", tt.header); got != tt.want {
+			t.Errorf("%s: POST /v1/register = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	server.RequireToken("secret")
+	if got := do("POST", "/v1/register", map[string]string{"Content-Type": "application/json"}); got != http.StatusUnauthorized {
+		t.Errorf("without token: POST /v1/register = %d, want 401", got)
+	}
+	if got := do("POST", "/v1/register", map[string]string{"Content-Type": "application/json", "Authorization": "Bearer wrong"}); got != http.StatusUnauthorized {
+		t.Errorf("wrong token: POST /v1/register = %d, want 401", got)
+	}
+	if got := do("POST", "/v1/register", map[string]string{"Content-Type": "application/json", "Authorization": "Bearer secret"}); got != http.StatusOK {
+		t.Errorf("with token: POST /v1/register = %d, want 200", got)
+	}
+	if got := do("GET", "/v1/health", nil); got != http.StatusOK {
+		t.Errorf("GET /v1/health without token = %d, want 200", got)
+	}
+}
+
+func TestJobs_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := NewJobs(ctx)
+	job, _ := jobs.Start("install", "hf/bert", "", func(ctx context.Context, progress ProgressFunc) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(10 * time.Second):
+			return nil
+		}
+	})
+	cancel()
+	jobs.Wait()
+	if got, _ := jobs.Get(job.ID); got.Status != JobFailed {
+		t.Errorf("job after cancel = %+v, want failed", got)
+	}
+}
+
+func TestJobs_Priority(t *testing.T) {
+	jobs := NewJobs(context.Background())
+	jobs.SetMaxRunning(1)
+
+	started := make(chan string, 8)
+	release := map[string]chan struct{}{}
+	fn := func(name string) JobFunc {
+		done := make(chan struct{})
+		release[name] = done
+		return func(ctx context.Context, progress ProgressFunc) error {
+			started <- name
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-done:
+				return nil
+			}
+		}
+	}
+	waitFor := func(id, status string) Job {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+			if job, _ := jobs.Get(id); job.Status == status {
+				return job
+			}
+		}
+		job, _ := jobs.Get(id)
+		t.Fatalf("job %s = %+v, want %s", id, job, status)
+		return job
+	}
+
+	prefetch, _ := jobs.Start("install", "hf/prefetch", PriorityBackground, fn("prefetch"))
+	if got := <-started; got != "prefetch" {
+		t.Fatalf("started %s, want prefetch", got)
+	}
+	normal, _ := jobs.Start("install", "hf/normal", PriorityNormal, fn("normal"))
+	if job, _ := jobs.Get(normal.ID); job.Status != JobQueued {
+		t.Errorf("normal job with no free slot = %+v, want queued", job)
+	}
+
+	// An urgent job starts at once and sends the background job back to the queue
+	urgent, _ := jobs.Start("install", "hf/urgent", PriorityUrgent, fn("urgent"))
+	if job := waitFor(prefetch.ID, JobQueued); job.Preemptions != 1 {
+		t.Errorf("preempted job = %+v, want 1 preemption", job)
+	}
+	for want := map[string]bool{"urgent": true, "normal": true}; len(want) > 0; {
+		got := <-started
+		if !want[got] {
+			t.Fatalf("started %s, want urgent and normal", got)
+		}
+		delete(want, got)
+	}
+	queue := jobs.Queue()
+	if len(queue) != 3 || queue[2].ID != prefetch.ID {
+		t.Errorf("queue = %+v, want the prefetch last", queue)
+	}
+
+	// Background jobs wait while an urgent job runs, and for a slot
+	close(release["normal"])
+	waitFor(normal.ID, JobSucceeded)
+	if job, _ := jobs.Get(prefetch.ID); job.Status != JobQueued {
+		t.Errorf("background job while an urgent job runs = %+v, want queued", job)
+	}
+	close(release["urgent"])
+	waitFor(urgent.ID, JobSucceeded)
+	if got := <-started; got != "prefetch" {
+		t.Fatalf("started %s, want prefetch", got)
+	}
+
+	// A more urgent request for a queued or running job raises its priority
+	if job, started := jobs.Start("install", "hf/prefetch", PriorityUrgent, fn("again")); started || job.Priority != PriorityUrgent {
+		t.Errorf("urgent request for a running job = %+v, %t; want the job raised to urgent", job, started)
+	}
+	close(release["prefetch"])
+	jobs.Wait()
+	if job, _ := jobs.Get(prefetch.ID); job.Status != JobSucceeded {
+		t.Errorf("prefetch job = %+v, want succeeded", job)
+	}
+}
@@ -0,0 +1,98 @@
+package delta
+
+import (
+	"fmt"
+	"io"
+)
+
+// Chunking sets the sizes of content-defined chunks. Past MinSize, a chunk ends where the
+// rolling hash of the last bytes is a multiple of AvgSize, so chunks are MinSize+AvgSize
+// bytes on average; they are never longer than MaxSize.
+// The index records the sizes a delta was made with, so it is applied with the same.
+type Chunking struct {
+	MinSize int `json:"min_size"`
+	AvgSize int `json:"avg_size"` // A power of two
+	MaxSize int `json:"max_size"`
+}
+
+// DefaultChunking suits model weights: small enough that a fine-tuned layer doesn't drag
+// in its neighbours, large enough that the index of a multi-GB model stays small
+var DefaultChunking = Chunking{MinSize: 256 << 10, AvgSize: 1 << 20, MaxSize: 4 << 20}
+
+// maxChunkSize bounds the chunk size a delta may ask for, since a chunk is buffered
+const maxChunkSize = 64 << 20
+
+// validate checks that the sizes are usable
+func (c Chunking) validate() error {
+	if c.MinSize <= 0 || c.AvgSize <= 0 || c.AvgSize&(c.AvgSize-1) != 0 || c.MaxSize < c.MinSize || c.MaxSize > maxChunkSize {
+		return fmt.Errorf("bad chunk sizes %+v", c)
+	}
+	return nil
+}
+
+// gear is the table of the gear rolling hash: a fixed pseudo-random value per byte.
+// It must never change, or deltas would no longer line up with installed files.
+var gear = func() [256]uint64 {
+	var table [256]uint64
+	state := uint64(0x6178_6f6e_6465_6c74) // "axondelt"
+	for i := range table {
+		// splitmix64
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// chunker splits a stream into content-defined chunks
+type chunker struct {
+	r      io.Reader
+	params Chunking
+	mask   uint64
+	buf    []byte
+	n      int // Bytes buffered
+	eof    bool
+}
+
+func newChunker(r io.Reader, params Chunking) *chunker {
+	return &chunker{r: r, params: params, mask: uint64(params.AvgSize - 1), buf: make([]byte, params.MaxSize)}
+}
+
+// next returns the next chunk, or io.EOF at the end of the stream
+func (c *chunker) next() ([]byte, error) {
+	for c.n < len(c.buf) && !c.eof {
+		n, err := c.r.Read(c.buf[c.n:])
+		c.n += n
+		if err == io.EOF {
+			c.eof = true
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	if c.n == 0 {
+		return nil, io.EOF
+	}
+
+	size := c.cut(c.buf[:c.n])
+	chunk := make([]byte, size)
+	copy(chunk, c.buf[:size])
+	c.n = copy(c.buf, c.buf[size:c.n])
+	return chunk, nil
+}
+
+// cut returns the length of the chunk at the start of data
+func (c *chunker) cut(data []byte) int {
+	if len(data) <= c.params.MinSize {
+		return len(data)
+	}
+	var hash uint64
+	for i := c.params.MinSize; i < len(data); i++ {
+		hash = hash<<1 + gear[data[i]]
+		if hash&c.mask == 0 {
+			return i + 1
+		}
+	}
+	return len(data)
+}
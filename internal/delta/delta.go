@@ -0,0 +1,463 @@
+// Package delta builds and applies delta packages, which update an installed version
+// of a model to a newer one without downloading the files the two have in common.
+//
+// Files are split into content-defined chunks with a gear rolling hash, so changing
+// part of a weight file only changes the chunks around the edit. A delta describes
+// every file of the new version as a list of chunk digests and carries only the chunks
+// the old version doesn't have; applying it rebuilds the new files from the installed
+// old version plus those chunks, and checks the SHA-256 of every rebuilt file.
+//
+// A delta is an uncompressed tar archive whose first entry is delta.json, the index,
+// followed by one entry per carried chunk named by its SHA-256.
+package delta
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// FormatVersion is the delta format written by this version of Axon
+const FormatVersion = 1
+
+// IndexFileName is the name of the delta index, always the first entry
+const IndexFileName = "delta.json"
+
+// Extension is the conventional file extension of deltas
+const Extension = ".axondelta"
+
+// ErrInvalid is returned for archives that are not well-formed deltas
+var ErrInvalid = errors.New("invalid delta")
+
+// ErrBaseMismatch is returned when the installed version lacks chunks the delta relies on,
+// e.g. because its files were modified or it is not the version the delta was made from
+var ErrBaseMismatch = errors.New("installed files don't match the delta's base version")
+
+// Chunk is a piece of a file, identified by its SHA-256
+type Chunk struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+}
+
+// File is a file of the new version and the chunks it is made of, in order
+type File struct {
+	Path   string  `json:"path"` // Slash-separated, relative to the model directory
+	Size   int64   `json:"size"`
+	SHA256 string  `json:"sha256"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Index is the contents of delta.json
+type Index struct {
+	FormatVersion int       `json:"format_version"`
+	Model         string    `json:"model"` // namespace/name
+	From          string    `json:"from"`  // Version the delta applies to
+	To            string    `json:"to"`    // Version the delta produces
+	CreatedAt     time.Time `json:"created_at"`
+	Chunking      Chunking  `json:"chunking"`
+	Files         []File    `json:"files"`
+	Chunks        []Chunk   `json:"chunks"` // Chunks carried in the delta, in archive order
+}
+
+// Size returns the total size of the new version's files
+func (idx *Index) Size() int64 {
+	var size int64
+	for _, file := range idx.Files {
+		size += file.Size
+	}
+	return size
+}
+
+// isModelFile reports whether a file in a model directory is carried by deltas. The
+// manifest and the files Axon derives from the model files are not.
+func isModelFile(relPath string) bool {
+	switch relPath {
+	case "manifest.yaml", model.InventoryFileName, ".axon_metadata.json":
+		return false
+	}
+	return !strings.HasSuffix(relPath, ".axon")
+}
+
+// modelFiles lists the files of a model directory that deltas carry, sorted by path
+func modelFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if strings.HasSuffix(relPath, ".axonenc") {
+			return fmt.Errorf("%s is encrypted", relPath)
+		}
+		if isModelFile(relPath) {
+			files = append(files, relPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in %s: %w", dir, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// location is where a chunk can be read from
+type location struct {
+	path   string
+	offset int64
+	size   int64
+}
+
+// chunkFile splits a file into chunks and calls fn with each chunk's offset and contents
+func chunkFile(filePath string, params Chunking, fn func(offset int64, data []byte) error) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	c := newChunker(f, params)
+	var offset int64
+	for {
+		data, err := c.next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		if err := fn(offset, data); err != nil {
+			return err
+		}
+		offset += int64(len(data))
+	}
+}
+
+// digest returns the hex SHA-256 of data
+func digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// chunkIndex maps the digests of the chunks of every model file in dir to where they are
+func chunkIndex(dir string, params Chunking) (map[string]location, error) {
+	files, err := modelFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make(map[string]location)
+	for _, relPath := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(relPath))
+		err := chunkFile(filePath, params, func(offset int64, data []byte) error {
+			chunks[digest(data)] = location{path: filePath, offset: offset, size: int64(len(data))}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chunks, nil
+}
+
+// Create writes a delta from the model files in oldDir (version from) to those in newDir
+// (version to) at dest, carrying the chunks of newDir that oldDir doesn't have. The delta
+// is written to a temporary file first, so dest is either complete or untouched.
+func Create(dest, modelID, from, to, oldDir, newDir string) (*Index, error) {
+	return create(dest, modelID, from, to, oldDir, newDir, DefaultChunking)
+}
+
+func create(dest, modelID, from, to, oldDir, newDir string, params Chunking) (*Index, error) {
+	base, err := chunkIndex(oldDir, params)
+	if err != nil {
+		return nil, err
+	}
+	files, err := modelFiles(newDir)
+	if err != nil {
+		return nil, err
+	}
+
+	index := &Index{
+		FormatVersion: FormatVersion,
+		Model:         modelID,
+		From:          from,
+		To:            to,
+		CreatedAt:     time.Now().UTC(),
+		Chunking:      params,
+		Files:         []File{},
+		Chunks:        []Chunk{},
+	}
+	var carried []location
+	seen := make(map[string]bool)
+	for _, relPath := range files {
+		filePath := filepath.Join(newDir, filepath.FromSlash(relPath))
+		file := File{Path: relPath, Chunks: []Chunk{}}
+		hasher := sha256.New()
+		err := chunkFile(filePath, params, func(offset int64, data []byte) error {
+			chunk := Chunk{SHA256: digest(data), Size: int64(len(data))}
+			file.Chunks = append(file.Chunks, chunk)
+			file.Size += chunk.Size
+			hasher.Write(data)
+			if _, ok := base[chunk.SHA256]; !ok && !seen[chunk.SHA256] {
+				seen[chunk.SHA256] = true
+				index.Chunks = append(index.Chunks, chunk)
+				carried = append(carried, location{path: filePath, offset: offset, size: chunk.Size})
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		file.SHA256 = hex.EncodeToString(hasher.Sum(nil))
+		index.Files = append(index.Files, file)
+	}
+
+	tmp := dest + ".tmp"
+	if err := writeArchive(tmp, index, carried); err != nil {
+		_ = os.Remove(tmp)
+		return nil, err
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		_ = os.Remove(tmp)
+		return nil, fmt.Errorf("failed to write delta: %w", err)
+	}
+	return index, nil
+}
+
+// writeArchive writes the index and the carried chunks to a new tar archive at p
+func writeArchive(p string, index *Index, carried []location) error {
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create delta: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	tw := tar.NewWriter(f)
+	data, err := json.Marshal(index)
+	if err != nil {
+		return fmt.Errorf("failed to encode delta index: %w", err)
+	}
+	if err := model.WriteTarEntry(tw, IndexFileName, int64(len(data)), bytes.NewReader(data)); err != nil {
+		return err
+	}
+	for i, loc := range carried {
+		if err := addChunk(tw, index.Chunks[i].SHA256, loc); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write delta: %w", err)
+	}
+	return f.Close()
+}
+
+// addChunk copies a chunk of a file into the archive
+func addChunk(tw *tar.Writer, name string, loc location) error {
+	src, err := os.Open(loc.path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", loc.path, err)
+	}
+	defer func() { _ = src.Close() }()
+	return model.WriteTarEntry(tw, name, loc.size, io.NewSectionReader(src, loc.offset, loc.size))
+}
+
+// ReadIndex reads the index of a delta without applying it
+func ReadIndex(deltaPath string) (*Index, error) {
+	f, err := os.Open(deltaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open delta: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return readIndex(tar.NewReader(f), filepath.Base(deltaPath))
+}
+
+// readIndex reads and validates the first entry of a delta
+func readIndex(tr *tar.Reader, name string) (*Index, error) {
+	header, err := tr.Next()
+	if err != nil || header.Name != IndexFileName {
+		return nil, fmt.Errorf("%w: %s is not an Axon delta (no %s)", ErrInvalid, name, IndexFileName)
+	}
+	var index Index
+	if err := json.NewDecoder(io.LimitReader(tr, 64<<20)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("%w: unreadable %s: %v", ErrInvalid, IndexFileName, err)
+	}
+	if index.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("%w: format version %d is not supported (this Axon reads version %d)", ErrInvalid, index.FormatVersion, FormatVersion)
+	}
+	if err := index.Chunking.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	for _, file := range index.Files {
+		// Paths stay inside the model directory
+		if file.Path == "" || path.IsAbs(file.Path) || path.Clean(file.Path) != file.Path || file.Path == ".." || strings.HasPrefix(file.Path, "../") {
+			return nil, fmt.Errorf("%w: bad file path %q", ErrInvalid, file.Path)
+		}
+	}
+	return &index, nil
+}
+
+// Apply rebuilds the new version described by a delta in destDir, from the model files
+// of the old version in baseDir and the chunks carried in the delta. Every rebuilt file
+// is checked against its SHA-256 in the index. It returns the index.
+func Apply(deltaPath, baseDir, destDir string) (*Index, error) {
+	f, err := os.Open(deltaPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open delta: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	tr := tar.NewReader(f)
+	index, err := readIndex(tr, filepath.Base(deltaPath))
+	if err != nil {
+		return nil, err
+	}
+
+	chunkDir, err := os.MkdirTemp(utils.TempDir(), "axon-delta-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(chunkDir) }()
+
+	chunks, err := extractChunks(tr, index, chunkDir)
+	if err != nil {
+		return nil, err
+	}
+	base, err := chunkIndex(baseDir, index.Chunking)
+	if err != nil {
+		return nil, err
+	}
+	for digest, loc := range base {
+		if _, ok := chunks[digest]; !ok {
+			chunks[digest] = loc
+		}
+	}
+
+	for _, file := range index.Files {
+		if err := rebuildFile(file, chunks, filepath.Join(destDir, filepath.FromSlash(file.Path))); err != nil {
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// extractChunks writes the chunks carried in a delta to dir, checking each against its
+// digest, and returns where they are
+func extractChunks(tr *tar.Reader, index *Index, dir string) (map[string]location, error) {
+	expected := make(map[string]Chunk, len(index.Chunks))
+	for _, chunk := range index.Chunks {
+		expected[chunk.SHA256] = chunk
+	}
+	chunks := make(map[string]location, len(index.Chunks))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read delta: %v", ErrInvalid, err)
+		}
+		chunk, ok := expected[header.Name]
+		if !ok || header.Typeflag != tar.TypeReg {
+			return nil, fmt.Errorf("%w: unexpected entry %q", ErrInvalid, header.Name)
+		}
+		delete(expected, header.Name)
+
+		// Chunk names were checked against the index, which only holds hex digests
+		chunkPath := filepath.Join(dir, fmt.Sprintf("%d", len(chunks)))
+		if err := extractChunk(tr, chunk, chunkPath); err != nil {
+			return nil, err
+		}
+		chunks[chunk.SHA256] = location{path: chunkPath, size: chunk.Size}
+	}
+	for name := range expected {
+		return nil, fmt.Errorf("%w: chunk %s is missing (truncated delta?)", ErrInvalid, name)
+	}
+	return chunks, nil
+}
+
+// extractChunk writes a chunk to dest, checking its size and digest
+func extractChunk(r io.Reader, chunk Chunk, dest string) error {
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to extract chunk: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(out, hasher), io.LimitReader(r, chunk.Size+1))
+	if err != nil {
+		return fmt.Errorf("failed to extract chunk: %w", err)
+	}
+	if size != chunk.Size {
+		return fmt.Errorf("%w: chunk %s is %d bytes, index says %d", ErrInvalid, chunk.SHA256, size, chunk.Size)
+	}
+	if got := hex.EncodeToString(hasher.Sum(nil)); !strings.EqualFold(got, chunk.SHA256) {
+		return fmt.Errorf("%w: chunk checksum mismatch (expected sha256:%s, got sha256:%s)", ErrInvalid, chunk.SHA256, got)
+	}
+	return out.Close()
+}
+
+// rebuildFile writes a file of the new version to dest from its chunks, checking its
+// size and digest
+func rebuildFile(file File, chunks map[string]location, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", file.Path, err)
+	}
+	defer func() { _ = out.Close() }()
+
+	hasher := sha256.New()
+	w := io.MultiWriter(out, hasher)
+	var size int64
+	for _, chunk := range file.Chunks {
+		loc, ok := chunks[chunk.SHA256]
+		if !ok || loc.size != chunk.Size {
+			return fmt.Errorf("%w: chunk %s of %s is neither in the delta nor in the installed files", ErrBaseMismatch, chunk.SHA256, file.Path)
+		}
+		if err := copyChunk(w, loc); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", file.Path, err)
+		}
+		size += chunk.Size
+	}
+	if got := hex.EncodeToString(hasher.Sum(nil)); size != file.Size || !strings.EqualFold(got, file.SHA256) {
+		return fmt.Errorf("%w: rebuilt %s does not match (expected sha256:%s, got sha256:%s)", ErrBaseMismatch, file.Path, file.SHA256, got)
+	}
+	return out.Close()
+}
+
+// copyChunk copies a chunk from where it is to w
+func copyChunk(w io.Writer, loc location) error {
+	src, err := os.Open(loc.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+	n, err := io.Copy(w, io.NewSectionReader(src, loc.offset, loc.size))
+	if err != nil {
+		return err
+	}
+	if n != loc.size {
+		return fmt.Errorf("%s changed while the delta was applied", loc.path)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package delta
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testChunking keeps chunks small so test files span many of them
+var testChunking = Chunking{MinSize: 1 << 10, AvgSize: 4 << 10, MaxSize: 16 << 10}
+
+// writeTestFiles creates files (relative path -> content) under dir
+func writeTestFiles(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// testVersions returns the files of two versions of a model: the second fine-tunes part
+// of the weights, changes the config and adds a file
+func testVersions() (map[string][]byte, map[string][]byte) {
+	weights := make([]byte, 512<<10)
+	rand.New(rand.NewSource(1)).Read(weights)
+	tuned := bytes.Clone(weights)
+	rand.New(rand.NewSource(2)).Read(tuned[200<<10 : 210<<10])
+	tuned = append(tuned[:300<<10], append([]byte("inserted bytes"), tuned[300<<10:]...)...)
+
+	old := map[string][]byte{
+		"weights/model.safetensors": weights,
+		"config/config.json":        []byte(`{"model_type": "bert", "version": 1}`),
+		"manifest.yaml":             []byte("kind: Model\n"),
+		"hf-bert-1.0.0.axon":        []byte("package"),
+	}
+	updated := map[string][]byte{
+		"weights/model.safetensors": tuned,
+		"config/config.json":        []byte(`{"model_type": "bert", "version": 2}`),
+		"tokenizer/vocab.txt":       []byte("[CLS]\n[SEP]\n"),
+		"manifest.yaml":             []byte("kind: Model\nversion: 2\n"),
+		"files.json":                []byte("{}"),
+	}
+	return old, updated
+}
+
+func TestCreateApply(t *testing.T) {
+	oldFiles, newFiles := testVersions()
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeTestFiles(t, oldDir, oldFiles)
+	writeTestFiles(t, newDir, newFiles)
+
+	deltaPath := filepath.Join(t.TempDir(), "bert"+Extension)
+	created, err := create(deltaPath, "hf/bert", "1.0.0", "2.0.0", oldDir, newDir, testChunking)
+	if err != nil {
+		t.Fatalf("create() error = %v", err)
+	}
+	if len(created.Files) != 3 {
+		t.Errorf("delta has %d files, want the 3 model files", len(created.Files))
+	}
+	info, err := os.Stat(deltaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := created.Size(); info.Size() > total/4 {
+		t.Errorf("delta is %d bytes for %d bytes of files; unchanged chunks were not reused", info.Size(), total)
+	}
+
+	dest := t.TempDir()
+	index, err := Apply(deltaPath, oldDir, dest)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if index.Model != "hf/bert" || index.From != "1.0.0" || index.To != "2.0.0" {
+		t.Errorf("index = %+v", index)
+	}
+	for _, path := range []string{"weights/model.safetensors", "config/config.json", "tokenizer/vocab.txt"} {
+		data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(path)))
+		if err != nil || !bytes.Equal(data, newFiles[path]) {
+			t.Errorf("rebuilt %s differs (%v)", path, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "manifest.yaml")); !os.IsNotExist(err) {
+		t.Error("delta carried the manifest")
+	}
+}
+
+func TestApply_BaseMismatch(t *testing.T) {
+	oldFiles, newFiles := testVersions()
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeTestFiles(t, oldDir, oldFiles)
+	writeTestFiles(t, newDir, newFiles)
+	deltaPath := filepath.Join(t.TempDir(), "bert"+Extension)
+	if _, err := create(deltaPath, "hf/bert", "1.0.0", "2.0.0", oldDir, newDir, testChunking); err != nil {
+		t.Fatal(err)
+	}
+
+	// A locally modified base lacks chunks the delta relies on
+	modified := bytes.Clone(oldFiles["weights/model.safetensors"])
+	rand.New(rand.NewSource(3)).Read(modified)
+	writeTestFiles(t, oldDir, map[string][]byte{"weights/model.safetensors": modified})
+
+	if _, err := Apply(deltaPath, oldDir, t.TempDir()); !errors.Is(err, ErrBaseMismatch) {
+		t.Errorf("Apply() error = %v, want ErrBaseMismatch", err)
+	}
+}
+
+func TestApply_Invalid(t *testing.T) {
+	oldFiles, newFiles := testVersions()
+	oldDir, newDir := t.TempDir(), t.TempDir()
+	writeTestFiles(t, oldDir, oldFiles)
+	writeTestFiles(t, newDir, newFiles)
+	deltaPath := filepath.Join(t.TempDir(), "bert"+Extension)
+	index, err := create(deltaPath, "hf/bert", "1.0.0", "2.0.0", oldDir, newDir, testChunking)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Chunks) == 0 {
+		t.Fatal("delta carries no chunks")
+	}
+	data, err := os.ReadFile(deltaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func([]byte) []byte
+		wantErr string
+	}{
+		{name: "not a delta", mutate: func([]byte) []byte { return []byte("garbage") }, wantErr: "not an Axon delta"},
+		{name: "corrupted chunk", mutate: func(d []byte) []byte {
+			// The first chunk follows the index entry's header and padded contents
+			indexData, _ := json.Marshal(index)
+			d = bytes.Clone(d)
+			d[512+(len(indexData)+511)/512*512+512+10] ^= 0xff
+			return d
+		}, wantErr: "checksum mismatch"},
+		{name: "future format", mutate: func(d []byte) []byte {
+			return bytes.Replace(d, []byte(`"format_version":1`), []byte(`"format_version":9`), 1)
+		}, wantErr: "not supported"},
+		{name: "path traversal", mutate: func(d []byte) []byte {
+			return bytes.Replace(d, []byte(`"path":"config/config.json"`), []byte(`"path":"../../config.jsonx"`), 1)
+		}, wantErr: "bad file path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "bad"+Extension)
+			if err := os.WriteFile(path, tt.mutate(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Apply(path, oldDir, t.TempDir())
+			if !errors.Is(err, ErrInvalid) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Apply() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
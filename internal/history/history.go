@@ -0,0 +1,153 @@
+// Package history records model lifecycle events (install, uninstall, register) in an
+// append-only log, so operators of shared hosts can reconstruct who changed which model when.
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileName is the history log file name in the Axon home directory
+const FileName = "history.log"
+
+// Actions recorded in the history log
+const (
+	ActionInstall    = "install"
+	ActionUninstall  = "uninstall"
+	ActionUpdate     = "update"
+	ActionRegister   = "register"
+	ActionUnregister = "unregister"
+	ActionImport     = "import"
+)
+
+// Results recorded in the history log
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// Event is a single history record, stored as one JSON line
+type Event struct {
+	Time        time.Time `json:"time"`
+	User        string    `json:"user"`
+	SudoUser    string    `json:"sudo_user,omitempty"` // The invoking user when run via sudo
+	Host        string    `json:"host"`
+	Action      string    `json:"action"`
+	Model       string    `json:"model"` // namespace/name@version as requested
+	Result      string    `json:"result"`
+	Error       string    `json:"error,omitempty"`
+	Digest      string    `json:"digest,omitempty"` // Package SHA256
+	DurationSec float64   `json:"duration_sec,omitempty"`
+}
+
+// Filter selects events when reading the log
+type Filter struct {
+	Action string    // Only this action ("" = all)
+	Model  string    // Only models containing this string ("" = all)
+	Since  time.Time // Only events at or after this time (zero = all)
+	Failed bool      // Only failures
+	Limit  int       // Only the most recent Limit events (0 = all)
+}
+
+// Match reports whether e passes the filter (ignoring Limit)
+func (f Filter) Match(e Event) bool {
+	if f.Action != "" && e.Action != f.Action {
+		return false
+	}
+	if f.Model != "" && !strings.Contains(e.Model, f.Model) {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.Failed && e.Result != ResultFailure {
+		return false
+	}
+	return true
+}
+
+// appendMu serializes appends from concurrent installs in one process
+var appendMu sync.Mutex
+
+// Append adds an event to the log at path, filling in the time, user and host if unset
+func Append(path string, e Event) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	if e.User == "" {
+		e.User = currentUser()
+		e.SudoUser = os.Getenv("SUDO_USER")
+	}
+	if e.Host == "" {
+		e.Host, _ = os.Hostname()
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history event: %w", err)
+	}
+	line = append(line, '\n')
+
+	appendMu.Lock()
+	defer appendMu.Unlock()
+
+	// O_APPEND keeps each single-write record intact when several processes log at once
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if _, err := f.Write(line); err != nil {
+		return fmt.Errorf("failed to write history log: %w", err)
+	}
+	return nil
+}
+
+// Read returns the events in the log at path that match filter, oldest first.
+// A missing log has no events; malformed lines (e.g., from a crash mid-write) are skipped.
+func Read(path string, filter Filter) ([]Event, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var events []Event
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		if filter.Match(e) {
+			events = append(events, e)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history log: %w", err)
+	}
+
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[len(events)-filter.Limit:]
+	}
+	return events, nil
+}
+
+// currentUser returns the name of the user running Axon
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
@@ -0,0 +1,91 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	events := []Event{
+		{Time: base, Action: ActionInstall, Model: "hf/bert-base-uncased@latest", Result: ResultSuccess, Digest: "abc123"},
+		{Time: base.Add(time.Hour), Action: ActionInstall, Model: "hf/gpt2@latest", Result: ResultFailure, Error: "download failed"},
+		{Time: base.Add(2 * time.Hour), Action: ActionUninstall, Model: "hf/bert-base-uncased@latest", Result: ResultSuccess},
+	}
+	for _, e := range events {
+		if err := Append(path, e); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+
+	all, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("Read() returned %d events, want 3", len(all))
+	}
+	if all[0].User == "" || all[0].Host == "" {
+		t.Errorf("Append() did not fill in user and host: %+v", all[0])
+	}
+	if !all[0].Time.Equal(base) || all[0].Digest != "abc123" {
+		t.Errorf("Read()[0] = %+v, want the first appended event", all[0])
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"by action", Filter{Action: ActionInstall}, 2},
+		{"by model", Filter{Model: "bert"}, 2},
+		{"since", Filter{Since: base.Add(30 * time.Minute)}, 2},
+		{"failed", Filter{Failed: true}, 1},
+		{"limit keeps newest", Filter{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(path, tt.filter)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(got) != tt.want {
+				t.Errorf("Read() returned %d events, want %d", len(got), tt.want)
+			}
+		})
+	}
+
+	newest, _ := Read(path, Filter{Limit: 1})
+	if newest[0].Action != ActionUninstall {
+		t.Errorf("Limit kept %s, want the newest event", newest[0].Action)
+	}
+}
+
+func TestRead_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	content := `{"time":"2026-01-02T03:04:05Z","action":"install","model":"hf/gpt2@latest","result":"success"}
+{"time":"2026-01-02T03:0
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := Read(path, Filter{})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Errorf("Read() returned %d events, want 1", len(events))
+	}
+}
+
+func TestRead_MissingLog(t *testing.T) {
+	events, err := Read(filepath.Join(t.TempDir(), FileName), Filter{})
+	if err != nil || len(events) != 0 {
+		t.Errorf("Read() = %v, %v; want no events and no error", events, err)
+	}
+}
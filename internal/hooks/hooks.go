@@ -0,0 +1,163 @@
+// Package hooks runs user-configured hooks on model lifecycle events, so monitoring and
+// orchestration systems can follow what Axon does: shell commands that get the event as
+// JSON on stdin, and HTTP webhooks the event is POSTed to.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Lifecycle events hooks can subscribe to
+const (
+	EventInstallComplete    = "install-complete"
+	EventConversionComplete = "conversion-complete"
+	EventVerifyFailure      = "verify-failure"
+	EventUninstall          = "uninstall"
+)
+
+// Events lists every lifecycle event
+var Events = []string{EventInstallComplete, EventConversionComplete, EventVerifyFailure, EventUninstall}
+
+// DefaultTimeout bounds hooks that don't set a timeout
+const DefaultTimeout = 10 * time.Second
+
+// Hook is a shell command or webhook run on lifecycle events
+type Hook struct {
+	Events  []string
+	Command string // Run with sh -c
+	URL     string // http(s) URL to POST to
+	Timeout time.Duration
+}
+
+// Payload is the JSON document hooks receive
+type Payload struct {
+	Event    string          `json:"event"`
+	Time     time.Time       `json:"time"`
+	Model    string          `json:"model"` // namespace/name@version
+	Path     string          `json:"path,omitempty"`
+	Error    string          `json:"error,omitempty"`    // Why a conversion or verification failed
+	Manifest json.RawMessage `json:"manifest,omitempty"` // With the field names of manifest.yaml
+}
+
+// Validate checks that the hook runs exactly one command or webhook on known events
+func (h Hook) Validate() error {
+	if (h.Command == "") == (h.URL == "") {
+		return fmt.Errorf("a hook needs exactly one of command and url")
+	}
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hook url %q is not an http(s) URL", h.URL)
+		}
+	}
+	if len(h.Events) == 0 {
+		return fmt.Errorf("hook %s has no events (any of %s)", h, strings.Join(Events, ", "))
+	}
+	for _, event := range h.Events {
+		if !knownEvent(event) {
+			return fmt.Errorf("hook %s: unknown event %q (want any of %s)", h, event, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+// Subscribed reports whether the hook runs on event
+func (h Hook) Subscribed(event string) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// String names the hook by its command or URL
+func (h Hook) String() string {
+	if h.URL != "" {
+		return h.URL
+	}
+	return fmt.Sprintf("%q", h.Command)
+}
+
+// Fire runs the hooks subscribed to payload.Event in order and returns their failures;
+// a failing hook doesn't stop the others
+func Fire(ctx context.Context, hooks []Hook, payload Payload) []error {
+	if payload.Time.IsZero() {
+		payload.Time = time.Now().UTC()
+	}
+	var data []byte
+	var errs []error
+	for _, h := range hooks {
+		if !h.Subscribed(payload.Event) {
+			continue
+		}
+		if data == nil {
+			var err error
+			if data, err = json.Marshal(payload); err != nil {
+				return []error{fmt.Errorf("failed to marshal %s event: %w", payload.Event, err)}
+			}
+		}
+		if err := h.run(ctx, payload, data); err != nil {
+			errs = append(errs, fmt.Errorf("%s hook %s failed: %w", payload.Event, h, err))
+		}
+	}
+	return errs
+}
+
+// run runs the hook once with the JSON payload data
+func (h Hook) run(ctx context.Context, payload Payload, data []byte) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if h.Command != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		cmd.Stdin = bytes.NewReader(data)
+		cmd.Env = append(os.Environ(), "AXON_EVENT="+payload.Event, "AXON_MODEL="+payload.Model)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			if out := strings.TrimSpace(string(output)); out != "" {
+				return fmt.Errorf("%w: %s", err, out)
+			}
+			return err
+		}
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Axon-Event", payload.Event)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// knownEvent reports whether event is a lifecycle event
+func knownEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHook_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		hook    Hook
+		wantErr string
+	}{
+		{name: "command", hook: Hook{Events: []string{EventInstallComplete}, Command: "true"}},
+		{name: "webhook", hook: Hook{Events: Events, URL: "https://example.com/axon"}},
+		{name: "neither", hook: Hook{Events: Events}, wantErr: "exactly one of command and url"},
+		{name: "both", hook: Hook{Events: Events, Command: "true", URL: "https://example.com"}, wantErr: "exactly one of command and url"},
+		{name: "not http", hook: Hook{Events: Events, URL: "ftp://example.com"}, wantErr: "not an http(s) URL"},
+		{name: "no events", hook: Hook{Command: "true"}, wantErr: "has no events"},
+		{name: "unknown event", hook: Hook{Events: []string{"install"}, Command: "true"}, wantErr: `unknown event "install"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.hook.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFire(t *testing.T) {
+	var received Payload
+	var event string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		event = r.Header.Get("X-Axon-Event")
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("webhook body is not a payload: %v", err)
+		}
+	}))
+	defer server.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	out := filepath.Join(t.TempDir(), "event.json")
+	hooks := []Hook{
+		{Events: []string{EventUninstall}, Command: "cat > " + out + " && test \"$AXON_MODEL\" = hf/bert@1.0.0"},
+		{Events: []string{EventUninstall}, URL: failing.URL},
+		{Events: []string{EventUninstall}, Command: "echo broken >&2; exit 3"},
+		{Events: []string{EventUninstall}, URL: server.URL},
+		{Events: []string{EventInstallComplete}, Command: "touch " + out + ".install"},
+	}
+
+	payload := Payload{Event: EventUninstall, Model: "hf/bert@1.0.0", Manifest: json.RawMessage(`{"metadata":{"name":"bert"}}`)}
+	errs := Fire(context.Background(), hooks, payload)
+
+	// The failing hooks are reported, and don't stop the ones after them
+	if len(errs) != 2 || !strings.Contains(errs[0].Error(), "unexpected status code: 500") || !strings.Contains(errs[1].Error(), "broken") {
+		t.Errorf("Fire() errors = %v, want the failing webhook and command", errs)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("command hook did not run: %v", err)
+	}
+	var got Payload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("command hook stdin is not a payload: %v", err)
+	}
+	if got.Event != EventUninstall || got.Model != "hf/bert@1.0.0" || got.Time.IsZero() || string(got.Manifest) != `{"metadata":{"name":"bert"}}` {
+		t.Errorf("command hook payload = %+v", got)
+	}
+	if event != EventUninstall || received.Model != "hf/bert@1.0.0" {
+		t.Errorf("webhook got event %q, payload %+v", event, received)
+	}
+	if _, err := os.Stat(out + ".install"); err == nil {
+		t.Error("hook ran on an event it is not subscribed to")
+	}
+}
@@ -0,0 +1,177 @@
+package host
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Check statuses, from best to worst
+const (
+	StatusPass = "pass"
+	StatusWarn = "warn" // Below the recommended hardware; the model runs, slower
+	StatusFail = "fail" // Below the minimum; the model won't run well, if at all
+)
+
+// Check is the outcome of comparing one resource with a model's requirements
+type Check struct {
+	Resource string // "cpu", "memory", "gpu" or "disk"
+	Status   string
+	Detail   string
+}
+
+// gib is the number of bytes in a GB of requirements
+const gib = 1 << 30
+
+// CheckRequirements compares the host with a model's requirements. The disk is only
+// checked for models that aren't installed yet (installed false).
+func (h Info) CheckRequirements(r types.Requirements, installed bool) []Check {
+	checks := []Check{h.checkCPU(r.Compute.CPU), h.checkMemory(r.Compute.Memory), h.checkGPU(r.Compute.GPU)}
+	if !installed {
+		checks = append(checks, h.checkDisk(r.Storage))
+	}
+	return checks
+}
+
+// Worst returns the worst status of the checks
+func Worst(checks []Check) string {
+	worst := StatusPass
+	for _, check := range checks {
+		switch {
+		case check.Status == StatusFail:
+			return StatusFail
+		case check.Status == StatusWarn:
+			worst = StatusWarn
+		}
+	}
+	return worst
+}
+
+func (h Info) checkCPU(r types.CPURequirement) Check {
+	check := Check{Resource: "cpu", Status: StatusPass, Detail: fmt.Sprintf("%d (minimum %d, recommended %d cores)", h.CPUs, r.MinCores, r.RecommendedCores)}
+	switch {
+	case h.CPUs < r.MinCores:
+		check.Status = StatusFail
+	case h.CPUs < r.RecommendedCores:
+		check.Status = StatusWarn
+	}
+	return check
+}
+
+func (h Info) checkMemory(r types.MemoryRequirement) Check {
+	required := fmt.Sprintf("(minimum %s, recommended %s)", formatGB(r.MinGB), formatGB(r.RecommendedGB))
+	if h.MemoryBytes == 0 {
+		return Check{Resource: "memory", Status: StatusWarn, Detail: "could not detect memory " + required}
+	}
+
+	total := float64(h.MemoryBytes) / gib
+	check := Check{Resource: "memory", Status: StatusPass, Detail: fmt.Sprintf("%s %s", formatGB(total), required)}
+	switch {
+	case total < r.MinGB:
+		check.Status = StatusFail
+	case total < r.RecommendedGB:
+		check.Status = StatusWarn
+	}
+	// Enough memory in total can still be taken by other processes
+	if available := float64(h.AvailableMemoryBytes) / gib; h.AvailableMemoryBytes > 0 && available < r.MinGB {
+		check.Detail = fmt.Sprintf("%s, %s available now %s", formatGB(total), formatGB(available), required)
+		if check.Status == StatusPass {
+			check.Status = StatusWarn
+		}
+	}
+	return check
+}
+
+func (h Info) checkGPU(r *types.GPURequirement) Check {
+	if r == nil || !r.Required && !r.Recommended {
+		detail := "not needed"
+		if best := bestGPU(h.GPUs, nil); best != nil {
+			detail = fmt.Sprintf("not needed (%s)", describeGPU(*best))
+		}
+		return Check{Resource: "gpu", Status: StatusPass, Detail: detail}
+	}
+
+	need := "recommended"
+	if r.Required {
+		need = "required"
+	}
+	if r.MinVRAMGB > 0 {
+		need += fmt.Sprintf(", %s VRAM", formatGB(r.MinVRAMGB))
+	}
+	if r.MinComputeCapability != "" {
+		need += ", compute capability " + r.MinComputeCapability
+	}
+	if len(r.Accelerators) > 0 {
+		need += ", " + strings.Join(r.Accelerators, " or ")
+	}
+	shortfall := StatusWarn
+	if r.Required {
+		shortfall = StatusFail
+	}
+	// GPUs of platforms the model doesn't run on don't count
+	gpu := bestGPU(h.GPUs, r.Accelerators)
+	if gpu == nil {
+		return Check{Resource: "gpu", Status: shortfall, Detail: fmt.Sprintf("none detected (%s)", need)}
+	}
+
+	check := Check{Resource: "gpu", Status: StatusPass, Detail: fmt.Sprintf("%s (%s)", describeGPU(*gpu), need)}
+	if gpu.VRAMBytes > 0 && float64(gpu.VRAMBytes)/gib < r.MinVRAMGB {
+		check.Status = shortfall
+	}
+	if gpu.ComputeCapability != "" && r.MinComputeCapability != "" && !model.ComputeCapabilityAtLeast(gpu.ComputeCapability, r.MinComputeCapability) {
+		check.Status = shortfall
+	}
+	return check
+}
+
+// bestGPU returns the GPU with the most memory among those of the accelerators (any if
+// none are given), or nil
+func bestGPU(gpus []GPU, accelerators []string) *GPU {
+	var best *GPU
+	for i, gpu := range gpus {
+		if len(accelerators) > 0 && gpu.Accelerator != "" && !slices.Contains(accelerators, gpu.Accelerator) {
+			continue
+		}
+		if best == nil || gpu.VRAMBytes > best.VRAMBytes {
+			best = &gpus[i]
+		}
+	}
+	return best
+}
+
+func (h Info) checkDisk(r types.Storage) Check {
+	required := fmt.Sprintf("(minimum %s, recommended %s)", formatGB(r.MinGB), formatGB(r.RecommendedGB))
+	if h.DiskFreeBytes == 0 {
+		return Check{Resource: "disk", Status: StatusWarn, Detail: "could not detect free disk space " + required}
+	}
+
+	free := float64(h.DiskFreeBytes) / gib
+	check := Check{Resource: "disk", Status: StatusPass, Detail: fmt.Sprintf("%s free %s", formatGB(free), required)}
+	switch {
+	case free < r.MinGB:
+		check.Status = StatusFail
+	case free < r.RecommendedGB:
+		check.Status = StatusWarn
+	}
+	return check
+}
+
+// describeGPU names a GPU with its memory and compute capability
+func describeGPU(gpu GPU) string {
+	description := gpu.Name
+	if gpu.VRAMBytes > 0 {
+		description += ", " + formatGB(float64(gpu.VRAMBytes)/gib)
+	}
+	if gpu.ComputeCapability != "" {
+		description += ", compute capability " + gpu.ComputeCapability
+	}
+	return description
+}
+
+// formatGB renders a size in GB for check details
+func formatGB(gb float64) string {
+	return fmt.Sprintf("%.1fGB", gb)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package host
+
+import "syscall"
+
+// freeDiskBytes returns the space available to unprivileged users on the disk path is on
+func freeDiskBytes(path string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package host
+
+import "errors"
+
+// freeDiskBytes is not implemented on Windows; the disk check reports it as unknown
+func freeDiskBytes(path string) (int64, error) {
+	return 0, errors.ErrUnsupported
+}
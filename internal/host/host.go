@@ -0,0 +1,141 @@
+// Package host detects the hardware of the machine Axon runs on and checks it against
+// the requirements models declare in their manifests.
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// GPU is a GPU detected on the host
+type GPU struct {
+	Name              string
+	VRAMBytes         int64  // 0 if unknown
+	Accelerator       string // GPU platform, e.g. "cuda", "metal"
+	ComputeCapability string // CUDA compute capability, e.g. "8.6"; "" if unknown
+}
+
+// Info is the hardware of the host. Sizes are 0 when they couldn't be detected.
+type Info struct {
+	CPUs                 int
+	MemoryBytes          int64 // Total memory
+	AvailableMemoryBytes int64 // Memory available for new processes now
+	DiskFreeBytes        int64 // Free space on the disk models are cached on
+	GPUs                 []GPU
+}
+
+// Paths and commands hardware is detected with, replaced in tests
+var (
+	meminfoPath = "/proc/meminfo"
+	nvidiaSMI   = "nvidia-smi"
+)
+
+// gpuQueryTimeout bounds how long nvidia-smi may take to list the GPUs
+const gpuQueryTimeout = 5 * time.Second
+
+// Detect returns the hardware of the host, with the free space of the disk diskPath is on
+func Detect(ctx context.Context, diskPath string) Info {
+	info := Info{CPUs: runtime.NumCPU()}
+	info.MemoryBytes, info.AvailableMemoryBytes = detectMemory(ctx)
+	if free, err := freeDiskBytes(diskPath); err == nil {
+		info.DiskFreeBytes = free
+	}
+	info.GPUs = detectGPUs(ctx)
+	// Apple silicon GPUs share the system memory
+	if len(info.GPUs) == 0 && runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		info.GPUs = []GPU{{Name: "Apple silicon (unified memory)", VRAMBytes: info.MemoryBytes, Accelerator: types.AcceleratorMetal}}
+	}
+	return info
+}
+
+// detectMemory returns the total and available memory of the host
+func detectMemory(ctx context.Context) (total, available int64) {
+	switch runtime.GOOS {
+	case "linux":
+		data, err := os.ReadFile(meminfoPath)
+		if err != nil {
+			return 0, 0
+		}
+		return parseMeminfo(data)
+	case "darwin":
+		out, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0, 0
+		}
+		total, _ = strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		return total, 0
+	}
+	return 0, 0
+}
+
+// parseMeminfo reads MemTotal and MemAvailable from /proc/meminfo
+func parseMeminfo(data []byte) (total, available int64) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available = kb * 1024
+		}
+	}
+	return total, available
+}
+
+// detectGPUs lists the NVIDIA GPUs nvidia-smi reports (none without the driver)
+func detectGPUs(ctx context.Context) []GPU {
+	if _, err := exec.LookPath(nvidiaSMI); err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, gpuQueryTimeout)
+	defer cancel()
+	// Drivers before 510 don't report the compute capability
+	out, err := exec.CommandContext(ctx, nvidiaSMI, "--query-gpu=name,memory.total,compute_cap", "--format=csv,noheader,nounits").Output()
+	if err != nil {
+		out, err = exec.CommandContext(ctx, nvidiaSMI, "--query-gpu=name,memory.total", "--format=csv,noheader,nounits").Output()
+	}
+	if err != nil {
+		return nil
+	}
+	return parseNvidiaSMI(out)
+}
+
+// parseNvidiaSMI parses "name, memory MiB[, compute capability]" lines of nvidia-smi
+// --query-gpu output
+func parseNvidiaSMI(out []byte) []GPU {
+	var gpus []GPU
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) < 2 || strings.TrimSpace(fields[0]) == "" {
+			continue
+		}
+		gpu := GPU{Name: strings.TrimSpace(fields[0]), Accelerator: types.AcceleratorCUDA}
+		if mib, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64); err == nil {
+			gpu.VRAMBytes = mib << 20
+		}
+		if len(fields) > 2 {
+			if capability := strings.TrimSpace(fields[2]); capability != "[N/A]" {
+				gpu.ComputeCapability = capability
+			}
+		}
+		gpus = append(gpus, gpu)
+	}
+	return gpus
+}
@@ -0,0 +1,121 @@
+package host
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	total, available := parseMeminfo([]byte("MemTotal:       16384000 kB\nMemFree:         1000000 kB\nMemAvailable:    8192000 kB\n"))
+	if total != 16384000*1024 || available != 8192000*1024 {
+		t.Errorf("parseMeminfo() = %d, %d", total, available)
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	got := parseNvidiaSMI([]byte("NVIDIA A100-SXM4-40GB, 40960\nNVIDIA T4, [N/A]\n"))
+	want := []GPU{{Name: "NVIDIA A100-SXM4-40GB", VRAMBytes: 40960 << 20, Accelerator: "cuda"}, {Name: "NVIDIA T4", Accelerator: "cuda"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNvidiaSMI() = %+v, want %+v", got, want)
+	}
+
+	// Newer drivers also report the compute capability
+	got = parseNvidiaSMI([]byte("NVIDIA L4, 23034, 8.9\nTesla K80, 11441, [N/A]\n"))
+	want = []GPU{{Name: "NVIDIA L4", VRAMBytes: 23034 << 20, Accelerator: "cuda", ComputeCapability: "8.9"}, {Name: "Tesla K80", VRAMBytes: 11441 << 20, Accelerator: "cuda"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNvidiaSMI() with compute capabilities = %+v, want %+v", got, want)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	originalSMI := nvidiaSMI
+	defer func() { nvidiaSMI = originalSMI }()
+	nvidiaSMI = "axon-test-no-such-nvidia-smi"
+
+	info := Detect(context.Background(), t.TempDir())
+	if info.CPUs < 1 {
+		t.Errorf("CPUs = %d", info.CPUs)
+	}
+}
+
+func TestCheckRequirements(t *testing.T) {
+	const gb = 1 << 30
+	requirements := types.Requirements{
+		Compute: types.Compute{
+			CPU:    types.CPURequirement{MinCores: 4, RecommendedCores: 8},
+			Memory: types.MemoryRequirement{MinGB: 8, RecommendedGB: 16},
+			GPU:    &types.GPURequirement{Recommended: true, MinVRAMGB: 16},
+		},
+		Storage: types.Storage{MinGB: 10, RecommendedGB: 20},
+	}
+
+	tests := []struct {
+		name      string
+		host      Info
+		installed bool
+		want      map[string]string
+	}{
+		{
+			name: "workstation",
+			host: Info{CPUs: 16, MemoryBytes: 64 * gb, AvailableMemoryBytes: 48 * gb, DiskFreeBytes: 500 * gb, GPUs: []GPU{{Name: "T4", VRAMBytes: 15 * gb}, {Name: "A100", VRAMBytes: 40 * gb}}},
+			want: map[string]string{"cpu": StatusPass, "memory": StatusPass, "gpu": StatusPass, "disk": StatusPass},
+		},
+		{
+			name: "laptop",
+			host: Info{CPUs: 4, MemoryBytes: 12 * gb, DiskFreeBytes: 15 * gb},
+			want: map[string]string{"cpu": StatusWarn, "memory": StatusWarn, "gpu": StatusWarn, "disk": StatusWarn},
+		},
+		{
+			name:      "busy small VM, model installed",
+			host:      Info{CPUs: 2, MemoryBytes: 16 * gb, AvailableMemoryBytes: 2 * gb},
+			installed: true,
+			want:      map[string]string{"cpu": StatusFail, "memory": StatusWarn, "gpu": StatusWarn},
+		},
+		{
+			name: "undetected memory and disk",
+			host: Info{CPUs: 8},
+			want: map[string]string{"cpu": StatusPass, "memory": StatusWarn, "gpu": StatusWarn, "disk": StatusWarn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]string)
+			for _, check := range tt.host.CheckRequirements(requirements, tt.installed) {
+				got[check.Resource] = check.Status
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckRequirements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// A required GPU fails without one
+	required := requirements
+	required.Compute.GPU = &types.GPURequirement{Required: true}
+	checks := Info{CPUs: 8, MemoryBytes: 32 * gb, DiskFreeBytes: 100 * gb}.CheckRequirements(required, false)
+	if Worst(checks) != StatusFail {
+		t.Errorf("Worst() = %s without a required GPU, want fail", Worst(checks))
+	}
+
+	// GPUs of other platforms, or below the compute capability, don't meet the requirement
+	fp8 := &types.GPURequirement{Required: true, MinComputeCapability: "8.9", Accelerators: []string{types.AcceleratorCUDA}}
+	gpus := []struct {
+		name string
+		gpus []GPU
+		want string
+	}{
+		{"ada", []GPU{{Name: "L4", Accelerator: "cuda", ComputeCapability: "8.9"}}, StatusPass},
+		{"ampere", []GPU{{Name: "A100", Accelerator: "cuda", ComputeCapability: "8.0"}}, StatusFail},
+		{"capability unknown", []GPU{{Name: "A100", Accelerator: "cuda"}}, StatusPass},
+		{"apple silicon", []GPU{{Name: "Apple silicon", Accelerator: "metal"}}, StatusFail},
+	}
+	for _, tt := range gpus {
+		if got := (Info{GPUs: tt.gpus}).checkGPU(fp8); got.Status != tt.want {
+			t.Errorf("checkGPU() on %s = %+v, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package journal records multi-step cache operations (install, uninstall) while they run,
+// so one interrupted by a crash, a kill or a full disk can be finished or rolled back
+// instead of leaving a model that looks installed but isn't.
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DirName is the journal directory in the Axon home directory. Each running operation
+// has one file in it, removed when the operation finishes.
+const DirName = "journal"
+
+// Operations recorded in the journal
+const (
+	OpInstall   = "install"
+	OpUninstall = "uninstall"
+)
+
+// Entry is the journal record of one operation
+type Entry struct {
+	ID        string    `json:"id"`
+	Operation string    `json:"operation"`
+	Model     string    `json:"model"`          // namespace/name@version
+	Path      string    `json:"path,omitempty"` // The model's cache directory
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Steps     []string  `json:"steps,omitempty"`     // Completed steps, in order
+	Artifacts []string  `json:"artifacts,omitempty"` // Files and directories the operation creates, removed by a rollback
+
+	file string
+}
+
+// seq tells apart the entries of operations a process starts in the same second
+var seq atomic.Int64
+
+// Begin records the start of an operation on model in the journal directory dir
+func Begin(dir, operation, model, path string) (*Entry, error) {
+	now := time.Now().UTC()
+	host, _ := os.Hostname()
+	e := &Entry{
+		ID:        fmt.Sprintf("%s-%d-%d", now.Format("20060102T150405"), os.Getpid(), seq.Add(1)),
+		Operation: operation,
+		Model:     model,
+		Path:      path,
+		PID:       os.Getpid(),
+		Host:      host,
+		StartedAt: now,
+	}
+	e.file = filepath.Join(dir, e.ID+".json")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	if err := e.save(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Track records files and directories the operation is about to create, before creating
+// them, so a rollback removes them even if the operation dies halfway through writing them.
+// A nil entry records nothing, so operations run even if the journal can't be written.
+func (e *Entry) Track(paths ...string) error {
+	if e == nil {
+		return nil
+	}
+	e.Artifacts = append(e.Artifacts, paths...)
+	return e.save()
+}
+
+// Step records that a step of the operation completed
+func (e *Entry) Step(step string) error {
+	if e == nil {
+		return nil
+	}
+	e.Steps = append(e.Steps, step)
+	return e.save()
+}
+
+// Done reports whether step completed
+func (e *Entry) Done(step string) bool {
+	if e == nil {
+		return false
+	}
+	for _, s := range e.Steps {
+		if s == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Finish removes the entry from the journal once the operation finished or was rolled back
+func (e *Entry) Finish() error {
+	if e == nil {
+		return nil
+	}
+	if err := os.Remove(e.file); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove journal entry: %w", err)
+	}
+	return nil
+}
+
+// RemoveArtifacts removes what the operation created, newest first
+func (e *Entry) RemoveArtifacts() error {
+	if e == nil {
+		return nil
+	}
+	for i := len(e.Artifacts) - 1; i >= 0; i-- {
+		if err := os.RemoveAll(e.Artifacts[i]); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", e.Artifacts[i], err)
+		}
+	}
+	return nil
+}
+
+// Interrupted reports whether the operation's process is gone. Operations started on
+// another host (a shared home directory) can't be checked and count as running.
+func (e *Entry) Interrupted() bool {
+	if host, _ := os.Hostname(); e.Host != host {
+		return false
+	}
+	return !processAlive(e.PID)
+}
+
+// save writes the entry atomically, so a crash leaves the previous state rather than
+// half a record
+func (e *Entry) save() error {
+	e.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal entry: %w", err)
+	}
+	tmp := e.file + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	if err := os.Rename(tmp, e.file); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// List returns the entries in the journal directory dir, oldest first. A missing
+// directory has no entries; unreadable entries are skipped.
+func List(dir string) ([]*Entry, error) {
+	files, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+
+	var entries []*Entry
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		e := &Entry{}
+		if err := json.Unmarshal(data, e); err != nil {
+			continue
+		}
+		e.file = path
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].StartedAt.Before(entries[j].StartedAt) })
+	return entries, nil
+}
@@ -0,0 +1,95 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), DirName)
+	work := t.TempDir()
+
+	e, err := Begin(dir, OpInstall, "hf/bert@latest", filepath.Join(work, "bert"))
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	artifacts := []string{filepath.Join(work, "bert.axon.tmp"), filepath.Join(work, "bert")}
+	if err := e.Track(artifacts...); err != nil {
+		t.Fatalf("Track() error = %v", err)
+	}
+	if err := os.WriteFile(artifacts[0], []byte("partial download"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(artifacts[1], "weights"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Step("downloaded"); err != nil {
+		t.Fatalf("Step() error = %v", err)
+	}
+	if !e.Done("downloaded") || e.Done("extracted") {
+		t.Errorf("Done() with steps %v", e.Steps)
+	}
+
+	second, err := Begin(dir, OpUninstall, "hf/gpt2@latest", filepath.Join(work, "gpt2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID == e.ID {
+		t.Errorf("operations started together share ID %s", e.ID)
+	}
+
+	entries, err := List(dir)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != e.ID || entries[1].ID != second.ID {
+		t.Fatalf("List() = %+v, want both entries oldest first", entries)
+	}
+	got := entries[0]
+	if got.Model != "hf/bert@latest" || got.PID != os.Getpid() || len(got.Artifacts) != 2 || !got.Done("downloaded") {
+		t.Errorf("listed entry = %+v", got)
+	}
+	// This process is running
+	if got.Interrupted() {
+		t.Error("Interrupted() = true for an entry of this process")
+	}
+
+	if err := got.RemoveArtifacts(); err != nil {
+		t.Fatalf("RemoveArtifacts() error = %v", err)
+	}
+	for _, path := range artifacts {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s not removed: %v", path, err)
+		}
+	}
+	if err := got.Finish(); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	if entries, _ := List(dir); len(entries) != 1 || entries[0].ID != second.ID {
+		t.Errorf("List() after Finish() = %+v, want the uninstall only", entries)
+	}
+}
+
+func TestNilEntry(t *testing.T) {
+	var e *Entry
+	if err := e.Track("/nonexistent"); err != nil {
+		t.Errorf("Track() error = %v", err)
+	}
+	if err := e.Step("downloaded"); err != nil || e.Done("downloaded") {
+		t.Errorf("Step() = %v, Done() = %t", err, e.Done("downloaded"))
+	}
+	if err := e.RemoveArtifacts(); err != nil {
+		t.Errorf("RemoveArtifacts() error = %v", err)
+	}
+	if err := e.Finish(); err != nil {
+		t.Errorf("Finish() error = %v", err)
+	}
+}
+
+func TestList_Missing(t *testing.T) {
+	entries, err := List(filepath.Join(t.TempDir(), DirName))
+	if err != nil || len(entries) != 0 {
+		t.Errorf("List() of a missing journal = %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package journal
+
+import "os"
+
+// processAlive reports whether a process with pid is running; on Windows finding a
+// process opens it, which fails once it has exited
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = p.Release()
+	return true
+}
@@ -0,0 +1,251 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// fieldSetter validates a value and stores it in a manifest
+type fieldSetter func(m *types.Manifest, value string) error
+
+// tagPattern matches a single tag: no whitespace or commas (e.g. "text-classification", "license:mit")
+var tagPattern = regexp.MustCompile(`^[^\s,]+$`)
+
+// taskPattern matches task names, which are Hugging Face pipeline tags
+var taskPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// computeCapabilityPattern matches CUDA compute capabilities (e.g. "8.0")
+var computeCapabilityPattern = regexp.MustCompile(`^[0-9]+\.[0-9]+$`)
+
+// acceleratorPattern matches the GPU platforms of GPU requirements (e.g. "cuda", "rocm")
+var acceleratorPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// editableFields are the manifest fields that may be edited in place, by the path used
+// on the command line. Identity, files and distribution are not editable: they describe
+// the package contents.
+var editableFields = map[string]fieldSetter{
+	"description": func(m *types.Manifest, v string) error {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("description cannot be empty")
+		}
+		m.Metadata.Description = v
+		return nil
+	},
+	"license": func(m *types.Manifest, v string) error {
+		if strings.TrimSpace(v) == "" {
+			return fmt.Errorf("license cannot be empty")
+		}
+		m.Metadata.License = v
+		return nil
+	},
+	"homepage":      func(m *types.Manifest, v string) error { m.Metadata.Homepage = v; return nil },
+	"documentation": func(m *types.Manifest, v string) error { m.Metadata.Documentation = v; return nil },
+	"tags": func(m *types.Manifest, v string) error {
+		var tags []string
+		seen := make(map[string]bool)
+		for _, tag := range strings.Split(v, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			if !tagPattern.MatchString(tag) {
+				return fmt.Errorf("invalid tag %q", tag)
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+		m.Metadata.Tags = tags
+		return nil
+	},
+	"requirements.cpu.min_cores": func(m *types.Manifest, v string) error {
+		return setPositiveInt(&m.Spec.Requirements.Compute.CPU.MinCores, v)
+	},
+	"requirements.cpu.recommended_cores": func(m *types.Manifest, v string) error {
+		return setPositiveInt(&m.Spec.Requirements.Compute.CPU.RecommendedCores, v)
+	},
+	"requirements.memory.min_gb": func(m *types.Manifest, v string) error {
+		return setPositiveFloat(&m.Spec.Requirements.Compute.Memory.MinGB, v)
+	},
+	"requirements.memory.recommended_gb": func(m *types.Manifest, v string) error {
+		return setPositiveFloat(&m.Spec.Requirements.Compute.Memory.RecommendedGB, v)
+	},
+	"requirements.storage.min_gb": func(m *types.Manifest, v string) error {
+		return setPositiveFloat(&m.Spec.Requirements.Storage.MinGB, v)
+	},
+	"requirements.storage.recommended_gb": func(m *types.Manifest, v string) error {
+		return setPositiveFloat(&m.Spec.Requirements.Storage.RecommendedGB, v)
+	},
+	"requirements.gpu.required": func(m *types.Manifest, v string) error {
+		return setBool(&gpuRequirement(m).Required, v)
+	},
+	"requirements.gpu.recommended": func(m *types.Manifest, v string) error {
+		return setBool(&gpuRequirement(m).Recommended, v)
+	},
+	"requirements.gpu.min_vram_gb": func(m *types.Manifest, v string) error {
+		return setPositiveFloat(&gpuRequirement(m).MinVRAMGB, v)
+	},
+	"requirements.gpu.cuda_version": func(m *types.Manifest, v string) error {
+		gpuRequirement(m).CUDAVersion = v
+		return nil
+	},
+	"requirements.gpu.rocm_version": func(m *types.Manifest, v string) error {
+		gpuRequirement(m).ROCmVersion = v
+		return nil
+	},
+	"requirements.gpu.min_compute_capability": func(m *types.Manifest, v string) error {
+		if v != "" && !computeCapabilityPattern.MatchString(v) {
+			return fmt.Errorf("invalid compute capability %q (expected major.minor, e.g. 8.0)", v)
+		}
+		gpuRequirement(m).MinComputeCapability = v
+		return nil
+	},
+	"requirements.gpu.accelerators": func(m *types.Manifest, v string) error {
+		var accelerators []string
+		for _, accelerator := range strings.Split(v, ",") {
+			accelerator = strings.ToLower(strings.TrimSpace(accelerator))
+			if accelerator == "" || slices.Contains(accelerators, accelerator) {
+				continue
+			}
+			if !acceleratorPattern.MatchString(accelerator) {
+				return fmt.Errorf("invalid accelerator %q (e.g. cuda, rocm, metal)", accelerator)
+			}
+			accelerators = append(accelerators, accelerator)
+		}
+		gpuRequirement(m).Accelerators = accelerators
+		return nil
+	},
+	"task": func(m *types.Manifest, v string) error {
+		if v != "" && !taskPattern.MatchString(v) {
+			return fmt.Errorf("invalid task %q (expected a pipeline tag such as image-classification)", v)
+		}
+		m.Spec.Task = v
+		return nil
+	},
+}
+
+// EditableFields returns the fields accepted by Set, sorted
+func EditableFields() []string {
+	fields := make([]string, 0, len(editableFields))
+	for field := range editableFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Set validates value and stores it in the named field of m
+func Set(m *types.Manifest, field, value string) error {
+	setter, ok := editableFields[field]
+	if !ok {
+		return fmt.Errorf("field %q cannot be edited (editable: %s)", field, strings.Join(EditableFields(), ", "))
+	}
+	if err := setter(m, value); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// Edit applies field=value assignments to a copy of m, checks the result and bumps the
+// metadata revision. It returns the edited manifest; m itself is not modified.
+func Edit(m *types.Manifest, assignments []string, now time.Time) (*types.Manifest, error) {
+	if len(assignments) == 0 {
+		return nil, fmt.Errorf("nothing to set: expected field=value")
+	}
+	edited, err := Clone(m)
+	if err != nil {
+		return nil, err
+	}
+	for _, assignment := range assignments {
+		field, value, ok := strings.Cut(assignment, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid assignment %q: expected field=value", assignment)
+		}
+		if err := Set(edited, strings.TrimSpace(field), value); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateEdit(m, edited); err != nil {
+		return nil, err
+	}
+	edited.Metadata.Revision++
+	edited.Metadata.Updated = now.UTC()
+	return edited, nil
+}
+
+// ValidateEdit checks an edited manifest against its original: recommended requirements
+// can't be below the minimums, and a manifest that passed Validate must still pass.
+// Manifests generated on install (e.g. with version "latest") never passed Validate,
+// so only the edited values are checked for them.
+func ValidateEdit(before, after *types.Manifest) error {
+	compute := after.Spec.Requirements.Compute
+	if compute.CPU.RecommendedCores > 0 && compute.CPU.RecommendedCores < compute.CPU.MinCores {
+		return fmt.Errorf("requirements.cpu.recommended_cores (%d) is below min_cores (%d)", compute.CPU.RecommendedCores, compute.CPU.MinCores)
+	}
+	if compute.Memory.RecommendedGB > 0 && compute.Memory.RecommendedGB < compute.Memory.MinGB {
+		return fmt.Errorf("requirements.memory.recommended_gb (%g) is below min_gb (%g)", compute.Memory.RecommendedGB, compute.Memory.MinGB)
+	}
+	storage := after.Spec.Requirements.Storage
+	if storage.RecommendedGB > 0 && storage.RecommendedGB < storage.MinGB {
+		return fmt.Errorf("requirements.storage.recommended_gb (%g) is below min_gb (%g)", storage.RecommendedGB, storage.MinGB)
+	}
+
+	if Validate(before) == nil {
+		if err := Validate(after); err != nil {
+			return fmt.Errorf("edited manifest is no longer valid: %w", err)
+		}
+	}
+	return nil
+}
+
+// Clone returns a deep copy of m
+func Clone(m *types.Manifest) (*types.Manifest, error) {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy manifest: %w", err)
+	}
+	return ParseBytes(data)
+}
+
+// gpuRequirement returns the GPU requirement of m, creating it if needed
+func gpuRequirement(m *types.Manifest) *types.GPURequirement {
+	if m.Spec.Requirements.Compute.GPU == nil {
+		m.Spec.Requirements.Compute.GPU = &types.GPURequirement{}
+	}
+	return m.Spec.Requirements.Compute.GPU
+}
+
+func setPositiveInt(dst *int, v string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || n <= 0 {
+		return fmt.Errorf("expected a positive integer, got %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func setPositiveFloat(dst *float64, v string) error {
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil || f <= 0 {
+		return fmt.Errorf("expected a positive number, got %q", v)
+	}
+	*dst = f
+	return nil
+}
+
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(v))
+	if err != nil {
+		return fmt.Errorf("expected true or false, got %q", v)
+	}
+	*dst = b
+	return nil
+}
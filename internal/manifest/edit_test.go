@@ -0,0 +1,140 @@
+package manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestEdit(t *testing.T) {
+	base := func() *types.Manifest {
+		m := &types.Manifest{}
+		m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version = "hf", "bert", "latest"
+		m.Metadata.Description = "BERT"
+		m.Metadata.Tags = []string{"nlp"}
+		m.Spec.Requirements.Compute.CPU = types.CPURequirement{MinCores: 2, RecommendedCores: 4}
+		m.Spec.Requirements.Compute.Memory = types.MemoryRequirement{MinGB: 2, RecommendedGB: 4}
+		return m
+	}
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		assignments []string
+		check       func(t *testing.T, m *types.Manifest)
+		wantErr     string
+	}{
+		{
+			name:        "description and tags",
+			assignments: []string{"description=BERT base, uncased", "tags=nlp, fill-mask,nlp,license:apache-2.0"},
+			check: func(t *testing.T, m *types.Manifest) {
+				if m.Metadata.Description != "BERT base, uncased" {
+					t.Errorf("Description = %q", m.Metadata.Description)
+				}
+				if want := []string{"nlp", "fill-mask", "license:apache-2.0"}; !reflect.DeepEqual(m.Metadata.Tags, want) {
+					t.Errorf("Tags = %v, want %v", m.Metadata.Tags, want)
+				}
+			},
+		},
+		{
+			name: "requirements",
+			assignments: []string{"requirements.memory.recommended_gb=16", "requirements.gpu.required=true", "requirements.gpu.min_vram_gb=8",
+				"requirements.gpu.min_compute_capability=8.0", "requirements.gpu.accelerators=CUDA, rocm,cuda"},
+			check: func(t *testing.T, m *types.Manifest) {
+				if m.Spec.Requirements.Compute.Memory.RecommendedGB != 16 {
+					t.Errorf("RecommendedGB = %v", m.Spec.Requirements.Compute.Memory.RecommendedGB)
+				}
+				if gpu := m.Spec.Requirements.Compute.GPU; gpu == nil || !gpu.Required || gpu.MinVRAMGB != 8 || gpu.MinComputeCapability != "8.0" {
+					t.Errorf("GPU = %+v", gpu)
+				}
+				if want := []string{"cuda", "rocm"}; !reflect.DeepEqual(m.Spec.Requirements.Compute.GPU.Accelerators, want) {
+					t.Errorf("Accelerators = %v, want %v", m.Spec.Requirements.Compute.GPU.Accelerators, want)
+				}
+			},
+		},
+		{
+			name:        "task",
+			assignments: []string{"task=image-classification"},
+			check: func(t *testing.T, m *types.Manifest) {
+				if m.Spec.Task != "image-classification" {
+					t.Errorf("Task = %q", m.Spec.Task)
+				}
+			},
+		},
+		{name: "nothing to set", wantErr: "nothing to set"},
+		{name: "not an assignment", assignments: []string{"description"}, wantErr: "expected field=value"},
+		{name: "identity is not editable", assignments: []string{"version=2.0.0"}, wantErr: "cannot be edited"},
+		{name: "empty description", assignments: []string{"description= "}, wantErr: "cannot be empty"},
+		{name: "bad number", assignments: []string{"requirements.cpu.min_cores=two"}, wantErr: "positive integer"},
+		{name: "negative memory", assignments: []string{"requirements.memory.min_gb=-1"}, wantErr: "positive number"},
+		{name: "bad tag", assignments: []string{"tags=fill mask"}, wantErr: "invalid tag"},
+		{name: "bad task", assignments: []string{"task=Image Classification"}, wantErr: "invalid task"},
+		{name: "bad compute capability", assignments: []string{"requirements.gpu.min_compute_capability=ampere"}, wantErr: "invalid compute capability"},
+		{name: "bad accelerator", assignments: []string{"requirements.gpu.accelerators=cuda 12"}, wantErr: "invalid accelerator"},
+		{name: "recommended below minimum", assignments: []string{"requirements.cpu.min_cores=8"}, wantErr: "below min_cores"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := base()
+			edited, err := Edit(original, tt.assignments, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Edit() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Edit() error = %v", err)
+			}
+			if edited.Metadata.Revision != 1 || !edited.Metadata.Updated.Equal(now) {
+				t.Errorf("revision %d updated %v, want revision 1 updated %v", edited.Metadata.Revision, edited.Metadata.Updated, now)
+			}
+			if !reflect.DeepEqual(original, base()) {
+				t.Error("Edit() modified the original manifest")
+			}
+			tt.check(t, edited)
+		})
+	}
+}
+
+func TestValidateEdit_KeepsValidManifestsValid(t *testing.T) {
+	valid := &types.Manifest{
+		APIVersion: "axon.mlos.io/v1",
+		Kind:       "Model",
+		Metadata:   types.Metadata{Name: "bert", Namespace: "nlp", Version: "1.0.0", Description: "BERT", License: "Apache-2.0"},
+		Spec: types.Spec{
+			Framework: types.Framework{Name: "pytorch", Version: "2.0.0"},
+			Format: types.Format{Type: "checkpoint", Files: []types.ModelFile{
+				{Path: "model.pth", Size: 1024, SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+			}},
+			IO: types.IO{
+				Inputs:  []types.IOSpec{{Name: "input", DType: "float32"}},
+				Outputs: []types.IOSpec{{Name: "output", DType: "float32"}},
+			},
+			Requirements: types.Requirements{Compute: types.Compute{
+				CPU:    types.CPURequirement{MinCores: 2},
+				Memory: types.MemoryRequirement{MinGB: 4},
+			}},
+		},
+		Distribution: types.Distribution{
+			Package:  types.PackageInfo{URL: "https://example.com/bert.axon", Size: 1024, SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+			Registry: types.RegistryInfo{URL: "https://example.com", Namespace: "nlp"},
+		},
+	}
+	if err := Validate(valid); err != nil {
+		t.Fatalf("fixture is invalid: %v", err)
+	}
+
+	edited, err := Clone(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited.Metadata.License = ""
+	if err := ValidateEdit(valid, edited); err == nil {
+		t.Error("ValidateEdit() accepted an edit that invalidates the manifest")
+	}
+}
@@ -2,6 +2,7 @@
 package manifest
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
@@ -48,3 +49,16 @@ func Write(manifest *types.Manifest, filePath string) error {
 
 	return nil
 }
+
+// ToJSON returns a manifest as JSON with the field names of manifest.yaml
+func ToJSON(manifest *types.Manifest) (json.RawMessage, error) {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	return json.Marshal(doc)
+}
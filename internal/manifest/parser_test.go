@@ -0,0 +1,40 @@
+package manifest
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestToJSON(t *testing.T) {
+	m := &types.Manifest{
+		APIVersion: "axon.mlos.io/v1",
+		Metadata:   types.Metadata{Namespace: "hf", Name: "bert", Version: "1.0.0", Created: time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	m.Spec.Format.ExecutionFormat = "onnx"
+
+	data, err := ToJSON(m)
+	if err != nil {
+		t.Fatalf("ToJSON() error = %v", err)
+	}
+	var doc struct {
+		APIVersion string `json:"apiVersion"`
+		Metadata   struct {
+			Name    string `json:"name"`
+			Created string `json:"created"`
+		} `json:"metadata"`
+		Spec struct {
+			Format struct {
+				ExecutionFormat string `json:"execution_format"`
+			} `json:"format"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.APIVersion != m.APIVersion || doc.Metadata.Name != "bert" || doc.Metadata.Created != "2026-05-01T12:00:00Z" || doc.Spec.Format.ExecutionFormat != "onnx" {
+		t.Errorf("ToJSON() = %s, want the field names of manifest.yaml", data)
+	}
+}
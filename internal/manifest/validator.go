@@ -170,6 +170,17 @@ func validateSpec(spec types.Spec) error {
 		return fmt.Errorf("requirements.compute.memory.min_gb must be positive")
 	}
 
+	if gpu := spec.Requirements.Compute.GPU; gpu != nil {
+		if gpu.MinComputeCapability != "" && !computeCapabilityPattern.MatchString(gpu.MinComputeCapability) {
+			return fmt.Errorf("requirements.compute.gpu.min_compute_capability must be major.minor (e.g. 8.0)")
+		}
+		for _, accelerator := range gpu.Accelerators {
+			if !acceleratorPattern.MatchString(accelerator) {
+				return fmt.Errorf("requirements.compute.gpu.accelerators has invalid accelerator %q", accelerator)
+			}
+		}
+	}
+
 	return nil
 }
 
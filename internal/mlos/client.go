@@ -0,0 +1,302 @@
+// Package mlos provides an HTTP client for the MLOS Core API.
+// Axon uses it to register installed models and to query what the running
+// Core instance is able to execute.
+package mlos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the MLOS Core endpoint used when MLOS_CORE_ENDPOINT is not set
+const DefaultEndpoint = "http://localhost:8080"
+
+// ErrCapabilitiesUnsupported is returned when Core does not expose a capabilities API
+// (older Core releases). Callers should skip negotiation rather than fail.
+var ErrCapabilitiesUnsupported = errors.New("MLOS Core does not report capabilities")
+
+// ErrUsageUnsupported is returned when Core does not expose model usage statistics
+var ErrUsageUnsupported = errors.New("MLOS Core does not report model usage")
+
+// Client is an HTTP client for MLOS Core
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+// NewClient creates a new MLOS Core client for the given endpoint
+func NewClient(endpoint string) *Client {
+	return &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// EndpointFromEnv returns the MLOS Core endpoint from MLOS_CORE_ENDPOINT, or DefaultEndpoint
+func EndpointFromEnv() string {
+	if endpoint := os.Getenv("MLOS_CORE_ENDPOINT"); endpoint != "" {
+		return endpoint
+	}
+	return DefaultEndpoint
+}
+
+// Endpoint returns the base URL of the Core API
+func (c *Client) Endpoint() string {
+	return c.endpoint
+}
+
+// Capabilities describes what a running MLOS Core instance can execute
+type Capabilities struct {
+	// Version of the MLOS Core release (empty for older releases)
+	Version string `json:"version,omitempty"`
+
+	// RuntimePlugins lists the execution formats with a loaded runtime plugin (e.g., "onnx", "gguf")
+	RuntimePlugins []string `json:"runtime_plugins"`
+
+	// ExecutionFormats lists the execution formats the loaded plugins accept, when they
+	// go beyond the plugin names (e.g., a libtorch plugin running "pytorch" and
+	// "torchscript"). Older releases don't report it.
+	ExecutionFormats []string `json:"execution_formats,omitempty"`
+}
+
+// Formats returns the execution formats Core can execute: its execution formats and
+// runtime plugins, without duplicates
+func (c *Capabilities) Formats() []string {
+	var formats []string
+	seen := make(map[string]bool)
+	for _, format := range append(append([]string{}, c.ExecutionFormats...), c.RuntimePlugins...) {
+		if key := strings.ToLower(format); !seen[key] {
+			seen[key] = true
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// Supports reports whether Core can execute the given execution format
+func (c *Capabilities) Supports(format string) bool {
+	for _, supported := range c.Formats() {
+		if strings.EqualFold(supported, format) {
+			return true
+		}
+	}
+	return false
+}
+
+// Capabilities queries Core for its version, loaded runtime plugins and execution formats
+func (c *Client) Capabilities(ctx context.Context) (*Capabilities, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+"/capabilities", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MLOS Core at %s: %w", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrCapabilitiesUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from MLOS Core: %d", resp.StatusCode)
+	}
+
+	var caps Capabilities
+	if err := json.NewDecoder(resp.Body).Decode(&caps); err != nil {
+		return nil, fmt.Errorf("failed to decode capabilities: %w", err)
+	}
+
+	return &caps, nil
+}
+
+// RegisteredModel describes a model already registered with Core
+type RegisteredModel struct {
+	ModelID      string `json:"model_id"`
+	Version      string `json:"version,omitempty"`
+	Digest       string `json:"digest,omitempty"`
+	Path         string `json:"path,omitempty"`
+	RegisteredAt string `json:"registered_at,omitempty"`
+}
+
+// GetModel looks up a registered model by ID (e.g., "hf/bert-base-uncased@latest").
+// It returns nil and no error if Core has no model with that ID.
+func (c *Client) GetModel(ctx context.Context, modelID string) (*RegisteredModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+"/models/"+url.PathEscape(modelID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MLOS Core at %s: %w", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from MLOS Core: %d", resp.StatusCode)
+	}
+
+	var model RegisteredModel
+	if err := json.NewDecoder(resp.Body).Decode(&model); err != nil {
+		return nil, fmt.Errorf("failed to decode registered model: %w", err)
+	}
+
+	return &model, nil
+}
+
+// ListModels returns every model registered with Core. Core may answer with a list or
+// with an object holding the list under "models".
+func (c *Client) ListModels(ctx context.Context) ([]RegisteredModel, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+"/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MLOS Core at %s: %w", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from MLOS Core: %d", resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode registered models: %w", err)
+	}
+	var models []RegisteredModel
+	if err := json.Unmarshal(raw, &models); err == nil {
+		return models, nil
+	}
+	var wrapped struct {
+		Models []RegisteredModel `json:"models"`
+	}
+	if err := json.Unmarshal(raw, &wrapped); err != nil {
+		return nil, fmt.Errorf("failed to decode registered models: %w", err)
+	}
+	return wrapped.Models, nil
+}
+
+// UnregisterModel removes a model from Core. Unregistering a model Core doesn't have
+// is not an error.
+func (c *Client) UnregisterModel(ctx context.Context, modelID string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", c.endpoint+"/models/"+url.PathEscape(modelID), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MLOS Core at %s: %w", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	}
+	return fmt.Errorf("unexpected status code from MLOS Core: %d", resp.StatusCode)
+}
+
+// ModelUsage is how often Core was asked to run a model. Core counts requests for
+// models it doesn't have too, which is what makes them worth prefetching.
+type ModelUsage struct {
+	ModelID       string `json:"model_id"`
+	Requests      int64  `json:"requests"`
+	LastRequested string `json:"last_requested,omitempty"`
+}
+
+// ModelUsage returns Core's per-model request counts
+func (c *Client) ModelUsage(ctx context.Context) ([]ModelUsage, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+"/stats/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MLOS Core at %s: %w", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrUsageUnsupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code from MLOS Core: %d", resp.StatusCode)
+	}
+
+	var usage struct {
+		Models []ModelUsage `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&usage); err != nil {
+		return nil, fmt.Errorf("failed to decode model usage: %w", err)
+	}
+	return usage.Models, nil
+}
+
+// Health checks that Core is up and serving its API
+func (c *Client) Health(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+"/health", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MLOS Core at %s: %w", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("MLOS Core is not healthy (status %d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// WaitHealthy polls Health every interval until Core is healthy or ctx is done
+func (c *Client) WaitHealthy(ctx context.Context, interval time.Duration) error {
+	for {
+		err := c.Health(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for MLOS Core: %w", err)
+		case <-time.After(interval):
+		}
+	}
+}
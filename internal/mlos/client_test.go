@@ -0,0 +1,193 @@
+package mlos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestClient_Capabilities(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/capabilities" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"runtime_plugins": ["onnx", "gguf"]}`))
+	}))
+	defer server.Close()
+
+	caps, err := NewClient(server.URL).Capabilities(context.Background())
+	if err != nil {
+		t.Fatalf("Capabilities() error = %v", err)
+	}
+
+	if !caps.Supports("onnx") || !caps.Supports("GGUF") {
+		t.Errorf("Supports() should report loaded plugins, got %v", caps.RuntimePlugins)
+	}
+	if caps.Supports("pytorch") {
+		t.Error("Supports(pytorch) = true, want false")
+	}
+}
+
+func TestCapabilities_Formats(t *testing.T) {
+	caps := &Capabilities{
+		Version:          "1.6.0",
+		RuntimePlugins:   []string{"onnx", "libtorch"},
+		ExecutionFormats: []string{"ONNX", "pytorch", "torchscript"},
+	}
+	if got := caps.Formats(); !reflect.DeepEqual(got, []string{"ONNX", "pytorch", "torchscript", "libtorch"}) {
+		t.Errorf("Formats() = %v", got)
+	}
+	if !caps.Supports("pytorch") || !caps.Supports("onnx") || caps.Supports("gguf") {
+		t.Errorf("Supports() should report the execution formats and plugins of %+v", caps)
+	}
+}
+
+func TestClient_Capabilities_Unsupported(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := NewClient(server.URL).Capabilities(context.Background())
+	if !errors.Is(err, ErrCapabilitiesUnsupported) {
+		t.Errorf("Capabilities() error = %v, want ErrCapabilitiesUnsupported", err)
+	}
+}
+
+func TestClient_GetModel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/models/hf%2Fbert@latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"model_id": "hf/bert@latest", "digest": "sha256:abc"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	model, err := client.GetModel(context.Background(), "hf/bert@latest")
+	if err != nil {
+		t.Fatalf("GetModel() error = %v", err)
+	}
+	if model == nil || model.Digest != "sha256:abc" {
+		t.Errorf("GetModel() = %+v, want digest sha256:abc", model)
+	}
+
+	missing, err := client.GetModel(context.Background(), "hf/other@latest")
+	if err != nil {
+		t.Fatalf("GetModel() error = %v", err)
+	}
+	if missing != nil {
+		t.Errorf("GetModel() = %+v, want nil for unregistered model", missing)
+	}
+}
+
+func TestClient_ListModels(t *testing.T) {
+	for _, body := range []string{
+		`[{"model_id": "hf/bert@latest", "path": "/models/bert"}]`,
+		`{"models": [{"model_id": "hf/bert@latest", "path": "/models/bert"}]}`,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/models" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(body))
+		}))
+
+		models, err := NewClient(server.URL).ListModels(context.Background())
+		server.Close()
+		if err != nil {
+			t.Fatalf("ListModels() error = %v for %s", err, body)
+		}
+		if len(models) != 1 || models[0].ModelID != "hf/bert@latest" || models[0].Path != "/models/bert" {
+			t.Errorf("ListModels() = %+v for %s", models, body)
+		}
+	}
+}
+
+func TestClient_UnregisterModel(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		deleted = append(deleted, r.URL.EscapedPath())
+		if r.URL.EscapedPath() == "/models/hf%2Fbroken@latest" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if err := client.UnregisterModel(context.Background(), "hf/bert@latest"); err != nil {
+		t.Errorf("UnregisterModel() error = %v", err)
+	}
+	if err := client.UnregisterModel(context.Background(), "hf/broken@latest"); err == nil {
+		t.Error("UnregisterModel() should fail when Core answers 500")
+	}
+	if len(deleted) != 2 || deleted[0] != "/models/hf%2Fbert@latest" {
+		t.Errorf("UnregisterModel() requests = %v", deleted)
+	}
+}
+
+func TestClient_WaitHealthy(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/health" || calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if err := client.WaitHealthy(context.Background(), time.Millisecond); err != nil {
+		t.Fatalf("WaitHealthy() error = %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("WaitHealthy() polled %d times, want 3", calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := NewClient("http://127.0.0.1:1").WaitHealthy(ctx, 5*time.Millisecond); err == nil {
+		t.Error("WaitHealthy() should time out when Core is unreachable")
+	}
+}
+
+func TestClient_ModelUsage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stats/models" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"models": [{"model_id": "hf/bert@latest", "requests": 42}]}`))
+	}))
+	defer server.Close()
+
+	usage, err := NewClient(server.URL).ModelUsage(context.Background())
+	if err != nil {
+		t.Fatalf("ModelUsage() error = %v", err)
+	}
+	if len(usage) != 1 || usage[0].ModelID != "hf/bert@latest" || usage[0].Requests != 42 {
+		t.Errorf("ModelUsage() = %+v", usage)
+	}
+
+	old := httptest.NewServer(http.NotFoundHandler())
+	defer old.Close()
+	if _, err := NewClient(old.URL).ModelUsage(context.Background()); !errors.Is(err, ErrUsageUnsupported) {
+		t.Errorf("ModelUsage() on old Core error = %v, want ErrUsageUnsupported", err)
+	}
+}
@@ -0,0 +1,210 @@
+package mlos
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+// TenantSeparator separates the tenant from the model ID in tenant-scoped Core model IDs
+// (team-a:hf/bert@latest)
+const TenantSeparator = ":"
+
+// executionFormatPattern matches the execution formats Core selects runtime plugins by
+var executionFormatPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// digestPattern matches the content digests Core compares registrations by
+var digestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+
+// RegisterSchemaVersion is the version of the registration request schema. Core uses it
+// to tell which fields a request can carry; requests without one predate versioning.
+// Version 2 adds the model's GPU requirements.
+const RegisterSchemaVersion = 2
+
+// RegisterRequest is the body of POST /models/register. Core reads the model's manifest
+// from ManifestPath; the other fields let it pick a runtime plugin (ExecutionFormat) and
+// detect conflicting re-registrations (Digest, Replace). Multi-encoder models (CLIP,
+// encoder-decoder) also name their onnx_manifest.json and the ONNX file of each component.
+type RegisterRequest struct {
+	SchemaVersion   int    `json:"schema_version"`
+	ModelID         string `json:"model_id"` // [tenant:]namespace/name@version
+	Tenant          string `json:"tenant"`   // Empty for the default scope
+	Name            string `json:"name"`
+	Framework       string `json:"framework"`
+	ExecutionFormat string `json:"execution_format"`
+	Task            string `json:"task,omitempty"` // e.g. "image-classification", for routing requests
+	Path            string `json:"path"`
+	Description     string `json:"description"`
+	ManifestPath    string `json:"manifest_path"`
+	Digest          string `json:"digest"`
+	Replace         bool   `json:"replace"`
+
+	MultiEncoder     string      `json:"multi_encoder,omitempty"`      // Architecture, e.g. "multi-encoder", "encoder-decoder"
+	ONNXManifestPath string      `json:"onnx_manifest_path,omitempty"` // onnx_manifest.json in path
+	Components       []Component `json:"components,omitempty"`
+
+	// The model's tensors, from the manifest's I/O schema
+	Inputs  []TensorSpec `json:"inputs,omitempty"`
+	Outputs []TensorSpec `json:"outputs,omitempty"`
+
+	// The GPU the model needs or benefits from, for scheduling it on a suitable device;
+	// nil for models that run on CPU
+	GPU *GPURequirement `json:"gpu,omitempty"`
+}
+
+// GPURequirement is the GPU a registered model needs (Required) or runs faster on
+// (Recommended)
+type GPURequirement struct {
+	Required             bool     `json:"required"`
+	Recommended          bool     `json:"recommended"`
+	MinVRAMGB            float64  `json:"min_vram_gb,omitempty"`
+	MinComputeCapability string   `json:"min_compute_capability,omitempty"` // CUDA, e.g. "8.0"
+	CUDAVersion          string   `json:"cuda_version,omitempty"`
+	ROCmVersion          string   `json:"rocm_version,omitempty"`
+	Accelerators         []string `json:"accelerators,omitempty"` // e.g. "cuda", "rocm", "metal"
+}
+
+// TensorSpec describes an input or output tensor of a model
+type TensorSpec struct {
+	Name  string `json:"name"`
+	DType string `json:"dtype"`
+	Shape []int  `json:"shape"` // -1 for dynamic dimensions
+}
+
+// Component is one ONNX file of a multi-encoder model
+type Component struct {
+	Role string `json:"role"` // e.g. "text_encoder", "vision_encoder", "encoder", "decoder"
+	Path string `json:"path"` // Relative to the request's path
+}
+
+// Validate checks the request against Core's registration schema, so a request Core
+// would reject is caught before it is sent. All problems are reported together.
+func (r *RegisterRequest) Validate() error {
+	var problems []error
+
+	if r.SchemaVersion != RegisterSchemaVersion {
+		problems = append(problems, fmt.Errorf("schema_version %d is not %d", r.SchemaVersion, RegisterSchemaVersion))
+	}
+
+	modelID := r.ModelID
+	if r.Tenant != "" {
+		var ok bool
+		if modelID, ok = strings.CutPrefix(modelID, r.Tenant+TenantSeparator); !ok {
+			problems = append(problems, fmt.Errorf("model_id %q is not scoped to tenant %q", r.ModelID, r.Tenant))
+		}
+	}
+	if ref, err := spec.Parse(modelID); err != nil || !ref.HasVersion || ref.String() != modelID {
+		problems = append(problems, fmt.Errorf("model_id %q is not namespace/name@version", r.ModelID))
+	}
+
+	if r.Name == "" {
+		problems = append(problems, errors.New("name is required"))
+	}
+	if !executionFormatPattern.MatchString(r.ExecutionFormat) {
+		problems = append(problems, fmt.Errorf("execution_format %q is not a runtime format (e.g. onnx, gguf)", r.ExecutionFormat))
+	}
+	if !filepath.IsAbs(r.Path) {
+		problems = append(problems, fmt.Errorf("path %q is not absolute", r.Path))
+	}
+	if r.ManifestPath != filepath.Join(r.Path, "manifest.yaml") {
+		problems = append(problems, fmt.Errorf("manifest_path %q is not manifest.yaml in path", r.ManifestPath))
+	}
+	if !digestPattern.MatchString(r.Digest) {
+		problems = append(problems, fmt.Errorf("digest %q is not sha256:<64 hex characters>", r.Digest))
+	}
+	if r.MultiEncoder != "" {
+		if r.ONNXManifestPath != filepath.Join(r.Path, model.ONNXManifestFileName) {
+			problems = append(problems, fmt.Errorf("onnx_manifest_path %q is not %s in path", r.ONNXManifestPath, model.ONNXManifestFileName))
+		}
+		if len(r.Components) < 2 {
+			problems = append(problems, fmt.Errorf("multi-encoder model has %d components, need at least 2", len(r.Components)))
+		}
+	}
+	for _, component := range r.Components {
+		if component.Role == "" {
+			problems = append(problems, fmt.Errorf("component %q has no role", component.Path))
+		}
+		if !filepath.IsLocal(filepath.FromSlash(component.Path)) {
+			problems = append(problems, fmt.Errorf("component path %q is not inside path", component.Path))
+		}
+	}
+	if r.GPU != nil {
+		if r.GPU.MinVRAMGB < 0 {
+			problems = append(problems, fmt.Errorf("gpu.min_vram_gb %g is negative", r.GPU.MinVRAMGB))
+		}
+		if r.GPU.MinComputeCapability != "" {
+			if _, _, err := model.ParseComputeCapability(r.GPU.MinComputeCapability); err != nil {
+				problems = append(problems, fmt.Errorf("gpu.min_compute_capability: %w", err))
+			}
+		}
+		for _, accelerator := range r.GPU.Accelerators {
+			if !executionFormatPattern.MatchString(accelerator) {
+				problems = append(problems, fmt.Errorf("gpu accelerator %q is not a platform name (e.g. cuda, rocm)", accelerator))
+			}
+		}
+	}
+	problems = append(problems, validateTensors("inputs", r.Inputs)...)
+	problems = append(problems, validateTensors("outputs", r.Outputs)...)
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid registration request: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+// validateTensors checks that the tensors in a list have distinct names
+func validateTensors(list string, tensors []TensorSpec) []error {
+	var problems []error
+	seen := make(map[string]bool)
+	for _, tensor := range tensors {
+		switch {
+		case tensor.Name == "":
+			problems = append(problems, fmt.Errorf("%s has a tensor without a name", list))
+		case seen[tensor.Name]:
+			problems = append(problems, fmt.Errorf("%s has tensor %q twice", list, tensor.Name))
+		}
+		seen[tensor.Name] = true
+	}
+	return problems
+}
+
+// RegisterModel validates the request and registers the model with Core
+func (c *Client) RegisterModel(ctx context.Context, request *RegisterRequest) error {
+	if err := request.Validate(); err != nil {
+		return err
+	}
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("failed to encode registration request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint+"/models/register", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to MLOS Core at %s: %w\nMake sure MLOS Core is running: mlos_core", c.endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("MLOS Core registration failed (status %d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package mlos
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func validRegisterRequest() RegisterRequest {
+	return RegisterRequest{
+		SchemaVersion:   RegisterSchemaVersion,
+		ModelID:         "hf/bert@latest",
+		Name:            "bert",
+		Framework:       "PyTorch",
+		ExecutionFormat: "onnx",
+		Path:            "/cache/models/hf/bert/latest",
+		Description:     `BERT "base", uncased`,
+		ManifestPath:    "/cache/models/hf/bert/latest/manifest.yaml",
+		Digest:          "sha256:" + strings.Repeat("ab", 32),
+		Inputs:          []TensorSpec{{Name: "input_ids", DType: "int64", Shape: []int{-1, -1}}},
+		Outputs:         []TensorSpec{{Name: "logits", DType: "float32", Shape: []int{-1, -1, 30522}}},
+	}
+}
+
+// multiEncoder makes r the registration of a CLIP model exported as two ONNX files
+func multiEncoder(r *RegisterRequest) {
+	r.MultiEncoder = "multi-encoder"
+	r.ONNXManifestPath = r.Path + "/onnx_manifest.json"
+	r.Components = []Component{{Role: "text_encoder", Path: "text_model.onnx"}, {Role: "vision_encoder", Path: "vision_model.onnx"}}
+}
+
+func TestRegisterRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *RegisterRequest)
+		want   string // Substring of the error; empty if valid
+	}{
+		{"valid", func(r *RegisterRequest) {}, ""},
+		{"tenant", func(r *RegisterRequest) { r.Tenant, r.ModelID = "team-a", "team-a:hf/bert@latest" }, ""},
+		{"tenant missing from model_id", func(r *RegisterRequest) { r.Tenant = "team-a" }, "not scoped to tenant"},
+		{"model_id without version", func(r *RegisterRequest) { r.ModelID = "hf/bert" }, "namespace/name@version"},
+		{"no name", func(r *RegisterRequest) { r.Name = "" }, "name is required"},
+		{"no execution format", func(r *RegisterRequest) { r.ExecutionFormat = "" }, "execution_format"},
+		{"relative path", func(r *RegisterRequest) { r.Path, r.ManifestPath = "models/bert", "models/bert/manifest.yaml" }, "not absolute"},
+		{"manifest elsewhere", func(r *RegisterRequest) { r.ManifestPath = "/tmp/manifest.yaml" }, "manifest_path"},
+		{"bad digest", func(r *RegisterRequest) { r.Digest = "abc" }, "digest"},
+		{"no schema version", func(r *RegisterRequest) { r.SchemaVersion = 0 }, "schema_version"},
+		{"unnamed input", func(r *RegisterRequest) { r.Inputs[0].Name = "" }, "inputs has a tensor without a name"},
+		{"duplicate output", func(r *RegisterRequest) { r.Outputs = append(r.Outputs, r.Outputs[0]) }, `outputs has tensor "logits" twice`},
+		{"multi-encoder", multiEncoder, ""},
+		{"multi-encoder without onnx manifest", func(r *RegisterRequest) { multiEncoder(r); r.ONNXManifestPath = "" }, "onnx_manifest_path"},
+		{"multi-encoder with one component", func(r *RegisterRequest) { multiEncoder(r); r.Components = r.Components[:1] }, "need at least 2"},
+		{"component outside path", func(r *RegisterRequest) { multiEncoder(r); r.Components[1].Path = "../other/model.onnx" }, "not inside path"},
+		{"component without role", func(r *RegisterRequest) { multiEncoder(r); r.Components[0].Role = "" }, "no role"},
+		{"gpu", func(r *RegisterRequest) {
+			r.GPU = &GPURequirement{Required: true, MinComputeCapability: "8.9", Accelerators: []string{"cuda"}}
+		}, ""},
+		{"bad compute capability", func(r *RegisterRequest) { r.GPU = &GPURequirement{Required: true, MinComputeCapability: "8"} }, "min_compute_capability"},
+		{"bad accelerator", func(r *RegisterRequest) {
+			r.GPU = &GPURequirement{Recommended: true, Accelerators: []string{"CUDA 12"}}
+		}, "not a platform name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRegisterRequest()
+			tt.modify(&r)
+			err := r.Validate()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// Every problem is reported at once
+	r := RegisterRequest{}
+	if err := r.Validate(); err == nil || strings.Count(err.Error(), "\n") < 4 {
+		t.Errorf("Validate() of an empty request = %v, want all problems", err)
+	}
+}
+
+func TestClient_RegisterModel(t *testing.T) {
+	var received RegisterRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/models/register" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL)
+
+	request := validRegisterRequest()
+	multiEncoder(&request)
+	if err := client.RegisterModel(context.Background(), &request); err != nil {
+		t.Fatalf("RegisterModel() error = %v", err)
+	}
+	if !reflect.DeepEqual(received, request) {
+		t.Errorf("Core received %+v, want %+v", received, request)
+	}
+
+	// Invalid requests are not sent
+	received = RegisterRequest{}
+	request.Digest = ""
+	if err := client.RegisterModel(context.Background(), &request); err == nil {
+		t.Error("RegisterModel() with an invalid request succeeded")
+	}
+	if received.ModelID != "" {
+		t.Error("invalid request was sent to Core")
+	}
+}
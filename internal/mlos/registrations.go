@@ -0,0 +1,156 @@
+package mlos
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// RegistrationsFileName is the file in the Axon home directory that records registrations
+const RegistrationsFileName = "registrations.json"
+
+// Registration records a model registered with Core, so it can be registered again after
+// Core restarts (e.g., on host reboot)
+type Registration struct {
+	ModelID      string   `json:"model_id"` // namespace/name@version
+	Path         string   `json:"path"`
+	Digest       string   `json:"digest,omitempty"`
+	Convert      bool     `json:"convert,omitempty"`    // Registered with --convert
+	DependsOn    []string `json:"depends_on,omitempty"` // Models from the manifest dependencies
+	RegisteredAt string   `json:"registered_at"`
+}
+
+// DependsOnModel reports whether the registration depends on modelID
+func (r Registration) DependsOnModel(modelID string) bool {
+	for _, dep := range r.DependsOn {
+		if matchesDependency(modelID, dep) {
+			return true
+		}
+	}
+	return false
+}
+
+// Registrations is the persisted set of registrations, in registration order
+type Registrations struct {
+	path   string
+	Models []Registration `json:"models"`
+}
+
+// LoadRegistrations reads the registrations file at path (empty if it doesn't exist)
+func LoadRegistrations(path string) (*Registrations, error) {
+	r := &Registrations{path: path}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registrations: %w", err)
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse registrations: %w", err)
+	}
+	return r, nil
+}
+
+// Record adds or replaces the registration for reg.ModelID
+func (r *Registrations) Record(reg Registration) {
+	if reg.RegisteredAt == "" {
+		reg.RegisteredAt = time.Now().Format(time.RFC3339)
+	}
+	for i, existing := range r.Models {
+		if existing.ModelID == reg.ModelID {
+			r.Models[i] = reg
+			return
+		}
+	}
+	r.Models = append(r.Models, reg)
+}
+
+// Remove drops the registration for modelID, reporting whether there was one
+func (r *Registrations) Remove(modelID string) bool {
+	for i, existing := range r.Models {
+		if existing.ModelID == modelID {
+			r.Models = append(r.Models[:i], r.Models[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Save writes the registrations file atomically
+func (r *Registrations) Save() error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal registrations: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("failed to create registrations directory: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write registrations: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write registrations: %w", err)
+	}
+	return nil
+}
+
+// InDependencyOrder returns the registrations ordered so that every model comes after the
+// models it depends on, otherwise keeping registration order. Dependencies without a version
+// match any version; dependencies that were never registered are ignored.
+func (r *Registrations) InDependencyOrder() ([]Registration, error) {
+	n := len(r.Models)
+	dependents := make([][]int, n)
+	pending := make([]int, n)
+	for i, reg := range r.Models {
+		for j, other := range r.Models {
+			if j != i && reg.DependsOnModel(other.ModelID) {
+				dependents[j] = append(dependents[j], i)
+				pending[i]++
+			}
+		}
+	}
+
+	ordered := make([]Registration, 0, n)
+	done := make([]bool, n)
+	for len(ordered) < n {
+		progressed := false
+		for i := range r.Models {
+			if done[i] || pending[i] > 0 {
+				continue
+			}
+			done[i] = true
+			progressed = true
+			ordered = append(ordered, r.Models[i])
+			for _, dependent := range dependents[i] {
+				pending[dependent]--
+			}
+			// Restart from the top so registration order is kept where dependencies allow
+			break
+		}
+		if !progressed {
+			var cycle []string
+			for i, reg := range r.Models {
+				if !done[i] {
+					cycle = append(cycle, reg.ModelID)
+				}
+			}
+			return nil, fmt.Errorf("circular model dependencies: %s", strings.Join(cycle, ", "))
+		}
+	}
+	return ordered, nil
+}
+
+// matchesDependency reports whether modelID satisfies a dependency spec
+// ("namespace/name" or "namespace/name@version")
+func matchesDependency(modelID, dep string) bool {
+	if strings.Contains(dep, "@") {
+		return modelID == dep
+	}
+	return strings.HasPrefix(modelID, dep+"@")
+}
@@ -0,0 +1,74 @@
+package mlos
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRegistrations_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), RegistrationsFileName)
+
+	regs, err := LoadRegistrations(path)
+	if err != nil {
+		t.Fatalf("LoadRegistrations() error = %v", err)
+	}
+	if len(regs.Models) != 0 {
+		t.Fatalf("missing file loaded %d registrations, want 0", len(regs.Models))
+	}
+
+	regs.Record(Registration{ModelID: "hf/bert@latest", Path: "/cache/bert", Digest: "sha256:aaa"})
+	regs.Record(Registration{ModelID: "hf/gpt2@latest", Path: "/cache/gpt2"})
+	regs.Record(Registration{ModelID: "hf/bert@latest", Path: "/cache/bert", Digest: "sha256:bbb"})
+	if err := regs.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := LoadRegistrations(path)
+	if err != nil {
+		t.Fatalf("LoadRegistrations() error = %v", err)
+	}
+	if len(loaded.Models) != 2 {
+		t.Fatalf("loaded %d registrations, want 2 (re-registration replaces)", len(loaded.Models))
+	}
+	if loaded.Models[0].Digest != "sha256:bbb" || loaded.Models[0].RegisteredAt == "" {
+		t.Errorf("Models[0] = %+v, want the latest registration of hf/bert", loaded.Models[0])
+	}
+
+	if !loaded.Remove("hf/gpt2@latest") || loaded.Remove("hf/gpt2@latest") {
+		t.Error("Remove() should report removing a registration exactly once")
+	}
+}
+
+func TestRegistrations_InDependencyOrder(t *testing.T) {
+	regs := &Registrations{Models: []Registration{
+		{ModelID: "hf/pipeline@latest", DependsOn: []string{"hf/encoder", "hf/tokenizer@1.0"}},
+		{ModelID: "hf/standalone@latest"},
+		{ModelID: "hf/encoder@latest", DependsOn: []string{"hf/unregistered"}},
+		{ModelID: "hf/tokenizer@1.0"},
+	}}
+
+	ordered, err := regs.InDependencyOrder()
+	if err != nil {
+		t.Fatalf("InDependencyOrder() error = %v", err)
+	}
+	var got []string
+	for _, reg := range ordered {
+		got = append(got, reg.ModelID)
+	}
+	want := []string{"hf/standalone@latest", "hf/encoder@latest", "hf/tokenizer@1.0", "hf/pipeline@latest"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("InDependencyOrder() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRegistrations_InDependencyOrder_Cycle(t *testing.T) {
+	regs := &Registrations{Models: []Registration{
+		{ModelID: "hf/a@latest", DependsOn: []string{"hf/b"}},
+		{ModelID: "hf/b@latest", DependsOn: []string{"hf/a"}},
+	}}
+	if _, err := regs.InDependencyOrder(); err == nil {
+		t.Error("InDependencyOrder() should fail on circular dependencies")
+	}
+}
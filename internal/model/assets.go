@@ -0,0 +1,96 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Preprocessing assets inference needs besides the weights
+const (
+	AssetTokenizer    = "tokenizer"    // Turns text into token IDs (and generated IDs back into text)
+	AssetPreprocessor = "preprocessor" // Resizes and normalizes images, or extracts audio features
+)
+
+// preprocessorConfigFile is where image processors and audio feature extractors are configured
+const preprocessorConfigFile = "preprocessor_config.json"
+
+// taskAssets are the preprocessing assets each task needs. Feature extraction isn't
+// listed: it's text or vision depending on the model, so nothing can be required.
+var taskAssets = map[string][]string{
+	TaskTextClassification:          {AssetTokenizer},
+	TaskTokenClassification:         {AssetTokenizer},
+	TaskQuestionAnswering:           {AssetTokenizer},
+	TaskFillMask:                    {AssetTokenizer},
+	TaskTextGeneration:              {AssetTokenizer},
+	TaskText2TextGeneration:         {AssetTokenizer},
+	TaskImageClassification:         {AssetPreprocessor},
+	TaskObjectDetection:             {AssetPreprocessor},
+	TaskImageSegmentation:           {AssetPreprocessor},
+	TaskImageToText:                 {AssetPreprocessor, AssetTokenizer},
+	TaskZeroShotImageClassification: {AssetPreprocessor, AssetTokenizer},
+	TaskAutomaticSpeechRecognition:  {AssetPreprocessor, AssetTokenizer},
+	TaskAudioClassification:         {AssetPreprocessor},
+}
+
+// tokenizerFileSets are the file combinations tokenizer libraries can load a tokenizer
+// from: a fast tokenizer, a WordPiece vocabulary, a BPE vocabulary with its merges, or
+// a SentencePiece model
+var tokenizerFileSets = [][]string{
+	{"tokenizer.json"},
+	{"vocab.txt"},
+	{"vocab.json", "merges.txt"},
+	{"tokenizer.model"},
+	{"spiece.model"},
+	{"sentencepiece.bpe.model"},
+}
+
+// AssetFiles describes the files that provide an asset, for messages
+func AssetFiles(asset string) string {
+	if asset == AssetTokenizer {
+		return "tokenizer.json, vocab.txt, vocab.json with merges.txt, or a SentencePiece model"
+	}
+	return preprocessorConfigFile
+}
+
+// MissingAssets returns the preprocessing assets the task needs that the extracted
+// model in dir lacks (none for tasks without requirements). GGUF files embed their
+// tokenizer.
+func MissingAssets(dir, task string) []string {
+	var missing []string
+	for _, asset := range taskAssets[task] {
+		if !hasAsset(dir, asset) {
+			missing = append(missing, asset)
+		}
+	}
+	return missing
+}
+
+// hasAsset reports whether dir has the files of an asset, at the root or in its
+// canonical layout directory
+func hasAsset(dir, asset string) bool {
+	exists := func(name string) bool {
+		_, err := os.Stat(LayoutFile(dir, name))
+		return err == nil
+	}
+	if asset == AssetPreprocessor {
+		return exists(preprocessorConfigFile)
+	}
+
+	for _, set := range tokenizerFileSets {
+		complete := true
+		for _, name := range set {
+			complete = complete && exists(name)
+		}
+		if complete {
+			return true
+		}
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.EqualFold(filepath.Ext(entry.Name()), ".gguf") {
+			return true
+		}
+	}
+	return false
+}
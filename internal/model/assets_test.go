@@ -0,0 +1,43 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMissingAssets(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		task  string
+		want  []string
+	}{
+		{"fast tokenizer", []string{"tokenizer/tokenizer.json"}, TaskTextClassification, nil},
+		{"wordpiece vocabulary", []string{"vocab.txt"}, TaskFillMask, nil},
+		{"bpe without merges", []string{"tokenizer/vocab.json"}, TaskTextGeneration, []string{AssetTokenizer}},
+		{"bpe with merges", []string{"vocab.json", "merges.txt"}, TaskTextGeneration, nil},
+		{"gguf embeds its tokenizer", []string{"model.Q4_K_M.gguf"}, TaskTextGeneration, nil},
+		{"vision without preprocessor", []string{"config/config.json"}, TaskImageClassification, []string{AssetPreprocessor}},
+		{"vision with preprocessor", []string{"config/preprocessor_config.json"}, TaskObjectDetection, nil},
+		{"speech needs both", []string{"tokenizer/tokenizer.json"}, TaskAutomaticSpeechRecognition, []string{AssetPreprocessor}},
+		{"feature extraction", nil, TaskFeatureExtraction, nil},
+		{"unknown task", nil, "", nil},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for _, file := range tt.files {
+			path := filepath.Join(dir, file)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte("{}"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := MissingAssets(dir, tt.task); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: MissingAssets() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// InventoryFileName is the name of the integrity manifest written next to extracted model files
+const InventoryFileName = "files.json"
+
+// InventoryEntry describes a single extracted file
+type InventoryEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Inventory is the integrity manifest of an extracted model directory
+type Inventory struct {
+	GeneratedAt string           `json:"generated_at"`
+	Files       []InventoryEntry `json:"files"`
+}
+
+// isInventoryExcluded reports whether a file is managed by Axon itself and
+// therefore not part of the extracted model tree.
+// These files are rewritten after install, so hashing them would produce false mismatches.
+func isInventoryExcluded(relPath string) bool {
+	base := filepath.Base(relPath)
+	return base == InventoryFileName ||
+		base == "manifest.yaml" ||
+		base == ".axon_metadata.json" ||
+		base == "provenance.json" ||
+		strings.HasSuffix(base, ".axon")
+}
+
+// BuildInventory walks an extracted model directory and hashes every model file
+func BuildInventory(dir string) (*Inventory, error) {
+	inventory := &Inventory{
+		GeneratedAt: time.Now().Format(time.RFC3339),
+		Files:       []InventoryEntry{},
+	}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if isInventoryExcluded(relPath) {
+			return nil
+		}
+
+		hash, err := utils.ComputeSHA256(path)
+		if err != nil {
+			return err
+		}
+
+		inventory.Files = append(inventory.Files, InventoryEntry{
+			Path:   filepath.ToSlash(relPath),
+			Size:   info.Size(),
+			SHA256: hash,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build inventory: %w", err)
+	}
+
+	sort.Slice(inventory.Files, func(i, j int) bool {
+		return inventory.Files[i].Path < inventory.Files[j].Path
+	})
+
+	return inventory, nil
+}
+
+// Digest returns a content digest ("sha256:<hex>") of the inventoried files.
+// It depends only on file paths and hashes, so it is stable across re-extraction.
+func (inv *Inventory) Digest() string {
+	entries := make([]InventoryEntry, len(inv.Files))
+	copy(entries, inv.Files)
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+
+	h := sha256.New()
+	for _, entry := range entries {
+		_, _ = fmt.Fprintf(h, "%s %s\n", entry.Path, entry.SHA256)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// ModelDigest returns the content digest of the model in dir, using files.json
+// when present and hashing the tree otherwise.
+func ModelDigest(dir string) (string, error) {
+	inventory, err := ReadInventory(dir)
+	if err != nil {
+		inventory, err = BuildInventory(dir)
+		if err != nil {
+			return "", err
+		}
+	}
+	return inventory.Digest(), nil
+}
+
+// WriteInventory builds the inventory for dir and saves it as files.json inside dir
+func WriteInventory(dir string) (*Inventory, error) {
+	inventory, err := BuildInventory(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal inventory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, InventoryFileName), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write inventory: %w", err)
+	}
+
+	return inventory, nil
+}
+
+// inventoryEntry returns the files.json entry for relPath in dir, or nil
+func inventoryEntry(dir, relPath string) *InventoryEntry {
+	inventory, err := ReadInventory(dir)
+	if err != nil {
+		return nil
+	}
+	for i := range inventory.Files {
+		if inventory.Files[i].Path == relPath {
+			return &inventory.Files[i]
+		}
+	}
+	return nil
+}
+
+// ReadInventory reads files.json from dir
+func ReadInventory(dir string) (*Inventory, error) {
+	data, err := os.ReadFile(filepath.Join(dir, InventoryFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	var inventory Inventory
+	if err := json.Unmarshal(data, &inventory); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory: %w", err)
+	}
+
+	return &inventory, nil
+}
+
+// VerifyInventory checks the extracted tree in dir against its files.json.
+// It returns a list of human-readable problems; an empty list means the tree is intact.
+func VerifyInventory(dir string) ([]string, error) {
+	return VerifyEncryptedInventory(dir, nil)
+}
+
+// VerifyEncryptedInventory is VerifyInventory for a tree whose files may be encrypted
+// at rest: encrypted files are decrypted with key as they are hashed. Without a key,
+// encrypted files are reported as unverifiable.
+func VerifyEncryptedInventory(dir string, key []byte) ([]string, error) {
+	inventory, err := ReadInventory(dir)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyEntries(dir, inventory.Files, key), nil
+}
+
+// VerifyEntries checks the files of dir against inventory entries, e.g. those recorded
+// in the cache's integrity index, decrypting encrypted files with key as they are hashed.
+// It returns the problems found; none means the files are intact.
+func VerifyEntries(dir string, entries []InventoryEntry, key []byte) []string {
+	var problems []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, filepath.FromSlash(entry.Path))
+		info, err := os.Stat(path)
+		if err != nil {
+			if _, encErr := os.Stat(path + cachecrypt.Suffix); encErr == nil {
+				if problem := verifyEncryptedEntry(entry, path+cachecrypt.Suffix, key); problem != "" {
+					problems = append(problems, problem)
+				}
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s: missing", entry.Path))
+			continue
+		}
+		if info.Size() != entry.Size {
+			problems = append(problems, fmt.Sprintf("%s: size mismatch (expected %d, got %d)", entry.Path, entry.Size, info.Size()))
+			continue
+		}
+		if err := utils.VerifySHA256(path, entry.SHA256); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", entry.Path, err))
+		}
+	}
+	return problems
+}
+
+// verifyEncryptedEntry checks the plaintext of an encrypted file against its inventory entry
+func verifyEncryptedEntry(entry InventoryEntry, encPath string, key []byte) string {
+	if key == nil {
+		return fmt.Sprintf("%s: encrypted (no key to verify)", entry.Path)
+	}
+	digest, size, err := cachecrypt.HashFile(encPath, key)
+	switch {
+	case err != nil:
+		return fmt.Sprintf("%s: %v", entry.Path, err)
+	case size != entry.Size:
+		return fmt.Sprintf("%s: size mismatch (expected %d, got %d)", entry.Path, entry.Size, size)
+	case digest != entry.SHA256:
+		return fmt.Sprintf("%s: checksum mismatch (expected %s, got %s)", entry.Path, entry.SHA256, digest)
+	}
+	return ""
+}
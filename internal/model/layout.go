@@ -0,0 +1,321 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+)
+
+// The canonical layout of an extracted model directory, which every install normalizes
+// into so MLOS Core finds model files in the same places whichever adapter produced them:
+//
+//	model.onnx          the ONNX model, or the files named in onnx_manifest.json
+//	onnx_manifest.json  the components of a multi-encoder ONNX model
+//	*.gguf, *.tflite    other execution files, next to model.onnx
+//	weights/            framework weights (safetensors, PyTorch, Keras, Flax) and shard indexes
+//	tokenizer/          tokenizer files (tokenizer.json, vocab.txt, merges.txt, ...)
+//	config/             model configuration (config.json, generation_config.json, ...)
+//
+// Files Axon manages (manifest.yaml, files.json, packages) and anything else stay where
+// they are, so model-specific subdirectories (SavedModel variables/, 1_Pooling/) keep
+// working.
+const (
+	ONNXFileName         = "model.onnx"
+	ONNXManifestFileName = "onnx_manifest.json"
+	WeightsDir           = "weights"
+	TokenizerDir         = "tokenizer"
+	ConfigDir            = "config"
+)
+
+// onnxSubdir is where exporters such as Optimum put ONNX files
+const onnxSubdir = "onnx"
+
+// strayFileSuffixes mark partial or temporary downloads that must not be installed
+var strayFileSuffixes = []string{".tmp", ".part", ".partial", ".incomplete", ".download"}
+
+// nestedArchiveSuffixes mark archives adapters package whole (TensorFlow Hub, ModelScope)
+var nestedArchiveSuffixes = []string{".tar.gz", ".tgz"}
+
+// tokenizerFiles are the files tokenizer libraries load
+var tokenizerFiles = map[string]bool{
+	"tokenizer.json":          true,
+	"tokenizer_config.json":   true,
+	"special_tokens_map.json": true,
+	"added_tokens.json":       true,
+	"vocab.txt":               true,
+	"vocab.json":              true,
+	"merges.txt":              true,
+	"tokenizer.model":         true,
+	"spiece.model":            true,
+	"sentencepiece.bpe.model": true,
+}
+
+// frameworkWeightSuffixes mark weights that need a framework runtime or conversion
+var frameworkWeightSuffixes = []string{".safetensors", ".bin", ".pt", ".pth", ".h5", ".msgpack", ".ckpt", ".index.json"}
+
+// LayoutChange is one step of normalizing a model directory
+type LayoutChange struct {
+	Action string // unpacked, removed or moved
+	From   string // Path relative to the model directory
+	To     string // New path, for moves
+}
+
+func (c LayoutChange) String() string {
+	if c.To != "" {
+		return fmt.Sprintf("%s %s -> %s", c.Action, c.From, c.To)
+	}
+	return fmt.Sprintf("%s %s", c.Action, c.From)
+}
+
+// layoutDir returns the canonical subdirectory of a root-level file ("" if it stays at the root)
+func layoutDir(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case isInventoryExcluded(name):
+		return ""
+	case tokenizerFiles[lower]:
+		return TokenizerDir
+	case strings.HasSuffix(lower, ".json") && strings.Contains(lower, "config") && lower != ONNXManifestFileName:
+		return ConfigDir
+	}
+	for _, suffix := range frameworkWeightSuffixes {
+		if strings.HasSuffix(lower, suffix) {
+			return WeightsDir
+		}
+	}
+	return ""
+}
+
+// LayoutFile returns the path of a root-level model file such as config.json or
+// tokenizer.json in dir, whether or not dir has been normalized yet
+func LayoutFile(dir, name string) string {
+	if sub := layoutDir(name); sub != "" {
+		path := filepath.Join(dir, sub, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(dir, name)
+}
+
+// NormalizeLayout rearranges an extracted model directory into the canonical layout:
+// it unpacks archives nested in the package, removes partial downloads, moves ONNX
+// exports out of onnx/, names a lone ONNX model model.onnx and sorts weights, tokenizer
+// and config files into their subdirectories. It is idempotent.
+func NormalizeLayout(dir string) ([]LayoutChange, error) {
+	if cachecrypt.IsEncryptedDir(dir) {
+		return nil, fmt.Errorf("can't normalize %s: model files are encrypted", dir)
+	}
+
+	var changes []LayoutChange
+	for _, step := range []func(string) ([]LayoutChange, error){
+		unpackNestedArchives, removeStrayFiles, hoistONNXExports, nameONNXModel, sortRootFiles,
+	} {
+		stepChanges, err := step(dir)
+		changes = append(changes, stepChanges...)
+		if err != nil {
+			return changes, fmt.Errorf("failed to normalize %s: %w", dir, err)
+		}
+	}
+	return changes, nil
+}
+
+// rootFiles lists the names of the regular files directly in dir
+func rootFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// unpackNestedArchives extracts root-level tar.gz archives in place and removes them
+func unpackNestedArchives(dir string) ([]LayoutChange, error) {
+	names, err := rootFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var changes []LayoutChange
+	for _, name := range names {
+		if !hasAnySuffix(strings.ToLower(name), nestedArchiveSuffixes) {
+			continue
+		}
+		archive := filepath.Join(dir, name)
+		if err := ExtractPackage(archive, dir); err != nil {
+			return changes, fmt.Errorf("failed to unpack %s: %w", name, err)
+		}
+		if err := os.Remove(archive); err != nil {
+			return changes, err
+		}
+		changes = append(changes, LayoutChange{Action: "unpacked", From: name})
+	}
+	return changes, nil
+}
+
+// removeStrayFiles deletes partial and temporary downloads anywhere in dir
+func removeStrayFiles(dir string) ([]LayoutChange, error) {
+	var changes []LayoutChange
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !hasAnySuffix(strings.ToLower(info.Name()), strayFileSuffixes) {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		changes = append(changes, LayoutChange{Action: "removed", From: filepath.ToSlash(relPath)})
+		return nil
+	})
+	return changes, err
+}
+
+// hoistONNXExports moves ONNX files, their external data and onnx_manifest.json from
+// onnx/ to the root. External data is located relative to the model file, so nothing is
+// moved if any name is already taken at the root.
+func hoistONNXExports(dir string) ([]LayoutChange, error) {
+	subdir := filepath.Join(dir, onnxSubdir)
+	names, err := rootFiles(subdir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var exports []string
+	for _, name := range names {
+		lower := strings.ToLower(name)
+		if !hasAnySuffix(lower, []string{".onnx", ".onnx_data", ".onnx.data"}) && lower != ONNXManifestFileName {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return nil, nil
+		}
+		exports = append(exports, name)
+	}
+
+	var changes []LayoutChange
+	for _, name := range exports {
+		if err := os.Rename(filepath.Join(subdir, name), filepath.Join(dir, name)); err != nil {
+			return changes, err
+		}
+		changes = append(changes, LayoutChange{Action: "moved", From: onnxSubdir + "/" + name, To: name})
+	}
+	_ = os.Remove(subdir) // Only succeeds if nothing else was in it
+	return changes, nil
+}
+
+// nameONNXModel renames a lone root-level ONNX model to model.onnx. Multi-encoder models
+// keep their names, which onnx_manifest.json refers to.
+func nameONNXModel(dir string) ([]LayoutChange, error) {
+	if _, err := os.Stat(filepath.Join(dir, ONNXManifestFileName)); err == nil {
+		return nil, nil
+	}
+	names, err := rootFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var models []string
+	for _, name := range names {
+		if strings.HasSuffix(strings.ToLower(name), ".onnx") {
+			models = append(models, name)
+		}
+	}
+	if len(models) != 1 || models[0] == ONNXFileName {
+		return nil, nil
+	}
+	if err := os.Rename(filepath.Join(dir, models[0]), filepath.Join(dir, ONNXFileName)); err != nil {
+		return nil, err
+	}
+	return []LayoutChange{{Action: "moved", From: models[0], To: ONNXFileName}}, nil
+}
+
+// sortRootFiles moves root-level weights, tokenizer and config files into their
+// subdirectories, replacing older copies there (e.g. when a package is extracted again)
+func sortRootFiles(dir string) ([]LayoutChange, error) {
+	names, err := rootFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	var changes []LayoutChange
+	for _, name := range names {
+		sub := layoutDir(name)
+		if sub == "" {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			return changes, err
+		}
+		if err := os.Rename(filepath.Join(dir, name), filepath.Join(dir, sub, name)); err != nil {
+			return changes, err
+		}
+		changes = append(changes, LayoutChange{Action: "moved", From: name, To: sub + "/" + name})
+	}
+	return changes, nil
+}
+
+// NativeLayout temporarily moves the files in weights/, tokenizer/ and config/ back to
+// the root of dir, where converters and framework loaders (from_pretrained, llama.cpp)
+// expect them. The returned function moves them back; it is safe to call more than once.
+func NativeLayout(dir string) (func() error, error) {
+	var moved []string // Paths relative to dir, in their canonical subdirectory
+	restore := func() error {
+		var errs []string
+		for _, relPath := range moved {
+			if err := os.Rename(filepath.Join(dir, filepath.Base(relPath)), filepath.Join(dir, relPath)); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+		moved = nil
+		if len(errs) > 0 {
+			return fmt.Errorf("failed to restore the layout of %s: %s", dir, strings.Join(errs, "; "))
+		}
+		return nil
+	}
+
+	for _, sub := range []string{WeightsDir, TokenizerDir, ConfigDir} {
+		names, err := rootFiles(filepath.Join(dir, sub))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			_ = restore()
+			return nil, err
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				continue // Never overwrite a root-level file
+			}
+			if err := os.Rename(filepath.Join(dir, sub, name), filepath.Join(dir, name)); err != nil {
+				_ = restore()
+				return nil, err
+			}
+			moved = append(moved, filepath.Join(sub, name))
+		}
+	}
+	return restore, nil
+}
+
+// hasAnySuffix reports whether s ends with one of suffixes
+func hasAnySuffix(s string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// writeTestTree creates files (relative path -> content) under dir
+func writeTestTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// listTestTree returns the relative paths of all files under dir
+func listTestTree(t *testing.T, dir string) []string {
+	t.Helper()
+	var paths []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		paths = append(paths, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func TestNormalizeLayout(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string
+	}{
+		{
+			name: "hugging face repository",
+			files: map[string]string{
+				"config.json":            "{}",
+				"generation_config.json": "{}",
+				"tokenizer.json":         "{}",
+				"vocab.txt":              "[CLS]",
+				"model.safetensors":      "weights",
+				"README.md":              "readme",
+				"manifest.yaml":          "kind: Model",
+				"hf-bert-latest.axon":    "package",
+				"1_Pooling/config.json":  "{}",
+			},
+			want: []string{
+				"1_Pooling/config.json", "README.md", "config/config.json", "config/generation_config.json",
+				"hf-bert-latest.axon", "manifest.yaml", "tokenizer/tokenizer.json", "tokenizer/vocab.txt", "weights/model.safetensors",
+			},
+		},
+		{
+			name: "optimum export",
+			files: map[string]string{
+				"onnx/decoder_model.onnx":      "decoder",
+				"onnx/encoder_model.onnx":      "encoder",
+				"onnx/encoder_model.onnx_data": "data",
+				"onnx/onnx_manifest.json":      "{}",
+				"onnx/README.md":               "readme",
+			},
+			want: []string{"decoder_model.onnx", "encoder_model.onnx", "encoder_model.onnx_data", "onnx/README.md", "onnx_manifest.json"},
+		},
+		{
+			name:  "lone onnx model",
+			files: map[string]string{"onnx/resnet50-v2-7.onnx": "onnx", "resnet.pt.tmp": "partial", "weights/old.bin.part": "partial"},
+			want:  []string{"model.onnx"},
+		},
+		{
+			name: "root export takes precedence",
+			files: map[string]string{
+				"model.onnx":      "root",
+				"onnx/model.onnx": "nested",
+				"onnx/model_data": "data",
+			},
+			want: []string{"model.onnx", "onnx/model.onnx", "onnx/model_data"},
+		},
+		{
+			name:  "execution files stay at the root",
+			files: map[string]string{"model.Q4_K_M.gguf": "gguf", "model.tflite": "tflite"},
+			want:  []string{"model.Q4_K_M.gguf", "model.tflite"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeTestTree(t, dir, tt.files)
+
+			if _, err := NormalizeLayout(dir); err != nil {
+				t.Fatalf("NormalizeLayout() error = %v", err)
+			}
+			if got := listTestTree(t, dir); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layout = %v, want %v", got, tt.want)
+			}
+
+			changes, err := NormalizeLayout(dir)
+			if err != nil || len(changes) != 0 {
+				t.Errorf("second NormalizeLayout() = %v, %v; want no changes", changes, err)
+			}
+		})
+	}
+}
+
+func TestNormalizeLayout_NestedArchive(t *testing.T) {
+	dir := t.TempDir()
+	writeTestPackage(t, filepath.Join(dir, "model.tar.gz"), map[string]string{
+		"saved_model.pb":                 "graph",
+		"variables/variables.index":      "index",
+		"variables/variables.data-00000": "data",
+	})
+
+	changes, err := NormalizeLayout(dir)
+	if err != nil {
+		t.Fatalf("NormalizeLayout() error = %v", err)
+	}
+	if len(changes) != 1 || changes[0].String() != "unpacked model.tar.gz" {
+		t.Errorf("changes = %v, want unpacked model.tar.gz", changes)
+	}
+	want := []string{"saved_model.pb", "variables/variables.data-00000", "variables/variables.index"}
+	if got := listTestTree(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("layout = %v, want %v", got, want)
+	}
+}
+
+func TestNativeLayout(t *testing.T) {
+	dir := t.TempDir()
+	writeTestTree(t, dir, map[string]string{
+		"config.json":       "{}",
+		"tokenizer.json":    "{}",
+		"model.safetensors": "weights",
+		"model.onnx":        "onnx",
+	})
+	if _, err := NormalizeLayout(dir); err != nil {
+		t.Fatal(err)
+	}
+	normalized := listTestTree(t, dir)
+
+	if got := LayoutFile(dir, "config.json"); got != filepath.Join(dir, ConfigDir, "config.json") {
+		t.Errorf("LayoutFile(config.json) = %s", got)
+	}
+
+	restore, err := NativeLayout(dir)
+	if err != nil {
+		t.Fatalf("NativeLayout() error = %v", err)
+	}
+	want := []string{"config.json", "model.onnx", "model.safetensors", "tokenizer.json"}
+	if got := listTestTree(t, dir); !reflect.DeepEqual(got, want) {
+		t.Errorf("native layout = %v, want %v", got, want)
+	}
+	if got := LayoutFile(dir, "config.json"); got != filepath.Join(dir, "config.json") {
+		t.Errorf("LayoutFile(config.json) = %s in the native layout", got)
+	}
+
+	if err := restore(); err != nil {
+		t.Fatalf("restore() error = %v", err)
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("second restore() error = %v", err)
+	}
+	if got := listTestTree(t, dir); !reflect.DeepEqual(got, normalized) {
+		t.Errorf("restored layout = %v, want %v", got, normalized)
+	}
+}
@@ -1,5 +1,24 @@
 // Package model provides functionality for model package handling, extraction, and verification.
 package model
 
-// TODO: Implement model package handling
-// This will handle model package extraction and verification
+import (
+	"github.com/mlOS-foundation/axon/internal/archive"
+)
+
+// ExtractPackage extracts a .axon package (tar.gz, format v1 or v2) to the destination
+// directory. Unsafe entries and packages over the extraction limits fail; see
+// archive.ExtractTarGz.
+func ExtractPackage(packagePath, destDir string) error {
+	return archive.ExtractTarGz(packagePath, destDir, func(name string) bool {
+		// The index of v2 packages describes the package, it isn't a model file
+		return name == PackageIndexFileName
+	})
+}
+
+// ExtractPackageMatching extracts the files of a .axon package whose paths keep accepts
+// to the destination directory, with the same limits as ExtractPackage
+func ExtractPackageMatching(packagePath, destDir string, keep func(name string) bool) error {
+	return archive.ExtractTarGz(packagePath, destDir, func(name string) bool {
+		return name == PackageIndexFileName || !keep(name)
+	})
+}
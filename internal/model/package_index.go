@@ -0,0 +1,617 @@
+package model
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/archive"
+)
+
+// Package format v2
+//
+// A v2 .axon package is still a tar.gz, so anything that reads v1 packages can read it,
+// but every file is compressed as its own gzip member. The last file in the archive is
+// package-index.json, listing every file with its size, SHA-256 and the offset of its
+// member, and the package ends with an empty gzip member whose header records where the
+// index is. Tools read the index from the end of the package and decompress just the
+// member of the file they want, e.g. config.json of a multi-GB model.
+//
+//	[member: file 1] ... [member: file N] [member: package-index.json]
+//	[member: tar end-of-archive] [footer: empty member, extra field -> index member]
+//
+// Packages are written in a canonical form, so the same files built by the same Axon
+// version give a byte-identical package that can be deduplicated and signed by digest:
+//
+//   - files are in byte order of their slash-separated paths
+//   - tar headers hold only the path, size, mode 0644 (0755 for executables) and the
+//     package time, with no owner, group or access times
+//   - gzip headers hold no file name or modification time
+//   - the index's created_at is the package time
+//
+// The package time is $SOURCE_DATE_EPOCH when it's set, else the Unix epoch.
+
+// PackageFormatVersion is the package format written by this version of Axon
+const PackageFormatVersion = 2
+
+// PackageIndexFileName is the name of the index in v2 packages
+const PackageIndexFileName = "package-index.json"
+
+// ErrNoPackageIndex is returned for packages without an index (format v1)
+var ErrNoPackageIndex = errors.New("package has no index (format v1)")
+
+// PackageIndex is the contents of package-index.json
+type PackageIndex struct {
+	FormatVersion int            `json:"format_version"`
+	Compression   string         `json:"compression"` // "gzip": one gzip member per file
+	CreatedAt     time.Time      `json:"created_at"`
+	Files         []PackageEntry `json:"files"`
+}
+
+// PackageEntry describes a file in a package
+type PackageEntry struct {
+	Path   string `json:"path"` // Slash-separated
+	Size   int64  `json:"size"`
+	Mode   int64  `json:"mode"`
+	SHA256 string `json:"sha256,omitempty"` // Not known for v1 packages
+
+	// Byte range of the gzip member holding the file's tar entry (v2 only)
+	Offset         int64 `json:"offset"`
+	CompressedSize int64 `json:"compressed_size"`
+}
+
+// File returns the entry for a path, if the package has one
+func (idx *PackageIndex) File(name string) (PackageEntry, bool) {
+	for _, entry := range idx.Files {
+		if entry.Path == name {
+			return entry, true
+		}
+	}
+	return PackageEntry{}, false
+}
+
+// SourceDateEpoch returns the time in $SOURCE_DATE_EPOCH (seconds since the Unix epoch),
+// which reproducible builds record instead of the current time
+func SourceDateEpoch() (time.Time, bool) {
+	seconds, err := strconv.ParseInt(os.Getenv("SOURCE_DATE_EPOCH"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(seconds, 0).UTC(), true
+}
+
+// packageTime returns the time recorded for every file in a package
+func packageTime() time.Time {
+	if t, ok := SourceDateEpoch(); ok {
+		return t
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// canonicalMode returns the mode a file is packaged with
+func canonicalMode(mode os.FileMode) int64 {
+	if mode&0100 != 0 {
+		return 0755
+	}
+	return 0644
+}
+
+// footerSubfield identifies the gzip extra subfield of the footer ("AX")
+var footerSubfield = [2]byte{'A', 'X'}
+
+// footer returns the closing gzip member of a v2 package, which records the byte range of
+// the index member as 16 hex digits of offset and 16 of size in its extra field
+func footer(offset, size int64) []byte {
+	extra := make([]byte, 4, 36)
+	copy(extra, footerSubfield[:])
+	binary.LittleEndian.PutUint16(extra[2:], 32)
+	extra = fmt.Appendf(extra, "%016x%016x", offset, size)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	gz.Extra = extra
+	_ = gz.Close()
+	return buf.Bytes()
+}
+
+// footerSize is the size of every footer
+var footerSize = int64(len(footer(0, 0)))
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// memberWriter sends the tar stream to the current gzip member
+type memberWriter struct {
+	gz *gzip.Writer
+}
+
+func (m *memberWriter) Write(p []byte) (int, error) {
+	return m.gz.Write(p)
+}
+
+// packageWriter writes a v2 package
+type packageWriter struct {
+	out    *countingWriter
+	member *memberWriter
+	tw     *tar.Writer
+	start  int64
+}
+
+// begin starts a new gzip member, whose header holds no name or modification time
+func (pw *packageWriter) begin() {
+	pw.start = pw.out.n
+	pw.member.gz = gzip.NewWriter(pw.out)
+}
+
+// end finishes the current gzip member and returns its byte range
+func (pw *packageWriter) end() (int64, int64, error) {
+	if err := pw.tw.Flush(); err != nil {
+		return 0, 0, err
+	}
+	if err := pw.member.gz.Close(); err != nil {
+		return 0, 0, err
+	}
+	return pw.start, pw.out.n - pw.start, nil
+}
+
+// add writes one file as its own member and returns its index entry
+func (pw *packageWriter) add(name string, size, mode int64, r io.Reader) (PackageEntry, error) {
+	header := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     mode,
+		ModTime:  packageTime(),
+	}
+
+	pw.begin()
+	if err := pw.tw.WriteHeader(header); err != nil {
+		return PackageEntry{}, err
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(pw.tw, hasher), r); err != nil {
+		return PackageEntry{}, err
+	}
+	offset, compressed, err := pw.end()
+	if err != nil {
+		return PackageEntry{}, err
+	}
+	return PackageEntry{
+		Path:           name,
+		Size:           header.Size,
+		Mode:           header.Mode,
+		SHA256:         hex.EncodeToString(hasher.Sum(nil)),
+		Offset:         offset,
+		CompressedSize: compressed,
+	}, nil
+}
+
+// WriteTarEntry writes one regular file of the given size, read from r, to an uncompressed
+// archive such as a bundle or a delta
+func WriteTarEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, Typeflag: tar.TypeReg, ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if _, err := io.Copy(tw, r); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// PackageWrite describes a package written by WritePackage or UpdatePackage
+type PackageWrite struct {
+	Index  *PackageIndex
+	SHA256 string // Of the whole package, hashed as it was written
+	Size   int64
+	Reused int // Files whose members were copied from the base package
+}
+
+// WritePackage writes the files under srcDir to a v2 package at destPath and returns its
+// index and digest
+func WritePackage(srcDir, destPath string) (*PackageWrite, error) {
+	return writePackage(srcDir, destPath, nil, nil)
+}
+
+// UpdatePackage writes the files under srcDir to a v2 package at destPath like
+// WritePackage, but copies the compressed members of the files the package at basePath
+// already holds unchanged (same path, mode and SHA-256) instead of compressing them
+// again, so adding a file to a multi-GB package costs hashing its weights rather than
+// recompressing them. Copied members are checked against the base index as they are
+// copied.
+//
+// Members are only copied from canonical packages with the current package time, so the
+// result is the package WritePackage would give when both were built by the same Axon
+// version. Anything else (missing or v1 packages, older or differently timed ones,
+// corrupt members) is rebuilt in full.
+func UpdatePackage(basePath, srcDir, destPath string) (*PackageWrite, error) {
+	base, err := os.Open(basePath)
+	if err != nil {
+		return WritePackage(srcDir, destPath)
+	}
+	defer func() { _ = base.Close() }()
+
+	baseIndex, err := readPackageIndex(base)
+	if err != nil || !baseIndex.CreatedAt.Equal(packageTime()) {
+		return WritePackage(srcDir, destPath)
+	}
+	written, err := writePackage(srcDir, destPath, base, baseIndex)
+	if errors.Is(err, errCorruptMember) {
+		return WritePackage(srcDir, destPath)
+	}
+	return written, err
+}
+
+// writePackage writes the files under srcDir to a v2 package at destPath, copying the
+// members of unchanged files from base when it's given
+func writePackage(srcDir, destPath string, base *os.File, baseIndex *PackageIndex) (*PackageWrite, error) {
+	file, err := os.Create(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create package file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	// The package is hashed as it's written rather than read again for its digest
+	hasher := sha256.New()
+	member := &memberWriter{}
+	pw := &packageWriter{out: &countingWriter{w: io.MultiWriter(file, hasher)}, member: member, tw: tar.NewWriter(member)}
+	index := &PackageIndex{FormatVersion: PackageFormatVersion, Compression: "gzip", CreatedAt: packageTime(), Files: []PackageEntry{}}
+
+	var files []string
+	err = filepath.Walk(srcDir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(srcDir, p)
+		if err != nil || filepath.ToSlash(relPath) == PackageIndexFileName {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relPath))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Walk orders by directory ("a/b" before "a.txt"); the canonical order is by path
+	sort.Strings(files)
+
+	reused := 0
+	for _, name := range files {
+		if entry, ok := unchangedEntry(baseIndex, srcDir, name); ok {
+			copied, err := pw.copyMember(base, entry)
+			if err != nil {
+				return nil, fmt.Errorf("failed to copy %s: %w", name, err)
+			}
+			index.Files = append(index.Files, copied)
+			reused++
+			continue
+		}
+		if err := addPackageFile(pw, index, srcDir, name); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode package index: %w", err)
+	}
+	indexEntry, err := pw.add(PackageIndexFileName, int64(len(data)), 0644, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to write package index: %w", err)
+	}
+
+	// The end-of-archive marker gets a member of its own, then the footer closes the file
+	pw.begin()
+	if err := pw.tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write package: %w", err)
+	}
+	if err := member.gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write package: %w", err)
+	}
+	if _, err := pw.out.Write(footer(indexEntry.Offset, indexEntry.CompressedSize)); err != nil {
+		return nil, fmt.Errorf("failed to write package: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write package: %w", err)
+	}
+	return &PackageWrite{Index: index, SHA256: hex.EncodeToString(hasher.Sum(nil)), Size: pw.out.n, Reused: reused}, nil
+}
+
+// errCorruptMember reports a member of a base package that doesn't hold the file its
+// index entry describes
+var errCorruptMember = errors.New("member doesn't match the package index")
+
+// unchangedEntry returns the base index entry of the file name (relative to srcDir) if
+// the file is unchanged from it
+func unchangedEntry(baseIndex *PackageIndex, srcDir, name string) (PackageEntry, bool) {
+	if baseIndex == nil {
+		return PackageEntry{}, false
+	}
+	entry, ok := baseIndex.File(name)
+	if !ok || entry.SHA256 == "" {
+		return PackageEntry{}, false
+	}
+	src, err := os.Open(filepath.Join(srcDir, filepath.FromSlash(name)))
+	if err != nil {
+		return PackageEntry{}, false
+	}
+	defer func() { _ = src.Close() }()
+	info, err := src.Stat()
+	if err != nil || info.Size() != entry.Size || canonicalMode(info.Mode()) != entry.Mode {
+		return PackageEntry{}, false
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, src); err != nil {
+		return PackageEntry{}, false
+	}
+	return entry, hex.EncodeToString(hasher.Sum(nil)) == entry.SHA256
+}
+
+// copyMember copies the member of a base package entry as it is and returns the entry at
+// its new offset. The member is decompressed on the way to check that it holds the
+// entry's file; a mismatch returns errCorruptMember.
+func (pw *packageWriter) copyMember(base *os.File, entry PackageEntry) (PackageEntry, error) {
+	pw.start = pw.out.n
+	section := io.NewSectionReader(base, entry.Offset, entry.CompressedSize)
+	gz, err := gzip.NewReader(io.TeeReader(section, pw.out))
+	if err != nil {
+		return PackageEntry{}, errCorruptMember
+	}
+	gz.Multistream(false)
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil || header.Name != entry.Path || header.Size != entry.Size || header.Mode != entry.Mode {
+		return PackageEntry{}, errCorruptMember
+	}
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, tr); err != nil || hex.EncodeToString(hasher.Sum(nil)) != entry.SHA256 {
+		return PackageEntry{}, errCorruptMember
+	}
+	// The tar padding and the gzip trailer, then whatever the gzip reader didn't buffer
+	if _, err := io.Copy(io.Discard, gz); err != nil {
+		return PackageEntry{}, errCorruptMember
+	}
+	if _, err := io.Copy(pw.out, section); err != nil {
+		return PackageEntry{}, err
+	}
+	entry.Offset, entry.CompressedSize = pw.start, pw.out.n-pw.start
+	return entry, nil
+}
+
+// addPackageFile adds the file name (relative to srcDir) to a package being written
+func addPackageFile(pw *packageWriter, index *PackageIndex, srcDir, name string) error {
+	src, err := os.Open(filepath.Join(srcDir, filepath.FromSlash(name)))
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	defer func() { _ = src.Close() }()
+	info, err := src.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	entry, err := pw.add(name, info.Size(), canonicalMode(info.Mode()), src)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	index.Files = append(index.Files, entry)
+	return nil
+}
+
+// readFooter returns the byte range of the index member recorded at the end of a package
+func readFooter(f *os.File, size int64) (int64, int64, error) {
+	if size < footerSize {
+		return 0, 0, ErrNoPackageIndex
+	}
+	tail := make([]byte, footerSize)
+	if _, err := f.ReadAt(tail, size-footerSize); err != nil {
+		return 0, 0, fmt.Errorf("failed to read package: %w", err)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(tail))
+	if err != nil {
+		return 0, 0, ErrNoPackageIndex
+	}
+	extra := gz.Extra
+	if len(extra) != 36 || extra[0] != footerSubfield[0] || extra[1] != footerSubfield[1] {
+		return 0, 0, ErrNoPackageIndex
+	}
+	offset, err1 := strconv.ParseInt(string(extra[4:20]), 16, 64)
+	length, err2 := strconv.ParseInt(string(extra[20:36]), 16, 64)
+	if err1 != nil || err2 != nil || offset < 0 || length <= 0 || offset+length > size-footerSize {
+		return 0, 0, fmt.Errorf("corrupt package footer")
+	}
+	return offset, length, nil
+}
+
+// openMember returns the tar entry in the gzip member at the given byte range
+func openMember(f *os.File, offset, size int64) (*tar.Reader, *tar.Header, error) {
+	gz, err := gzip.NewReader(io.NewSectionReader(f, offset, size))
+	if err != nil {
+		return nil, nil, err
+	}
+	gz.Multistream(false)
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	if err != nil {
+		return nil, nil, err
+	}
+	return tr, header, nil
+}
+
+// ReadPackageIndex reads the index of a v2 package without reading the rest of it. It
+// returns ErrNoPackageIndex for v1 packages.
+func ReadPackageIndex(packagePath string) (*PackageIndex, error) {
+	f, err := os.Open(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return readPackageIndex(f)
+}
+
+func readPackageIndex(f *os.File) (*PackageIndex, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open package: %w", err)
+	}
+	offset, size, err := readFooter(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	tr, header, err := openMember(f, offset, size)
+	if err != nil || header.Name != PackageIndexFileName {
+		return nil, fmt.Errorf("corrupt package index")
+	}
+	var index PackageIndex
+	if err := json.NewDecoder(io.LimitReader(tr, 64<<20)).Decode(&index); err != nil {
+		return nil, fmt.Errorf("corrupt package index: %w", err)
+	}
+	if index.FormatVersion > PackageFormatVersion {
+		return nil, fmt.Errorf("package format %d is not supported (this Axon reads up to %d)", index.FormatVersion, PackageFormatVersion)
+	}
+	return &index, nil
+}
+
+// ListPackage lists the files in a package. v2 packages are listed from their index;
+// v1 packages are read through, and their entries have no digests or offsets.
+func ListPackage(packagePath string) ([]PackageEntry, error) {
+	index, err := ReadPackageIndex(packagePath)
+	if err == nil {
+		return index.Files, nil
+	}
+	if !errors.Is(err, ErrNoPackageIndex) {
+		return nil, err
+	}
+
+	var entries []PackageEntry
+	err = walkPackage(packagePath, func(header *tar.Header, _ io.Reader) (bool, error) {
+		if header.Typeflag == tar.TypeReg {
+			entries = append(entries, PackageEntry{Path: path.Clean(filepath.ToSlash(header.Name)), Size: header.Size, Mode: header.Mode})
+		}
+		return true, nil
+	})
+	return entries, err
+}
+
+// walkPackage streams the tar entries of a package to fn until it returns false
+func walkPackage(packagePath string, fn func(*tar.Header, io.Reader) (bool, error)) error {
+	f, err := os.Open(packagePath)
+	if err != nil {
+		return fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer func() { _ = gz.Close() }()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+		more, err := fn(header, tr)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// ExtractPackageFile extracts one file of a package to dest. From a v2 package only that
+// file's member is read and the file is checked against its SHA-256 in the index; a v1
+// package is read up to the file.
+func ExtractPackageFile(packagePath, name, dest string) error {
+	name = path.Clean(strings.TrimPrefix(filepath.ToSlash(name), "./"))
+
+	f, err := os.Open(packagePath)
+	if err != nil {
+		return fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	index, err := readPackageIndex(f)
+	if errors.Is(err, ErrNoPackageIndex) {
+		found := false
+		err := walkPackage(packagePath, func(header *tar.Header, r io.Reader) (bool, error) {
+			if header.Typeflag != tar.TypeReg || path.Clean(filepath.ToSlash(header.Name)) != name {
+				return true, nil
+			}
+			found = true
+			return false, writePackageFile(r, dest, header.Mode, "")
+		})
+		if err == nil && !found {
+			return fmt.Errorf("%s is not in the package", name)
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	entry, ok := index.File(name)
+	if !ok {
+		return fmt.Errorf("%s is not in the package", name)
+	}
+	tr, header, err := openMember(f, entry.Offset, entry.CompressedSize)
+	if err != nil || header.Name != entry.Path {
+		return fmt.Errorf("corrupt package: no %s at the offset in the index", name)
+	}
+	return writePackageFile(tr, dest, entry.Mode, entry.SHA256)
+}
+
+// writePackageFile writes a package file to dest, checking its digest if one is given
+func writePackageFile(r io.Reader, dest string, mode int64, digest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(mode)&os.ModePerm|0600)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() { _ = out.Close() }()
+
+	hasher := sha256.New()
+	maxSize := archive.Settings().MaxSize
+	n, err := io.Copy(io.MultiWriter(out, hasher), io.LimitReader(r, maxSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to extract file: %w", err)
+	}
+	if n > maxSize {
+		_ = out.Close()
+		_ = os.Remove(dest)
+		return fmt.Errorf("%w: %s unpacks to more than %d bytes", archive.ErrLimitExceeded, filepath.Base(dest), maxSize)
+	}
+	if got := hex.EncodeToString(hasher.Sum(nil)); digest != "" && !strings.EqualFold(got, digest) {
+		_ = out.Close()
+		_ = os.Remove(dest)
+		return fmt.Errorf("checksum mismatch for %s (expected sha256:%s, got sha256:%s)", filepath.Base(dest), digest, got)
+	}
+	return out.Close()
+}
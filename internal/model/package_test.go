@@ -0,0 +1,442 @@
+package model
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+)
+
+// writeTestPackage creates a tar.gz package containing the given files
+func writeTestPackage(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create package: %v", err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatalf("Failed to write header: %v", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("Failed to write content: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("Failed to close tar writer: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatalf("Failed to close gzip writer: %v", err)
+	}
+}
+
+func TestExtractPackage(t *testing.T) {
+	tmpDir := t.TempDir()
+	packagePath := filepath.Join(tmpDir, "model.axon")
+	writeTestPackage(t, packagePath, map[string]string{
+		"config.json":     `{"model_type": "bert"}`,
+		"onnx/model.onnx": "onnx-bytes",
+	})
+
+	destDir := filepath.Join(tmpDir, "out")
+	if err := ExtractPackage(packagePath, destDir); err != nil {
+		t.Fatalf("ExtractPackage() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(destDir, "onnx", "model.onnx"))
+	if err != nil {
+		t.Fatalf("Extracted file missing: %v", err)
+	}
+	if string(data) != "onnx-bytes" {
+		t.Errorf("Extracted content = %q, want %q", string(data), "onnx-bytes")
+	}
+}
+
+func TestExtractPackage_PathTraversal(t *testing.T) {
+	tmpDir := t.TempDir()
+	packagePath := filepath.Join(tmpDir, "evil.axon")
+	writeTestPackage(t, packagePath, map[string]string{
+		"../escape.txt": "nope",
+	})
+
+	if err := ExtractPackage(packagePath, filepath.Join(tmpDir, "out")); err == nil {
+		t.Error("ExtractPackage() should reject entries escaping the destination")
+	}
+}
+
+func TestVerifyInventory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("onnx-bytes"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("kind: Model"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	inventory, err := WriteInventory(dir)
+	if err != nil {
+		t.Fatalf("WriteInventory() error = %v", err)
+	}
+	if len(inventory.Files) != 1 || inventory.Files[0].Path != "model.onnx" {
+		t.Fatalf("WriteInventory() files = %+v, want only model.onnx", inventory.Files)
+	}
+
+	problems, err := VerifyInventory(dir)
+	if err != nil {
+		t.Fatalf("VerifyInventory() error = %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("VerifyInventory() problems = %v, want none", problems)
+	}
+
+	// Tamper with the file
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("tampered!!"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	problems, err = VerifyInventory(dir)
+	if err != nil {
+		t.Fatalf("VerifyInventory() error = %v", err)
+	}
+	if len(problems) != 1 {
+		t.Errorf("VerifyInventory() problems = %v, want 1", problems)
+	}
+}
+
+func TestVerifyEncryptedInventory(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), []byte("onnx-bytes"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if _, err := WriteInventory(dir); err != nil {
+		t.Fatalf("WriteInventory() error = %v", err)
+	}
+	digest, err := ModelDigest(dir)
+	if err != nil {
+		t.Fatalf("ModelDigest() error = %v", err)
+	}
+
+	key, err := cachecrypt.GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if err := cachecrypt.EncryptFile(filepath.Join(dir, "model.onnx"), key); err != nil {
+		t.Fatalf("EncryptFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		key          []byte
+		wantProblems int
+	}{
+		{name: "right key", key: key, wantProblems: 0},
+		{name: "no key", key: nil, wantProblems: 1},
+		{name: "wrong key", key: make([]byte, cachecrypt.KeySize), wantProblems: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := VerifyEncryptedInventory(dir, tt.key)
+			if err != nil {
+				t.Fatalf("VerifyEncryptedInventory() error = %v", err)
+			}
+			if len(problems) != tt.wantProblems {
+				t.Errorf("VerifyEncryptedInventory() problems = %v, want %d", problems, tt.wantProblems)
+			}
+		})
+	}
+
+	// Encryption must not change the content digest Core registrations are checked against
+	if got, err := ModelDigest(dir); err != nil || got != digest {
+		t.Errorf("ModelDigest() after encryption = %q, %v; want %q", got, err, digest)
+	}
+	if file, got, err := PrimaryWeightDigest(dir); err != nil || file != "model.onnx" {
+		t.Errorf("PrimaryWeightDigest() = %q, %q, %v; want model.onnx", file, got, err)
+	}
+}
+
+func TestModelDigest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.bin"), []byte("weights"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	built, err := ModelDigest(dir)
+	if err != nil {
+		t.Fatalf("ModelDigest() error = %v", err)
+	}
+	if !strings.HasPrefix(built, "sha256:") {
+		t.Errorf("ModelDigest() = %q, want sha256: prefix", built)
+	}
+
+	// Digest from files.json must match the one computed from the tree,
+	// and must ignore Axon-managed files
+	if _, err := WriteInventory(dir); err != nil {
+		t.Fatalf("WriteInventory() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+	fromInventory, err := ModelDigest(dir)
+	if err != nil {
+		t.Fatalf("ModelDigest() error = %v", err)
+	}
+	if fromInventory != built {
+		t.Errorf("ModelDigest() = %q, want %q", fromInventory, built)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "model.bin"), []byte("other"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := WriteInventory(dir); err != nil {
+		t.Fatalf("WriteInventory() error = %v", err)
+	}
+	changed, _ := ModelDigest(dir)
+	if changed == built {
+		t.Error("ModelDigest() should change when model content changes")
+	}
+}
+
+func TestWritePackage(t *testing.T) {
+	srcDir := t.TempDir()
+	writeTestTree(t, srcDir, map[string]string{
+		"config.json":         `{"model_type": "bert"}`,
+		"weights/model.bin":   strings.Repeat("weights", 10000),
+		"tokenizer/vocab.txt": "[CLS]\n",
+	})
+	packagePath := filepath.Join(t.TempDir(), "model.axon")
+	written, err := WritePackage(srcDir, packagePath)
+	if err != nil {
+		t.Fatalf("WritePackage() error = %v", err)
+	}
+
+	index, err := ReadPackageIndex(packagePath)
+	if err != nil {
+		t.Fatalf("ReadPackageIndex() error = %v", err)
+	}
+	if index.FormatVersion != PackageFormatVersion || len(index.Files) != 3 || len(written.Index.Files) != 3 {
+		t.Errorf("index = %+v", index)
+	}
+	// The digest is taken as the package is written
+	data, err := os.ReadFile(packagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sum := sha256.Sum256(data); written.SHA256 != hex.EncodeToString(sum[:]) || written.Size != int64(len(data)) {
+		t.Errorf("written digest = sha256:%s (%d bytes), want sha256:%x (%d bytes)", written.SHA256, written.Size, sum, len(data))
+	}
+	entry, ok := index.File("weights/model.bin")
+	if !ok || entry.Size != 70000 || entry.SHA256 == "" || entry.CompressedSize == 0 {
+		t.Errorf("weights entry = %+v, %v", entry, ok)
+	}
+
+	// Readers of v1 packages see a plain tar.gz, without the index
+	destDir := t.TempDir()
+	if err := ExtractPackage(packagePath, destDir); err != nil {
+		t.Fatalf("ExtractPackage() error = %v", err)
+	}
+	if got := listTestTree(t, destDir); strings.Join(got, ",") != "config.json,tokenizer/vocab.txt,weights/model.bin" {
+		t.Errorf("extracted %v", got)
+	}
+
+	dest := filepath.Join(t.TempDir(), "config.json")
+	if err := ExtractPackageFile(packagePath, "./config.json", dest); err != nil {
+		t.Fatalf("ExtractPackageFile() error = %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != `{"model_type": "bert"}` {
+		t.Errorf("config.json = %q, %v", data, err)
+	}
+	if err := ExtractPackageFile(packagePath, "missing.json", dest); err == nil {
+		t.Error("ExtractPackageFile() of a missing file succeeded")
+	}
+
+	// A damaged member is caught by its checksum or by gzip
+	data, err = os.ReadFile(packagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[entry.Offset+entry.CompressedSize/2] ^= 0xff
+	damaged := filepath.Join(t.TempDir(), "damaged.axon")
+	if err := os.WriteFile(damaged, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ExtractPackageFile(damaged, "weights/model.bin", filepath.Join(t.TempDir(), "model.bin")); err == nil {
+		t.Error("ExtractPackageFile() of a damaged file succeeded")
+	}
+}
+
+func TestWritePackage_Reproducible(t *testing.T) {
+	files := map[string]string{"a.txt": "a", "a/b": "b", "config.json": "{}"}
+	build := func(perm os.FileMode, mtime time.Time) []byte {
+		srcDir := t.TempDir()
+		writeTestTree(t, srcDir, files)
+		for name := range files {
+			p := filepath.Join(srcDir, filepath.FromSlash(name))
+			if err := os.Chmod(p, perm); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(p, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+		packagePath := filepath.Join(t.TempDir(), "model.axon")
+		if _, err := WritePackage(srcDir, packagePath); err != nil {
+			t.Fatalf("WritePackage() error = %v", err)
+		}
+		data, err := os.ReadFile(packagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	first := build(0644, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	second := build(0600, time.Now())
+	if !bytes.Equal(first, second) {
+		t.Fatal("the same files gave different packages")
+	}
+
+	// Entries in path order, with nothing but the path, size, mode and package time
+	gz, err := gzip.NewReader(bytes.NewReader(first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+		if !header.ModTime.Equal(time.Unix(0, 0)) || header.Uid != 0 || header.Gid != 0 || header.Uname != "" || header.Mode != 0644 {
+			t.Errorf("%s: header = %+v, want canonical", header.Name, header)
+		}
+	}
+	if got := strings.Join(names, ","); got != "a.txt,a/b,config.json,"+PackageIndexFileName {
+		t.Errorf("entries = %s, want path order", got)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	written, err := WritePackage(t.TempDir(), filepath.Join(t.TempDir(), "empty.axon"))
+	if err != nil {
+		t.Fatalf("WritePackage() error = %v", err)
+	}
+	if index := written.Index; !index.CreatedAt.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("CreatedAt = %s, want $SOURCE_DATE_EPOCH", index.CreatedAt)
+	}
+}
+
+func TestPackageV1Compatibility(t *testing.T) {
+	packagePath := filepath.Join(t.TempDir(), "model.axon")
+	writeTestPackage(t, packagePath, map[string]string{"config.json": "{}", "model.onnx": "onnx"})
+
+	if _, err := ReadPackageIndex(packagePath); err != ErrNoPackageIndex {
+		t.Errorf("ReadPackageIndex() error = %v, want ErrNoPackageIndex", err)
+	}
+	entries, err := ListPackage(packagePath)
+	if err != nil || len(entries) != 2 {
+		t.Errorf("ListPackage() = %+v, %v", entries, err)
+	}
+	dest := filepath.Join(t.TempDir(), "model.onnx")
+	if err := ExtractPackageFile(packagePath, "model.onnx", dest); err != nil {
+		t.Fatalf("ExtractPackageFile() error = %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "onnx" {
+		t.Errorf("model.onnx = %q, %v", data, err)
+	}
+}
+
+func TestUpdatePackage(t *testing.T) {
+	weights := strings.Repeat("weights ", 4096)
+	baseDir := t.TempDir()
+	writeTestTree(t, baseDir, map[string]string{"config.json": "{}", "model.safetensors": weights, "notes.txt": "v1"})
+	basePath := filepath.Join(t.TempDir(), "base.axon")
+	if _, err := WritePackage(baseDir, basePath); err != nil {
+		t.Fatalf("WritePackage() error = %v", err)
+	}
+
+	// A converted model: one file added, one changed, the weights untouched
+	srcDir := t.TempDir()
+	writeTestTree(t, srcDir, map[string]string{"config.json": "{}", "model.safetensors": weights, "notes.txt": "v2", "model.onnx": "onnx"})
+	fullPath := filepath.Join(t.TempDir(), "full.axon")
+	if _, err := WritePackage(srcDir, fullPath); err != nil {
+		t.Fatalf("WritePackage() error = %v", err)
+	}
+	full, err := os.ReadFile(fullPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	update := func(basePath string) ([]byte, int) {
+		t.Helper()
+		destPath := filepath.Join(t.TempDir(), "model.axon")
+		written, err := UpdatePackage(basePath, srcDir, destPath)
+		if err != nil {
+			t.Fatalf("UpdatePackage() error = %v", err)
+		}
+		data, err := os.ReadFile(destPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sum := sha256.Sum256(data); written.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("written digest = sha256:%s, want sha256:%x", written.SHA256, sum)
+		}
+		return data, written.Reused
+	}
+
+	data, reused := update(basePath)
+	if reused != 2 {
+		t.Errorf("reused %d files, want 2 (config.json and model.safetensors)", reused)
+	}
+	if !bytes.Equal(data, full) {
+		t.Error("updated package differs from a full build")
+	}
+
+	// A member that doesn't match the index is compressed again
+	index, err := ReadPackageIndex(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := index.File("model.safetensors")
+	base, err := os.ReadFile(basePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	base[entry.Offset+entry.CompressedSize/2] ^= 0xff
+	corruptPath := filepath.Join(t.TempDir(), "corrupt.axon")
+	if err := os.WriteFile(corruptPath, base, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, reused := update(corruptPath); reused != 0 || !bytes.Equal(data, full) {
+		t.Errorf("update of a corrupt package reused %d files, equal to a full build = %v", reused, bytes.Equal(data, full))
+	}
+
+	// So is everything when the package time changed, or the base is missing
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	if _, reused := update(basePath); reused != 0 {
+		t.Errorf("reused %d files of a package with another package time", reused)
+	}
+	if _, reused := update(filepath.Join(t.TempDir(), "missing.axon")); reused != 0 {
+		t.Errorf("reused %d files of a missing package", reused)
+	}
+}
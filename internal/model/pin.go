@@ -0,0 +1,136 @@
+package model
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/cachecrypt"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// DigestPinPrefix marks a model spec version that pins a content digest
+// instead of naming a version, e.g. hf/bert-base-uncased@sha256:abcd...
+const DigestPinPrefix = spec.DigestPrefix
+
+// MinDigestPinLength is the shortest digest prefix accepted in a pin
+const MinDigestPinLength = spec.MinDigestLength
+
+// weightExtensions lists the file extensions that hold model weights
+var weightExtensions = []string{".safetensors", ".bin", ".gguf", ".onnx", ".pt", ".pth", ".h5", ".msgpack", ".tflite", ".mlmodel"}
+
+// ParseDigestPin reports whether version is a digest pin and returns the pinned
+// (lowercase hex) digest. Abbreviated digests of at least MinDigestPinLength characters are accepted.
+func ParseDigestPin(version string) (string, bool, error) {
+	digest, pinned, err := spec.ParseDigest(version)
+	if err != nil {
+		return "", true, fmt.Errorf("invalid digest pin %q (%v)", version, err)
+	}
+	return digest, pinned, nil
+}
+
+// PinnedSpec formats a model spec pinned to a content digest
+func PinnedSpec(namespace, name, digest string) string {
+	return fmt.Sprintf("%s/%s@%s%s", namespace, name, DigestPinPrefix, strings.TrimPrefix(digest, DigestPinPrefix))
+}
+
+// IsWeightFile reports whether a file holds model weights
+func IsWeightFile(path string) bool {
+	lower := strings.ToLower(path)
+	for _, ext := range weightExtensions {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrimaryWeightFile returns the path (relative to dir) of the largest weight file in an
+// extracted model directory. Ties are broken by path so the choice is deterministic.
+// An encrypted weight file is reported under its plaintext name.
+func PrimaryWeightFile(dir string) (string, error) {
+	var primary string
+	var primarySize int64 = -1
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = strings.TrimSuffix(filepath.ToSlash(relPath), cachecrypt.Suffix)
+		if isInventoryExcluded(relPath) || !IsWeightFile(relPath) {
+			return nil
+		}
+		if info.Size() > primarySize || (info.Size() == primarySize && relPath < primary) {
+			primary, primarySize = relPath, info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to scan model files: %w", err)
+	}
+	if primary == "" {
+		return "", fmt.Errorf("no weight files found in %s", dir)
+	}
+	return primary, nil
+}
+
+// PrimaryWeightDigest returns the SHA256 of the primary weight file in dir. The digest
+// of an encrypted weight file is the plaintext digest recorded in files.json.
+func PrimaryWeightDigest(dir string) (file, digest string, err error) {
+	file, err = PrimaryWeightFile(dir)
+	if err != nil {
+		return "", "", err
+	}
+	path := filepath.Join(dir, filepath.FromSlash(file))
+	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+		if entry := inventoryEntry(dir, file); entry != nil {
+			return file, entry.SHA256, nil
+		}
+		return "", "", fmt.Errorf("%s is encrypted and has no recorded digest in %s", file, InventoryFileName)
+	}
+	digest, err = utils.ComputeSHA256(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash %s: %w", file, err)
+	}
+	return file, digest, nil
+}
+
+// VerifyDigestPin checks a pinned digest against the package at packagePath and, if dir
+// is non-empty, against the primary weight file extracted there. It returns a description
+// of what matched, or an error listing the digests that were found.
+func VerifyDigestPin(pin, packagePath, dir string) (string, error) {
+	var found []string
+
+	if packagePath != "" {
+		digest, err := utils.ComputeSHA256(packagePath)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash package: %w", err)
+		}
+		if strings.HasPrefix(digest, pin) {
+			return "package " + filepath.Base(packagePath), nil
+		}
+		found = append(found, "package="+digest)
+	}
+
+	if dir != "" {
+		file, digest, err := PrimaryWeightDigest(dir)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(digest, pin) {
+			return "weights " + file, nil
+		}
+		found = append(found, file+"="+digest)
+	}
+
+	return "", fmt.Errorf("digest mismatch: pinned %s%s, got %s", DigestPinPrefix, pin, strings.Join(found, ", "))
+}
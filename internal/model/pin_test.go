@@ -0,0 +1,96 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+func TestParseDigestPin(t *testing.T) {
+	full := strings.Repeat("ab", 32)
+	tests := []struct {
+		version    string
+		wantDigest string
+		wantPinned bool
+		wantErr    bool
+	}{
+		{"latest", "", false, false},
+		{"1.0.0", "", false, false},
+		{"sha256:" + full, full, true, false},
+		{"SHA256:ABCDEF012345", "abcdef012345", true, false},
+		{"sha256:abcd", "", true, true},
+		{"sha256:" + full + "00", "", true, true},
+		{"sha256:zzzzzzzzzzzz", "", true, true},
+	}
+
+	for _, tt := range tests {
+		digest, pinned, err := ParseDigestPin(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDigestPin(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			continue
+		}
+		if digest != tt.wantDigest || pinned != tt.wantPinned {
+			t.Errorf("ParseDigestPin(%q) = (%q, %v), want (%q, %v)", tt.version, digest, pinned, tt.wantDigest, tt.wantPinned)
+		}
+	}
+}
+
+func TestPinnedSpec(t *testing.T) {
+	want := "hf/bert-base-uncased@sha256:abc123"
+	if got := PinnedSpec("hf", "bert-base-uncased", "abc123"); got != want {
+		t.Errorf("PinnedSpec() = %q, want %q", got, want)
+	}
+	if got := PinnedSpec("hf", "bert-base-uncased", "sha256:abc123"); got != want {
+		t.Errorf("PinnedSpec() with prefix = %q, want %q", got, want)
+	}
+}
+
+func TestVerifyDigestPin(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json":              `{"model_type": "bert"}`,
+		"model.safetensors":        "the primary weights",
+		"onnx/model.onnx":          "small",
+		"manifest.yaml":            "this manifest is larger than every weight file",
+		"tiny-model-latest.axon":   "package bytes",
+		"tokenizer/vocab.txt":      "vocab",
+		"text_encoder/weights.bin": "w",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	primary, err := PrimaryWeightFile(dir)
+	if err != nil {
+		t.Fatalf("PrimaryWeightFile() error = %v", err)
+	}
+	if primary != "model.safetensors" {
+		t.Errorf("PrimaryWeightFile() = %q, want model.safetensors", primary)
+	}
+
+	packagePath := filepath.Join(dir, "tiny-model-latest.axon")
+	packageDigest, _ := utils.ComputeSHA256(packagePath)
+	weightDigest, _ := utils.ComputeSHA256(filepath.Join(dir, "model.safetensors"))
+
+	if matched, err := VerifyDigestPin(packageDigest, packagePath, dir); err != nil || !strings.HasPrefix(matched, "package") {
+		t.Errorf("VerifyDigestPin(package) = (%q, %v), want package match", matched, err)
+	}
+	if matched, err := VerifyDigestPin(weightDigest[:16], packagePath, dir); err != nil || !strings.HasPrefix(matched, "weights") {
+		t.Errorf("VerifyDigestPin(weights prefix) = (%q, %v), want weights match", matched, err)
+	}
+	if _, err := VerifyDigestPin(weightDigest, packagePath, ""); err == nil {
+		t.Error("VerifyDigestPin() without extracted files should only check the package")
+	}
+	if _, err := VerifyDigestPin(strings.Repeat("0", 64), packagePath, dir); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Errorf("VerifyDigestPin(wrong) error = %v, want digest mismatch", err)
+	}
+}
@@ -0,0 +1,225 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// weightFamilies groups weight files by serialization format. Repositories often ship the
+// same weights in several formats, so only the largest family counts as the weights.
+var weightFamilies = map[string]string{
+	".safetensors": "safetensors",
+	".bin":         "pytorch",
+	".pt":          "pytorch",
+	".pth":         "pytorch",
+	".ckpt":        "pytorch",
+	".h5":          "tensorflow",
+	".pb":          "tensorflow",
+	".msgpack":     "flax",
+	".onnx":        "onnx",
+	".onnx_data":   "onnx",
+	".gguf":        "gguf",
+}
+
+// bytesPerParameter is the weight size assumed when only the parameter count is known
+const bytesPerParameter = 4
+
+// gib is the number of bytes in a GB of requirements
+const gib = 1 << 30
+
+// DefaultRequirements are the requirements adapters declare for a model whose size they
+// don't know
+func DefaultRequirements() types.Requirements {
+	return types.Requirements{
+		Compute: types.Compute{
+			CPU:    types.CPURequirement{MinCores: 2, RecommendedCores: 4},
+			Memory: types.MemoryRequirement{MinGB: 2.0, RecommendedGB: 4.0},
+		},
+	}
+}
+
+// IsPlaceholderRequirements reports whether requirements are unset or the defaults of an
+// adapter that didn't know the model's size, so an estimate should replace them
+func IsPlaceholderRequirements(r types.Requirements) bool {
+	defaults := DefaultRequirements()
+	return r.Compute.GPU == nil && r.Storage == (types.Storage{}) &&
+		(r.Compute == (types.Compute{}) || r.Compute.CPU == defaults.Compute.CPU && r.Compute.Memory == defaults.Compute.Memory)
+}
+
+// WeightBytes returns the size of the weights among files: the largest family of weight
+// files, so weights shipped as both pytorch_model.bin and model.safetensors count once
+func WeightBytes(files []types.ModelFile) int64 {
+	totals := make(map[string]int64)
+	for _, file := range files {
+		if family, ok := weightFamilies[strings.ToLower(filepath.Ext(file.Path))]; ok {
+			totals[family] += file.Size
+		}
+	}
+	var largest int64
+	for _, total := range totals {
+		largest = max(largest, total)
+	}
+	return largest
+}
+
+// EstimateRequirements estimates the hardware a model needs from the sizes of its files,
+// or, when no weight sizes are known, from its parameter count (0 if unknown). It
+// returns false when neither is known.
+//
+// Memory is the weights plus runtime overhead at minimum, and twice the weights
+// recommended, leaving room for activations and for ONNX conversion, which holds the
+// source and exported weights at once. Storage leaves room for the converted copy.
+// Models of 4GB of weights and more recommend a GPU with room for the weights.
+func EstimateRequirements(files []types.ModelFile, parameters int64) (types.Requirements, bool) {
+	weights := WeightBytes(files)
+	if weights == 0 {
+		weights = parameters * bytesPerParameter
+	}
+	if weights <= 0 {
+		return DefaultRequirements(), false
+	}
+	var total int64
+	for _, file := range files {
+		total += file.Size
+	}
+	total = max(total, weights)
+
+	weightsGB := float64(weights) / gib
+	r := types.Requirements{
+		Compute: types.Compute{
+			Memory: types.MemoryRequirement{
+				MinGB:         roundUpGB(weightsGB*1.2 + 0.5),
+				RecommendedGB: roundUpGB(weightsGB*2 + 1),
+			},
+		},
+		Storage: types.Storage{
+			MinGB:         roundUpGB(float64(total) / gib),
+			RecommendedGB: roundUpGB(float64(total+weights) / gib),
+		},
+	}
+	switch {
+	case weightsGB < 1:
+		r.Compute.CPU = types.CPURequirement{MinCores: 2, RecommendedCores: 4}
+	case weightsGB < 8:
+		r.Compute.CPU = types.CPURequirement{MinCores: 4, RecommendedCores: 8}
+	default:
+		r.Compute.CPU = types.CPURequirement{MinCores: 8, RecommendedCores: 16}
+	}
+	if weightsGB >= 4 {
+		r.Compute.GPU = &types.GPURequirement{Recommended: true, MinVRAMGB: roundUpGB(weightsGB * 1.2)}
+	}
+	return r, true
+}
+
+// EstimateDirRequirements estimates the requirements of an installed model from the
+// files in its directory
+func EstimateDirRequirements(dir string) (types.Requirements, bool) {
+	var files []types.ModelFile
+	_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			files = append(files, types.ModelFile{Path: path, Size: info.Size()})
+		}
+		return nil
+	})
+	return EstimateRequirements(files, 0)
+}
+
+// formatAccelerators lists the GPU platforms runtimes execute each execution format on
+var formatAccelerators = map[string][]string{
+	"onnx":        {types.AcceleratorCUDA, types.AcceleratorROCm},
+	"gguf":        {types.AcceleratorCUDA, types.AcceleratorROCm, types.AcceleratorMetal},
+	"pytorch":     {types.AcceleratorCUDA, types.AcceleratorROCm, types.AcceleratorMetal},
+	"safetensors": {types.AcceleratorCUDA, types.AcceleratorROCm, types.AcceleratorMetal},
+	"tensorflow":  {types.AcceleratorCUDA},
+}
+
+// quantizationGPUs are the GPUs quantized checkpoints need, by the quant_method of their
+// config.json: their weights only load into these platforms' GPU kernels, and FP8 and
+// AWQ kernels need recent NVIDIA architectures (Ada, Turing)
+var quantizationGPUs = map[string]types.GPURequirement{
+	"gptq":         {Required: true, MinComputeCapability: "6.0", Accelerators: []string{types.AcceleratorCUDA, types.AcceleratorROCm}},
+	"awq":          {Required: true, MinComputeCapability: "7.5", Accelerators: []string{types.AcceleratorCUDA}},
+	"fp8":          {Required: true, MinComputeCapability: "8.9", Accelerators: []string{types.AcceleratorCUDA}},
+	"bitsandbytes": {Required: true, Accelerators: []string{types.AcceleratorCUDA}},
+}
+
+// DetectGPURequirement completes the GPU requirement of a model from its config.json
+// and execution format: quantized checkpoints that only run on GPU kernels require a
+// GPU, and a recommended or required GPU lists the accelerators the execution format
+// runs on. Fields already set, e.g. by the publisher, are kept.
+func DetectGPURequirement(r *types.Requirements, config []byte, executionFormat string) {
+	var parsed struct {
+		QuantizationConfig struct {
+			QuantMethod string `json:"quant_method"`
+		} `json:"quantization_config"`
+	}
+	if len(config) > 0 && json.Unmarshal(config, &parsed) == nil {
+		if quantized, ok := quantizationGPUs[strings.ToLower(parsed.QuantizationConfig.QuantMethod)]; ok {
+			if r.Compute.GPU == nil {
+				r.Compute.GPU = &types.GPURequirement{}
+			}
+			gpu := r.Compute.GPU
+			gpu.Required = true
+			if gpu.MinComputeCapability == "" {
+				gpu.MinComputeCapability = quantized.MinComputeCapability
+			}
+			if len(gpu.Accelerators) == 0 {
+				gpu.Accelerators = slices.Clone(quantized.Accelerators)
+			}
+		}
+	}
+
+	gpu := r.Compute.GPU
+	if gpu != nil && (gpu.Required || gpu.Recommended) && len(gpu.Accelerators) == 0 {
+		gpu.Accelerators = slices.Clone(formatAccelerators[executionFormat])
+	}
+}
+
+// DetectDirGPURequirement completes the GPU requirement of an installed model from its
+// config.json, at the root or in the canonical config/ directory
+func DetectDirGPURequirement(r *types.Requirements, modelPath, executionFormat string) {
+	config, _ := os.ReadFile(LayoutFile(modelPath, "config.json"))
+	DetectGPURequirement(r, config, executionFormat)
+}
+
+// ParseComputeCapability parses a CUDA compute capability such as "8.6"
+func ParseComputeCapability(s string) (major, minor int, err error) {
+	majorText, minorText, ok := strings.Cut(strings.TrimSpace(s), ".")
+	if ok {
+		major, err = strconv.Atoi(majorText)
+	}
+	if ok && err == nil {
+		minor, err = strconv.Atoi(minorText)
+	}
+	if !ok || err != nil || major < 0 || minor < 0 {
+		return 0, 0, fmt.Errorf("invalid compute capability %q (expected major.minor, e.g. 8.0)", s)
+	}
+	return major, minor, nil
+}
+
+// ComputeCapabilityAtLeast reports whether compute capability have meets need. Values
+// that don't parse are treated as meeting it, as nothing can be told from them.
+func ComputeCapabilityAtLeast(have, need string) bool {
+	haveMajor, haveMinor, err := ParseComputeCapability(have)
+	if err != nil {
+		return true
+	}
+	needMajor, needMinor, err := ParseComputeCapability(need)
+	if err != nil {
+		return true
+	}
+	return haveMajor > needMajor || haveMajor == needMajor && haveMinor >= needMinor
+}
+
+// roundUpGB rounds a size in GB up to a tenth, and to at least 0.1
+func roundUpGB(gb float64) float64 {
+	return math.Max(math.Ceil(gb*10)/10, 0.1)
+}
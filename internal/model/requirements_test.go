@@ -0,0 +1,177 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestEstimateRequirements(t *testing.T) {
+	const gb = 1 << 30
+
+	tests := []struct {
+		name       string
+		files      []types.ModelFile
+		parameters int64
+		wantOK     bool
+		wantMemory types.MemoryRequirement
+		wantCores  int
+		wantGPU    bool
+	}{
+		{
+			name:       "small model, weights in two formats count once",
+			files:      []types.ModelFile{{Path: "model.safetensors", Size: gb / 2}, {Path: "pytorch_model.bin", Size: gb / 2}, {Path: "config.json", Size: 600}},
+			wantOK:     true,
+			wantMemory: types.MemoryRequirement{MinGB: 1.1, RecommendedGB: 2.0},
+			wantCores:  2,
+		},
+		{
+			name:       "sharded 7B model",
+			files:      []types.ModelFile{{Path: "model-00001-of-00002.safetensors", Size: 7 * gb}, {Path: "model-00002-of-00002.safetensors", Size: 7 * gb}},
+			wantOK:     true,
+			wantMemory: types.MemoryRequirement{MinGB: 17.3, RecommendedGB: 29.0},
+			wantCores:  8,
+			wantGPU:    true,
+		},
+		{
+			name:       "parameter count only",
+			files:      []types.ModelFile{{Path: "model.safetensors"}},
+			parameters: gb / 4,
+			wantOK:     true,
+			wantMemory: types.MemoryRequirement{MinGB: 1.7, RecommendedGB: 3.0},
+			wantCores:  4,
+		},
+		{name: "unknown size", files: []types.ModelFile{{Path: "model.safetensors"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := EstimateRequirements(tt.files, tt.parameters)
+			if ok != tt.wantOK {
+				t.Fatalf("EstimateRequirements() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				if !reflect.DeepEqual(got, DefaultRequirements()) {
+					t.Errorf("EstimateRequirements() = %+v, want the defaults", got)
+				}
+				return
+			}
+			if got.Compute.Memory != tt.wantMemory {
+				t.Errorf("Memory = %+v, want %+v", got.Compute.Memory, tt.wantMemory)
+			}
+			if got.Compute.CPU.MinCores != tt.wantCores {
+				t.Errorf("MinCores = %d, want %d", got.Compute.CPU.MinCores, tt.wantCores)
+			}
+			if (got.Compute.GPU != nil) != tt.wantGPU {
+				t.Errorf("GPU = %+v, wantGPU %v", got.Compute.GPU, tt.wantGPU)
+			}
+			if got.Storage.MinGB <= 0 || got.Storage.RecommendedGB < got.Storage.MinGB {
+				t.Errorf("Storage = %+v", got.Storage)
+			}
+			if IsPlaceholderRequirements(got) {
+				t.Error("an estimate counts as placeholder requirements")
+			}
+		})
+	}
+}
+
+func TestIsPlaceholderRequirements(t *testing.T) {
+	if !IsPlaceholderRequirements(DefaultRequirements()) || !IsPlaceholderRequirements(types.Requirements{}) {
+		t.Error("defaults and unset requirements should be placeholders")
+	}
+	published := DefaultRequirements()
+	published.Compute.Memory.MinGB = 8
+	if IsPlaceholderRequirements(published) {
+		t.Error("requirements set by the publisher were treated as placeholders")
+	}
+}
+
+func TestEstimateDirRequirements(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "model.onnx"), make([]byte, 2<<20), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := EstimateDirRequirements(dir)
+	if !ok || got.Compute.Memory.MinGB != 0.6 {
+		t.Errorf("EstimateDirRequirements() = %+v, %v", got, ok)
+	}
+	if _, ok := EstimateDirRequirements(t.TempDir()); ok {
+		t.Error("EstimateDirRequirements() of an empty directory succeeded")
+	}
+}
+
+func TestDetectGPURequirement(t *testing.T) {
+	tests := []struct {
+		name            string
+		gpu             *types.GPURequirement
+		config          string
+		executionFormat string
+		want            *types.GPURequirement
+	}{
+		{
+			name:            "small model",
+			config:          `{"model_type": "bert"}`,
+			executionFormat: "onnx",
+		},
+		{
+			name:            "large model",
+			gpu:             &types.GPURequirement{Recommended: true, MinVRAMGB: 9.6},
+			executionFormat: "gguf",
+			want:            &types.GPURequirement{Recommended: true, MinVRAMGB: 9.6, Accelerators: []string{"cuda", "rocm", "metal"}},
+		},
+		{
+			name:            "fp8 checkpoint",
+			config:          `{"quantization_config": {"quant_method": "fp8", "activation_scheme": "dynamic"}}`,
+			executionFormat: "safetensors",
+			want:            &types.GPURequirement{Required: true, MinComputeCapability: "8.9", Accelerators: []string{"cuda"}},
+		},
+		{
+			name:            "publisher's requirement kept",
+			gpu:             &types.GPURequirement{Recommended: true, MinComputeCapability: "9.0", Accelerators: []string{"cuda"}},
+			config:          `{"quantization_config": {"quant_method": "GPTQ", "bits": 4}}`,
+			executionFormat: "pytorch",
+			want:            &types.GPURequirement{Required: true, Recommended: true, MinComputeCapability: "9.0", Accelerators: []string{"cuda"}},
+		},
+		{
+			name:   "format not known yet",
+			gpu:    &types.GPURequirement{Recommended: true},
+			config: `not json`,
+			want:   &types.GPURequirement{Recommended: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := types.Requirements{Compute: types.Compute{GPU: tt.gpu}}
+			DetectGPURequirement(&r, []byte(tt.config), tt.executionFormat)
+			if !reflect.DeepEqual(r.Compute.GPU, tt.want) {
+				t.Errorf("GPU = %+v, want %+v", r.Compute.GPU, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeCapabilityAtLeast(t *testing.T) {
+	tests := []struct {
+		have, need string
+		want       bool
+	}{
+		{"8.9", "8.9", true},
+		{"9.0", "8.9", true},
+		{"8.6", "8.9", false},
+		{"7.5", "8.0", false},
+		{"10.0", "9.0", true},
+		{"", "8.0", true},
+	}
+	for _, tt := range tests {
+		if got := ComputeCapabilityAtLeast(tt.have, tt.need); got != tt.want {
+			t.Errorf("ComputeCapabilityAtLeast(%q, %q) = %v, want %v", tt.have, tt.need, got, tt.want)
+		}
+	}
+	if _, _, err := ParseComputeCapability("8"); err == nil {
+		t.Error("ParseComputeCapability(\"8\") succeeded")
+	}
+}
@@ -0,0 +1,175 @@
+package model
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Index files of sharded checkpoints, by the weight format they shard
+var shardIndexFiles = map[string]string{
+	"safetensors": "model.safetensors.index.json",
+	"pytorch":     "pytorch_model.bin.index.json",
+}
+
+// ShardIndexFile returns the index file of a sharded checkpoint in a weight format
+// (safetensors or pytorch), or "" for formats that aren't sharded this way
+func ShardIndexFile(format string) string {
+	return shardIndexFiles[format]
+}
+
+// maxSafetensorsHeader bounds the JSON header read from a safetensors file
+const maxSafetensorsHeader = 100 << 20
+
+// ShardIndex is a parsed sharded checkpoint index (model.safetensors.index.json or
+// pytorch_model.bin.index.json), which maps every tensor to the shard holding it
+type ShardIndex struct {
+	Name      string            // Path of the index file in the repository
+	TotalSize int64             // metadata.total_size
+	WeightMap map[string]string // Tensor name -> shard path, relative to the index
+}
+
+// ParseShardIndex parses the index file name
+func ParseShardIndex(name string, data []byte) (*ShardIndex, error) {
+	var parsed struct {
+		Metadata struct {
+			TotalSize json.Number `json:"total_size"` // Some exporters write a float
+		} `json:"metadata"`
+		WeightMap map[string]string `json:"weight_map"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	if len(parsed.WeightMap) == 0 {
+		return nil, fmt.Errorf("%s maps no tensors", name)
+	}
+	index := &ShardIndex{Name: name, WeightMap: parsed.WeightMap}
+	if size, err := parsed.Metadata.TotalSize.Float64(); err == nil {
+		index.TotalSize = int64(size)
+	}
+	return index, nil
+}
+
+// Shards returns the paths of the shards the index references, relative to the
+// repository root, in order
+func (i *ShardIndex) Shards() []string {
+	seen := make(map[string]bool)
+	var shards []string
+	for _, shard := range i.WeightMap {
+		p := i.shardPath(shard)
+		if !seen[p] {
+			seen[p] = true
+			shards = append(shards, p)
+		}
+	}
+	sort.Strings(shards)
+	return shards
+}
+
+// Tensors returns the tensors the index maps to a shard (a path from Shards)
+func (i *ShardIndex) Tensors(shard string) []string {
+	var tensors []string
+	for tensor, s := range i.WeightMap {
+		if i.shardPath(s) == shard {
+			tensors = append(tensors, tensor)
+		}
+	}
+	sort.Strings(tensors)
+	return tensors
+}
+
+// shardPath resolves a shard named in the index against the index's directory
+func (i *ShardIndex) shardPath(shard string) string {
+	return path.Join(path.Dir(i.Name), shard)
+}
+
+// Missing returns the referenced shards that aren't among files
+func (i *ShardIndex) Missing(files []string) []string {
+	have := make(map[string]bool, len(files))
+	for _, f := range files {
+		have[f] = true
+	}
+	var missing []string
+	for _, shard := range i.Shards() {
+		if !have[shard] {
+			missing = append(missing, shard)
+		}
+	}
+	return missing
+}
+
+// ShardMap returns the manifest record of the checkpoint
+func (i *ShardIndex) ShardMap() *types.ShardMap {
+	m := &types.ShardMap{Index: i.Name, TotalSize: i.TotalSize}
+	for _, shard := range i.Shards() {
+		m.Shards = append(m.Shards, types.Shard{Path: shard, Tensors: len(i.Tensors(shard))})
+	}
+	return m
+}
+
+// CheckSafetensorsShard checks that the safetensors file at filePath is complete: its
+// header parses, holds every tensor in tensors, and its data is as long as the header
+// says. Truncated or mismatched shards fail here instead of when the model is loaded.
+func CheckSafetensorsShard(filePath string, tensors []string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	var headerSize uint64
+	if err := binary.Read(f, binary.LittleEndian, &headerSize); err != nil {
+		return fmt.Errorf("failed to read safetensors header: %w", err)
+	}
+	if headerSize > maxSafetensorsHeader || int64(headerSize)+8 > info.Size() {
+		return fmt.Errorf("invalid safetensors header size %d", headerSize)
+	}
+	var header map[string]json.RawMessage
+	if err := json.NewDecoder(io.LimitReader(f, int64(headerSize))).Decode(&header); err != nil {
+		return fmt.Errorf("failed to parse safetensors header: %w", err)
+	}
+
+	var missing []string
+	for _, tensor := range tensors {
+		if _, ok := header[tensor]; !ok {
+			missing = append(missing, tensor)
+		}
+	}
+	if len(missing) > 0 {
+		if len(missing) > 3 {
+			missing = append(missing[:3], fmt.Sprintf("and %d more", len(missing)-3))
+		}
+		return fmt.Errorf("missing tensors %s", strings.Join(missing, ", "))
+	}
+
+	// The data ends where the furthest tensor does
+	var end int64
+	for name, raw := range header {
+		if name == "__metadata__" {
+			continue
+		}
+		var tensor struct {
+			DataOffsets [2]int64 `json:"data_offsets"`
+		}
+		if json.Unmarshal(raw, &tensor) == nil && tensor.DataOffsets[1] > end {
+			end = tensor.DataOffsets[1]
+		}
+	}
+	if size := info.Size() - 8 - int64(headerSize); size < end {
+		return fmt.Errorf("truncated: %d bytes of tensor data, header declares %d", size, end)
+	}
+	return nil
+}
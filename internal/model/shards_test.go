@@ -0,0 +1,91 @@
+package model
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// writeSafetensors writes a safetensors file with the given header and dataLen bytes of data
+func writeSafetensors(t *testing.T, header string, dataLen int) string {
+	t.Helper()
+	data := binary.LittleEndian.AppendUint64(nil, uint64(len(header)))
+	data = append(data, header...)
+	data = append(data, make([]byte, dataLen)...)
+	p := filepath.Join(t.TempDir(), "model.safetensors")
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseShardIndex(t *testing.T) {
+	index, err := ParseShardIndex("text_encoder/model.safetensors.index.json", []byte(`{
+		"metadata": {"total_size": 2.5e9},
+		"weight_map": {
+			"embed.weight": "model-00001-of-00002.safetensors",
+			"layer.0.weight": "model-00001-of-00002.safetensors",
+			"lm_head.weight": "model-00002-of-00002.safetensors"
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseShardIndex() error = %v", err)
+	}
+
+	wantShards := []string{"text_encoder/model-00001-of-00002.safetensors", "text_encoder/model-00002-of-00002.safetensors"}
+	if got := index.Shards(); !reflect.DeepEqual(got, wantShards) {
+		t.Errorf("Shards() = %v, want %v", got, wantShards)
+	}
+	if got := index.Tensors(wantShards[0]); !reflect.DeepEqual(got, []string{"embed.weight", "layer.0.weight"}) {
+		t.Errorf("Tensors() = %v", got)
+	}
+	if got := index.Missing([]string{wantShards[1], "config.json"}); !reflect.DeepEqual(got, wantShards[:1]) {
+		t.Errorf("Missing() = %v, want %v", got, wantShards[:1])
+	}
+
+	want := &types.ShardMap{
+		Index:     "text_encoder/model.safetensors.index.json",
+		TotalSize: 2_500_000_000,
+		Shards:    []types.Shard{{Path: wantShards[0], Tensors: 2}, {Path: wantShards[1], Tensors: 1}},
+	}
+	if got := index.ShardMap(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ShardMap() = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseShardIndex("model.safetensors.index.json", []byte(`{"metadata": {}}`)); err == nil {
+		t.Error("ParseShardIndex() of an index without a weight map succeeded")
+	}
+}
+
+func TestCheckSafetensorsShard(t *testing.T) {
+	header := `{"__metadata__": {"format": "pt"}, "a": {"dtype": "F32", "shape": [2], "data_offsets": [0, 8]}, "b": {"dtype": "F32", "shape": [4], "data_offsets": [8, 24]}}`
+	tests := []struct {
+		name    string
+		header  string
+		dataLen int
+		tensors []string
+		wantErr string
+	}{
+		{"complete", header, 24, []string{"a", "b"}, ""},
+		{"missing tensor", header, 24, []string{"a", "c"}, "missing tensors c"},
+		{"truncated", header, 16, []string{"a", "b"}, "truncated"},
+	}
+	for _, tt := range tests {
+		p := writeSafetensors(t, tt.header, tt.dataLen)
+		err := CheckSafetensorsShard(p, tt.tensors)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: CheckSafetensorsShard() error = %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: CheckSafetensorsShard() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
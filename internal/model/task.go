@@ -0,0 +1,137 @@
+package model
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// Tasks a model performs, named like Hugging Face pipeline tags. The task is recorded in
+// the manifest (spec.task): converters export the model for it and MLOS Core routes
+// requests by it.
+const (
+	TaskFeatureExtraction           = "feature-extraction"
+	TaskTextClassification          = "text-classification"
+	TaskTokenClassification         = "token-classification"
+	TaskQuestionAnswering           = "question-answering"
+	TaskFillMask                    = "fill-mask"
+	TaskTextGeneration              = "text-generation"
+	TaskText2TextGeneration         = "text2text-generation"
+	TaskImageClassification         = "image-classification"
+	TaskObjectDetection             = "object-detection"
+	TaskImageSegmentation           = "image-segmentation"
+	TaskImageToText                 = "image-to-text"
+	TaskZeroShotImageClassification = "zero-shot-image-classification"
+	TaskAutomaticSpeechRecognition  = "automatic-speech-recognition"
+	TaskAudioClassification         = "audio-classification"
+)
+
+// architectureTasks maps the head of a transformers architecture (the class name suffix)
+// to its task. Longer suffixes come first, since some end in shorter ones.
+var architectureTasks = []struct {
+	suffix string
+	task   string
+}{
+	{"ForImageClassificationWithTeacher", TaskImageClassification},
+	{"ForSequenceClassification", TaskTextClassification},
+	{"ForTokenClassification", TaskTokenClassification},
+	{"ForQuestionAnswering", TaskQuestionAnswering},
+	{"ForMaskedLM", TaskFillMask},
+	{"ForCausalLM", TaskTextGeneration},
+	{"LMHeadModel", TaskTextGeneration},
+	{"ForSeq2SeqLM", TaskText2TextGeneration},
+	{"ForConditionalGeneration", TaskText2TextGeneration},
+	{"ForImageClassification", TaskImageClassification},
+	{"ForObjectDetection", TaskObjectDetection},
+	{"ForSemanticSegmentation", TaskImageSegmentation},
+	{"ForInstanceSegmentation", TaskImageSegmentation},
+	{"ForUniversalSegmentation", TaskImageSegmentation},
+	{"ForSpeechSeq2Seq", TaskAutomaticSpeechRecognition},
+	{"ForCTC", TaskAutomaticSpeechRecognition},
+	{"ForAudioClassification", TaskAudioClassification},
+}
+
+// architectureModelTasks are the tasks of whole-model architectures without a head suffix
+var architectureModelTasks = map[string]string{
+	"CLIPModel":                 TaskZeroShotImageClassification,
+	"SiglipModel":               TaskZeroShotImageClassification,
+	"VisionEncoderDecoderModel": TaskImageToText,
+}
+
+// speechModelTypes are the model types whose conditional generation is speech recognition
+// and whose sequence classification is audio classification
+var speechModelTypes = map[string]bool{
+	"whisper":        true,
+	"speech_to_text": true,
+	"wav2vec2":       true,
+	"hubert":         true,
+	"wavlm":          true,
+	"unispeech":      true,
+	"sew":            true,
+	"data2vec-audio": true,
+}
+
+// captioningModelTypes are the model types whose conditional generation describes images
+var captioningModelTypes = map[string]bool{
+	"blip":       true,
+	"blip-2":     true,
+	"git":        true,
+	"pix2struct": true,
+}
+
+// DetectTask returns the task of a model from its Hugging Face pipeline tag, or else from
+// the architectures in its config.json ("" when neither tells)
+func DetectTask(pipelineTag string, config []byte) string {
+	if tag := strings.ToLower(strings.TrimSpace(pipelineTag)); tag != "" {
+		return tag
+	}
+
+	var parsed struct {
+		ModelType     string   `json:"model_type"`
+		Architectures []string `json:"architectures"`
+	}
+	if len(config) == 0 || json.Unmarshal(config, &parsed) != nil {
+		return ""
+	}
+	for _, architecture := range parsed.Architectures {
+		if task := architectureTask(architecture, strings.ToLower(parsed.ModelType)); task != "" {
+			return task
+		}
+	}
+	return ""
+}
+
+// architectureTask returns the task of a transformers architecture class
+func architectureTask(architecture, modelType string) string {
+	if task, ok := architectureModelTasks[architecture]; ok {
+		return task
+	}
+	for _, head := range architectureTasks {
+		if !strings.HasSuffix(architecture, head.suffix) {
+			continue
+		}
+		switch {
+		case head.suffix == "ForConditionalGeneration" && speechModelTypes[modelType]:
+			return TaskAutomaticSpeechRecognition
+		case head.suffix == "ForConditionalGeneration" && captioningModelTypes[modelType]:
+			return TaskImageToText
+		case head.suffix == "ForSequenceClassification" && speechModelTypes[modelType]:
+			return TaskAudioClassification
+		}
+		return head.task
+	}
+	if strings.HasSuffix(architecture, "Model") {
+		return TaskFeatureExtraction
+	}
+	return ""
+}
+
+// DetectDirTask returns the task of an extracted model from its config.json, at the
+// root or in the canonical config/ directory
+func DetectDirTask(modelPath string) string {
+	data, err := os.ReadFile(LayoutFile(modelPath, "config.json"))
+	if err != nil {
+		return ""
+	}
+	return DetectTask("", data)
+}
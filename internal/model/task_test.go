@@ -0,0 +1,59 @@
+package model
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectTask(t *testing.T) {
+	tests := []struct {
+		name        string
+		pipelineTag string
+		config      string
+		want        string
+	}{
+		{name: "pipeline tag wins", pipelineTag: "Image-Classification", config: `{"architectures": ["BertForMaskedLM"]}`, want: "image-classification"},
+		{name: "masked LM", config: `{"model_type": "bert", "architectures": ["BertForMaskedLM"]}`, want: TaskFillMask},
+		{name: "sequence classification", config: `{"model_type": "distilbert", "architectures": ["DistilBertForSequenceClassification"]}`, want: TaskTextClassification},
+		{name: "causal LM head", config: `{"model_type": "gpt2", "architectures": ["GPT2LMHeadModel"]}`, want: TaskTextGeneration},
+		{name: "seq2seq", config: `{"model_type": "t5", "architectures": ["T5ForConditionalGeneration"]}`, want: TaskText2TextGeneration},
+		{name: "speech recognition", config: `{"model_type": "whisper", "architectures": ["WhisperForConditionalGeneration"]}`, want: TaskAutomaticSpeechRecognition},
+		{name: "CTC", config: `{"model_type": "wav2vec2", "architectures": ["Wav2Vec2ForCTC"]}`, want: TaskAutomaticSpeechRecognition},
+		{name: "audio classification", config: `{"model_type": "wav2vec2", "architectures": ["Wav2Vec2ForSequenceClassification"]}`, want: TaskAudioClassification},
+		{name: "image classification", config: `{"model_type": "vit", "architectures": ["ViTForImageClassification"]}`, want: TaskImageClassification},
+		{name: "distilled image classification", config: `{"model_type": "deit", "architectures": ["DeiTForImageClassificationWithTeacher"]}`, want: TaskImageClassification},
+		{name: "object detection", config: `{"model_type": "detr", "architectures": ["DetrForObjectDetection"]}`, want: TaskObjectDetection},
+		{name: "segmentation", config: `{"model_type": "segformer", "architectures": ["SegformerForSemanticSegmentation"]}`, want: TaskImageSegmentation},
+		{name: "captioning", config: `{"model_type": "blip", "architectures": ["BlipForConditionalGeneration"]}`, want: TaskImageToText},
+		{name: "CLIP", config: `{"model_type": "clip", "architectures": ["CLIPModel"]}`, want: TaskZeroShotImageClassification},
+		{name: "base model", config: `{"model_type": "bert", "architectures": ["BertModel"]}`, want: TaskFeatureExtraction},
+		{name: "no architectures", config: `{"model_type": "bert"}`, want: ""},
+		{name: "not JSON", config: `not json`, want: ""},
+		{name: "nothing", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectTask(tt.pipelineTag, []byte(tt.config)); got != tt.want {
+				t.Errorf("DetectTask() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectDirTask(t *testing.T) {
+	dir := t.TempDir()
+	if got := DetectDirTask(dir); got != "" {
+		t.Errorf("DetectDirTask() without config.json = %q", got)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ConfigDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ConfigDir, "config.json"), []byte(`{"architectures": ["ResNetForImageClassification"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := DetectDirTask(dir); got != TaskImageClassification {
+		t.Errorf("DetectDirTask() in the canonical layout = %q, want %q", got, TaskImageClassification)
+	}
+}
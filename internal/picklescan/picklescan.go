@@ -0,0 +1,726 @@
+// Package picklescan inspects the pickles in PyTorch weight files for imports that run
+// code when the file is loaded.
+//
+// Loading a .bin, .pt or .ckpt checkpoint unpickles it, which calls every global the
+// pickle imports: a checkpoint importing os.system or builtins.exec runs whatever it
+// likes in the process that loads it (the ONNX converter, or MLOS Core). The scanner
+// reads the pickle opcodes without executing them and classifies each import as safe
+// (rebuilding tensors and containers), dangerous (commands, code execution, the file
+// system or network) or unknown. Zip-based checkpoints (torch.save since PyTorch 1.6),
+// legacy checkpoints and plain pickles are read.
+package picklescan
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Scanner is the name scans are recorded under in provenance.json
+const Scanner = "axon-picklescan"
+
+// Severities of the imports a scan reports
+const (
+	SeverityDangerous = "dangerous" // Runs commands or code, or reaches the file system or network
+	SeverityUnknown   = "unknown"   // Not known to be safe, such as the classes of a pickled model object
+)
+
+// ErrNotPickle is returned by ScanFile for weight files that hold no pickles, such as
+// GGML .bin files
+var ErrNotPickle = errors.New("not a pickle or PyTorch checkpoint")
+
+// Import is a global a pickle imports, which is called or instantiated when it's loaded
+type Import struct {
+	Module string // Empty when the pickle computes the import (STACK_GLOBAL of non-constants)
+	Name   string
+}
+
+// String formats the import as module.name
+func (i Import) String() string {
+	if i.Module == "" {
+		return "<computed import>"
+	}
+	return i.Module + "." + i.Name
+}
+
+// dangerousImports are patterns (path.Match) of imports that give a pickle code
+// execution, processes, files or the network
+var dangerousImports = []string{
+	"os.*", "posix.*", "nt.*", "sys.*", "subprocess.*", "pty.*", "commands.*",
+	"shutil.*", "socket.*", "http.*", "httplib.*", "urllib.*", "urllib2.*", "requests.*",
+	"aiohttp.*", "webbrowser.*", "runpy.*", "importlib.*", "code.*", "ctypes.*",
+	"multiprocessing.*", "asyncio.*", "pickle.*", "_pickle.*", "marshal.*", "dill.*",
+	"builtins.eval", "builtins.exec", "builtins.compile", "builtins.open",
+	"builtins.getattr", "builtins.setattr", "builtins.delattr", "builtins.__import__",
+	"builtins.breakpoint", "builtins.input", "builtins.globals", "builtins.vars",
+	"__builtin__.eval", "__builtin__.exec", "__builtin__.execfile", "__builtin__.compile",
+	"__builtin__.open", "__builtin__.file", "__builtin__.getattr", "__builtin__.setattr",
+	"__builtin__.__import__", "__builtin__.apply", "__builtin__.input",
+	"operator.attrgetter", "operator.methodcaller",
+	"torch.load", "torch.hub.*", "torch.storage._load_from_bytes", "numpy.load", "numpy.testing.*",
+}
+
+// safeImports are patterns of the imports PyTorch, NumPy and the standard library use to
+// rebuild tensors, arrays and containers
+var safeImports = []string{
+	"collections.OrderedDict", "collections.defaultdict",
+	"torch._utils._rebuild_*", "torch._tensor._rebuild_from_type_v2",
+	"torch.*Storage", "torch.Size", "torch.device", "torch.serialization._get_layout",
+	"torch.float*", "torch.double", "torch.half", "torch.bfloat16", "torch.complex*",
+	"torch.cfloat", "torch.cdouble", "torch.int*", "torch.uint*", "torch.long",
+	"torch.short", "torch.bool", "torch.qint*", "torch.quint*",
+	"numpy.core.multiarray._reconstruct", "numpy.core.multiarray.scalar",
+	"numpy._core.multiarray._reconstruct", "numpy._core.multiarray.scalar",
+	"numpy.ndarray", "numpy.dtype", "numpy.dtypes.*DType",
+	"_codecs.encode",
+	"builtins.set", "builtins.frozenset", "builtins.bytearray", "builtins.slice",
+	"builtins.range", "builtins.complex", "__builtin__.set", "__builtin__.frozenset",
+	"__builtin__.bytearray", "__builtin__.slice", "__builtin__.complex",
+}
+
+// Classify returns the severity of an import, or "" for an import known to be safe
+func Classify(imp Import) string {
+	if imp.Module == "" {
+		return SeverityDangerous
+	}
+	name := imp.String()
+	if matchesAny(dangerousImports, name) {
+		return SeverityDangerous
+	}
+	if matchesAny(safeImports, name) {
+		return ""
+	}
+	return SeverityUnknown
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// weightExtensions are the extensions of files that may hold pickles
+var weightExtensions = map[string]bool{
+	".bin": true, ".pt": true, ".pth": true, ".ckpt": true, ".pkl": true, ".pickle": true,
+}
+
+// IsWeightFile reports whether a file is scanned for pickles, by its extension
+func IsWeightFile(name string) bool {
+	return weightExtensions[strings.ToLower(filepath.Ext(name))]
+}
+
+// ScanDir scans the weight files under dir and returns the scan, with the imports not
+// known to be safe as findings
+func ScanDir(dir string) (*types.WeightScan, error) {
+	scan := &types.WeightScan{Scanner: Scanner, ScannedAt: time.Now().UTC()}
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !IsWeightFile(p) {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		imports, err := ScanFile(p)
+		if errors.Is(err, ErrNotPickle) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to scan %s: %w", rel, err)
+		}
+		scan.Files = append(scan.Files, rel)
+		seen := make(map[string]bool)
+		for _, imp := range imports {
+			severity := Classify(imp)
+			if severity == "" || seen[imp.String()] {
+				continue
+			}
+			seen[imp.String()] = true
+			scan.Findings = append(scan.Findings, types.WeightScanFinding{File: rel, Import: imp.String(), Severity: severity})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(scan.Files)
+	sort.SliceStable(scan.Findings, func(i, j int) bool {
+		if scan.Findings[i].File != scan.Findings[j].File {
+			return scan.Findings[i].File < scan.Findings[j].File
+		}
+		return scan.Findings[i].Import < scan.Findings[j].Import
+	})
+	return scan, nil
+}
+
+// zipMagic starts zip-based checkpoints
+var zipMagic = []byte("PK\x03\x04")
+
+// legacyMagic is the first pickle of a legacy (pre-1.6) PyTorch checkpoint, after the
+// protocol byte: the magic number 0x1950a86a20f9469cfc6c as LONG1, then STOP
+var legacyMagic = []byte{opLong1, 0x0a, 0x6c, 0xfc, 0x9c, 0x46, 0xf9, 0x20, 0x6a, 0xa8, 0x50, 0x19, opStop}
+
+// legacyPickles is how many pickles a legacy checkpoint holds before the tensor data:
+// the magic number, protocol version, system info, the object and its storage keys
+const legacyPickles = 5
+
+// ScanFile returns the imports of the pickles in a weight file: every .pkl entry of a
+// zip-based checkpoint, the pickles of a legacy checkpoint, or a plain pickle of any
+// protocol. Files that don't start with a pickle opcode, and GGUF, GGML and safetensors
+// files, return ErrNotPickle.
+func ScanFile(filePath string) ([]Import, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	r := bufio.NewReader(f)
+	head, _ := r.Peek(2 + len(legacyMagic))
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		return scanZip(f)
+	case len(head) >= 2 && head[0] == opProto && bytes.Equal(head[2:], legacyMagic):
+		return readPickles(r, legacyPickles)
+	case len(head) >= 2 && head[0] == opProto && head[1] <= highestProtocol:
+		return readPickles(r, 1)
+	case strings.HasSuffix(strings.ToLower(filePath), ".pkl") || strings.HasSuffix(strings.ToLower(filePath), ".pickle"):
+		// Protocols 0 and 1 have no PROTO opcode
+		return readPickles(r, 1)
+	}
+
+	// Any other weight file may be a protocol 0 or 1 pickle, which torch.load unpickles
+	// too, so it's read unless it doesn't start with a pickle opcode. A file that is known
+	// not to be a pickle (GGUF, GGML, safetensors) is only one if it parses completely.
+	imports, err := readPickles(r, 1)
+	if err != nil && (errors.Is(err, errBadStart) || hasOtherMagic(f, head)) {
+		return nil, ErrNotPickle
+	}
+	return imports, err
+}
+
+// otherMagics start weight files that aren't pickles: GGUF and the GGML family, whose
+// magics are written as little-endian integers
+var otherMagics = [][]byte{[]byte("GGUF"), []byte("lmgg"), []byte("fmgg"), []byte("tjgg"), []byte("algg")}
+
+// hasOtherMagic reports whether a file starting with head is a GGUF, GGML or safetensors
+// file. A safetensors file starts with the length of its JSON header, which is within
+// the file; a protocol 0 pickle's first 8 bytes, read as that length, are far beyond it.
+func hasOtherMagic(f *os.File, head []byte) bool {
+	for _, magic := range otherMagics {
+		if bytes.HasPrefix(head, magic) {
+			return true
+		}
+	}
+	if len(head) < 9 || head[8] != '{' {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && binary.LittleEndian.Uint64(head) <= uint64(info.Size())-8
+}
+
+// scanZip returns the imports of the pickles in a zip-based checkpoint
+func scanZip(f *os.File) ([]Import, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint archive: %w", err)
+	}
+	var imports []Import
+	scanned := 0
+	for _, entry := range archive.File {
+		if !strings.HasSuffix(entry.Name, ".pkl") {
+			continue
+		}
+		rc, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", entry.Name, err)
+		}
+		found, err := readPickles(bufio.NewReader(rc), 1)
+		_ = rc.Close()
+		imports = append(imports, found...)
+		if err != nil {
+			return imports, fmt.Errorf("%s: %w", entry.Name, err)
+		}
+		scanned++
+	}
+	if scanned == 0 {
+		return nil, ErrNotPickle
+	}
+	return imports, nil
+}
+
+// readPickles reads n consecutive pickles and returns their imports. The imports of a
+// pickle that fails to parse are returned with the error, since unpickling runs them
+// before reaching the broken part.
+func readPickles(r *bufio.Reader, n int) ([]Import, error) {
+	var imports []Import
+	for i := 0; i < n; i++ {
+		found, err := readPickle(r)
+		imports = append(imports, found...)
+		if err != nil {
+			return imports, err
+		}
+	}
+	return imports, nil
+}
+
+// Pickle opcodes (see Python's pickletools)
+const (
+	opMark           = '('
+	opStop           = '.'
+	opPop            = '0'
+	opPopMark        = '1'
+	opDup            = '2'
+	opFloat          = 'F'
+	opInt            = 'I'
+	opBinInt         = 'J'
+	opBinInt1        = 'K'
+	opLong           = 'L'
+	opBinInt2        = 'M'
+	opNone           = 'N'
+	opPersID         = 'P'
+	opBinPersID      = 'Q'
+	opReduce         = 'R'
+	opString         = 'S'
+	opBinString      = 'T'
+	opShortBinString = 'U'
+	opUnicode        = 'V'
+	opBinUnicode     = 'X'
+	opAppend         = 'a'
+	opBuild          = 'b'
+	opGlobal         = 'c'
+	opDict           = 'd'
+	opEmptyDict      = '}'
+	opAppends        = 'e'
+	opGet            = 'g'
+	opBinGet         = 'h'
+	opInst           = 'i'
+	opLongBinGet     = 'j'
+	opList           = 'l'
+	opEmptyList      = ']'
+	opObj            = 'o'
+	opPut            = 'p'
+	opBinPut         = 'q'
+	opLongBinPut     = 'r'
+	opSetItem        = 's'
+	opTuple          = 't'
+	opEmptyTuple     = ')'
+	opSetItems       = 'u'
+	opBinFloat       = 'G'
+
+	opProto    = 0x80
+	opNewObj   = 0x81
+	opExt1     = 0x82
+	opExt2     = 0x83
+	opExt4     = 0x84
+	opTuple1   = 0x85
+	opTuple2   = 0x86
+	opTuple3   = 0x87
+	opNewTrue  = 0x88
+	opNewFalse = 0x89
+	opLong1    = 0x8a
+	opLong4    = 0x8b
+
+	opBinBytes      = 'B'
+	opShortBinBytes = 'C'
+
+	opShortBinUnicode = 0x8c
+	opBinUnicode8     = 0x8d
+	opBinBytes8       = 0x8e
+	opEmptySet        = 0x8f
+	opAddItems        = 0x90
+	opFrozenSet       = 0x91
+	opNewObjEx        = 0x92
+	opStackGlobal     = 0x93
+	opMemoize         = 0x94
+	opFrame           = 0x95
+
+	opByteArray8     = 0x96
+	opNextBuffer     = 0x97
+	opReadOnlyBuffer = 0x98
+)
+
+// extCodeSizes are the sizes of the extension code arguments of EXT1, EXT2 and EXT4
+var extCodeSizes = map[byte]uint64{opExt1: 1, opExt2: 2, opExt4: 4}
+
+// highestProtocol is the newest pickle protocol the scanner reads
+const highestProtocol = 5
+
+// Bounds on what the scanner keeps in memory
+const (
+	maxPickleString = 1 << 10  // Longer strings are skipped; module and class names are short
+	maxPickleLine   = 64 << 10 // Longest line argument of a text opcode
+	maxStackDepth   = 1 << 20  // Most values and marks on the stack (pickle batches 1000 items)
+)
+
+// errBadStart is returned by readPickle for data whose first opcode is invalid
+var errBadStart = errors.New("not a pickle")
+
+// errStackUnderflow is returned for opcodes that take more values than the stack has,
+// which the unpickler refuses too
+var errStackUnderflow = errors.New("stack underflow")
+
+// pickleStack is the unpickler's stack, with the stacks MARK set aside. Values are the
+// constant strings pushed, or nil for anything else (including computed strings).
+type pickleStack struct {
+	values []*string
+	marks  [][]*string
+	depth  int
+}
+
+// push pushes a value
+func (s *pickleStack) push(v *string) error {
+	if s.depth++; s.depth > maxStackDepth {
+		return fmt.Errorf("stack deeper than %d values", maxStackDepth)
+	}
+	s.values = append(s.values, v)
+	return nil
+}
+
+// pop removes the top n values and returns them, bottom first
+func (s *pickleStack) pop(n int) ([]*string, error) {
+	if len(s.values) < n {
+		return nil, errStackUnderflow
+	}
+	popped := s.values[len(s.values)-n:]
+	s.values = s.values[:len(s.values)-n]
+	s.depth -= n
+	return popped, nil
+}
+
+// replace pops n values and pushes the (non-string) result of an operation on them
+func (s *pickleStack) replace(n int) error {
+	if _, err := s.pop(n); err != nil {
+		return err
+	}
+	return s.push(nil)
+}
+
+// top returns the top value without removing it
+func (s *pickleStack) top() (*string, error) {
+	if len(s.values) == 0 {
+		return nil, errStackUnderflow
+	}
+	return s.values[len(s.values)-1], nil
+}
+
+// mark sets the current stack aside and starts an empty one
+func (s *pickleStack) mark() error {
+	if s.depth++; s.depth > maxStackDepth {
+		return fmt.Errorf("stack deeper than %d values", maxStackDepth)
+	}
+	s.marks = append(s.marks, s.values)
+	s.values = nil
+	return nil
+}
+
+// popMark returns the values pushed since the last mark and restores the stack before it
+func (s *pickleStack) popMark() ([]*string, error) {
+	if len(s.marks) == 0 {
+		return nil, errors.New("no mark on the stack")
+	}
+	items := s.values
+	s.values = s.marks[len(s.marks)-1]
+	s.marks = s.marks[:len(s.marks)-1]
+	s.depth -= len(items) + 1
+	return items, nil
+}
+
+// replaceMark pops the values since the last mark and pushes the (non-string) result
+// of an operation on them
+func (s *pickleStack) replaceMark() error {
+	if _, err := s.popMark(); err != nil {
+		return err
+	}
+	return s.push(nil)
+}
+
+// readPickle reads one pickle up to its STOP opcode and returns the globals it imports.
+// It doesn't evaluate the pickle: it models the stack and memo the unpickler would have,
+// tracking only which values are constant strings, to resolve the operands of
+// STACK_GLOBAL. Imports whose module or name isn't a constant string are computed.
+func readPickle(r *bufio.Reader) ([]Import, error) {
+	var imports []Import
+	var stack pickleStack
+	memo := make(map[uint64]*string)
+	put := func(index uint64) error {
+		v, err := stack.top()
+		memo[index] = v
+		return err
+	}
+
+	for opcodes := 0; ; opcodes++ {
+		op, err := r.ReadByte()
+		if err != nil {
+			return imports, atStart(opcodes, fmt.Errorf("truncated pickle: %w", err))
+		}
+		switch op {
+		case opStop:
+			return imports, nil
+		case opProto:
+			err = skip(r, 1)
+		case opFrame:
+			err = skip(r, 8)
+
+		case opMark:
+			err = stack.mark()
+		case opPop:
+			// With nothing above the last mark, POP discards the mark
+			if len(stack.values) > 0 {
+				_, err = stack.pop(1)
+			} else {
+				_, err = stack.popMark()
+			}
+		case opPopMark:
+			_, err = stack.popMark()
+		case opDup:
+			var v *string
+			if v, err = stack.top(); err == nil {
+				err = stack.push(v)
+			}
+
+		// Values
+		case opNone, opNewTrue, opNewFalse, opEmptyDict, opEmptyList, opEmptyTuple, opEmptySet, opNextBuffer:
+			err = stack.push(nil)
+		case opInt, opLong, opFloat, opPersID:
+			if _, err = readLine(r); err == nil {
+				err = stack.push(nil)
+			}
+		case opBinInt1:
+			if err = skip(r, 1); err == nil {
+				err = stack.push(nil)
+			}
+		case opBinInt2:
+			if err = skip(r, 2); err == nil {
+				err = stack.push(nil)
+			}
+		case opBinInt:
+			if err = skip(r, 4); err == nil {
+				err = stack.push(nil)
+			}
+		case opBinFloat:
+			if err = skip(r, 8); err == nil {
+				err = stack.push(nil)
+			}
+		case opLong1, opShortBinBytes:
+			if err = skipSized(r, 1); err == nil {
+				err = stack.push(nil)
+			}
+		case opLong4, opBinBytes:
+			if err = skipSized(r, 4); err == nil {
+				err = stack.push(nil)
+			}
+		case opBinBytes8, opByteArray8:
+			if err = skipSized(r, 8); err == nil {
+				err = stack.push(nil)
+			}
+
+		// Strings
+		case opString:
+			var line string
+			if line, err = readLine(r); err == nil {
+				s := strings.Trim(line, `'"`)
+				err = stack.push(&s)
+			}
+		case opUnicode:
+			var line string
+			if line, err = readLine(r); err == nil {
+				err = stack.push(&line)
+			}
+		case opShortBinString, opShortBinUnicode:
+			var s *string
+			if s, err = readSized(r, 1); err == nil {
+				err = stack.push(s)
+			}
+		case opBinString, opBinUnicode:
+			var s *string
+			if s, err = readSized(r, 4); err == nil {
+				err = stack.push(s)
+			}
+		case opBinUnicode8:
+			var s *string
+			if s, err = readSized(r, 8); err == nil {
+				err = stack.push(s)
+			}
+
+		// Containers and calls, which consume their operands
+		case opTuple, opList, opDict, opFrozenSet, opObj:
+			err = stack.replaceMark()
+		case opTuple1, opBinPersID, opReadOnlyBuffer:
+			err = stack.replace(1)
+		case opTuple2, opReduce, opNewObj, opBuild:
+			err = stack.replace(2)
+		case opTuple3, opNewObjEx:
+			err = stack.replace(3)
+		case opAppend:
+			_, err = stack.pop(1)
+		case opSetItem:
+			_, err = stack.pop(2)
+		case opAppends, opSetItems, opAddItems:
+			_, err = stack.popMark()
+
+		// Imports
+		case opGlobal, opInst:
+			var module, name string
+			if module, err = readLine(r); err == nil {
+				if name, err = readLine(r); err == nil {
+					imports = append(imports, Import{Module: module, Name: name})
+					if op == opInst {
+						err = stack.replaceMark()
+					} else {
+						err = stack.push(nil)
+					}
+				}
+			}
+		case opStackGlobal:
+			var operands []*string
+			if operands, err = stack.pop(2); err == nil && operands[0] != nil && operands[1] != nil {
+				imports = append(imports, Import{Module: *operands[0], Name: *operands[1]})
+			} else {
+				imports = append(imports, Import{})
+			}
+			if err == nil {
+				err = stack.push(nil)
+			}
+		case opExt1, opExt2, opExt4:
+			// Extension codes import whatever copyreg registered for them
+			imports = append(imports, Import{})
+			if err = skip(r, extCodeSizes[op]); err == nil {
+				err = stack.push(nil)
+			}
+
+		// Memo
+		case opGet, opBinGet, opLongBinGet:
+			var index uint64
+			if index, err = readMemoIndex(r, op); err == nil {
+				err = stack.push(memo[index])
+			}
+		case opPut, opBinPut, opLongBinPut:
+			var index uint64
+			if index, err = readMemoIndex(r, op); err == nil {
+				err = put(index)
+			}
+		case opMemoize:
+			err = put(uint64(len(memo)))
+
+		default:
+			return imports, atStart(opcodes, fmt.Errorf("invalid pickle opcode 0x%02x", op))
+		}
+		if err != nil {
+			return imports, atStart(opcodes, fmt.Errorf("invalid pickle: %w", err))
+		}
+	}
+}
+
+// atStart marks an error of the first opcode of a pickle (opcodes read before it is 0)
+// with errBadStart
+func atStart(opcodes int, err error) error {
+	if opcodes == 0 {
+		return fmt.Errorf("%w: %w", errBadStart, err)
+	}
+	return err
+}
+
+// readMemoIndex reads the memo index argument of a GET or PUT opcode
+func readMemoIndex(r *bufio.Reader, op byte) (uint64, error) {
+	switch op {
+	case opGet, opPut:
+		line, err := readLine(r)
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseUint(line, 10, 64)
+	case opBinGet, opBinPut:
+		return readUint(r, 1)
+	default:
+		return readUint(r, 4)
+	}
+}
+
+// readLine reads the newline-terminated argument of a text opcode
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := r.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxPickleLine {
+			return "", fmt.Errorf("line argument longer than %d bytes", maxPickleLine)
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(line), "\r\n"), nil
+	}
+}
+
+// readUint reads a little-endian unsigned integer of size bytes
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(r, buf[:size]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+// readSized reads a string prefixed with its length (of lengthSize bytes), returning
+// nil for strings too long to be names
+func readSized(r *bufio.Reader, lengthSize int) (*string, error) {
+	n, err := readUint(r, lengthSize)
+	if err != nil {
+		return nil, err
+	}
+	if n > maxPickleString {
+		return nil, skip(r, n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	s := string(buf)
+	return &s, nil
+}
+
+// skipSized skips a value prefixed with its length (of lengthSize bytes)
+func skipSized(r *bufio.Reader, lengthSize int) error {
+	n, err := readUint(r, lengthSize)
+	if err != nil {
+		return err
+	}
+	return skip(r, n)
+}
+
+// skip discards n bytes
+func skip(r *bufio.Reader, n uint64) error {
+	if n > 1<<62 {
+		return fmt.Errorf("invalid length %d", n)
+	}
+	_, err := io.CopyN(io.Discard, r, int64(n))
+	return err
+}
@@ -0,0 +1,162 @@
+package picklescan
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Pickles as torch.save and pickle.dumps write them
+var (
+	// OrderedDict of a tensor rebuilt from a persistent storage, protocol 2
+	safePickle = "\x80\x02ccollections\nOrderedDict\nq\x00)Rq\x01X\x06\x00\x00\x00weightq\x02ctorch._utils\n_rebuild_tensor_v2\nq\x03((X\x07\x00\x00\x00storageq\x04ctorch\nFloatStorage\nq\x05X\x01\x00\x00\x000q\x06X\x03\x00\x00\x00cpuq\x07K\x04tq\x08QK\x00K\x04\x85q\tK\x01\x85q\n\x89h\x00)Rq\x0btq\x0cRq\rs."
+
+	// posix.system("id"), protocol 2
+	systemPickle = "\x80\x02cposix\nsystem\nq\x00X\x02\x00\x00\x00idq\x01\x85q\x02Rq\x03."
+
+	// (builtins.set(), builtins.eval("1")) with the module name taken from the memo, protocol 4
+	stackGlobalPickle = "\x80\x04\x95\x00\x00\x00\x00\x00\x00\x00\x00\x8c\x08builtins\x94\x8c\x03set\x94\x93\x94)R\x94h\x00\x8c\x04eval\x94\x93\x94\x8c\x011\x94\x85\x94R\x94\x86\x94."
+
+	// os.system("id") with a string popped off the stack before STACK_GLOBAL, protocol 4
+	popPickle = "\x80\x04\x8c\x02os\x8c\x06system\x8c\x04junk0\x93\x8c\x02id\x85R."
+
+	// os.system("id") with a marked tuple discarded before STACK_GLOBAL, protocol 4
+	popMarkPickle = "\x80\x04\x8c\x02os\x8c\x06system(\x8c\x01a\x8c\x01b1\x93\x8c\x02id\x85R."
+
+	// An import whose name is the result of a call (builtins.str("system")), protocol 4
+	computedPickle = "\x80\x04\x8c\x02os\x8c\x08builtins\x8c\x03str\x93\x8c\x06system\x85R\x93\x8c\x02id\x85R."
+)
+
+func TestReadPickle_Imports(t *testing.T) {
+	tests := []struct {
+		name   string
+		pickle string
+		want   []Import
+	}{
+		{"safe", safePickle, []Import{{"collections", "OrderedDict"}, {"torch._utils", "_rebuild_tensor_v2"}, {"torch", "FloatStorage"}}},
+		{"system", systemPickle, []Import{{"posix", "system"}}},
+		{"stack global", stackGlobalPickle, []Import{{"builtins", "set"}, {"builtins", "eval"}}},
+		{"pop before stack global", popPickle, []Import{{"os", "system"}}},
+		{"pop mark before stack global", popMarkPickle, []Import{{"os", "system"}}},
+		{"computed stack global", computedPickle, []Import{{"builtins", "str"}, {}}},
+	}
+	for _, tt := range tests {
+		imports, err := ScanFile(writeFile(t, "model.bin", tt.pickle))
+		if err != nil {
+			t.Errorf("%s: ScanFile() error = %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(imports, tt.want) {
+			t.Errorf("%s: imports = %v, want %v", tt.name, imports, tt.want)
+		}
+	}
+
+	// Unpickling runs what precedes a broken part, so its imports are still reported
+	imports, err := ScanFile(writeFile(t, "model.pt", systemPickle[:len(systemPickle)-4]))
+	if err == nil || len(imports) != 1 {
+		t.Errorf("ScanFile() of a truncated pickle = %v, %v; want the import and an error", imports, err)
+	}
+
+	// A protocol 0 pickle has no PROTO opcode, whatever the file is named
+	imports, err = ScanFile(writeFile(t, "pytorch_model.bin", "cos\nsystem\n(S'id'\ntR."))
+	if err != nil || !reflect.DeepEqual(imports, []Import{{"os", "system"}}) {
+		t.Errorf("ScanFile() of a protocol 0 pickle = %v, %v; want os.system", imports, err)
+	}
+
+	// STACK_GLOBAL without two operands above the mark is a computed import, and an error
+	imports, err = ScanFile(writeFile(t, "model.bin", "\x80\x04\x8c\x02os(\x8c\x06system\x93."))
+	if err == nil || !reflect.DeepEqual(imports, []Import{{}}) {
+		t.Errorf("ScanFile() of a stack underflow = %v, %v; want a computed import and an error", imports, err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		imp  Import
+		want string
+	}{
+		{Import{"torch._utils", "_rebuild_tensor_v2"}, ""},
+		{Import{"torch", "BFloat16Storage"}, ""},
+		{Import{"numpy.core.multiarray", "_reconstruct"}, ""},
+		{Import{"os", "system"}, SeverityDangerous},
+		{Import{"subprocess", "Popen"}, SeverityDangerous},
+		{Import{"urllib.request", "urlopen"}, SeverityDangerous},
+		{Import{"builtins", "exec"}, SeverityDangerous},
+		{Import{}, SeverityDangerous},
+		{Import{"transformers.training_args", "TrainingArguments"}, SeverityUnknown},
+	}
+	for _, tt := range tests {
+		if got := Classify(tt.imp); got != tt.want {
+			t.Errorf("Classify(%s) = %q, want %q", tt.imp, got, tt.want)
+		}
+	}
+}
+
+func TestScanDir(t *testing.T) {
+	dir := t.TempDir()
+
+	// A zip-based checkpoint with a dangerous pickle
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{"archive/data.pkl": systemPickle, "archive/data/0": "\x00\x00\x80?"} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeFileIn(t, dir, "pytorch_model.bin", archive.String())
+
+	// A legacy checkpoint: magic, protocol, system info, the object and its storage keys,
+	// then raw tensor data
+	legacy := "\x80\x02" + string(legacyMagic) + "\x80\x02M\xe9\x03.\x80\x02}q\x00." + safePickle + "\x80\x02]q\x00X\x01\x00\x00\x000q\x01a.\x80\xff\xff\x00"
+	writeFileIn(t, dir, "text_encoder/model.pt", legacy)
+
+	// Files that hold no pickles are skipped
+	writeFileIn(t, dir, "ggml-model.bin", "lmgg\x01\x00\x00\x00")
+	writeFileIn(t, dir, "model.bin", "\x00\x00\x80?\x00\x00\x00@")
+	writeFileIn(t, dir, "converted.bin", "K\x00\x00\x00\x00\x00\x00\x00{\"__metadata__\":{}}"+strings.Repeat(" ", 75-19))
+	writeFileIn(t, dir, "model.safetensors", "not scanned")
+
+	scan, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir() error = %v", err)
+	}
+	if want := []string{"pytorch_model.bin", "text_encoder/model.pt"}; !reflect.DeepEqual(scan.Files, want) {
+		t.Errorf("Files = %v, want %v", scan.Files, want)
+	}
+	want := []types.WeightScanFinding{{File: "pytorch_model.bin", Import: "posix.system", Severity: SeverityDangerous}}
+	if !reflect.DeepEqual(scan.Findings, want) {
+		t.Errorf("Findings = %+v, want %+v", scan.Findings, want)
+	}
+	if _, err := ScanFile(filepath.Join(dir, "ggml-model.bin")); !errors.Is(err, ErrNotPickle) {
+		t.Errorf("ScanFile() of a GGML file error = %v, want ErrNotPickle", err)
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	return writeFileIn(t, t.TempDir(), name, content)
+}
+
+func writeFileIn(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
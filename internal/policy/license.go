@@ -0,0 +1,130 @@
+// Package policy enforces organization policies on the models Axon installs and publishes.
+//
+// License policies match a model's declared license against allow and deny lists of
+// license identifiers. Matching is case-insensitive and patterns may use * wildcards,
+// so "agpl-*" denies every AGPL version and "*rail*" every RAIL variant.
+//
+// Size policies cap the size of a single model and the cache space of each namespace,
+// and are checked before a download starts.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ErrLicenseDenied is returned (wrapped in a *LicenseError) when a license violates the policy
+var ErrLicenseDenied = errors.New("license denied by policy")
+
+// unknownLicense is the license adapters report when a model doesn't declare one
+const unknownLicense = "unknown"
+
+// licenseSeparator splits license expressions such as "MIT OR Apache-2.0"
+var licenseSeparator = regexp.MustCompile(`(?i)\s+(?:or|and)\s+|,`)
+
+// LicensePolicy lists the licenses models may and may not have. An empty Allowed list
+// allows every license that isn't denied.
+type LicensePolicy struct {
+	Allowed []string
+	Denied  []string
+}
+
+// LicenseError describes why a license violates the policy
+type LicenseError struct {
+	License   string   // The license as declared in the manifest
+	Offending []string // The licenses in the declaration that violate the policy
+	Reason    string
+}
+
+func (e *LicenseError) Error() string {
+	return fmt.Sprintf("license %s %s", quoteAll(e.Offending), e.Reason)
+}
+
+func (e *LicenseError) Unwrap() error {
+	return ErrLicenseDenied
+}
+
+// Check returns a *LicenseError if the declared license violates the policy. Each
+// license of a compound declaration ("MIT OR AGPL-3.0") must satisfy the policy, and
+// a model without a license is rejected when an allow list is set.
+func (p LicensePolicy) Check(license string) error {
+	licenses := SplitLicenses(license)
+
+	var denied []string
+	var pattern string
+	for _, l := range licenses {
+		if match := matchAny(p.Denied, l); match != "" {
+			denied = append(denied, l)
+			pattern = match
+		}
+	}
+	if len(denied) > 0 {
+		return &LicenseError{
+			License:   license,
+			Offending: denied,
+			Reason:    fmt.Sprintf("is denied by policy.denied_licenses (%q)", pattern),
+		}
+	}
+
+	if len(p.Allowed) == 0 {
+		return nil
+	}
+	if len(licenses) == 0 {
+		return &LicenseError{
+			License:   license,
+			Offending: []string{unknownLicense},
+			Reason:    fmt.Sprintf("is not in policy.allowed_licenses (%s); the model declares no license", strings.Join(p.Allowed, ", ")),
+		}
+	}
+	var notAllowed []string
+	for _, l := range licenses {
+		if matchAny(p.Allowed, l) == "" {
+			notAllowed = append(notAllowed, l)
+		}
+	}
+	if len(notAllowed) > 0 {
+		return &LicenseError{
+			License:   license,
+			Offending: notAllowed,
+			Reason:    fmt.Sprintf("is not in policy.allowed_licenses (%s)", strings.Join(p.Allowed, ", ")),
+		}
+	}
+	return nil
+}
+
+// SplitLicenses returns the individual licenses of a license declaration, dropping
+// "unknown" placeholders
+func SplitLicenses(license string) []string {
+	var licenses []string
+	for _, l := range licenseSeparator.Split(license, -1) {
+		l = strings.Trim(strings.TrimSpace(l), "()")
+		if l == "" || strings.EqualFold(l, unknownLicense) {
+			continue
+		}
+		licenses = append(licenses, l)
+	}
+	return licenses
+}
+
+// matchAny returns the first pattern that matches license, or ""
+func matchAny(patterns []string, license string) string {
+	license = strings.ToLower(license)
+	for _, pattern := range patterns {
+		if ok, err := path.Match(strings.ToLower(strings.TrimSpace(pattern)), license); err == nil && ok {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// quoteAll formats licenses as a quoted, comma-separated list
+func quoteAll(licenses []string) string {
+	quoted := make([]string, len(licenses))
+	for i, l := range licenses {
+		quoted[i] = fmt.Sprintf("%q", l)
+	}
+	return strings.Join(quoted, ", ")
+}
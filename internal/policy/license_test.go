@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLicensePolicy_Check(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    LicensePolicy
+		license   string
+		offending []string // nil when allowed
+	}{
+		{"no policy", LicensePolicy{}, "agpl-3.0", nil},
+		{"denied exact", LicensePolicy{Denied: []string{"AGPL-3.0"}}, "agpl-3.0", []string{"agpl-3.0"}},
+		{"denied wildcard", LicensePolicy{Denied: []string{"*rail*"}}, "bigscience-openrail-m", []string{"bigscience-openrail-m"}},
+		{"not denied", LicensePolicy{Denied: []string{"agpl-*"}}, "apache-2.0", nil},
+		{"unknown passes deny list", LicensePolicy{Denied: []string{"agpl-*"}}, "Unknown", nil},
+		{"allowed", LicensePolicy{Allowed: []string{"mit", "apache-*"}}, "Apache-2.0", nil},
+		{"not allowed", LicensePolicy{Allowed: []string{"mit"}}, "gpl-3.0", []string{"gpl-3.0"}},
+		{"no license with allow list", LicensePolicy{Allowed: []string{"mit"}}, "Unknown", []string{"unknown"}},
+		{"deny wins over allow", LicensePolicy{Allowed: []string{"*"}, Denied: []string{"agpl-*"}}, "agpl-3.0", []string{"agpl-3.0"}},
+		{"compound denied", LicensePolicy{Denied: []string{"agpl-*"}}, "MIT OR AGPL-3.0", []string{"AGPL-3.0"}},
+		{"compound allowed", LicensePolicy{Allowed: []string{"mit", "apache-2.0"}}, "(MIT OR Apache-2.0)", nil},
+		{"compound partly allowed", LicensePolicy{Allowed: []string{"mit"}}, "mit, cc-by-nc-4.0", []string{"cc-by-nc-4.0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.license)
+			if tt.offending == nil {
+				if err != nil {
+					t.Errorf("Check(%q) error = %v, want allowed", tt.license, err)
+				}
+				return
+			}
+			var licenseErr *LicenseError
+			if !errors.As(err, &licenseErr) || !errors.Is(err, ErrLicenseDenied) {
+				t.Fatalf("Check(%q) error = %v, want *LicenseError", tt.license, err)
+			}
+			if !reflect.DeepEqual(licenseErr.Offending, tt.offending) {
+				t.Errorf("Offending = %v, want %v", licenseErr.Offending, tt.offending)
+			}
+			for _, l := range tt.offending {
+				if !strings.Contains(err.Error(), l) {
+					t.Errorf("error %q does not name %q", err, l)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSizeExceeded is returned (wrapped in a *SizeError) when a model is larger than the
+// policy allows
+var ErrSizeExceeded = errors.New("model size exceeds policy")
+
+// GB is the number of bytes in a gigabyte as used by size policies
+const GB = 1 << 30
+
+// SizePolicy limits how large a single model may be and how much cache space the models
+// of a namespace, or of a whole tenant, may use together. Zero limits are not enforced.
+type SizePolicy struct {
+	MaxModelBytes    int64
+	QuotaBytes       map[string]int64 // By namespace
+	TenantQuotaBytes int64
+}
+
+// SizeError describes which limit a model exceeds
+type SizeError struct {
+	Size   int64 // Size of the model
+	Limit  int64
+	Reason string
+}
+
+func (e *SizeError) Error() string {
+	return e.Reason
+}
+
+func (e *SizeError) Unwrap() error {
+	return ErrSizeExceeded
+}
+
+// Check returns a *SizeError if a model of size bytes in namespace exceeds the policy.
+// used is the cache space the namespace's other installed models already take. A size
+// of 0 means it is unknown and always passes.
+func (p SizePolicy) Check(namespace string, size, used int64) error {
+	if size <= 0 {
+		return nil
+	}
+	if p.MaxModelBytes > 0 && size > p.MaxModelBytes {
+		return &SizeError{
+			Size:   size,
+			Limit:  p.MaxModelBytes,
+			Reason: fmt.Sprintf("model size %.2f GB exceeds policy.max_model_size_gb (%.2f GB)", gigabytes(size), gigabytes(p.MaxModelBytes)),
+		}
+	}
+	if quota := p.QuotaBytes[namespace]; quota > 0 && used+size > quota {
+		return &SizeError{
+			Size:  size,
+			Limit: quota,
+			Reason: fmt.Sprintf("model size %.2f GB would bring namespace %q to %.2f GB, over its policy.namespace_quotas_gb quota (%.2f GB)",
+				gigabytes(size), namespace, gigabytes(used+size), gigabytes(quota)),
+		}
+	}
+	return nil
+}
+
+// CheckTenant returns a *SizeError if a model of size bytes would take the tenant over
+// its quota. used is the cache space the tenant's installed models already take.
+func (p SizePolicy) CheckTenant(tenant string, size, used int64) error {
+	if size <= 0 || p.TenantQuotaBytes <= 0 || used+size <= p.TenantQuotaBytes {
+		return nil
+	}
+	return &SizeError{
+		Size:  size,
+		Limit: p.TenantQuotaBytes,
+		Reason: fmt.Sprintf("model size %.2f GB would bring tenant %q to %.2f GB, over its policy.tenant_quotas_gb quota (%.2f GB)",
+			gigabytes(size), tenant, gigabytes(used+size), gigabytes(p.TenantQuotaBytes)),
+	}
+}
+
+func gigabytes(bytes int64) float64 {
+	return float64(bytes) / GB
+}
@@ -0,0 +1,67 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSizePolicy_Check(t *testing.T) {
+	p := SizePolicy{
+		MaxModelBytes: 10 * GB,
+		QuotaBytes:    map[string]int64{"hf": 20 * GB},
+	}
+
+	tests := []struct {
+		name      string
+		policy    SizePolicy
+		namespace string
+		size      int64
+		used      int64
+		wantLimit int64 // 0 when allowed
+	}{
+		{"no policy", SizePolicy{}, "hf", 60 * GB, 0, 0},
+		{"under limit", p, "hf", 5 * GB, 0, 0},
+		{"over model limit", p, "hf", 60 * GB, 0, 10 * GB},
+		{"unknown size", p, "hf", 0, 0, 0},
+		{"within quota", p, "hf", 5 * GB, 15 * GB, 0},
+		{"over quota", p, "hf", 5 * GB, 16 * GB, 20 * GB},
+		{"no quota for namespace", p, "pytorch", 5 * GB, 100 * GB, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.namespace, tt.size, tt.used)
+			if tt.wantLimit == 0 {
+				if err != nil {
+					t.Errorf("Check() error = %v, want allowed", err)
+				}
+				return
+			}
+			var sizeErr *SizeError
+			if !errors.As(err, &sizeErr) {
+				t.Fatalf("Check() error = %v, want *SizeError", err)
+			}
+			if !errors.Is(err, ErrSizeExceeded) {
+				t.Errorf("Check() error does not wrap ErrSizeExceeded")
+			}
+			if sizeErr.Limit != tt.wantLimit {
+				t.Errorf("Check() limit = %d, want %d", sizeErr.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestSizePolicy_CheckTenant(t *testing.T) {
+	p := SizePolicy{TenantQuotaBytes: 100 * GB}
+
+	if err := p.CheckTenant("team-a", 10*GB, 90*GB); err != nil {
+		t.Errorf("CheckTenant() error = %v, want allowed at the quota", err)
+	}
+	var sizeErr *SizeError
+	if err := p.CheckTenant("team-a", 10*GB, 91*GB); !errors.As(err, &sizeErr) || sizeErr.Limit != 100*GB {
+		t.Errorf("CheckTenant() error = %v, want *SizeError over the quota", err)
+	}
+	if err := (SizePolicy{}).CheckTenant("team-a", 10*GB, 1000*GB); err != nil {
+		t.Errorf("CheckTenant() without quota error = %v", err)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/mlOS-foundation/axon/internal/registry/core"
 	"github.com/mlOS-foundation/axon/pkg/types"
 )
 
@@ -94,6 +96,26 @@ func TestPyTorchHubAdapter_GetManifest(t *testing.T) {
 	}
 }
 
+func TestPyTorchHubAdapter_GetManifest_RepoNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	adapter := NewPyTorchHubAdapter()
+	adapter.baseURL = server.URL
+
+	_, err := adapter.GetManifest(context.Background(), "pytorch", "nonexistent/resnet50", "latest")
+	if err == nil {
+		t.Fatal("GetManifest() should fail when GitHub API reports 404")
+	}
+	if !strings.Contains(err.Error(), "repository not found") {
+		t.Errorf("GetManifest() error = %v, want 'repository not found'", err)
+	}
+}
+
 func TestPyTorchHubAdapter_ParseHubconf(t *testing.T) {
 	adapter := NewPyTorchHubAdapter()
 
@@ -343,6 +365,30 @@ func TestTensorFlowHubAdapter_GetManifest(t *testing.T) {
 	}
 }
 
+func TestTensorFlowHubAdapter_GetManifest_NotFound(t *testing.T) {
+	// TF Hub has no existence API: unknown models land on a search page, detected heuristically
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><head><title>Find Pre-trained Models</title></head></html>"))
+	}))
+	defer server.Close()
+
+	adapter := NewTensorFlowHubAdapter()
+	adapter.baseURL = server.URL
+
+	_, err := adapter.GetManifest(context.Background(), "tfhub", "google/does-not-exist", "latest")
+	if err == nil {
+		t.Fatal("GetManifest() should fail for missing model")
+	}
+	if !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("GetManifest() error = %v, want 'model not found'", err)
+	}
+}
+
 func TestTensorFlowHubAdapter_Search(t *testing.T) {
 	adapter := NewTensorFlowHubAdapter()
 	ctx := context.Background()
@@ -419,3 +465,31 @@ func TestTensorFlowHubAdapter_DownloadPackage_WithMockServer(t *testing.T) {
 		t.Logf("DownloadPackage() failed as expected with mock server: %v", err)
 	}
 }
+
+func TestDefaultFactories(t *testing.T) {
+	adapterRegistry := core.NewAdapterRegistry()
+	RegisterDefaultFactories(adapterRegistry)
+
+	for _, name := range DefaultAdapterOrder {
+		config := core.AdapterConfig{BaseURL: "https://example.com/", Timeout: 10}
+		adapter, err := adapterRegistry.CreateAdapter(name, config)
+		if err != nil {
+			t.Fatalf("CreateAdapter(%s) error = %v", name, err)
+		}
+		if adapter.Name() != name {
+			t.Errorf("CreateAdapter(%s) created %s", name, adapter.Name())
+		}
+	}
+
+	pytorch, _ := adapterRegistry.CreateAdapter("pytorch", core.AdapterConfig{BaseURL: "https://ghe.example.com/api/v3/", Timeout: 10})
+	if p := pytorch.(*PyTorchHubAdapter); p.baseURL != "https://ghe.example.com/api/v3" || p.httpClient.Timeout != 10*time.Second {
+		t.Errorf("pytorch adapter = %s with timeout %v, want the configured base URL and timeout", p.baseURL, p.httpClient.Timeout)
+	}
+	hf, _ := adapterRegistry.CreateAdapter("huggingface", core.AdapterConfig{})
+	if h := hf.(*HuggingFaceAdapter); h.baseURL != "https://huggingface.co" {
+		t.Errorf("huggingface base URL = %s, want the default", h.baseURL)
+	}
+	if _, err := adapterRegistry.CreateAdapter("local", core.AdapterConfig{}); err == nil {
+		t.Error("CreateAdapter(local) without a base URL succeeded")
+	}
+}
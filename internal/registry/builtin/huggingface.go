@@ -10,12 +10,15 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/mlOS-foundation/axon/internal/model"
 	"github.com/mlOS-foundation/axon/internal/registry/core"
 	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 )
 
 // HuggingFaceAdapter implements RepositoryAdapter for Hugging Face Hub.
@@ -24,7 +27,6 @@ type HuggingFaceAdapter struct {
 	httpClient *core.HTTPClient
 	baseURL    string
 	token      string
-	validator  *core.ModelValidator
 }
 
 // NewHuggingFaceAdapter creates a new Hugging Face adapter.
@@ -34,7 +36,6 @@ func NewHuggingFaceAdapter() *HuggingFaceAdapter {
 		httpClient: client,
 		baseURL:    "https://huggingface.co",
 		token:      "",
-		validator:  core.NewModelValidator(),
 	}
 }
 
@@ -46,6 +47,32 @@ func NewHuggingFaceAdapterWithToken(token string) *HuggingFaceAdapter {
 	return adapter
 }
 
+// HuggingFaceFactory implements AdapterFactory for creating Hugging Face adapters.
+type HuggingFaceFactory struct{}
+
+// NewHuggingFaceFactory creates a new Hugging Face factory.
+func NewHuggingFaceFactory() *HuggingFaceFactory {
+	return &HuggingFaceFactory{}
+}
+
+// Name returns the factory name.
+func (f *HuggingFaceFactory) Name() string {
+	return "huggingface"
+}
+
+// Create creates a new Hugging Face adapter with the given configuration.
+func (f *HuggingFaceFactory) Create(config core.AdapterConfig) (core.RepositoryAdapter, error) {
+	adapter := NewHuggingFaceAdapter()
+	if config.BaseURL != "" {
+		adapter.baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	}
+	adapter.httpClient = core.NewHTTPClient(adapter.baseURL, factoryTimeout(config))
+	if config.Token != "" {
+		adapter.SetToken(config.Token)
+	}
+	return adapter, nil
+}
+
 // SetToken sets the Hugging Face token (for gated/private models).
 func (h *HuggingFaceAdapter) SetToken(token string) {
 	h.token = token
@@ -94,35 +121,39 @@ func (h *HuggingFaceAdapter) GetManifest(ctx context.Context, namespace, name, v
 		hfModelID = fmt.Sprintf("%s/%s", namespace, name)
 	}
 
-	// Validate model exists on Hugging Face
-	modelURL := fmt.Sprintf("%s/%s", h.baseURL, hfModelID)
-	valid, err := h.validator.ValidateModelExists(ctx, modelURL)
+	// Validate model exists via the Hugging Face model API (404 means not found)
+	info, err := h.modelInfo(ctx, hfModelID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate model existence: %w", err)
 	}
-	if !valid {
+	if !info.found {
 		return nil, fmt.Errorf("model not found: %s/%s@%s", namespace, name, version)
 	}
+	// Private repositories answer 401 without a token (as missing ones do) and 403 to
+	// tokens without read access
+	if info.denied != 0 {
+		return nil, h.accessDenied(hfModelID, info.denied)
+	}
+	// Gated models fail here with what to do, rather than with a 401 or 403 mid-download
+	if info.gated != "" {
+		if err := h.checkGatedAccess(ctx, hfModelID, info); err != nil {
+			return nil, err
+		}
+	}
 
 	// Try to fetch config.json to extract I/O schema
 	// This is optional - if it fails, we'll use generic I/O schema
 	var inputs, outputs []types.IOSpec
-	configURL := fmt.Sprintf("%s/%s/resolve/main/config.json", h.baseURL, hfModelID)
-	tempConfig := filepath.Join(os.TempDir(), fmt.Sprintf("axon-config-%d.json", time.Now().UnixNano()))
-
-	if resp, err := h.httpClient.Get(ctx, configURL); err == nil && resp.StatusCode == http.StatusOK {
-		// Download config.json temporarily
-		if file, err := os.Create(tempConfig); err == nil {
-			io.Copy(file, resp.Body)
-			file.Close()
-			resp.Body.Close()
-
-			// Extract I/O schema from config
-			if extractedInputs, extractedOutputs, err := ExtractIOSchemaFromConfig(tempConfig); err == nil {
-				inputs = extractedInputs
-				outputs = extractedOutputs
-			}
-			os.Remove(tempConfig) // Clean up
+	config, err := h.configJSON(ctx, hfModelID)
+	if err == nil {
+		// Vision and audio models size their inputs in preprocessor_config.json
+		var preprocessor []byte
+		if containsString(info.files, PreprocessorConfigFile) {
+			preprocessor, _ = h.hubFile(ctx, hfModelID, PreprocessorConfigFile)
+		}
+		if extractedInputs, extractedOutputs, err := extractIOSchemaFromConfigData(config, preprocessor); err == nil {
+			inputs = extractedInputs
+			outputs = extractedOutputs
 		}
 	}
 
@@ -144,6 +175,11 @@ func (h *HuggingFaceAdapter) GetManifest(ctx context.Context, namespace, name, v
 		}
 	}
 
+	license := info.license
+	if license == "" {
+		license = "Unknown"
+	}
+
 	// Create manifest with HF download URLs
 	manifest := &types.Manifest{
 		APIVersion: "v1",
@@ -153,7 +189,7 @@ func (h *HuggingFaceAdapter) GetManifest(ctx context.Context, namespace, name, v
 			Namespace:   namespace,
 			Version:     version,
 			Description: fmt.Sprintf("Model from Hugging Face: %s", hfModelID),
-			License:     "Unknown", // Would fetch from HF API
+			License:     license,
 			Created:     time.Now(),
 			Updated:     time.Now(),
 		},
@@ -173,22 +209,13 @@ func (h *HuggingFaceAdapter) GetManifest(ctx context.Context, namespace, name, v
 					},
 				},
 			},
+			// The pipeline tag, or else the architectures in config.json
+			Task: model.DetectTask(info.pipeline, config),
 			IO: types.IO{
 				Inputs:  inputs,
 				Outputs: outputs,
 			},
-			Requirements: types.Requirements{
-				Compute: types.Compute{
-					CPU: types.CPURequirement{
-						MinCores:         2,
-						RecommendedCores: 4,
-					},
-					Memory: types.MemoryRequirement{
-						MinGB:         2.0,
-						RecommendedGB: 4.0,
-					},
-				},
-			},
+			Requirements: model.DefaultRequirements(),
 		},
 		Distribution: types.Distribution{
 			Package: types.PackageInfo{
@@ -201,6 +228,27 @@ func (h *HuggingFaceAdapter) GetManifest(ctx context.Context, namespace, name, v
 		},
 	}
 
+	// Declare the files that will be downloaded with their real sizes, so size policies
+	// can be checked before the download starts
+	files, index, err := h.downloadFiles(ctx, hfModelID, info)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) > 0 {
+		manifest.Spec.Format.Files = files
+	}
+	if index != nil {
+		manifest.Spec.Format.Shards = index.ShardMap()
+	}
+
+	// Size the hardware requirements from the weights instead of the defaults
+	if requirements, ok := model.EstimateRequirements(files, info.parameters); ok {
+		manifest.Spec.Requirements = requirements
+	}
+	// Quantized checkpoints require a GPU. The accelerators of the execution format are
+	// added on install, once the format is known.
+	model.DetectGPURequirement(&manifest.Spec.Requirements, config, "")
+
 	return manifest, nil
 }
 
@@ -220,51 +268,64 @@ func (h *HuggingFaceAdapter) DownloadPackage(ctx context.Context, manifest *type
 	defer builder.Cleanup()
 
 	// Get model file list from Hugging Face API
-	allFiles, err := h.getModelFiles(ctx, hfModelID)
-	if err != nil {
+	var allFiles []string
+	source := types.ProvenanceSource{
+		Adapter:    h.Name(),
+		Model:      hfModelID,
+		Repository: fmt.Sprintf("%s/%s", h.baseURL, hfModelID),
+	}
+	if info, err := h.modelInfo(ctx, hfModelID); err == nil {
+		allFiles = info.files
+		source.Revision = info.revision
+		source.Gated = info.gated
+	}
+	builder.SetSource(source)
+	if allFiles == nil {
 		// Fallback to common files if API fails
 		allFiles = []string{"config.json", "pytorch_model.bin", "tokenizer.json", "tokenizer_config.json", "vocab.txt", "vocab.json"}
 	}
 
-	// Detect best format and select appropriate files
-	// Priority: GGUF > ONNX > SafeTensors > PyTorch (reduces download size and skips conversion)
-	formatType, modelFiles := h.detectModelFormat(allFiles)
+	formatType, modelFiles, index, err := h.selectRepoFiles(ctx, hfModelID, allFiles)
+	if err != nil {
+		return err
+	}
 	if formatType != "unknown" && formatType != "pytorch" {
 		fmt.Printf("✓ Detected %s format, selecting optimized file set\n", strings.ToUpper(formatType))
 		// Update manifest with detected format
 		manifest.Spec.Format.Type = formatType
 		manifest.Spec.Format.ExecutionFormat = formatType
 	}
-
-	// Ensure tokenizer files are included for non-GGUF formats
-	// (GGUF models have tokenizer embedded)
-	if formatType != "gguf" {
-		tokenizerFiles := []string{"tokenizer.json", "tokenizer_config.json", "vocab.txt", "vocab.json"}
-		for _, tokenizerFile := range tokenizerFiles {
-			// Check if already in list
-			found := false
-			for _, file := range modelFiles {
-				if file == tokenizerFile {
-					found = true
-					break
-				}
-			}
-			if !found {
-				// Try to add tokenizer file (will be skipped if not available)
-				modelFiles = append(modelFiles, tokenizerFile)
-			}
-		}
+	var shards []string
+	if index != nil {
+		shards = index.Shards()
+		manifest.Spec.Format.Shards = index.ShardMap()
+		fmt.Printf("✓ Sharded checkpoint: %d shards listed in %s\n", len(shards), index.Name)
 	}
 
 	// Download files from Hugging Face
-	httpClient := &http.Client{Timeout: 10 * time.Minute}
+	httpClient := &http.Client{Timeout: 10 * time.Minute, Transport: core.Transport}
 	downloadedFiles := []string{}
 
-	for _, file := range modelFiles {
+	// Index-based loop: alternate weight files may be appended while downloading
+	var missingWeights []string
+	for i := 0; i < len(modelFiles); i++ {
+		file := modelFiles[i]
 		url := fmt.Sprintf("%s/%s/resolve/main/%s", h.baseURL, hfModelID, file)
 
 		// Create temp file for download
-		tempFile := filepath.Join(os.TempDir(), fmt.Sprintf("axon-hf-%s-%d", file, time.Now().UnixNano()))
+		tempFile := filepath.Join(utils.TempDir(), fmt.Sprintf("axon-hf-%s-%d", path.Base(file), time.Now().UnixNano()))
+
+		// config.json and preprocessor_config.json were already fetched for the manifest
+		if file == "config.json" || file == PreprocessorConfigFile {
+			if data, err := h.hubFile(ctx, hfModelID, file); err == nil {
+				if err := os.WriteFile(tempFile, data, 0644); err == nil && builder.AddFile(tempFile, file) == nil {
+					builder.SetFileURL(file, url)
+					downloadedFiles = append(downloadedFiles, file)
+				}
+				_ = os.Remove(tempFile)
+			}
+			continue
+		}
 
 		// Add auth header if token is provided
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
@@ -276,25 +337,59 @@ func (h *HuggingFaceAdapter) DownloadPackage(ctx context.Context, manifest *type
 		}
 
 		resp, err := httpClient.Do(req)
+		if err == nil && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			_ = resp.Body.Close()
+			return fmt.Errorf("failed to download %s: %w", file, h.accessDenied(hfModelID, resp.StatusCode))
+		}
 		if err != nil || resp.StatusCode != http.StatusOK {
+			notFound := resp != nil && resp.StatusCode == http.StatusNotFound
 			if resp != nil {
 				_ = resp.Body.Close()
 			}
+			// Every shard of an indexed checkpoint is needed
+			if containsString(shards, file) {
+				if err == nil {
+					err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+				}
+				return fmt.Errorf("incomplete sharded checkpoint: failed to download shard %s: %w", file, err)
+			}
+			// Repos often publish only one of pytorch_model.bin / model.safetensors
+			if notFound && isWeightFile(file) {
+				missingWeights = append(missingWeights, file)
+				if alt := alternateWeightFile(file); alt != "" && !containsString(modelFiles, alt) {
+					fmt.Printf("⚠️  %s not found, trying %s\n", file, alt)
+					modelFiles = append(modelFiles, alt)
+				}
+			}
 			continue // Skip missing files
 		}
 
-		// Download file
-		if err := core.DownloadFile(ctx, httpClient, url, tempFile, progress); err != nil {
-			_ = resp.Body.Close()
+		// Download file from the response already in hand; large files come from the
+		// LFS/Xet CDN in parallel ranges
+		err = core.SaveResponseChunked(ctx, httpClient, resp, tempFile, progress)
+		_ = resp.Body.Close()
+		if err != nil {
+			_ = os.Remove(tempFile)
+			if containsString(shards, file) {
+				return fmt.Errorf("incomplete sharded checkpoint: failed to download shard %s: %w", file, err)
+			}
 			continue
 		}
-		_ = resp.Body.Close()
+		// A shard must hold the tensors the index maps to it
+		if formatType == "safetensors" && containsString(shards, file) {
+			if err := model.CheckSafetensorsShard(tempFile, index.Tensors(file)); err != nil {
+				_ = os.Remove(tempFile)
+				return fmt.Errorf("incomplete sharded checkpoint: shard %s: %w", file, err)
+			}
+		}
 
 		// Add to package
 		if err := builder.AddFile(tempFile, file); err != nil {
 			_ = os.Remove(tempFile)
 			continue
 		}
+		builder.SetFileURL(file, url)
+		builder.SetFileETag(file, resp.Header.Get("ETag"))
 
 		downloadedFiles = append(downloadedFiles, file)
 		_ = os.Remove(tempFile) // Clean up temp file
@@ -303,6 +398,23 @@ func (h *HuggingFaceAdapter) DownloadPackage(ctx context.Context, manifest *type
 	if len(downloadedFiles) == 0 {
 		return fmt.Errorf("no files downloaded from Hugging Face for %s", hfModelID)
 	}
+	if index != nil {
+		if missing := index.Missing(downloadedFiles); len(missing) > 0 {
+			return fmt.Errorf("incomplete sharded checkpoint: failed to package shards %s", strings.Join(missing, ", "))
+		}
+	}
+
+	// Don't produce a package with configs and tokenizers but no weights
+	hasWeights := false
+	for _, file := range downloadedFiles {
+		if isWeightFile(file) {
+			hasWeights = true
+			break
+		}
+	}
+	if !hasWeights {
+		return fmt.Errorf("no model weights downloaded from Hugging Face for %s (not found: %s)", hfModelID, strings.Join(missingWeights, ", "))
+	}
 
 	// Build package
 	if err := builder.Build(destPath); err != nil {
@@ -310,43 +422,272 @@ func (h *HuggingFaceAdapter) DownloadPackage(ctx context.Context, manifest *type
 	}
 
 	// Update manifest with checksum
-	if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
 		fmt.Printf("Warning: failed to update manifest checksum: %v\n", err)
 	}
 
 	return nil
 }
 
-// getModelFiles fetches the list of files from Hugging Face API.
-func (h *HuggingFaceAdapter) getModelFiles(ctx context.Context, modelID string) ([]string, error) {
-	url := fmt.Sprintf("%s/api/models/%s", h.baseURL, modelID)
+// LatestRevision returns the commit SHA of the model repository's main branch
+func (h *HuggingFaceAdapter) LatestRevision(ctx context.Context, namespace, name string) (string, error) {
+	hfModelID := name
+	if namespace != "" && namespace != "hf" {
+		hfModelID = fmt.Sprintf("%s/%s", namespace, name)
+	}
+	info, err := h.modelInfo(ctx, hfModelID)
+	if err != nil {
+		return "", err
+	}
+	if !info.found {
+		return "", fmt.Errorf("model %s not found on Hugging Face", hfModelID)
+	}
+	if info.revision == "" {
+		return "", fmt.Errorf("no revision reported by Hugging Face for %s", hfModelID)
+	}
+	return info.revision, nil
+}
 
-	resp, err := h.httpClient.Get(ctx, url)
+// selectFiles returns the detected format of a repository and the files to download
+// from it. Priority: GGUF > ONNX > SafeTensors > PyTorch (reduces download size and
+// skips conversion).
+func (h *HuggingFaceAdapter) selectFiles(allFiles []string) (string, []string) {
+	formatType, modelFiles := h.detectModelFormat(allFiles)
+
+	// Ensure tokenizer files are included for non-GGUF formats
+	// (GGUF models have tokenizer embedded)
+	if formatType != "gguf" {
+		tokenizerFiles := []string{"tokenizer.json", "tokenizer_config.json", "vocab.txt", "vocab.json"}
+		for _, tokenizerFile := range tokenizerFiles {
+			// Try to add tokenizer file (will be skipped if not available)
+			if !containsString(modelFiles, tokenizerFile) {
+				modelFiles = append(modelFiles, tokenizerFile)
+			}
+		}
+	}
+	return formatType, modelFiles
+}
+
+// selectRepoFiles returns the detected format of a repository and the files to download
+// from it like selectFiles, but for sharded weights exactly the shards their index
+// references (returned too), so leftover or duplicate weight files aren't mixed in
+func (h *HuggingFaceAdapter) selectRepoFiles(ctx context.Context, modelID string, allFiles []string) (string, []string, *model.ShardIndex, error) {
+	formatType, modelFiles := h.selectFiles(allFiles)
+	indexFile := model.ShardIndexFile(formatType)
+	if indexFile == "" || !containsString(allFiles, indexFile) {
+		return formatType, modelFiles, nil, nil
+	}
+
+	data, err := h.hubFile(ctx, modelID, indexFile)
 	if err != nil {
-		return nil, err
+		return "", nil, nil, fmt.Errorf("failed to fetch %s: %w", indexFile, err)
+	}
+	index, err := model.ParseShardIndex(indexFile, data)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if missing := index.Missing(allFiles); len(missing) > 0 {
+		return "", nil, nil, fmt.Errorf("incomplete sharded checkpoint in %s: %s references %s, which the repository doesn't have", modelID, indexFile, strings.Join(missing, ", "))
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	selected := index.Shards()
+	for _, file := range modelFiles {
+		if weightFormat(file) != formatType {
+			selected = append(selected, file)
+		}
+	}
+	return formatType, selected, index, nil
+}
+
+// weightFormat returns the format of a safetensors or PyTorch weight file ("" for others)
+func weightFormat(file string) string {
+	lower := strings.ToLower(file)
+	switch {
+	case strings.HasSuffix(lower, ".safetensors"):
+		return "safetensors"
+	case strings.HasSuffix(lower, ".bin") || strings.HasSuffix(lower, ".pt") || strings.HasSuffix(lower, ".pth"):
+		return "pytorch"
 	}
+	return ""
+}
 
-	var modelInfo struct {
-		Siblings []struct {
-			RFileName string `json:"rfilename"`
-		} `json:"siblings"`
+// downloadFiles returns the files DownloadPackage will fetch from a repository, with the
+// sizes reported by the API (nil if the API didn't list the repository's files), and the
+// shard index of sharded weights
+func (h *HuggingFaceAdapter) downloadFiles(ctx context.Context, modelID string, info *hfModelInfo) ([]types.ModelFile, *model.ShardIndex, error) {
+	if info.files == nil {
+		return nil, nil, nil
+	}
+	_, selected, index, err := h.selectRepoFiles(ctx, modelID, info.files)
+	if err != nil || info.sizes == nil {
+		return nil, index, err
 	}
+	var files []types.ModelFile
+	for _, file := range selected {
+		if size, ok := info.sizes[file]; ok {
+			files = append(files, types.ModelFile{Path: file, Size: size})
+		}
+	}
+	return files, index, nil
+}
+
+// hfModelInfo is what the Hugging Face model API reports about a repository
+type hfModelInfo struct {
+	found    bool             // False only when the API answers 404
+	denied   int              // 401 or 403 when the API refused the token (or its absence)
+	files    []string         // Nil when the API couldn't list the files
+	sizes    map[string]int64 // File sizes in bytes, for the files the API reported them for
+	revision string           // Commit SHA of the repository's main branch, when reported
+	license  string           // License declared in the model card, when reported
+	pipeline string           // Pipeline tag (the model's task), when reported
+	gated    string           // core.GatedAuto or core.GatedManual for gated models
+
+	parameters int64 // Parameter count of the safetensors weights, when reported
+}
+
+// modelInfo queries the model API once per command (and token) for whether the model
+// exists and which files it has. Server errors are treated as "might exist" so the
+// download step can surface a more precise error.
+func (h *HuggingFaceAdapter) modelInfo(ctx context.Context, modelID string) (*hfModelInfo, error) {
+	url := fmt.Sprintf("%s/api/models/%s?blobs=true", h.baseURL, modelID)
+	key := fmt.Sprintf("hf-model-info:%s:%t", url, h.token != "")
+	value, err := core.ResolutionFrom(ctx).Do(key, func() (interface{}, error) {
+		resp, err := h.httpClient.Get(ctx, url)
+		if err != nil {
+			return nil, fmt.Errorf("network error during validation: %w", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+
+		info := &hfModelInfo{found: resp.StatusCode != http.StatusNotFound}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			info.denied = resp.StatusCode
+		}
+		if resp.StatusCode != http.StatusOK {
+			return info, nil
+		}
 
-	if err := json.NewDecoder(resp.Body).Decode(&modelInfo); err != nil {
+		var modelInfo struct {
+			SHA         string      `json:"sha"`
+			Tags        []string    `json:"tags"`
+			PipelineTag string      `json:"pipeline_tag"`
+			Gated       interface{} `json:"gated"` // false, or the approval mode
+			CardData    struct {
+				License interface{} `json:"license"` // A string, or a list for multi-licensed models
+			} `json:"cardData"`
+			Siblings []struct {
+				RFileName string `json:"rfilename"`
+				Size      int64  `json:"size"` // Reported with ?blobs=true
+			} `json:"siblings"`
+			Safetensors struct {
+				Total int64 `json:"total"`
+			} `json:"safetensors"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&modelInfo); err != nil {
+			return info, nil
+		}
+		info.revision = modelInfo.SHA
+		info.license = hfLicense(modelInfo.Tags, modelInfo.CardData.License)
+		info.pipeline = modelInfo.PipelineTag
+		if gated, ok := modelInfo.Gated.(string); ok {
+			info.gated = gated
+		}
+		info.parameters = modelInfo.Safetensors.Total
+		if len(modelInfo.Siblings) == 0 {
+			return info, nil
+		}
+		info.sizes = make(map[string]int64, len(modelInfo.Siblings))
+		for _, sibling := range modelInfo.Siblings {
+			info.files = append(info.files, sibling.RFileName)
+			if sibling.Size > 0 {
+				info.sizes[sibling.RFileName] = sibling.Size
+			}
+		}
+		return info, nil
+	})
+	if err != nil {
 		return nil, err
 	}
+	return value.(*hfModelInfo), nil
+}
+
+// accessDenied returns the error of a request for the model refused with status
+func (h *HuggingFaceAdapter) accessDenied(modelID string, status int) error {
+	return &core.AccessDeniedError{Adapter: h.Name(), Model: modelID, Status: status, HasToken: h.token != ""}
+}
+
+// checkGatedAccess checks that the token (if any) may download a gated model's files,
+// by requesting one of them. The model API reports gated models to everyone, but the
+// files answer 401 without a token and 403 to accounts that haven't been granted access.
+func (h *HuggingFaceAdapter) checkGatedAccess(ctx context.Context, modelID string, info *hfModelInfo) error {
+	file := "config.json"
+	if len(info.files) > 0 && !containsString(info.files, file) {
+		file = info.files[0]
+	}
+	resp, err := h.httpClient.Do(ctx, http.MethodHead, fmt.Sprintf("%s/%s/resolve/main/%s", h.baseURL, modelID, file), nil)
+	if err != nil {
+		// The download reports network errors
+		return nil
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	return &core.GatedModelError{
+		Adapter:  h.Name(),
+		Model:    modelID,
+		URL:      fmt.Sprintf("%s/%s", h.baseURL, modelID),
+		Approval: info.gated,
+		HasToken: h.token != "",
+	}
+}
 
-	var files []string
-	for _, sibling := range modelInfo.Siblings {
-		files = append(files, sibling.RFileName)
+// hfLicense returns the license of a model from its "license:" tags, falling back to the
+// model card. Multiple licenses are joined with " OR ".
+func hfLicense(tags []string, cardLicense interface{}) string {
+	var licenses []string
+	for _, tag := range tags {
+		if license, ok := strings.CutPrefix(tag, "license:"); ok && license != "" {
+			licenses = append(licenses, license)
+		}
 	}
+	if len(licenses) == 0 {
+		switch v := cardLicense.(type) {
+		case string:
+			licenses = append(licenses, v)
+		case []interface{}:
+			for _, item := range v {
+				if license, ok := item.(string); ok {
+					licenses = append(licenses, license)
+				}
+			}
+		}
+	}
+	return strings.Join(licenses, " OR ")
+}
+
+// configJSON fetches the model's config.json once per command
+func (h *HuggingFaceAdapter) configJSON(ctx context.Context, modelID string) ([]byte, error) {
+	return h.hubFile(ctx, modelID, "config.json")
+}
 
-	return files, nil
+// hubFile fetches a small file of the model's repository once per command
+func (h *HuggingFaceAdapter) hubFile(ctx context.Context, modelID, file string) ([]byte, error) {
+	url := fmt.Sprintf("%s/%s/resolve/main/%s", h.baseURL, modelID, file)
+	return core.ResolutionFrom(ctx).Bytes("hf-file:"+url, func() ([]byte, error) {
+		resp, err := h.httpClient.Get(ctx, url)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		}
+		return io.ReadAll(core.LimitDownload(ctx, resp.Body))
+	})
 }
 
 // detectModelFormat analyzes file list and returns the best format to use.
@@ -404,6 +745,41 @@ func (h *HuggingFaceAdapter) detectModelFormat(files []string) (string, []string
 	return "unknown", files
 }
 
+// isWeightFile reports whether a file holds model weights (as opposed to config or tokenizer files)
+func isWeightFile(file string) bool {
+	lower := strings.ToLower(file)
+	for _, ext := range []string{".bin", ".safetensors", ".pt", ".pth", ".gguf", ".onnx", ".h5", ".msgpack"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// alternateWeightFile returns the equivalent weight file in the other Hugging Face
+// serialization format (PyTorch pickle <-> SafeTensors), or "" if there is none.
+// Handles sharded files, e.g. pytorch_model-00001-of-00002.bin <-> model-00001-of-00002.safetensors.
+func alternateWeightFile(file string) string {
+	dir, base := path.Split(file)
+	switch {
+	case strings.HasPrefix(base, "pytorch_model") && strings.HasSuffix(base, ".bin"):
+		return dir + "model" + strings.TrimSuffix(strings.TrimPrefix(base, "pytorch_model"), ".bin") + ".safetensors"
+	case strings.HasPrefix(base, "model") && strings.HasSuffix(base, ".safetensors"):
+		return dir + "pytorch_model" + strings.TrimSuffix(strings.TrimPrefix(base, "model"), ".safetensors") + ".bin"
+	}
+	return ""
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
 // selectBestGGUF picks the best GGUF file from a list.
 // Prefers Q4_K_M (good balance of quality/size), then Q4_K_S, then any Q4, then first available.
 func selectBestGGUF(files []string) string {
@@ -420,3 +796,111 @@ func selectBestGGUF(files []string) string {
 	// Return first GGUF file if no preference matched
 	return files[0]
 }
+
+// Token scopes 'axon login --scope' can require of a Hugging Face token
+const (
+	TokenScopeRead  = "read"  // Download models, including those of private organizations
+	TokenScopeWrite = "write" // Also push to repositories
+)
+
+// Fine-grained token permissions granting the scopes
+const (
+	hfPermissionRead  = "repo.content.read"
+	hfPermissionWrite = "repo.write"
+)
+
+// HFTokenInfo is what Hugging Face reports about an access token
+type HFTokenInfo struct {
+	User   string         // Account the token belongs to
+	Role   string         // "read", "write" or "fineGrained"
+	Global []string       // Permissions of a fine-grained token everywhere the account has access
+	Scoped []HFTokenScope // Permissions of a fine-grained token on specific users, organizations or repositories
+}
+
+// HFTokenScope is the permissions of a fine-grained token on one entity
+type HFTokenScope struct {
+	Entity      string // e.g. "org acme" or "model acme/internal-bert"
+	Permissions []string
+}
+
+// Allows reports whether the token has scope (TokenScopeRead or TokenScopeWrite)
+// somewhere. Fine-grained tokens may have it for some organizations only.
+func (i *HFTokenInfo) Allows(scope string) bool {
+	switch i.Role {
+	case "write":
+		return true
+	case "read":
+		return scope == TokenScopeRead
+	}
+	permissions := append([]string{}, i.Global...)
+	for _, s := range i.Scoped {
+		permissions = append(permissions, s.Permissions...)
+	}
+	return containsString(permissions, hfPermissionWrite) ||
+		(scope == TokenScopeRead && containsString(permissions, hfPermissionRead))
+}
+
+// String summarizes the token's role, or for fine-grained tokens where it can read
+func (i *HFTokenInfo) String() string {
+	if i.Role != "fineGrained" {
+		return i.Role + " token"
+	}
+	var parts []string
+	if containsString(i.Global, hfPermissionRead) || containsString(i.Global, hfPermissionWrite) {
+		parts = append(parts, "all repositories")
+	}
+	for _, s := range i.Scoped {
+		if containsString(s.Permissions, hfPermissionRead) || containsString(s.Permissions, hfPermissionWrite) {
+			parts = append(parts, s.Entity)
+		}
+	}
+	if len(parts) == 0 {
+		return "fine-grained token without repository access"
+	}
+	return "fine-grained token for " + strings.Join(parts, ", ")
+}
+
+// TokenInfo asks Hugging Face about the adapter's token
+func (h *HuggingFaceAdapter) TokenInfo(ctx context.Context) (*HFTokenInfo, error) {
+	resp, err := h.httpClient.Get(ctx, h.baseURL+"/api/whoami-v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("the token was rejected by Hugging Face (HTTP 401)")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var whoami struct {
+		Name string `json:"name"`
+		Auth struct {
+			AccessToken struct {
+				Role        string `json:"role"`
+				FineGrained struct {
+					Global []string `json:"global"`
+					Scoped []struct {
+						Entity struct {
+							Type string `json:"type"`
+							Name string `json:"name"`
+						} `json:"entity"`
+						Permissions []string `json:"permissions"`
+					} `json:"scoped"`
+				} `json:"fineGrained"`
+			} `json:"accessToken"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&whoami); err != nil {
+		return nil, fmt.Errorf("failed to parse token info: %w", err)
+	}
+	token := whoami.Auth.AccessToken
+	info := &HFTokenInfo{User: whoami.Name, Role: token.Role, Global: token.FineGrained.Global}
+	for _, s := range token.FineGrained.Scoped {
+		info.Scoped = append(info.Scoped, HFTokenScope{Entity: s.Entity.Type + " " + s.Entity.Name, Permissions: s.Permissions})
+	}
+	return info, nil
+}
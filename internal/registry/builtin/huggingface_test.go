@@ -0,0 +1,539 @@
+package builtin
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestHuggingFaceAdapter_GetManifest_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/bert-base-uncased":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"id": "bert-base-uncased"}`))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": "Repository not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+	ctx := context.Background()
+
+	if _, err := adapter.GetManifest(ctx, "hf", "bert-base-uncased", "latest"); err != nil {
+		t.Errorf("GetManifest() for existing model error = %v", err)
+	}
+
+	_, err := adapter.GetManifest(ctx, "hf", "does-not-exist", "latest")
+	if err == nil {
+		t.Fatal("GetManifest() should fail for missing model")
+	}
+	if !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("GetManifest() error = %v, want 'model not found'", err)
+	}
+}
+
+func TestHuggingFaceAdapter_GetManifest_License(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/tagged":
+			_, _ = w.Write([]byte(`{"tags": ["pytorch", "license:agpl-3.0"], "cardData": {"license": "mit"}}`))
+		case "/api/models/card-only":
+			_, _ = w.Write([]byte(`{"cardData": {"license": ["openrail", "mit"]}}`))
+		case "/api/models/undeclared":
+			_, _ = w.Write([]byte(`{"id": "undeclared"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"tagged", "agpl-3.0"},
+		{"card-only", "openrail OR mit"},
+		{"undeclared", "Unknown"},
+	}
+	for _, tt := range tests {
+		manifest, err := adapter.GetManifest(context.Background(), "hf", tt.model, "latest")
+		if err != nil {
+			t.Fatalf("GetManifest(%s) error = %v", tt.model, err)
+		}
+		if manifest.Metadata.License != tt.want {
+			t.Errorf("GetManifest(%s) license = %q, want %q", tt.model, manifest.Metadata.License, tt.want)
+		}
+	}
+}
+
+func TestHuggingFaceAdapter_GetManifest_Task(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/tagged":
+			_, _ = w.Write([]byte(`{"pipeline_tag": "image-classification"}`))
+		case "/api/models/untagged":
+			_, _ = w.Write([]byte(`{"id": "untagged"}`))
+		case "/untagged/resolve/main/config.json":
+			_, _ = w.Write([]byte(`{"model_type": "whisper", "architectures": ["WhisperForConditionalGeneration"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+
+	for model, want := range map[string]string{"tagged": "image-classification", "untagged": "automatic-speech-recognition"} {
+		manifest, err := adapter.GetManifest(context.Background(), "hf", model, "latest")
+		if err != nil {
+			t.Fatalf("GetManifest(%s) error = %v", model, err)
+		}
+		if manifest.Spec.Task != want {
+			t.Errorf("GetManifest(%s) task = %q, want %q", model, manifest.Spec.Task, want)
+		}
+	}
+}
+
+func TestHuggingFaceAdapter_GetManifest_FileSizes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/sized":
+			if r.URL.Query().Get("blobs") != "true" {
+				t.Errorf("model info requested without blobs=true: %s", r.URL)
+			}
+			_, _ = w.Write([]byte(`{"siblings": [
+				{"rfilename": "config.json", "size": 600},
+				{"rfilename": "model.safetensors", "size": 440000000},
+				{"rfilename": "pytorch_model.bin", "size": 440000000},
+				{"rfilename": "tokenizer.json", "size": 700000},
+				{"rfilename": "README.md", "size": 9000}
+			]}`))
+		case "/api/models/unsized":
+			_, _ = w.Write([]byte(`{"siblings": [{"rfilename": "model.safetensors"}]}`))
+		case "/api/models/counted":
+			_, _ = w.Write([]byte(`{"safetensors": {"total": 7000000000}, "siblings": [{"rfilename": "model.safetensors"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+
+	manifest, err := adapter.GetManifest(context.Background(), "hf", "sized", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	// Only the files DownloadPackage will fetch: safetensors wins over the .bin and README isn't downloaded
+	want := []types.ModelFile{
+		{Path: "model.safetensors", Size: 440000000},
+		{Path: "config.json", Size: 600},
+		{Path: "tokenizer.json", Size: 700000},
+	}
+	if !reflect.DeepEqual(manifest.Spec.Format.Files, want) {
+		t.Errorf("Format.Files = %+v, want %+v", manifest.Spec.Format.Files, want)
+	}
+	// Requirements are sized from the weights
+	if memory := manifest.Spec.Requirements.Compute.Memory; memory.MinGB != 1.0 || memory.RecommendedGB != 1.9 {
+		t.Errorf("Memory = %+v, want sized from 440MB of weights", memory)
+	}
+
+	manifest, err = adapter.GetManifest(context.Background(), "hf", "unsized", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if len(manifest.Spec.Format.Files) != 1 || manifest.Spec.Format.Files[0].Size != 0 {
+		t.Errorf("Format.Files = %+v, want the placeholder when sizes aren't reported", manifest.Spec.Format.Files)
+	}
+	if !reflect.DeepEqual(manifest.Spec.Requirements, model.DefaultRequirements()) {
+		t.Errorf("Requirements = %+v, want the defaults when sizes aren't reported", manifest.Spec.Requirements)
+	}
+
+	// Without file sizes, the parameter count sizes the requirements
+	manifest, err = adapter.GetManifest(context.Background(), "hf", "counted", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if gpu := manifest.Spec.Requirements.Compute.GPU; gpu == nil || !gpu.Recommended || manifest.Spec.Requirements.Compute.CPU.MinCores != 8 {
+		t.Errorf("Requirements = %+v, want a GPU recommended for 7B parameters", manifest.Spec.Requirements)
+	}
+}
+
+func TestHuggingFaceAdapter_GetManifest_Gated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/meta-llama/Llama-3-8B":
+			_, _ = w.Write([]byte(`{"gated": "manual", "siblings": [{"rfilename": "config.json"}, {"rfilename": "model.safetensors"}]}`))
+		case "/meta-llama/Llama-3-8B/resolve/main/config.json":
+			switch r.Header.Get("Authorization") {
+			case "":
+				w.WriteHeader(http.StatusUnauthorized)
+			case "Bearer hf_granted":
+				_, _ = w.Write([]byte(`{}`))
+			default:
+				w.WriteHeader(http.StatusForbidden)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		token     string
+		wantGated bool
+	}{
+		{"", true},
+		{"hf_pending", true},
+		{"hf_granted", false},
+	}
+	for _, tt := range tests {
+		adapter := NewHuggingFaceAdapter()
+		adapter.baseURL = server.URL
+		adapter.SetToken(tt.token)
+
+		_, err := adapter.GetManifest(context.Background(), "meta-llama", "Llama-3-8B", "latest")
+		var gated *core.GatedModelError
+		if !errors.As(err, &gated) {
+			if tt.wantGated || err != nil {
+				t.Errorf("token %q: GetManifest() error = %v, want gated %t", tt.token, err, tt.wantGated)
+			}
+			continue
+		}
+		if !tt.wantGated {
+			t.Errorf("token %q: GetManifest() error = %v, want access", tt.token, err)
+			continue
+		}
+		if gated.HasToken != (tt.token != "") || gated.Approval != core.GatedManual || gated.URL != server.URL+"/meta-llama/Llama-3-8B" {
+			t.Errorf("token %q: GatedModelError = %+v", tt.token, gated)
+		}
+	}
+}
+
+func TestHuggingFaceAdapter_PrivateRepository(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.Header.Get("Authorization")
+		switch {
+		case token == "":
+			w.WriteHeader(http.StatusUnauthorized)
+		case token == "Bearer hf_other_org":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/api/models/acme/internal-bert":
+			_, _ = w.Write([]byte(`{"siblings": [{"rfilename": "config.json"}, {"rfilename": "model.safetensors"}]}`))
+		case r.URL.Path == "/acme/internal-bert/resolve/main/config.json":
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			// The token may read the metadata but not the weights
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		token      string
+		wantStatus int
+	}{
+		{"", http.StatusUnauthorized},
+		{"hf_other_org", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		adapter := NewHuggingFaceAdapter()
+		adapter.baseURL = server.URL
+		adapter.SetToken(tt.token)
+		_, err := adapter.GetManifest(context.Background(), "acme", "internal-bert", "latest")
+		var denied *core.AccessDeniedError
+		if !errors.As(err, &denied) || denied.Status != tt.wantStatus || denied.HasToken != (tt.token != "") {
+			t.Errorf("token %q: GetManifest() error = %v, want access denied with %d", tt.token, err, tt.wantStatus)
+		}
+	}
+
+	// A file the token can't read fails the download with the reason
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+	adapter.SetToken("hf_acme")
+	manifest, err := adapter.GetManifest(context.Background(), "acme", "internal-bert", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	err = adapter.DownloadPackage(context.Background(), manifest, filepath.Join(t.TempDir(), "model.axon"), nil)
+	var denied *core.AccessDeniedError
+	if !errors.As(err, &denied) || !strings.Contains(err.Error(), "model.safetensors") {
+		t.Errorf("DownloadPackage() error = %v, want access denied for the weights", err)
+	}
+}
+
+func TestHFTokenInfo_Allows(t *testing.T) {
+	tests := []struct {
+		name      string
+		info      HFTokenInfo
+		wantRead  bool
+		wantWrite bool
+	}{
+		{"read", HFTokenInfo{Role: "read"}, true, false},
+		{"write", HFTokenInfo{Role: "write"}, true, true},
+		{"fine-grained org read", HFTokenInfo{Role: "fineGrained", Scoped: []HFTokenScope{{Entity: "org acme", Permissions: []string{"repo.content.read"}}}}, true, false},
+		{"fine-grained global write", HFTokenInfo{Role: "fineGrained", Global: []string{"repo.write"}}, true, true},
+		{"fine-grained without repositories", HFTokenInfo{Role: "fineGrained", Global: []string{"discussion.write"}}, false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.info.Allows(TokenScopeRead); got != tt.wantRead {
+			t.Errorf("%s: Allows(read) = %t, want %t", tt.name, got, tt.wantRead)
+		}
+		if got := tt.info.Allows(TokenScopeWrite); got != tt.wantWrite {
+			t.Errorf("%s: Allows(write) = %t, want %t", tt.name, got, tt.wantWrite)
+		}
+	}
+}
+
+func TestAlternateWeightFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want string
+	}{
+		{"pytorch_model.bin", "model.safetensors"},
+		{"model.safetensors", "pytorch_model.bin"},
+		{"pytorch_model-00001-of-00002.bin", "model-00001-of-00002.safetensors"},
+		{"model-00002-of-00002.safetensors", "pytorch_model-00002-of-00002.bin"},
+		{"text_encoder/pytorch_model.bin", "text_encoder/model.safetensors"},
+		{"model.onnx", ""},
+		{"config.json", ""},
+	}
+
+	for _, tt := range tests {
+		if got := alternateWeightFile(tt.file); got != tt.want {
+			t.Errorf("alternateWeightFile(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
+
+// packageFileNames lists the entries of a .axon (tar.gz) package
+func packageFileNames(t *testing.T, packagePath string) []string {
+	t.Helper()
+
+	f, err := os.Open(packagePath)
+	if err != nil {
+		t.Fatalf("failed to open package: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("failed to read gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+	return names
+}
+
+func TestHuggingFaceAdapter_DownloadPackage_AlternateWeightFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tiny-model/resolve/main/config.json":
+			_, _ = w.Write([]byte(`{"model_type": "bert"}`))
+		case "/tiny-model/resolve/main/model.safetensors":
+			_, _ = w.Write([]byte("weights"))
+		case "/api/models/tiny-model":
+			// Force the fallback file list, which only names pytorch_model.bin
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+
+	manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: "tiny-model"}}
+	destPath := filepath.Join(t.TempDir(), "tiny-model.axon")
+	if err := adapter.DownloadPackage(context.Background(), manifest, destPath, nil); err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+
+	names := packageFileNames(t, destPath)
+	if !containsString(names, "model.safetensors") {
+		t.Errorf("package files = %v, want model.safetensors", names)
+	}
+}
+
+func TestHuggingFaceAdapter_DownloadPackage_NoWeights(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tiny-model/resolve/main/config.json" {
+			_, _ = w.Write([]byte(`{"model_type": "bert"}`))
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+
+	manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "hf", Name: "tiny-model"}}
+	err := adapter.DownloadPackage(context.Background(), manifest, filepath.Join(t.TempDir(), "tiny-model.axon"), nil)
+	if err == nil || !strings.Contains(err.Error(), "no model weights") {
+		t.Errorf("DownloadPackage() error = %v, want 'no model weights'", err)
+	}
+}
+
+func TestHuggingFaceAdapter_InstallRequestsEachEndpointOnce(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/api/models/tiny-model":
+			_, _ = w.Write([]byte(`{"siblings": [{"rfilename": "config.json"}, {"rfilename": "preprocessor_config.json"}, {"rfilename": "model.safetensors"}]}`))
+		case "/tiny-model/resolve/main/config.json":
+			_, _ = w.Write([]byte(`{"model_type": "vit", "image_size": 384}`))
+		case "/tiny-model/resolve/main/preprocessor_config.json":
+			_, _ = w.Write([]byte(`{"size": {"height": 384, "width": 384}}`))
+		case "/tiny-model/resolve/main/model.safetensors":
+			_, _ = w.Write([]byte("weights"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+	ctx := core.WithResolution(context.Background(), core.NewResolution())
+
+	manifest, err := core.ResolveManifest(ctx, adapter, "hf", "tiny-model", "latest")
+	if err != nil {
+		t.Fatalf("ResolveManifest() error = %v", err)
+	}
+	if _, err := core.ResolveManifest(ctx, adapter, "hf", "tiny-model", "latest"); err != nil {
+		t.Fatalf("second ResolveManifest() error = %v", err)
+	}
+	destPath := filepath.Join(t.TempDir(), "tiny-model.axon")
+	if err := adapter.DownloadPackage(ctx, manifest, destPath, nil); err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+
+	if inputs := manifest.Spec.IO.Inputs; len(inputs) != 1 || !reflect.DeepEqual(inputs[0].Shape, []int{-1, 3, 384, 384}) {
+		t.Errorf("inputs = %+v, want pixel_values [-1 3 384 384]", inputs)
+	}
+	for _, path := range []string{"/api/models/tiny-model", "/tiny-model/resolve/main/config.json", "/tiny-model/resolve/main/preprocessor_config.json", "/tiny-model/resolve/main/model.safetensors"} {
+		if requests[path] != 1 {
+			t.Errorf("%s requested %d times, want 1", path, requests[path])
+		}
+	}
+	names := packageFileNames(t, destPath)
+	for _, want := range []string{"config.json", "preprocessor_config.json", "model.safetensors"} {
+		if !containsString(names, want) {
+			t.Errorf("package files = %v, want %s", names, want)
+		}
+	}
+}
+
+func TestHuggingFaceAdapter_ShardedCheckpoint(t *testing.T) {
+	shard := func(tensor string) []byte {
+		header := `{"` + tensor + `": {"dtype": "F32", "shape": [1], "data_offsets": [0, 4]}}`
+		data := append([]byte{byte(len(header)), 0, 0, 0, 0, 0, 0, 0}, header...)
+		return append(data, 0, 0, 0, 0)
+	}
+	index := `{"metadata": {"total_size": 8}, "weight_map": {"a": "model-00001-of-00002.safetensors", "b": "model-00002-of-00002.safetensors"}}`
+	var truncated atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/big-model":
+			// A stray consolidated file next to the shards
+			_, _ = w.Write([]byte(`{"siblings": [{"rfilename": "config.json"}, {"rfilename": "model.safetensors.index.json"},
+				{"rfilename": "model-00001-of-00002.safetensors"}, {"rfilename": "model-00002-of-00002.safetensors"},
+				{"rfilename": "consolidated.safetensors"}]}`))
+		case "/api/models/broken-model":
+			_, _ = w.Write([]byte(`{"siblings": [{"rfilename": "model.safetensors.index.json"}, {"rfilename": "model-00001-of-00002.safetensors"}]}`))
+		case "/big-model/resolve/main/config.json":
+			_, _ = w.Write([]byte(`{"model_type": "llama"}`))
+		case "/big-model/resolve/main/model.safetensors.index.json", "/broken-model/resolve/main/model.safetensors.index.json":
+			_, _ = w.Write([]byte(index))
+		case "/big-model/resolve/main/model-00001-of-00002.safetensors":
+			_, _ = w.Write(shard("a"))
+		case "/big-model/resolve/main/model-00002-of-00002.safetensors":
+			data := shard("b")
+			if truncated.Load() {
+				data = data[:len(data)-2]
+			}
+			_, _ = w.Write(data)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	adapter := NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+	manifest, err := adapter.GetManifest(context.Background(), "hf", "big-model", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	shards := manifest.Spec.Format.Shards
+	if shards == nil || shards.Index != "model.safetensors.index.json" || len(shards.Shards) != 2 || shards.TotalSize != 8 {
+		t.Fatalf("Format.Shards = %+v, want the two shards of the index", shards)
+	}
+
+	destPath := filepath.Join(t.TempDir(), "big-model.axon")
+	if err := adapter.DownloadPackage(context.Background(), manifest, destPath, nil); err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+	names := packageFileNames(t, destPath)
+	for _, want := range []string{"model-00001-of-00002.safetensors", "model-00002-of-00002.safetensors", "model.safetensors.index.json"} {
+		if !containsString(names, want) {
+			t.Errorf("package files = %v, want %s", names, want)
+		}
+	}
+	if containsString(names, "consolidated.safetensors") {
+		t.Errorf("package files = %v, want only the shards the index references", names)
+	}
+
+	// A truncated shard fails the download
+	truncated.Store(true)
+	adapter = NewHuggingFaceAdapter()
+	adapter.baseURL = server.URL
+	err = adapter.DownloadPackage(context.Background(), manifest, destPath, nil)
+	if err == nil || !strings.Contains(err.Error(), "model-00002-of-00002.safetensors") {
+		t.Errorf("DownloadPackage() with a truncated shard: error = %v", err)
+	}
+
+	// An index referencing shards the repository lacks fails the manifest
+	_, err = adapter.GetManifest(context.Background(), "hf", "broken-model", "latest")
+	if err == nil || !strings.Contains(err.Error(), "model-00002-of-00002.safetensors") {
+		t.Errorf("GetManifest() of an incomplete checkpoint: error = %v", err)
+	}
+}
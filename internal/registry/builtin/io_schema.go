@@ -11,7 +11,13 @@ import (
 	"github.com/mlOS-foundation/axon/pkg/types"
 )
 
+// PreprocessorConfigFile is the Hugging Face image processor / feature extractor config,
+// kept next to config.json. It holds the input resolution and normalization of vision
+// models and the sampling rate and feature size of audio models.
+const PreprocessorConfigFile = "preprocessor_config.json"
+
 // ExtractIOSchemaFromConfig extracts I/O schema from Hugging Face model config.json
+// (and the preprocessor_config.json next to it, if any).
 // This is exported so it can be used by adapters and commands
 func ExtractIOSchemaFromConfig(configPath string) ([]types.IOSpec, []types.IOSpec, error) {
 	// Read config.json
@@ -19,19 +25,103 @@ func ExtractIOSchemaFromConfig(configPath string) ([]types.IOSpec, []types.IOSpe
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to read config.json: %w", err)
 	}
+	// The preprocessor config is optional; without it defaults apply
+	preprocessor, _ := os.ReadFile(filepath.Join(filepath.Dir(configPath), PreprocessorConfigFile))
+	return extractIOSchemaFromConfigData(data, preprocessor)
+}
+
+// modelConfig holds the config.json fields that shape a model's inputs and outputs
+type modelConfig struct {
+	ModelType          string            `json:"model_type"`
+	Architectures      []string          `json:"architectures"`
+	ImageSize          json.RawMessage   `json:"image_size"` // An int, or [height, width]
+	NumChannels        int               `json:"num_channels"`
+	ID2Label           map[string]string `json:"id2label"`
+	NumLabels          int               `json:"num_labels"`
+	VocabSize          int               `json:"vocab_size"`
+	HiddenSize         int               `json:"hidden_size"`
+	NumMelBins         int               `json:"num_mel_bins"`
+	MaxSourcePositions int               `json:"max_source_positions"`
+	MaxLength          int               `json:"max_length"`
+	VisionConfig       *modelConfig      `json:"vision_config"`
+}
+
+// preprocessorConfig holds the preprocessor_config.json fields that shape model inputs
+type preprocessorConfig struct {
+	ImageMean    []float64       `json:"image_mean"`
+	ImageStd     []float64       `json:"image_std"`
+	Size         json.RawMessage `json:"size"`      // An int, {"height", "width"} or {"shortest_edge"}
+	CropSize     json.RawMessage `json:"crop_size"` // Same forms as size; the final size when set
+	DoNormalize  *bool           `json:"do_normalize"`
+	FeatureSize  int             `json:"feature_size"`
+	SamplingRate int             `json:"sampling_rate"`
+	NbMaxFrames  int             `json:"nb_max_frames"`
+	MaxLength    int             `json:"max_length"`
+}
+
+// Preprocessing defaults when preprocessor_config.json doesn't say
+var (
+	imageNetMean     = []float64{0.485, 0.456, 0.406}
+	imageNetStd      = []float64{0.229, 0.224, 0.225}
+	clipMean         = []float64{0.48145466, 0.4578275, 0.40821073}
+	clipStd          = []float64{0.26862954, 0.26130258, 0.27577711}
+	defaultImageSize = 224
+	defaultAudioRate = 16000
+)
+
+// visionModelTypes are image models taking pixel_values, named by config.json model_type
+var visionModelTypes = map[string]bool{
+	"vit": true, "deit": true, "swin": true, "swinv2": true, "beit": true, "data2vec-vision": true,
+	"dinov2": true, "convnext": true, "convnextv2": true, "resnet": true, "regnet": true,
+	"efficientnet": true, "mobilenet_v1": true, "mobilenet_v2": true, "mobilevit": true,
+	"mobilevitv2": true, "levit": true, "cvt": true, "bit": true, "focalnet": true,
+	"poolformer": true, "van": true, "segformer": true, "clip_vision_model": true,
+	"siglip_vision_model": true,
+}
+
+// clipModelTypes are contrastive image-text models with both text and image inputs
+var clipModelTypes = map[string]bool{"clip": true, "siglip": true, "chinese_clip": true}
+
+// waveformModelTypes are speech models taking the raw waveform as input_values
+var waveformModelTypes = map[string]bool{
+	"wav2vec2": true, "wav2vec2-conformer": true, "hubert": true, "wavlm": true,
+	"unispeech": true, "unispeech-sat": true, "sew": true, "sew-d": true, "data2vec-audio": true,
+}
 
+// extractIOSchemaFromConfigData extracts the I/O schema from config.json content and,
+// when available, preprocessor_config.json content (nil if there is none)
+func extractIOSchemaFromConfigData(data, preprocessorData []byte) ([]types.IOSpec, []types.IOSpec, error) {
 	// Parse JSON
-	var config map[string]interface{}
+	var config modelConfig
 	if err := json.Unmarshal(data, &config); err != nil {
 		return nil, nil, fmt.Errorf("failed to parse config.json: %w", err)
 	}
+	var preprocessor preprocessorConfig
+	if len(preprocessorData) > 0 {
+		if err := json.Unmarshal(preprocessorData, &preprocessor); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", PreprocessorConfigFile, err)
+		}
+	}
 
 	// Get model type
-	modelType, ok := config["model_type"].(string)
-	if !ok {
+	modelType := strings.ToLower(config.ModelType)
+	if modelType == "" {
 		modelType = "unknown"
 	}
 
+	switch {
+	case clipModelTypes[modelType]:
+		return clipIOSchema(modelType, &config, &preprocessor)
+	case visionModelTypes[modelType] || (modelType == "unknown" && (len(config.ImageSize) > 0 || config.NumChannels > 0)):
+		return visionIOSchema(&config, &preprocessor)
+	case modelType == "whisper":
+		return whisperIOSchema(&config, &preprocessor)
+	case modelType == "audio-spectrogram-transformer":
+		return spectrogramIOSchema(&config, &preprocessor)
+	case waveformModelTypes[modelType]:
+		return waveformIOSchema(&config, &preprocessor)
+	}
+
 	// Extract inputs based on model type
 	inputs := extractInputsForModelType(modelType)
 
@@ -41,6 +131,289 @@ func ExtractIOSchemaFromConfig(configPath string) ([]types.IOSpec, []types.IOSpe
 	return inputs, outputs, nil
 }
 
+// pixelValues is the image input of a vision model: float32 [batch, channels, height, width]
+// normalized with the preprocessor's mean and std
+func pixelValues(config *modelConfig, preprocessor *preprocessorConfig, mean, std []float64) types.IOSpec {
+	channels := config.NumChannels
+	if channels <= 0 {
+		channels = 3
+	}
+	height, width := imageSize(config, preprocessor)
+	if len(preprocessor.ImageMean) > 0 {
+		mean = preprocessor.ImageMean
+	}
+	if len(preprocessor.ImageStd) > 0 {
+		std = preprocessor.ImageStd
+	}
+	var resize interface{} = height
+	if height != width {
+		resize = []int{height, width}
+	}
+	return types.IOSpec{
+		Name:        "pixel_values",
+		DType:       "float32",
+		Shape:       []int{-1, channels, height, width}, // batch, channels, height, width
+		Description: "Preprocessed image pixels",
+		Preprocessing: &types.PreprocessingSpec{
+			Type: "normalization",
+			Config: map[string]interface{}{
+				"mean":   mean,
+				"std":    std,
+				"resize": resize,
+			},
+		},
+	}
+}
+
+// imageSize returns the input resolution of a vision model: the preprocessor's crop size,
+// else its resize target, else the config's image_size, else 224x224
+func imageSize(config *modelConfig, preprocessor *preprocessorConfig) (int, int) {
+	for _, raw := range []json.RawMessage{preprocessor.CropSize, preprocessor.Size, config.ImageSize} {
+		if height, width, ok := parseImageSize(raw); ok {
+			return height, width
+		}
+	}
+	return defaultImageSize, defaultImageSize
+}
+
+// parseImageSize decodes an image size given as an int, [height, width], {"height",
+// "width"} or {"shortest_edge"} (the side a square input is resized to)
+func parseImageSize(raw json.RawMessage) (int, int, bool) {
+	if len(raw) == 0 {
+		return 0, 0, false
+	}
+	var side int
+	if json.Unmarshal(raw, &side) == nil && side > 0 {
+		return side, side, true
+	}
+	var pair []int
+	if json.Unmarshal(raw, &pair) == nil && len(pair) == 2 && pair[0] > 0 && pair[1] > 0 {
+		return pair[0], pair[1], true
+	}
+	var dims struct {
+		Height       int `json:"height"`
+		Width        int `json:"width"`
+		ShortestEdge int `json:"shortest_edge"`
+	}
+	if json.Unmarshal(raw, &dims) == nil {
+		if dims.Height > 0 && dims.Width > 0 {
+			return dims.Height, dims.Width, true
+		}
+		if dims.ShortestEdge > 0 {
+			return dims.ShortestEdge, dims.ShortestEdge, true
+		}
+	}
+	return 0, 0, false
+}
+
+// numLabels returns the number of classes of a classification head (-1 if unknown)
+func numLabels(config *modelConfig) int {
+	if len(config.ID2Label) > 0 {
+		return len(config.ID2Label)
+	}
+	if config.NumLabels > 0 {
+		return config.NumLabels
+	}
+	return -1
+}
+
+// orDynamic returns n, or -1 (a dynamic dimension) if n isn't set
+func orDynamic(n int) int {
+	if n > 0 {
+		return n
+	}
+	return -1
+}
+
+// visionIOSchema is the I/O of image classification models (ViT, ResNet, ConvNeXt, ...)
+func visionIOSchema(config *modelConfig, preprocessor *preprocessorConfig) ([]types.IOSpec, []types.IOSpec, error) {
+	inputs := []types.IOSpec{pixelValues(config, preprocessor, imageNetMean, imageNetStd)}
+	outputs := []types.IOSpec{
+		{
+			Name:        "logits",
+			DType:       "float32",
+			Shape:       []int{-1, numLabels(config)}, // batch, num_classes
+			Description: "Class logits",
+		},
+	}
+	return inputs, outputs, nil
+}
+
+// clipIOSchema is the I/O of contrastive image-text models: tokenized text and an image
+// sized by the vision tower, scored against each other
+func clipIOSchema(modelType string, config *modelConfig, preprocessor *preprocessorConfig) ([]types.IOSpec, []types.IOSpec, error) {
+	vision := config.VisionConfig
+	if vision == nil {
+		vision = &modelConfig{}
+	}
+	tokenization := &types.PreprocessingSpec{
+		Type:          "tokenization",
+		Tokenizer:     "tokenizer.json",
+		TokenizerType: modelType,
+	}
+	inputs := []types.IOSpec{
+		{
+			Name:          "input_ids",
+			DType:         "int64",
+			Shape:         []int{-1, -1}, // batch_size, sequence_length
+			Description:   "Token IDs from tokenizer",
+			Preprocessing: tokenization,
+		},
+		{
+			Name:          "attention_mask",
+			DType:         "int64",
+			Shape:         []int{-1, -1},
+			Description:   "Attention mask",
+			Preprocessing: tokenization,
+		},
+		pixelValues(vision, preprocessor, clipMean, clipStd),
+	}
+	outputs := []types.IOSpec{
+		{
+			Name:        "logits_per_image",
+			DType:       "float32",
+			Shape:       []int{-1, -1}, // image batch, text batch
+			Description: "Image-text similarity scores",
+		},
+		{
+			Name:        "logits_per_text",
+			DType:       "float32",
+			Shape:       []int{-1, -1}, // text batch, image batch
+			Description: "Text-image similarity scores",
+		},
+	}
+	return inputs, outputs, nil
+}
+
+// whisperIOSchema is the I/O of Whisper: log-mel spectrograms of 30s audio windows in,
+// decoder token logits out
+func whisperIOSchema(config *modelConfig, preprocessor *preprocessorConfig) ([]types.IOSpec, []types.IOSpec, error) {
+	melBins := preprocessor.FeatureSize
+	if melBins <= 0 {
+		melBins = config.NumMelBins
+	}
+	if melBins <= 0 {
+		melBins = 80
+	}
+	// The encoder downsamples frames by 2, so its positions cover twice as many frames
+	frames := preprocessor.NbMaxFrames
+	if frames <= 0 && config.MaxSourcePositions > 0 {
+		frames = 2 * config.MaxSourcePositions
+	}
+	if frames <= 0 {
+		frames = 3000
+	}
+	inputs := []types.IOSpec{
+		{
+			Name:        "input_features",
+			DType:       "float32",
+			Shape:       []int{-1, melBins, frames}, // batch, mel bins, frames
+			Description: "Log-mel spectrogram of the audio",
+			Preprocessing: &types.PreprocessingSpec{
+				Type: "log_mel_spectrogram",
+				Config: map[string]interface{}{
+					"sampling_rate": samplingRate(preprocessor),
+					"feature_size":  melBins,
+					"frames":        frames,
+				},
+			},
+		},
+	}
+	outputs := []types.IOSpec{
+		{
+			Name:        "logits",
+			DType:       "float32",
+			Shape:       []int{-1, -1, orDynamic(config.VocabSize)}, // batch, sequence, vocab_size
+			Description: "Token logits",
+		},
+	}
+	return inputs, outputs, nil
+}
+
+// spectrogramIOSchema is the I/O of the Audio Spectrogram Transformer: filterbank
+// features in, class logits out
+func spectrogramIOSchema(config *modelConfig, preprocessor *preprocessorConfig) ([]types.IOSpec, []types.IOSpec, error) {
+	frames := preprocessor.MaxLength
+	if frames <= 0 {
+		frames = config.MaxLength
+	}
+	melBins := preprocessor.FeatureSize
+	if melBins <= 0 {
+		melBins = config.NumMelBins
+	}
+	inputs := []types.IOSpec{
+		{
+			Name:        "input_values",
+			DType:       "float32",
+			Shape:       []int{-1, orDynamic(frames), orDynamic(melBins)}, // batch, frames, mel bins
+			Description: "Log-mel filterbank features of the audio",
+			Preprocessing: &types.PreprocessingSpec{
+				Type: "log_mel_spectrogram",
+				Config: map[string]interface{}{
+					"sampling_rate": samplingRate(preprocessor),
+					"feature_size":  orDynamic(melBins),
+					"frames":        orDynamic(frames),
+				},
+			},
+		},
+	}
+	outputs := []types.IOSpec{
+		{
+			Name:        "logits",
+			DType:       "float32",
+			Shape:       []int{-1, numLabels(config)}, // batch, num_classes
+			Description: "Class logits",
+		},
+	}
+	return inputs, outputs, nil
+}
+
+// waveformIOSchema is the I/O of wav2vec2-style models: the raw waveform in, per-frame
+// CTC logits (speech recognition) or class logits (audio classification) out
+func waveformIOSchema(config *modelConfig, preprocessor *preprocessorConfig) ([]types.IOSpec, []types.IOSpec, error) {
+	normalize := true
+	if preprocessor.DoNormalize != nil {
+		normalize = *preprocessor.DoNormalize
+	}
+	inputs := []types.IOSpec{
+		{
+			Name:        "input_values",
+			DType:       "float32",
+			Shape:       []int{-1, -1}, // batch, samples
+			Description: "Raw audio waveform",
+			Preprocessing: &types.PreprocessingSpec{
+				Type: "waveform",
+				Config: map[string]interface{}{
+					"sampling_rate": samplingRate(preprocessor),
+					"normalize":     normalize,
+				},
+			},
+		},
+	}
+
+	output := types.IOSpec{
+		Name:        "logits",
+		DType:       "float32",
+		Shape:       []int{-1, -1, orDynamic(config.VocabSize)}, // batch, frames, vocab_size
+		Description: "CTC token logits",
+	}
+	for _, architecture := range config.Architectures {
+		if strings.HasSuffix(architecture, "ForSequenceClassification") || strings.HasSuffix(architecture, "ForAudioClassification") {
+			output.Shape = []int{-1, numLabels(config)} // batch, num_classes
+			output.Description = "Class logits"
+		}
+	}
+	return inputs, []types.IOSpec{output}, nil
+}
+
+// samplingRate returns the audio sampling rate a model expects
+func samplingRate(preprocessor *preprocessorConfig) int {
+	if preprocessor.SamplingRate > 0 {
+		return preprocessor.SamplingRate
+	}
+	return defaultAudioRate
+}
+
 // extractInputsForModelType returns input specs based on model architecture
 func extractInputsForModelType(modelType string) []types.IOSpec {
 	modelType = strings.ToLower(modelType)
@@ -135,24 +508,6 @@ func extractInputsForModelType(modelType string) []types.IOSpec {
 				},
 			},
 		}
-	case "vit", "deit", "swin":
-		// Vision Transformer models
-		return []types.IOSpec{
-			{
-				Name:        "pixel_values",
-				DType:       "float32",
-				Shape:       []int{-1, 3, 224, 224}, // batch, channels, height, width
-				Description: "Preprocessed image pixels",
-				Preprocessing: &types.PreprocessingSpec{
-					Type: "normalization",
-					Config: map[string]interface{}{
-						"mean":   []float64{0.485, 0.456, 0.406},
-						"std":    []float64{0.229, 0.224, 0.225},
-						"resize": 224,
-					},
-				},
-			},
-		}
 	default:
 		// Generic fallback
 		return []types.IOSpec{
@@ -181,16 +536,6 @@ func extractOutputsForModelType(modelType string) []types.IOSpec {
 				Description: "Model logits",
 			},
 		}
-	case "vit", "deit", "swin":
-		// Vision models output class logits
-		return []types.IOSpec{
-			{
-				Name:        "logits",
-				DType:       "float32",
-				Shape:       []int{-1, -1}, // batch, num_classes
-				Description: "Class logits",
-			},
-		}
 	default:
 		// Generic fallback
 		return []types.IOSpec{
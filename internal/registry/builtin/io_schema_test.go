@@ -0,0 +1,150 @@
+package builtin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestExtractIOSchemaFromConfigData(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       string
+		preprocessor string
+		wantInputs   map[string][]int
+		wantOutputs  map[string][]int
+		wantPrep     map[string]interface{} // Preprocessing config of the last input
+	}{
+		{
+			name:        "bert",
+			config:      `{"model_type": "bert"}`,
+			wantInputs:  map[string][]int{"input_ids": {-1, -1}, "attention_mask": {-1, -1}, "token_type_ids": {-1, -1}},
+			wantOutputs: map[string][]int{"logits": {-1, -1, -1}},
+		},
+		{
+			name:        "vit from config",
+			config:      `{"model_type": "vit", "image_size": 384, "num_channels": 3, "id2label": {"0": "cat", "1": "dog", "2": "bird"}}`,
+			wantInputs:  map[string][]int{"pixel_values": {-1, 3, 384, 384}},
+			wantOutputs: map[string][]int{"logits": {-1, 3}},
+			wantPrep:    map[string]interface{}{"mean": imageNetMean, "std": imageNetStd, "resize": 384},
+		},
+		{
+			name:         "resnet with preprocessor",
+			config:       `{"model_type": "resnet", "num_labels": 1000}`,
+			preprocessor: `{"size": {"shortest_edge": 256}, "crop_size": {"height": 224, "width": 224}, "image_mean": [0.5, 0.5, 0.5], "image_std": [0.5, 0.5, 0.5]}`,
+			wantInputs:   map[string][]int{"pixel_values": {-1, 3, 224, 224}},
+			wantOutputs:  map[string][]int{"logits": {-1, 1000}},
+			wantPrep:     map[string]interface{}{"mean": []float64{0.5, 0.5, 0.5}, "std": []float64{0.5, 0.5, 0.5}, "resize": 224},
+		},
+		{
+			name:        "grayscale with rectangular image size",
+			config:      `{"image_size": [480, 640], "num_channels": 1}`,
+			wantInputs:  map[string][]int{"pixel_values": {-1, 1, 480, 640}},
+			wantOutputs: map[string][]int{"logits": {-1, -1}},
+			wantPrep:    map[string]interface{}{"mean": imageNetMean, "std": imageNetStd, "resize": []int{480, 640}},
+		},
+		{
+			name:        "clip",
+			config:      `{"model_type": "clip", "vision_config": {"image_size": 336}}`,
+			wantInputs:  map[string][]int{"input_ids": {-1, -1}, "attention_mask": {-1, -1}, "pixel_values": {-1, 3, 336, 336}},
+			wantOutputs: map[string][]int{"logits_per_image": {-1, -1}, "logits_per_text": {-1, -1}},
+			wantPrep:    map[string]interface{}{"mean": clipMean, "std": clipStd, "resize": 336},
+		},
+		{
+			name:        "whisper from config",
+			config:      `{"model_type": "whisper", "num_mel_bins": 128, "max_source_positions": 1500, "vocab_size": 51866}`,
+			wantInputs:  map[string][]int{"input_features": {-1, 128, 3000}},
+			wantOutputs: map[string][]int{"logits": {-1, -1, 51866}},
+			wantPrep:    map[string]interface{}{"sampling_rate": 16000, "feature_size": 128, "frames": 3000},
+		},
+		{
+			name:         "wav2vec2 ctc",
+			config:       `{"model_type": "wav2vec2", "architectures": ["Wav2Vec2ForCTC"], "vocab_size": 32}`,
+			preprocessor: `{"feature_size": 1, "sampling_rate": 16000, "do_normalize": false}`,
+			wantInputs:   map[string][]int{"input_values": {-1, -1}},
+			wantOutputs:  map[string][]int{"logits": {-1, -1, 32}},
+			wantPrep:     map[string]interface{}{"sampling_rate": 16000, "normalize": false},
+		},
+		{
+			name:        "hubert audio classification",
+			config:      `{"model_type": "hubert", "architectures": ["HubertForSequenceClassification"], "id2label": {"0": "yes", "1": "no"}}`,
+			wantInputs:  map[string][]int{"input_values": {-1, -1}},
+			wantOutputs: map[string][]int{"logits": {-1, 2}},
+			wantPrep:    map[string]interface{}{"sampling_rate": 16000, "normalize": true},
+		},
+		{
+			name:         "audio spectrogram transformer",
+			config:       `{"model_type": "audio-spectrogram-transformer", "max_length": 1024, "num_mel_bins": 128, "num_labels": 527}`,
+			preprocessor: `{"feature_size": 128, "max_length": 1024, "sampling_rate": 16000}`,
+			wantInputs:   map[string][]int{"input_values": {-1, 1024, 128}},
+			wantOutputs:  map[string][]int{"logits": {-1, 527}},
+			wantPrep:     map[string]interface{}{"sampling_rate": 16000, "feature_size": 128, "frames": 1024},
+		},
+		{
+			name:        "unknown",
+			config:      `{"architectures": ["MyModel"]}`,
+			wantInputs:  map[string][]int{"input": {-1, -1}},
+			wantOutputs: map[string][]int{"output": {-1, -1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var preprocessor []byte
+			if tt.preprocessor != "" {
+				preprocessor = []byte(tt.preprocessor)
+			}
+			inputs, outputs, err := extractIOSchemaFromConfigData([]byte(tt.config), preprocessor)
+			if err != nil {
+				t.Fatalf("extractIOSchemaFromConfigData() error = %v", err)
+			}
+
+			gotInputs := make(map[string][]int)
+			for _, input := range inputs {
+				gotInputs[input.Name] = input.Shape
+				if input.DType == "" {
+					t.Errorf("input %s has no dtype", input.Name)
+				}
+			}
+			if !reflect.DeepEqual(gotInputs, tt.wantInputs) {
+				t.Errorf("inputs = %v, want %v", gotInputs, tt.wantInputs)
+			}
+			gotOutputs := make(map[string][]int)
+			for _, output := range outputs {
+				gotOutputs[output.Name] = output.Shape
+			}
+			if !reflect.DeepEqual(gotOutputs, tt.wantOutputs) {
+				t.Errorf("outputs = %v, want %v", gotOutputs, tt.wantOutputs)
+			}
+
+			if tt.wantPrep != nil {
+				last := inputs[len(inputs)-1]
+				if last.DType != "float32" || last.Preprocessing == nil || !reflect.DeepEqual(last.Preprocessing.Config, tt.wantPrep) {
+					t.Errorf("%s = %s %+v, want float32 with preprocessing %v", last.Name, last.DType, last.Preprocessing, tt.wantPrep)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractIOSchemaFromConfig_PreprocessorConfig(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.json":          `{"model_type": "convnext"}`,
+		PreprocessorConfigFile: `{"size": {"height": 288, "width": 288}}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inputs, _, err := ExtractIOSchemaFromConfig(filepath.Join(dir, "config.json"))
+	if err != nil {
+		t.Fatalf("ExtractIOSchemaFromConfig() error = %v", err)
+	}
+	if len(inputs) != 1 || !reflect.DeepEqual(inputs[0].Shape, []int{-1, 3, 288, 288}) {
+		t.Errorf("inputs = %+v, want pixel_values [-1 3 288 288]", inputs)
+	}
+}
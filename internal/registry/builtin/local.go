@@ -3,15 +3,20 @@ package builtin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
 
 	"github.com/mlOS-foundation/axon/internal/registry"
 	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
 	"github.com/mlOS-foundation/axon/pkg/types"
 )
 
 // LocalRegistryAdapter implements RepositoryAdapter for local Axon registry.
 type LocalRegistryAdapter struct {
 	client *registry.Client
+	proxy  bool // The registry proxies upstream repositories
 }
 
 // NewLocalRegistryAdapter creates a new local registry adapter.
@@ -21,17 +26,62 @@ func NewLocalRegistryAdapter(baseURL string, mirrors []string) *LocalRegistryAda
 	}
 }
 
+// LocalRegistryFactory implements AdapterFactory for creating local registry adapters.
+// Mirrors are passed as the "mirrors" option ([]string).
+type LocalRegistryFactory struct{}
+
+// NewLocalRegistryFactory creates a new local registry factory.
+func NewLocalRegistryFactory() *LocalRegistryFactory {
+	return &LocalRegistryFactory{}
+}
+
+// Name returns the factory name.
+func (f *LocalRegistryFactory) Name() string {
+	return "local"
+}
+
+// Create creates a new local registry adapter with the given configuration.
+func (f *LocalRegistryFactory) Create(config core.AdapterConfig) (core.RepositoryAdapter, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("local registry adapter requires a base URL")
+	}
+	mirrors, _ := config.Options["mirrors"].([]string)
+	adapter := NewLocalRegistryAdapter(config.BaseURL, mirrors)
+	if config.Timeout > 0 {
+		adapter.client.SetTimeout(factoryTimeout(config))
+	}
+	if config.Token != "" {
+		adapter.SetToken(config.Token)
+	}
+	return adapter, nil
+}
+
+// SetToken sets the bearer token for a registry that requires authentication.
+func (l *LocalRegistryAdapter) SetToken(token string) {
+	l.client.SetToken(token)
+}
+
+// SetProxy makes the adapter handle the namespaces of the other adapters too, for a
+// registry that fetches and caches models from upstream repositories.
+func (l *LocalRegistryAdapter) SetProxy(proxy bool) {
+	l.proxy = proxy
+}
+
 // Name returns the adapter name.
 func (l *LocalRegistryAdapter) Name() string {
 	return "local"
 }
 
 // CanHandle returns true if this adapter can handle the given namespace and name.
-// Local registry can only handle models that are NOT from known adapters.
+// Local registry can only handle models that are NOT from known adapters, unless it is
+// a proxy for them.
 func (l *LocalRegistryAdapter) CanHandle(namespace, name string) bool {
-	// Known adapter namespaces: hf, pytorch, torch, modelscope, tfhub, tf
+	if l.proxy {
+		return l.client.BaseURL() != ""
+	}
+	// Known adapter namespaces: hf, pytorch, torch, modelscope, tfhub, tf, url
 	if namespace == "hf" || namespace == "pytorch" || namespace == "torch" ||
-		namespace == "modelscope" || namespace == "tfhub" || namespace == "tf" {
+		namespace == "modelscope" || namespace == "tfhub" || namespace == "tf" || namespace == "url" {
 		return false
 	}
 	// Local registry can handle models if it's configured and model is not from a known adapter
@@ -43,11 +93,27 @@ func (l *LocalRegistryAdapter) Search(ctx context.Context, query string) ([]type
 	return l.client.Search(ctx, query)
 }
 
-// GetManifest retrieves the manifest for the specified model.
+// GetManifest retrieves the manifest for the specified model. Latest is resolved through
+// the registry's version listing; registries without one are asked for a version named
+// latest.
 func (l *LocalRegistryAdapter) GetManifest(ctx context.Context, namespace, name, version string) (*types.Manifest, error) {
+	if version == spec.Latest {
+		list, err := l.client.GetVersions(ctx, namespace, name)
+		switch {
+		case err == nil && list.Latest != "":
+			version = list.Latest
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return nil, fmt.Errorf("failed to resolve latest version of %s/%s: %w", namespace, name, err)
+		}
+	}
 	return l.client.GetManifest(ctx, namespace, name, version)
 }
 
+// ListVersions lists the versions the registry has of the model.
+func (l *LocalRegistryAdapter) ListVersions(ctx context.Context, namespace, name string) ([]string, error) {
+	return l.client.ListVersions(ctx, namespace, name)
+}
+
 // DownloadPackage downloads the model package to the specified destination path.
 func (l *LocalRegistryAdapter) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress core.ProgressCallback) error {
 	// Convert core.ProgressCallback to registry.ProgressCallback
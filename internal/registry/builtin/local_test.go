@@ -0,0 +1,76 @@
+package builtin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLocalRegistryAdapter_GetManifestLatest(t *testing.T) {
+	manifest := func(version string) string {
+		return "apiVersion: v1\nkind: Model\nmetadata:\n  namespace: nlp\n  name: bert\n  version: " + version + "\ndistribution:\n  package:\n    url: /packages/p.axon\n"
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/models/nlp/bert/versions":
+			_, _ = w.Write([]byte(`{"namespace": "nlp", "name": "bert", "versions": ["1.0.0", "1.10.0"], "latest": "1.10.0"}`))
+		case "/api/v1/models/vision/resnet/versions.json":
+			// A static registry whose listing has no latest pointer
+			_, _ = w.Write([]byte(`{"versions": ["2.0.0", "2.0.0-rc.1"]}`))
+		case "/api/v1/models/nlp/bert/1.10.0/manifest.yaml", "/api/v1/models/vision/resnet/2.0.0/manifest.yaml", "/api/v1/models/nlp/gpt2/latest/manifest.yaml":
+			version := strings.Split(r.URL.Path, "/")[6]
+			_, _ = w.Write([]byte(manifest(version)))
+		case "/api/v1/models/nlp/broken/versions":
+			http.Error(w, "boom", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	adapter := NewLocalRegistryAdapter(server.URL, nil)
+
+	tests := []struct {
+		namespace, name string
+		want            string
+	}{
+		{"nlp", "bert", "1.10.0"},
+		{"vision", "resnet", "2.0.0"},
+		{"nlp", "gpt2", "latest"}, // No listing: a version named latest
+	}
+	for _, tt := range tests {
+		m, err := adapter.GetManifest(context.Background(), tt.namespace, tt.name, "latest")
+		if err != nil {
+			t.Errorf("GetManifest(%s/%s@latest) error = %v", tt.namespace, tt.name, err)
+			continue
+		}
+		if m.Metadata.Version != tt.want {
+			t.Errorf("GetManifest(%s/%s@latest) version = %s, want %s", tt.namespace, tt.name, m.Metadata.Version, tt.want)
+		}
+	}
+
+	if _, err := adapter.GetManifest(context.Background(), "nlp", "broken", "latest"); err == nil || !strings.Contains(err.Error(), "failed to resolve latest") {
+		t.Errorf("GetManifest() with a failing listing error = %v", err)
+	}
+}
+
+func TestLocalRegistryAdapter_CanHandleProxy(t *testing.T) {
+	adapter := NewLocalRegistryAdapter("http://localhost:8080", nil)
+	if adapter.CanHandle("hf", "bert-base-uncased") {
+		t.Error("CanHandle(hf) = true without proxy, want false")
+	}
+	if !adapter.CanHandle("nlp", "bert") {
+		t.Error("CanHandle(nlp) = false, want true")
+	}
+
+	adapter.SetProxy(true)
+	for _, namespace := range []string{"hf", "pytorch", "nlp"} {
+		if !adapter.CanHandle(namespace, "model") {
+			t.Errorf("CanHandle(%s) = false with proxy, want true", namespace)
+		}
+	}
+	if NewLocalRegistryAdapter("", nil).CanHandle("hf", "bert") {
+		t.Error("CanHandle() = true without a registry URL")
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/mlOS-foundation/axon/internal/registry/core"
 	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 )
 
 // ModelScopeAdapter implements RepositoryAdapter for ModelScope.
@@ -49,6 +50,12 @@ func NewModelScopeAdapterWithToken(token string) *ModelScopeAdapter {
 	return adapter
 }
 
+// SetToken sets the ModelScope token (for private models).
+func (m *ModelScopeAdapter) SetToken(token string) {
+	m.token = token
+	m.httpClient.SetToken(token)
+}
+
 // Name returns the adapter name.
 func (m *ModelScopeAdapter) Name() string {
 	return "modelscope"
@@ -76,9 +83,14 @@ func (m *ModelScopeAdapter) GetManifest(ctx context.Context, namespace, name, ve
 
 	// Construct model URL
 	modelURL := fmt.Sprintf("%s/models/%s/%s", m.baseURL, owner, modelName)
+	apiURL := fmt.Sprintf("%s/api/v1/models/%s/%s", m.baseURL, owner, modelName)
 
-	// Validate model exists
-	valid, err := m.validator.ValidateModelExists(ctx, modelURL)
+	// Validate model exists with the API, and the model page if the API can't tell
+	var headers map[string]string
+	if m.token != "" {
+		headers = map[string]string{"Authorization": "Bearer " + m.token}
+	}
+	valid, err := m.validator.Validate(ctx, m.validator.API(apiURL, headers), m.validator.Page(modelURL))
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate model existence: %w", err)
 	}
@@ -87,7 +99,6 @@ func (m *ModelScopeAdapter) GetManifest(ctx context.Context, namespace, name, ve
 	}
 
 	// Try to fetch model metadata from API
-	apiURL := fmt.Sprintf("%s/api/v1/models/%s/%s", m.baseURL, owner, modelName)
 	resp, err := m.httpClient.Get(ctx, apiURL)
 	if err != nil {
 		// If API fails, create basic manifest
@@ -192,8 +203,9 @@ func (m *ModelScopeAdapter) DownloadPackage(ctx context.Context, manifest *types
 	mainFileURL := downloadURL + "model.tar.gz"
 
 	// Create temp file for download
-	tempFile := "/tmp/modelscope-download.tar.gz"
-	httpClient := &http.Client{Timeout: 10 * time.Minute}
+	tempFile := filepath.Join(utils.TempDir(), fmt.Sprintf("modelscope-download-%d.tar.gz", time.Now().UnixNano()))
+	defer func() { _ = os.Remove(tempFile) }()
+	httpClient := &http.Client{Timeout: 10 * time.Minute, Transport: core.Transport}
 
 	if err := core.DownloadFile(ctx, httpClient, mainFileURL, tempFile, progress); err != nil {
 		// If direct download fails, try alternative approach
@@ -205,6 +217,13 @@ func (m *ModelScopeAdapter) DownloadPackage(ctx context.Context, manifest *types
 	if err := builder.AddFile(tempFile, "model.tar.gz"); err != nil {
 		return fmt.Errorf("failed to add file to package: %w", err)
 	}
+	builder.SetSource(types.ProvenanceSource{
+		Adapter:    m.Name(),
+		Model:      strings.TrimPrefix(modelURL, m.baseURL+"/models/"),
+		Repository: modelURL,
+		Revision:   "master",
+	})
+	builder.SetFileURL("model.tar.gz", mainFileURL)
 
 	// Ensure destination directory exists
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
@@ -217,7 +236,7 @@ func (m *ModelScopeAdapter) DownloadPackage(ctx context.Context, manifest *types
 	}
 
 	// Update manifest with checksum
-	if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
 		return fmt.Errorf("failed to update manifest checksum: %w", err)
 	}
 
@@ -330,7 +349,9 @@ func (f *ModelScopeFactory) Create(config core.AdapterConfig) (core.RepositoryAd
 
 	if config.BaseURL != "" {
 		adapter.baseURL = config.BaseURL
-		adapter.httpClient = core.NewHTTPClient(config.BaseURL, time.Duration(config.Timeout)*time.Second)
+	}
+	if config.BaseURL != "" || config.Timeout > 0 {
+		adapter.httpClient = core.NewHTTPClient(adapter.baseURL, factoryTimeout(config))
 	}
 
 	if config.Token != "" {
@@ -3,6 +3,9 @@ package builtin
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/mlOS-foundation/axon/internal/registry/core"
@@ -89,6 +92,25 @@ func TestModelScopeAdapter_GetManifest(t *testing.T) {
 	}
 }
 
+func TestModelScopeAdapter_GetManifest_NotFound(t *testing.T) {
+	// The ModelScope API answers 404 for missing models
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	adapter := NewModelScopeAdapter()
+	adapter.baseURL = server.URL
+
+	_, err := adapter.GetManifest(context.Background(), "modelscope", "damo/does-not-exist", "latest")
+	if err == nil {
+		t.Fatal("GetManifest() should fail for missing model")
+	}
+	if !strings.Contains(err.Error(), "model not found") {
+		t.Errorf("GetManifest() error = %v, want 'model not found'", err)
+	}
+}
+
 func TestModelScopeFactory_Name(t *testing.T) {
 	factory := NewModelScopeFactory()
 	if factory.Name() != "modelscope" {
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mlOS-foundation/axon/internal/model"
 	"github.com/mlOS-foundation/axon/internal/registry/core"
 	"github.com/mlOS-foundation/axon/pkg/types"
 )
@@ -28,7 +29,8 @@ type PyTorchHubAdapter struct {
 func NewPyTorchHubAdapter() *PyTorchHubAdapter {
 	return &PyTorchHubAdapter{
 		httpClient: &http.Client{
-			Timeout: 5 * time.Minute,
+			Timeout:   5 * time.Minute,
+			Transport: core.Transport,
 		},
 		baseURL:        "https://api.github.com",
 		githubToken:    "", // No token by default
@@ -40,7 +42,8 @@ func NewPyTorchHubAdapter() *PyTorchHubAdapter {
 func NewPyTorchHubAdapterWithToken(token string) *PyTorchHubAdapter {
 	return &PyTorchHubAdapter{
 		httpClient: &http.Client{
-			Timeout: 5 * time.Minute,
+			Timeout:   5 * time.Minute,
+			Transport: core.Transport,
 		},
 		baseURL:        "https://api.github.com",
 		githubToken:    token,
@@ -48,6 +51,30 @@ func NewPyTorchHubAdapterWithToken(token string) *PyTorchHubAdapter {
 	}
 }
 
+// PyTorchHubFactory implements AdapterFactory for creating PyTorch Hub adapters.
+// The base URL is that of the GitHub API the hub repositories are read from.
+type PyTorchHubFactory struct{}
+
+// NewPyTorchHubFactory creates a new PyTorch Hub factory.
+func NewPyTorchHubFactory() *PyTorchHubFactory {
+	return &PyTorchHubFactory{}
+}
+
+// Name returns the factory name.
+func (f *PyTorchHubFactory) Name() string {
+	return "pytorch"
+}
+
+// Create creates a new PyTorch Hub adapter with the given configuration.
+func (f *PyTorchHubFactory) Create(config core.AdapterConfig) (core.RepositoryAdapter, error) {
+	adapter := NewPyTorchHubAdapterWithToken(config.Token)
+	if config.BaseURL != "" {
+		adapter.baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	}
+	adapter.httpClient.Timeout = factoryTimeout(config)
+	return adapter, nil
+}
+
 // SetToken sets the GitHub token (for rate limit increases)
 func (p *PyTorchHubAdapter) SetToken(token string) {
 	p.githubToken = token
@@ -104,9 +131,13 @@ func (p *PyTorchHubAdapter) GetManifest(ctx context.Context, namespace, name, ve
 	// Construct GitHub repo path (PyTorch Hub repos are under pytorch/ organization)
 	githubRepo := fmt.Sprintf("pytorch/%s", repo)
 
-	// Validate GitHub repository exists
-	repoURL := fmt.Sprintf("https://github.com/%s", githubRepo)
-	valid, err := p.modelValidator.ValidateModelExists(ctx, repoURL)
+	// Validate GitHub repository exists via the GitHub API (404 means not found)
+	repoURL := fmt.Sprintf("%s/repos/%s", p.baseURL, githubRepo)
+	var headers map[string]string
+	if p.githubToken != "" {
+		headers = map[string]string{"Authorization": fmt.Sprintf("token %s", p.githubToken)}
+	}
+	valid, err := p.modelValidator.Validate(ctx, p.modelValidator.API(repoURL, headers))
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate repository existence: %w", err)
 	}
@@ -189,18 +220,7 @@ func (p *PyTorchHubAdapter) GetManifest(ctx context.Context, namespace, name, ve
 					},
 				},
 			},
-			Requirements: types.Requirements{
-				Compute: types.Compute{
-					CPU: types.CPURequirement{
-						MinCores:         2,
-						RecommendedCores: 4,
-					},
-					Memory: types.MemoryRequirement{
-						MinGB:         2.0,
-						RecommendedGB: 4.0,
-					},
-				},
-			},
+			Requirements: model.DefaultRequirements(),
 		},
 		Distribution: types.Distribution{
 			Package: types.PackageInfo{
@@ -250,6 +270,13 @@ func (p *PyTorchHubAdapter) DownloadPackage(ctx context.Context, manifest *types
 	// 2. Download weights from GitHub releases
 	// 3. Download model architecture files if needed
 
+	source := types.ProvenanceSource{
+		Adapter:    p.Name(),
+		Model:      manifest.Metadata.Name,
+		Repository: fmt.Sprintf("https://github.com/%s", githubRepo),
+	}
+	urls := make(map[string]string)
+
 	// Try to get latest release from GitHub
 	releaseURL := fmt.Sprintf("%s/repos/%s/releases/latest", p.baseURL, githubRepo)
 	req, err := http.NewRequestWithContext(ctx, "GET", releaseURL, nil)
@@ -274,34 +301,11 @@ func (p *PyTorchHubAdapter) DownloadPackage(ctx context.Context, manifest *types
 	if resp.StatusCode != http.StatusOK {
 		// If no release found, try to download from main branch
 		// PyTorch Hub models might not have releases
-		if err := p.downloadFromBranch(ctx, githubRepo, modelName, tempDir, progress); err != nil {
+		if err := p.downloadFromBranch(ctx, githubRepo, modelName, tempDir, urls, progress); err != nil {
 			return err
 		}
 		// Create .axon package after downloading from branch
-		builder, err := core.NewPackageBuilder()
-		if err != nil {
-			return fmt.Errorf("failed to create package builder: %w", err)
-		}
-		defer builder.Cleanup()
-
-		if err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil || info.IsDir() {
-				return err
-			}
-			relPath, _ := filepath.Rel(tempDir, path)
-			return builder.AddFile(path, relPath)
-		}); err != nil {
-			return fmt.Errorf("failed to add files to package: %w", err)
-		}
-
-		if err := builder.Build(destPath); err != nil {
-			return fmt.Errorf("failed to build package: %w", err)
-		}
-
-		if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
-			fmt.Printf("Warning: failed to update manifest checksum: %v\n", err)
-		}
-		return nil
+		return p.buildPackage(manifest, source, urls, tempDir, destPath)
 	}
 
 	// Parse release response
@@ -328,54 +332,41 @@ func (p *PyTorchHubAdapter) DownloadPackage(ctx context.Context, manifest *types
 			if err := p.downloadFile(ctx, asset.BrowserDownloadURL, filepath.Join(tempDir, asset.Name), asset.Size, progress); err != nil {
 				continue // Skip failed downloads
 			}
+			urls[asset.Name] = asset.BrowserDownloadURL
 			downloadedFiles = append(downloadedFiles, asset.Name)
 		}
 	}
 
 	if len(downloadedFiles) == 0 {
 		// Fallback: try downloading from branch
-		if err := p.downloadFromBranch(ctx, githubRepo, modelName, tempDir, progress); err != nil {
+		if err := p.downloadFromBranch(ctx, githubRepo, modelName, tempDir, urls, progress); err != nil {
 			return err
 		}
 		// Create .axon package after downloading from branch
-		builder, err := core.NewPackageBuilder()
-		if err != nil {
-			return fmt.Errorf("failed to create package builder: %w", err)
-		}
-		defer builder.Cleanup()
-
-		if err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil || info.IsDir() {
-				return err
-			}
-			relPath, _ := filepath.Rel(tempDir, path)
-			return builder.AddFile(path, relPath)
-		}); err != nil {
-			return fmt.Errorf("failed to add files to package: %w", err)
-		}
-
-		if err := builder.Build(destPath); err != nil {
-			return fmt.Errorf("failed to build package: %w", err)
-		}
-
-		if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
-			fmt.Printf("Warning: failed to update manifest checksum: %v\n", err)
-		}
-		return nil
+		return p.buildPackage(manifest, source, urls, tempDir, destPath)
 	}
 
 	// Create .axon package
+	return p.buildPackage(manifest, source, urls, tempDir, destPath)
+}
+
+// buildPackage packages the files downloaded to tempDir, recording where they came from
+func (p *PyTorchHubAdapter) buildPackage(manifest *types.Manifest, source types.ProvenanceSource, urls map[string]string, tempDir, destPath string) error {
 	builder, err := core.NewPackageBuilder()
 	if err != nil {
 		return fmt.Errorf("failed to create package builder: %w", err)
 	}
 	defer builder.Cleanup()
 
+	builder.SetSource(source)
 	if err := filepath.Walk(tempDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return err
 		}
 		relPath, _ := filepath.Rel(tempDir, path)
+		if url, ok := urls[relPath]; ok {
+			builder.SetFileURL(relPath, url)
+		}
 		return builder.AddFile(path, relPath)
 	}); err != nil {
 		return fmt.Errorf("failed to add files to package: %w", err)
@@ -386,7 +377,7 @@ func (p *PyTorchHubAdapter) DownloadPackage(ctx context.Context, manifest *types
 	}
 
 	// Update manifest with checksum
-	if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
 		fmt.Printf("Warning: failed to update manifest checksum: %v\n", err)
 	}
 
@@ -397,8 +388,8 @@ func (p *PyTorchHubAdapter) DownloadPackage(ctx context.Context, manifest *types
 // This is a pure Go implementation that:
 // 1. Fetches hubconf.py from GitHub
 // 2. Parses it to extract model weight URLs
-// 3. Downloads weights directly from those URLs
-func (p *PyTorchHubAdapter) downloadFromBranch(ctx context.Context, githubRepo, modelName, destDir string, progress core.ProgressCallback) error {
+// 3. Downloads weights directly from those URLs, recording each file's URL in urls
+func (p *PyTorchHubAdapter) downloadFromBranch(ctx context.Context, githubRepo, modelName, destDir string, urls map[string]string, progress core.ProgressCallback) error {
 	// Fetch hubconf.py from GitHub
 	hubconfURL := fmt.Sprintf("https://raw.githubusercontent.com/%s/main/hubconf.py", githubRepo)
 
@@ -478,6 +469,7 @@ func (p *PyTorchHubAdapter) downloadFromBranch(ctx context.Context, githubRepo,
 			fmt.Printf("Warning: failed to download %s: %v\n", url, err)
 			continue
 		}
+		urls[filename] = url
 		downloadedFiles = append(downloadedFiles, filename)
 	}
 
@@ -641,17 +633,19 @@ func (p *PyTorchHubAdapter) downloadFile(ctx context.Context, url, destPath stri
 		_ = outFile.Close()
 	}()
 
+	body := core.LimitDownload(ctx, resp.Body)
+
 	// Copy with progress tracking
 	if progress != nil {
 		// Use TeeReader to track progress
-		reader := io.TeeReader(resp.Body, &progressWriter{
+		reader := io.TeeReader(body, &progressWriter{
 			writer:   outFile,
 			progress: progress,
 			total:    resp.ContentLength,
 		})
 		_, err = io.Copy(io.Discard, reader)
 	} else {
-		_, err = io.Copy(outFile, resp.Body)
+		_, err = io.Copy(outFile, body)
 	}
 	return err
 }
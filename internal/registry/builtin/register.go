@@ -2,9 +2,37 @@
 package builtin
 
 import (
+	"time"
+
 	"github.com/mlOS-foundation/axon/internal/registry/core"
 )
 
+// DefaultAdapterOrder lists the builtin adapters in the order RegisterDefaultAdapters
+// consults them
+var DefaultAdapterOrder = []string{"local", "url", "pytorch", "tensorflow-hub", "modelscope", "huggingface"}
+
+// defaultTimeout is the HTTP timeout of builtin adapters
+const defaultTimeout = 5 * time.Minute
+
+// factoryTimeout returns the configured timeout, or the default if none is set
+func factoryTimeout(config core.AdapterConfig) time.Duration {
+	if config.Timeout <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(config.Timeout) * time.Second
+}
+
+// RegisterDefaultFactories registers a factory for every builtin adapter, so adapters can
+// be created by name from configuration. Factory names match the adapter names.
+func RegisterDefaultFactories(registry *core.AdapterRegistry) {
+	registry.RegisterFactory(NewLocalRegistryFactory())
+	registry.RegisterFactory(NewURLFactory())
+	registry.RegisterFactory(NewPyTorchHubFactory())
+	registry.RegisterFactory(NewTensorFlowHubFactory())
+	registry.RegisterFactory(NewModelScopeFactory())
+	registry.RegisterFactory(NewHuggingFaceFactory())
+}
+
 // RegisterDefaultAdapters registers all builtin adapters with the registry.
 // This is called automatically when the CLI initializes.
 func RegisterDefaultAdapters(registry *core.AdapterRegistry, localRegistryURL string, mirrors []string, hfToken string, enableHF bool) {
@@ -14,19 +42,22 @@ func RegisterDefaultAdapters(registry *core.AdapterRegistry, localRegistryURL st
 		registry.Register(localAdapter)
 	}
 
-	// 2. PyTorch Hub - handles pytorch/ and torch/ namespaces
+	// 2. Direct URLs - handles the url/ namespace
+	registry.Register(NewURLAdapter())
+
+	// 3. PyTorch Hub - handles pytorch/ and torch/ namespaces
 	pytorchAdapter := NewPyTorchHubAdapter()
 	registry.Register(pytorchAdapter)
 
-	// 3. TensorFlow Hub - handles tfhub/ and tf/ namespaces
+	// 4. TensorFlow Hub - handles tfhub/ and tf/ namespaces
 	tfhubAdapter := NewTensorFlowHubAdapter()
 	registry.Register(tfhubAdapter)
 
-	// 4. ModelScope - handles modelscope/ and ms/ namespaces
+	// 5. ModelScope - handles modelscope/ and ms/ namespaces
 	modelscopeAdapter := NewModelScopeAdapter()
 	registry.Register(modelscopeAdapter)
 
-	// 5. Hugging Face (fallback - can handle any model)
+	// 6. Hugging Face (fallback - can handle any model)
 	if enableHF {
 		if hfToken != "" {
 			hfAdapter := NewHuggingFaceAdapterWithToken(hfToken)
@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mlOS-foundation/axon/internal/model"
 	"github.com/mlOS-foundation/axon/internal/registry/core"
 	"github.com/mlOS-foundation/axon/pkg/types"
 )
@@ -18,6 +19,7 @@ import (
 type TensorFlowHubAdapter struct {
 	httpClient     *http.Client
 	baseURL        string // TensorFlow Hub base URL
+	kaggleURL      string // Where tfhub.dev redirects models to
 	modelValidator *core.ModelValidator
 }
 
@@ -25,13 +27,48 @@ type TensorFlowHubAdapter struct {
 func NewTensorFlowHubAdapter() *TensorFlowHubAdapter {
 	return &TensorFlowHubAdapter{
 		httpClient: &http.Client{
-			Timeout: 5 * time.Minute,
+			Timeout:   5 * time.Minute,
+			Transport: core.Transport,
 		},
 		baseURL:        "https://tfhub.dev",
+		kaggleURL:      core.DefaultKaggleURL,
 		modelValidator: core.NewModelValidator(),
 	}
 }
 
+// TensorFlowHubFactory implements AdapterFactory for creating TensorFlow Hub adapters.
+type TensorFlowHubFactory struct{}
+
+// NewTensorFlowHubFactory creates a new TensorFlow Hub factory.
+func NewTensorFlowHubFactory() *TensorFlowHubFactory {
+	return &TensorFlowHubFactory{}
+}
+
+// Name returns the factory name.
+func (f *TensorFlowHubFactory) Name() string {
+	return "tensorflow-hub"
+}
+
+// Create creates a new TensorFlow Hub adapter with the given configuration.
+// TensorFlow Hub needs no token, so config.Token is ignored.
+func (f *TensorFlowHubFactory) Create(config core.AdapterConfig) (core.RepositoryAdapter, error) {
+	adapter := NewTensorFlowHubAdapter()
+	if config.BaseURL != "" {
+		adapter.baseURL = strings.TrimSuffix(config.BaseURL, "/")
+	}
+	adapter.httpClient.Timeout = factoryTimeout(config)
+	return adapter, nil
+}
+
+// modelExists validates a model by where tfhub.dev redirects it on Kaggle (confirmed
+// with the Kaggle API when KAGGLE_USERNAME and KAGGLE_KEY are set), falling back to the
+// model page heuristic
+func (t *TensorFlowHubAdapter) modelExists(ctx context.Context, modelURL string) (bool, error) {
+	kaggle := t.modelValidator.Kaggle(modelURL, os.Getenv("KAGGLE_USERNAME"), os.Getenv("KAGGLE_KEY"))
+	kaggle.KaggleURL = t.kaggleURL
+	return t.modelValidator.Validate(ctx, kaggle, t.modelValidator.Page(modelURL))
+}
+
 // Name returns the name of the adapter.
 func (t *TensorFlowHubAdapter) Name() string {
 	return "tensorflow-hub"
@@ -126,7 +163,7 @@ func (t *TensorFlowHubAdapter) GetManifest(ctx context.Context, namespace, name,
 	resp, err := t.httpClient.Do(req)
 	if err != nil {
 		// Network error - validate model exists before creating manifest
-		valid, err := t.modelValidator.ValidateModelExists(ctx, modelURL)
+		valid, err := t.modelExists(ctx, modelURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to validate model existence: %w", err)
 		}
@@ -143,7 +180,7 @@ func (t *TensorFlowHubAdapter) GetManifest(ctx context.Context, namespace, name,
 	// Check if model exists - 404 means model not found
 	if resp.StatusCode == http.StatusNotFound {
 		// Metadata API returned 404 - validate model page exists
-		valid, err := t.modelValidator.ValidateModelExists(ctx, modelURL)
+		valid, err := t.modelExists(ctx, modelURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to validate model existence: %w", err)
 		}
@@ -157,7 +194,7 @@ func (t *TensorFlowHubAdapter) GetManifest(ctx context.Context, namespace, name,
 	// Other non-200 status codes - validate model exists
 	if resp.StatusCode != http.StatusOK {
 		// Validate model exists by checking the base model URL
-		valid, err := t.modelValidator.ValidateModelExists(ctx, modelURL)
+		valid, err := t.modelExists(ctx, modelURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to validate model existence: %w", err)
 		}
@@ -188,7 +225,7 @@ func (t *TensorFlowHubAdapter) GetManifest(ctx context.Context, namespace, name,
 	// Decode metadata
 	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
 		// If decode fails, validate model exists before creating basic manifest
-		valid, err := t.modelValidator.ValidateModelExists(ctx, modelURL)
+		valid, err := t.modelExists(ctx, modelURL)
 		if err != nil {
 			return nil, fmt.Errorf("failed to validate model existence: %w", err)
 		}
@@ -237,18 +274,7 @@ func (t *TensorFlowHubAdapter) GetManifest(ctx context.Context, namespace, name,
 				Inputs:  []types.IOSpec{},
 				Outputs: []types.IOSpec{},
 			},
-			Requirements: types.Requirements{
-				Compute: types.Compute{
-					CPU: types.CPURequirement{
-						MinCores:         2,
-						RecommendedCores: 4,
-					},
-					Memory: types.MemoryRequirement{
-						MinGB:         2.0,
-						RecommendedGB: 4.0,
-					},
-				},
-			},
+			Requirements: model.DefaultRequirements(),
 		},
 		Distribution: types.Distribution{
 			Package: types.PackageInfo{
@@ -326,18 +352,7 @@ func (t *TensorFlowHubAdapter) createBasicManifest(namespace, name, version, pub
 					},
 				},
 			},
-			Requirements: types.Requirements{
-				Compute: types.Compute{
-					CPU: types.CPURequirement{
-						MinCores:         2,
-						RecommendedCores: 4,
-					},
-					Memory: types.MemoryRequirement{
-						MinGB:         2.0,
-						RecommendedGB: 4.0,
-					},
-				},
-			},
+			Requirements: model.DefaultRequirements(),
 		},
 		Distribution: types.Distribution{
 			Package: types.PackageInfo{
@@ -399,13 +414,20 @@ func (t *TensorFlowHubAdapter) DownloadPackage(ctx context.Context, manifest *ty
 	if err := builder.AddFile(modelFile, "model.tar.gz"); err != nil {
 		return fmt.Errorf("failed to add file to package: %w", err)
 	}
+	builder.SetSource(types.ProvenanceSource{
+		Adapter:    t.Name(),
+		Model:      manifest.Metadata.Name,
+		Repository: modelURL,
+		Revision:   version,
+	})
+	builder.SetFileURL("model.tar.gz", downloadURL)
 
 	if err := builder.Build(destPath); err != nil {
 		return fmt.Errorf("failed to build package: %w", err)
 	}
 
 	// Update manifest with checksum
-	if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
 		fmt.Printf("Warning: failed to update manifest checksum: %v\n", err)
 	}
 
@@ -0,0 +1,330 @@
+// Package builtin provides default adapters for Axon.
+//
+// # URL Adapter
+//
+// The URL adapter installs a single model file from any HTTP(S) URL, for model shares
+// that aren't behind a registry. The file's format is inferred from its extension.
+package builtin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// urlFormat is a model file format the URL adapter recognizes
+type urlFormat struct {
+	Type      string // Format type and execution format
+	Framework string
+}
+
+// urlFormats maps file extensions to formats
+var urlFormats = map[string]urlFormat{
+	".onnx":        {Type: "onnx", Framework: "ONNX"},
+	".pt":          {Type: "pytorch", Framework: "PyTorch"},
+	".pth":         {Type: "pytorch", Framework: "PyTorch"},
+	".safetensors": {Type: "safetensors", Framework: "PyTorch"},
+	".gguf":        {Type: "gguf", Framework: "GGUF"},
+	".h5":          {Type: "tensorflow", Framework: "TensorFlow"},
+	".keras":       {Type: "tensorflow", Framework: "TensorFlow"},
+	".pb":          {Type: "tensorflow", Framework: "TensorFlow"},
+}
+
+// URLSpec describes a download in a url spec file, for URLs that can't be written as a
+// model spec (e.g. signed URLs with a query string) or that need a checksum or metadata:
+//
+//	url: https://models.internal/resnet50.onnx?token=abc
+//	sha256: 5546055f0339...
+//	license: apache-2.0
+//
+// The spec file is installed as url/<path to the file>.
+type URLSpec struct {
+	URL         string `yaml:"url"`
+	SHA256      string `yaml:"sha256,omitempty"`      // Expected checksum of the downloaded file
+	Format      string `yaml:"format,omitempty"`      // File extension to use instead of the URL's, e.g. onnx
+	Description string `yaml:"description,omitempty"` // Defaults to the URL
+	License     string `yaml:"license,omitempty"`
+}
+
+// URLAdapter implements RepositoryAdapter for model files at arbitrary URLs
+type URLAdapter struct {
+	httpClient     *core.HTTPClient // Existence checks
+	downloadClient *core.HTTPClient // Downloads, which may take longer than any timeout
+}
+
+// NewURLAdapter creates a new URL adapter.
+func NewURLAdapter() *URLAdapter {
+	return &URLAdapter{
+		httpClient:     core.NewHTTPClient("", defaultTimeout),
+		downloadClient: core.NewHTTPClient("", 0),
+	}
+}
+
+// Name returns the adapter name.
+func (u *URLAdapter) Name() string {
+	return "url"
+}
+
+// CanHandle returns true for the url namespace.
+func (u *URLAdapter) CanHandle(namespace, name string) bool {
+	return namespace == spec.URLNamespace
+}
+
+// Search is not supported: there is nothing to search.
+func (u *URLAdapter) Search(ctx context.Context, query string) ([]types.SearchResult, error) {
+	return []types.SearchResult{}, nil
+}
+
+// resolveURLSpec returns the download a model name describes: the URL itself
+// (https/example.com/model.onnx), or a url spec file (specs/model.yaml)
+func resolveURLSpec(name string) (*URLSpec, error) {
+	if rawURL, ok := spec.DirectURL(name); ok {
+		return &URLSpec{URL: rawURL}, nil
+	}
+	if ext := strings.ToLower(path.Ext(name)); ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("%s/%s is neither a URL nor a url spec file (.yaml)", spec.URLNamespace, name)
+	}
+
+	data, err := os.ReadFile(filepath.FromSlash(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read url spec file: %w", err)
+	}
+	var urlSpec URLSpec
+	if err := yaml.Unmarshal(data, &urlSpec); err != nil {
+		return nil, fmt.Errorf("failed to parse url spec file %s: %w", name, err)
+	}
+	if urlSpec.URL == "" {
+		return nil, fmt.Errorf("url spec file %s has no url", name)
+	}
+	parsed, err := url.Parse(urlSpec.URL)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return nil, fmt.Errorf("url spec file %s: %q is not an http or https URL", name, urlSpec.URL)
+	}
+	if urlSpec.SHA256 != "" {
+		digest, _, err := spec.ParseDigest(spec.DigestPrefix + strings.TrimPrefix(urlSpec.SHA256, spec.DigestPrefix))
+		if err != nil || len(digest) != 64 {
+			return nil, fmt.Errorf("url spec file %s: sha256 must be 64 hex characters", name)
+		}
+		urlSpec.SHA256 = digest
+	}
+	return &urlSpec, nil
+}
+
+// fileName returns the name of the downloaded file in the package and its format
+func (s *URLSpec) fileName() (string, urlFormat, error) {
+	parsed, err := url.Parse(s.URL)
+	if err != nil {
+		return "", urlFormat{}, fmt.Errorf("invalid URL %q: %w", s.URL, err)
+	}
+	base := path.Base(parsed.Path)
+	ext := strings.ToLower(path.Ext(base))
+	if s.Format != "" {
+		ext = "." + strings.TrimPrefix(strings.ToLower(s.Format), ".")
+		if !strings.EqualFold(path.Ext(base), ext) {
+			base = "model" + ext
+		}
+	}
+
+	format, ok := urlFormats[ext]
+	if !ok {
+		supported := make([]string, 0, len(urlFormats))
+		for e := range urlFormats {
+			supported = append(supported, e)
+		}
+		sort.Strings(supported)
+		return "", urlFormat{}, fmt.Errorf("can't infer the model format of %q from its extension (supported: %s); set format in a url spec file", base, strings.Join(supported, ", "))
+	}
+	return base, format, nil
+}
+
+// GetManifest describes the file at the URL. The file's checksum is computed when it
+// is downloaded, and checked against the spec file's sha256 if one is given.
+func (u *URLAdapter) GetManifest(ctx context.Context, namespace, name, version string) (*types.Manifest, error) {
+	urlSpec, err := resolveURLSpec(name)
+	if err != nil {
+		return nil, err
+	}
+	fileName, format, err := urlSpec.fileName()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the file exists before committing to a download; servers that don't
+	// support HEAD are given the benefit of the doubt
+	var size int64
+	resp, err := u.httpClient.Do(ctx, http.MethodHead, urlSpec.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", redactURL(urlSpec.URL), err)
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("model not found: %s", redactURL(urlSpec.URL))
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, fmt.Errorf("access to %s denied (status %d)", redactURL(urlSpec.URL), resp.StatusCode)
+	case resp.StatusCode == http.StatusOK && resp.ContentLength > 0:
+		size = resp.ContentLength
+	}
+
+	description := urlSpec.Description
+	if description == "" {
+		description = fmt.Sprintf("Model file from %s", redactURL(urlSpec.URL))
+	}
+	license := urlSpec.License
+	if license == "" {
+		license = "Unknown"
+	}
+	parsed, _ := url.Parse(urlSpec.URL)
+
+	return &types.Manifest{
+		APIVersion: "v1",
+		Kind:       "Model",
+		Metadata: types.Metadata{
+			Name:        name,
+			Namespace:   namespace,
+			Version:     version,
+			Description: description,
+			License:     license,
+			Created:     time.Now(),
+			Updated:     time.Now(),
+		},
+		Spec: types.Spec{
+			Framework: types.Framework{
+				Name:    format.Framework,
+				Version: "latest",
+			},
+			Format: types.Format{
+				Type:            format.Type,
+				ExecutionFormat: format.Type,
+				Files: []types.ModelFile{
+					{
+						Path:   fileName,
+						Size:   size,
+						SHA256: urlSpec.SHA256, // Computed during download if not given
+					},
+				},
+			},
+		},
+		Distribution: types.Distribution{
+			Package: types.PackageInfo{
+				URL: redactURL(urlSpec.URL), // The full URL is read from the spec when downloading
+			},
+			Registry: types.RegistryInfo{
+				URL:       parsed.Scheme + "://" + parsed.Host,
+				Namespace: spec.URLNamespace,
+			},
+		},
+	}, nil
+}
+
+// DownloadPackage downloads the file, checks and records its checksum, and packages it.
+func (u *URLAdapter) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress core.ProgressCallback) error {
+	if len(manifest.Spec.Format.Files) != 1 {
+		return fmt.Errorf("expected a single model file in the manifest, got %d", len(manifest.Spec.Format.Files))
+	}
+	file := &manifest.Spec.Format.Files[0]
+	urlSpec, err := resolveURLSpec(manifest.Metadata.Name)
+	if err != nil {
+		return err
+	}
+	fileURL := urlSpec.URL
+
+	builder, err := core.NewPackageBuilder()
+	if err != nil {
+		return fmt.Errorf("failed to create package builder: %w", err)
+	}
+	defer builder.Cleanup()
+
+	tempFile := filepath.Join(utils.TempDir(), fmt.Sprintf("url-download-%d%s", time.Now().UnixNano(), path.Ext(file.Path)))
+	defer func() { _ = os.Remove(tempFile) }()
+
+	resp, err := u.downloadClient.Get(ctx, fileURL)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", redactURL(fileURL), err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: unexpected status code: %d", redactURL(fileURL), resp.StatusCode)
+	}
+	if err := core.SaveResponse(ctx, resp, tempFile, progress); err != nil {
+		return fmt.Errorf("failed to download %s: %w", redactURL(fileURL), err)
+	}
+
+	// The file is hashed as it's added to the package
+	if err := builder.AddFile(tempFile, file.Path); err != nil {
+		return fmt.Errorf("failed to add file to package: %w", err)
+	}
+	digest, _ := builder.FileDigest(file.Path)
+	if file.SHA256 != "" && digest.SHA256 != file.SHA256 {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256:%s, got sha256:%s", redactURL(fileURL), file.SHA256, digest.SHA256)
+	}
+	file.SHA256 = digest.SHA256
+	file.Size = digest.Size
+	builder.SetSource(types.ProvenanceSource{
+		Adapter:    u.Name(),
+		Model:      manifest.Metadata.Name,
+		Repository: redactURL(fileURL),
+	})
+	builder.SetFileURL(file.Path, redactURL(fileURL))
+	builder.SetFileETag(file.Path, resp.Header.Get("ETag"))
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+	if err := builder.Build(destPath); err != nil {
+		return fmt.Errorf("failed to build package: %w", err)
+	}
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
+		return fmt.Errorf("failed to update manifest checksum: %w", err)
+	}
+	return nil
+}
+
+// redactURL drops the query string, which often carries a signature or token, from
+// URLs that are printed or recorded
+func redactURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.RawQuery == "" && parsed.User == nil) {
+		return rawURL
+	}
+	parsed.RawQuery = ""
+	parsed.User = nil
+	return parsed.String()
+}
+
+// URLFactory implements AdapterFactory for creating URL adapters.
+type URLFactory struct{}
+
+// NewURLFactory creates a new URL factory.
+func NewURLFactory() *URLFactory {
+	return &URLFactory{}
+}
+
+// Name returns the factory name.
+func (f *URLFactory) Name() string {
+	return "url"
+}
+
+// Create creates a new URL adapter. Only the timeout of existence checks is
+// configurable: the URL is part of each model's name.
+func (f *URLFactory) Create(config core.AdapterConfig) (core.RepositoryAdapter, error) {
+	adapter := NewURLAdapter()
+	if config.Timeout > 0 {
+		adapter.httpClient = core.NewHTTPClient("", factoryTimeout(config))
+	}
+	return adapter, nil
+}
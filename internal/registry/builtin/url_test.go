@@ -0,0 +1,112 @@
+package builtin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/spec"
+)
+
+func TestURLAdapter(t *testing.T) {
+	content := []byte("onnx weights")
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/resnet.onnx" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.URL.Query().Get("sig") != "" && r.URL.Query().Get("sig") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(content)
+	}))
+	defer srv.Close()
+
+	t.Chdir(t.TempDir())
+	writeSpec := func(name, body string) {
+		t.Helper()
+		if err := os.WriteFile(name, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeSpec("signed.yaml", "url: "+srv.URL+"/models/resnet.onnx?sig=secret\nsha256: "+checksum+"\nlicense: mit\n")
+	writeSpec("tampered.yaml", "url: "+srv.URL+"/models/resnet.onnx\nsha256: "+strings.Repeat("0", 64)+"\n")
+	writeSpec("renamed.yaml", "url: "+srv.URL+"/models/resnet.onnx\nformat: pt\n")
+	writeSpec("nourl.yaml", "license: mit\n")
+
+	tests := []struct {
+		name        string
+		spec        string
+		file        string
+		format      string
+		manifestErr string
+		downloadErr string
+	}{
+		{name: "escaped URL", spec: "url/" + strings.Replace(srv.URL, "://", "%3A//", 1) + "/models/resnet.onnx", file: "resnet.onnx", format: "onnx"},
+		{name: "spec file", spec: "url/signed.yaml", file: "resnet.onnx", format: "onnx"},
+		{name: "format override", spec: "url/renamed.yaml", file: "model.pt", format: "pytorch"},
+		{name: "checksum mismatch", spec: "url/tampered.yaml", file: "resnet.onnx", format: "onnx", downloadErr: "checksum mismatch"},
+		{name: "not found", spec: "url/" + srv.URL + "/models/missing.onnx", manifestErr: "not found"},
+		{name: "unknown extension", spec: "url/" + srv.URL + "/models/resnet.bin", manifestErr: "can't infer the model format"},
+		{name: "spec file without url", spec: "url/nourl.yaml", manifestErr: "has no url"},
+		{name: "neither", spec: "url/models/resnet", manifestErr: "neither a URL nor a url spec file"},
+	}
+
+	adapter := NewURLAdapter()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := spec.Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.spec, err)
+			}
+			if !adapter.CanHandle(ref.Namespace, ref.Name) {
+				t.Fatalf("CanHandle(%s, %s) = false", ref.Namespace, ref.Name)
+			}
+
+			manifest, err := adapter.GetManifest(context.Background(), ref.Namespace, ref.Name, ref.Version)
+			if tt.manifestErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.manifestErr) {
+					t.Fatalf("GetManifest() error = %v, want %q", err, tt.manifestErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetManifest() error = %v", err)
+			}
+			if manifest.Spec.Format.Type != tt.format || manifest.Spec.Format.Files[0].Path != tt.file {
+				t.Errorf("format = %s with file %s, want %s with %s", manifest.Spec.Format.Type, manifest.Spec.Format.Files[0].Path, tt.format, tt.file)
+			}
+			if strings.Contains(manifest.Distribution.Package.URL, "secret") {
+				t.Errorf("manifest records the signed URL %s", manifest.Distribution.Package.URL)
+			}
+
+			destPath := filepath.Join(t.TempDir(), "model.axon")
+			err = adapter.DownloadPackage(context.Background(), manifest, destPath, nil)
+			if tt.downloadErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.downloadErr) {
+					t.Fatalf("DownloadPackage() error = %v, want %q", err, tt.downloadErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadPackage() error = %v", err)
+			}
+			if file := manifest.Spec.Format.Files[0]; file.SHA256 != checksum || file.Size != int64(len(content)) {
+				t.Errorf("file = %+v, want sha256 %s and size %d", file, checksum, len(content))
+			}
+			if manifest.Distribution.Package.SHA256 == "" {
+				t.Error("package checksum not recorded")
+			}
+		})
+	}
+}
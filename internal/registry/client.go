@@ -3,14 +3,21 @@ package registry
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/mlOS-foundation/axon/internal/manifest"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
 	"github.com/mlOS-foundation/axon/pkg/types"
 	"github.com/mlOS-foundation/axon/pkg/utils"
 )
@@ -20,6 +27,7 @@ type Client struct {
 	baseURL    string
 	httpClient *http.Client
 	mirrors    []string
+	token      string
 }
 
 // BaseURL returns the base URL of the client
@@ -27,25 +35,127 @@ func (c *Client) BaseURL() string {
 	return c.baseURL
 }
 
+// SetToken sets the bearer token sent to the registry
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// SetTimeout sets the timeout of registry requests
+func (c *Client) SetTimeout(timeout time.Duration) {
+	c.httpClient.Timeout = timeout
+}
+
+// authorize adds the token to requests for the registry itself. Packages may be served
+// from mirrors or other hosts, which must not receive it.
+func (c *Client) authorize(req *http.Request) {
+	if c.token != "" && c.baseURL != "" && strings.HasPrefix(req.URL.String(), strings.TrimRight(c.baseURL, "/")+"/") {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
 // NewClient creates a new registry client
 func NewClient(baseURL string, mirrors []string) *Client {
 	return &Client{
 		baseURL: baseURL,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: core.Transport,
 		},
 		mirrors: mirrors,
 	}
 }
 
-// Search searches for models in the registry
+// Search searches for models in the registry. Static registries, which can't answer
+// queries, are searched through the search.json that 'axon registry export' writes.
 func (c *Client) Search(ctx context.Context, query string) ([]types.SearchResult, error) {
-	url := fmt.Sprintf("%s/api/v1/search?q=%s", c.baseURL, query)
+	data, err := c.get(ctx, fmt.Sprintf("%s/api/v1/search?q=%s", c.baseURL, url.QueryEscape(query)))
+	if errors.Is(err, os.ErrNotExist) {
+		return c.searchStatic(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var results []types.SearchResult
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
 
+	return results, nil
+}
+
+// searchStatic matches query against the search index of a static registry: every word
+// must be in the model's namespace, name, description or tags
+func (c *Client) searchStatic(ctx context.Context, query string) ([]types.SearchResult, error) {
+	data, err := c.get(ctx, fmt.Sprintf("%s/api/v1/search.json", c.baseURL))
+	if err != nil {
+		return nil, err
+	}
+	var models []types.SearchResult
+	if err := json.Unmarshal(data, &models); err != nil {
+		return nil, fmt.Errorf("failed to parse search index: %w", err)
+	}
+
+	terms := strings.Fields(strings.ToLower(query))
+	results := []types.SearchResult{}
+	for _, model := range models {
+		text := strings.ToLower(strings.Join(append([]string{model.Namespace + "/" + model.Name, model.Description}, model.Tags...), " "))
+		matches := true
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			results = append(results, model)
+		}
+	}
+	return results, nil
+}
+
+// GetIndex retrieves the registry index, or the index.json of a static registry.
+// Registries that serve the index as a plain list of models (with "version" instead of
+// "latest_version") are accepted too.
+func (c *Client) GetIndex(ctx context.Context) (*types.RegistryIndex, error) {
+	data, err := c.get(ctx, fmt.Sprintf("%s/api/v1/index", c.baseURL))
+	if errors.Is(err, os.ErrNotExist) {
+		data, err = c.get(ctx, fmt.Sprintf("%s/api/v1/index.json", c.baseURL))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		types.IndexModelEntry
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &entries); err == nil {
+		index := &types.RegistryIndex{}
+		for _, entry := range entries {
+			if entry.LatestVersion == "" {
+				entry.LatestVersion = entry.Version
+			}
+			index.Models = append(index.Models, entry.IndexModelEntry)
+		}
+		return index, nil
+	}
+
+	var index types.RegistryIndex
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse index: %w", err)
+	}
+	return &index, nil
+}
+
+// get retrieves a registry URL. It returns an error wrapping os.ErrNotExist if the
+// registry answers 404.
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	c.authorize(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -55,16 +165,18 @@ func (c *Client) Search(ctx context.Context, query string) ([]types.SearchResult
 		_ = resp.Body.Close()
 	}()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%s: %w", url, os.ErrNotExist)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	var results []types.SearchResult
-	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
-
-	return results, nil
+	return data, nil
 }
 
 // GetManifest retrieves a model manifest from the registry
@@ -75,6 +187,7 @@ func (c *Client) GetManifest(ctx context.Context, namespace, name, version strin
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	c.authorize(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -99,9 +212,108 @@ func (c *Client) GetManifest(ctx context.Context, namespace, name, version strin
 		return nil, fmt.Errorf("failed to parse manifest: %w", err)
 	}
 
+	// Packages published with 'axon publish --registry-dir' are served by the registry itself
+	if strings.HasPrefix(manifest.Distribution.Package.URL, "/") {
+		manifest.Distribution.Package.URL = strings.TrimRight(c.baseURL, "/") + manifest.Distribution.Package.URL
+	}
+
 	return manifest, nil
 }
 
+// GetVersions retrieves the versions the registry has of a model, with the one latest
+// resolves to. Static registries that only have a versions.json are supported too. It
+// returns an error wrapping os.ErrNotExist if the registry doesn't list them.
+func (c *Client) GetVersions(ctx context.Context, namespace, name string) (*types.ModelVersions, error) {
+	var lastErr error
+	for _, file := range []string{"versions", "versions.json"} {
+		url := fmt.Sprintf("%s/api/v1/models/%s/%s/%s", c.baseURL, namespace, name, file)
+		list, err := c.getVersions(ctx, url)
+		if err == nil {
+			if list.Latest == "" {
+				list.Latest = spec.LatestVersion(list.Versions)
+			}
+			return list, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		lastErr = fmt.Errorf("no versions listed for %s/%s: %w", namespace, name, err)
+	}
+	return nil, lastErr
+}
+
+// ListVersions retrieves the versions the registry has of a model, oldest first. It
+// returns an error wrapping os.ErrNotExist if the registry doesn't list them.
+func (c *Client) ListVersions(ctx context.Context, namespace, name string) ([]string, error) {
+	list, err := c.GetVersions(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return list.Versions, nil
+}
+
+func (c *Client) getVersions(ctx context.Context, url string) (*types.ModelVersions, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, os.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var list types.ModelVersions
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse versions: %w", err)
+	}
+	return &list, nil
+}
+
+// GetAttestation retrieves the attestation published with a model version. It returns
+// an error wrapping os.ErrNotExist if the registry has none.
+func (c *Client) GetAttestation(ctx context.Context, namespace, name, version string) ([]byte, error) {
+	url := fmt.Sprintf("%s/api/v1/models/%s/%s/%s/attestation.json", c.baseURL, namespace, name, version)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no attestation for %s/%s@%s: %w", namespace, name, version, os.ErrNotExist)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return data, nil
+}
+
 // DownloadPackage downloads a model package
 func (c *Client) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress ProgressCallback) error {
 	urls := []string{manifest.Distribution.Package.URL}
@@ -124,6 +336,7 @@ func (c *Client) downloadFromURL(ctx context.Context, url, destPath, expectedSHA
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
+	c.authorize(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -146,18 +359,20 @@ func (c *Client) downloadFromURL(ctx context.Context, url, destPath, expectedSHA
 	}()
 
 	reader := &progressReader{
-		Reader:   resp.Body,
+		Reader:   core.LimitDownload(ctx, resp.Body),
 		Total:    resp.ContentLength,
 		Callback: progress,
 	}
 
-	if _, err := io.Copy(file, reader); err != nil {
+	// The package is hashed as it's written, not read again once it's downloaded
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hasher), reader); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
 	// Verify checksum if provided
 	if expectedSHA256 != "" {
-		if err := verifyChecksum(destPath, expectedSHA256); err != nil {
+		if err := utils.CompareSHA256(hex.EncodeToString(hasher.Sum(nil)), expectedSHA256); err != nil {
 			return fmt.Errorf("checksum verification failed: %w", err)
 		}
 	}
@@ -183,11 +398,3 @@ func (pr *progressReader) Read(p []byte) (int, error) {
 	}
 	return n, err
 }
-
-func verifyChecksum(filePath, expectedSHA256 string) error {
-	if expectedSHA256 == "" {
-		return nil // No checksum to verify
-	}
-
-	return utils.VerifySHA256(filePath, expectedSHA256)
-}
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestClientAuthorize(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+		url   string
+		want  string
+	}{
+		{name: "registry request", token: "secret", url: "https://registry.example.com/api/v1/search?q=bert", want: "Bearer secret"},
+		{name: "other host", token: "secret", url: "https://mirror.example.com/api/v1/search", want: ""},
+		{name: "host with registry prefix", token: "secret", url: "https://registry.example.com.evil.net/x", want: ""},
+		{name: "no token", token: "", url: "https://registry.example.com/api/v1/search", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewClient("https://registry.example.com/", nil)
+			c.SetToken(tt.token)
+			req, err := http.NewRequest("GET", tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.authorize(req)
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientGetIndex(t *testing.T) {
+	for _, body := range []string{
+		`{"version": "1", "models": [{"namespace": "nlp", "name": "bert", "latest_version": "1.0.0", "downloads": 42}]}`,
+		`[{"namespace": "nlp", "name": "bert", "version": "1.0.0"}]`,
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/api/v1/index" {
+				http.NotFound(w, r)
+				return
+			}
+			_, _ = w.Write([]byte(body))
+		}))
+
+		index, err := NewClient(server.URL, nil).GetIndex(context.Background())
+		server.Close()
+		if err != nil {
+			t.Fatalf("GetIndex() error = %v for %s", err, body)
+		}
+		if len(index.Models) != 1 || index.Models[0].Name != "bert" || index.Models[0].LatestVersion != "1.0.0" {
+			t.Errorf("GetIndex() = %+v for %s", index.Models, body)
+		}
+	}
+}
+
+func TestClientGetManifestAndAttestation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/models/nlp/bert/1.0.0/manifest.yaml":
+			_, _ = w.Write([]byte("apiVersion: v1\nkind: Model\nmetadata:\n  namespace: nlp\n  name: bert\n  version: 1.0.0\ndistribution:\n  package:\n    url: /packages/nlp-bert-1.0.0.axon\n"))
+		case "/api/v1/models/nlp/bert/1.0.0/attestation.json":
+			_, _ = w.Write([]byte(`{"payloadType": "application/vnd.in-toto+json"}`))
+		case "/api/v1/models/nlp/bert/versions.json":
+			_, _ = w.Write([]byte(`{"namespace": "nlp", "name": "bert", "versions": ["1.0.0", "1.1.0"]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	c := NewClient(server.URL, nil)
+
+	m, err := c.GetManifest(context.Background(), "nlp", "bert", "1.0.0")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if want := server.URL + "/packages/nlp-bert-1.0.0.axon"; m.Distribution.Package.URL != want {
+		t.Errorf("package URL = %q, want %q", m.Distribution.Package.URL, want)
+	}
+
+	if data, err := c.GetAttestation(context.Background(), "nlp", "bert", "1.0.0"); err != nil || len(data) == 0 {
+		t.Errorf("GetAttestation() = %q, %v", data, err)
+	}
+	if _, err := c.GetAttestation(context.Background(), "nlp", "bert", "2.0.0"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("GetAttestation() of a version without one error = %v, want os.ErrNotExist", err)
+	}
+
+	if versions, err := c.ListVersions(context.Background(), "nlp", "bert"); err != nil || len(versions) != 2 || versions[1] != "1.1.0" {
+		t.Errorf("ListVersions() = %v, %v", versions, err)
+	}
+	if _, err := c.ListVersions(context.Background(), "nlp", "gpt2"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ListVersions() of an unknown model error = %v, want os.ErrNotExist", err)
+	}
+}
+
+func TestClientStaticRegistry(t *testing.T) {
+	// A static host: only files, so the index and search are JSON files
+	files := map[string]string{
+		"/api/v1/index.json":  `{"version": "1", "models": [{"namespace": "nlp", "name": "bert", "latest_version": "1.0.0"}]}`,
+		"/api/v1/search.json": `[{"namespace": "nlp", "name": "bert", "version": "1.0.0", "description": "Bidirectional encoder", "tags": ["text"]}, {"namespace": "vision", "name": "resnet", "version": "2.0.0", "description": "Residual network", "tags": ["image"]}]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+
+	index, err := client.GetIndex(context.Background())
+	if err != nil {
+		t.Fatalf("GetIndex() error = %v", err)
+	}
+	if len(index.Models) != 1 || index.Models[0].Name != "bert" {
+		t.Errorf("GetIndex() = %+v", index.Models)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"bert", []string{"bert"}},
+		{"IMAGE", []string{"resnet"}},
+		{"nlp/ber", []string{"bert"}},
+		{"residual network", []string{"resnet"}},
+		{"residual text", nil},
+		{"", []string{"bert", "resnet"}},
+	}
+	for _, tt := range tests {
+		results, err := client.Search(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("Search(%q) error = %v", tt.query, err)
+		}
+		var names []string
+		for _, result := range results {
+			names = append(names, result.Name)
+		}
+		if !reflect.DeepEqual(names, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, names, tt.want)
+		}
+	}
+}
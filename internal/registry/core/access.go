@@ -0,0 +1,29 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// AccessDeniedError reports a repository refusing a model's files or metadata with 401
+// or 403, which for private organization repositories is a matter of the token rather
+// than of the model existing
+type AccessDeniedError struct {
+	Adapter  string // Adapter to log in to, e.g. "huggingface"
+	Model    string // Model ID in the repository, e.g. "acme/internal-bert"
+	Status   int    // http.StatusUnauthorized or http.StatusForbidden
+	HasToken bool   // Whether the request was sent with a token
+}
+
+func (e *AccessDeniedError) Error() string {
+	login := fmt.Sprintf("'axon login %s --scope read'", e.Adapter)
+	switch {
+	case !e.HasToken:
+		return fmt.Sprintf("%s was not found or is private: log in with a token that can read it (%s)", e.Model, login)
+	case e.Status == http.StatusUnauthorized:
+		return fmt.Sprintf("the %s token was rejected for %s (HTTP 401): it may be expired or revoked, log in again (%s)", e.Adapter, e.Model, login)
+	}
+	owner, _, _ := strings.Cut(e.Model, "/")
+	return fmt.Sprintf("the %s token has no read access to %s (HTTP 403): a fine-grained token needs read access to the repositories of %s", e.Adapter, e.Model, owner)
+}
@@ -85,6 +85,25 @@ type RepositoryAdapter interface {
 	Search(ctx context.Context, query string) ([]types.SearchResult, error)
 }
 
+// TokenAuthenticator is implemented by adapters that accept an access token
+// (e.g., for gated or private models, or higher API rate limits).
+type TokenAuthenticator interface {
+	SetToken(token string)
+}
+
+// VersionLister is implemented by adapters that can list the versions of a model, so
+// version ranges such as ^1.2 can be resolved against them.
+type VersionLister interface {
+	ListVersions(ctx context.Context, namespace, name string) ([]string, error)
+}
+
+// RevisionResolver is implemented by adapters whose repositories have revisions (e.g.
+// commit SHAs), so installed models can be checked for upstream changes without
+// downloading anything. The revision is the one recorded in package provenance.
+type RevisionResolver interface {
+	LatestRevision(ctx context.Context, namespace, name string) (string, error)
+}
+
 // AdapterConfig holds configuration options for adapters.
 // This follows the Builder Pattern for flexible adapter configuration.
 type AdapterConfig struct {
@@ -186,6 +205,12 @@ func (r *AdapterRegistry) RegisterFactory(factory AdapterFactory) {
 	r.factories[factory.Name()] = factory
 }
 
+// HasFactory reports whether a factory with the given name is registered.
+func (r *AdapterRegistry) HasFactory(name string) bool {
+	_, ok := r.factories[name]
+	return ok
+}
+
 // CreateAdapter creates an adapter using a registered factory.
 func (r *AdapterRegistry) CreateAdapter(factoryName string, config AdapterConfig) (RepositoryAdapter, error) {
 	factory, ok := r.factories[factoryName]
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of ChunkedDownload
+const (
+	DefaultDownloadChunks = 8
+	DefaultChunkThreshold = 64 << 20
+	DefaultChunkRetries   = 3
+)
+
+// ChunkedDownload configures ranged parallel downloads of large files. CDNs such as
+// Hugging Face's (LFS and Xet) cap the throughput of one connection well below a fast
+// link, so large files are fetched as several byte ranges at once.
+type ChunkedDownload struct {
+	Chunks    int   // Ranges fetched in parallel per file (0 = DefaultDownloadChunks, 1 = off)
+	Threshold int64 // Files at least this large are chunked (0 = DefaultChunkThreshold)
+	Retries   int   // Retries of a failed range, resuming where it stopped (0 = DefaultChunkRetries)
+}
+
+// chunkedDownload holds the settings from SetChunkedDownload
+var chunkedDownload atomic.Pointer[ChunkedDownload]
+
+// SetChunkedDownload configures ranged parallel downloads, filling in defaults
+func SetChunkedDownload(c ChunkedDownload) {
+	if c.Chunks == 0 {
+		c.Chunks = DefaultDownloadChunks
+	}
+	if c.Threshold == 0 {
+		c.Threshold = DefaultChunkThreshold
+	}
+	if c.Retries == 0 {
+		c.Retries = DefaultChunkRetries
+	}
+	chunkedDownload.Store(&c)
+}
+
+// chunkedDownloadSettings returns the configured settings, or the defaults
+func chunkedDownloadSettings() ChunkedDownload {
+	if c := chunkedDownload.Load(); c != nil {
+		return *c
+	}
+	return ChunkedDownload{Chunks: DefaultDownloadChunks, Threshold: DefaultChunkThreshold, Retries: DefaultChunkRetries}
+}
+
+// chunkRetryDelay is the wait before the first retry of a range, doubled for each next one
+var chunkRetryDelay = time.Second
+
+// SaveResponseChunked writes the file a response is for to destPath like SaveResponse,
+// but fetches files above the chunking threshold as parallel byte ranges when the server
+// accepts them. The ranges are requested from the URL the response came from (after
+// redirects to a CDN), pinned to its strong ETag or else its Last-Modified date so a
+// file changing mid-download fails the download rather than mixing versions. Anything
+// else, including files with neither to pin them to, is saved from the response.
+func SaveResponseChunked(ctx context.Context, client *http.Client, resp *http.Response, destPath string, progress ProgressCallback) error {
+	settings := chunkedDownloadSettings()
+	size := resp.ContentLength
+	if settings.Chunks < 2 || size < settings.Threshold || resp.Header.Get("Accept-Ranges") != "bytes" || resp.Request == nil || rangeValidator(resp.Header) == "" {
+		return SaveResponse(ctx, resp, destPath, progress)
+	}
+	// The ranges are requested anew
+	_ = resp.Body.Close()
+
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	file, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if err := file.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate file: %w", err)
+	}
+
+	var current atomic.Int64
+	var progressMu sync.Mutex
+	written := func(n int64) {
+		done := current.Add(n)
+		if progress != nil {
+			progressMu.Lock()
+			progress(done, size)
+			progressMu.Unlock()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	chunkSize := (size + int64(settings.Chunks) - 1) / int64(settings.Chunks)
+	errs := make(chan error, settings.Chunks)
+	var wg sync.WaitGroup
+	for start := int64(0); start < size; start += chunkSize {
+		end := min(start+chunkSize, size) - 1
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			if err := fetchRange(ctx, client, resp, file, start, end, settings.Retries, written); err != nil {
+				errs <- err
+				cancel() // No use fetching the rest
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+
+	if progress != nil {
+		progress(current.Load(), size)
+	}
+	return nil
+}
+
+// fetchRange downloads bytes start-end (inclusive) of the response's file into file,
+// retrying from where a failed attempt stopped
+func fetchRange(ctx context.Context, client *http.Client, resp *http.Response, file *os.File, start, end int64, retries int, written func(int64)) error {
+	offset := start
+	delay := chunkRetryDelay
+	var err error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		var n int64
+		n, err = fetchRangeOnce(ctx, client, resp, file, offset, end, written)
+		offset += n
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || errors.Is(err, errRangeChanged) {
+			return err
+		}
+	}
+	return fmt.Errorf("bytes %d-%d after %d attempts: %w", start, end, retries+1, err)
+}
+
+// errRangeChanged reports a file that changed on the server during the download
+var errRangeChanged = errors.New("file changed on the server during the download")
+
+// fetchRangeOnce requests bytes offset-end and writes them at their offset in file,
+// returning how many were written
+func fetchRangeOnce(ctx context.Context, client *http.Client, resp *http.Response, file *os.File, offset, end int64, written func(int64)) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resp.Request.URL.String(), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	// The headers of the request that got the file, as redirects left them (credentials
+	// aren't forwarded to another host)
+	req.Header = resp.Request.Header.Clone()
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", offset, end))
+	req.Header.Set("If-Range", rangeValidator(resp.Header))
+
+	rangeResp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = rangeResp.Body.Close()
+	}()
+	switch rangeResp.StatusCode {
+	case http.StatusPartialContent:
+	case http.StatusOK:
+		// The server ignored the range: If-Range didn't match
+		return 0, errRangeChanged
+	default:
+		return 0, fmt.Errorf("unexpected status code: %d", rangeResp.StatusCode)
+	}
+
+	w := &offsetWriter{file: file, offset: offset, written: written}
+	n, err := io.Copy(w, io.LimitReader(LimitDownload(ctx, rangeResp.Body), end-offset+1))
+	if err == nil && n < end-offset+1 {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// rangeValidator returns the If-Range value that pins ranges to the version of the file a
+// response is for: its ETag if it's strong, or else its Last-Modified date. Servers must
+// ignore If-Range with a weak ETag, so it can't pin ranges. It's empty when there is
+// nothing to pin them to.
+func rangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// offsetWriter writes sequentially into a file from an offset, reporting what it wrote
+type offsetWriter struct {
+	file    *os.File
+	offset  int64
+	written func(int64)
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	w.written(int64(n))
+	return n, err
+}
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSaveResponseChunked(t *testing.T) {
+	defer chunkedDownload.Store(nil)
+	defer func(delay time.Duration) { chunkRetryDelay = delay }(chunkRetryDelay)
+	chunkRetryDelay = time.Millisecond
+	SetChunkedDownload(ChunkedDownload{Chunks: 4, Threshold: 1 << 10})
+
+	content := bytes.Repeat([]byte("0123456789abcdef"), 1<<10) // 16 KiB
+	var ranges atomic.Int32
+	var failOnce sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/resolve/model.safetensors" {
+			// Large files redirect to the CDN
+			http.Redirect(w, r, "/cdn/model.safetensors", http.StatusFound)
+			return
+		}
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+			// The first range breaks off early and is resumed
+			broken := false
+			if r.Header.Get("Range") == "bytes=0-4095" {
+				failOnce.Do(func() { broken = true })
+			}
+			if broken {
+				w.Header().Set("Content-Range", "bytes 0-4095/16384")
+				w.Header().Set("Content-Length", "4096")
+				w.WriteHeader(http.StatusPartialContent)
+				_, _ = w.Write(content[:100])
+				return
+			}
+		}
+		w.Header().Set("ETag", `"v1"`)
+		http.ServeContent(w, r, "model.safetensors", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/resolve/model.safetensors")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	dest := filepath.Join(t.TempDir(), "model.safetensors")
+	var last int64
+	err = SaveResponseChunked(context.Background(), server.Client(), resp, dest, func(downloaded, total int64) {
+		if total != int64(len(content)) {
+			t.Errorf("progress total = %d, want %d", total, len(content))
+		}
+		last = downloaded
+	})
+	if err != nil {
+		t.Fatalf("SaveResponseChunked() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Error("reassembled file differs from the original")
+	}
+	if last != int64(len(content)) {
+		t.Errorf("progress ended at %d, want %d", last, len(content))
+	}
+	// 4 ranges and a retry of the broken one
+	if n := ranges.Load(); n != 5 {
+		t.Errorf("%d range requests, want 5", n)
+	}
+}
+
+func TestSaveResponseChunked_Fallbacks(t *testing.T) {
+	defer chunkedDownload.Store(nil)
+	SetChunkedDownload(ChunkedDownload{Chunks: 4, Threshold: 1 << 10})
+
+	content := strings.Repeat("x", 4<<10)
+	var ranges atomic.Int32
+	var changed atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+			if changed.Load() {
+				// A new version: If-Range no longer matches, so the whole file is sent
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(content))
+				return
+			}
+		}
+		switch r.URL.Path {
+		case "/small":
+			_, _ = w.Write([]byte("small"))
+		case "/no-ranges":
+			_, _ = w.Write([]byte(content))
+		case "/weak":
+			// If-Range with a weak ETag never matches, so ranges couldn't be pinned to it
+			w.Header().Set("ETag", `W/"v1"`)
+			http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "file", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	// Small files, servers that don't take ranges and files without a strong ETag or
+	// Last-Modified date are saved from the response
+	for _, path := range []string{"/small", "/no-ranges", "/weak"} {
+		resp, err := server.Client().Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dest := filepath.Join(t.TempDir(), "file")
+		if err := SaveResponseChunked(context.Background(), server.Client(), resp, dest, nil); err != nil {
+			t.Errorf("SaveResponseChunked(%s) error = %v", path, err)
+		}
+		_ = resp.Body.Close()
+	}
+	if n := ranges.Load(); n != 0 {
+		t.Errorf("%d range requests for files that can't be chunked", n)
+	}
+
+	resp, err := server.Client().Get(server.URL + "/model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	changed.Store(true)
+	err = SaveResponseChunked(context.Background(), server.Client(), resp, filepath.Join(t.TempDir(), "file"), nil)
+	if err == nil || !strings.Contains(err.Error(), "changed on the server") {
+		t.Errorf("SaveResponseChunked() of a file that changed: error = %v", err)
+	}
+}
+
+func TestSaveResponseChunked_WeakETag(t *testing.T) {
+	defer chunkedDownload.Store(nil)
+	SetChunkedDownload(ChunkedDownload{Chunks: 4, Threshold: 1 << 10})
+
+	content := strings.Repeat("0123456789abcdef", 1<<8)
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var ranges, ignored atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Range") != "" {
+			ranges.Add(1)
+			if strings.HasPrefix(r.Header.Get("If-Range"), "W/") {
+				ignored.Add(1)
+			}
+		}
+		// A weak ETag with a Last-Modified date, as CDNs that compress files send
+		w.Header().Set("ETag", `W/"v1"`)
+		http.ServeContent(w, r, "file", modified, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	dest := filepath.Join(t.TempDir(), "file")
+	if err := SaveResponseChunked(context.Background(), server.Client(), resp, dest, nil); err != nil {
+		t.Fatalf("SaveResponseChunked() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != content {
+		t.Error("reassembled file differs from the original")
+	}
+	// The ranges are pinned to the Last-Modified date instead of the weak ETag
+	if n := ranges.Load(); n != 4 || ignored.Load() != 0 {
+		t.Errorf("%d range requests, %d with a weak If-Range; want 4 and 0", n, ignored.Load())
+	}
+}
@@ -0,0 +1,30 @@
+package core
+
+import "fmt"
+
+// Approval modes of gated models
+const (
+	GatedAuto   = "auto"   // Access is granted as soon as the license is accepted
+	GatedManual = "manual" // The model's authors review each access request
+)
+
+// GatedModelError reports a model whose repository only serves its files to accounts
+// that accepted its license (Hugging Face gated models such as Llama), so installs fail
+// with what to do instead of a 401 or 403 halfway through the download
+type GatedModelError struct {
+	Adapter  string // Adapter to log in to, e.g. "huggingface"
+	Model    string // Model ID in the repository
+	URL      string // Page where the license is accepted
+	Approval string // GatedAuto or GatedManual
+	HasToken bool   // Whether the request was sent with a token
+}
+
+func (e *GatedModelError) Error() string {
+	if !e.HasToken {
+		return fmt.Sprintf("%s is a gated model: accept its license at %s, then log in with a token of that account ('axon login %s')", e.Model, e.URL, e.Adapter)
+	}
+	if e.Approval == GatedManual {
+		return fmt.Sprintf("%s is a gated model and your token has no access yet: request access at %s (its authors review requests, so access can take a while)", e.Model, e.URL)
+	}
+	return fmt.Sprintf("%s is a gated model and your token's account hasn't accepted its license: accept it at %s (access is granted right away)", e.Model, e.URL)
+}
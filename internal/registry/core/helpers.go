@@ -2,8 +2,6 @@
 package core
 
 import (
-	"archive/tar"
-	"compress/gzip"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
@@ -15,7 +13,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mlOS-foundation/axon/internal/model"
 	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 )
 
 // HTTPClient provides a configurable HTTP client for adapters.
@@ -30,7 +30,8 @@ type HTTPClient struct {
 func NewHTTPClient(baseURL string, timeout time.Duration) *HTTPClient {
 	return &HTTPClient{
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: Transport,
 		},
 		baseURL:   baseURL,
 		userAgent: "Axon-CLI/1.0",
@@ -71,14 +72,28 @@ func (c *HTTPClient) Get(ctx context.Context, url string) (*http.Response, error
 
 // PackageBuilder helps build .axon package files.
 // This provides common functionality for creating tar.gz packages with manifests.
+// Every package includes a provenance.json describing where its files came from.
 type PackageBuilder struct {
-	tempDir string
-	files   []string
+	tempDir    string
+	files      []string
+	source     types.ProvenanceSource
+	urls       map[string]string
+	etags      map[string]string
+	converter  *types.ProvenanceConverter
+	weightScan *types.WeightScan
+	digests    map[string]FileDigest // Taken as the files were added
+	written    *model.PackageWrite   // The package from the last Build or Rebuild
+}
+
+// FileDigest is the SHA-256 and size of a file, hashed as it was added to a package
+type FileDigest struct {
+	SHA256 string
+	Size   int64
 }
 
 // NewPackageBuilder creates a new package builder.
 func NewPackageBuilder() (*PackageBuilder, error) {
-	tempDir, err := os.MkdirTemp("", "axon-package-*")
+	tempDir, err := os.MkdirTemp(utils.TempDir(), "axon-package-*")
 	if err != nil {
 		return nil, fmt.Errorf("failed to create temp directory: %w", err)
 	}
@@ -86,6 +101,7 @@ func NewPackageBuilder() (*PackageBuilder, error) {
 	return &PackageBuilder{
 		tempDir: tempDir,
 		files:   []string{},
+		digests: make(map[string]FileDigest),
 	}, nil
 }
 
@@ -104,20 +120,7 @@ func (pb *PackageBuilder) AddFile(srcPath, destPath string) error {
 		_ = src.Close()
 	}()
 
-	dst, err := os.Create(destFullPath)
-	if err != nil {
-		return fmt.Errorf("failed to create destination file: %w", err)
-	}
-	defer func() {
-		_ = dst.Close()
-	}()
-
-	if _, err := io.Copy(dst, src); err != nil {
-		return fmt.Errorf("failed to copy file: %w", err)
-	}
-
-	pb.files = append(pb.files, destPath)
-	return nil
+	return pb.copyFile(src, destPath)
 }
 
 // AddFileFromReader adds a file to the package from an io.Reader.
@@ -127,7 +130,13 @@ func (pb *PackageBuilder) AddFileFromReader(reader io.Reader, destPath string) e
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	dst, err := os.Create(destFullPath)
+	return pb.copyFile(reader, destPath)
+}
+
+// copyFile copies a file into the package directory, hashing it on the way so the
+// provenance doesn't read it again
+func (pb *PackageBuilder) copyFile(r io.Reader, destPath string) error {
+	dst, err := os.Create(filepath.Join(pb.tempDir, destPath))
 	if err != nil {
 		return fmt.Errorf("failed to create destination file: %w", err)
 	}
@@ -135,70 +144,65 @@ func (pb *PackageBuilder) AddFileFromReader(reader io.Reader, destPath string) e
 		_ = dst.Close()
 	}()
 
-	if _, err := io.Copy(dst, reader); err != nil {
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(dst, hasher), r)
+	if err != nil {
+		return fmt.Errorf("failed to copy file: %w", err)
+	}
+	if err := dst.Close(); err != nil {
 		return fmt.Errorf("failed to copy file: %w", err)
 	}
 
 	pb.files = append(pb.files, destPath)
+	pb.digests[filepath.ToSlash(filepath.Clean(destPath))] = FileDigest{SHA256: hex.EncodeToString(hasher.Sum(nil)), Size: size}
 	return nil
 }
 
+// FileDigest returns the digest of a file added to the package, taken as it was added
+func (pb *PackageBuilder) FileDigest(destPath string) (FileDigest, bool) {
+	digest, ok := pb.digests[filepath.ToSlash(filepath.Clean(destPath))]
+	return digest, ok
+}
+
 // Build creates the final .axon package file.
 func (pb *PackageBuilder) Build(destPath string) error {
-	file, err := os.Create(destPath)
-	if err != nil {
-		return fmt.Errorf("failed to create package file: %w", err)
+	if err := pb.writeProvenance(); err != nil {
+		return err
 	}
-	defer func() {
-		_ = file.Close()
-	}()
 
-	gzWriter := gzip.NewWriter(file)
-	defer func() {
-		_ = gzWriter.Close()
-	}()
-
-	tarWriter := tar.NewWriter(gzWriter)
-	defer func() {
-		_ = tarWriter.Close()
-	}()
-
-	// Walk directory and add files to tar
-	return filepath.Walk(pb.tempDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		relPath, err := filepath.Rel(pb.tempDir, path)
-		if err != nil {
-			return err
-		}
-
-		header, err := tar.FileInfoHeader(info, "")
-		if err != nil {
-			return err
-		}
-		header.Name = relPath
+	// Packages are written in format v2, with an index for reading single files
+	written, err := model.WritePackage(pb.tempDir, destPath)
+	if err != nil {
+		return err
+	}
+	pb.written = written
+	return nil
+}
 
-		if err := tarWriter.WriteHeader(header); err != nil {
-			return err
-		}
+// Rebuild creates the package like Build, copying the compressed files that basePath (an
+// earlier build of the package) holds unchanged instead of compressing them again.
+func (pb *PackageBuilder) Rebuild(basePath, destPath string) (*model.PackageWrite, error) {
+	if err := pb.writeProvenance(); err != nil {
+		return nil, err
+	}
 
-		srcFile, err := os.Open(path)
-		if err != nil {
-			return err
-		}
-		defer func() {
-			_ = srcFile.Close()
-		}()
+	written, err := model.UpdatePackage(basePath, pb.tempDir, destPath)
+	if err != nil {
+		return nil, err
+	}
+	pb.written = written
+	return written, nil
+}
 
-		_, err = io.Copy(tarWriter, srcFile)
-		return err
-	})
+// UpdateManifestWithChecksum updates a manifest with the checksum and size of the
+// package, as they were taken while it was built
+func (pb *PackageBuilder) UpdateManifestWithChecksum(manifest *types.Manifest) error {
+	if pb.written == nil {
+		return fmt.Errorf("the package has not been built")
+	}
+	manifest.Distribution.Package.SHA256 = pb.written.SHA256
+	manifest.Distribution.Package.Size = pb.written.Size
+	return nil
 }
 
 // Cleanup removes the temporary directory.
@@ -244,6 +248,10 @@ func UpdateManifestWithIOSchema(manifest *types.Manifest, modelPath string) erro
 func UpdateManifestWithExecutionFormat(manifest *types.Manifest, modelPath string) error {
 	// Check for GGUF files first (native LLM format - no conversion needed)
 	files, err := os.ReadDir(modelPath)
+	if weights, weightsErr := os.ReadDir(filepath.Join(modelPath, model.WeightsDir)); err == nil && weightsErr == nil {
+		// Framework weights are in weights/ once the model directory is normalized
+		files = append(files, weights...)
+	}
 	if err == nil {
 		for _, file := range files {
 			name := strings.ToLower(file.Name())
@@ -256,7 +264,7 @@ func UpdateManifestWithExecutionFormat(manifest *types.Manifest, modelPath strin
 	}
 
 	// Check for ONNX file (second priority - already execution-ready)
-	if _, err := os.Stat(filepath.Join(modelPath, "model.onnx")); err == nil {
+	if _, err := os.Stat(filepath.Join(modelPath, model.ONNXFileName)); err == nil {
 		manifest.Spec.Format.ExecutionFormat = "onnx"
 		return nil
 	}
@@ -342,7 +350,12 @@ func DownloadFile(ctx context.Context, client *http.Client, url, destPath string
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
+	return SaveResponseChunked(ctx, client, resp, destPath, progress)
+}
 
+// SaveResponse writes the body of a response to destPath, reporting progress. It lets
+// callers that already checked a response save it without requesting the URL again.
+func SaveResponse(ctx context.Context, resp *http.Response, destPath string, progress ProgressCallback) error {
 	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
@@ -357,6 +370,7 @@ func DownloadFile(ctx context.Context, client *http.Client, url, destPath string
 
 	total := resp.ContentLength
 	var current int64
+	body := LimitDownload(ctx, resp.Body)
 
 	if progress != nil && total > 0 {
 		// Use progressWriter to track progress while writing to file
@@ -367,9 +381,9 @@ func DownloadFile(ctx context.Context, client *http.Client, url, destPath string
 			total:    total,
 			current:  &current,
 		}
-		_, err = io.Copy(pw, resp.Body)
+		_, err = io.Copy(pw, body)
 	} else {
-		_, err = io.Copy(file, resp.Body)
+		_, err = io.Copy(file, body)
 	}
 
 	if err != nil {
@@ -0,0 +1,229 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Defaults of HostRateLimits
+const (
+	DefaultRateLimitRetries = 3
+	DefaultRateLimitMaxWait = 5 * time.Minute
+)
+
+// HostRateLimits configures how repository requests are spaced and how rate limited
+// ones (429, or 503 with Retry-After) are retried. CI runs installing many models hit
+// the rate limits of Hugging Face and GitHub; waiting for the time the host asks for
+// turns those into delays instead of failures.
+type HostRateLimits struct {
+	RequestsPerSecond map[string]float64 // Per host name (missing = unlimited)
+	Retries           int                // Retries of a rate limited request (0 = DefaultRateLimitRetries)
+	MaxWait           time.Duration      // Longest wait a host may ask for before giving up (0 = DefaultRateLimitMaxWait)
+}
+
+// hostRateLimits holds the settings from SetHostRateLimits
+var hostRateLimits atomic.Pointer[HostRateLimits]
+
+// hostLimiters holds the limiter of each host requests were sent to
+var hostLimiters sync.Map // host name -> *hostLimiter
+
+// SetHostRateLimits configures rate limiting of repository requests, filling in defaults
+func SetHostRateLimits(l HostRateLimits) {
+	if l.Retries == 0 {
+		l.Retries = DefaultRateLimitRetries
+	}
+	if l.MaxWait == 0 {
+		l.MaxWait = DefaultRateLimitMaxWait
+	}
+	hostRateLimits.Store(&l)
+	hostLimiters.Range(func(host, _ interface{}) bool {
+		hostLimiters.Delete(host)
+		return true
+	})
+}
+
+// hostRateLimitSettings returns the configured settings, or the defaults
+func hostRateLimitSettings() HostRateLimits {
+	if l := hostRateLimits.Load(); l != nil {
+		return *l
+	}
+	return HostRateLimits{Retries: DefaultRateLimitRetries, MaxWait: DefaultRateLimitMaxWait}
+}
+
+// hostLimiter spaces the requests to one host and holds them while the host is rate limiting
+type hostLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration // Between request starts (0 = unlimited)
+	next     time.Time     // When the next request may start
+}
+
+// limiterFor returns the limiter of a host
+func limiterFor(host string, settings HostRateLimits) *hostLimiter {
+	if l, ok := hostLimiters.Load(host); ok {
+		return l.(*hostLimiter)
+	}
+	l := &hostLimiter{}
+	if rps := settings.RequestsPerSecond[host]; rps > 0 {
+		l.interval = time.Duration(float64(time.Second) / rps)
+	}
+	actual, _ := hostLimiters.LoadOrStore(host, l)
+	return actual.(*hostLimiter)
+}
+
+// wait reserves the next request slot and blocks until it starts
+func (l *hostLimiter) wait(req *http.Request) error {
+	l.mu.Lock()
+	now := time.Now()
+	start := now
+	if l.next.After(now) {
+		start = l.next
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	if !start.After(now) {
+		return nil
+	}
+	timer := time.NewTimer(start.Sub(now))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// pauseUntil holds every request to the host until t
+func (l *hostLimiter) pauseUntil(t time.Time) {
+	l.mu.Lock()
+	if t.After(l.next) {
+		l.next = t
+	}
+	l.mu.Unlock()
+}
+
+// rateLimitNotice reports a retry of a rate limited request
+var rateLimitNotice = func(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// RateLimitTransport is the http.RoundTripper of repository requests. It spaces the
+// requests to each host as configured with SetHostRateLimits, retries rate limited
+// requests after the wait the host asks for (Retry-After, X-RateLimit-Reset or
+// RateLimit-Reset), and holds further requests to a host whose quota is used up.
+type RateLimitTransport struct {
+	Base http.RoundTripper // nil = http.DefaultTransport
+}
+
+// Transport is the shared rate limiting transport repository clients use
+var Transport http.RoundTripper = &RateLimitTransport{}
+
+// RoundTrip sends the request, waiting and retrying while the host rate limits it
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	settings := hostRateLimitSettings()
+	host := req.URL.Hostname()
+	limiter := limiterFor(host, settings)
+
+	for attempt := 0; ; attempt++ {
+		if err := limiter.wait(req); err != nil {
+			return nil, err
+		}
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, ok := rateLimitReset(resp.Header, now); ok && reset.Sub(now) <= settings.MaxWait {
+				limiter.pauseUntil(reset)
+			}
+		}
+		if !isRateLimited(resp) {
+			return resp, nil
+		}
+
+		wait, ok := retryAfter(resp.Header, now)
+		if !ok {
+			wait = time.Duration(1<<attempt) * time.Second
+		}
+		if attempt >= settings.Retries || wait > settings.MaxWait || (req.Body != nil && req.GetBody == nil) {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+
+		rateLimitNotice("⏳ Rate limited by %s, retrying in %s (retry %d of %d)\n", host, wait.Round(time.Second), attempt+1, settings.Retries)
+		limiter.pauseUntil(now.Add(wait))
+		if req.Body != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isRateLimited reports whether the host rejected the request for its rate
+func isRateLimited(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != "")
+}
+
+// retryAfter returns how long a rate limited response asks the client to wait
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := strings.TrimSpace(header.Get("Retry-After")); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if t, err := http.ParseTime(value); err == nil {
+			return max(t.Sub(now), 0), true
+		}
+	}
+	if reset, ok := rateLimitReset(header, now); ok {
+		return max(reset.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns when the host's rate limit window resets: X-RateLimit-Reset
+// (Unix time, as GitHub sends it, or seconds), RateLimit-Reset (seconds), or the t
+// parameter of the RateLimit header (seconds, as Hugging Face sends it)
+func rateLimitReset(header http.Header, now time.Time) (time.Time, bool) {
+	if value := strings.TrimSpace(header.Get("X-RateLimit-Reset")); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil && n >= 0 {
+			// Values this large are Unix times rather than seconds to wait
+			if n > 1_000_000_000 {
+				return time.Unix(n, 0), true
+			}
+			return now.Add(time.Duration(n) * time.Second), true
+		}
+	}
+	if value := strings.TrimSpace(header.Get("RateLimit-Reset")); value != "" {
+		if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+			return now.Add(time.Duration(n) * time.Second), true
+		}
+	}
+	for _, param := range strings.Split(header.Get("RateLimit"), ";") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(param), "t="); ok {
+			if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+				return now.Add(time.Duration(n) * time.Second), true
+			}
+		}
+	}
+	return time.Time{}, false
+}
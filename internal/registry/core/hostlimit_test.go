@@ -0,0 +1,128 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitTransport_Retry(t *testing.T) {
+	defer hostRateLimits.Store(nil)
+	SetHostRateLimits(HostRateLimits{Retries: 2, MaxWait: time.Minute})
+	var notices []string
+	defer func(notice func(string, ...interface{})) { rateLimitNotice = notice }(rateLimitNotice)
+	rateLimitNotice = func(format string, args ...interface{}) {
+		notices = append(notices, fmt.Sprintf(format, args...))
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/limited" && requests.Add(1) == 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case r.URL.Path == "/long":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: Transport}
+
+	resp, err := client.Get(server.URL + "/limited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requests.Load() != 2 {
+		t.Errorf("status %d after %d requests, want 200 after a retry", resp.StatusCode, requests.Load())
+	}
+	if len(notices) != 1 || !strings.Contains(notices[0], "Rate limited by 127.0.0.1, retrying in 0s (retry 1 of 2)") {
+		t.Errorf("notices = %q", notices)
+	}
+
+	// A wait longer than the maximum fails right away with the host's response
+	resp, err = client.Get(server.URL + "/long")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || len(notices) != 1 {
+		t.Errorf("status %d with notices %q, want the 429 without retrying", resp.StatusCode, notices)
+	}
+}
+
+func TestRateLimitTransport_Spacing(t *testing.T) {
+	defer hostRateLimits.Store(nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host := strings.Split(strings.TrimPrefix(server.URL, "http://"), ":")[0]
+	SetHostRateLimits(HostRateLimits{RequestsPerSecond: map[string]float64{host: 20}})
+
+	client := &http.Client{Transport: Transport}
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	// Three requests at 20 per second start 50ms apart
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 requests took %s, want at least 100ms at 20 requests per second", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", http.Header{"Retry-After": {"30"}}, 30 * time.Second, true},
+		{"date", http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}, time.Minute, true},
+		{"github reset", http.Header{"X-Ratelimit-Reset": {strconv.FormatInt(now.Add(90*time.Second).Unix(), 10)}}, 90 * time.Second, true},
+		{"reset seconds", http.Header{"Ratelimit-Reset": {"12"}}, 12 * time.Second, true},
+		{"hugging face", http.Header{"Ratelimit": {`"api";r=0;t=55`}}, 55 * time.Second, true},
+		{"none", http.Header{}, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: retryAfter() = %s, %t; want %s, %t", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRateLimitTransport_Exhausted(t *testing.T) {
+	defer hostRateLimits.Store(nil)
+	SetHostRateLimits(HostRateLimits{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The quota is used up for the next 30 seconds
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("RateLimit-Reset", "30")
+	}))
+	defer server.Close()
+
+	resp, err := (&http.Client{Transport: Transport}).Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	u, _ := url.Parse(server.URL)
+	limiter := limiterFor(u.Hostname(), hostRateLimitSettings())
+	if until := time.Until(limiter.next); until < 25*time.Second {
+		t.Errorf("host paused for %s, want about 30s until its quota resets", until)
+	}
+}
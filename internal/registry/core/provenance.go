@@ -0,0 +1,151 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// builderVersion is the Axon version recorded in the provenance of built packages
+var builderVersion atomic.Value
+
+// SetBuilderVersion sets the Axon version recorded in the provenance of built packages
+func SetBuilderVersion(version string) {
+	builderVersion.Store(version)
+}
+
+// SetSource records the upstream repository the package's files come from
+func (pb *PackageBuilder) SetSource(source types.ProvenanceSource) {
+	pb.source = source
+}
+
+// SetFileURL records where a package file (by its path in the package) was downloaded from
+func (pb *PackageBuilder) SetFileURL(destPath, url string) {
+	if pb.urls == nil {
+		pb.urls = make(map[string]string)
+	}
+	pb.urls[filepath.ToSlash(destPath)] = url
+}
+
+// SetFileETag records the ETag the server sent with a package file, so 'axon update
+// --check' can ask the server whether the file changed without downloading it
+func (pb *PackageBuilder) SetFileETag(destPath, etag string) {
+	if etag == "" {
+		return
+	}
+	if pb.etags == nil {
+		pb.etags = make(map[string]string)
+	}
+	pb.etags[filepath.ToSlash(destPath)] = etag
+}
+
+// SetConverter records the converter that produced converted files in the package
+func (pb *PackageBuilder) SetConverter(converter *types.ProvenanceConverter) {
+	pb.converter = converter
+}
+
+// SetWeightScan records the scan of the package's weight files
+func (pb *PackageBuilder) SetWeightScan(scan *types.WeightScan) {
+	pb.weightScan = scan
+}
+
+// writeProvenance hashes the package files and writes provenance.json next to them
+func (pb *PackageBuilder) writeProvenance() error {
+	version, _ := builderVersion.Load().(string)
+	if version == "" {
+		version = "dev"
+	}
+	provenance := types.Provenance{
+		SchemaVersion: types.ProvenanceSchemaVersion,
+		Builder:       types.ProvenanceBuilder{Name: "axon", Version: version},
+		Source:        pb.source,
+		Files:         []types.ProvenanceFile{},
+		Converter:     pb.converter,
+		WeightScan:    pb.weightScan,
+	}
+
+	// Reproducible builds record $SOURCE_DATE_EPOCH; otherwise no time is recorded, so
+	// the same files give the same package
+	if t, ok := model.SourceDateEpoch(); ok {
+		provenance.BuiltAt = t
+	}
+
+	err := filepath.Walk(pb.tempDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		relPath, err := filepath.Rel(pb.tempDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if relPath == types.ProvenanceFileName {
+			return nil
+		}
+		// Files were hashed as they were added, so they are not read again here
+		digest, ok := pb.digests[relPath]
+		if !ok {
+			var err error
+			if digest.SHA256, digest.Size, err = ComputeChecksum(path); err != nil {
+				return err
+			}
+		}
+		provenance.Files = append(provenance.Files, types.ProvenanceFile{
+			Path:   relPath,
+			Size:   digest.Size,
+			SHA256: digest.SHA256,
+			URL:    pb.urls[relPath],
+			ETag:   pb.etags[relPath],
+		})
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to hash package files: %w", err)
+	}
+	sort.Slice(provenance.Files, func(i, j int) bool { return provenance.Files[i].Path < provenance.Files[j].Path })
+
+	return WriteProvenance(filepath.Join(pb.tempDir, types.ProvenanceFileName), &provenance)
+}
+
+// SaveProvenance copies the provenance.json written by Build to destPath
+func (pb *PackageBuilder) SaveProvenance(destPath string) error {
+	data, err := os.ReadFile(filepath.Join(pb.tempDir, types.ProvenanceFileName))
+	if err != nil {
+		return fmt.Errorf("failed to read provenance: %w", err)
+	}
+	if err := os.WriteFile(destPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	return nil
+}
+
+// ReadProvenance reads a provenance.json file
+func ReadProvenance(path string) (*types.Provenance, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var provenance types.Provenance
+	if err := json.Unmarshal(data, &provenance); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+	}
+	return &provenance, nil
+}
+
+// WriteProvenance writes a provenance.json file
+func WriteProvenance(path string, provenance *types.Provenance) error {
+	data, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode provenance: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write provenance: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/internal/model"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestPackageBuilder_Provenance(t *testing.T) {
+	SetBuilderVersion("1.2.3")
+	defer SetBuilderVersion("")
+
+	srcDir := t.TempDir()
+	weights := filepath.Join(srcDir, "weights")
+	if err := os.WriteFile(weights, []byte("weights"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	builder, err := NewPackageBuilder()
+	if err != nil {
+		t.Fatalf("NewPackageBuilder() error = %v", err)
+	}
+	defer func() { _ = builder.Cleanup() }()
+
+	if err := builder.AddFile(weights, "model.safetensors"); err != nil {
+		t.Fatal(err)
+	}
+	if err := builder.AddFileFromReader(strings.NewReader("notes"), "docs/README.md"); err != nil {
+		t.Fatal(err)
+	}
+	builder.SetSource(types.ProvenanceSource{Adapter: "huggingface", Model: "org/model", Revision: "abc123"})
+	builder.SetFileURL("model.safetensors", "https://example.com/model.safetensors")
+
+	packagePath := filepath.Join(t.TempDir(), "model.axon")
+	if err := builder.Build(packagePath); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	extracted := t.TempDir()
+	if err := model.ExtractPackage(packagePath, extracted); err != nil {
+		t.Fatalf("ExtractPackage() error = %v", err)
+	}
+	provenance, err := ReadProvenance(filepath.Join(extracted, types.ProvenanceFileName))
+	if err != nil {
+		t.Fatalf("ReadProvenance() error = %v", err)
+	}
+
+	if provenance.SchemaVersion != types.ProvenanceSchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", provenance.SchemaVersion, types.ProvenanceSchemaVersion)
+	}
+	if provenance.Builder.Name != "axon" || provenance.Builder.Version != "1.2.3" {
+		t.Errorf("Builder = %+v, want axon 1.2.3", provenance.Builder)
+	}
+	if provenance.Source.Revision != "abc123" || provenance.Source.Model != "org/model" {
+		t.Errorf("Source = %+v", provenance.Source)
+	}
+	if len(provenance.Files) != 2 {
+		t.Fatalf("Files = %+v, want 2 entries (provenance.json excluded)", provenance.Files)
+	}
+
+	wantDigest, wantSize, _ := ComputeChecksum(weights)
+	file := provenance.Files[1]
+	if file.Path != "model.safetensors" || file.SHA256 != wantDigest || file.Size != wantSize {
+		t.Errorf("Files[1] = %+v, want model.safetensors %s (%d bytes)", file, wantDigest, wantSize)
+	}
+	if file.URL != "https://example.com/model.safetensors" {
+		t.Errorf("Files[1].URL = %q", file.URL)
+	}
+	if provenance.Files[0].Path != "docs/README.md" || provenance.Files[0].URL != "" {
+		t.Errorf("Files[0] = %+v, want docs/README.md without URL", provenance.Files[0])
+	}
+}
+
+func TestPackageBuilder_Reproducible(t *testing.T) {
+	build := func() string {
+		builder, err := NewPackageBuilder()
+		if err != nil {
+			t.Fatalf("NewPackageBuilder() error = %v", err)
+		}
+		defer func() { _ = builder.Cleanup() }()
+		if err := builder.AddFileFromReader(strings.NewReader("weights"), "model.safetensors"); err != nil {
+			t.Fatal(err)
+		}
+		builder.SetSource(types.ProvenanceSource{Adapter: "huggingface", Model: "org/model", Revision: "abc123"})
+
+		packagePath := filepath.Join(t.TempDir(), "model.axon")
+		if err := builder.Build(packagePath); err != nil {
+			t.Fatalf("Build() error = %v", err)
+		}
+		digest, _, err := ComputeChecksum(packagePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return digest
+	}
+
+	first := build()
+	time.Sleep(1100 * time.Millisecond) // Into the next second of any timestamp
+	if second := build(); second != first {
+		t.Errorf("rebuilding the same files gave %s, then %s", first, second)
+	}
+}
+
+func TestPackageBuilder_Digests(t *testing.T) {
+	builder, err := NewPackageBuilder()
+	if err != nil {
+		t.Fatalf("NewPackageBuilder() error = %v", err)
+	}
+	defer func() { _ = builder.Cleanup() }()
+	if err := builder.AddFileFromReader(strings.NewReader("hello, world"), "weights/model.bin"); err != nil {
+		t.Fatal(err)
+	}
+
+	digest, ok := builder.FileDigest("weights/model.bin")
+	if !ok || digest.SHA256 != "09ca7e4eaa6e8ae9c7d261167129184883644d07dfba7cbfbc4c8a2e08360d5b" || digest.Size != 12 {
+		t.Errorf("FileDigest() = %+v, %v", digest, ok)
+	}
+
+	var manifest types.Manifest
+	if err := builder.UpdateManifestWithChecksum(&manifest); err == nil {
+		t.Error("UpdateManifestWithChecksum() before Build succeeded")
+	}
+	packagePath := filepath.Join(t.TempDir(), "model.axon")
+	if err := builder.Build(packagePath); err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if err := builder.UpdateManifestWithChecksum(&manifest); err != nil {
+		t.Fatalf("UpdateManifestWithChecksum() error = %v", err)
+	}
+	checksum, size, err := ComputeChecksum(packagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Distribution.Package.SHA256 != checksum || manifest.Distribution.Package.Size != size {
+		t.Errorf("manifest package = %+v, want sha256:%s (%d bytes)", manifest.Distribution.Package, checksum, size)
+	}
+
+	provenance, err := ReadProvenance(filepath.Join(builder.tempDir, types.ProvenanceFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provenance.Files) != 1 || provenance.Files[0].SHA256 != digest.SHA256 || provenance.Files[0].Size != digest.Size {
+		t.Errorf("provenance files = %+v, want the digest taken when the file was added", provenance.Files)
+	}
+}
@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxRateLimitChunk caps how much a rate limited reader reads at once, so waits stay short
+// and concurrent streams interleave smoothly
+const maxRateLimitChunk = 32 * 1024
+
+// RateLimiter is a token bucket limiting throughput in bytes per second.
+// A single limiter can be shared by any number of concurrent readers.
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // Bytes per second
+	burst  float64 // Bucket capacity
+	tokens float64 // May go negative: readers reserve bytes and then wait off the debt
+	last   time.Time
+}
+
+// NewRateLimiter creates a limiter allowing bytesPerSecond, with a one second burst
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	rate := float64(bytesPerSecond)
+	return &RateLimiter{
+		rate:   rate,
+		burst:  rate,
+		tokens: rate,
+		last:   time.Now(),
+	}
+}
+
+// WaitN accounts for n bytes and blocks until the limiter allows them
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens -= float64(n)
+	wait := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader wraps r so that reads from it are throttled by the limiter
+func (l *RateLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	chunk := maxRateLimitChunk
+	if int(l.rate) < chunk {
+		chunk = max(int(l.rate), 1)
+	}
+	return &rateLimitedReader{ctx: ctx, reader: r, limiter: l, chunk: chunk}
+}
+
+type rateLimitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *RateLimiter
+	chunk   int
+}
+
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.chunk {
+		p = p[:r.chunk]
+	}
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// downloadLimiter is shared by all downloads, so the limit applies to their combined rate
+var downloadLimiter atomic.Pointer[RateLimiter]
+
+// SetDownloadRateLimit limits the combined rate of all downloads (0 = unlimited)
+func SetDownloadRateLimit(bytesPerSecond int64) {
+	if bytesPerSecond <= 0 {
+		downloadLimiter.Store(nil)
+		return
+	}
+	downloadLimiter.Store(NewRateLimiter(bytesPerSecond))
+}
+
+// LimitDownload wraps a download body with the download rate limit, if one is set
+func LimitDownload(ctx context.Context, r io.Reader) io.Reader {
+	if l := downloadLimiter.Load(); l != nil {
+		return l.Reader(ctx, r)
+	}
+	return r
+}
+
+// ParseRate parses a transfer rate such as "10MB/s", "512K" or "1048576" into bytes per
+// second. Units are binary (1K = 1024 bytes), as in curl's --limit-rate. Empty or "0"
+// means unlimited.
+func ParseRate(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	s = strings.TrimSuffix(s, "/S")
+	s = strings.TrimSuffix(s, "PS")
+	s = strings.TrimSuffix(s, "B")
+	s = strings.TrimSuffix(s, "I")
+	if s == "" {
+		if strings.TrimSpace(value) == "" {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("invalid rate %q", value)
+	}
+
+	multiplier := 1.0
+	switch s[len(s)-1] {
+	case 'K':
+		multiplier = 1 << 10
+	case 'M':
+		multiplier = 1 << 20
+	case 'G':
+		multiplier = 1 << 30
+	}
+	if multiplier != 1 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate %q (expected e.g. 10MB/s, 512K or a number of bytes per second)", value)
+	}
+	return int64(n * multiplier), nil
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "1048576", want: 1 << 20},
+		{value: "512K", want: 512 << 10},
+		{value: "10MB/s", want: 10 << 20},
+		{value: "10MiB/s", want: 10 << 20},
+		{value: "1.5m", want: 3 << 19},
+		{value: "2GBps", want: 2 << 30},
+		{value: "fast", wantErr: true},
+		{value: "MB/s", wantErr: true},
+		{value: "-1M", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRate(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRateLimiter_SharedAcrossStreams(t *testing.T) {
+	const rate = 64 * 1024
+	limiter := NewRateLimiter(rate)
+	data := make([]byte, rate)
+
+	// The bucket starts full (one second of burst), so two streams of one second's
+	// worth each should take about one more second in total
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out bytes.Buffer
+			if _, err := io.Copy(&out, limiter.Reader(context.Background(), bytes.NewReader(data))); err != nil {
+				t.Errorf("copy error = %v", err)
+			}
+			if out.Len() != len(data) {
+				t.Errorf("copied %d bytes, want %d", out.Len(), len(data))
+			}
+		}()
+	}
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	if elapsed < 800*time.Millisecond || elapsed > 3*time.Second {
+		t.Errorf("two streams took %v, want about 1s at the shared rate", elapsed)
+	}
+}
+
+func TestRateLimiter_Cancel(t *testing.T) {
+	limiter := NewRateLimiter(1024)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := io.Copy(io.Discard, limiter.Reader(ctx, bytes.NewReader(make([]byte, 8*1024))))
+	if err != context.Canceled {
+		t.Errorf("copy error = %v, want context.Canceled", err)
+	}
+}
+
+func TestLimitDownload(t *testing.T) {
+	defer SetDownloadRateLimit(0)
+
+	r := bytes.NewReader(nil)
+	if LimitDownload(context.Background(), r) != io.Reader(r) {
+		t.Error("LimitDownload() wrapped the reader without a rate limit")
+	}
+
+	SetDownloadRateLimit(1 << 20)
+	if LimitDownload(context.Background(), r) == io.Reader(r) {
+		t.Error("LimitDownload() did not wrap the reader with a rate limit set")
+	}
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Resolution memoizes remote lookups (manifests, file listings, config files) for the
+// duration of one command, so each endpoint is requested at most once even when several
+// installs run concurrently. It travels in the context: adapters look it up with
+// ResolutionFrom, and without one nothing is memoized.
+type Resolution struct {
+	mu      sync.Mutex
+	entries map[string]*resolutionEntry
+}
+
+// resolutionEntry is a memoized lookup; done is closed once value and err are set
+type resolutionEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+type resolutionKey struct{}
+
+// NewResolution returns an empty resolution context
+func NewResolution() *Resolution {
+	return &Resolution{entries: make(map[string]*resolutionEntry)}
+}
+
+// WithResolution returns a context carrying r
+func WithResolution(ctx context.Context, r *Resolution) context.Context {
+	return context.WithValue(ctx, resolutionKey{}, r)
+}
+
+// ResolutionFrom returns the resolution context of ctx, or nil
+func ResolutionFrom(ctx context.Context) *Resolution {
+	r, _ := ctx.Value(resolutionKey{}).(*Resolution)
+	return r
+}
+
+// Do returns the result of fetch for key, calling it at most once. Concurrent callers
+// with the same key wait for the first call. Failures aren't kept, so a later call
+// retries. A nil Resolution calls fetch every time.
+func (r *Resolution) Do(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	if r == nil {
+		return fetch()
+	}
+
+	r.mu.Lock()
+	if entry, ok := r.entries[key]; ok {
+		r.mu.Unlock()
+		<-entry.done
+		return entry.value, entry.err
+	}
+	entry := &resolutionEntry{done: make(chan struct{})}
+	r.entries[key] = entry
+	r.mu.Unlock()
+
+	entry.value, entry.err = fetch()
+	if entry.err != nil {
+		r.mu.Lock()
+		delete(r.entries, key)
+		r.mu.Unlock()
+	}
+	close(entry.done)
+	return entry.value, entry.err
+}
+
+// Bytes memoizes a lookup returning raw content, such as a config.json
+func (r *Resolution) Bytes(key string, fetch func() ([]byte, error)) ([]byte, error) {
+	value, err := r.Do(key, func() (interface{}, error) { return fetch() })
+	if err != nil {
+		return nil, err
+	}
+	return value.([]byte), nil
+}
+
+// ResolveManifest returns the adapter's manifest for a model, memoized in the context's
+// resolution. Each caller gets its own copy, since installs modify the manifest.
+func ResolveManifest(ctx context.Context, adapter RepositoryAdapter, namespace, name, version string) (*types.Manifest, error) {
+	key := fmt.Sprintf("manifest:%s:%s/%s@%s", adapter.Name(), namespace, name, version)
+	value, err := ResolutionFrom(ctx).Do(key, func() (interface{}, error) {
+		m, err := adapter.GetManifest(ctx, namespace, name, version)
+		if err != nil {
+			return nil, err
+		}
+		// Keep a private copy so changes by the first caller don't leak to later ones
+		return yaml.Marshal(m)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var m types.Manifest
+	if err := yaml.Unmarshal(value.([]byte), &m); err != nil {
+		return nil, fmt.Errorf("failed to copy manifest: %w", err)
+	}
+	return &m, nil
+}
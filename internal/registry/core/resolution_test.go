@@ -0,0 +1,131 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestResolutionDo(t *testing.T) {
+	r := NewResolution()
+	var calls int32
+	release := make(chan struct{})
+	fetch := func() (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "value", nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]interface{}, 8)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = r.Do("key", fetch)
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("fetch called %d times, want 1", calls)
+	}
+	for i, result := range results {
+		if result != "value" {
+			t.Errorf("result %d = %v, want value", i, result)
+		}
+	}
+
+	_, _ = r.Do("other", func() (interface{}, error) { atomic.AddInt32(&calls, 1); return nil, nil })
+	if calls != 2 {
+		t.Errorf("different key did not fetch")
+	}
+}
+
+func TestResolutionDoRetriesFailures(t *testing.T) {
+	r := NewResolution()
+	calls := 0
+	fetch := func() (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("temporary failure")
+		}
+		return "value", nil
+	}
+
+	if _, err := r.Do("key", fetch); err == nil {
+		t.Fatal("first Do() should fail")
+	}
+	if value, err := r.Do("key", fetch); err != nil || value != "value" {
+		t.Errorf("second Do() = %v, %v", value, err)
+	}
+	_, _ = r.Do("key", fetch)
+	if calls != 2 {
+		t.Errorf("fetch called %d times, want 2", calls)
+	}
+}
+
+func TestNilResolution(t *testing.T) {
+	var r *Resolution
+	calls := 0
+	for i := 0; i < 2; i++ {
+		_, _ = r.Do("key", func() (interface{}, error) { calls++; return nil, nil })
+	}
+	if calls != 2 {
+		t.Errorf("nil resolution memoized: fetch called %d times", calls)
+	}
+	if ResolutionFrom(context.Background()) != nil {
+		t.Error("ResolutionFrom() without a resolution should be nil")
+	}
+}
+
+// countingAdapter counts GetManifest calls
+type countingAdapter struct {
+	calls int32
+}
+
+func (a *countingAdapter) Name() string               { return "counting" }
+func (a *countingAdapter) CanHandle(_, _ string) bool { return true }
+func (a *countingAdapter) Search(context.Context, string) ([]types.SearchResult, error) {
+	return nil, nil
+}
+func (a *countingAdapter) DownloadPackage(context.Context, *types.Manifest, string, ProgressCallback) error {
+	return nil
+}
+func (a *countingAdapter) GetManifest(_ context.Context, namespace, name, version string) (*types.Manifest, error) {
+	atomic.AddInt32(&a.calls, 1)
+	return &types.Manifest{Metadata: types.Metadata{Namespace: namespace, Name: name, Version: version}}, nil
+}
+
+func TestResolveManifest(t *testing.T) {
+	adapter := &countingAdapter{}
+	ctx := WithResolution(context.Background(), NewResolution())
+
+	first, err := ResolveManifest(ctx, adapter, "nlp", "bert", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Metadata.Description = "modified by the first install"
+
+	second, err := ResolveManifest(ctx, adapter, "nlp", "bert", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if adapter.calls != 1 {
+		t.Errorf("GetManifest called %d times, want 1", adapter.calls)
+	}
+	if second.Metadata.Description != "" {
+		t.Error("changes to one caller's manifest leaked to another")
+	}
+
+	if _, err := ResolveManifest(ctx, adapter, "nlp", "bert", "2.0.0"); err != nil || adapter.calls != 2 {
+		t.Errorf("another version: calls = %d, err = %v", adapter.calls, err)
+	}
+}
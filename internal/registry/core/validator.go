@@ -3,8 +3,11 @@ package core
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -19,20 +22,169 @@ type ModelValidator struct {
 func NewModelValidator() *ModelValidator {
 	return &ModelValidator{
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: Transport,
 		},
 	}
 }
 
-// ValidateModelExists checks if a model exists at the given URL.
-// Returns true if model exists, false if not found, error for validation failures.
-//
-// This is a generic helper that can be used by all adapters.
+// Validator is a strategy for checking whether a model exists in its repository.
+// Validators that can't tell (no credentials for the API, an unexpected answer)
+// return ErrUndetermined so the next strategy is asked.
+type Validator interface {
+	Exists(ctx context.Context) (bool, error)
+}
+
+// ErrUndetermined is returned by validators that couldn't tell whether a model exists
+var ErrUndetermined = errors.New("model existence undetermined")
+
+// Validate asks the validators in order until one determines whether the model exists.
+// Adapters list the API of their repository first and the HTML page heuristic, if at
+// all, last. Returns true when none could tell, so the download step can surface a more
+// precise error; network failures are returned as errors.
+func (mv *ModelValidator) Validate(ctx context.Context, validators ...Validator) (bool, error) {
+	for _, v := range validators {
+		exists, err := v.Exists(ctx)
+		if errors.Is(err, ErrUndetermined) {
+			continue
+		}
+		return exists, err
+	}
+	return true, nil
+}
+
+// API returns a validator asking a repository's JSON API (Hugging Face, GitHub,
+// ModelScope, Replicate) about the resource at apiURL
+func (mv *ModelValidator) API(apiURL string, headers map[string]string) *APIValidator {
+	return &APIValidator{client: mv.httpClient, URL: apiURL, Headers: headers}
+}
+
+// Kaggle returns a validator for TF Hub models, which tfhub.dev redirects to Kaggle
+func (mv *ModelValidator) Kaggle(pageURL, username, key string) *KaggleValidator {
+	return &KaggleValidator{client: mv.httpClient, PageURL: pageURL, KaggleURL: DefaultKaggleURL, Username: username, Key: key}
+}
+
+// Page returns the HTML page heuristic, for repositories without an API
+func (mv *ModelValidator) Page(modelURL string) *PageValidator {
+	return &PageValidator{client: mv.httpClient, URL: modelURL}
+}
+
+// APIValidator checks a model with a repository's JSON API. JSON APIs report missing
+// resources with a plain 404 (or 410), so no HTML page sniffing is needed. Auth
+// failures (401/403) and server errors are undetermined.
+type APIValidator struct {
+	client  *http.Client
+	URL     string
+	Headers map[string]string
+}
+
+// Exists reports whether the API has the resource
+func (v *APIValidator) Exists(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", v.URL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Axon-CLI/1.0")
+	for key, value := range v.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("network error during validation: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return false, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	}
+	return false, ErrUndetermined
+}
+
+// DefaultKaggleURL is where TF Hub models are hosted since tfhub.dev moved to Kaggle
+const DefaultKaggleURL = "https://www.kaggle.com"
+
+// KaggleValidator checks a TF Hub model by where tfhub.dev redirects it: known models
+// go to their Kaggle model page, unknown ones to Kaggle's model search. The Kaggle
+// model API then confirms the model when credentials (those of the kaggle CLI) are
+// given; without them the model page is undetermined.
+type KaggleValidator struct {
+	client    *http.Client
+	PageURL   string // The tfhub.dev model URL
+	KaggleURL string // Kaggle's base URL
+	Username  string // Kaggle credentials, optional
+	Key       string
+}
+
+// Exists reports whether Kaggle has the model tfhub.dev redirects to
+func (v *KaggleValidator) Exists(ctx context.Context) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", v.PageURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "Axon-CLI/1.0")
+
+	// Follow tfhub.dev's redirects until they reach Kaggle
+	kaggle := strings.TrimSuffix(v.KaggleURL, "/")
+	var target *url.URL
+	client := *v.client
+	client.CheckRedirect = func(next *http.Request, via []*http.Request) error {
+		if strings.HasPrefix(next.URL.String(), kaggle+"/") {
+			target = next.URL
+			return http.ErrUseLastResponse
+		}
+		if len(via) >= 10 {
+			return fmt.Errorf("stopped after 10 redirects")
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("network error during validation: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if target == nil {
+		if resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, ErrUndetermined
+	}
+	// Model pages are /models/{owner}/{model}[/{framework}/{variation}/{version}]
+	parts := strings.Split(strings.Trim(target.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "models" {
+		return false, nil
+	}
+	if v.Username == "" || v.Key == "" {
+		return false, ErrUndetermined
+	}
+
+	apiURL := fmt.Sprintf("%s/api/v1/models/%s/%s/get", kaggle, parts[1], parts[2])
+	auth := base64.StdEncoding.EncodeToString([]byte(v.Username + ":" + v.Key))
+	api := &APIValidator{client: v.client, URL: apiURL, Headers: map[string]string{"Authorization": "Basic " + auth}}
+	return api.Exists(ctx)
+}
+
+// PageValidator is the HTML page heuristic, the last resort for repositories without
+// an API: it fetches the model page and looks for signs of an error or search page
+type PageValidator struct {
+	client *http.Client
+	URL    string
+}
+
+// Exists reports whether the page looks like a model page.
 // Uses GET request with redirect following to handle repositories that don't support HEAD.
-func (mv *ModelValidator) ValidateModelExists(ctx context.Context, modelURL string) (bool, error) {
+func (v *PageValidator) Exists(ctx context.Context) (bool, error) {
 	// Use GET request (some repositories like TensorFlow Hub don't support HEAD properly)
 	// Limit response size to avoid downloading large files
-	req, err := http.NewRequestWithContext(ctx, "GET", modelURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", v.URL, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -41,19 +193,7 @@ func (mv *ModelValidator) ValidateModelExists(ctx context.Context, modelURL stri
 	req.Header.Set("Range", "bytes=0-1023")
 	req.Header.Set("User-Agent", "Axon-CLI/1.0")
 
-	// Create client that follows redirects
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			// Allow up to 10 redirects
-			if len(via) >= 10 {
-				return fmt.Errorf("stopped after 10 redirects")
-			}
-			return nil
-		},
-	}
-
-	resp, err := client.Do(req)
+	resp, err := v.client.Do(req)
 	if err != nil {
 		// Network error - can't validate, return error so caller can decide
 		return false, fmt.Errorf("network error during validation: %w", err)
@@ -121,8 +261,6 @@ func (mv *ModelValidator) ValidateModelExists(ctx context.Context, modelURL stri
 		return true, nil
 	}
 
-	// Other status codes (401, 403, 500, etc.) - assume might exist
-	// Could be auth required, server error, etc.
-	// Return true to allow adapter to handle it
-	return true, nil
+	// Other status codes (401, 403, 500, etc.) - could be auth required, server error, etc.
+	return false, ErrUndetermined
 }
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// undetermined is a validator that can't tell, counting how often it was asked
+type undetermined struct{ asked int }
+
+func (u *undetermined) Exists(ctx context.Context) (bool, error) {
+	u.asked++
+	return false, ErrUndetermined
+}
+
+func TestModelValidator_API(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/models/org/found":
+			_, _ = w.Write([]byte(`{"id": "org/found"}`))
+		case "/api/models/org/private":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/search":
+			_, _ = w.Write([]byte("<html><head><title>Search models</title></head></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	mv := NewModelValidator()
+	ctx := context.Background()
+
+	tests := []struct {
+		name       string
+		validators []Validator
+		want       bool
+	}{
+		{"found", []Validator{mv.API(server.URL+"/api/models/org/found", nil)}, true},
+		{"missing", []Validator{mv.API(server.URL+"/api/models/org/missing", nil)}, false},
+		// 401 could be private or missing, so the next strategy decides
+		{"falls back", []Validator{mv.API(server.URL+"/api/models/org/private", nil), mv.Page(server.URL + "/search")}, false},
+		{"nothing can tell", []Validator{mv.API(server.URL+"/api/models/org/private", nil)}, true},
+	}
+	for _, tt := range tests {
+		got, err := mv.Validate(ctx, tt.validators...)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Validate() = %t, %v; want %t", tt.name, got, err, tt.want)
+		}
+	}
+
+	// Strategies after one that determined the answer aren't asked
+	last := &undetermined{}
+	if _, err := mv.Validate(ctx, mv.API(server.URL+"/api/models/org/found", nil), last); err != nil || last.asked != 0 {
+		t.Errorf("Validate() asked %d more validators, error %v", last.asked, err)
+	}
+}
+
+func TestModelValidator_Kaggle(t *testing.T) {
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/google/imagenet/resnet_v2_50/classification/5":
+			http.Redirect(w, r, "/models/google/resnet-v2/tensorFlow2/50-classification/2", http.StatusMovedPermanently)
+		case "/google/imagenet/removed/5":
+			http.Redirect(w, r, "/models/google/removed/tensorFlow2/default/1", http.StatusMovedPermanently)
+		case "/google/unknown/1":
+			http.Redirect(w, r, "/models?query=google/unknown/1", http.StatusMovedPermanently)
+		case "/api/v1/models/google/resnet-v2/get":
+			auth = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte(`{"slug": "resnet-v2"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	mv := NewModelValidator()
+	ctx := context.Background()
+	kaggle := func(path, username, key string) Validator {
+		v := mv.Kaggle(server.URL+path, username, key)
+		v.KaggleURL = server.URL
+		return v
+	}
+
+	tests := []struct {
+		name      string
+		validator Validator
+		want      bool
+		wantErr   error
+	}{
+		{"confirmed by the API", kaggle("/google/imagenet/resnet_v2_50/classification/5", "user", "key"), true, nil},
+		{"missing from the API", kaggle("/google/imagenet/removed/5", "user", "key"), false, nil},
+		{"redirected to search", kaggle("/google/unknown/1", "", ""), false, nil},
+		{"not on tfhub.dev", kaggle("/google/gone/1", "", ""), false, nil},
+		{"no credentials", kaggle("/google/imagenet/resnet_v2_50/classification/5", "", ""), false, ErrUndetermined},
+	}
+	for _, tt := range tests {
+		got, err := tt.validator.Exists(ctx)
+		if got != tt.want || err != tt.wantErr {
+			t.Errorf("%s: Exists() = %t, %v; want %t, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+	if auth != "Basic dXNlcjprZXk=" {
+		t.Errorf("Kaggle API Authorization = %q, want basic auth with the credentials", auth)
+	}
+}
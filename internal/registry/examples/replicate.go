@@ -25,6 +25,7 @@ import (
 
 	"github.com/mlOS-foundation/axon/internal/registry/core"
 	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
 )
 
 // ReplicateAdapter implements RepositoryAdapter for Replicate.
@@ -83,8 +84,13 @@ func (r *ReplicateAdapter) GetManifest(ctx context.Context, namespace, name, ver
 	// Construct model URL for validation
 	modelURL := fmt.Sprintf("https://replicate.com/%s/%s", owner, modelName)
 
-	// Validate model exists
-	valid, err := r.validator.ValidateModelExists(ctx, modelURL)
+	// Validate model exists with the API (which needs a token), and else the model page
+	apiURL := fmt.Sprintf("%s/v1/models/%s/%s", r.baseURL, owner, modelName)
+	var headers map[string]string
+	if r.apiToken != "" {
+		headers = map[string]string{"Authorization": "Bearer " + r.apiToken}
+	}
+	valid, err := r.validator.Validate(ctx, r.validator.API(apiURL, headers), r.validator.Page(modelURL))
 	if err != nil {
 		return nil, fmt.Errorf("failed to validate model existence: %w", err)
 	}
@@ -93,7 +99,6 @@ func (r *ReplicateAdapter) GetManifest(ctx context.Context, namespace, name, ver
 	}
 
 	// Fetch model metadata from Replicate API
-	apiURL := fmt.Sprintf("%s/v1/models/%s/%s", r.baseURL, owner, modelName)
 	resp, err := r.httpClient.Get(ctx, apiURL)
 	if err != nil {
 		// If API fails, create basic manifest
@@ -192,7 +197,7 @@ func (r *ReplicateAdapter) DownloadPackage(ctx context.Context, manifest *types.
 		manifest.Distribution.Package.URL, manifest.Metadata.Description)
 
 	// Write metadata to temp file and add to package
-	tempFile, err := os.CreateTemp("", "replicate-metadata-*.json")
+	tempFile, err := os.CreateTemp(utils.TempDir(), "replicate-metadata-*.json")
 	if err != nil {
 		return fmt.Errorf("failed to create temp file: %w", err)
 	}
@@ -210,6 +215,12 @@ func (r *ReplicateAdapter) DownloadPackage(ctx context.Context, manifest *types.
 	if err := builder.AddFile(tempFile.Name(), "metadata.json"); err != nil {
 		return fmt.Errorf("failed to add metadata: %w", err)
 	}
+	builder.SetSource(types.ProvenanceSource{
+		Adapter:    r.Name(),
+		Model:      fmt.Sprintf("%s/%s", manifest.Metadata.Namespace, manifest.Metadata.Name),
+		Repository: manifest.Distribution.Package.URL,
+		Revision:   manifest.Metadata.Version,
+	})
 
 	// Build package
 	if err := builder.Build(destPath); err != nil {
@@ -217,7 +228,7 @@ func (r *ReplicateAdapter) DownloadPackage(ctx context.Context, manifest *types.
 	}
 
 	// Update manifest with checksum
-	if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
+	if err := builder.UpdateManifestWithChecksum(manifest); err != nil {
 		return fmt.Errorf("failed to update manifest checksum: %w", err)
 	}
 
@@ -0,0 +1,354 @@
+// Package external runs repository adapters that live outside the Axon binary.
+//
+// An external adapter is an executable in the adapters directory (~/.axon/adapters/).
+// Axon starts it once per operation, writes a single JSON-RPC 2.0 request line to its
+// stdin and reads newline-delimited JSON-RPC messages from its stdout: any number of
+// "progress" notifications followed by exactly one response. Anything the adapter
+// writes to stderr is passed through to the user.
+//
+// Methods:
+//
+//	describe  {}                                       -> {"name", "protocol_version", "namespaces"}
+//	manifest  {"namespace", "name", "version"}         -> manifest (manifest.yaml fields as JSON)
+//	download  {"manifest", "dest_path"}                -> {} once the .axon package is at dest_path
+//	search    {"query"}                                -> [{"namespace", "name", "version", ...}]
+//
+// During download the adapter may send {"jsonrpc": "2.0", "method": "progress",
+// "params": {"current": <bytes>, "total": <bytes>}}. Adapters that don't support search
+// answer with the JSON-RPC "method not found" error (-32601).
+package external
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// ProtocolVersion is the version of the adapter protocol this package speaks
+const ProtocolVersion = 1
+
+// DescribeTimeout bounds how long discovery waits for an adapter to describe itself
+const DescribeTimeout = 10 * time.Second
+
+// maxMessageSize is the longest message line accepted from an adapter
+const maxMessageSize = 16 << 20
+
+// JSON-RPC error codes with a meaning in the protocol
+const (
+	codeMethodNotFound = -32601
+)
+
+// ErrProtocol is returned (wrapped) when an adapter doesn't follow the protocol
+var ErrProtocol = errors.New("external adapter protocol error")
+
+// Adapter is a core.RepositoryAdapter backed by an external executable
+type Adapter struct {
+	path       string
+	name       string
+	namespaces []string
+}
+
+// Description is what an adapter reports about itself
+type Description struct {
+	Name            string   `json:"name"`
+	ProtocolVersion int      `json:"protocol_version"`
+	Namespaces      []string `json:"namespaces"` // Model namespaces the adapter handles; "*" for any
+}
+
+// New describes the executable at path and returns an adapter for it
+func New(ctx context.Context, path string) (*Adapter, error) {
+	a := &Adapter{path: path}
+
+	ctx, cancel := context.WithTimeout(ctx, DescribeTimeout)
+	defer cancel()
+
+	var desc Description
+	if err := a.call(ctx, "describe", struct{}{}, &desc, nil); err != nil {
+		return nil, err
+	}
+	switch {
+	case desc.Name == "":
+		return nil, fmt.Errorf("%w: %s: describe returned no name", ErrProtocol, path)
+	case desc.ProtocolVersion != ProtocolVersion:
+		return nil, fmt.Errorf("%w: %s: speaks protocol version %d, expected %d", ErrProtocol, path, desc.ProtocolVersion, ProtocolVersion)
+	case len(desc.Namespaces) == 0:
+		return nil, fmt.Errorf("%w: %s: describe returned no namespaces", ErrProtocol, path)
+	}
+	a.name = desc.Name
+	a.namespaces = desc.Namespaces
+	return a, nil
+}
+
+// Discover returns an adapter for every executable in dir, sorted by file name. A
+// missing dir yields no adapters. Executables that fail to describe themselves are
+// skipped and reported in the returned errors.
+func Discover(ctx context.Context, dir string) ([]*Adapter, []error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, []error{fmt.Errorf("failed to read adapters directory: %w", err)}
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+
+	var adapters []*Adapter
+	var errs []error
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if strings.HasPrefix(name, ".") || !isExecutable(path) {
+			continue
+		}
+		adapter, err := New(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		adapters = append(adapters, adapter)
+	}
+	return adapters, errs
+}
+
+// isExecutable reports whether path is a regular file someone may execute
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// Name returns the name the adapter reported
+func (a *Adapter) Name() string {
+	return a.name
+}
+
+// Path returns the adapter executable
+func (a *Adapter) Path() string {
+	return a.path
+}
+
+// Namespaces returns the model namespaces the adapter handles
+func (a *Adapter) Namespaces() []string {
+	return a.namespaces
+}
+
+// CanHandle reports whether namespace is one the adapter described
+func (a *Adapter) CanHandle(namespace, name string) bool {
+	for _, ns := range a.namespaces {
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// GetManifest asks the adapter for a model manifest
+func (a *Adapter) GetManifest(ctx context.Context, namespace, name, version string) (*types.Manifest, error) {
+	params := map[string]string{"namespace": namespace, "name": name, "version": version}
+	var raw json.RawMessage
+	if err := a.call(ctx, "manifest", params, &raw, nil); err != nil {
+		return nil, err
+	}
+
+	// JSON is YAML, so the manifest.yaml field names decode as they do from a file
+	var manifest types.Manifest
+	if err := yaml.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("%w: %s: invalid manifest: %v", ErrProtocol, a.name, err)
+	}
+	if manifest.Metadata.Namespace == "" {
+		manifest.Metadata.Namespace = namespace
+	}
+	if manifest.Metadata.Name == "" {
+		manifest.Metadata.Name = name
+	}
+	if manifest.Metadata.Version == "" {
+		manifest.Metadata.Version = version
+	}
+	return &manifest, nil
+}
+
+// DownloadPackage asks the adapter to write the model package to destPath
+func (a *Adapter) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress core.ProgressCallback) error {
+	encoded, err := manifestJSON(manifest)
+	if err != nil {
+		return err
+	}
+	params := map[string]interface{}{"manifest": encoded, "dest_path": destPath}
+	if err := a.call(ctx, "download", params, nil, progress); err != nil {
+		return err
+	}
+	if _, err := os.Stat(destPath); err != nil {
+		return fmt.Errorf("%w: %s: download succeeded but wrote no package to %s", ErrProtocol, a.name, destPath)
+	}
+
+	// Adapters that can't know the digest up front leave it to Axon
+	if manifest.Distribution.Package.SHA256 == "" {
+		if err := core.UpdateManifestWithChecksum(manifest, destPath); err != nil {
+			return fmt.Errorf("failed to update manifest checksum: %w", err)
+		}
+	}
+	return nil
+}
+
+// Search asks the adapter for models matching query. Adapters without search return
+// no results.
+func (a *Adapter) Search(ctx context.Context, query string) ([]types.SearchResult, error) {
+	var results []types.SearchResult
+	err := a.call(ctx, "search", map[string]string{"query": query}, &results, nil)
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == codeMethodNotFound {
+		return nil, nil
+	}
+	return results, err
+}
+
+// manifestJSON converts a manifest to JSON with its manifest.yaml field names
+func manifestJSON(manifest *types.Manifest) (json.RawMessage, error) {
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	var generic interface{}
+	if err := yaml.Unmarshal(data, &generic); err != nil {
+		return nil, fmt.Errorf("failed to convert manifest: %w", err)
+	}
+	return json.Marshal(generic)
+}
+
+// request is a JSON-RPC request
+type request struct {
+	JSONRPC string      `json:"jsonrpc"`
+	ID      int         `json:"id"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// message is a JSON-RPC response or notification from the adapter
+type message struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error response from an adapter
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// progressParams are the params of a progress notification
+type progressParams struct {
+	Current int64 `json:"current"`
+	Total   int64 `json:"total"`
+}
+
+// call runs the adapter for one request and decodes the result into result (if non-nil)
+func (a *Adapter) call(ctx context.Context, method string, params, result interface{}, progress core.ProgressCallback) error {
+	name := a.name
+	if name == "" {
+		name = a.path
+	}
+
+	line, err := json.Marshal(request{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s request: %w", method, err)
+	}
+
+	cmd := exec.CommandContext(ctx, a.path)
+	cmd.Stdin = bytes.NewReader(append(line, '\n'))
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), fmt.Sprintf("AXON_ADAPTER_PROTOCOL=%d", ProtocolVersion))
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to run adapter %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to run adapter %s: %w", name, err)
+	}
+
+	response, readErr := readResponse(stdout, progress)
+	// Drain so an adapter writing after its response doesn't block on a full pipe
+	_, _ = io.Copy(io.Discard, stdout)
+	waitErr := cmd.Wait()
+
+	switch {
+	case ctx.Err() != nil:
+		return fmt.Errorf("adapter %s: %s: %w", name, method, ctx.Err())
+	case readErr != nil:
+		if waitErr != nil {
+			return fmt.Errorf("adapter %s: %s failed: %v", name, method, waitErr)
+		}
+		return fmt.Errorf("%w: %s: %s: %v", ErrProtocol, name, method, readErr)
+	case response.Error != nil:
+		return fmt.Errorf("adapter %s: %s failed: %w", name, method, response.Error)
+	case waitErr != nil:
+		return fmt.Errorf("adapter %s: %s failed: %v", name, method, waitErr)
+	}
+
+	if result != nil {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("%w: %s: invalid %s result: %v", ErrProtocol, name, method, err)
+		}
+	}
+	return nil
+}
+
+// readResponse reads messages until the response, reporting progress notifications
+func readResponse(r io.Reader, progress core.ProgressCallback) (*message, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var msg message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			return nil, fmt.Errorf("invalid message %q: %v", truncate(string(line), 80), err)
+		}
+		if msg.ID != nil {
+			return &msg, nil
+		}
+		if msg.Method == "progress" && progress != nil {
+			var p progressParams
+			if err := json.Unmarshal(msg.Params, &p); err == nil {
+				progress(p.Current, p.Total)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("no response")
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,143 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// testAdapter is a shell adapter that serves the acme/ namespace. Download requests
+// carry the manifest, so they are matched before manifest requests.
+const testAdapter = `#!/bin/sh
+read -r request
+case "$request" in
+*'"describe"'*)
+	echo '{"jsonrpc":"2.0","id":1,"result":{"name":"acme","protocol_version":1,"namespaces":["acme"]}}' ;;
+*'"download"'*)
+	dest=$(echo "$request" | sed 's/.*"dest_path":"\([^"]*\)".*/\1/')
+	echo '{"jsonrpc":"2.0","method":"progress","params":{"current":5,"total":10}}'
+	echo "log line" >&2
+	printf 'package' > "$dest"
+	echo '{"jsonrpc":"2.0","id":1,"result":{}}' ;;
+*'"method":"manifest"'*)
+	echo '{"jsonrpc":"2.0","id":1,"result":{"apiVersion":"v1","kind":"Model","metadata":{"name":"widget","license":"mit"},"spec":{"format":{"type":"onnx","files":[{"path":"model.onnx","size":1234}]}}}}' ;;
+*)
+	echo '{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}' ;;
+esac
+`
+
+func writeAdapter(t *testing.T, dir, name, script string, mode os.FileMode) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+		t.Fatalf("Failed to write adapter: %v", err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeAdapter(t, dir, "acme", testAdapter, 0755)
+	writeAdapter(t, dir, "README.md", "not an adapter", 0644)
+	writeAdapter(t, dir, "broken", "#!/bin/sh\necho not json\n", 0755)
+	writeAdapter(t, dir, "old", "#!/bin/sh\necho '{\"id\":1,\"result\":{\"name\":\"old\",\"protocol_version\":0,\"namespaces\":[\"old\"]}}'\n", 0755)
+
+	adapters, errs := Discover(context.Background(), dir)
+	if len(adapters) != 1 || adapters[0].Name() != "acme" {
+		t.Fatalf("Discover() adapters = %v, want only acme", adapters)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("Discover() errors = %v, want 2 (broken, old)", errs)
+	}
+	for _, err := range errs {
+		if !errors.Is(err, ErrProtocol) {
+			t.Errorf("Discover() error = %v, want ErrProtocol", err)
+		}
+	}
+
+	if adapters, errs := Discover(context.Background(), filepath.Join(dir, "missing")); adapters != nil || errs != nil {
+		t.Errorf("Discover() of missing dir = %v, %v; want nothing", adapters, errs)
+	}
+}
+
+func TestAdapter(t *testing.T) {
+	dir := t.TempDir()
+	writeAdapter(t, dir, "acme", testAdapter, 0755)
+	ctx := context.Background()
+
+	adapter, err := New(ctx, filepath.Join(dir, "acme"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if !adapter.CanHandle("acme", "widget") || adapter.CanHandle("hf", "widget") {
+		t.Errorf("CanHandle() does not match the described namespaces %v", adapter.Namespaces())
+	}
+
+	manifest, err := adapter.GetManifest(ctx, "acme", "widget", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if manifest.Metadata.Namespace != "acme" || manifest.Metadata.Version != "latest" || manifest.Metadata.License != "mit" {
+		t.Errorf("GetManifest() metadata = %+v", manifest.Metadata)
+	}
+	if len(manifest.Spec.Format.Files) != 1 || manifest.Spec.Format.Files[0].Size != 1234 {
+		t.Errorf("GetManifest() files = %+v, want model.onnx (1234 bytes)", manifest.Spec.Format.Files)
+	}
+
+	dest := filepath.Join(t.TempDir(), "widget.axon")
+	var progress []int64
+	err = adapter.DownloadPackage(ctx, manifest, dest, func(current, total int64) {
+		progress = append(progress, current, total)
+	})
+	if err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+	if data, err := os.ReadFile(dest); err != nil || string(data) != "package" {
+		t.Errorf("package = %q, %v; want the adapter's output", data, err)
+	}
+	if len(progress) != 2 || progress[0] != 5 || progress[1] != 10 {
+		t.Errorf("progress = %v, want [5 10]", progress)
+	}
+	if manifest.Distribution.Package.SHA256 == "" || manifest.Distribution.Package.Size != int64(len("package")) {
+		t.Errorf("DownloadPackage() did not record the package checksum: %+v", manifest.Distribution.Package)
+	}
+
+	// The test adapter doesn't implement search
+	results, err := adapter.Search(ctx, "widget")
+	if err != nil || len(results) != 0 {
+		t.Errorf("Search() = %v, %v; want no results", results, err)
+	}
+}
+
+func TestAdapter_ErrorResponse(t *testing.T) {
+	dir := t.TempDir()
+	writeAdapter(t, dir, "acme", strings.Replace(testAdapter,
+		`*'"method":"manifest"'*)`,
+		`*'"method":"manifest"'*)
+	echo '{"jsonrpc":"2.0","id":1,"error":{"code":1,"message":"model not found: acme/missing"}}' ;;
+*'"unused"'*)`, 1), 0755)
+
+	adapter, err := New(context.Background(), filepath.Join(dir, "acme"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	_, err = adapter.GetManifest(context.Background(), "acme", "missing", "latest")
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || !strings.Contains(err.Error(), "model not found: acme/missing") {
+		t.Errorf("GetManifest() error = %v, want the adapter's error", err)
+	}
+}
+
+func TestManifestJSON(t *testing.T) {
+	manifest := &types.Manifest{Metadata: types.Metadata{Namespace: "acme", Name: "widget"}}
+	data, err := manifestJSON(manifest)
+	if err != nil {
+		t.Fatalf("manifestJSON() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"namespace":"acme"`) {
+		t.Errorf("manifestJSON() = %s, want manifest.yaml field names", data)
+	}
+}
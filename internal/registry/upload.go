@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// UploadPackage uploads the package file at path to the registry as /packages/<fileName>.
+// The registry verifies it against the file's SHA-256, sent with it.
+func (c *Client) UploadPackage(ctx context.Context, fileName, path string) error {
+	digest, err := utils.ComputeSHA256(path)
+	if err != nil {
+		return fmt.Errorf("failed to hash package: %w", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open package: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	stat, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat package: %w", err)
+	}
+
+	target := fmt.Sprintf("%s/packages/%s", strings.TrimRight(c.baseURL, "/"), url.PathEscape(fileName))
+	return c.put(ctx, target, file, stat.Size(), "application/octet-stream", map[string]string{"X-Checksum-Sha256": digest})
+}
+
+// UploadAttestation uploads the attestation of a model version. It must precede the
+// manifest, which publishes the version.
+func (c *Client) UploadAttestation(ctx context.Context, namespace, name, version string, data []byte) error {
+	target := fmt.Sprintf("%s/api/v1/models/%s/%s/%s/attestation.json", strings.TrimRight(c.baseURL, "/"), namespace, name, version)
+	return c.put(ctx, target, bytes.NewReader(data), int64(len(data)), "application/json", nil)
+}
+
+// UploadManifest publishes a model version by uploading its manifest. The package it
+// names must have been uploaded with UploadPackage.
+func (c *Client) UploadManifest(ctx context.Context, namespace, name, version string, data []byte) error {
+	target := fmt.Sprintf("%s/api/v1/models/%s/%s/%s", strings.TrimRight(c.baseURL, "/"), namespace, name, version)
+	return c.put(ctx, target, bytes.NewReader(data), int64(len(data)), "application/x-yaml", nil)
+}
+
+// put uploads body to a registry URL. Uploads can take longer than the request timeout,
+// so only ctx bounds them.
+func (c *Client) put(ctx context.Context, target string, body io.Reader, size int64, contentType string, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	c.authorize(req)
+
+	client := *c.httpClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload to %s failed: %d %s", target, resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
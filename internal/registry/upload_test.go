@@ -0,0 +1,68 @@
+package registry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+func TestClientUpload(t *testing.T) {
+	uploads := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/api/v1/models/nlp/bert/2.0.0" {
+			http.Error(w, "nlp/bert@2.0.0 is already published", http.StatusConflict)
+			return
+		}
+		uploads[r.URL.Path] = string(body) + " " + r.Header.Get("X-Checksum-Sha256")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	packagePath := filepath.Join(t.TempDir(), "nlp-bert-1.0.0.axon")
+	if err := os.WriteFile(packagePath, []byte("package"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	digest := utils.ComputeSHA256Bytes([]byte("package"))
+
+	c := NewClient(server.URL, nil)
+	c.SetToken("secret")
+	ctx := context.Background()
+	if err := c.UploadPackage(ctx, "nlp-bert-1.0.0.axon", packagePath); err != nil {
+		t.Fatalf("UploadPackage() error = %v", err)
+	}
+	if err := c.UploadAttestation(ctx, "nlp", "bert", "1.0.0", []byte("{}")); err != nil {
+		t.Fatalf("UploadAttestation() error = %v", err)
+	}
+	if err := c.UploadManifest(ctx, "nlp", "bert", "1.0.0", []byte("kind: Model")); err != nil {
+		t.Fatalf("UploadManifest() error = %v", err)
+	}
+
+	want := map[string]string{
+		"/packages/nlp-bert-1.0.0.axon":                  "package " + digest,
+		"/api/v1/models/nlp/bert/1.0.0/attestation.json": "{} ",
+		"/api/v1/models/nlp/bert/1.0.0":                  "kind: Model ",
+	}
+	for path, body := range want {
+		if uploads[path] != body {
+			t.Errorf("upload to %s = %q, want %q", path, uploads[path], body)
+		}
+	}
+
+	// The registry's reason for refusing an upload is reported
+	err := c.UploadManifest(ctx, "nlp", "bert", "2.0.0", []byte("kind: Model"))
+	if err == nil || !strings.Contains(err.Error(), "409 nlp/bert@2.0.0 is already published") {
+		t.Errorf("UploadManifest() of a published version error = %v", err)
+	}
+}
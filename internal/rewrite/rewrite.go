@@ -0,0 +1,163 @@
+// Package rewrite maps public model specs onto private mirrors, so organizations that
+// mirror Hugging Face (or any other repository) internally can keep using public specs.
+//
+// A rule matches models by namespace/name, or by a prefix ending in /* (hf/*,
+// hf/meta-llama/*), and sends them either to a mirror URL speaking the protocol of the
+// model's repository (hf/* -> https://hf-mirror.corp) or to another model spec, such as
+// a mirror in an Axon registry (hf/* -> corp/hf/*). Models keep their public spec in the
+// cache either way.
+package rewrite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+// Rule sends the models it matches to a mirror
+type Rule struct {
+	Match string // namespace/name, or a prefix ending in /*
+	To    string // Mirror base URL, or the spec the models are fetched as (with * for the matched rest)
+	Token string // Token for the mirror; the public repository's isn't sent to it
+}
+
+// ParseRule parses the "match -> to" shorthand of a rule
+func ParseRule(s string) (Rule, error) {
+	match, to, ok := strings.Cut(s, "->")
+	if !ok {
+		return Rule{}, fmt.Errorf("invalid rewrite rule %q (expected e.g. hf/* -> https://hf-mirror.example.com)", s)
+	}
+	r := Rule{Match: strings.TrimSpace(match), To: strings.TrimSpace(to)}
+	return r, r.Validate()
+}
+
+// String formats the rule in its shorthand
+func (r Rule) String() string {
+	return r.Match + " -> " + r.To
+}
+
+// IsURL reports whether the rule sends models to a mirror URL rather than another spec
+func (r Rule) IsURL() bool {
+	return strings.Contains(r.To, "://")
+}
+
+// Validate checks that the rule's patterns are well formed
+func (r Rule) Validate() error {
+	wildcard := strings.HasSuffix(r.Match, "*")
+	switch {
+	case r.Match == "" || r.To == "":
+		return fmt.Errorf("rewrite rule %q needs both a match and a target", r.String())
+	case strings.Count(r.Match, "*") > 1 || (wildcard && r.Match != "*" && !strings.HasSuffix(r.Match, "/*")):
+		return fmt.Errorf("rewrite rule %q: only a trailing /* is supported in the match", r.String())
+	case !wildcard && !strings.Contains(r.Match, "/"):
+		return fmt.Errorf("rewrite rule %q: match namespace/name or a prefix ending in /*", r.String())
+	case r.IsURL():
+		return nil
+	case strings.Count(r.To, "*") > 1 || (strings.Contains(r.To, "*") && !wildcard):
+		return fmt.Errorf("rewrite rule %q: the target may use * once, for the rest of a /* match", r.String())
+	case wildcard && !strings.Contains(r.To, "*"):
+		return fmt.Errorf("rewrite rule %q: a /* match needs a * in the target spec", r.String())
+	}
+	return nil
+}
+
+// Target is where a rule sends a model
+type Target struct {
+	Rule      Rule
+	BaseURL   string // The mirror URL, for URL rules
+	Namespace string // The model in the mirror (unchanged for URL rules)
+	Name      string
+}
+
+// Apply returns where the rule sends namespace/name, if it matches
+func (r Rule) Apply(namespace, name string) (Target, bool) {
+	id := namespace + "/" + name
+	var rest string
+	switch {
+	case r.Match == "*":
+		rest = id
+	case strings.HasSuffix(r.Match, "/*"):
+		prefix := strings.TrimSuffix(r.Match, "*")
+		if !strings.HasPrefix(id, prefix) || id == prefix {
+			return Target{}, false
+		}
+		rest = strings.TrimPrefix(id, prefix)
+	case r.Match != id:
+		return Target{}, false
+	}
+
+	if r.IsURL() {
+		return Target{Rule: r, BaseURL: strings.TrimSuffix(r.To, "/"), Namespace: namespace, Name: name}, true
+	}
+	mapped := strings.Replace(r.To, "*", rest, 1)
+	mappedNamespace, mappedName, ok := strings.Cut(mapped, "/")
+	if !ok || mappedNamespace == "" || mappedName == "" {
+		return Target{}, false
+	}
+	return Target{Rule: r, Namespace: mappedNamespace, Name: mappedName}, true
+}
+
+// Find returns where the first matching rule sends namespace/name
+func Find(rules []Rule, namespace, name string) (Target, bool) {
+	for _, r := range rules {
+		if t, ok := r.Apply(namespace, name); ok {
+			return t, true
+		}
+	}
+	return Target{}, false
+}
+
+// remappedAdapter serves a model from the spec a rule maps it to, under its public spec
+type remappedAdapter struct {
+	core.RepositoryAdapter
+	namespace, name string // The public spec
+	target          Target
+}
+
+// NewRemappedAdapter returns an adapter that fetches namespace/name as the target's
+// spec from adapter (the adapter of the target), reporting manifests under the public
+// spec so the model is cached and registered under it
+func NewRemappedAdapter(adapter core.RepositoryAdapter, namespace, name string, target Target) core.RepositoryAdapter {
+	return &remappedAdapter{RepositoryAdapter: adapter, namespace: namespace, name: name, target: target}
+}
+
+// GetManifest returns the manifest of the mirrored model, under the public spec
+func (a *remappedAdapter) GetManifest(ctx context.Context, namespace, name, version string) (*types.Manifest, error) {
+	m, err := a.RepositoryAdapter.GetManifest(ctx, a.target.Namespace, a.target.Name, version)
+	if err != nil {
+		return nil, fmt.Errorf("%s/%s (mirrored as %s/%s): %w", namespace, name, a.target.Namespace, a.target.Name, err)
+	}
+	m.Metadata.Namespace, m.Metadata.Name = namespace, name
+	return m, nil
+}
+
+// DownloadPackage downloads the mirrored model. Adapters find the files by the spec in
+// the manifest, so it's the mirror's while downloading; what the adapter updates in the
+// manifest (checksums, detected format) is kept.
+func (a *remappedAdapter) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress core.ProgressCallback) error {
+	namespace, name := manifest.Metadata.Namespace, manifest.Metadata.Name
+	manifest.Metadata.Namespace, manifest.Metadata.Name = a.target.Namespace, a.target.Name
+	defer func() { manifest.Metadata.Namespace, manifest.Metadata.Name = namespace, name }()
+	return a.RepositoryAdapter.DownloadPackage(ctx, manifest, destPath, progress)
+}
+
+// ListVersions lists the mirrored model's versions, if its adapter can
+func (a *remappedAdapter) ListVersions(ctx context.Context, namespace, name string) ([]string, error) {
+	lister, ok := a.RepositoryAdapter.(core.VersionLister)
+	if !ok {
+		return nil, fmt.Errorf("the %s adapter can't list versions", a.Name())
+	}
+	return lister.ListVersions(ctx, a.target.Namespace, a.target.Name)
+}
+
+// LatestRevision returns the mirrored model's latest revision, if its adapter reports one
+func (a *remappedAdapter) LatestRevision(ctx context.Context, namespace, name string) (string, error) {
+	resolver, ok := a.RepositoryAdapter.(core.RevisionResolver)
+	if !ok {
+		return "", fmt.Errorf("the %s adapter doesn't report revisions", a.Name())
+	}
+	return resolver.LatestRevision(ctx, a.target.Namespace, a.target.Name)
+}
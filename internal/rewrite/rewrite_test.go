@@ -0,0 +1,100 @@
+package rewrite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := ParseRule(" hf/* ->  https://hf-mirror.example.com/ ")
+	if err != nil {
+		t.Fatalf("ParseRule() error = %v", err)
+	}
+	if rule.Match != "hf/*" || rule.To != "https://hf-mirror.example.com/" || !rule.IsURL() {
+		t.Errorf("ParseRule() = %+v", rule)
+	}
+
+	for _, invalid := range []string{
+		"hf/* https://hf-mirror.example.com",
+		"hf/* -> ",
+		"hf/*/bert -> corp/*",
+		"hf* -> corp/*",
+		"hf -> corp/hf",
+		"hf/* -> corp/hf",
+		"hf/bert -> corp/*",
+	} {
+		if _, err := ParseRule(invalid); err == nil {
+			t.Errorf("ParseRule(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestRuleApply(t *testing.T) {
+	rules := []Rule{
+		{Match: "hf/meta-llama/*", To: "corp/llama/*"},
+		{Match: "hf/*", To: "https://hf-mirror.example.com/"},
+		{Match: "pytorch/vision/resnet50", To: "corp/resnet50"},
+	}
+	tests := []struct {
+		namespace, name string
+		want            Target
+		wantOK          bool
+	}{
+		{"hf", "meta-llama/Llama-3-8B", Target{Rule: rules[0], Namespace: "corp", Name: "llama/Llama-3-8B"}, true},
+		{"hf", "bert-base-uncased", Target{Rule: rules[1], BaseURL: "https://hf-mirror.example.com", Namespace: "hf", Name: "bert-base-uncased"}, true},
+		{"pytorch", "vision/resnet50", Target{Rule: rules[2], Namespace: "corp", Name: "resnet50"}, true},
+		{"pytorch", "vision/resnet18", Target{}, false},
+		{"hfx", "bert", Target{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := Find(rules, tt.namespace, tt.name)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("Find(%s/%s) = %+v, %t; want %+v, %t", tt.namespace, tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+// mirror serves manifests and records what was downloaded
+type mirror struct {
+	downloaded string
+}
+
+func (m *mirror) Name() string                          { return "mirror" }
+func (m *mirror) CanHandle(namespace, name string) bool { return namespace == "corp" }
+func (m *mirror) GetManifest(ctx context.Context, namespace, name, version string) (*types.Manifest, error) {
+	return &types.Manifest{Metadata: types.Metadata{Namespace: namespace, Name: name, Version: version}}, nil
+}
+func (m *mirror) DownloadPackage(ctx context.Context, manifest *types.Manifest, destPath string, progress core.ProgressCallback) error {
+	m.downloaded = manifest.Metadata.Namespace + "/" + manifest.Metadata.Name
+	manifest.Distribution.Package.SHA256 = "abc123"
+	return nil
+}
+func (m *mirror) Search(ctx context.Context, query string) ([]types.SearchResult, error) {
+	return nil, nil
+}
+
+func TestRemappedAdapter(t *testing.T) {
+	inner := &mirror{}
+	target, _ := Rule{Match: "hf/*", To: "corp/hf/*"}.Apply("hf", "bert")
+	adapter := NewRemappedAdapter(inner, "hf", "bert", target)
+
+	m, err := adapter.GetManifest(context.Background(), "hf", "bert", "latest")
+	if err != nil {
+		t.Fatalf("GetManifest() error = %v", err)
+	}
+	if m.Metadata.Namespace != "hf" || m.Metadata.Name != "bert" {
+		t.Errorf("manifest of %s/%s, want the public spec", m.Metadata.Namespace, m.Metadata.Name)
+	}
+	if err := adapter.DownloadPackage(context.Background(), m, "bert.axon", nil); err != nil {
+		t.Fatalf("DownloadPackage() error = %v", err)
+	}
+	if inner.downloaded != "corp/hf/bert" {
+		t.Errorf("downloaded %s, want the mirror's spec", inner.downloaded)
+	}
+	if m.Metadata.Namespace != "hf" || m.Distribution.Package.SHA256 != "abc123" {
+		t.Errorf("manifest after download = %+v", m.Metadata)
+	}
+}
@@ -0,0 +1,284 @@
+package signing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Key store errors
+var (
+	ErrKeyNotFound = errors.New("key not found")
+	ErrKeyExists   = errors.New("a different key with this name exists")
+)
+
+// Key types in a key store
+const (
+	KeyTypeSigning = "signing" // A private key, for 'axon publish --sign'
+	KeyTypeTrusted = "trusted" // A public key whose signatures are accepted
+)
+
+// nameHeader is the PEM header naming the keys of an exported keyring
+const nameHeader = "Name"
+
+// KeyStore manages the keys in a directory (~/.axon/keys): private signing keys as
+// <name>.pem, and public keys trusted for verification as trusted/<name>.pub
+type KeyStore struct {
+	dir string
+}
+
+// NewKeyStore returns the key store in dir
+func NewKeyStore(dir string) *KeyStore {
+	return &KeyStore{dir: dir}
+}
+
+// Key is a key in a store
+type Key struct {
+	Name   string
+	Type   string // KeyTypeSigning or KeyTypeTrusted
+	Path   string
+	Public ed25519.PublicKey
+}
+
+// ID returns the key's fingerprint
+func (k Key) ID() string {
+	return KeyID(k.Public)
+}
+
+// SigningKeyPath returns the path of the named signing key
+func (s *KeyStore) SigningKeyPath(name string) string {
+	return filepath.Join(s.dir, name+".pem")
+}
+
+// trustedKeyPath returns the path of the named trusted key
+func (s *KeyStore) trustedKeyPath(name string) string {
+	return filepath.Join(s.dir, "trusted", name+".pub")
+}
+
+// Generate creates a signing key and trusts its public key, so packages signed with it
+// verify on this machine. It won't replace an existing key.
+func (s *KeyStore) Generate(name string) (ed25519.PrivateKey, error) {
+	if err := ValidateKeyName(name); err != nil {
+		return nil, err
+	}
+	key, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := WritePrivateKey(s.SigningKeyPath(name), key); err != nil {
+		return nil, err
+	}
+	if err := s.Trust(name, key.Public().(ed25519.PublicKey)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Trust adds a public key to the trusted keys. Adding the same key again is a no-op;
+// a different key with the same name is refused with ErrKeyExists.
+func (s *KeyStore) Trust(name string, key ed25519.PublicKey) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	path := s.trustedKeyPath(name)
+	if existing, err := readPublicKeyFile(path); err == nil {
+		if existing.Equal(key) {
+			return nil
+		}
+		return fmt.Errorf("%w: %s (key %s)", ErrKeyExists, name, KeyID(existing))
+	}
+
+	data, err := EncodePublicKeyPEM(key, "")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write public key: %w", err)
+	}
+	return nil
+}
+
+// Untrust removes a trusted key
+func (s *KeyStore) Untrust(name string) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(s.trustedKeyPath(name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: no trusted key named %s", ErrKeyNotFound, name)
+		}
+		return fmt.Errorf("failed to remove key: %w", err)
+	}
+	return nil
+}
+
+// RemoveSigningKey deletes a signing key. Packages signed with it can still be verified
+// while its public key is trusted.
+func (s *KeyStore) RemoveSigningKey(name string) error {
+	if err := ValidateKeyName(name); err != nil {
+		return err
+	}
+	if err := os.Remove(s.SigningKeyPath(name)); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w: no signing key named %s", ErrKeyNotFound, name)
+		}
+		return fmt.Errorf("failed to remove key: %w", err)
+	}
+	return nil
+}
+
+// List returns the signing keys, then the trusted keys, each by name
+func (s *KeyStore) List() ([]Key, error) {
+	var keys []Key
+	signingPaths, _ := filepath.Glob(filepath.Join(s.dir, "*.pem"))
+	for _, path := range signingPaths {
+		key, err := LoadPrivateKey(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, Key{Name: strings.TrimSuffix(filepath.Base(path), ".pem"), Type: KeyTypeSigning, Path: path, Public: key.Public().(ed25519.PublicKey)})
+	}
+	trustedPaths, _ := filepath.Glob(filepath.Join(s.dir, "trusted", "*.pub"))
+	for _, path := range trustedPaths {
+		key, err := readPublicKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, Key{Name: strings.TrimSuffix(filepath.Base(path), ".pub"), Type: KeyTypeTrusted, Path: path, Public: key})
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].Type != keys[j].Type {
+			return keys[i].Type == KeyTypeSigning
+		}
+		return keys[i].Name < keys[j].Name
+	})
+	return keys, nil
+}
+
+// TrustedKeys returns the public keys of the trusted keys
+func (s *KeyStore) TrustedKeys() ([]ed25519.PublicKey, error) {
+	keys, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var trusted []ed25519.PublicKey
+	for _, key := range keys {
+		if key.Type == KeyTypeTrusted {
+			trusted = append(trusted, key.Public)
+		}
+	}
+	return trusted, nil
+}
+
+// Find returns the named key: the signing key, or else the trusted key
+func (s *KeyStore) Find(name string) (Key, error) {
+	keys, err := s.List()
+	if err != nil {
+		return Key{}, err
+	}
+	for _, key := range keys {
+		if key.Name == name {
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("%w: %s", ErrKeyNotFound, name)
+}
+
+// ValidateKeyName checks that a key name is safe as a file name
+func ValidateKeyName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") {
+		return fmt.Errorf("invalid key name %q", name)
+	}
+	for _, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == '@') {
+			return fmt.Errorf("invalid key name %q: use letters, digits, '-', '_', '.' and '@'", name)
+		}
+	}
+	return nil
+}
+
+// EncodePublicKeyPEM encodes a public key as a PKIX PEM block, named by a header when
+// name is set
+func EncodePublicKeyPEM(key ed25519.PublicKey, name string) ([]byte, error) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	block := &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	if name != "" {
+		block.Headers = map[string]string{nameHeader: name}
+	}
+	return pem.EncodeToMemory(block), nil
+}
+
+// NamedPublicKey is a public key in a keyring
+type NamedPublicKey struct {
+	Name string // Empty if the keyring doesn't name it
+	Key  ed25519.PublicKey
+}
+
+// EncodeKeyring encodes public keys as concatenated PEM blocks, each with a Name header,
+// for distributing a team's keys in one file
+func EncodeKeyring(keys []NamedPublicKey) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, key := range keys {
+		data, err := EncodePublicKeyPEM(key.Key, key.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseKeyring parses the Ed25519 public keys in PEM data: an exported keyring, or a
+// single public key file such as 'openssl pkey -pubout' writes
+func ParseKeyring(data []byte) ([]NamedPublicKey, error) {
+	var keys []NamedPublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key: %w", err)
+		}
+		key, ok := parsed.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key %s is not an Ed25519 key", block.Headers[nameHeader])
+		}
+		keys = append(keys, NamedPublicKey{Name: block.Headers[nameHeader], Key: key})
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no public keys found")
+	}
+	return keys, nil
+}
+
+// readPublicKeyFile reads a PEM public key file
+func readPublicKeyFile(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := ParseKeyring(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return keys[0].Key, nil
+}
@@ -0,0 +1,109 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+)
+
+func TestKeyStore(t *testing.T) {
+	store := NewKeyStore(t.TempDir())
+
+	key, err := store.Generate("release")
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := store.Generate("release"); err == nil {
+		t.Error("Generate() replaced an existing key")
+	}
+	loaded, err := LoadPrivateKey(store.SigningKeyPath("release"))
+	if err != nil || !loaded.Equal(key) {
+		t.Fatalf("LoadPrivateKey() = %v, %v", loaded, err)
+	}
+
+	teammate, _ := GenerateKey()
+	teammatePublic := teammate.Public().(ed25519.PublicKey)
+	if err := store.Trust("alice", teammatePublic); err != nil {
+		t.Fatalf("Trust() error = %v", err)
+	}
+	if err := store.Trust("alice", teammatePublic); err != nil {
+		t.Errorf("Trust() of the same key again error = %v", err)
+	}
+	other, _ := GenerateKey()
+	if err := store.Trust("alice", other.Public().(ed25519.PublicKey)); !errors.Is(err, ErrKeyExists) {
+		t.Errorf("Trust() of another key under the same name error = %v, want ErrKeyExists", err)
+	}
+
+	keys, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	var listed []string
+	for _, k := range keys {
+		listed = append(listed, k.Type+":"+k.Name)
+	}
+	if want := []string{"signing:release", "trusted:alice", "trusted:release"}; len(listed) != len(want) || listed[0] != want[0] || listed[1] != want[1] || listed[2] != want[2] {
+		t.Errorf("List() = %v, want %v", listed, want)
+	}
+
+	// Generated keys are trusted, so packages signed with them verify locally
+	trusted, err := store.TrustedKeys()
+	if err != nil || len(trusted) != 2 {
+		t.Fatalf("TrustedKeys() = %d keys, %v", len(trusted), err)
+	}
+	found, err := store.Find("alice")
+	if err != nil || !found.Public.Equal(teammatePublic) || found.ID() != KeyID(teammatePublic) {
+		t.Errorf("Find(alice) = %+v, %v", found, err)
+	}
+
+	if err := store.Untrust("alice"); err != nil {
+		t.Fatalf("Untrust() error = %v", err)
+	}
+	if err := store.Untrust("alice"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Untrust() of a removed key error = %v, want ErrKeyNotFound", err)
+	}
+	if err := store.RemoveSigningKey("release"); err != nil {
+		t.Fatalf("RemoveSigningKey() error = %v", err)
+	}
+	if _, err := store.Find("release"); err != nil {
+		t.Errorf("the public key of a removed signing key should stay trusted: %v", err)
+	}
+
+	for _, bad := range []string{"", ".hidden", "../escape", "a/b"} {
+		if err := store.Trust(bad, teammatePublic); err == nil {
+			t.Errorf("Trust(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	first, _ := GenerateKey()
+	second, _ := GenerateKey()
+	keys := []NamedPublicKey{
+		{Name: "alice", Key: first.Public().(ed25519.PublicKey)},
+		{Name: "bob", Key: second.Public().(ed25519.PublicKey)},
+	}
+	data, err := EncodeKeyring(keys)
+	if err != nil {
+		t.Fatalf("EncodeKeyring() error = %v", err)
+	}
+	parsed, err := ParseKeyring(data)
+	if err != nil {
+		t.Fatalf("ParseKeyring() error = %v", err)
+	}
+	if len(parsed) != 2 || parsed[0].Name != "alice" || !parsed[1].Key.Equal(keys[1].Key) {
+		t.Errorf("ParseKeyring() = %+v", parsed)
+	}
+
+	// A plain public key file has no name
+	plain, err := EncodePublicKeyPEM(keys[0].Key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed, err := ParseKeyring(plain); err != nil || len(parsed) != 1 || parsed[0].Name != "" {
+		t.Errorf("ParseKeyring(plain) = %+v, %v", parsed, err)
+	}
+	if _, err := ParseKeyring([]byte("not a key")); err == nil {
+		t.Error("ParseKeyring() of garbage succeeded")
+	}
+}
@@ -0,0 +1,220 @@
+// Package signing signs and verifies Axon packages with Ed25519 keys.
+//
+// A signature covers the model identity and the package SHA256, so it can't be moved to
+// another model or version. Signatures are detached: they are stored in the manifest
+// (distribution.package.signatures) rather than in the package itself. Private keys are
+// PKCS#8 PEM files, so keys made with `openssl genpkey -algorithm ed25519` work too.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+// Algorithm is the signature algorithm recorded in manifests
+const Algorithm = "ed25519"
+
+// PublicKeyPrefix starts an inline public key, e.g. in security.trusted_keys
+const PublicKeyPrefix = "ed25519:"
+
+// payloadVersion identifies the format of the signed payload
+const payloadVersion = "axon-package-signature-v1"
+
+// Verification errors
+var (
+	ErrUnsigned       = errors.New("package is not signed")
+	ErrUntrusted      = errors.New("package is not signed by a trusted key")
+	ErrBadSignature   = errors.New("package signature is invalid")
+	ErrDigestMismatch = errors.New("package does not match its signed digest")
+)
+
+// GenerateKey creates a new signing key
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// WritePrivateKey saves a private key as owner-only PKCS#8 PEM; it won't overwrite a key
+func WritePrivateKey(path string, key ed25519.PrivateKey) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode private key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create private key: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	if err := pem.Encode(f, &pem.Block{Type: "PRIVATE KEY", Bytes: der}); err != nil {
+		return fmt.Errorf("failed to write private key: %w", err)
+	}
+	return nil
+}
+
+// LoadPrivateKey reads a PKCS#8 PEM Ed25519 private key
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("signing key %s is not PEM encoded", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is not an Ed25519 key", path)
+	}
+	return key, nil
+}
+
+// EncodePublicKey formats a public key for security.trusted_keys
+func EncodePublicKey(key ed25519.PublicKey) string {
+	return PublicKeyPrefix + base64.StdEncoding.EncodeToString(key)
+}
+
+// ParsePublicKey parses a trusted key: an inline "ed25519:<base64>" key, or the path to
+// a PEM public key file
+func ParsePublicKey(value string) (ed25519.PublicKey, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, PublicKeyPrefix) {
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, PublicKeyPrefix))
+		if err != nil || len(raw) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid public key %q", value)
+		}
+		return ed25519.PublicKey(raw), nil
+	}
+
+	data, err := os.ReadFile(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read public key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("public key %s is not PEM encoded", value)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", value, err)
+	}
+	key, ok := parsed.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is not an Ed25519 key", value)
+	}
+	return key, nil
+}
+
+// ParsePublicKeys parses a list of trusted keys
+func ParsePublicKeys(values []string) ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0, len(values))
+	for _, value := range values {
+		key, err := ParsePublicKey(value)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// KeyID is a short fingerprint of a public key
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// payload is the message signed for a package
+func payload(m *types.Manifest) []byte {
+	return []byte(fmt.Sprintf("%s\n%s/%s@%s\nsha256:%s\n", payloadVersion,
+		m.Metadata.Namespace, m.Metadata.Name, m.Metadata.Version,
+		strings.ToLower(m.Distribution.Package.SHA256)))
+}
+
+// Sign adds a signature of the manifest's package to the manifest, replacing an earlier
+// signature by the same key
+func Sign(m *types.Manifest, key ed25519.PrivateKey) (*types.PackageSignature, error) {
+	if m.Distribution.Package.SHA256 == "" {
+		return nil, fmt.Errorf("manifest has no package digest to sign")
+	}
+	public, ok := key.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("invalid signing key")
+	}
+	signature := types.PackageSignature{
+		KeyID:     KeyID(public),
+		Algorithm: Algorithm,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload(m))),
+	}
+
+	signatures := []types.PackageSignature{signature}
+	for _, existing := range m.Distribution.Package.Signatures {
+		if existing.KeyID != signature.KeyID {
+			signatures = append(signatures, existing)
+		}
+	}
+	m.Distribution.Package.Signatures = signatures
+	return &signature, nil
+}
+
+// Verify checks the manifest's package signatures against the trusted keys and returns
+// the ID of the key that verified. The package file itself is checked with CheckPackage.
+func Verify(m *types.Manifest, trusted []ed25519.PublicKey) (string, error) {
+	signatures := m.Distribution.Package.Signatures
+	if len(signatures) == 0 {
+		return "", ErrUnsigned
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(trusted))
+	for _, key := range trusted {
+		keys[KeyID(key)] = key
+	}
+
+	message := payload(m)
+	for _, signature := range signatures {
+		key, ok := keys[signature.KeyID]
+		if !ok || signature.Algorithm != Algorithm {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+		if err != nil || !ed25519.Verify(key, message, raw) {
+			return "", fmt.Errorf("%w (key %s)", ErrBadSignature, signature.KeyID)
+		}
+		return signature.KeyID, nil
+	}
+	return "", ErrUntrusted
+}
+
+// CheckPackage checks that a package file matches the digest in the manifest
+func CheckPackage(m *types.Manifest, packagePath string) error {
+	digest, err := utils.ComputeSHA256(packagePath)
+	if err != nil {
+		return fmt.Errorf("failed to hash package: %w", err)
+	}
+	if !strings.EqualFold(digest, m.Distribution.Package.SHA256) {
+		return fmt.Errorf("%w (expected sha256:%s, got sha256:%s)", ErrDigestMismatch, m.Distribution.Package.SHA256, digest)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+	"github.com/mlOS-foundation/axon/pkg/utils"
+)
+
+func testManifest(t *testing.T, packagePath string) *types.Manifest {
+	t.Helper()
+	digest, err := utils.ComputeSHA256(packagePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &types.Manifest{}
+	m.Metadata.Namespace = "nlp"
+	m.Metadata.Name = "bert"
+	m.Metadata.Version = "1.0.0"
+	m.Distribution.Package.SHA256 = digest
+	return m
+}
+
+func writePackage(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bert.axon")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSignVerify(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	public := key.Public().(ed25519.PublicKey)
+	otherPublic := other.Public().(ed25519.PublicKey)
+
+	tests := []struct {
+		name    string
+		tamper  func(m *types.Manifest)
+		trusted []ed25519.PublicKey
+		wantErr error
+	}{
+		{name: "valid", trusted: []ed25519.PublicKey{otherPublic, public}},
+		{name: "untrusted key", trusted: []ed25519.PublicKey{otherPublic}, wantErr: ErrUntrusted},
+		{name: "changed digest", tamper: func(m *types.Manifest) { m.Distribution.Package.SHA256 = "00" + m.Distribution.Package.SHA256[2:] }, trusted: []ed25519.PublicKey{public}, wantErr: ErrBadSignature},
+		{name: "moved to another version", tamper: func(m *types.Manifest) { m.Metadata.Version = "2.0.0" }, trusted: []ed25519.PublicKey{public}, wantErr: ErrBadSignature},
+		{name: "moved to another model", tamper: func(m *types.Manifest) { m.Metadata.Name = "gpt2" }, trusted: []ed25519.PublicKey{public}, wantErr: ErrBadSignature},
+		{name: "unsigned", tamper: func(m *types.Manifest) { m.Distribution.Package.Signatures = nil }, trusted: []ed25519.PublicKey{public}, wantErr: ErrUnsigned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testManifest(t, writePackage(t, "package contents"))
+			signature, err := Sign(m, key)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if signature.KeyID != KeyID(public) || signature.Algorithm != Algorithm {
+				t.Errorf("Sign() = %+v", signature)
+			}
+			if tt.tamper != nil {
+				tt.tamper(m)
+			}
+
+			keyID, err := Verify(m, tt.trusted)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && keyID != KeyID(public) {
+				t.Errorf("Verify() key = %s, want %s", keyID, KeyID(public))
+			}
+		})
+	}
+}
+
+func TestSignReplacesOwnSignature(t *testing.T) {
+	key, _ := GenerateKey()
+	other, _ := GenerateKey()
+	m := testManifest(t, writePackage(t, "package contents"))
+	for _, k := range []ed25519.PrivateKey{key, other, key} {
+		if _, err := Sign(m, k); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := len(m.Distribution.Package.Signatures); got != 2 {
+		t.Errorf("signatures = %d, want 2 (one per key)", got)
+	}
+}
+
+func TestCheckPackage(t *testing.T) {
+	path := writePackage(t, "package contents")
+	m := testManifest(t, path)
+	if err := CheckPackage(m, path); err != nil {
+		t.Fatalf("CheckPackage() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("tampered contents"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckPackage(m, path); !errors.Is(err, ErrDigestMismatch) {
+		t.Errorf("CheckPackage() on tampered package error = %v, want ErrDigestMismatch", err)
+	}
+}
+
+func TestKeyFiles(t *testing.T) {
+	dir := t.TempDir()
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "keys", "signing.pem")
+	if err := WritePrivateKey(path, key); err != nil {
+		t.Fatalf("WritePrivateKey() error = %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("private key mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	if err := WritePrivateKey(path, key); err == nil {
+		t.Error("WritePrivateKey() overwrote an existing key")
+	}
+
+	loaded, err := LoadPrivateKey(path)
+	if err != nil || !loaded.Equal(key) {
+		t.Fatalf("LoadPrivateKey() = %v, %v", loaded, err)
+	}
+
+	public := key.Public().(ed25519.PublicKey)
+	inline, err := ParsePublicKey(EncodePublicKey(public))
+	if err != nil || !inline.Equal(public) {
+		t.Errorf("ParsePublicKey(inline) = %v, %v", inline, err)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pemPath := filepath.Join(dir, "signing.pub")
+	if err := os.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fromFile, err := ParsePublicKey(pemPath)
+	if err != nil || !fromFile.Equal(public) {
+		t.Errorf("ParsePublicKey(file) = %v, %v", fromFile, err)
+	}
+
+	for _, bad := range []string{"ed25519:not-base64!", "ed25519:AAAA", filepath.Join(dir, "missing.pub")} {
+		if _, err := ParsePublicKey(bad); err == nil {
+			t.Errorf("ParsePublicKey(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package telemetry records what Axon does as Prometheus metrics, which 'axon serve'
+// exposes on /metrics, and as OpenTelemetry traces, exported over OTLP/HTTP when
+// AXON_OTEL_ENDPOINT is set. Both speak the wire formats directly, without client
+// libraries.
+package telemetry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Results of operations, as the result label of metrics
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+)
+
+// DurationBuckets are the histogram buckets for operation durations in seconds, from
+// cache hits to conversions of large models
+var DurationBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600, 1800}
+
+// Default is the registry Axon's metrics are recorded in
+var Default = NewRegistry()
+
+// Axon's metrics. Download throughput is
+// rate(axon_download_bytes_total) / rate(axon_download_duration_seconds_sum), and the
+// cache hit rate is the share of hit in axon_cache_lookups_total.
+var (
+	DownloadBytes    = Default.NewCounter("axon_download_bytes_total", "Bytes of model packages downloaded")
+	DownloadDuration = Default.NewHistogram("axon_download_duration_seconds", "Time spent downloading model packages", DurationBuckets)
+	InstallDuration  = Default.NewHistogram("axon_install_duration_seconds", "Time to install a model, by result", DurationBuckets, "result")
+	Conversions      = Default.NewCounter("axon_conversions_total", "ONNX conversions, by result", "result")
+	CacheLookups     = Default.NewCounter("axon_cache_lookups_total", "Installs of models that were already in the cache (hit) or not (miss)", "result")
+)
+
+// Result is the result label of an operation that returned err
+func Result(err error) string {
+	if err != nil {
+		return ResultFailure
+	}
+	return ResultSuccess
+}
+
+// metric is a metric family written in the Prometheus text format
+type metric interface {
+	writeTo(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (r *Registry) add(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// WritePrometheus writes every metric in the Prometheus text format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]metric(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.writeTo(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the metrics for Prometheus to scrape
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WritePrometheus(w)
+	})
+}
+
+// series are the label values of metric samples, keyed by their joined values
+type series struct {
+	labels []string
+	keys   []string
+	values map[string][]string
+}
+
+func newSeries(labels []string) series {
+	return series{labels: labels, values: make(map[string][]string)}
+}
+
+// key returns the key of the label values, adding them if they are new
+func (s *series) key(labelValues []string) string {
+	if len(labelValues) != len(s.labels) {
+		panic(fmt.Sprintf("telemetry: got %d label values for labels %v", len(labelValues), s.labels))
+	}
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+		sort.Strings(s.keys)
+		s.values[key] = append([]string(nil), labelValues...)
+	}
+	return key
+}
+
+// format writes the labels of a sample as {name="value",...}, with extra pairs after them
+func (s *series) format(key string, extra ...string) string {
+	var pairs []string
+	for i, value := range s.values[key] {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", s.labels[i], value))
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extra[i], extra[i+1]))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Counter is a cumulative metric, optionally split by labels
+type Counter struct {
+	name, help string
+
+	mu     sync.Mutex
+	series series
+	counts map[string]float64
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{name: name, help: help, series: newSeries(labels), counts: make(map[string]float64)}
+	r.add(c)
+	return c
+}
+
+// Add adds v to the counter of the label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[c.series.key(labelValues)] += v
+}
+
+// Inc adds one to the counter of the label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Value returns the counter of the label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[strings.Join(labelValues, "\xff")]
+}
+
+func (c *Counter) writeTo(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	if len(c.series.labels) == 0 && len(c.series.keys) == 0 {
+		fmt.Fprintf(w, "%s 0\n", c.name)
+	}
+	for _, key := range c.series.keys {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, c.series.format(key), formatFloat(c.counts[key]))
+	}
+}
+
+// Histogram counts observations in buckets, optionally split by labels
+type Histogram struct {
+	name, help string
+	buckets    []float64
+
+	mu     sync.Mutex
+	series series
+	data   map[string]*histogramData
+}
+
+type histogramData struct {
+	counts []uint64 // Per bucket, not cumulative
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds (ascending)
+// and label names
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, series: newSeries(labels), data: make(map[string]*histogramData)}
+	r.add(h)
+	return h
+}
+
+// Observe records v in the histogram of the label values
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	key := h.series.key(labelValues)
+	d, ok := h.data[key]
+	if !ok {
+		d = &histogramData{counts: make([]uint64, len(h.buckets))}
+		h.data[key] = d
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		d.counts[i]++
+	}
+	d.count++
+	d.sum += v
+}
+
+func (h *Histogram) writeTo(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	for _, key := range h.series.keys {
+		d := h.data[key]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += d.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.series.format(key, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, h.series.format(key, "le", "+Inf"), d.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, h.series.format(key), formatFloat(d.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, h.series.format(key), d.count)
+	}
+}
+
+// formatFloat formats a sample value the way Prometheus does
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,47 @@
+package telemetry
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry_WritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	bytes := r.NewCounter("test_bytes_total", "Bytes")
+	results := r.NewCounter("test_results_total", "Results", "result")
+	duration := r.NewHistogram("test_duration_seconds", "Durations", []float64{1, 10}, "result")
+
+	bytes.Add(1500)
+	results.Inc(ResultSuccess)
+	results.Inc(ResultFailure)
+	results.Inc(ResultSuccess)
+	duration.Observe(0.5, ResultSuccess)
+	duration.Observe(5, ResultSuccess)
+	duration.Observe(60, ResultSuccess)
+
+	var out strings.Builder
+	if err := r.WritePrometheus(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_bytes_total Bytes
+# TYPE test_bytes_total counter
+test_bytes_total 1500
+# HELP test_results_total Results
+# TYPE test_results_total counter
+test_results_total{result="failure"} 1
+test_results_total{result="success"} 2
+# HELP test_duration_seconds Durations
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{result="success",le="1"} 1
+test_duration_seconds_bucket{result="success",le="10"} 2
+test_duration_seconds_bucket{result="success",le="+Inf"} 3
+test_duration_seconds_sum{result="success"} 65.5
+test_duration_seconds_count{result="success"} 3
+`
+	if out.String() != want {
+		t.Errorf("WritePrometheus() =\n%s\nwant\n%s", out.String(), want)
+	}
+	if got := results.Value(ResultSuccess); got != 2 {
+		t.Errorf("Value(success) = %v, want 2", got)
+	}
+}
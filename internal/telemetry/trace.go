@@ -0,0 +1,287 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EndpointEnv is the OTLP/HTTP collector traces are exported to, e.g.
+// http://localhost:4318 (traces go to its /v1/traces)
+const EndpointEnv = "AXON_OTEL_ENDPOINT"
+
+// serviceName is the service.name of Axon's spans
+const serviceName = "axon"
+
+// Attr is a span attribute
+type Attr struct {
+	Key   string
+	Value interface{} // string, int64 or bool
+}
+
+// String returns a string attribute
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns an integer attribute
+func Int(key string, value int64) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Tracer batches finished spans and exports them to an OTLP/HTTP collector as JSON.
+// A trace is exported when its root span ends.
+type Tracer struct {
+	url     string
+	version string
+	onError func(error)
+	client  *http.Client
+
+	mu      sync.Mutex
+	pending []*Span
+	exports sync.WaitGroup
+}
+
+// NewTracer returns a tracer exporting to the OTLP/HTTP collector at endpoint. Export
+// failures are passed to onError, if set.
+func NewTracer(endpoint, version string, onError func(error)) *Tracer {
+	url := strings.TrimSuffix(endpoint, "/")
+	if !strings.HasSuffix(url, "/v1/traces") {
+		url += "/v1/traces"
+	}
+	return &Tracer{url: url, version: version, onError: onError, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+var (
+	tracerMu sync.RWMutex
+	tracer   *Tracer
+)
+
+// SetTracer makes t the tracer of Start; nil disables tracing
+func SetTracer(t *Tracer) {
+	tracerMu.Lock()
+	defer tracerMu.Unlock()
+	tracer = t
+}
+
+func currentTracer() *Tracer {
+	tracerMu.RLock()
+	defer tracerMu.RUnlock()
+	return tracer
+}
+
+// Span is an operation in a trace. A nil span, returned when tracing is off, ignores
+// every call.
+type Span struct {
+	tracer   *Tracer
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	attrs    []Attr
+	start    time.Time
+	end      time.Time
+	err      error
+}
+
+type spanKey struct{}
+
+// Start starts a span as a child of the span in ctx, if any, and returns a context
+// carrying it. It returns a nil span when tracing is off.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	t := currentTracer()
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{tracer: t, name: name, attrs: attrs, start: time.Now()}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		_, _ = rand.Read(span.traceID[:])
+	}
+	_, _ = rand.Read(span.spanID[:])
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttributes adds attributes to the span
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// End finishes the span, as failed if err is not nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.err = err
+	s.tracer.finish(s)
+}
+
+// finish queues a finished span, and exports the queue in the background when a root
+// span ends
+func (t *Tracer) finish(s *Span) {
+	t.mu.Lock()
+	t.pending = append(t.pending, s)
+	if s.parentID != ([8]byte{}) {
+		t.mu.Unlock()
+		return
+	}
+	batch := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	t.exports.Add(1)
+	go func() {
+		defer t.exports.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), t.client.Timeout)
+		defer cancel()
+		t.report(t.export(ctx, batch))
+	}()
+}
+
+// Shutdown exports the spans not exported yet and waits for running exports
+func (t *Tracer) Shutdown(ctx context.Context) {
+	t.mu.Lock()
+	batch := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	if len(batch) > 0 {
+		t.report(t.export(ctx, batch))
+	}
+	t.exports.Wait()
+}
+
+func (t *Tracer) report(err error) {
+	if err != nil && t.onError != nil {
+		t.onError(err)
+	}
+}
+
+// export sends spans to the collector as an OTLP ExportTraceServiceRequest
+func (t *Tracer) export(ctx context.Context, spans []*Span) error {
+	data, err := json.Marshal(t.request(spans))
+	if err != nil {
+		return fmt.Errorf("failed to marshal spans: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("failed to export spans: %s returned %d", t.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// OTLP/JSON encoding of spans (opentelemetry-proto, trace/v1)
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string                 `json:"key"`
+		Value map[string]interface{} `json:"value"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// OTLP span kind and status codes
+const (
+	otlpSpanKindInternal = 1
+	otlpStatusOK         = 1
+	otlpStatusError      = 2
+)
+
+func (t *Tracer) request(spans []*Span) otlpRequest {
+	scope := otlpScopeSpans{Scope: otlpScope{Name: serviceName, Version: t.version}}
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           hex.EncodeToString(s.traceID[:]),
+			SpanID:            hex.EncodeToString(s.spanID[:]),
+			Name:              s.name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+			Status:            otlpStatus{Code: otlpStatusOK},
+		}
+		if s.parentID != ([8]byte{}) {
+			span.ParentSpanID = hex.EncodeToString(s.parentID[:])
+		}
+		for _, attr := range s.attrs {
+			span.Attributes = append(span.Attributes, otlpAttr(attr))
+		}
+		if s.err != nil {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: s.err.Error()}
+		}
+		scope.Spans = append(scope.Spans, span)
+	}
+
+	resource := otlpResource{Attributes: []otlpAttribute{otlpAttr(String("service.name", serviceName))}}
+	if t.version != "" {
+		resource.Attributes = append(resource.Attributes, otlpAttr(String("service.version", t.version)))
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{Resource: resource, ScopeSpans: []otlpScopeSpans{scope}}}}
+}
+
+// otlpAttr encodes an attribute as an OTLP AnyValue; 64-bit integers are strings in JSON
+func otlpAttr(attr Attr) otlpAttribute {
+	var value map[string]interface{}
+	switch v := attr.Value.(type) {
+	case int64:
+		value = map[string]interface{}{"intValue": strconv.FormatInt(v, 10)}
+	case bool:
+		value = map[string]interface{}{"boolValue": v}
+	default:
+		value = map[string]interface{}{"stringValue": fmt.Sprint(v)}
+	}
+	return otlpAttribute{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTracer_Export(t *testing.T) {
+	exported := make(chan otlpRequest, 2)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("exported to %s, want /v1/traces", r.URL.Path)
+		}
+		var req otlpRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("export is not an OTLP request: %v", err)
+		}
+		exported <- req
+	}))
+	defer collector.Close()
+
+	if _, span := Start(context.Background(), "untraced"); span != nil {
+		t.Error("Start() without a tracer returned a span")
+	}
+
+	tracer := NewTracer(collector.URL, "1.0.0", func(err error) { t.Errorf("export error = %v", err) })
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	ctx, install := Start(context.Background(), "install", String("model", "hf/bert@1.0.0"))
+	_, download := Start(ctx, "download")
+	download.SetAttributes(Int("bytes", 1500))
+	download.End(errors.New("connection reset"))
+	install.End(nil)
+	tracer.Shutdown(context.Background())
+
+	req := <-exported
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("export = %+v", req)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 2 || spans[0].Name != "download" || spans[1].Name != "install" {
+		t.Fatalf("spans = %+v, want download and install", spans)
+	}
+	child, root := spans[0], spans[1]
+	if child.TraceID != root.TraceID || child.ParentSpanID != root.SpanID || root.ParentSpanID != "" {
+		t.Errorf("download = %+v, want a child of install %+v", child, root)
+	}
+	if child.Status.Code != otlpStatusError || child.Status.Message != "connection reset" || root.Status.Code != otlpStatusOK {
+		t.Errorf("statuses = %+v, %+v", child.Status, root.Status)
+	}
+	if len(child.Attributes) != 1 || child.Attributes[0].Value["intValue"] != "1500" {
+		t.Errorf("download attributes = %+v", child.Attributes)
+	}
+	if len(exported) != 0 {
+		t.Error("spans were exported more than once")
+	}
+}
@@ -0,0 +1,547 @@
+// Package tokenizer provides a minimal pure-Go reader for Hugging Face tokenizer.json files.
+// It implements enough of the WordPiece, BPE, Unigram and WordLevel models to encode and decode
+// plain text, which is used to catch corrupt or truncated tokenizer downloads at install
+// time instead of at inference time in MLOS Core.
+package tokenizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Model types found in tokenizer.json
+const (
+	ModelWordPiece = "WordPiece"
+	ModelBPE       = "BPE"
+	ModelUnigram   = "Unigram"
+	ModelWordLevel = "WordLevel"
+)
+
+// metaspace is the SentencePiece word boundary marker
+const metaspace = "▁"
+
+// pre-tokenization modes
+const (
+	splitWhitespace = iota // BERT-style whitespace and punctuation split
+	splitByteLevel         // GPT-2 byte-level BPE
+	splitMetaspace         // SentencePiece "▁" word boundaries
+)
+
+// Tokenizer is a parsed tokenizer.json
+type Tokenizer struct {
+	Type string // WordPiece, BPE, Unigram or WordLevel
+
+	vocab    map[string]int
+	idToken  map[int]string
+	scores   map[string]float64 // Unigram piece scores
+	ranks    map[string]int     // BPE merge ranks, keyed by "left right"
+	unkToken string
+
+	lowercase      bool
+	split          int
+	subwordPrefix  string // WordPiece continuation prefix, e.g. "##"
+	endOfWord      string // BPE end-of-word suffix, e.g. "</w>"
+	byteFallback   bool
+	maxPieceLength int
+}
+
+// tokenizerFile mirrors the parts of tokenizer.json this package reads
+type tokenizerFile struct {
+	AddedTokens []struct {
+		ID      int    `json:"id"`
+		Content string `json:"content"`
+	} `json:"added_tokens"`
+	Normalizer   json.RawMessage `json:"normalizer"`
+	PreTokenizer json.RawMessage `json:"pre_tokenizer"`
+	Model        struct {
+		Type                    string          `json:"type"`
+		Vocab                   json.RawMessage `json:"vocab"`
+		Merges                  json.RawMessage `json:"merges"`
+		UnkToken                *string         `json:"unk_token"`
+		UnkID                   *int            `json:"unk_id"`
+		ContinuingSubwordPrefix *string         `json:"continuing_subword_prefix"`
+		EndOfWordSuffix         *string         `json:"end_of_word_suffix"`
+		ByteFallback            bool            `json:"byte_fallback"`
+	} `json:"model"`
+}
+
+// LoadFile reads and parses a tokenizer.json file
+func LoadFile(path string) (*Tokenizer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tokenizer: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses the contents of a tokenizer.json file
+func Parse(data []byte) (*Tokenizer, error) {
+	var file tokenizerFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse tokenizer.json (truncated or corrupt?): %w", err)
+	}
+
+	t := &Tokenizer{
+		Type:    file.Model.Type,
+		vocab:   make(map[string]int),
+		idToken: make(map[int]string),
+	}
+	if t.Type == "" {
+		t.Type = inferModelType(file.Model.Vocab, file.Model.Merges)
+	}
+
+	switch t.Type {
+	case ModelWordPiece, ModelBPE, ModelWordLevel:
+		if err := json.Unmarshal(file.Model.Vocab, &t.vocab); err != nil {
+			return nil, fmt.Errorf("failed to parse %s vocab: %w", t.Type, err)
+		}
+		for token, id := range t.vocab {
+			t.idToken[id] = token
+		}
+	case ModelUnigram:
+		if err := t.parseUnigramVocab(file.Model.Vocab); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported tokenizer model type: %q", t.Type)
+	}
+	if len(t.vocab) == 0 {
+		return nil, fmt.Errorf("tokenizer vocabulary is empty")
+	}
+
+	if t.Type == ModelBPE {
+		if err := t.parseMerges(file.Model.Merges); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, added := range file.AddedTokens {
+		t.vocab[added.Content] = added.ID
+		t.idToken[added.ID] = added.Content
+	}
+
+	if file.Model.UnkToken != nil {
+		t.unkToken = *file.Model.UnkToken
+	} else if file.Model.UnkID != nil {
+		t.unkToken = t.idToken[*file.Model.UnkID]
+	}
+	t.subwordPrefix = "##"
+	if file.Model.ContinuingSubwordPrefix != nil {
+		t.subwordPrefix = *file.Model.ContinuingSubwordPrefix
+	}
+	if file.Model.EndOfWordSuffix != nil {
+		t.endOfWord = *file.Model.EndOfWordSuffix
+	}
+	t.byteFallback = file.Model.ByteFallback
+
+	normalizer := string(file.Normalizer)
+	preTokenizer := string(file.PreTokenizer)
+	t.lowercase = strings.Contains(normalizer, `"Lowercase"`) || strings.Contains(normalizer, `"lowercase":true`) || strings.Contains(normalizer, `"lowercase": true`)
+	switch {
+	case strings.Contains(preTokenizer, `"ByteLevel"`):
+		t.split = splitByteLevel
+	case strings.Contains(preTokenizer, `"Metaspace"`) || strings.Contains(normalizer, metaspace) || t.Type == ModelUnigram:
+		t.split = splitMetaspace
+	default:
+		t.split = splitWhitespace
+	}
+
+	for token := range t.vocab {
+		if n := len([]rune(token)); n > t.maxPieceLength {
+			t.maxPieceLength = n
+		}
+	}
+
+	return t, nil
+}
+
+// inferModelType guesses the model type of older tokenizer.json files without a "type" field
+func inferModelType(vocab, merges json.RawMessage) string {
+	switch {
+	case strings.HasPrefix(strings.TrimSpace(string(vocab)), "["):
+		return ModelUnigram
+	case len(merges) > 0 && string(merges) != "null":
+		return ModelBPE
+	default:
+		return ModelWordPiece
+	}
+}
+
+// parseUnigramVocab reads a Unigram vocab: a list of [piece, score] pairs whose index is the id
+func (t *Tokenizer) parseUnigramVocab(raw json.RawMessage) error {
+	var entries [][]interface{}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return fmt.Errorf("failed to parse Unigram vocab: %w", err)
+	}
+
+	t.scores = make(map[string]float64, len(entries))
+	for id, entry := range entries {
+		if len(entry) != 2 {
+			return fmt.Errorf("invalid Unigram vocab entry %d", id)
+		}
+		piece, ok := entry[0].(string)
+		score, ok2 := entry[1].(float64)
+		if !ok || !ok2 {
+			return fmt.Errorf("invalid Unigram vocab entry %d", id)
+		}
+		t.vocab[piece] = id
+		t.idToken[id] = piece
+		t.scores[piece] = score
+	}
+	return nil
+}
+
+// parseMerges reads BPE merges, stored either as "left right" strings or [left, right] pairs
+func (t *Tokenizer) parseMerges(raw json.RawMessage) error {
+	var merges []json.RawMessage
+	if err := json.Unmarshal(raw, &merges); err != nil {
+		return fmt.Errorf("failed to parse BPE merges: %w", err)
+	}
+
+	t.ranks = make(map[string]int, len(merges))
+	for rank, merge := range merges {
+		var pair string
+		var parts []string
+		if err := json.Unmarshal(merge, &pair); err == nil {
+			parts = strings.SplitN(pair, " ", 2)
+		} else if err := json.Unmarshal(merge, &parts); err != nil {
+			return fmt.Errorf("invalid BPE merge %d: %w", rank, err)
+		}
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid BPE merge %d", rank)
+		}
+		key := parts[0] + " " + parts[1]
+		if _, exists := t.ranks[key]; !exists {
+			t.ranks[key] = rank
+		}
+	}
+	return nil
+}
+
+// VocabSize returns the number of tokens, including added tokens
+func (t *Tokenizer) VocabSize() int {
+	return len(t.idToken)
+}
+
+// UnknownToken returns the token used for text the vocabulary can't represent ("" if none)
+func (t *Tokenizer) UnknownToken() string {
+	return t.unkToken
+}
+
+// Lowercases reports whether the tokenizer lowercases its input
+func (t *Tokenizer) Lowercases() bool {
+	return t.lowercase
+}
+
+// Encode converts text to token ids
+func (t *Tokenizer) Encode(text string) ([]int, error) {
+	if t.lowercase {
+		text = strings.ToLower(text)
+	}
+
+	var ids []int
+	for _, word := range t.preTokenize(text) {
+		for _, token := range t.tokenizeWord(word) {
+			id, ok := t.vocab[token]
+			if !ok {
+				return nil, fmt.Errorf("token %q is not in the vocabulary", token)
+			}
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Decode converts token ids back to text
+func (t *Tokenizer) Decode(ids []int) (string, error) {
+	var b strings.Builder
+	var bytes []byte
+	for i, id := range ids {
+		token, ok := t.idToken[id]
+		if !ok {
+			return "", fmt.Errorf("token id %d is out of range", id)
+		}
+
+		switch {
+		case t.split == splitByteLevel:
+			b.WriteString(token)
+		case t.Type == ModelWordPiece || t.Type == ModelWordLevel:
+			if strings.HasPrefix(token, t.subwordPrefix) {
+				b.WriteString(strings.TrimPrefix(token, t.subwordPrefix))
+			} else {
+				if i > 0 {
+					b.WriteString(" ")
+				}
+				b.WriteString(token)
+			}
+		default:
+			if value, ok := byteFallbackValue(token); ok {
+				bytes = append(bytes, value)
+				continue
+			}
+			if len(bytes) > 0 {
+				b.Write(bytes)
+				bytes = nil
+			}
+			b.WriteString(token)
+		}
+	}
+	b.Write(bytes)
+
+	text := b.String()
+	switch {
+	case t.split == splitByteLevel:
+		return decodeByteLevel(text), nil
+	case t.split == splitMetaspace:
+		return strings.TrimPrefix(strings.ReplaceAll(text, metaspace, " "), " "), nil
+	case t.endOfWord != "":
+		return strings.TrimSpace(strings.ReplaceAll(text, t.endOfWord, " ")), nil
+	}
+	return text, nil
+}
+
+// preTokenize splits text into the words the model tokenizes independently
+func (t *Tokenizer) preTokenize(text string) []string {
+	switch t.split {
+	case splitByteLevel:
+		var words []string
+		for i, field := range strings.Split(text, " ") {
+			if i > 0 {
+				field = " " + field
+			}
+			if field != "" {
+				words = append(words, encodeByteLevel(field))
+			}
+		}
+		return words
+	case splitMetaspace:
+		var words []string
+		for _, field := range strings.Fields(text) {
+			words = append(words, metaspace+field)
+		}
+		return words
+	default:
+		var words []string
+		for _, field := range strings.Fields(text) {
+			start := 0
+			runes := []rune(field)
+			for i, r := range runes {
+				if unicode.IsPunct(r) || unicode.IsSymbol(r) {
+					if i > start {
+						words = append(words, string(runes[start:i]))
+					}
+					words = append(words, string(r))
+					start = i + 1
+				}
+			}
+			if start < len(runes) {
+				words = append(words, string(runes[start:]))
+			}
+		}
+		return words
+	}
+}
+
+// tokenizeWord splits a single pre-tokenized word into vocabulary tokens
+func (t *Tokenizer) tokenizeWord(word string) []string {
+	if _, ok := t.vocab[word]; ok && t.Type != ModelBPE {
+		return []string{word}
+	}
+	switch t.Type {
+	case ModelWordLevel:
+		return []string{t.unkToken}
+	case ModelWordPiece:
+		return t.wordPiece(word)
+	case ModelUnigram:
+		return t.unigram(word)
+	default:
+		return t.bpe(word)
+	}
+}
+
+// wordPiece applies greedy longest-match-first WordPiece tokenization
+func (t *Tokenizer) wordPiece(word string) []string {
+	runes := []rune(word)
+	var tokens []string
+	for start := 0; start < len(runes); {
+		end := len(runes)
+		var match string
+		for ; end > start; end-- {
+			candidate := string(runes[start:end])
+			if start > 0 {
+				candidate = t.subwordPrefix + candidate
+			}
+			if _, ok := t.vocab[candidate]; ok {
+				match = candidate
+				break
+			}
+		}
+		if match == "" {
+			return []string{t.unkToken}
+		}
+		tokens = append(tokens, match)
+		start = end
+	}
+	return tokens
+}
+
+// bpe applies the BPE merges to a word, lowest rank first
+func (t *Tokenizer) bpe(word string) []string {
+	var symbols []string
+	for _, r := range word {
+		symbols = append(symbols, string(r))
+	}
+	if t.endOfWord != "" && len(symbols) > 0 {
+		symbols[len(symbols)-1] += t.endOfWord
+	}
+
+	for len(symbols) > 1 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i < len(symbols)-1; i++ {
+			if rank, ok := t.ranks[symbols[i]+" "+symbols[i+1]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		symbols[best] += symbols[best+1]
+		symbols = append(symbols[:best+1], symbols[best+2:]...)
+	}
+
+	var tokens []string
+	for _, symbol := range symbols {
+		if _, ok := t.vocab[symbol]; ok {
+			tokens = append(tokens, symbol)
+		} else if t.byteFallback {
+			for _, b := range []byte(symbol) {
+				tokens = append(tokens, fmt.Sprintf("<0x%02X>", b))
+			}
+		} else if t.unkToken != "" {
+			tokens = append(tokens, t.unkToken)
+		} else {
+			tokens = append(tokens, symbol)
+		}
+	}
+	return tokens
+}
+
+// unigram finds the highest-scoring segmentation of a word (Viterbi)
+func (t *Tokenizer) unigram(word string) []string {
+	runes := []rune(word)
+	n := len(runes)
+	best := make([]float64, n+1)
+	prev := make([]int, n+1)
+	for i := 1; i <= n; i++ {
+		best[i] = math.Inf(-1)
+	}
+
+	const unkPenalty = -100.0
+	for end := 1; end <= n; end++ {
+		for start := max(0, end-t.maxPieceLength); start < end; start++ {
+			if math.IsInf(best[start], -1) {
+				continue
+			}
+			piece := string(runes[start:end])
+			score, ok := t.scores[piece]
+			if !ok {
+				if end-start != 1 {
+					continue
+				}
+				score = unkPenalty
+			}
+			if best[start]+score > best[end] {
+				best[end] = best[start] + score
+				prev[end] = start
+			}
+		}
+	}
+
+	var tokens []string
+	for end := n; end > 0; end = prev[end] {
+		piece := string(runes[prev[end]:end])
+		if _, ok := t.scores[piece]; !ok {
+			if t.byteFallback {
+				var fallback []string
+				for _, b := range []byte(piece) {
+					fallback = append(fallback, fmt.Sprintf("<0x%02X>", b))
+				}
+				tokens = append(fallback, tokens...)
+				continue
+			}
+			piece = t.unkToken
+		}
+		tokens = append([]string{piece}, tokens...)
+	}
+	return tokens
+}
+
+// byteFallbackValue parses SentencePiece byte tokens such as <0x0A>
+func byteFallbackValue(token string) (byte, bool) {
+	var value byte
+	if len(token) != 6 || !strings.HasPrefix(token, "<0x") || !strings.HasSuffix(token, ">") {
+		return 0, false
+	}
+	if _, err := fmt.Sscanf(token, "<0x%02X>", &value); err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// byteEncoder is the GPT-2 mapping from bytes to printable unicode characters
+var byteEncoder, byteDecoder = buildByteLevelTables()
+
+func buildByteLevelTables() (map[byte]rune, map[rune]byte) {
+	var printable []int
+	for _, r := range [][2]int{{'!', '~'}, {'¡', '¬'}, {'®', 'ÿ'}} {
+		for b := r[0]; b <= r[1]; b++ {
+			printable = append(printable, b)
+		}
+	}
+	sort.Ints(printable)
+
+	encoder := make(map[byte]rune, 256)
+	decoder := make(map[rune]byte, 256)
+	isPrintable := make(map[int]bool, len(printable))
+	for _, b := range printable {
+		isPrintable[b] = true
+	}
+	next := 0
+	for b := 0; b < 256; b++ {
+		r := rune(b)
+		if !isPrintable[b] {
+			r = rune(256 + next)
+			next++
+		}
+		encoder[byte(b)] = r
+		decoder[r] = byte(b)
+	}
+	return encoder, decoder
+}
+
+// encodeByteLevel maps the bytes of s to their byte-level BPE characters
+func encodeByteLevel(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		b.WriteRune(byteEncoder[c])
+	}
+	return b.String()
+}
+
+// decodeByteLevel reverses encodeByteLevel
+func decodeByteLevel(s string) string {
+	var bytes []byte
+	for _, r := range s {
+		if b, ok := byteDecoder[r]; ok {
+			bytes = append(bytes, b)
+		} else {
+			bytes = append(bytes, string(r)...)
+		}
+	}
+	return string(bytes)
+}
@@ -0,0 +1,159 @@
+package tokenizer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// wordPieceJSON builds a BERT-style uncased tokenizer.json with the given vocabulary
+func wordPieceJSON(t *testing.T, tokens []string) []byte {
+	t.Helper()
+	vocab := map[string]int{}
+	for i, token := range tokens {
+		vocab[token] = i
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"normalizer":    map[string]interface{}{"type": "BertNormalizer", "lowercase": true},
+		"pre_tokenizer": map[string]interface{}{"type": "BertPreTokenizer"},
+		"model": map[string]interface{}{
+			"type":                      "WordPiece",
+			"unk_token":                 "[UNK]",
+			"continuing_subword_prefix": "##",
+			"vocab":                     vocab,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+var bertTokens = []string{"[UNK]", "[CLS]", "hello", "world", "!", "ax", "##on", "checks", "token", "##izer", "##s", ",", "123", "times", "."}
+
+// byteLevelBPEJSON builds a GPT-2 style tokenizer.json covering every byte plus a few merges
+func byteLevelBPEJSON(t *testing.T) []byte {
+	t.Helper()
+	vocab := map[string]int{}
+	for b := 0; b < 256; b++ {
+		vocab[string(byteEncoder[byte(b)])] = len(vocab)
+	}
+	merges := []string{"H e", "He l", "Hel l", "Hell o", "Ġ w", "Ġw o", "Ġwo r", "Ġwor l", "Ġworl d"}
+	for _, merge := range merges {
+		vocab[strings.ReplaceAll(merge, " ", "")] = len(vocab)
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"pre_tokenizer": map[string]interface{}{"type": "ByteLevel", "add_prefix_space": false},
+		"model":         map[string]interface{}{"type": "BPE", "vocab": vocab, "merges": merges},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// unigramJSON builds a SentencePiece-style Unigram tokenizer.json
+func unigramJSON(t *testing.T) []byte {
+	t.Helper()
+	pieces := []string{"<unk>", "▁Hello", "▁world", "!", "▁Axon", "▁checks", "▁token", "izers", ",", "▁123", "▁times", "."}
+	vocab := [][]interface{}{}
+	for i, piece := range pieces {
+		vocab = append(vocab, []interface{}{piece, -float64(i)})
+	}
+	data, err := json.Marshal(map[string]interface{}{
+		"pre_tokenizer": map[string]interface{}{"type": "Metaspace", "replacement": "▁"},
+		"model":         map[string]interface{}{"type": "Unigram", "unk_id": 0, "vocab": vocab},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestTokenizer_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		wantType string
+	}{
+		{"wordpiece", wordPieceJSON(t, bertTokens), ModelWordPiece},
+		{"byte-level bpe", byteLevelBPEJSON(t), ModelBPE},
+		{"unigram", unigramJSON(t), ModelUnigram},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tok, err := Parse(tt.data)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if tok.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", tok.Type, tt.wantType)
+			}
+			if err := tok.RoundTrip(SampleText); err != nil {
+				t.Errorf("RoundTrip() error = %v", err)
+			}
+		})
+	}
+
+	tok, err := Parse(wordPieceJSON(t, bertTokens[:8]))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if err := tok.RoundTrip(SampleText); err == nil || !strings.Contains(err.Error(), "unknown tokens") {
+		t.Errorf("RoundTrip() with an incomplete vocabulary error = %v, want unknown tokens", err)
+	}
+}
+
+func TestTokenizer_ByteLevelMerges(t *testing.T) {
+	tok, err := Parse(byteLevelBPEJSON(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := tok.Encode("Hello world")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 2 {
+		t.Errorf("Encode(\"Hello world\") = %v, want 2 merged tokens", ids)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	valid := wordPieceJSON(t, bertTokens)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{"valid", valid, ""},
+		{"truncated", valid[:len(valid)/2], "truncated"},
+		// Vocabularies that don't cover English text are valid
+		{"incomplete vocab", wordPieceJSON(t, bertTokens[:8]), ""},
+		{"empty vocab", []byte(`{"model": {"type": "WordPiece", "vocab": {}}}`), "empty"},
+		{"unsupported model", []byte(`{"model": {"type": "CharLevel", "vocab": {"a": 0}}}`), "unsupported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Validate(path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
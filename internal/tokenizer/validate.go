@@ -0,0 +1,63 @@
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// FileName is the Hugging Face fast tokenizer file name
+const FileName = "tokenizer.json"
+
+// SampleText is round-tripped through the tokenizer to spot incomplete vocabularies.
+// It sticks to ASCII words, punctuation and digits that English vocabularies cover.
+const SampleText = "Hello world! Axon checks tokenizers, 123 times."
+
+// Validate loads a tokenizer.json and fails on truncated, corrupt or structurally
+// invalid files. It doesn't round-trip text: vocabularies for other languages or
+// domains (e.g. DNA) legitimately can't encode SampleText, so callers run RoundTrip
+// separately and treat its failure as a warning.
+func Validate(path string) (*Tokenizer, error) {
+	return LoadFile(path)
+}
+
+// RoundTrip encodes and decodes text and checks the result matches, ignoring
+// whitespace and case (tokenizers may lowercase and split off punctuation)
+func (t *Tokenizer) RoundTrip(text string) error {
+	ids, err := t.Encode(text)
+	if err != nil {
+		return fmt.Errorf("failed to encode sample text: %w", err)
+	}
+	if len(ids) == 0 {
+		return fmt.Errorf("sample text encoded to no tokens")
+	}
+
+	if t.unkToken != "" {
+		if unkID, ok := t.vocab[t.unkToken]; ok {
+			for _, id := range ids {
+				if id == unkID {
+					return fmt.Errorf("sample text contains unknown tokens (%s); vocabulary looks incomplete", t.unkToken)
+				}
+			}
+		}
+	}
+
+	decoded, err := t.Decode(ids)
+	if err != nil {
+		return fmt.Errorf("failed to decode sample tokens: %w", err)
+	}
+	if !strings.EqualFold(stripSpace(decoded), stripSpace(text)) {
+		return fmt.Errorf("round trip mismatch: encoded %q, decoded %q", text, decoded)
+	}
+	return nil
+}
+
+// stripSpace removes all whitespace from s
+func stripSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,83 @@
+package spec
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ModelRef identifies a model version, and is the one place its ID is turned into paths.
+// Multi-part names nest in directory trees such as the cache (PathSegments:
+// hf/microsoft/resnet-50/latest), and are flattened into one path component for temp
+// files, packages and archive entries (FileName: hf-microsoft_resnet-50-latest.axon).
+// Characters that aren't safe in file names on every platform are replaced, so the
+// encodings don't always reverse: the cache records each model's ref in its metadata.
+type ModelRef struct {
+	Namespace string
+	Name      string // May have several /-separated segments
+	Version   string
+}
+
+// NewModelRef returns the ref of a model version
+func NewModelRef(namespace, name, version string) ModelRef {
+	return ModelRef{Namespace: namespace, Name: name, Version: version}
+}
+
+// Ref returns the model version the spec names
+func (s Spec) Ref() ModelRef {
+	return NewModelRef(s.Namespace, s.Name, s.Version)
+}
+
+// String formats the ref as namespace/name@version
+func (r ModelRef) String() string {
+	return fmt.Sprintf("%s/%s@%s", r.Namespace, r.Name, r.Version)
+}
+
+// PathSegments returns the directories of the model version in a tree: the namespace,
+// each segment of the name, and the version. None is empty, . or .., or contains a
+// separator, so the path stays inside the tree whatever the ref holds.
+func (r ModelRef) PathSegments() []string {
+	segments := []string{PathSegment(r.Namespace)}
+	for _, segment := range strings.FieldsFunc(r.Name, isSeparator) {
+		segments = append(segments, PathSegment(segment))
+	}
+	if len(segments) == 1 {
+		segments = append(segments, PathSegment(""))
+	}
+	return append(segments, PathSegment(r.Version))
+}
+
+// Path returns the directory of the model version under root
+func (r ModelRef) Path(root string) string {
+	return filepath.Join(append([]string{root}, r.PathSegments()...)...)
+}
+
+// FileName returns a single path component naming the model version, with the given
+// extension: namespace-name-version, with separators in each replaced by underscores
+func (r ModelRef) FileName(ext string) string {
+	return fmt.Sprintf("%s-%s-%s%s", fileNamePart(r.Namespace), fileNamePart(r.Name), fileNamePart(r.Version), ext)
+}
+
+// PathSegment encodes one namespace, name or version segment as a directory name
+func PathSegment(segment string) string {
+	safe := fileNamePart(segment)
+	if safe == "" || safe == "." || safe == ".." {
+		return "_" + safe
+	}
+	return safe
+}
+
+// fileNamePart replaces the characters of s that aren't safe in a file name with
+// underscores
+func fileNamePart(s string) string {
+	return strings.Map(func(c rune) rune {
+		if isNameChar(c) || c == '+' {
+			return c
+		}
+		return '_'
+	}, s)
+}
+
+func isSeparator(c rune) bool {
+	return c == '/' || c == '\\'
+}
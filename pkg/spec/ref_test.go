@@ -0,0 +1,72 @@
+package spec
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModelRef_Paths(t *testing.T) {
+	tests := []struct {
+		name         string
+		ref          ModelRef
+		wantSegments []string
+		wantFileName string
+	}{
+		{
+			name:         "simple",
+			ref:          NewModelRef("hf", "bert-base-uncased", "latest"),
+			wantSegments: []string{"hf", "bert-base-uncased", "latest"},
+			wantFileName: "hf-bert-base-uncased-latest.axon",
+		},
+		{
+			name:         "multi-part name",
+			ref:          NewModelRef("hf", "microsoft/resnet-50", "1.0.0+cpu"),
+			wantSegments: []string{"hf", "microsoft", "resnet-50", "1.0.0+cpu"},
+			wantFileName: "hf-microsoft_resnet-50-1.0.0+cpu.axon",
+		},
+		{
+			name:         "traversal",
+			ref:          NewModelRef("..", "a/../../b", "."),
+			wantSegments: []string{"_..", "a", "_..", "_..", "b", "_."},
+			wantFileName: "..-a_.._.._b-..axon",
+		},
+		{
+			name:         "unsafe characters",
+			ref:          NewModelRef("hf", `org\my model`, "sha256:abcd"),
+			wantSegments: []string{"hf", "org", "my_model", "sha256_abcd"},
+			wantFileName: "hf-org_my_model-sha256_abcd.axon",
+		},
+		{
+			name:         "empty",
+			ref:          NewModelRef("", "", ""),
+			wantSegments: []string{"_", "_", "_"},
+			wantFileName: "--.axon",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ref.PathSegments(); !reflect.DeepEqual(got, tt.wantSegments) {
+				t.Errorf("PathSegments() = %q, want %q", got, tt.wantSegments)
+			}
+			root := filepath.Join("cache", "models")
+			if got, want := tt.ref.Path(root), filepath.Join(append([]string{root}, tt.wantSegments...)...); got != want {
+				t.Errorf("Path() = %q, want %q", got, want)
+			}
+			if got := tt.ref.FileName(".axon"); got != tt.wantFileName {
+				t.Errorf("FileName() = %q, want %q", got, tt.wantFileName)
+			}
+		})
+	}
+}
+
+func TestSpec_Ref(t *testing.T) {
+	s, err := Parse("hf/microsoft/resnet-50@v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Ref(), NewModelRef("hf", "microsoft/resnet-50", "v2"); got != want || got.String() != s.String() {
+		t.Errorf("Ref() = %+v (%s), want %+v", got, got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ErrNoMatchingVersion is returned by Resolve when no available version satisfies a range
+var ErrNoMatchingVersion = errors.New("no matching version")
+
+// Constraint returns the version range of the spec (KindRange only)
+func (s Spec) Constraint() (*semver.Constraints, error) {
+	if s.Kind != KindRange {
+		return nil, fmt.Errorf("version %q is not a version range", s.Version)
+	}
+	return semver.NewConstraint(s.Version)
+}
+
+// Resolve returns the highest of the available versions that satisfies the spec's range.
+// Versions that aren't semantic versions are ignored. Prereleases are only selected when
+// pre is set, and then whenever the release they precede is in the range (so ^1.2 admits
+// 1.5.0-rc.1). The choice depends only on the range and the set of versions: of versions
+// that are equal as semantic versions, such as v1.0.0 and 1.0.0, the lowest string wins.
+func (s Spec) Resolve(versions []string, pre bool) (string, error) {
+	constraint, err := s.Constraint()
+	if err != nil {
+		return "", err
+	}
+
+	var best string
+	var bestVersion *semver.Version
+	for _, version := range versions {
+		v, err := semver.NewVersion(version)
+		if err != nil || !satisfies(constraint, v, pre) {
+			continue
+		}
+		if bestVersion == nil {
+			best, bestVersion = version, v
+			continue
+		}
+		if c := v.Compare(bestVersion); c > 0 || c == 0 && version < best {
+			best, bestVersion = version, v
+		}
+	}
+	if bestVersion == nil {
+		return "", fmt.Errorf("%w for %s/%s@%s in %s", ErrNoMatchingVersion, s.Namespace, s.Name, s.Version, describeVersions(versions))
+	}
+	return best, nil
+}
+
+// satisfies reports whether v is in the range; prereleases only are with pre
+func satisfies(constraint *semver.Constraints, v *semver.Version, pre bool) bool {
+	if v.Prerelease() == "" {
+		return constraint.Check(v)
+	}
+	if !pre {
+		return false
+	}
+	release, err := v.SetPrerelease("")
+	return constraint.Check(v) || err == nil && constraint.Check(&release)
+}
+
+// describeVersions lists versions for error messages
+func describeVersions(versions []string) string {
+	if len(versions) == 0 {
+		return "no available versions"
+	}
+	sorted := append([]string(nil), versions...)
+	sort.Strings(sorted)
+	return fmt.Sprintf("available versions %v", sorted)
+}
+
+// SortVersions orders versions oldest first: tags that aren't semantic versions by name,
+// then semantic versions by precedence (v1.2.0 before 1.10.0)
+func SortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, errI := semver.NewVersion(versions[i])
+		vj, errJ := semver.NewVersion(versions[j])
+		switch {
+		case errI != nil && errJ != nil:
+			return versions[i] < versions[j]
+		case errI != nil || errJ != nil:
+			return errI != nil
+		case !vi.Equal(vj):
+			return vi.LessThan(vj)
+		}
+		return versions[i] < versions[j]
+	})
+}
+
+// LatestVersion returns the version latest refers to among versions: the highest
+// release, else the highest prerelease, else a version named latest, else the last tag
+// by name ("" if there are none)
+func LatestVersion(versions []string) string {
+	if len(versions) == 0 {
+		return ""
+	}
+	sorted := append([]string(nil), versions...)
+	SortVersions(sorted)
+
+	var prerelease string
+	for i := len(sorted) - 1; i >= 0; i-- {
+		v, err := semver.NewVersion(sorted[i])
+		if err != nil {
+			continue
+		}
+		if v.Prerelease() == "" {
+			return sorted[i]
+		}
+		if prerelease == "" {
+			prerelease = sorted[i]
+		}
+	}
+	if prerelease != "" {
+		return prerelease
+	}
+	if slices.Contains(sorted, Latest) {
+		return Latest
+	}
+	return sorted[len(sorted)-1]
+}
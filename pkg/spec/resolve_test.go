@@ -0,0 +1,92 @@
+package spec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSpec_Resolve(t *testing.T) {
+	versions := []string{"1.0.0", "1.2.0", "1.4.1", "v1.4.1", "1.5.0-rc.1", "2.0.0", "2.1.0-beta", "3.0.0", "main", "latest"}
+
+	tests := []struct {
+		version string
+		pre     bool
+		want    string
+	}{
+		{version: "^1.2", want: "1.4.1"},
+		{version: "^1.2", pre: true, want: "1.5.0-rc.1"},
+		{version: ">=2.0 <3.0", want: "2.0.0"},
+		{version: ">=2.0 <3.0", pre: true, want: "2.1.0-beta"},
+		{version: "~1.2", want: "1.2.0"},
+		{version: "1.x || >=3", want: "3.0.0"},
+		{version: "1.0 - 1.3", want: "1.2.0"},
+		{version: "*", want: "3.0.0"},
+		{version: ">=4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			s, err := Parse("nlp/bert@" + tt.version)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			got, err := s.Resolve(versions, tt.pre)
+			if tt.want == "" {
+				if !errors.Is(err, ErrNoMatchingVersion) {
+					t.Errorf("Resolve() = %q, %v, want ErrNoMatchingVersion", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Resolve(pre=%v) = %q, %v, want %q", tt.pre, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpec_Resolve_Deterministic(t *testing.T) {
+	s, err := Parse("nlp/bert@^1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Equal versions spelled differently resolve the same whatever the listing order
+	for _, versions := range [][]string{{"v1.4.1", "1.4.1", "1.4.0"}, {"1.4.0", "1.4.1", "v1.4.1"}} {
+		if got, err := s.Resolve(versions, false); err != nil || got != "1.4.1" {
+			t.Errorf("Resolve(%v) = %q, %v, want 1.4.1", versions, got, err)
+		}
+	}
+
+	latest, _ := Parse("nlp/bert@1.4.1")
+	if _, err := latest.Resolve(nil, false); err == nil {
+		t.Error("Resolve() of a version that isn't a range succeeded")
+	}
+}
+
+func TestSortVersionsAndLatest(t *testing.T) {
+	tests := []struct {
+		versions []string
+		sorted   []string
+		latest   string
+	}{
+		{
+			versions: []string{"1.10.0", "v1.2.0", "2.0.0-rc.1", "main", "1.9.0", "latest"},
+			sorted:   []string{"latest", "main", "v1.2.0", "1.9.0", "1.10.0", "2.0.0-rc.1"},
+			latest:   "1.10.0",
+		},
+		{versions: []string{"2.0.0-rc.2", "2.0.0-rc.10", "main"}, sorted: []string{"main", "2.0.0-rc.2", "2.0.0-rc.10"}, latest: "2.0.0-rc.10"},
+		{versions: []string{"main", "latest", "dev"}, sorted: []string{"dev", "latest", "main"}, latest: "latest"},
+		{versions: []string{"main", "dev"}, sorted: []string{"dev", "main"}, latest: "main"},
+		{versions: nil, sorted: nil, latest: ""},
+	}
+	for _, tt := range tests {
+		versions := append([]string(nil), tt.versions...)
+		SortVersions(versions)
+		if !reflect.DeepEqual(versions, tt.sorted) {
+			t.Errorf("SortVersions(%v) = %v, want %v", tt.versions, versions, tt.sorted)
+		}
+		if got := LatestVersion(tt.versions); got != tt.latest {
+			t.Errorf("LatestVersion(%v) = %q, want %q", tt.versions, got, tt.latest)
+		}
+	}
+}
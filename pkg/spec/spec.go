@@ -0,0 +1,525 @@
+// Package spec parses model specifications such as hf/bert-base-uncased@latest.
+//
+// A spec is [registry/]namespace/name[@version]. The name may have several path segments
+// (hf/microsoft/resnet-50) and the version may be "latest" (the default), a semantic
+// version, a range of semantic versions (^1.2, ">=2.0 <3.0"; see Resolve), a sha256:<hex>
+// content digest, or any other tag the repository understands, such as a branch or
+// revision. As in container image references, a first segment with a
+// dot or a port, or localhost, is the host of an Axon registry to fetch the model from
+// (registry.example.com/nlp/bert@1.2.3). Specs may be quoted, as they often are in YAML
+// files, and repository URLs such as https://huggingface.co/microsoft/resnet-50 are
+// accepted as well.
+//
+// Model files downloaded directly from a URL use the url namespace, with the URL as the
+// name: url/https%3A//example.com/models/resnet.onnx (or the unescaped URL) is stored as
+// url/https/example.com/models/resnet.onnx. See DirectURL.
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// Latest is the version used when a spec doesn't name one
+const Latest = "latest"
+
+// DigestPrefix marks a version that pins a content digest instead of naming a version,
+// e.g. hf/bert-base-uncased@sha256:abcd...
+const DigestPrefix = "sha256:"
+
+// MinDigestLength is the shortest digest prefix accepted in a pin
+const MinDigestLength = 12
+
+// URLNamespace is the namespace of model files downloaded directly from a URL
+const URLNamespace = "url"
+
+// Format describes the accepted spec syntax, for error messages
+const Format = "[registry/]namespace/name[@version]"
+
+// Parse errors, wrapped in a *ParseError
+var (
+	ErrEmpty            = errors.New("empty model spec")
+	ErrMissingNamespace = errors.New("missing namespace")
+	ErrMissingName      = errors.New("missing name")
+	ErrEmptySegment     = errors.New("empty path segment")
+	ErrInvalidCharacter = errors.New("invalid character")
+	ErrEmptyVersion     = errors.New("empty version after @")
+	ErrMultipleVersions = errors.New("more than one @")
+	ErrInvalidDigest    = errors.New("invalid digest")
+	ErrInvalidRange     = errors.New("invalid version range")
+	ErrUnsupportedURL   = errors.New("unsupported repository URL")
+)
+
+// VersionKind classifies the version of a spec
+type VersionKind int
+
+const (
+	// KindLatest is the "latest" version
+	KindLatest VersionKind = iota
+	// KindSemver is a semantic version such as 1.2.0 or v2
+	KindSemver
+	// KindDigest is a sha256: content digest pin
+	KindDigest
+	// KindTag is any other version, e.g. a branch or revision
+	KindTag
+	// KindRange is a semantic version constraint such as ^1.2 or >=2.0 <3.0, resolved
+	// against the versions a registry has
+	KindRange
+)
+
+func (k VersionKind) String() string {
+	switch k {
+	case KindLatest:
+		return "latest"
+	case KindSemver:
+		return "semver"
+	case KindDigest:
+		return "digest"
+	case KindRange:
+		return "range"
+	default:
+		return "tag"
+	}
+}
+
+// Spec is a parsed model specification
+type Spec struct {
+	Registry  string // Host[:port] of the registry the spec names, if any
+	Namespace string
+	Name      string
+	Version   string // Latest when the spec doesn't name a version
+
+	// HasVersion reports whether the spec named a version explicitly
+	HasVersion bool
+
+	Kind VersionKind
+
+	// Digest is the pinned lowercase hex digest, without DigestPrefix (KindDigest only)
+	Digest string
+}
+
+// ParseError describes why a spec is invalid
+type ParseError struct {
+	Input  string
+	Err    error  // One of the Err* values
+	Detail string // What exactly is wrong, e.g. the offending character
+	Hint   string // How to fix it, e.g. "did you mean hf/bert?"
+
+	// Pos is the 1-based byte column in Input the problem is at (0 when it isn't at one place)
+	Pos int
+}
+
+func (e *ParseError) Error() string {
+	msg := fmt.Sprintf("invalid model spec %q: %v", e.Input, e.Err)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	if e.Pos > 0 {
+		msg += fmt.Sprintf(" at column %d", e.Pos)
+	}
+	if e.Hint != "" {
+		msg += "; " + e.Hint
+	} else {
+		msg += "; expected " + Format
+	}
+	return msg
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Parse parses a model spec or repository URL
+func Parse(input string) (Spec, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return Spec{}, &ParseError{Input: input, Err: ErrEmpty}
+	}
+	offset := strings.Index(input, s) // Of s in input, for error positions
+	if unquoted, ok := unquote(s); ok {
+		inner := strings.TrimSpace(unquoted)
+		s, offset = inner, offset+1+strings.Index(unquoted, inner)
+		if s == "" {
+			return Spec{}, &ParseError{Input: input, Err: ErrEmpty}
+		}
+	}
+	if spec, ok, err := parseDirectURL(input, s); ok {
+		return spec, err
+	}
+	if strings.Contains(s, "://") {
+		return parseURL(input, s)
+	}
+
+	path, version, hasVersion := strings.Cut(s, "@")
+	versionOffset := offset + len(path) + 1
+	if hasVersion && strings.Contains(version, "@") {
+		return Spec{}, &ParseError{Input: input, Err: ErrMultipleVersions, Pos: column(versionOffset, strings.Index(version, "@"))}
+	}
+
+	var registry string
+	if host, rest, ok := strings.Cut(path, "/"); ok && isRegistryHost(host) && strings.Contains(rest, "/") {
+		if err := checkRegistry(input, host, offset); err != nil {
+			return Spec{}, err
+		}
+		registry, path, offset = host, rest, offset+len(host)+1
+	}
+
+	namespace, name, found := strings.Cut(path, "/")
+	switch {
+	case !found && path != "":
+		return Spec{}, &ParseError{Input: input, Err: ErrMissingNamespace, Hint: fmt.Sprintf("did you mean hf/%s?", s)}
+	case namespace == "":
+		return Spec{}, &ParseError{Input: input, Err: ErrMissingNamespace, Pos: column(offset, 0)}
+	case name == "":
+		return Spec{}, &ParseError{Input: input, Err: ErrMissingName, Hint: fmt.Sprintf("expected %s/<name>", namespace), Pos: column(offset, len(namespace)+1)}
+	}
+
+	if err := checkSegment(input, namespace, "namespace", offset); err != nil {
+		return Spec{}, err
+	}
+	segmentOffset := offset + len(namespace) + 1
+	for _, segment := range strings.Split(name, "/") {
+		if err := checkSegment(input, segment, "name", segmentOffset); err != nil {
+			return Spec{}, err
+		}
+		segmentOffset += len(segment) + 1
+	}
+
+	spec, err := build(input, namespace, name, version, hasVersion, versionOffset)
+	spec.Registry = registry
+	return spec, err
+}
+
+// unquote returns s without the single or double quotes around it, if it is quoted
+func unquote(s string) (string, bool) {
+	if len(s) < 2 || (s[0] != '"' && s[0] != '\'') || s[len(s)-1] != s[0] {
+		return s, false
+	}
+	return s[1 : len(s)-1], true
+}
+
+// column returns the 1-based column of byte i of a part of the input that starts at
+// offset (0 when the offset isn't known)
+func column(offset, i int) int {
+	if offset < 0 {
+		return 0
+	}
+	return offset + i + 1
+}
+
+// isRegistryHost reports whether the first segment of a spec is a registry host rather
+// than a namespace: as in container image references, a hostname with a dot or a port,
+// or localhost. "." and ".." are path segments, not hosts.
+func isRegistryHost(segment string) bool {
+	if segment == "." || segment == ".." {
+		return false
+	}
+	return strings.ContainsAny(segment, ".:") || segment == "localhost"
+}
+
+// checkRegistry validates a registry host[:port] that starts at offset in the input: a
+// hostname of dot-separated labels of letters, digits and hyphens (not at either end of
+// a label), and a port from 1 to 65535
+func checkRegistry(input, host string, offset int) error {
+	hostname, port, hasPort := strings.Cut(host, ":")
+	if n, err := strconv.Atoi(port); hasPort && (!isDigits(port) || err != nil || n < 1 || n > 65535) {
+		return &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: fmt.Sprintf("port %q in registry", port), Pos: column(offset, len(hostname)+1)}
+	}
+	labelOffset := 0
+	for _, label := range strings.Split(hostname, ".") {
+		if label == "" {
+			return &ParseError{Input: input, Err: ErrEmptySegment, Detail: "in registry host", Pos: column(offset, labelOffset)}
+		}
+		for i, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: fmt.Sprintf("%q in registry", c), Pos: column(offset, labelOffset+i)}
+			}
+		}
+		if i := strings.Index(label, "-"); i == 0 || strings.HasSuffix(label, "-") {
+			if i != 0 {
+				i = len(label) - 1
+			}
+			return &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: "'-' at the start or end of a label in registry", Pos: column(offset, labelOffset+i)}
+		}
+		labelOffset += len(label) + 1
+	}
+	return nil
+}
+
+// build validates the version, which starts at versionOffset in the input (-1 if it
+// isn't there as is), and assembles a Spec
+func build(input, namespace, name, version string, hasVersion bool, versionOffset int) (Spec, error) {
+	spec := Spec{Namespace: namespace, Name: name, Version: version, HasVersion: hasVersion}
+	if !hasVersion {
+		spec.Version = Latest
+		return spec, nil
+	}
+	if version == "" {
+		return Spec{}, &ParseError{Input: input, Err: ErrEmptyVersion, Hint: fmt.Sprintf("omit the @ or use %s/%s@%s", namespace, name, Latest), Pos: column(versionOffset, -1)}
+	}
+	if isRange(version) {
+		for i, c := range version {
+			if !isVersionChar(c) && !strings.ContainsRune(rangeChars, c) {
+				return Spec{}, &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: fmt.Sprintf("%q in version range", c), Pos: column(versionOffset, i)}
+			}
+		}
+		if _, err := semver.NewConstraint(version); err != nil {
+			return Spec{}, &ParseError{Input: input, Err: ErrInvalidRange, Detail: err.Error(), Pos: column(versionOffset, 0)}
+		}
+		spec.Kind = KindRange
+		return spec, nil
+	}
+	for i, c := range version {
+		if !isVersionChar(c) {
+			return Spec{}, &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: fmt.Sprintf("%q in version", c), Pos: column(versionOffset, i)}
+		}
+	}
+
+	digest, pinned, err := ParseDigest(version)
+	switch {
+	case err != nil:
+		return Spec{}, &ParseError{Input: input, Err: ErrInvalidDigest, Detail: err.Error(), Pos: column(versionOffset, len(DigestPrefix))}
+	case pinned:
+		spec.Kind = KindDigest
+		spec.Digest = digest
+		spec.Version = DigestPrefix + digest
+	case version == Latest:
+		spec.Kind = KindLatest
+	case isSemver(version):
+		spec.Kind = KindSemver
+	default:
+		spec.Kind = KindTag
+	}
+	return spec, nil
+}
+
+// ParseDigest reports whether version is a digest pin and returns the pinned lowercase
+// hex digest. Abbreviated digests of at least MinDigestLength characters are accepted.
+func ParseDigest(version string) (string, bool, error) {
+	if !strings.HasPrefix(strings.ToLower(version), DigestPrefix) {
+		return "", false, nil
+	}
+
+	digest := strings.ToLower(version[len(DigestPrefix):])
+	if len(digest) < MinDigestLength || len(digest) > 64 {
+		return "", true, fmt.Errorf("expected %s followed by %d-64 hex characters", DigestPrefix, MinDigestLength)
+	}
+	for _, c := range digest {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return "", true, fmt.Errorf("%q is not a hex digest", version[len(DigestPrefix):])
+		}
+	}
+	return digest, true, nil
+}
+
+// String formats the spec as [registry/]namespace/name@version
+func (s Spec) String() string {
+	if s.Registry != "" {
+		return fmt.Sprintf("%s/%s/%s@%s", s.Registry, s.Namespace, s.Name, s.Version)
+	}
+	return fmt.Sprintf("%s/%s@%s", s.Namespace, s.Name, s.Version)
+}
+
+// ID formats the spec as namespace/name
+func (s Spec) ID() string {
+	return s.Namespace + "/" + s.Name
+}
+
+// Semver returns the version as a semantic version (KindSemver only)
+func (s Spec) Semver() (*semver.Version, error) {
+	if s.Kind != KindSemver {
+		return nil, fmt.Errorf("version %q is not a semantic version", s.Version)
+	}
+	return semver.NewVersion(s.Version)
+}
+
+// checkSegment validates one namespace or name path segment, which starts at offset in
+// the input (-1 if it isn't there as is)
+func checkSegment(input, segment, what string, offset int) error {
+	if segment == "" {
+		return &ParseError{Input: input, Err: ErrEmptySegment, Detail: "in " + what, Pos: column(offset, -1)}
+	}
+	if segment == "." || segment == ".." {
+		return &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: fmt.Sprintf("%q segment in %s", segment, what), Pos: column(offset, 0)}
+	}
+	for i, c := range segment {
+		if !isNameChar(c) {
+			return &ParseError{Input: input, Err: ErrInvalidCharacter, Detail: fmt.Sprintf("%q in %s", c, what), Pos: column(offset, i)}
+		}
+	}
+	return nil
+}
+
+func isNameChar(c rune) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.'
+}
+
+func isVersionChar(c rune) bool {
+	return isNameChar(c) || c == '+' || c == ':'
+}
+
+// rangeOperators mark a version range; rangeChars may appear in one besides version
+// characters
+const (
+	rangeOperators = "^~<>=!*,|"
+	rangeChars     = rangeOperators + " "
+)
+
+// isRange reports whether version is a version range rather than a single version: one
+// with comparison operators, wildcards, several alternatives or a hyphen range
+func isRange(version string) bool {
+	return strings.ContainsAny(version, rangeOperators) || strings.Contains(version, " - ")
+}
+
+// isSemver reports whether version is a semantic version (a leading v is allowed)
+func isSemver(version string) bool {
+	_, err := semver.NewVersion(version)
+	return err == nil
+}
+
+// parseURL parses a model page URL on a supported repository
+func parseURL(input, s string) (Spec, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return Spec{}, &ParseError{Input: input, Err: ErrUnsupportedURL, Detail: "not a valid URL"}
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	segments := strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })
+	unsupported := &ParseError{
+		Input: input,
+		Err:   ErrUnsupportedURL,
+		Hint:  "use a huggingface.co, modelscope.cn or tfhub.dev model URL, or " + Format,
+	}
+
+	var namespace, version string
+	var nameSegments []string
+	switch host {
+	case "huggingface.co", "hf.co":
+		// https://huggingface.co/<org>/<model>[/tree/<revision>]
+		namespace = "hf"
+		nameSegments = segments
+		for i, segment := range segments {
+			if segment == "tree" || segment == "blob" || segment == "resolve" {
+				nameSegments = segments[:i]
+				if segment == "tree" && i+1 < len(segments) {
+					version = segments[i+1]
+				}
+				break
+			}
+		}
+		if len(nameSegments) > 2 {
+			return Spec{}, unsupported
+		}
+	case "modelscope.cn":
+		// https://modelscope.cn/models/<owner>/<model>
+		if len(segments) < 3 || segments[0] != "models" {
+			return Spec{}, unsupported
+		}
+		namespace = "modelscope"
+		nameSegments = segments[1:3]
+	case "tfhub.dev":
+		// https://tfhub.dev/<publisher>/<model...>[/<version>]
+		namespace = "tfhub"
+		nameSegments = segments
+		if n := len(segments); n > 2 && isDigits(segments[n-1]) {
+			nameSegments = segments[:n-1]
+			version = segments[n-1]
+		}
+	default:
+		return Spec{}, unsupported
+	}
+
+	if len(nameSegments) == 0 {
+		return Spec{}, &ParseError{Input: input, Err: ErrMissingName}
+	}
+	for _, segment := range nameSegments {
+		if err := checkSegment(input, segment, "name", -1); err != nil {
+			return Spec{}, err
+		}
+	}
+	return build(input, namespace, strings.Join(nameSegments, "/"), version, version != "", -1)
+}
+
+// parseDirectURL parses url/<URL>[@version], where the URL may be escaped as in
+// url/https%3A//example.com/model.onnx. It reports false if s isn't a URL spec, e.g. for
+// url/<name> specs that are already in the stored form.
+func parseDirectURL(input, s string) (Spec, bool, error) {
+	rest, ok := strings.CutPrefix(s, URLNamespace+"/")
+	if !ok {
+		return Spec{}, false, nil
+	}
+
+	// An @ after the last / starts the version; earlier ones belong to the URL
+	var version string
+	hasVersion := false
+	if at := strings.LastIndex(rest, "@"); at > strings.LastIndex(rest, "/") {
+		rest, version, hasVersion = rest[:at], rest[at+1:], true
+	}
+	raw, err := url.PathUnescape(rest)
+	if err != nil || !strings.Contains(raw, "://") {
+		return Spec{}, false, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return Spec{}, true, &ParseError{Input: input, Err: ErrUnsupportedURL, Detail: "expected an http or https URL"}
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return Spec{}, true, &ParseError{
+			Input:  input,
+			Err:    ErrUnsupportedURL,
+			Detail: "credentials, query strings and fragments can't be part of a model name",
+			Hint:   "describe the download in a url spec file and install url/<file>.yaml",
+		}
+	}
+
+	// The port is kept after an underscore, which can't appear in a hostname
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" {
+		host += "_" + port
+	}
+	segments := append([]string{u.Scheme, host}, strings.FieldsFunc(u.Path, func(r rune) bool { return r == '/' })...)
+	if len(segments) == 2 {
+		return Spec{}, true, &ParseError{Input: input, Err: ErrMissingName, Detail: "the URL has no file path"}
+	}
+	for _, segment := range segments[1:] {
+		if err := checkSegment(input, segment, "name", -1); err != nil {
+			return Spec{}, true, err
+		}
+	}
+	spec, err := build(input, URLNamespace, strings.Join(segments, "/"), version, hasVersion, -1)
+	return spec, true, err
+}
+
+// DirectURL returns the URL of a url-namespace model name in the stored form
+// (https/example.com/models/resnet.onnx). It reports false for other names, such as the
+// paths of url spec files.
+func DirectURL(name string) (string, bool) {
+	scheme, rest, ok := strings.Cut(name, "/")
+	if !ok || (scheme != "http" && scheme != "https") {
+		return "", false
+	}
+	host, path, ok := strings.Cut(rest, "/")
+	if !ok || host == "" || path == "" {
+		return "", false
+	}
+	if h, port, found := strings.Cut(host, "_"); found {
+		host = h + ":" + port
+	}
+	return scheme + "://" + host + "/" + path, true
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
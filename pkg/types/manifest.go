@@ -26,6 +26,7 @@ type Metadata struct {
 	Created       time.Time `yaml:"created"`
 	Updated       time.Time `yaml:"updated"`
 	Tags          []string  `yaml:"tags,omitempty"`
+	Revision      int       `yaml:"revision,omitempty"` // Incremented by each in-place metadata edit ('axon manifest set')
 }
 
 // Author represents a model author
@@ -39,10 +40,61 @@ type Author struct {
 type Spec struct {
 	Framework    Framework    `yaml:"framework"`
 	Format       Format       `yaml:"format"`
+	Task         string       `yaml:"task,omitempty"` // e.g. "text-classification", "image-classification" (Hugging Face pipeline tags)
 	IO           IO           `yaml:"io"`
 	Requirements Requirements `yaml:"requirements"`
 	Performance  Performance  `yaml:"performance,omitempty"`
 	Dependencies Dependencies `yaml:"dependencies,omitempty"`
+	Conversion   *Conversion  `yaml:"conversion,omitempty"`
+}
+
+// Conversion records the outcome and provenance of execution format conversion during install
+type Conversion struct {
+	Status      string    `yaml:"status"`                 // "converted", "failed", "skipped", "not_needed"
+	Target      string    `yaml:"target"`                 // Target execution format (e.g., "onnx")
+	Method      string    `yaml:"method,omitempty"`       // "pre-converted", "docker", "local-python"
+	Image       string    `yaml:"image,omitempty"`        // Converter image (docker method)
+	ImageDigest string    `yaml:"image_digest,omitempty"` // Converter image digest (docker method)
+	Opset       int       `yaml:"opset,omitempty"`        // ONNX opset version
+	Precision   string    `yaml:"precision,omitempty"`    // ONNX weight precision: fp32, fp16 or int8
+	DurationSec float64   `yaml:"duration_seconds,omitempty"`
+	ConvertedAt time.Time `yaml:"converted_at,omitempty"`
+	Error       string    `yaml:"error,omitempty"`      // Converter error output when conversion failed
+	Attempts    int       `yaml:"attempts,omitempty"`   // Number of conversion attempts
+	TimedOut    bool      `yaml:"timed_out,omitempty"`  // The last attempt hit the conversion timeout
+	OOMKilled   bool      `yaml:"oom_killed,omitempty"` // The last attempt was killed for running out of memory
+	SafeMode    bool      `yaml:"safe_mode,omitempty"`  // The last attempt ran without constant folding and with shorter sequences
+	SizeRatio   float64   `yaml:"size_ratio,omitempty"` // Output size relative to the source weights
+	Warning     string    `yaml:"warning,omitempty"`    // Non-fatal problem found after conversion
+	Cached      bool      `yaml:"cached,omitempty"`     // Outputs were reused from the conversion result cache
+	Parity      *Parity   `yaml:"parity,omitempty"`     // Source vs converted output comparison, when requested
+
+	Artifacts []ConvertedArtifact `yaml:"artifacts,omitempty"` // Files produced by the conversion
+}
+
+// ConvertedArtifact records a file produced by conversion and the environment that produced it
+type ConvertedArtifact struct {
+	Path      string     `yaml:"path"` // Relative path from model root (e.g., "model.onnx")
+	Toolchain *Toolchain `yaml:"toolchain,omitempty"`
+}
+
+// Toolchain records converter package versions, used to debug conversion differences between hosts
+type Toolchain struct {
+	Python       string `yaml:"python,omitempty" json:"python,omitempty"`
+	Torch        string `yaml:"torch,omitempty" json:"torch,omitempty"`
+	Transformers string `yaml:"transformers,omitempty" json:"transformers,omitempty"`
+	Optimum      string `yaml:"optimum,omitempty" json:"optimum,omitempty"`
+	ONNX         string `yaml:"onnx,omitempty" json:"onnx,omitempty"`
+	Opset        int    `yaml:"opset,omitempty" json:"opset,omitempty"`
+}
+
+// Parity records how closely a converted model's outputs match the source model's on a sample input
+type Parity struct {
+	MaxAbsDiff      float64 `yaml:"max_abs_diff" json:"max_abs_diff"`
+	Tolerance       float64 `yaml:"tolerance" json:"tolerance"`
+	OutputsCompared int     `yaml:"outputs_compared,omitempty" json:"outputs_compared,omitempty"`
+	Passed          bool    `yaml:"passed" json:"passed"`
+	Skipped         string  `yaml:"skipped,omitempty" json:"skipped,omitempty"` // Why the check could not run
 }
 
 // Framework specifies the ML framework
@@ -58,6 +110,21 @@ type Format struct {
 	MultiEncoder    string          `yaml:"multi_encoder,omitempty" json:"multi_encoder,omitempty"` // Architecture for multi-encoder models (clip, seq2seq)
 	Files           []ModelFile     `yaml:"files" json:"files"`
 	ExecutionFiles  []ExecutionFile `yaml:"execution_files,omitempty" json:"execution_files,omitempty"` // Explicit paths for execution files (ONNX, GGUF, etc.)
+	Shards          *ShardMap       `yaml:"shards,omitempty" json:"shards,omitempty"`                   // Set for weights split across several files
+}
+
+// ShardMap records a sharded checkpoint: the index file mapping tensors to shards, and
+// the shards it references
+type ShardMap struct {
+	Index     string  `yaml:"index" json:"index"`                               // e.g. model.safetensors.index.json
+	TotalSize int64   `yaml:"total_size,omitempty" json:"total_size,omitempty"` // Bytes of tensor data, as the index declares
+	Shards    []Shard `yaml:"shards" json:"shards"`
+}
+
+// Shard is one file of a sharded checkpoint
+type Shard struct {
+	Path    string `yaml:"path" json:"path"`
+	Tensors int    `yaml:"tensors" json:"tensors"` // Tensors the index maps to the shard
 }
 
 // ExecutionFile represents a model file for execution by Core
@@ -92,7 +159,7 @@ type IOSpec struct {
 
 // PreprocessingSpec describes preprocessing requirements
 type PreprocessingSpec struct {
-	Type          string                 `yaml:"type"`                     // "tokenization", "normalization", "resize"
+	Type          string                 `yaml:"type"`                     // "tokenization", "normalization", "resize", "log_mel_spectrogram", "waveform"
 	Tokenizer     string                 `yaml:"tokenizer,omitempty"`      // Path to tokenizer.json
 	TokenizerType string                 `yaml:"tokenizer_type,omitempty"` // "bert", "gpt2", etc.
 	Config        map[string]interface{} `yaml:"config,omitempty"`         // Normalization params, resize params, etc.
@@ -125,12 +192,22 @@ type MemoryRequirement struct {
 
 // GPURequirement specifies GPU requirements
 type GPURequirement struct {
-	Required    bool    `yaml:"required"`
-	Recommended bool    `yaml:"recommended"`
-	MinVRAMGB   float64 `yaml:"min_vram_gb,omitempty"`
-	CUDAVersion string  `yaml:"cuda_version,omitempty"`
+	Required             bool     `yaml:"required"`
+	Recommended          bool     `yaml:"recommended"`
+	MinVRAMGB            float64  `yaml:"min_vram_gb,omitempty"`
+	MinComputeCapability string   `yaml:"min_compute_capability,omitempty"` // CUDA compute capability, e.g. "8.0"
+	CUDAVersion          string   `yaml:"cuda_version,omitempty"`
+	ROCmVersion          string   `yaml:"rocm_version,omitempty"`
+	Accelerators         []string `yaml:"accelerators,omitempty"` // GPU platforms the model runs on, e.g. "cuda", "rocm"
 }
 
+// GPU platforms of GPURequirement.Accelerators
+const (
+	AcceleratorCUDA  = "cuda"
+	AcceleratorROCm  = "rocm"
+	AcceleratorMetal = "metal"
+)
+
 // Storage specifies storage requirements
 type Storage struct {
 	MinGB         float64 `yaml:"min_gb"`
@@ -161,6 +238,17 @@ type PackageDependency struct {
 type Distribution struct {
 	Package  PackageInfo  `yaml:"package"`
 	Registry RegistryInfo `yaml:"registry"`
+
+	// Delta packages that update an earlier version to this one (see internal/delta)
+	Deltas []DeltaInfo `yaml:"deltas,omitempty"`
+}
+
+// DeltaInfo describes a delta package from an earlier version
+type DeltaInfo struct {
+	From   string `yaml:"from"` // Version the delta applies to
+	URL    string `yaml:"url"`  // Absolute, or relative to the package URL
+	Size   int64  `yaml:"size"`
+	SHA256 string `yaml:"sha256"`
 }
 
 // PackageInfo contains package location and checksums
@@ -169,6 +257,16 @@ type PackageInfo struct {
 	Size    int64    `yaml:"size"`
 	SHA256  string   `yaml:"sha256"`
 	Mirrors []string `yaml:"mirrors,omitempty"`
+
+	// Detached signatures over the package digest (see internal/signing)
+	Signatures []PackageSignature `yaml:"signatures,omitempty"`
+}
+
+// PackageSignature is a signature of a package by a publisher key
+type PackageSignature struct {
+	KeyID     string `yaml:"key_id"`    // Fingerprint of the public key
+	Algorithm string `yaml:"algorithm"` // "ed25519"
+	Signature string `yaml:"signature"` // Base64
 }
 
 // RegistryInfo contains registry information
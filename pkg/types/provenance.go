@@ -0,0 +1,73 @@
+package types
+
+import "time"
+
+// ProvenanceFileName is the supply-chain record included in every package Axon builds
+const ProvenanceFileName = "provenance.json"
+
+// ProvenanceSchemaVersion is the version of the provenance.json format
+const ProvenanceSchemaVersion = 1
+
+// Provenance records where the files of a package came from and how they were produced,
+// for supply-chain audits
+type Provenance struct {
+	SchemaVersion int                  `json:"schema_version"`
+	BuiltAt       time.Time            `json:"built_at,omitzero"` // $SOURCE_DATE_EPOCH of reproducible builds (unset otherwise)
+	Builder       ProvenanceBuilder    `json:"builder"`
+	Source        ProvenanceSource     `json:"source"`
+	Files         []ProvenanceFile     `json:"files"`
+	Converter     *ProvenanceConverter `json:"converter,omitempty"`   // Set when the package includes converted files
+	WeightScan    *WeightScan          `json:"weight_scan,omitempty"` // Set when the installed weights were scanned
+}
+
+// ProvenanceBuilder identifies the tool that built the package
+type ProvenanceBuilder struct {
+	Name    string `json:"name"` // "axon"
+	Version string `json:"version"`
+}
+
+// ProvenanceSource identifies the upstream repository of the model
+type ProvenanceSource struct {
+	Adapter    string `json:"adapter,omitempty"`    // Repository adapter, e.g. "huggingface"
+	Model      string `json:"model,omitempty"`      // Model ID in the repository
+	Repository string `json:"repository,omitempty"` // Repository URL
+	Revision   string `json:"revision,omitempty"`   // Commit or revision the files were taken from
+	Gated      string `json:"gated,omitempty"`      // Approval mode of a gated model, whose license the downloading account accepted
+}
+
+// ProvenanceFile is a file in the package
+type ProvenanceFile struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	URL    string `json:"url,omitempty"`  // Where the file was downloaded from (empty for generated files)
+	ETag   string `json:"etag,omitempty"` // ETag the server sent with the file, for detecting upstream changes
+}
+
+// WeightScan records the inspection of a model's pickle-based weight files (.bin, .pt,
+// .ckpt) for imports that run code when they are loaded
+type WeightScan struct {
+	Scanner   string              `json:"scanner"`
+	ScannedAt time.Time           `json:"scanned_at"`
+	Files     []string            `json:"files"` // Weight files whose pickles were inspected
+	Findings  []WeightScanFinding `json:"findings,omitempty"`
+}
+
+// WeightScanFinding is an import in a weight file that isn't known to be safe
+type WeightScanFinding struct {
+	File     string `json:"file"`
+	Import   string `json:"import"`   // module.name
+	Severity string `json:"severity"` // dangerous or unknown
+}
+
+// ProvenanceConverter identifies the converter that produced converted files
+type ProvenanceConverter struct {
+	Method      string     `json:"method"`
+	Image       string     `json:"image,omitempty"`
+	ImageDigest string     `json:"image_digest,omitempty"`
+	Opset       int        `json:"opset,omitempty"`
+	Precision   string     `json:"precision,omitempty"` // ONNX weight precision: fp32, fp16 or int8
+	Toolchain   *Toolchain `json:"toolchain,omitempty"`
+	Parity      *Parity    `json:"parity,omitempty"` // Max output deviation from the source model, when checked
+	Files       []string   `json:"files,omitempty"`  // Converted files
+}
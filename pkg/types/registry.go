@@ -44,3 +44,12 @@ type Statistics struct {
 	TotalDownloads  int `json:"total_downloads"`
 	TotalNamespaces int `json:"total_namespaces"`
 }
+
+// ModelVersions lists the versions a registry has of a model. It is served at
+// api/v1/models/<namespace>/<name>/versions (versions.json on static registries).
+type ModelVersions struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Versions  []string `json:"versions"`         // Oldest first
+	Latest    string   `json:"latest,omitempty"` // The version @latest resolves to
+}
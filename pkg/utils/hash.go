@@ -34,8 +34,14 @@ func VerifySHA256(filePath, expectedSHA256 string) error {
 		return err
 	}
 
+	return CompareSHA256(actual, expectedSHA256)
+}
+
+// CompareSHA256 verifies a SHA256 checksum taken elsewhere, such as while the file was
+// downloaded, against the expected one
+func CompareSHA256(actualSHA256, expectedSHA256 string) error {
 	expected := fmt.Sprintf("%064s", expectedSHA256)
-	actual = fmt.Sprintf("%064s", actual)
+	actual := fmt.Sprintf("%064s", actualSHA256)
 
 	if actual != expected {
 		return fmt.Errorf("checksum mismatch: expected %s, got %s", expectedSHA256, actual)
@@ -48,6 +48,14 @@ func TestVerifySHA256(t *testing.T) {
 	if err := VerifySHA256(testFile, "0000000000000000000000000000000000000000000000000000000000000000"); err == nil {
 		t.Error("VerifySHA256() should fail with wrong hash")
 	}
+
+	// A checksum taken while the file was written is compared the same way
+	if err := CompareSHA256(expectedHash, expectedHash); err != nil {
+		t.Errorf("CompareSHA256() error = %v, want nil", err)
+	}
+	if err := CompareSHA256(expectedHash, "0000000000000000000000000000000000000000000000000000000000000000"); err == nil {
+		t.Error("CompareSHA256() should fail with wrong hash")
+	}
 }
 
 func TestComputeSHA256Bytes(t *testing.T) {
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// rename is os.Rename, replaced in tests to simulate moves across filesystems
+var rename = os.Rename
+
+// MoveFile moves the file src to dst. Within a filesystem that's a rename; across
+// filesystems (e.g. a temp directory on tmpfs) the file is copied next to dst, synced and
+// renamed into place, so dst never holds a partial file, and src is removed.
+func MoveFile(src, dst string) error {
+	err := rename(src, dst)
+	if err == nil || !errors.Is(err, errCrossDevice) {
+		return err
+	}
+	if err := copyAtomic(src, dst); err != nil {
+		return fmt.Errorf("failed to move %s across filesystems: %w", src, err)
+	}
+	if err := os.Remove(src); err != nil {
+		return fmt.Errorf("failed to remove %s after copying it: %w", src, err)
+	}
+	return nil
+}
+
+// copyAtomic copies src to a temporary file in dst's directory and renames it to dst
+func copyAtomic(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = in.Close() }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := out.Name()
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, info.Mode().Perm()); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+)
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "model.axon.tmp"), filepath.Join(dir, "cache", "model.axon")
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(src, []byte("package"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	if data, err := os.ReadFile(dst); err != nil || string(data) != "package" {
+		t.Errorf("moved file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source left behind: %v", err)
+	}
+}
+
+func TestMoveFile_CrossDevice(t *testing.T) {
+	defer func() { rename = os.Rename }()
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: errCrossDevice}
+	}
+
+	dir := t.TempDir()
+	src, dst := filepath.Join(dir, "model.axon.tmp"), filepath.Join(dir, "model.axon")
+	if err := os.WriteFile(src, []byte("package"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveFile(src, dst); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(dst); string(data) != "package" {
+		t.Errorf("copied file = %q", data)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0640 {
+		t.Errorf("copied file mode = %v, want 0640", info.Mode().Perm())
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("source left behind: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+
+	// Other rename failures aren't papered over with a copy
+	rename = func(src, dst string) error {
+		return &os.LinkError{Op: "rename", Old: src, New: dst, Err: syscall.EACCES}
+	}
+	if err := os.WriteFile(src, []byte("package"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveFile(src, dst+".2"); err == nil {
+		t.Error("MoveFile() succeeded despite a permission error")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package utils
+
+import "syscall"
+
+// errCrossDevice is the error of a rename across filesystems
+var errCrossDevice error = syscall.EXDEV
@@ -0,0 +1,8 @@
+//go:build windows
+
+package utils
+
+import "syscall"
+
+// errCrossDevice is ERROR_NOT_SAME_DEVICE, the error of a move to another volume
+var errCrossDevice error = syscall.Errno(17)
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+)
+
+// TempDirEnv overrides the directory used for temporary files
+const TempDirEnv = "AXON_TMPDIR"
+
+// tempDir is the directory set with SetTempDir
+var tempDir string
+
+// SetTempDir makes dir the directory for temporary files, creating it if needed.
+// TMPDIR is set as well so child processes (e.g., the local Python converter)
+// stage their files there too.
+func SetTempDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	tempDir = dir
+	if err := os.Setenv("TMPDIR", dir); err != nil {
+		return fmt.Errorf("failed to set TMPDIR: %w", err)
+	}
+	return nil
+}
+
+// TempDir returns the directory for temporary files: the one set with SetTempDir,
+// else $AXON_TMPDIR, else the system default. /tmp is often a small tmpfs, so
+// multi-GB downloads and packages should be staged here rather than in os.TempDir().
+func TempDir() string {
+	if tempDir != "" {
+		return tempDir
+	}
+	if dir := os.Getenv(TempDirEnv); dir != "" {
+		return dir
+	}
+	return os.TempDir()
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTempDir(t *testing.T) {
+	defer func() { tempDir = "" }()
+
+	t.Setenv(TempDirEnv, "")
+	if got := TempDir(); got != os.TempDir() {
+		t.Errorf("TempDir() = %q, want system default %q", got, os.TempDir())
+	}
+
+	envDir := t.TempDir()
+	t.Setenv(TempDirEnv, envDir)
+	if got := TempDir(); got != envDir {
+		t.Errorf("TempDir() = %q, want %s %q", got, TempDirEnv, envDir)
+	}
+
+	t.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	dir := filepath.Join(t.TempDir(), "cache", "tmp")
+	if err := SetTempDir(dir); err != nil {
+		t.Fatalf("SetTempDir() error = %v", err)
+	}
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
+		t.Fatalf("SetTempDir() did not create %s", dir)
+	}
+	if got := TempDir(); got != dir {
+		t.Errorf("TempDir() = %q, want %q", got, dir)
+	}
+	if got := os.Getenv("TMPDIR"); got != dir {
+		t.Errorf("TMPDIR = %q, want %q", got, dir)
+	}
+}
@@ -73,3 +73,161 @@ def write_multi_encoder_manifest(output_dir, onnx_files, task=None):
     print(f'   Created onnx_manifest.json for {architecture} model')
     return manifest_path
 
+
+
+# Parity check options passed by Axon (--parity-check, --parity-tolerance)
+PARITY_ENABLED = os.environ.get('AXON_ONNX_PARITY', '0') == '1'
+PARITY_TOLERANCE = float(os.environ.get('AXON_ONNX_PARITY_TOLERANCE', '1e-3'))
+PARITY_REPORT = 'parity.json'
+
+
+def _flatten_outputs(output):
+    """Flatten model outputs (tensors, tuples, dicts, ModelOutput) into a list of tensors."""
+    import torch
+
+    if hasattr(output, 'to_tuple'):
+        output = output.to_tuple()
+    if isinstance(output, torch.Tensor):
+        return [output]
+    if isinstance(output, dict):
+        output = tuple(output.values())
+    flat = []
+    if isinstance(output, (tuple, list)):
+        for item in output:
+            flat.extend(_flatten_outputs(item))
+    return flat
+
+
+def _compare_outputs(model, sample, onnx_path):
+    """Run sample through the source model and the ONNX model and compare the outputs."""
+    import numpy as np
+    import onnxruntime as ort
+    import torch
+
+    session = ort.InferenceSession(onnx_path, providers=['CPUExecutionProvider'])
+    onnx_inputs = session.get_inputs()
+
+    if isinstance(sample, dict):
+        feeds = dict(sample)
+    elif isinstance(sample, (tuple, list)):
+        feeds = {inp.name: value for inp, value in zip(onnx_inputs, sample)}
+    else:
+        feeds = {onnx_inputs[0].name: sample}
+
+    # Optimum exports also take masks and token type IDs
+    reference = next(iter(feeds.values()))
+    for inp in onnx_inputs:
+        if inp.name in feeds:
+            continue
+        if inp.name == 'attention_mask':
+            feeds[inp.name] = torch.ones_like(reference)
+        elif 'int' in inp.type:
+            feeds[inp.name] = torch.zeros_like(reference)
+        else:
+            raise ValueError(f'no sample value for ONNX input {inp.name}')
+
+    with torch.no_grad():
+        if isinstance(sample, torch.Tensor) and len(feeds) == 1:
+            expected = _flatten_outputs(model(sample))
+        else:
+            expected = _flatten_outputs(model(**feeds))
+    actual = session.run(None, {inp.name: feeds[inp.name].numpy() for inp in onnx_inputs})
+
+    max_diff = 0.0
+    compared = 0
+    for want, got in zip(expected, actual):
+        want = want.detach().cpu().numpy()
+        if want.shape != got.shape:
+            continue
+        compared += 1
+        diff = np.abs(want.astype(np.float64) - got.astype(np.float64))
+        if diff.size and not np.all(np.isfinite(diff)):
+            return {'passed': False, 'outputs_compared': compared, 'error': 'outputs contain NaN or Inf'}
+        if diff.size:
+            max_diff = max(max_diff, float(diff.max()))
+    if compared == 0:
+        raise ValueError('no source and ONNX outputs with matching shapes to compare')
+    return {'max_abs_diff': max_diff, 'outputs_compared': compared, 'passed': max_diff <= PARITY_TOLERANCE}
+
+
+def check_parity(model, sample, onnx_path):
+    """
+    Compare the outputs of the source model and the exported ONNX model on a sample input
+    and write parity.json next to the ONNX file for Axon to record in provenance.
+    Does nothing unless Axon requested a parity check. Returns the report.
+    """
+    if not PARITY_ENABLED:
+        return None
+
+    print('🔍 Checking ONNX output parity with the source model...')
+    report = {'tolerance': PARITY_TOLERANCE}
+    if hasattr(model, 'eval'):
+        model.eval()
+    try:
+        report.update(_compare_outputs(model, sample, onnx_path))
+    except ImportError as e:
+        report['skipped'] = f'missing dependency: {e}'
+    except Exception as e:
+        report['skipped'] = str(e)
+
+    if 'skipped' in report:
+        print(f'⚠️  Parity check skipped: {report["skipped"]}')
+    elif report['passed']:
+        print(f'   Max deviation: {report["max_abs_diff"]:.3g} (tolerance {PARITY_TOLERANCE:g})')
+    else:
+        print(f'❌ Outputs deviate from the source model: {report.get("error") or report["max_abs_diff"]}')
+
+    _write_parity_report(os.path.dirname(onnx_path) or '.', report)
+    return report
+
+
+def skip_parity(output_dir, reason):
+    """Record that a requested parity check could not run for this export."""
+    if not PARITY_ENABLED:
+        return
+    print(f'⚠️  Parity check skipped: {reason}')
+    _write_parity_report(output_dir, {'tolerance': PARITY_TOLERANCE, 'skipped': reason})
+
+
+def _write_parity_report(output_dir, report):
+    with open(os.path.join(output_dir, PARITY_REPORT), 'w') as f:
+        json.dump(report, f, indent=2)
+
+
+# Weight precision requested by Axon (--precision): fp32 leaves the export as it is
+ONNX_PRECISION = os.environ.get('AXON_ONNX_PRECISION', 'fp32')
+
+
+def apply_precision(output_path):
+    """
+    Convert the exported ONNX files next to output_path to the requested precision:
+    fp16 casts float weights with onnxconverter-common (keeping fp32 inputs and outputs),
+    int8 quantizes the weights dynamically with onnxruntime. Returns False on failure.
+    """
+    if ONNX_PRECISION == 'fp32':
+        return True
+
+    onnx_files = find_onnx_files(os.path.dirname(output_path) or '.')
+    print(f'🔄 Converting {len(onnx_files)} ONNX file(s) to {ONNX_PRECISION}...')
+    try:
+        for path in onnx_files:
+            if ONNX_PRECISION == 'fp16':
+                import onnx
+                from onnxconverter_common import float16
+                onnx.save(float16.convert_float_to_float16(onnx.load(path), keep_io_types=True), path)
+            elif ONNX_PRECISION == 'int8':
+                from onnxruntime.quantization import quantize_dynamic, QuantType
+                quantize_dynamic(path, path + '.tmp', weight_type=QuantType.QInt8)
+                os.replace(path + '.tmp', path)
+            else:
+                print(f'❌ ERROR: Unsupported precision: {ONNX_PRECISION}')
+                return False
+    except ImportError as e:
+        print(f'❌ ERROR: Missing dependency for {ONNX_PRECISION}: {str(e)}')
+        print('   Install with: pip install onnx onnxconverter-common onnxruntime')
+        return False
+    except Exception as e:
+        print(f'❌ ERROR: {ONNX_PRECISION} conversion failed: {str(e)}')
+        return False
+    print(f'✅ Converted to {ONNX_PRECISION}')
+    return True
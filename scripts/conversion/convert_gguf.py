@@ -0,0 +1,70 @@
+#!/usr/bin/env python3
+"""
+Hugging Face to GGUF conversion for Axon.
+
+Converts a Hugging Face (safetensors or PyTorch) LLM directory to GGUF using
+llama.cpp's convert_hf_to_gguf.py, then quantizes it with llama-quantize.
+
+Usage:
+    convert_gguf.py <model_path> <output_path> <quant>
+
+    quant: f16, q8_0, q6_k, q5_k_m, q5_k_s, q5_0, q4_k_m, q4_k_s, q4_0
+"""
+
+import os
+import subprocess
+import sys
+
+LLAMA_CPP_DIR = os.environ.get('LLAMA_CPP_DIR', '/opt/llama.cpp')
+QUANTIZE_BIN = os.environ.get('LLAMA_QUANTIZE', 'llama-quantize')
+
+
+def run(cmd):
+    """Run a command, streaming its output, and return True on success."""
+    print(f'   $ {" ".join(cmd)}')
+    return subprocess.run(cmd).returncode == 0
+
+
+def convert_to_gguf(model_path, output_path, quant):
+    """Convert model_path to a GGUF file at output_path with the given quantization."""
+    convert_script = os.path.join(LLAMA_CPP_DIR, 'convert_hf_to_gguf.py')
+    if not os.path.exists(convert_script):
+        print(f'❌ ERROR: llama.cpp converter not found at {convert_script}')
+        return False
+
+    if not os.path.exists(os.path.join(model_path, 'config.json')):
+        print(f'❌ ERROR: {model_path} is not a Hugging Face model directory (no config.json)')
+        return False
+
+    os.makedirs(os.path.dirname(output_path) or '.', exist_ok=True)
+    quant = quant.lower()
+
+    # f16 needs no quantization step
+    f16_path = output_path if quant == 'f16' else output_path + '.f16.tmp'
+
+    print(f'🔄 Converting {model_path} to GGUF (f16)...')
+    if not run([sys.executable, convert_script, model_path, '--outfile', f16_path, '--outtype', 'f16']):
+        print('❌ ERROR: convert_hf_to_gguf.py failed')
+        return False
+
+    if quant != 'f16':
+        print(f'🔄 Quantizing to {quant.upper()}...')
+        ok = run([QUANTIZE_BIN, f16_path, output_path, quant.upper()])
+        os.remove(f16_path)
+        if not ok:
+            print('❌ ERROR: llama-quantize failed')
+            if os.path.exists(output_path):
+                os.remove(output_path)
+            return False
+
+    print(f'✅ GGUF written to {output_path}')
+    return True
+
+
+if __name__ == "__main__":
+    if len(sys.argv) != 4:
+        print("Usage: convert_gguf.py <model_path> <output_path> <quant>")
+        sys.exit(1)
+
+    success = convert_to_gguf(sys.argv[1], sys.argv[2], sys.argv[3])
+    sys.exit(0 if success else 1)
@@ -56,6 +56,18 @@ import warnings
 warnings.filterwarnings('ignore')
 os.environ['TF_CPP_MIN_LOG_LEVEL'] = '3'
 
+# Export options passed by Axon (--opset, --task, --dynamic-axes)
+ONNX_OPSET = int(os.environ.get('AXON_ONNX_OPSET', '14'))
+ONNX_TASK = os.environ.get('AXON_ONNX_TASK') or None
+ONNX_DYNAMIC_AXES = os.environ.get('AXON_ONNX_DYNAMIC_AXES', '1') != '0'
+# Safe mode (used when retrying after a timeout): no constant folding, shorter sequences
+ONNX_SAFE_MODE = os.environ.get('AXON_ONNX_SAFE_MODE', '0') == '1'
+MAX_SEQ_LEN = 32 if ONNX_SAFE_MODE else 128
+
+# Shared multi-encoder and parity check helpers
+sys.path.insert(0, os.path.dirname(os.path.abspath(__file__)))
+from convert_common import find_onnx_files, write_multi_encoder_manifest, check_parity, skip_parity, apply_precision, PARITY_ENABLED
+
 # Task mapping from model architecture/config to Optimum task
 # This is used when task='auto' fails (especially for local directories)
 # Comprehensive mapping for vision, NLP, audio, and multimodal models
@@ -437,7 +449,7 @@ def try_optimum_export(model_path, output_path, hf_model_id, task=None):
             model_name_or_path=model_name_or_path,
             output=output_dir,
             task=task,
-            opset=14,
+            opset=ONNX_OPSET,
             device='cpu',
             fp16=False,
         )
@@ -483,8 +495,7 @@ def try_optimum_export(model_path, output_path, hf_model_id, task=None):
         return False
 
 
-# find_onnx_files and write_multi_encoder_manifest are now imported from convert_common
-# (or use fallback implementations defined above)
+# find_onnx_files and write_multi_encoder_manifest are imported from convert_common
 
 
 def load_model_and_tokenizer(model_path, hf_model_id, task=None):
@@ -654,7 +665,7 @@ def create_dummy_input(model, task, processor=None):
     
     else:
         # NLP models - text input
-        seq_len = min(128, getattr(config, 'max_position_embeddings', 128))
+        seq_len = min(MAX_SEQ_LEN, getattr(config, 'max_position_embeddings', MAX_SEQ_LEN))
         vocab_size = getattr(config, 'vocab_size', 30522)
         
         print(f'   Creating text input: (1, {seq_len}), vocab_size={vocab_size}')
@@ -689,9 +700,9 @@ def try_torch_export(model, dummy_input, output_path, input_names, dynamic_axes,
                 output_path,
                 input_names=input_names,
                 output_names=['logits'],
-                dynamic_axes=dynamic_axes,
-                opset_version=14,
-                do_constant_folding=True,
+                dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+                opset_version=ONNX_OPSET,
+                do_constant_folding=not ONNX_SAFE_MODE,
                 export_params=True,
                 verbose=False,
             )
@@ -712,9 +723,9 @@ def try_torch_export(model, dummy_input, output_path, input_names, dynamic_axes,
                 output_path,
                 input_names=input_names,
                 output_names=output_names,
-                dynamic_axes=dynamic_axes,
-                opset_version=14,
-                do_constant_folding=True,
+                dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+                opset_version=ONNX_OPSET,
+                do_constant_folding=not ONNX_SAFE_MODE,
                 export_params=True,
                 verbose=False,
             )
@@ -755,9 +766,9 @@ def try_torch_jit_trace(model, dummy_input, output_path, input_names, dynamic_ax
             output_path,
             input_names=input_names,
             output_names=['output'],
-            dynamic_axes=dynamic_axes,
-            opset_version=14,
-            do_constant_folding=True,
+            dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+            opset_version=ONNX_OPSET,
+            do_constant_folding=not ONNX_SAFE_MODE,
         )
         
         if os.path.exists(output_path):
@@ -771,6 +782,23 @@ def try_torch_jit_trace(model, dummy_input, output_path, input_names, dynamic_ax
         return False
 
 
+def check_optimum_parity(model_path, output_path, hf_model_id, task):
+    """Load the source model and compare it with a single-file Optimum export."""
+    if not PARITY_ENABLED:
+        return
+    output_dir = os.path.dirname(output_path) or '.'
+    if len(find_onnx_files(output_dir)) > 1 or not os.path.exists(output_path):
+        skip_parity(output_dir, 'multi-file exports are not compared')
+        return
+    try:
+        model, processor, task = load_model_and_tokenizer(model_path, hf_model_id, task)
+        dummy_input, _, _ = create_dummy_input(model, task, processor)
+    except Exception as e:
+        skip_parity(output_dir, f'failed to load the source model: {e}')
+        return
+    check_parity(model, dummy_input, output_path)
+
+
 def convert_huggingface_to_onnx(model_path, output_path, axon_model_id):
     """Convert a Hugging Face model to ONNX using multiple strategies."""
     try:
@@ -784,11 +812,12 @@ def convert_huggingface_to_onnx(model_path, output_path, axon_model_id):
         print(f'📦 Converting model: {hf_model_id} (Axon ID: {axon_model_id})')
         
         # Detect task first
-        detected_task = detect_task_from_config(model_path)
+        detected_task = ONNX_TASK or detect_task_from_config(model_path)
         print(f'   Detected task: {detected_task}')
         
         # Strategy 1: Try Optimum first (doesn't need model loading)
         if try_optimum_export(model_path, output_path, hf_model_id, task=detected_task):
+            check_optimum_parity(model_path, output_path, hf_model_id, detected_task)
             return True
         
         # Load model for other strategies
@@ -807,6 +836,7 @@ def convert_huggingface_to_onnx(model_path, output_path, axon_model_id):
         
         for strategy in strategies:
             if strategy():
+                check_parity(model, dummy_input, output_path)
                 return True
         
         # All strategies failed
@@ -833,5 +863,5 @@ if __name__ == "__main__":
     output_path = sys.argv[2]
     axon_model_id = sys.argv[3]
 
-    success = convert_huggingface_to_onnx(model_path, output_path, axon_model_id)
+    success = convert_huggingface_to_onnx(model_path, output_path, axon_model_id) and apply_precision(output_path)
     sys.exit(0 if success else 1)
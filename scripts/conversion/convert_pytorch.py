@@ -19,11 +19,30 @@ import warnings
 
 warnings.filterwarnings('ignore')
 
+# Export options passed by Axon (--opset, --task, --dynamic-axes)
+ONNX_OPSET = int(os.environ.get('AXON_ONNX_OPSET', '14'))
+ONNX_TASK = os.environ.get('AXON_ONNX_TASK') or None
+ONNX_DYNAMIC_AXES = os.environ.get('AXON_ONNX_DYNAMIC_AXES', '1') != '0'
+# Safe mode (used when retrying after a timeout): no constant folding, shorter sequences
+ONNX_SAFE_MODE = os.environ.get('AXON_ONNX_SAFE_MODE', '0') == '1'
+MAX_SEQ_LEN = 32 if ONNX_SAFE_MODE else 128
+
 # Import shared utilities for multi-encoder support
 try:
     sys.path.insert(0, os.path.dirname(os.path.abspath(__file__)))
-    from convert_common import find_onnx_files, write_multi_encoder_manifest
+    from convert_common import find_onnx_files, write_multi_encoder_manifest, check_parity, skip_parity, apply_precision
 except ImportError:
+    # Without convert_common no parity report is written; Axon records the check as skipped
+    def check_parity(model, sample, onnx_path):
+        return None
+
+    def skip_parity(output_dir, reason):
+        pass
+
+    # Without convert_common exports stay fp32, so other precisions fail
+    def apply_precision(output_path):
+        return os.environ.get('AXON_ONNX_PRECISION', 'fp32') == 'fp32'
+
     # Fallback if convert_common not available
     def find_onnx_files(directory):
         onnx_files = []
@@ -138,12 +157,12 @@ def create_dummy_input(model, model_type, task):
     elif model_type == "nlp":
         # NLP input - try to get vocab size from model if available
         vocab_size = 30522  # Default BERT vocab size
-        seq_len = 128
+        seq_len = MAX_SEQ_LEN
         
         # Try to detect from model
         if hasattr(model, 'config'):
             vocab_size = getattr(model.config, 'vocab_size', vocab_size)
-            seq_len = min(128, getattr(model.config, 'max_position_embeddings', seq_len))
+            seq_len = min(MAX_SEQ_LEN, getattr(model.config, 'max_position_embeddings', seq_len))
         elif hasattr(model, 'vocab_size'):
             vocab_size = model.vocab_size
         
@@ -189,8 +208,8 @@ def try_optimum_export_pytorch(model_path, output_path, model_id):
                 main_export(
                     model_name_or_path=model_id,
                     output=output_dir,
-                    task='auto',
-                    opset=14,
+                    task=ONNX_TASK or 'auto',
+                    opset=ONNX_OPSET,
                     device='cpu',
                     fp16=False,
                 )
@@ -271,13 +290,14 @@ def try_pytorch_hub_load(model_id, output_path):
                     output_path,
                     input_names=input_names,
                     output_names=['output'],
-                    dynamic_axes=dynamic_axes,
-                    opset_version=14,
-                    do_constant_folding=True,
+                    dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+                    opset_version=ONNX_OPSET,
+                    do_constant_folding=not ONNX_SAFE_MODE,
                 )
                 
                 if os.path.exists(output_path):
                     print('✅ SUCCESS (PyTorch Hub)')
+                    check_parity(model, dummy_input, output_path)
                     return True
         
         return False
@@ -316,13 +336,14 @@ def try_load_torch_script(model_path, output_path):
             output_path,
             input_names=input_names,
             output_names=['output'],
-            dynamic_axes=dynamic_axes,
-            opset_version=14,
-            do_constant_folding=True,
+            dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+            opset_version=ONNX_OPSET,
+            do_constant_folding=not ONNX_SAFE_MODE,
         )
         
         if os.path.exists(output_path):
             print('✅ SUCCESS (TorchScript)')
+            check_parity(model, dummy_input, output_path)
             return True
         
         return False
@@ -380,13 +401,14 @@ def try_load_state_dict(model_path, output_path):
             output_path,
             input_names=input_names,
             output_names=['output'],
-            dynamic_axes=dynamic_axes,
-            opset_version=14,
-            do_constant_folding=True,
+            dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+            opset_version=ONNX_OPSET,
+            do_constant_folding=not ONNX_SAFE_MODE,
         )
         
         if os.path.exists(output_path):
             print('✅ SUCCESS (state dict)')
+            check_parity(model, dummy_input, output_path)
             return True
         
         return False
@@ -420,13 +442,14 @@ def try_torchvision_model(model_id, output_path):
                 output_path,
                 input_names=input_names,
                 output_names=['output'],
-                dynamic_axes=dynamic_axes,
-                opset_version=14,
-                do_constant_folding=True,
+                dynamic_axes=dynamic_axes if ONNX_DYNAMIC_AXES else None,
+                opset_version=ONNX_OPSET,
+                do_constant_folding=not ONNX_SAFE_MODE,
             )
             
             if os.path.exists(output_path):
                 print('✅ SUCCESS (torchvision)')
+                check_parity(model, dummy_input, output_path)
                 return True
         
         return False
@@ -455,8 +478,10 @@ def convert_pytorch_to_onnx(model_path, output_path, axon_model_id):
             onnx_files = find_onnx_files(output_dir)
             if len(onnx_files) > 1:
                 # Multi-encoder model - manifest already created by Optimum
+                skip_parity(output_dir, 'multi-file exports are not compared')
                 return True
             elif len(onnx_files) == 1 and os.path.exists(output_path):
+                skip_parity(output_dir, 'Optimum exports of PyTorch models are not compared')
                 return True
         
         # Try strategies in order
@@ -505,5 +530,5 @@ if __name__ == "__main__":
     output_path = sys.argv[2]
     axon_model_id = sys.argv[3]
     
-    success = convert_pytorch_to_onnx(model_path, output_path, axon_model_id)
+    success = convert_pytorch_to_onnx(model_path, output_path, axon_model_id) and apply_precision(output_path)
     sys.exit(0 if success else 1)
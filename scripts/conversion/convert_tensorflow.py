@@ -19,11 +19,18 @@ import warnings
 warnings.filterwarnings('ignore')
 os.environ['TF_CPP_MIN_LOG_LEVEL'] = '3'
 
+# Export options passed by Axon (--opset)
+ONNX_OPSET = int(os.environ.get('AXON_ONNX_OPSET', '14'))
+
 # Import shared utilities for multi-encoder support
 try:
     sys.path.insert(0, os.path.dirname(os.path.abspath(__file__)))
-    from convert_common import find_onnx_files, write_multi_encoder_manifest
+    from convert_common import find_onnx_files, write_multi_encoder_manifest, apply_precision
 except ImportError:
+    # Without convert_common exports stay fp32, so other precisions fail
+    def apply_precision(output_path):
+        return os.environ.get('AXON_ONNX_PRECISION', 'fp32') == 'fp32'
+
     # Fallback if convert_common not available
     def find_onnx_files(directory):
         onnx_files = []
@@ -89,7 +96,7 @@ def try_saved_model_conversion(model_path, output_path):
         print(f'   Converting SavedModel to ONNX...')
         model_proto, external_tensor_storage = tf2onnx.convert.from_saved_model(
             model_path,
-            opset=14,
+            opset=ONNX_OPSET,
             output_path=output_path,
         )
         
@@ -131,7 +138,7 @@ def try_keras_h5_conversion(model_path, output_path):
         model_proto, external_tensor_storage = tf2onnx.convert.from_keras(
             model,
             input_signature=spec,
-            opset=14,
+            opset=ONNX_OPSET,
             output_path=output_path,
         )
         
@@ -169,7 +176,7 @@ def try_keras_model_conversion(model_path, output_path):
         model_proto, external_tensor_storage = tf2onnx.convert.from_keras(
             model,
             input_signature=spec,
-            opset=14,
+            opset=ONNX_OPSET,
             output_path=output_path,
         )
         
@@ -213,7 +220,7 @@ def try_tfhub_model(model_id, output_path):
             print('   Converting to ONNX...')
             model_proto, external_tensor_storage = tf2onnx.convert.from_function(
                 func,
-                opset=14,
+                opset=ONNX_OPSET,
                 output_path=output_path,
             )
             
@@ -292,5 +299,5 @@ if __name__ == "__main__":
     output_path = sys.argv[2]
     axon_model_id = sys.argv[3]
     
-    success = convert_tensorflow_to_onnx(model_path, output_path, axon_model_id)
+    success = convert_tensorflow_to_onnx(model_path, output_path, axon_model_id) and apply_precision(output_path)
     sys.exit(0 if success else 1)
@@ -2,14 +2,39 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	bolt "go.etcd.io/bbolt"
+	berrors "go.etcd.io/bbolt/errors"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/internal/registry/builtin"
+	"github.com/mlOS-foundation/axon/internal/registry/core"
+	"github.com/mlOS-foundation/axon/pkg/spec"
+	"github.com/mlOS-foundation/axon/pkg/types"
 )
 
 func main() {
@@ -18,22 +43,92 @@ func main() {
 		registryDir = os.Args[1]
 	}
 
+	// Client address allowlist and mTLS, for registries on shared networks
+	access, err := loadAccessConfig()
+	if err != nil {
+		log.Fatalf("invalid access configuration: %v", err)
+	}
+	tlsConfig, err := access.TLSConfig()
+	if err != nil {
+		log.Fatalf("invalid TLS configuration: %v", err)
+	}
+
+	// Build the search index once; uploads, deletes and the retention GC update it as
+	// they change the registry, and a slow refresh picks up manifests edited on disk
+	index, err := openSearchIndex(registryDir, filepath.Join(registryDir, indexFileName))
+	if err != nil {
+		log.Fatalf("failed to load search index: %v", err)
+	}
+	if err := index.Refresh(); err != nil {
+		log.Fatalf("failed to build search index: %v", err)
+	}
+	go index.Watch(indexRefreshInterval, nil)
+
+	// Retention policy for old package versions (retention.yaml in the registry directory)
+	policy, err := loadRetentionPolicy(registryDir)
+	if err != nil {
+		log.Fatalf("failed to load retention policy: %v", err)
+	}
+	if policy.Enabled() && policy.interval > 0 {
+		go runRetentionSchedule(registryDir, policy, index)
+	}
+	http.HandleFunc("/admin/retention", retentionHandler(registryDir, policy, index))
+
+	// Uploads with PUT, for 'axon publish --registry'
+	upload, err := loadUploadConfig()
+	if err != nil {
+		log.Fatalf("invalid upload configuration: %v", err)
+	}
+
+	// Caching proxy for upstream repositories (AXON_REGISTRY_PROXY)
+	proxy := loadProxyConfig(registryDir, upload, index)
+
 	// Serve static files and web UI
 	http.HandleFunc("/", indexHandler(registryDir))
-	http.HandleFunc("/api/v1/search", searchHandler(registryDir))
-	http.HandleFunc("/api/v1/models/", manifestHandler(registryDir))
-	http.HandleFunc("/packages/", packageHandler(registryDir))
+	http.HandleFunc("/api/v1/search", searchHandler(index))
+	http.HandleFunc("/api/v1/index", indexJSONHandler(registryDir))
+	http.HandleFunc("/api/v1/models/", withDelete(
+		withUpload(withProxy(registryDir, proxy, manifestHandler(registryDir)), manifestUploadHandler(registryDir, upload, index)),
+		manifestDeleteHandler(registryDir, upload, index)))
+	http.HandleFunc("/packages/", withUpload(packageHandler(registryDir), packageUploadHandler(registryDir, upload)))
 
 	port := "8080"
 	if p := os.Getenv("PORT"); p != "" {
 		port = p
 	}
 
-	fmt.Printf("🚀 Starting local registry server on http://localhost:%s\n", port)
+	scheme := "http"
+	if access.TLS() {
+		scheme = "https"
+	}
+	server := &http.Server{
+		Addr:      ":" + port,
+		Handler:   allowlistHandler(access.allowlist, http.DefaultServeMux),
+		TLSConfig: tlsConfig,
+	}
+
+	fmt.Printf("🚀 Starting local registry server on %s://localhost:%s\n", scheme, port)
 	fmt.Printf("📁 Registry directory: %s\n", registryDir)
-	fmt.Printf("🌐 Web UI: http://localhost:%s\n", port)
-	fmt.Printf("🔍 API: http://localhost:%s/api/v1/search?q=<query>\n", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	fmt.Printf("🗂️  Indexed %d models\n", index.Len())
+	if policy.Enabled() {
+		fmt.Printf("🧹 Retention: %s\n", policy)
+	}
+	if restrictions := access.String(); restrictions != "" {
+		fmt.Printf("🔒 Access: %s\n", restrictions)
+	}
+	if upload.Enabled() {
+		fmt.Printf("📤 Uploads: enabled (PUT /api/v1/models/... and /packages/...)\n")
+	}
+	if proxy != nil {
+		fmt.Printf("🔁 Proxy: caching %s from upstream repositories\n", proxy)
+	}
+	fmt.Printf("🌐 Web UI: %s://localhost:%s\n", scheme, port)
+	fmt.Printf("🔍 API: %s://localhost:%s/api/v1/search?q=<query>\n", scheme, port)
+	fmt.Printf("📇 Index: %s://localhost:%s/api/v1/index\n", scheme, port)
+	if access.TLS() {
+		log.Fatal(server.ListenAndServeTLS(access.certFile, access.keyFile))
+	}
+	log.Fatal(server.ListenAndServe())
 }
 
 func indexHandler(registryDir string) http.HandlerFunc {
@@ -45,36 +140,7 @@ func indexHandler(registryDir string) http.HandlerFunc {
 		}
 
 		// List all models
-		models := []map[string]interface{}{}
-		manifestsDir := filepath.Join(registryDir, "api/v1/models")
-
-		err := filepath.Walk(manifestsDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			if info.IsDir() || !strings.HasSuffix(path, "manifest.yaml") {
-				return nil
-			}
-
-			// Extract namespace/name/version from path
-			relPath, _ := filepath.Rel(manifestsDir, path)
-			parts := strings.Split(relPath, string(filepath.Separator))
-			if len(parts) >= 3 {
-				namespace := parts[0]
-				name := parts[1]
-				version := strings.TrimSuffix(parts[2], "/manifest.yaml")
-
-				models = append(models, map[string]interface{}{
-					"namespace":   namespace,
-					"name":        name,
-					"version":     version,
-					"description": fmt.Sprintf("%s/%s model", namespace, name),
-					"manifestUrl": fmt.Sprintf("/api/v1/models/%s/%s/%s/manifest.yaml", namespace, name, version),
-				})
-			}
-			return nil
-		})
-
+		models, err := listModels(registryDir)
 		if err != nil {
 			http.Error(w, fmt.Sprintf("error listing models: %v", err), http.StatusInternalServerError)
 			return
@@ -365,92 +431,720 @@ func indexHandler(registryDir string) http.HandlerFunc {
 			return
 		}
 
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 		data := struct {
 			Models []map[string]interface{}
 		}{
 			Models: models,
 		}
-		if err := t.Execute(w, data); err != nil {
+		var page bytes.Buffer
+		if err := t.Execute(&page, data); err != nil {
 			http.Error(w, fmt.Sprintf("error rendering template: %v", err), http.StatusInternalServerError)
 			return
 		}
+		serveContent(w, r, "text/html; charset=utf-8", page.Bytes())
+	}
+}
+
+// listModels returns every model manifest in the registry
+func listModels(registryDir string) ([]map[string]interface{}, error) {
+	models := []map[string]interface{}{}
+	manifestsDir := filepath.Join(registryDir, "api/v1/models")
+
+	err := filepath.Walk(manifestsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		// Extract namespace/name/version from path
+		relPath, _ := filepath.Rel(manifestsDir, path)
+		if namespace, name, version, ok := splitManifestKey(relPath); ok {
+			models = append(models, map[string]interface{}{
+				"namespace":   namespace,
+				"name":        name,
+				"version":     version,
+				"description": fmt.Sprintf("%s/%s model", namespace, name),
+				"manifestUrl": fmt.Sprintf("/api/v1/models/%s/%s/%s/manifest.yaml", namespace, name, version),
+			})
+		}
+		return nil
+	})
+	return models, err
+}
+
+// indexJSONHandler serves the list of all models, so clients can check for updates
+// with a single (conditional) request
+func indexJSONHandler(registryDir string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		models, err := listModels(registryDir)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error listing models: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		data, err := json.Marshal(models)
+		if err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		serveContent(w, r, "application/json", data)
 	}
 }
 
-func searchHandler(registryDir string) http.HandlerFunc {
+// searchHandler searches the index: q is matched against model names, namespaces,
+// descriptions, tags, frameworks, formats, licenses and tasks, and framework, format and
+// license filter the results. At least one of them is required.
+func searchHandler(index *searchIndex) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		query := r.URL.Query().Get("q")
-		if query == "" {
+		params := r.URL.Query()
+		query := params.Get("q")
+		filter := searchFilter{
+			Framework: params.Get("framework"),
+			Format:    params.Get("format"),
+			License:   params.Get("license"),
+		}
+		if query == "" && filter == (searchFilter{}) {
 			http.Error(w, "query parameter 'q' is required", http.StatusBadRequest)
 			return
 		}
 
-		// Search for manifests matching the query
+		// Search the index for models matching the query, best matches first
 		results := []map[string]interface{}{}
-		manifestsDir := filepath.Join(registryDir, "api/v1/models")
+		for _, model := range index.Find(query, filter) {
+			description := model.Description
+			if description == "" {
+				description = fmt.Sprintf("%s/%s model", model.Namespace, model.Name)
+			}
+			results = append(results, map[string]interface{}{
+				"namespace":   model.Namespace,
+				"name":        model.Name,
+				"version":     model.Version,
+				"description": description,
+				"framework":   model.Framework,
+				"format":      model.Format,
+				"license":     model.License,
+				"tags":        model.Tags,
+			})
+		}
 
-		err := filepath.Walk(manifestsDir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if err := json.NewEncoder(w).Encode(results); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// indexRefreshInterval is how often the search index picks up manifests added, changed or
+// removed on disk rather than through the API, which updates it immediately
+const indexRefreshInterval = time.Minute
+
+// indexFileName is the search index database (bbolt) in the registry directory, so a
+// restart only re-reads the manifests that changed while the server was down
+const indexFileName = ".search-index.db"
+
+// indexFormatVersion is bumped when the database layout changes; older databases are
+// cleared and rebuilt
+const indexFormatVersion = "2"
+
+// Buckets of the search index database
+var (
+	metaBucket     = []byte("meta")     // "version" -> indexFormatVersion
+	modelsBucket   = []byte("models")   // Manifest key -> indexedModel as JSON
+	trigramsBucket = []byte("trigrams") // Trigram -> bucket of the keys of the models that have it
+)
+
+// indexedModel is a model manifest in the search index
+type indexedModel struct {
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	Description string    `json:"description,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	Framework   string    `json:"framework,omitempty"`
+	Format      string    `json:"format,omitempty"`
+	License     string    `json:"license,omitempty"`
+	Task        string    `json:"task,omitempty"`
+	ModTime     time.Time `json:"mod_time"` // Of the manifest when it was indexed
+}
+
+// searchFilter restricts search results to exact (case-insensitive) field values; empty
+// fields don't filter
+type searchFilter struct {
+	Framework string
+	Format    string
+	License   string
+}
+
+func (f searchFilter) matches(model *indexedModel) bool {
+	return (f.Framework == "" || strings.EqualFold(f.Framework, model.Framework)) &&
+		(f.Format == "" || strings.EqualFold(f.Format, model.Format)) &&
+		(f.License == "" || strings.EqualFold(f.License, model.License))
+}
+
+// searchIndex is a trigram index over the text fields of model manifests, kept in a bbolt
+// database. Searches match every query term as a substring, case-insensitively, like a
+// scan of every manifest, without reading the manifests. Refresh re-indexes only
+// manifests that were added, changed or removed, and the database outlives the server,
+// so a restarted server doesn't re-read every manifest either.
+type searchIndex struct {
+	registryDir string
+	db          *bolt.DB
+
+	mu sync.Mutex // Serializes updates, so a refresh can't undo a concurrent publish
+}
+
+// openSearchIndex opens the search index of the registry in registryDir, kept in the
+// database at path. A database that is unreadable or has an older layout is rebuilt.
+func openSearchIndex(registryDir, path string) (*searchIndex, error) {
+	options := &bolt.Options{Timeout: time.Second}
+	db, err := bolt.Open(path, 0644, options)
+	if err != nil && !errors.Is(err, berrors.ErrTimeout) {
+		log.Printf("rebuilding search index: %s is unreadable (%v)", path, err)
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove search index: %w", err)
+		}
+		db, err = bolt.Open(path, 0644, options)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if meta := tx.Bucket(metaBucket); meta != nil && string(meta.Get([]byte("version"))) != indexFormatVersion {
+			log.Printf("rebuilding search index: %s is outdated", path)
+			for _, name := range [][]byte{metaBucket, modelsBucket, trigramsBucket} {
+				if err := tx.DeleteBucket(name); err != nil && !errors.Is(err, berrors.ErrBucketNotFound) {
+					return err
+				}
 			}
-			if info.IsDir() || !strings.HasSuffix(path, "manifest.yaml") {
-				return nil
+		}
+		for _, name := range [][]byte{metaBucket, modelsBucket, trigramsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
 			}
+		}
+		return tx.Bucket(metaBucket).Put([]byte("version"), []byte(indexFormatVersion))
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize search index: %w", err)
+	}
+	return &searchIndex{registryDir: registryDir, db: db}, nil
+}
+
+// Close closes the index database
+func (idx *searchIndex) Close() error {
+	return idx.db.Close()
+}
+
+// Len returns the number of indexed models
+func (idx *searchIndex) Len() int {
+	n := 0
+	_ = idx.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(modelsBucket).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// Refresh scans the manifests directory and updates the index for manifests that were
+// published, modified or deleted since the last refresh. Only those manifests are read.
+func (idx *searchIndex) Refresh() error {
+	manifestsDir := filepath.Join(idx.registryDir, "api/v1/models")
+	found := make(map[string]time.Time)
+
+	err := filepath.Walk(manifestsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, _ := filepath.Rel(manifestsDir, path)
+		if _, _, _, ok := splitManifestKey(relPath); ok {
+			found[relPath] = info.ModTime()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
 
-			// Extract namespace/name/version from path
-			relPath, _ := filepath.Rel(manifestsDir, path)
-			parts := strings.Split(relPath, string(filepath.Separator))
-			if len(parts) >= 3 {
-				namespace := parts[0]
-				name := parts[1]
-				version := strings.TrimSuffix(parts[2], "/manifest.yaml")
-
-				// Simple search - check if query matches name or namespace
-				if strings.Contains(strings.ToLower(name), strings.ToLower(query)) ||
-					strings.Contains(strings.ToLower(namespace), strings.ToLower(query)) {
-					results = append(results, map[string]interface{}{
-						"namespace":   namespace,
-						"name":        name,
-						"version":     version,
-						"description": fmt.Sprintf("%s/%s model", namespace, name),
-					})
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	// Compare the modification times in the index with the manifests'
+	var stale []string
+	changed := make(map[string]*indexedModel)
+	err = idx.db.View(func(tx *bolt.Tx) error {
+		indexed := make(map[string]bool)
+		err := tx.Bucket(modelsBucket).ForEach(func(k, v []byte) error {
+			key := string(k)
+			indexed[key] = true
+			var model indexedModel
+			if modTime, ok := found[key]; !ok || json.Unmarshal(v, &model) != nil || !modTime.Equal(model.ModTime) {
+				stale = append(stale, key)
+				if ok {
+					changed[key] = nil
 				}
 			}
 			return nil
 		})
+		for key := range found {
+			if !indexed[key] {
+				changed[key] = nil
+			}
+		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read search index: %w", err)
+	}
+	if len(stale) == 0 && len(changed) == 0 {
+		return nil
+	}
 
-		if err != nil {
-			http.Error(w, fmt.Sprintf("error searching: %v", err), http.StatusInternalServerError)
-			return
+	// Read the new and changed manifests outside the transaction; searches go on meanwhile
+	for key := range changed {
+		changed[key] = readIndexedModel(manifestsDir, key, found[key])
+	}
+	err = idx.db.Update(func(tx *bolt.Tx) error {
+		for _, key := range stale {
+			if err := idx.remove(tx, key); err != nil {
+				return err
+			}
+		}
+		for key, model := range changed {
+			if err := idx.add(tx, key, model); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update search index: %w", err)
+	}
+	return nil
+}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		if err := json.NewEncoder(w).Encode(results); err != nil {
-			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+// manifestKey returns the path of a model version's manifest relative to the models
+// directory, which is also its index key
+func manifestKey(namespace, name, version string) string {
+	return filepath.Join(namespace, filepath.FromSlash(name), version, "manifest.yaml")
+}
+
+// splitManifestKey returns the model version of a manifest path relative to the models
+// directory: {namespace}/{name}/{version}/manifest.yaml, where the name may have several
+// segments, as proxied Hugging Face models (hf/microsoft/resnet-50) do
+func splitManifestKey(key string) (namespace, name, version string, ok bool) {
+	parts := strings.Split(key, string(filepath.Separator))
+	if len(parts) < 4 || parts[len(parts)-1] != "manifest.yaml" {
+		return "", "", "", false
+	}
+	return parts[0], strings.Join(parts[1:len(parts)-2], "/"), parts[len(parts)-2], true
+}
+
+// Publish indexes a version that was just published, so searches find it at once
+func (idx *searchIndex) Publish(namespace, name, version string) error {
+	manifestsDir := filepath.Join(idx.registryDir, "api/v1/models")
+	key := manifestKey(namespace, name, version)
+	info, err := os.Stat(filepath.Join(manifestsDir, key))
+	if err != nil {
+		return fmt.Errorf("failed to index %s/%s@%s: %w", namespace, name, version, err)
+	}
+	model := readIndexedModel(manifestsDir, key, info.ModTime())
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.db.Update(func(tx *bolt.Tx) error {
+		if err := idx.remove(tx, key); err != nil {
+			return err
+		}
+		return idx.add(tx, key, model)
+	})
+}
+
+// Unpublish drops a version that was just removed from the index
+func (idx *searchIndex) Unpublish(namespace, name, version string) error {
+	key := manifestKey(namespace, name, version)
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	return idx.db.Update(func(tx *bolt.Tx) error {
+		return idx.remove(tx, key)
+	})
+}
+
+// readIndexedModel reads the searchable fields of the manifest at key; the path gives
+// the model's ID, and a manifest that can't be parsed is indexed by it alone
+func readIndexedModel(manifestsDir, key string, modTime time.Time) *indexedModel {
+	namespace, name, version, _ := splitManifestKey(key)
+	model := &indexedModel{
+		Namespace: namespace,
+		Name:      name,
+		Version:   version,
+		ModTime:   modTime,
+	}
+
+	var manifest struct {
+		Metadata struct {
+			Description string   `yaml:"description"`
+			License     string   `yaml:"license"`
+			Tags        []string `yaml:"tags"`
+		} `yaml:"metadata"`
+		Spec struct {
+			Framework struct {
+				Name string `yaml:"name"`
+			} `yaml:"framework"`
+			Format struct {
+				Type string `yaml:"type"`
+			} `yaml:"format"`
+			Task string `yaml:"task"`
+		} `yaml:"spec"`
+	}
+	data, err := os.ReadFile(filepath.Join(manifestsDir, key))
+	if err != nil || yaml.Unmarshal(data, &manifest) != nil {
+		return model
+	}
+	model.Description = manifest.Metadata.Description
+	model.License = manifest.Metadata.License
+	model.Tags = manifest.Metadata.Tags
+	model.Framework = manifest.Spec.Framework.Name
+	model.Format = manifest.Spec.Format.Type
+	model.Task = manifest.Spec.Task
+	return model
+}
+
+// Watch refreshes the index every interval until stop is closed, as a fallback for
+// manifests edited on disk
+func (idx *searchIndex) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := idx.Refresh(); err != nil {
+				log.Printf("failed to refresh search index: %v", err)
+			}
+		case <-stop:
 			return
 		}
 	}
 }
 
+// Search returns the models matching query, best matches first
+func (idx *searchIndex) Search(query string) []indexedModel {
+	return idx.Find(query, searchFilter{})
+}
+
+// Find returns the models that match every whitespace-separated term of query in one of
+// their fields and pass the filter. Matches in names rank above matches in namespaces,
+// then tags, then the other fields; ties are ordered by path.
+func (idx *searchIndex) Find(query string, filter searchFilter) []indexedModel {
+	terms := strings.Fields(strings.ToLower(query))
+
+	type match struct {
+		key   string
+		score int
+		model indexedModel
+	}
+	var matches []match
+	err := idx.db.View(func(tx *bolt.Tx) error {
+		models := tx.Bucket(modelsBucket)
+		trigrams := tx.Bucket(trigramsBucket)
+
+		// Intersect the posting lists of the terms' trigrams; short terms check every model
+		var candidates map[string]struct{}
+		for _, term := range terms {
+			for _, trigram := range trigramsOf(term) {
+				postings := trigrams.Bucket([]byte(trigram))
+				switch {
+				case postings == nil:
+					candidates = make(map[string]struct{})
+				case candidates == nil:
+					candidates = make(map[string]struct{})
+					if err := postings.ForEach(func(k, _ []byte) error {
+						candidates[string(k)] = struct{}{}
+						return nil
+					}); err != nil {
+						return err
+					}
+				default:
+					for key := range candidates {
+						if k, _ := postings.Cursor().Seek([]byte(key)); string(k) != key {
+							delete(candidates, key)
+						}
+					}
+				}
+			}
+		}
+		if candidates == nil {
+			candidates = make(map[string]struct{})
+			if err := models.ForEach(func(k, _ []byte) error {
+				candidates[string(k)] = struct{}{}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		for key := range candidates {
+			var model indexedModel
+			if err := json.Unmarshal(models.Get([]byte(key)), &model); err != nil || !filter.matches(&model) {
+				continue
+			}
+			// Trigrams can match across fields, so confirm every term
+			score := 0
+			for _, term := range terms {
+				termScore := matchScore(&model, term)
+				if termScore == 0 {
+					score = -1
+					break
+				}
+				score += termScore
+			}
+			if score >= 0 {
+				matches = append(matches, match{key: key, score: score, model: model})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to search index: %v", err)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].key < matches[j].key
+	})
+
+	results := make([]indexedModel, len(matches))
+	for i, m := range matches {
+		results[i] = m.model
+	}
+	return results
+}
+
+// matchScore rates how well a lowercase term matches a model (0 if it doesn't)
+func matchScore(model *indexedModel, term string) int {
+	name := strings.ToLower(model.Name)
+	switch {
+	case name == term:
+		return 10
+	case strings.Contains(name, term):
+		return 5
+	case strings.Contains(strings.ToLower(model.Namespace), term):
+		return 3
+	}
+	for _, tag := range model.Tags {
+		if strings.Contains(strings.ToLower(tag), term) {
+			return 2
+		}
+	}
+	for _, field := range []string{model.Description, model.Framework, model.Format, model.License, model.Task} {
+		if strings.Contains(strings.ToLower(field), term) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// add indexes a model in an update transaction
+func (idx *searchIndex) add(tx *bolt.Tx, key string, model *indexedModel) error {
+	data, err := json.Marshal(model)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(modelsBucket).Put([]byte(key), data); err != nil {
+		return err
+	}
+	trigrams := tx.Bucket(trigramsBucket)
+	for _, trigram := range modelTrigrams(model) {
+		postings, err := trigrams.CreateBucketIfNotExists([]byte(trigram))
+		if err != nil {
+			return err
+		}
+		if err := postings.Put([]byte(key), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove drops a model, if it is indexed, in an update transaction
+func (idx *searchIndex) remove(tx *bolt.Tx, key string) error {
+	models := tx.Bucket(modelsBucket)
+	var model indexedModel
+	if err := json.Unmarshal(models.Get([]byte(key)), &model); err == nil {
+		trigrams := tx.Bucket(trigramsBucket)
+		for _, trigram := range modelTrigrams(&model) {
+			postings := trigrams.Bucket([]byte(trigram))
+			if postings == nil {
+				continue
+			}
+			if err := postings.Delete([]byte(key)); err != nil {
+				return err
+			}
+			if k, _ := postings.Cursor().First(); k == nil {
+				if err := trigrams.DeleteBucket([]byte(trigram)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return models.Delete([]byte(key))
+}
+
+// modelTrigrams returns the trigrams of a model's searchable fields
+func modelTrigrams(model *indexedModel) []string {
+	fields := append([]string{model.Name, model.Namespace, model.Description, model.Framework, model.Format, model.License, model.Task}, model.Tags...)
+	var trigrams []string
+	for _, field := range fields {
+		trigrams = append(trigrams, trigramsOf(strings.ToLower(field))...)
+	}
+	return trigrams
+}
+
+// trigramsOf returns the three-byte substrings of s (none if s is shorter)
+func trigramsOf(s string) []string {
+	if len(s) < 3 {
+		return nil
+	}
+	trigrams := make([]string, 0, len(s)-2)
+	for i := 0; i+3 <= len(s); i++ {
+		trigrams = append(trigrams, s[i:i+3])
+	}
+	return trigrams
+}
+
 func manifestHandler(registryDir string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Extract path: /api/v1/models/{namespace}/{name}/{version}/manifest.yaml
+		// Extract path: /api/v1/models/{namespace}/{name}/{version}/{manifest.yaml,attestation.json}
+		// or /api/v1/models/{namespace}/{name}/versions (also as versions.json, the file
+		// 'axon publish --registry-dir' writes for static hosting)
 		path := strings.TrimPrefix(r.URL.Path, "/api/v1/models/")
 		manifestPath := filepath.Join(registryDir, "api/v1/models", path)
 
-		// Check if file exists
-		if _, err := os.Stat(manifestPath); os.IsNotExist(err) {
+		// Versions are listed from the directories, which uploads and retention change
+		var data []byte
+		var err error
+		listing := filepath.Base(manifestPath) == "versions" || filepath.Base(manifestPath) == "versions.json"
+		if listing {
+			data, err = listVersions(filepath.Join(registryDir, "api/v1/models"), filepath.Dir(manifestPath))
+		} else {
+			data, err = os.ReadFile(manifestPath)
+		}
+		if os.IsNotExist(err) {
 			http.Error(w, "manifest not found", http.StatusNotFound)
 			return
 		}
+		if err != nil {
+			http.Error(w, fmt.Sprintf("error reading manifest: %v", err), http.StatusInternalServerError)
+			return
+		}
 
-		// Serve the YAML file
-		w.Header().Set("Content-Type", "application/x-yaml")
+		// Manifests are YAML; attestations published next to them are DSSE envelopes
+		contentType := "application/x-yaml"
+		switch {
+		case listing:
+			contentType = "application/json"
+		case filepath.Base(manifestPath) == "attestation.json":
+			contentType = "application/vnd.dsse.envelope.v1+json"
+		}
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		http.ServeFile(w, r, manifestPath)
+		serveContent(w, r, contentType, data)
+	}
+}
+
+// modelVersions is the version listing of a model
+type modelVersions struct {
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Versions  []string `json:"versions"` // Oldest first
+	Latest    string   `json:"latest"`   // The version @latest resolves to
+}
+
+// listVersions lists the versions of the model in modelDir, under manifestsDir, that have
+// a manifest, in version order, with the one latest resolves to
+func listVersions(manifestsDir, modelDir string) ([]byte, error) {
+	entries, err := os.ReadDir(modelDir)
+	if err != nil {
+		return nil, err
+	}
+	versions := []string{}
+	for _, entry := range entries {
+		if _, err := os.Stat(filepath.Join(modelDir, entry.Name(), "manifest.yaml")); err == nil {
+			versions = append(versions, entry.Name())
+		}
+	}
+	if len(versions) == 0 {
+		return nil, os.ErrNotExist
+	}
+	sortVersions(versions)
+	// The name may have several segments
+	relPath, err := filepath.Rel(manifestsDir, modelDir)
+	if err != nil {
+		return nil, err
+	}
+	namespace, name, _ := strings.Cut(filepath.ToSlash(relPath), "/")
+	return json.Marshal(modelVersions{
+		Namespace: namespace,
+		Name:      name,
+		Versions:  versions,
+		Latest:    latestVersion(versions),
+	})
+}
+
+// sortVersions orders versions oldest first: tags that aren't semantic versions by name,
+// then semantic versions by precedence
+func sortVersions(versions []string) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, errI := semver.NewVersion(versions[i])
+		vj, errJ := semver.NewVersion(versions[j])
+		switch {
+		case errI != nil && errJ != nil:
+			return versions[i] < versions[j]
+		case errI != nil || errJ != nil:
+			return errI != nil
+		case !vi.Equal(vj):
+			return vi.LessThan(vj)
+		}
+		return versions[i] < versions[j]
+	})
+}
+
+// latestVersion returns the version latest resolves to among sorted versions: the
+// highest release, else the highest prerelease, else a version named latest, else the
+// last tag
+func latestVersion(sorted []string) string {
+	var prerelease string
+	for i := len(sorted) - 1; i >= 0; i-- {
+		v, err := semver.NewVersion(sorted[i])
+		if err != nil {
+			continue
+		}
+		if v.Prerelease() == "" {
+			return sorted[i]
+		}
+		if prerelease == "" {
+			prerelease = sorted[i]
+		}
+	}
+	if prerelease != "" {
+		return prerelease
+	}
+	for _, version := range sorted {
+		if version == "latest" {
+			return version
+		}
 	}
+	return sorted[len(sorted)-1]
 }
 
 func packageHandler(registryDir string) http.HandlerFunc {
@@ -470,3 +1164,1210 @@ func packageHandler(registryDir string) http.HandlerFunc {
 		http.ServeFile(w, r, packagePath)
 	}
 }
+
+// minCompressSize is the smallest body worth compressing
+const minCompressSize = 512
+
+// serveContent writes body with an ETag, answering If-None-Match with 304 Not Modified,
+// and gzip-compresses it when the client accepts gzip. Manifests and the index are
+// re-fetched by every client on every update check, so unchanged responses cost no body.
+func serveContent(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	sum := sha256.Sum256(body)
+	etag := hex.EncodeToString(sum[:16])
+
+	gzipped := len(body) >= minCompressSize && acceptsGzip(r.Header.Get("Accept-Encoding"))
+	tag := `"` + etag + `"`
+	if gzipped {
+		// Each encoding is a different representation and needs its own entity tag
+		tag = `"` + etag + `-gzip"`
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", tag)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Add("Vary", "Accept-Encoding")
+
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if gzipped {
+		var compressed bytes.Buffer
+		gz := gzip.NewWriter(&compressed)
+		if _, err := gz.Write(body); err == nil && gz.Close() == nil {
+			w.Header().Set("Content-Encoding", "gzip")
+			body = compressed.Bytes()
+		} else {
+			w.Header().Set("ETag", `"`+etag+`"`)
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag in any encoding
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.Trim(strings.TrimPrefix(candidate, "W/"), `"`)
+		if strings.TrimSuffix(candidate, "-gzip") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip
+func acceptsGzip(acceptEncoding string) bool {
+	wildcard := false
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		coding := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		allowed := true
+		for _, param := range fields[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if value, err := strconv.ParseFloat(q, 64); err == nil && value == 0 {
+					allowed = false
+				}
+			}
+		}
+
+		switch coding {
+		case "gzip":
+			return allowed
+		case "*":
+			wildcard = allowed
+		}
+	}
+	return wildcard
+}
+
+// retentionFileName configures package retention, in the registry directory
+const retentionFileName = "retention.yaml"
+
+// retentionPolicy limits how many package versions the registry keeps. The newest
+// version of every model is always kept.
+type retentionPolicy struct {
+	KeepLast         int    `yaml:"keep_last"`          // Versions kept per model (0 = unlimited)
+	MaxAge           string `yaml:"max_age"`            // Versions older than this are removed, e.g. 720h
+	MaxNamespaceSize string `yaml:"max_namespace_size"` // Oldest versions are removed above this, e.g. 50GB
+	Interval         string `yaml:"interval"`           // How often the GC job runs, e.g. 1h (empty = only on demand)
+
+	maxAge           time.Duration
+	maxNamespaceSize int64
+	interval         time.Duration
+}
+
+// loadRetentionPolicy reads retention.yaml from the registry directory; without one,
+// nothing is ever removed
+func loadRetentionPolicy(registryDir string) (*retentionPolicy, error) {
+	policy := &retentionPolicy{}
+	data, err := os.ReadFile(filepath.Join(registryDir, retentionFileName))
+	if os.IsNotExist(err) {
+		return policy, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", retentionFileName, err)
+	}
+	return policy, policy.parse()
+}
+
+// parse validates the policy and parses its durations and sizes
+func (p *retentionPolicy) parse() error {
+	var err error
+	if p.KeepLast < 0 {
+		return fmt.Errorf("keep_last must not be negative")
+	}
+	if p.MaxAge != "" {
+		if p.maxAge, err = time.ParseDuration(p.MaxAge); err != nil {
+			return fmt.Errorf("invalid max_age: %w", err)
+		}
+	}
+	if p.MaxNamespaceSize != "" {
+		if p.maxNamespaceSize, err = parseSize(p.MaxNamespaceSize); err != nil {
+			return fmt.Errorf("invalid max_namespace_size: %w", err)
+		}
+	}
+	if p.Interval != "" {
+		if p.interval, err = time.ParseDuration(p.Interval); err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+	}
+	return nil
+}
+
+// Enabled reports whether the policy can remove anything
+func (p *retentionPolicy) Enabled() bool {
+	return p.KeepLast > 0 || p.maxAge > 0 || p.maxNamespaceSize > 0
+}
+
+func (p *retentionPolicy) String() string {
+	var rules []string
+	if p.KeepLast > 0 {
+		rules = append(rules, fmt.Sprintf("keep last %d versions", p.KeepLast))
+	}
+	if p.maxAge > 0 {
+		rules = append(rules, fmt.Sprintf("max age %s", p.maxAge))
+	}
+	if p.maxNamespaceSize > 0 {
+		rules = append(rules, fmt.Sprintf("max namespace size %s", p.MaxNamespaceSize))
+	}
+	if p.interval > 0 {
+		rules = append(rules, fmt.Sprintf("every %s", p.interval))
+	}
+	return strings.Join(rules, ", ")
+}
+
+// parseSize parses a size such as "50GB", "500M" or "1073741824" (binary units)
+func parseSize(value string) (int64, error) {
+	s := strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(value)), "B"), "I")
+	multiplier := int64(1)
+	if s != "" {
+		switch s[len(s)-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier != 1 {
+			s = s[:len(s)-1]
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", value)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// packageVersion is a published model version and its package
+type packageVersion struct {
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	PublishedAt time.Time `json:"published_at"`
+	Bytes       int64     `json:"bytes"`
+	Reason      string    `json:"reason,omitempty"`
+
+	manifestDir string
+	packagePath string
+}
+
+// retentionReport lists the versions removed (or, in a dry run, to be removed)
+type retentionReport struct {
+	DryRun         bool             `json:"dry_run"`
+	Removed        []packageVersion `json:"removed"`
+	ReclaimedBytes int64            `json:"reclaimed_bytes"`
+}
+
+// listPackageVersions returns every published version with its package size, using the
+// manifest modification time as the publication time
+func listPackageVersions(registryDir string) ([]packageVersion, error) {
+	manifestsDir := filepath.Join(registryDir, "api/v1/models")
+	var versions []packageVersion
+
+	err := filepath.Walk(manifestsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, _ := filepath.Rel(manifestsDir, path)
+		namespace, name, version, ok := splitManifestKey(relPath)
+		if !ok {
+			return nil
+		}
+
+		v := packageVersion{
+			Namespace:   namespace,
+			Name:        name,
+			Version:     version,
+			PublishedAt: info.ModTime(),
+			manifestDir: filepath.Dir(path),
+		}
+
+		var manifest struct {
+			Distribution struct {
+				Package struct {
+					URL string `yaml:"url"`
+				} `yaml:"package"`
+			} `yaml:"distribution"`
+		}
+		if data, err := os.ReadFile(path); err == nil && yaml.Unmarshal(data, &manifest) == nil && manifest.Distribution.Package.URL != "" {
+			v.packagePath = filepath.Join(registryDir, "packages", filepath.Base(manifest.Distribution.Package.URL))
+			if stat, err := os.Stat(v.packagePath); err == nil {
+				v.Bytes = stat.Size()
+			}
+		}
+		versions = append(versions, v)
+		return nil
+	})
+	return versions, err
+}
+
+// newerFirst orders versions of a model by publication time, then by semantic version
+func newerFirst(a, b packageVersion) bool {
+	if !a.PublishedAt.Equal(b.PublishedAt) {
+		return a.PublishedAt.After(b.PublishedAt)
+	}
+	va, errA := semver.NewVersion(a.Version)
+	vb, errB := semver.NewVersion(b.Version)
+	if errA == nil && errB == nil {
+		return va.GreaterThan(vb)
+	}
+	return a.Version > b.Version
+}
+
+// planRetention selects the versions the policy removes
+func planRetention(versions []packageVersion, policy *retentionPolicy, now time.Time) []packageVersion {
+	byModel := make(map[string][]packageVersion)
+	for _, v := range versions {
+		key := v.Namespace + "/" + v.Name
+		byModel[key] = append(byModel[key], v)
+	}
+
+	var removed []packageVersion
+	kept := make(map[string][]packageVersion) // Removable versions kept, by namespace
+	nsSize := make(map[string]int64)
+	for _, modelVersions := range byModel {
+		sort.Slice(modelVersions, func(i, j int) bool { return newerFirst(modelVersions[i], modelVersions[j]) })
+		for i, v := range modelVersions {
+			switch {
+			case i == 0:
+				// The newest version is always kept
+			case policy.KeepLast > 0 && i >= policy.KeepLast:
+				v.Reason = fmt.Sprintf("beyond the last %d versions", policy.KeepLast)
+			case policy.maxAge > 0 && now.Sub(v.PublishedAt) > policy.maxAge:
+				v.Reason = fmt.Sprintf("older than %s", policy.maxAge)
+			}
+			if v.Reason != "" {
+				removed = append(removed, v)
+				continue
+			}
+			nsSize[v.Namespace] += v.Bytes
+			if i > 0 {
+				kept[v.Namespace] = append(kept[v.Namespace], v)
+			}
+		}
+	}
+
+	// Remove the oldest remaining versions of namespaces over the size limit
+	if policy.maxNamespaceSize > 0 {
+		for ns, candidates := range kept {
+			sort.Slice(candidates, func(i, j int) bool { return candidates[i].PublishedAt.Before(candidates[j].PublishedAt) })
+			for _, v := range candidates {
+				if nsSize[ns] <= policy.maxNamespaceSize {
+					break
+				}
+				v.Reason = fmt.Sprintf("namespace %s exceeds %s", ns, policy.MaxNamespaceSize)
+				removed = append(removed, v)
+				nsSize[ns] -= v.Bytes
+			}
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		a, b := removed[i], removed[j]
+		if a.Namespace+"/"+a.Name != b.Namespace+"/"+b.Name {
+			return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
+		}
+		return newerFirst(a, b)
+	})
+	return removed
+}
+
+// applyRetention removes the versions the policy selects (only reports them on a dry run)
+func applyRetention(registryDir string, policy *retentionPolicy, dryRun bool, now time.Time) (*retentionReport, error) {
+	versions, err := listPackageVersions(registryDir)
+	if err != nil {
+		return nil, err
+	}
+	return removeVersions(versions, planRetention(versions, policy, now), dryRun)
+}
+
+// removeVersions removes some of the published versions (only reports them on a dry run).
+// A package is removed with the last version that references it: versions that were
+// published with the same package as a surviving version leave it in place.
+func removeVersions(versions, removed []packageVersion, dryRun bool) (*retentionReport, error) {
+	removing := make(map[string]bool)
+	for _, v := range removed {
+		removing[v.manifestDir] = true
+	}
+	referenced := make(map[string]bool) // Packages of the surviving versions
+	for _, v := range versions {
+		if !removing[v.manifestDir] && v.packagePath != "" {
+			referenced[v.packagePath] = true
+		}
+	}
+
+	report := &retentionReport{DryRun: dryRun, Removed: []packageVersion{}}
+	freed := make(map[string]bool)
+	for _, v := range removed {
+		free := v.packagePath != "" && !referenced[v.packagePath] && !freed[v.packagePath]
+		if !dryRun {
+			// Remove the manifest first so clients never see a manifest without its package
+			if err := os.RemoveAll(v.manifestDir); err != nil {
+				return report, fmt.Errorf("failed to remove %s/%s@%s: %w", v.Namespace, v.Name, v.Version, err)
+			}
+			if free {
+				_ = os.Remove(v.packagePath)
+				_ = os.Remove(v.packagePath + ".sha256")
+			}
+		}
+		report.Removed = append(report.Removed, v)
+		if free {
+			freed[v.packagePath] = true
+			report.ReclaimedBytes += v.Bytes
+		}
+	}
+	return report, nil
+}
+
+// runRetentionSchedule applies the retention policy every policy interval
+func runRetentionSchedule(registryDir string, policy *retentionPolicy, index *searchIndex) {
+	ticker := time.NewTicker(policy.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		report, err := applyRetention(registryDir, policy, false, time.Now())
+		if err != nil {
+			log.Printf("retention GC failed: %v", err)
+		}
+		if report != nil && len(report.Removed) > 0 {
+			log.Printf("retention GC removed %d versions (%d bytes)", len(report.Removed), report.ReclaimedBytes)
+			unpublish(index, report.Removed)
+		}
+	}
+}
+
+// retentionHandler is the admin endpoint for the retention GC: GET reports what the
+// policy would remove, POST runs it now. When AXON_REGISTRY_ADMIN_TOKEN is set, requests
+// must carry it as a bearer token; without it only GET is allowed.
+func retentionHandler(registryDir string, policy *retentionPolicy, index *searchIndex) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := os.Getenv("AXON_REGISTRY_ADMIN_TOKEN"); token != "" {
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+		} else if r.Method != http.MethodGet {
+			http.Error(w, "running the retention GC requires AXON_REGISTRY_ADMIN_TOKEN", http.StatusForbidden)
+			return
+		}
+
+		var dryRun bool
+		switch r.Method {
+		case http.MethodGet:
+			dryRun = true
+		case http.MethodPost:
+			dryRun = r.URL.Query().Get("dry_run") == "true"
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		report, err := applyRetention(registryDir, policy, dryRun, time.Now())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("retention GC failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if !dryRun {
+			unpublish(index, report.Removed)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(report); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// unpublish drops removed versions from the search index
+func unpublish(index *searchIndex, removed []packageVersion) {
+	if index == nil {
+		return
+	}
+	for _, v := range removed {
+		if err := index.Unpublish(v.Namespace, v.Name, v.Version); err != nil {
+			log.Printf("failed to update search index: %v", err)
+		}
+	}
+}
+
+// accessConfig restricts who can reach the registry, so it can be exposed on a shared
+// network without a fronting proxy. It is read from the environment:
+//
+//	AXON_REGISTRY_ALLOW      comma-separated CIDRs or addresses allowed to connect
+//	AXON_REGISTRY_TLS_CERT   server certificate (PEM); enables HTTPS
+//	AXON_REGISTRY_TLS_KEY    server private key (PEM)
+//	AXON_REGISTRY_CLIENT_CA  CA bundle (PEM); clients must present a certificate it signed
+type accessConfig struct {
+	allowlist    []*net.IPNet
+	certFile     string
+	keyFile      string
+	clientCAFile string
+}
+
+// loadAccessConfig reads the access configuration from the environment
+func loadAccessConfig() (*accessConfig, error) {
+	allowlist, err := parseAllowlist(os.Getenv("AXON_REGISTRY_ALLOW"))
+	if err != nil {
+		return nil, err
+	}
+	access := &accessConfig{
+		allowlist:    allowlist,
+		certFile:     os.Getenv("AXON_REGISTRY_TLS_CERT"),
+		keyFile:      os.Getenv("AXON_REGISTRY_TLS_KEY"),
+		clientCAFile: os.Getenv("AXON_REGISTRY_CLIENT_CA"),
+	}
+	if (access.certFile == "") != (access.keyFile == "") {
+		return nil, fmt.Errorf("AXON_REGISTRY_TLS_CERT and AXON_REGISTRY_TLS_KEY must be set together")
+	}
+	if access.clientCAFile != "" && access.certFile == "" {
+		return nil, fmt.Errorf("AXON_REGISTRY_CLIENT_CA requires AXON_REGISTRY_TLS_CERT and AXON_REGISTRY_TLS_KEY")
+	}
+	return access, nil
+}
+
+// TLS reports whether the server is served over HTTPS
+func (a *accessConfig) TLS() bool {
+	return a.certFile != ""
+}
+
+// TLSConfig returns the server TLS configuration, requiring verified client
+// certificates when a client CA is configured
+func (a *accessConfig) TLSConfig() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if a.clientCAFile == "" {
+		return config, nil
+	}
+	pem, err := os.ReadFile(a.clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in client CA %s", a.clientCAFile)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
+
+// String describes the access restrictions for the startup banner
+func (a *accessConfig) String() string {
+	var parts []string
+	if len(a.allowlist) > 0 {
+		networks := make([]string, len(a.allowlist))
+		for i, network := range a.allowlist {
+			networks[i] = network.String()
+		}
+		parts = append(parts, "allow "+strings.Join(networks, ", "))
+	}
+	if a.clientCAFile != "" {
+		parts = append(parts, "client certificates required")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// parseAllowlist parses comma-separated CIDRs; bare addresses allow a single host
+func parseAllowlist(value string) ([]*net.IPNet, error) {
+	var allowlist []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid allowlist entry %q", entry)
+			}
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowlist entry %q: %w", entry, err)
+		}
+		allowlist = append(allowlist, network)
+	}
+	return allowlist, nil
+}
+
+// allowlistHandler rejects requests from addresses outside the allowlist. It checks
+// the connection's address only: X-Forwarded-For is set by the client and can't be
+// trusted without a proxy in front.
+func allowlistHandler(allowlist []*net.IPNet, next http.Handler) http.Handler {
+	if len(allowlist) == 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		if ip != nil {
+			for _, network := range allowlist {
+				if network.Contains(ip) {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+		}
+		log.Printf("rejected request from %s: not in allowlist", host)
+		http.Error(w, "forbidden", http.StatusForbidden)
+	})
+}
+
+// uploadConfig enables publishing to the registry over HTTP, so 'axon publish --registry'
+// can target it directly. It is read from the environment:
+//
+//	AXON_REGISTRY_UPLOAD_TOKEN      bearer token uploads must carry; uploads are disabled without it
+//	AXON_REGISTRY_NAMESPACE_QUOTA   total package size allowed per namespace, e.g. 50GB
+type uploadConfig struct {
+	token string
+	quota int64 // Bytes per namespace (0 = unlimited)
+}
+
+// loadUploadConfig reads the upload configuration from the environment
+func loadUploadConfig() (*uploadConfig, error) {
+	upload := &uploadConfig{token: os.Getenv("AXON_REGISTRY_UPLOAD_TOKEN")}
+	if quota := os.Getenv("AXON_REGISTRY_NAMESPACE_QUOTA"); quota != "" {
+		size, err := parseSize(quota)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AXON_REGISTRY_NAMESPACE_QUOTA: %w", err)
+		}
+		upload.quota = size
+	}
+	return upload, nil
+}
+
+// Enabled reports whether uploads are accepted
+func (u *uploadConfig) Enabled() bool {
+	return u.token != ""
+}
+
+// authorize checks the upload token of a request, and writes the error if it fails
+func (u *uploadConfig) authorize(w http.ResponseWriter, r *http.Request) bool {
+	if !u.Enabled() {
+		http.Error(w, "uploads are disabled (set AXON_REGISTRY_UPLOAD_TOKEN)", http.StatusForbidden)
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(u.token)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// withUpload routes PUT requests to upload and every other request to get
+func withUpload(get, upload http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			upload(w, r)
+			return
+		}
+		get(w, r)
+	}
+}
+
+// withDelete routes DELETE requests to del and every other request to next
+func withDelete(next, del http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			del(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// packageUploadHandler stores a package uploaded with PUT /packages/{file}. The body must
+// hash to the X-Checksum-Sha256 header; the hash is kept in {file}.sha256. Re-uploading
+// the same content succeeds, and replacing a package with different content is refused.
+func packageUploadHandler(registryDir string, upload *uploadConfig) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !upload.authorize(w, r) {
+			return
+		}
+		filename := strings.TrimPrefix(r.URL.Path, "/packages/")
+		if filename == "" || filename != filepath.Base(filename) || strings.HasPrefix(filename, ".") || strings.HasSuffix(filename, ".sha256") {
+			http.Error(w, fmt.Sprintf("invalid package file name %q", filename), http.StatusBadRequest)
+			return
+		}
+		want := strings.ToLower(r.Header.Get("X-Checksum-Sha256"))
+		if len(want) != sha256.Size*2 {
+			http.Error(w, "missing or invalid X-Checksum-Sha256 header", http.StatusBadRequest)
+			return
+		}
+
+		packagesDir := filepath.Join(registryDir, "packages")
+		packagePath := filepath.Join(packagesDir, filename)
+		if existing, err := fileSHA256(packagePath); err == nil {
+			if existing != want {
+				http.Error(w, fmt.Sprintf("package %s already exists with different content", filename), http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		if err := os.MkdirAll(packagesDir, 0755); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create packages directory: %v", err), http.StatusInternalServerError)
+			return
+		}
+		tmp, err := os.CreateTemp(packagesDir, ".upload-*")
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to store package: %v", err), http.StatusInternalServerError)
+			return
+		}
+		defer func() { _ = os.Remove(tmp.Name()) }()
+
+		// Hash while writing, so the body is read once
+		hasher := sha256.New()
+		if upload.quota > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, upload.quota)
+		}
+		_, err = io.Copy(io.MultiWriter(tmp, hasher), r.Body)
+		if closeErr := tmp.Close(); err == nil {
+			err = closeErr
+		}
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, fmt.Sprintf("package exceeds the namespace quota of %d bytes", upload.quota), http.StatusRequestEntityTooLarge)
+			return
+		case err != nil:
+			http.Error(w, fmt.Sprintf("failed to store package: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if got := hex.EncodeToString(hasher.Sum(nil)); got != want {
+			http.Error(w, fmt.Sprintf("checksum mismatch: got sha256 %s, header says %s", got, want), http.StatusBadRequest)
+			return
+		}
+
+		if err := os.WriteFile(packagePath+".sha256", []byte(want+"\n"), 0644); err != nil {
+			http.Error(w, fmt.Sprintf("failed to store checksum: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if err := os.Rename(tmp.Name(), packagePath); err != nil {
+			http.Error(w, fmt.Sprintf("failed to store package: %v", err), http.StatusInternalServerError)
+			return
+		}
+		log.Printf("uploaded package %s (sha256 %s)", filename, want)
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// uploadedManifest is the part of an uploaded manifest the registry checks
+type uploadedManifest struct {
+	Metadata struct {
+		Namespace string `yaml:"namespace"`
+		Name      string `yaml:"name"`
+		Version   string `yaml:"version"`
+	} `yaml:"metadata"`
+	Distribution struct {
+		Package struct {
+			URL    string `yaml:"url"`
+			SHA256 string `yaml:"sha256"`
+		} `yaml:"package"`
+	} `yaml:"distribution"`
+}
+
+// manifestUploadHandler publishes a model version with PUT
+// /api/v1/models/{namespace}/{name}/{version}, whose body is the manifest. Its package
+// must have been uploaded first, under /packages/, and match the manifest's sha256; the
+// namespace must stay within its quota. An attestation for the version can be uploaded
+// before the manifest with PUT .../{version}/attestation.json. Published versions are
+// immutable.
+func manifestUploadHandler(registryDir string, upload *uploadConfig, index *searchIndex) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !upload.authorize(w, r) {
+			return
+		}
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/models/"), "/")
+		path, attestation := strings.CutSuffix(path, "/attestation.json")
+		parts := strings.Split(path, "/")
+		if len(parts) != 3 || !validSegments(parts) {
+			http.Error(w, "expected /api/v1/models/{namespace}/{name}/{version}", http.StatusBadRequest)
+			return
+		}
+		namespace, name, version := parts[0], parts[1], parts[2]
+		manifestDir := filepath.Join(registryDir, "api/v1/models", namespace, name, version)
+		manifestPath := filepath.Join(manifestDir, "manifest.yaml")
+		if _, err := os.Stat(manifestPath); err == nil {
+			http.Error(w, fmt.Sprintf("%s/%s@%s is already published", namespace, name, version), http.StatusConflict)
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxManifestSize+1))
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to read body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if len(data) > maxManifestSize {
+			http.Error(w, "manifest too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if attestation {
+			if !json.Valid(data) {
+				http.Error(w, "attestation is not JSON", http.StatusBadRequest)
+				return
+			}
+			if err := os.MkdirAll(manifestDir, 0755); err != nil {
+				http.Error(w, fmt.Sprintf("failed to create manifest directory: %v", err), http.StatusInternalServerError)
+				return
+			}
+			if err := os.WriteFile(filepath.Join(manifestDir, "attestation.json"), data, 0644); err != nil {
+				http.Error(w, fmt.Sprintf("failed to store attestation: %v", err), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+
+		var manifest uploadedManifest
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			http.Error(w, fmt.Sprintf("invalid manifest: %v", err), http.StatusBadRequest)
+			return
+		}
+		if m := manifest.Metadata; m.Namespace != namespace || m.Name != name || m.Version != version {
+			http.Error(w, fmt.Sprintf("manifest is for %s/%s@%s, not %s/%s@%s", m.Namespace, m.Name, m.Version, namespace, name, version), http.StatusBadRequest)
+			return
+		}
+		packageName, ok := strings.CutPrefix(manifest.Distribution.Package.URL, "/packages/")
+		if !ok || packageName != filepath.Base(packageName) {
+			http.Error(w, "the manifest's package URL must be /packages/{file} on this registry", http.StatusBadRequest)
+			return
+		}
+		packagePath := filepath.Join(registryDir, "packages", packageName)
+		digest, err := fileSHA256(packagePath)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("package %s has not been uploaded", packageName), http.StatusBadRequest)
+			return
+		}
+		if !strings.EqualFold(digest, manifest.Distribution.Package.SHA256) {
+			http.Error(w, fmt.Sprintf("checksum mismatch: package %s has sha256 %s, the manifest says %s", packageName, digest, manifest.Distribution.Package.SHA256), http.StatusBadRequest)
+			return
+		}
+		if err := checkNamespaceQuota(registryDir, namespace, packagePath, upload.quota); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// Write the manifest atomically, so readers never see a partial one
+		if err := os.MkdirAll(manifestDir, 0755); err != nil {
+			http.Error(w, fmt.Sprintf("failed to create manifest directory: %v", err), http.StatusInternalServerError)
+			return
+		}
+		tmpPath := manifestPath + ".tmp"
+		if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+			http.Error(w, fmt.Sprintf("failed to store manifest: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if err := os.Rename(tmpPath, manifestPath); err != nil {
+			_ = os.Remove(tmpPath)
+			http.Error(w, fmt.Sprintf("failed to store manifest: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if index != nil {
+			if err := index.Publish(namespace, name, version); err != nil {
+				log.Printf("failed to update search index: %v", err)
+			}
+		}
+		log.Printf("published %s/%s@%s", namespace, name, version)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"namespace": namespace, "name": name, "version": version})
+	}
+}
+
+// manifestDeleteHandler removes a published version with DELETE
+// /api/v1/models/{namespace}/{name}/{version}. Its package is removed too, unless another
+// version was published with it.
+func manifestDeleteHandler(registryDir string, upload *uploadConfig, index *searchIndex) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !upload.authorize(w, r) {
+			return
+		}
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/models/"), "/"), "/")
+		if len(parts) < 3 || !validSegments(parts) {
+			http.Error(w, "expected /api/v1/models/{namespace}/{name}/{version}", http.StatusBadRequest)
+			return
+		}
+		// Proxied models may have names of several segments
+		namespace, name, version := parts[0], strings.Join(parts[1:len(parts)-1], "/"), parts[len(parts)-1]
+		manifestDir := filepath.Dir(filepath.Join(registryDir, "api/v1/models", manifestKey(namespace, name, version)))
+
+		versions, err := listPackageVersions(registryDir)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to list versions: %v", err), http.StatusInternalServerError)
+			return
+		}
+		var removed []packageVersion
+		for _, v := range versions {
+			if v.manifestDir == manifestDir {
+				removed = append(removed, v)
+			}
+		}
+		if len(removed) == 0 {
+			http.Error(w, fmt.Sprintf("%s/%s@%s is not published", namespace, name, version), http.StatusNotFound)
+			return
+		}
+		if _, err := removeVersions(versions, removed, false); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		unpublish(index, removed)
+		log.Printf("deleted %s/%s@%s", namespace, name, version)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// maxManifestSize bounds uploaded manifests and attestations
+const maxManifestSize = 4 << 20
+
+// errNamespaceQuota reports a namespace whose packages would exceed its quota
+var errNamespaceQuota = errors.New("namespace quota exceeded")
+
+// checkNamespaceQuota reports an error if publishing the package at packagePath would
+// take the namespace's packages over quota bytes
+func checkNamespaceQuota(registryDir, namespace, packagePath string, quota int64) error {
+	if quota <= 0 {
+		return nil
+	}
+	used, err := namespaceUsage(registryDir, namespace, packagePath)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(packagePath)
+	if err != nil {
+		return err
+	}
+	if used+stat.Size() > quota {
+		return fmt.Errorf("%w: %s would use %d bytes, over its quota of %d bytes", errNamespaceQuota, namespace, used+stat.Size(), quota)
+	}
+	return nil
+}
+
+// namespaceUsage returns the size of the packages of a namespace's published versions,
+// not counting the package at except
+func namespaceUsage(registryDir, namespace, except string) (int64, error) {
+	versions, err := listPackageVersions(registryDir)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure namespace %s: %w", namespace, err)
+	}
+	var used int64
+	for _, v := range versions {
+		if v.Namespace == namespace && v.packagePath != except {
+			used += v.Bytes
+		}
+	}
+	return used, nil
+}
+
+// validSegments reports whether every path segment is a plain name, safe to join to a
+// directory
+func validSegments(segments []string) bool {
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsAny(segment, `\:`) {
+			return false
+		}
+	}
+	return true
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// proxyFetchTimeout bounds fetching a model from upstream, which outlives the request
+// that started it when other requests wait for the same model
+const proxyFetchTimeout = 30 * time.Minute
+
+// upstream fetches models the registry doesn't have
+type upstream interface {
+	// Fetch returns the manifest of a model version and downloads its package to destPath
+	Fetch(ctx context.Context, namespace, name, version, destPath string) (*types.Manifest, error)
+}
+
+// adapterUpstream fetches models with Axon's builtin adapters, the way 'axon install'
+// does without a registry
+type adapterUpstream struct {
+	adapters *core.AdapterRegistry
+}
+
+func newAdapterUpstream(hfToken string) *adapterUpstream {
+	adapters := core.NewAdapterRegistry()
+	builtin.RegisterDefaultAdapters(adapters, "", nil, hfToken, true)
+	return &adapterUpstream{adapters: adapters}
+}
+
+func (u *adapterUpstream) Fetch(ctx context.Context, namespace, name, version, destPath string) (*types.Manifest, error) {
+	adapter, err := u.adapters.FindAdapter(namespace, name)
+	if err != nil {
+		return nil, fmt.Errorf("no upstream adapter for %s/%s: %w", namespace, name, err)
+	}
+	manifest, err := adapter.GetManifest(ctx, namespace, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get manifest from %s: %w", adapter.Name(), err)
+	}
+	if err := adapter.DownloadPackage(ctx, manifest, destPath, nil); err != nil {
+		return nil, fmt.Errorf("failed to download package from %s: %w", adapter.Name(), err)
+	}
+	return manifest, nil
+}
+
+// registryProxy makes the registry a caching proxy: a manifest requested in a proxied
+// namespace that the registry doesn't have is fetched from upstream, with its package,
+// and published like an upload, so later requests are served locally. Like uploads,
+// fetches must carry the upload token when uploads are enabled, and their packages count
+// towards the namespace quota. It is configured from the environment:
+//
+//	AXON_REGISTRY_PROXY            comma-separated namespaces to proxy, e.g. hf,pytorch, or * for all
+//	AXON_REGISTRY_PROXY_HF_TOKEN   Hugging Face token for gated and private models
+type registryProxy struct {
+	registryDir string
+	namespaces  []string
+	upstream    upstream
+	upload      *uploadConfig
+	index       *searchIndex
+
+	mu       sync.Mutex
+	inflight map[string]*proxyFetch // Keyed by model version, so concurrent requests fetch once
+}
+
+// proxyFetch is a fetch of one model version from upstream
+type proxyFetch struct {
+	done chan struct{}
+	err  error
+}
+
+// loadProxyConfig returns the proxy configured in the environment, or nil if proxying is
+// off
+func loadProxyConfig(registryDir string, upload *uploadConfig, index *searchIndex) *registryProxy {
+	var namespaces []string
+	for _, namespace := range strings.Split(os.Getenv("AXON_REGISTRY_PROXY"), ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+	return newRegistryProxy(registryDir, namespaces, newAdapterUpstream(os.Getenv("AXON_REGISTRY_PROXY_HF_TOKEN")), upload, index)
+}
+
+func newRegistryProxy(registryDir string, namespaces []string, upstream upstream, upload *uploadConfig, index *searchIndex) *registryProxy {
+	return &registryProxy{
+		registryDir: registryDir,
+		namespaces:  namespaces,
+		upstream:    upstream,
+		upload:      upload,
+		index:       index,
+		inflight:    make(map[string]*proxyFetch),
+	}
+}
+
+// String describes the proxied namespaces
+func (p *registryProxy) String() string {
+	return strings.Join(p.namespaces, ", ")
+}
+
+// proxies reports whether models in namespace are fetched from upstream
+func (p *registryProxy) proxies(namespace string) bool {
+	for _, ns := range p.namespaces {
+		if ns == "*" || ns == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// Fetch caches a model version from upstream, unless it is already in the registry.
+// Concurrent calls for the same version share one fetch; a caller that gives up waiting
+// doesn't cancel it for the others.
+func (p *registryProxy) Fetch(ctx context.Context, namespace, name, version string) error {
+	key := namespace + "/" + name + "@" + version
+	p.mu.Lock()
+	fetch, ok := p.inflight[key]
+	if !ok {
+		fetch = &proxyFetch{done: make(chan struct{})}
+		p.inflight[key] = fetch
+		go func() {
+			fetchCtx, cancel := context.WithTimeout(context.Background(), proxyFetchTimeout)
+			defer cancel()
+			fetch.err = p.fetch(fetchCtx, namespace, name, version)
+			p.mu.Lock()
+			delete(p.inflight, key)
+			p.mu.Unlock()
+			close(fetch.done)
+		}()
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-fetch.done:
+		return fetch.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// fetch downloads a model version from upstream and publishes it in the registry
+func (p *registryProxy) fetch(ctx context.Context, namespace, name, version string) error {
+	manifestDir := filepath.Join(append([]string{p.registryDir, "api/v1/models", namespace}, append(strings.Split(name, "/"), version)...)...)
+	manifestPath := filepath.Join(manifestDir, "manifest.yaml")
+	if _, err := os.Stat(manifestPath); err == nil {
+		return nil
+	}
+
+	// A namespace already at its quota isn't worth a download
+	if p.upload.quota > 0 {
+		used, err := namespaceUsage(p.registryDir, namespace, "")
+		if err != nil {
+			return err
+		}
+		if used >= p.upload.quota {
+			return fmt.Errorf("%w: %s uses %d bytes, its quota is %d bytes", errNamespaceQuota, namespace, used, p.upload.quota)
+		}
+	}
+
+	packagesDir := filepath.Join(p.registryDir, "packages")
+	if err := os.MkdirAll(packagesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create packages directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(packagesDir, ".proxy-*")
+	if err != nil {
+		return fmt.Errorf("failed to create package file: %w", err)
+	}
+	_ = tmp.Close()
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	log.Printf("fetching %s/%s@%s from upstream", namespace, name, version)
+	manifest, err := p.upstream.Fetch(ctx, namespace, name, version, tmp.Name())
+	if err != nil {
+		return err
+	}
+
+	if err := checkNamespaceQuota(p.registryDir, namespace, tmp.Name(), p.upload.quota); err != nil {
+		return err
+	}
+
+	// Serve the package from the registry, with the checksum of what was downloaded
+	packageName := spec.NewModelRef(namespace, name, version).FileName(".axon")
+	packagePath := filepath.Join(packagesDir, packageName)
+	digest, err := fileSHA256(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("failed to hash package: %w", err)
+	}
+	stat, err := os.Stat(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("failed to stat package: %w", err)
+	}
+	if err := os.WriteFile(packagePath+".sha256", []byte(digest+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to store checksum: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), packagePath); err != nil {
+		return fmt.Errorf("failed to store package: %w", err)
+	}
+	manifest.Metadata.Namespace, manifest.Metadata.Name, manifest.Metadata.Version = namespace, name, version
+	manifest.Distribution.Package.URL = "/packages/" + packageName
+	manifest.Distribution.Package.Size = stat.Size()
+	manifest.Distribution.Package.SHA256 = digest
+	manifest.Distribution.Package.Mirrors = nil
+
+	// Write the manifest last and atomically: it is what publishes the version
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	tmpPath := manifestPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to store manifest: %w", err)
+	}
+	if err := os.Rename(tmpPath, manifestPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to store manifest: %w", err)
+	}
+	if p.index != nil {
+		if err := p.index.Publish(namespace, name, version); err != nil {
+			log.Printf("failed to update search index: %v", err)
+		}
+	}
+	log.Printf("cached %s/%s@%s (%d bytes)", namespace, name, version, stat.Size())
+	return nil
+}
+
+// withProxy fetches manifests missing from the registry from upstream before next serves
+// them, when the proxy is on and proxies their namespace
+func withProxy(registryDir string, proxy *registryProxy, next http.HandlerFunc) http.HandlerFunc {
+	if proxy == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/models/"), "/")
+		parts := strings.Split(path, "/")
+		if r.Method != http.MethodGet || len(parts) < 4 || parts[len(parts)-1] != "manifest.yaml" ||
+			!validSegments(parts) || !proxy.proxies(parts[0]) {
+			next(w, r)
+			return
+		}
+		if _, err := os.Stat(filepath.Join(registryDir, "api/v1/models", path)); os.IsNotExist(err) {
+			// A fetch publishes the model, so it takes the upload token when uploads are on
+			if proxy.upload.Enabled() && !proxy.upload.authorize(w, r) {
+				return
+			}
+			namespace, version := parts[0], parts[len(parts)-2]
+			name := strings.Join(parts[1:len(parts)-2], "/")
+			if err := proxy.Fetch(r.Context(), namespace, name, version); err != nil {
+				log.Printf("failed to fetch %s/%s@%s from upstream: %v", namespace, name, version, err)
+				status := http.StatusBadGateway
+				if errors.Is(err, errNamespaceQuota) {
+					status = http.StatusInsufficientStorage
+				}
+				http.Error(w, fmt.Sprintf("failed to fetch %s/%s@%s from upstream: %v", namespace, name, version, err), status)
+				return
+			}
+		}
+		next(w, r)
+	}
+}
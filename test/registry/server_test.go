@@ -0,0 +1,1285 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"gopkg.in/yaml.v3"
+
+	"github.com/mlOS-foundation/axon/pkg/types"
+)
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.8", true},
+		{"br, zstd", false},
+		{"gzip;q=0", false},
+		{"gzip; q=0, *", false},
+		{"*", true},
+		{"*;q=0", false},
+	}
+
+	for _, tt := range tests {
+		if got := acceptsGzip(tt.header); got != tt.want {
+			t.Errorf("acceptsGzip(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestManifestHandler_CompressionAndConditionalGet(t *testing.T) {
+	registryDir := t.TempDir()
+	manifestDir := filepath.Join(registryDir, "api/v1/models/nlp/bert/1.0.0")
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := []byte("apiVersion: v1\nkind: Model\n" + strings.Repeat("# padding\n", 100))
+	if err := os.WriteFile(filepath.Join(manifestDir, "manifest.yaml"), manifest, 0644); err != nil {
+		t.Fatal(err)
+	}
+	handler := manifestHandler(registryDir)
+	url := "/api/v1/models/nlp/bert/1.0.0/manifest.yaml"
+
+	// Compressed response
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", rec.Header().Get("Content-Encoding"))
+	}
+	gz, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, manifest) {
+		t.Error("decompressed body does not match the manifest")
+	}
+	gzipTag := rec.Header().Get("ETag")
+
+	// Uncompressed response has a different entity tag
+	req = httptest.NewRequest(http.MethodGet, url, nil)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Header().Get("Content-Encoding") != "" || !bytes.Equal(rec.Body.Bytes(), manifest) {
+		t.Error("expected identity response without Accept-Encoding")
+	}
+	plainTag := rec.Header().Get("ETag")
+	if plainTag == "" || plainTag == gzipTag {
+		t.Errorf("ETags = %q and %q, want distinct non-empty tags", plainTag, gzipTag)
+	}
+
+	// Either tag revalidates to 304
+	for _, tag := range []string{plainTag, gzipTag, "W/" + gzipTag} {
+		req = httptest.NewRequest(http.MethodGet, url, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set("If-None-Match", tag)
+		rec = httptest.NewRecorder()
+		handler(rec, req)
+		if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status = %d, body %d bytes, want 304 with no body", tag, rec.Code, rec.Body.Len())
+		}
+	}
+
+	// A changed manifest no longer matches
+	if err := os.WriteFile(filepath.Join(manifestDir, "manifest.yaml"), append(manifest, "# v2\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	req = httptest.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("If-None-Match", plainTag)
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("status after change = %d, want 200", rec.Code)
+	}
+}
+
+func TestManifestHandler_Attestation(t *testing.T) {
+	registryDir := t.TempDir()
+	manifestDir := filepath.Join(registryDir, "api/v1/models/nlp/bert/1.0.0")
+	if err := os.MkdirAll(manifestDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	envelope := []byte(`{"payloadType": "application/vnd.in-toto+json", "payload": "", "signatures": []}`)
+	if err := os.WriteFile(filepath.Join(manifestDir, "attestation.json"), envelope, 0644); err != nil {
+		t.Fatal(err)
+	}
+	handler := manifestHandler(registryDir)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/bert/1.0.0/attestation.json", nil))
+	if rec.Code != http.StatusOK || !bytes.Equal(rec.Body.Bytes(), envelope) {
+		t.Fatalf("status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/vnd.dsse.envelope.v1+json" {
+		t.Errorf("Content-Type = %q, want the DSSE envelope type", got)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/bert/2.0.0/attestation.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status of a missing attestation = %d, want 404", rec.Code)
+	}
+}
+
+func TestManifestHandler_Versions(t *testing.T) {
+	registryDir := t.TempDir()
+	for _, version := range []string{"1.0.0", "1.2.0"} {
+		dir := filepath.Join(registryDir, "api/v1/models/nlp/bert", version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("kind: Model\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A version directory without a manifest isn't published yet
+	if err := os.MkdirAll(filepath.Join(registryDir, "api/v1/models/nlp/bert/2.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	handler := manifestHandler(registryDir)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/bert/versions.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	var got modelVersions
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || !reflect.DeepEqual(got.Versions, []string{"1.0.0", "1.2.0"}) {
+		t.Errorf("versions = %s, %v, want 1.0.0 and 1.2.0", rec.Body.String(), err)
+	}
+
+	// Without the extension, as the versions API
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/bert/versions", nil))
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Latest != "1.2.0" || got.Namespace != "nlp" || got.Name != "bert" {
+		t.Errorf("versions = %s, %v, want latest 1.2.0", rec.Body.String(), err)
+	}
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/gpt2/versions.json", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status of an unknown model = %d, want 404", rec.Code)
+	}
+}
+
+func TestSortVersionsAndLatest(t *testing.T) {
+	tests := []struct {
+		versions []string
+		sorted   []string
+		latest   string
+	}{
+		{
+			versions: []string{"1.10.0", "v1.2.0", "2.0.0-rc.1", "main", "1.9.0", "latest"},
+			sorted:   []string{"latest", "main", "v1.2.0", "1.9.0", "1.10.0", "2.0.0-rc.1"},
+			latest:   "1.10.0",
+		},
+		{versions: []string{"2.0.0-rc.2", "2.0.0-rc.10", "main"}, sorted: []string{"main", "2.0.0-rc.2", "2.0.0-rc.10"}, latest: "2.0.0-rc.10"},
+		{versions: []string{"main", "latest", "dev"}, sorted: []string{"dev", "latest", "main"}, latest: "latest"},
+		{versions: []string{"main", "dev"}, sorted: []string{"dev", "main"}, latest: "main"},
+	}
+	for _, tt := range tests {
+		versions := append([]string(nil), tt.versions...)
+		sortVersions(versions)
+		if !reflect.DeepEqual(versions, tt.sorted) {
+			t.Errorf("sortVersions(%v) = %v, want %v", tt.versions, versions, tt.sorted)
+		}
+		if got := latestVersion(versions); got != tt.latest {
+			t.Errorf("latestVersion(%v) = %q, want %q", versions, got, tt.latest)
+		}
+	}
+}
+
+func TestIndexJSONHandler(t *testing.T) {
+	registryDir := t.TempDir()
+	for _, path := range []string{"nlp/bert/1.0.0", "vision/resnet50/1.0.0"} {
+		dir := filepath.Join(registryDir, "api/v1/models", path)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("kind: Model\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	indexJSONHandler(registryDir)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/index", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"name":"bert"`) || !strings.Contains(rec.Body.String(), `"name":"resnet50"`) {
+		t.Errorf("index = %s, want both models", rec.Body.String())
+	}
+	if rec.Header().Get("ETag") == "" {
+		t.Error("index response has no ETag")
+	}
+}
+
+func writeManifest(t *testing.T, registryDir, path string) {
+	t.Helper()
+	dir := filepath.Join(registryDir, "api/v1/models", path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte("kind: Model\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// openTestIndex opens a search index of registryDir, closed when the test ends
+func openTestIndex(t *testing.T, registryDir string) *searchIndex {
+	t.Helper()
+	idx, err := openSearchIndex(registryDir, filepath.Join(registryDir, indexFileName))
+	if err != nil {
+		t.Fatalf("openSearchIndex() error = %v", err)
+	}
+	t.Cleanup(func() { _ = idx.Close() })
+	return idx
+}
+
+func searchNames(idx *searchIndex, query string) []string {
+	var names []string
+	for _, model := range idx.Search(query) {
+		names = append(names, model.Namespace+"/"+model.Name)
+	}
+	return names
+}
+
+func TestSearchIndex(t *testing.T) {
+	registryDir := t.TempDir()
+	writeManifest(t, registryDir, "nlp/bert-base-uncased/1.0.0")
+	writeManifest(t, registryDir, "nlp/distilbert/1.0.0")
+	writeManifest(t, registryDir, "vision/resnet50/1.0.0")
+
+	idx := openTestIndex(t, registryDir)
+	if err := idx.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"BERT", []string{"nlp/bert-base-uncased", "nlp/distilbert"}},
+		{"nlp", []string{"nlp/bert-base-uncased", "nlp/distilbert"}},
+		{"net5", []string{"vision/resnet50"}},
+		{"50", []string{"vision/resnet50"}},
+		{"vision/res", nil},
+		{"gpt", nil},
+	}
+	for _, tt := range tests {
+		if got := searchNames(idx, tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+
+	// Publishing and deleting manifests is picked up by the next refresh
+	writeManifest(t, registryDir, "nlp/gpt2/1.0.0")
+	if err := os.RemoveAll(filepath.Join(registryDir, "api/v1/models/nlp/distilbert")); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if got := searchNames(idx, "gpt"); !reflect.DeepEqual(got, []string{"nlp/gpt2"}) {
+		t.Errorf("Search(gpt) after publish = %v", got)
+	}
+	if got := searchNames(idx, "bert"); !reflect.DeepEqual(got, []string{"nlp/bert-base-uncased"}) {
+		t.Errorf("Search(bert) after delete = %v", got)
+	}
+	_ = idx.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(trigramsBucket).Bucket([]byte("dis")) != nil {
+			t.Error("trigrams of deleted models should be dropped from the index")
+		}
+		return nil
+	})
+	if idx.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", idx.Len())
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	registryDir := t.TempDir()
+	writeManifest(t, registryDir, "nlp/gpt2/1.0.0")
+	idx := openTestIndex(t, registryDir)
+	if err := idx.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	searchHandler(idx)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search?q=gpt", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"name":"gpt2"`) {
+		t.Errorf("search response = %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	searchHandler(idx)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status without query = %d, want 400", rec.Code)
+	}
+}
+
+// writeModelManifest writes a manifest with searchable metadata
+func writeModelManifest(t *testing.T, registryDir, path, description, framework, format, license string, tags ...string) {
+	t.Helper()
+	dir := filepath.Join(registryDir, "api/v1/models", path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := fmt.Sprintf("kind: Model\nmetadata:\n  description: %q\n  license: %s\n  tags: [%s]\nspec:\n  framework:\n    name: %s\n  format:\n    type: %s\n",
+		description, license, strings.Join(tags, ", "), framework, format)
+	if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSearchIndex_FullTextAndFilters(t *testing.T) {
+	registryDir := t.TempDir()
+	writeModelManifest(t, registryDir, "nlp/bert/1.0.0", "Bidirectional encoder for text classification", "pytorch", "pytorch", "apache-2.0", "nlp", "encoder")
+	writeModelManifest(t, registryDir, "nlp/roberta/1.0.0", "Robustly optimized BERT", "pytorch", "onnx", "mit", "nlp")
+	writeModelManifest(t, registryDir, "vision/vit/1.0.0", "Vision transformer for image classification", "tensorflow", "savedmodel", "apache-2.0", "image", "transformer")
+
+	idx := openTestIndex(t, registryDir)
+	if err := idx.Refresh(); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	find := func(query string, filter searchFilter) []string {
+		var names []string
+		for _, model := range idx.Find(query, filter) {
+			names = append(names, model.Namespace+"/"+model.Name)
+		}
+		return names
+	}
+	tests := []struct {
+		name   string
+		query  string
+		filter searchFilter
+		want   []string
+	}{
+		{"exact name ranks above description", "bert", searchFilter{}, []string{"nlp/bert", "nlp/roberta"}},
+		{"description", "classification", searchFilter{}, []string{"nlp/bert", "vision/vit"}},
+		{"every term must match", "image classification", searchFilter{}, []string{"vision/vit"}},
+		{"tag ranks above description", "transformer", searchFilter{}, []string{"vision/vit"}},
+		{"tag", "encoder", searchFilter{}, []string{"nlp/bert"}},
+		{"framework filter", "", searchFilter{Framework: "PyTorch"}, []string{"nlp/bert", "nlp/roberta"}},
+		{"format filter", "bert", searchFilter{Format: "onnx"}, []string{"nlp/roberta"}},
+		{"license filter", "classification", searchFilter{License: "apache-2.0"}, []string{"nlp/bert", "vision/vit"}},
+		{"filters combine", "", searchFilter{Framework: "pytorch", License: "mit"}, []string{"nlp/roberta"}},
+		{"no match", "", searchFilter{Framework: "jax"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := find(tt.query, tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find(%q, %+v) = %v, want %v", tt.query, tt.filter, got, tt.want)
+			}
+		})
+	}
+
+	// Changed manifests are re-read
+	writeModelManifest(t, registryDir, "nlp/bert/1.0.0", "Masked language model", "jax", "pytorch", "apache-2.0")
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(registryDir, "api/v1/models/nlp/bert/1.0.0/manifest.yaml"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if got := find("", searchFilter{Framework: "jax"}); !reflect.DeepEqual(got, []string{"nlp/bert"}) {
+		t.Errorf("Find(framework=jax) after update = %v", got)
+	}
+	if got := find("encoder", searchFilter{}); got != nil {
+		t.Errorf("Find(encoder) after update = %v, want nil", got)
+	}
+}
+
+func TestSearchIndex_Persist(t *testing.T) {
+	registryDir := t.TempDir()
+	dbPath := filepath.Join(registryDir, indexFileName)
+	writeModelManifest(t, registryDir, "nlp/bert/1.0.0", "Bidirectional encoder", "pytorch", "pytorch", "apache-2.0")
+
+	idx, err := openSearchIndex(registryDir, dbPath)
+	if err != nil {
+		t.Fatalf("openSearchIndex() error = %v", err)
+	}
+	if err := idx.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if err := idx.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A restarted index reopens the database and doesn't re-read unchanged manifests:
+	// make the manifest unreadable without changing its modification time
+	manifestPath := filepath.Join(registryDir, "api/v1/models/nlp/bert/1.0.0/manifest.yaml")
+	info, err := os.Stat(manifestPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manifestPath, []byte("{not yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(manifestPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, err := openSearchIndex(registryDir, dbPath)
+	if err != nil {
+		t.Fatalf("openSearchIndex() error = %v", err)
+	}
+	if restarted.Len() != 1 {
+		t.Fatalf("Len() after reopening = %d, want 1", restarted.Len())
+	}
+	if err := restarted.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if got := restarted.Find("encoder", searchFilter{Framework: "pytorch"}); len(got) != 1 {
+		t.Errorf("Find(encoder) after restart = %v, want the model from the database", got)
+	}
+	if err := restarted.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// An unreadable database is rebuilt from the manifests
+	if err := os.WriteFile(dbPath, bytes.Repeat([]byte("garbage "), 1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rebuilt := openTestIndex(t, registryDir)
+	if rebuilt.Len() != 0 {
+		t.Errorf("Len() of a rebuilt index before refreshing = %d, want 0", rebuilt.Len())
+	}
+	if err := rebuilt.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.Len() != 1 {
+		t.Errorf("Len() after rebuild = %d, want 1", rebuilt.Len())
+	}
+}
+
+func TestSearchHandler_Filters(t *testing.T) {
+	registryDir := t.TempDir()
+	writeModelManifest(t, registryDir, "nlp/bert/1.0.0", "Bidirectional encoder", "pytorch", "pytorch", "apache-2.0", "nlp")
+	writeModelManifest(t, registryDir, "vision/vit/1.0.0", "Vision transformer", "tensorflow", "savedmodel", "apache-2.0")
+	idx := openTestIndex(t, registryDir)
+	if err := idx.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	searchHandler(idx)(rec, httptest.NewRequest(http.MethodGet, "/api/v1/search?framework=pytorch", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0]["name"] != "bert" || results[0]["description"] != "Bidirectional encoder" || results[0]["framework"] != "pytorch" {
+		t.Errorf("results = %v, want bert with its description and framework", results)
+	}
+}
+
+// publishVersion writes a manifest and a package of size bytes, published age ago
+func publishVersion(t *testing.T, registryDir, namespace, name, version string, size int, age time.Duration) {
+	t.Helper()
+	packageName := fmt.Sprintf("%s-%s-%s.axon", namespace, name, version)
+	dir := filepath.Join(registryDir, "api/v1/models", namespace, name, version)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := fmt.Sprintf("distribution:\n  package:\n    url: http://localhost:8080/packages/%s\n", packageName)
+	manifestPath := filepath.Join(dir, "manifest.yaml")
+	if err := os.WriteFile(manifestPath, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	published := time.Now().Add(-age)
+	if err := os.Chtimes(manifestPath, published, published); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(registryDir, "packages"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(registryDir, "packages", packageName), make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func removedVersions(report *retentionReport) []string {
+	var versions []string
+	for _, v := range report.Removed {
+		versions = append(versions, fmt.Sprintf("%s/%s@%s", v.Namespace, v.Name, v.Version))
+	}
+	return versions
+}
+
+func TestRetentionPolicy(t *testing.T) {
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		policy retentionPolicy
+		want   []string
+	}{
+		{
+			name:   "keep last",
+			policy: retentionPolicy{KeepLast: 2},
+			want:   []string{"nlp/bert@1.0.0"},
+		},
+		{
+			name:   "max age keeps the newest version",
+			policy: retentionPolicy{MaxAge: "240h"},
+			want:   []string{"nlp/bert@1.1.0", "nlp/bert@1.0.0"},
+		},
+		{
+			name:   "max namespace size removes the oldest first",
+			policy: retentionPolicy{MaxNamespaceSize: "2500B"},
+			want:   []string{"nlp/bert@1.0.0"},
+		},
+		{
+			name:   "disabled",
+			policy: retentionPolicy{},
+			want:   nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registryDir := t.TempDir()
+			publishVersion(t, registryDir, "nlp", "bert", "1.0.0", 1000, 30*day)
+			publishVersion(t, registryDir, "nlp", "bert", "1.1.0", 1000, 20*day)
+			publishVersion(t, registryDir, "nlp", "bert", "2.0.0", 1000, day)
+			publishVersion(t, registryDir, "vision", "resnet", "1.0.0", 5000, 40*day)
+
+			policy := tt.policy
+			if err := policy.parse(); err != nil {
+				t.Fatalf("parse() error = %v", err)
+			}
+
+			// A dry run reports without removing anything
+			report, err := applyRetention(registryDir, &policy, true, time.Now())
+			if err != nil {
+				t.Fatalf("applyRetention() error = %v", err)
+			}
+			if got := removedVersions(report); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("dry run removed %v, want %v", got, tt.want)
+			}
+			versions, _ := listPackageVersions(registryDir)
+			if len(versions) != 4 {
+				t.Fatalf("dry run left %d versions, want 4", len(versions))
+			}
+
+			report, err = applyRetention(registryDir, &policy, false, time.Now())
+			if err != nil {
+				t.Fatalf("applyRetention() error = %v", err)
+			}
+			versions, _ = listPackageVersions(registryDir)
+			if len(versions) != 4-len(tt.want) {
+				t.Errorf("%d versions left, want %d", len(versions), 4-len(tt.want))
+			}
+			for _, v := range report.Removed {
+				if _, err := os.Stat(v.packagePath); !os.IsNotExist(err) {
+					t.Errorf("package of %s@%s was not removed", v.Name, v.Version)
+				}
+			}
+		})
+	}
+}
+
+func TestRetentionPolicy_SharedPackage(t *testing.T) {
+	registryDir := t.TempDir()
+	publishVersion(t, registryDir, "nlp", "bert", "1.0.0", 1000, 30*24*time.Hour)
+	publishVersion(t, registryDir, "nlp", "bert", "2.0.0", 1000, time.Hour)
+
+	// 1.0.1 and 1.0.2 republish the package of 1.0.0; 1.0.1 is removed with it, 1.0.2
+	// survives and still needs it
+	for i, version := range []string{"1.0.1", "1.0.2"} {
+		dir := filepath.Join(registryDir, "api/v1/models/nlp/bert", version)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		manifest := "distribution:\n  package:\n    url: http://localhost:8080/packages/nlp-bert-1.0.0.axon\n"
+		if err := os.WriteFile(filepath.Join(dir, "manifest.yaml"), []byte(manifest), 0644); err != nil {
+			t.Fatal(err)
+		}
+		published := time.Now().Add(-time.Duration(20-i) * 24 * time.Hour)
+		if err := os.Chtimes(filepath.Join(dir, "manifest.yaml"), published, published); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	policy := retentionPolicy{KeepLast: 2}
+	if err := policy.parse(); err != nil {
+		t.Fatal(err)
+	}
+	report, err := applyRetention(registryDir, &policy, false, time.Now())
+	if err != nil {
+		t.Fatalf("applyRetention() error = %v", err)
+	}
+	if got, want := removedVersions(report), []string{"nlp/bert@1.0.1", "nlp/bert@1.0.0"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("removed %v, want %v", got, want)
+	}
+	if _, err := os.Stat(filepath.Join(registryDir, "packages/nlp-bert-1.0.0.axon")); err != nil {
+		t.Errorf("package shared with 1.0.2 was removed: %v", err)
+	}
+	if report.ReclaimedBytes != 0 {
+		t.Errorf("ReclaimedBytes = %d, want 0", report.ReclaimedBytes)
+	}
+
+	// Once no version references it, the package goes
+	policy = retentionPolicy{KeepLast: 1}
+	if err := policy.parse(); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = applyRetention(registryDir, &policy, false, time.Now()); err != nil {
+		t.Fatalf("applyRetention() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(registryDir, "packages/nlp-bert-1.0.0.axon")); !os.IsNotExist(err) {
+		t.Errorf("unreferenced package was kept: %v", err)
+	}
+	if report.ReclaimedBytes != 1000 {
+		t.Errorf("ReclaimedBytes = %d, want 1000", report.ReclaimedBytes)
+	}
+}
+
+func TestRetentionHandler(t *testing.T) {
+	registryDir := t.TempDir()
+	publishVersion(t, registryDir, "nlp", "bert", "1.0.0", 10, 48*time.Hour)
+	publishVersion(t, registryDir, "nlp", "bert", "2.0.0", 10, time.Hour)
+
+	policy := &retentionPolicy{KeepLast: 1}
+	index := openTestIndex(t, registryDir)
+	handler := retentionHandler(registryDir, policy, index)
+
+	// Without an admin token only dry runs are allowed
+	t.Setenv("AXON_REGISTRY_ADMIN_TOKEN", "")
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodPost, "/admin/retention", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("POST status without admin token = %d, want 403", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/admin/retention", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET status without admin token = %d, want 200", rec.Code)
+	}
+
+	t.Setenv("AXON_REGISTRY_ADMIN_TOKEN", "secret")
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/admin/retention", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", rec.Code)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/admin/retention", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status with wrong token = %d, want 401", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/retention", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"dry_run":true`) || !strings.Contains(rec.Body.String(), `"version":"1.0.0"`) {
+		t.Errorf("GET response = %d %s, want a dry-run report", rec.Code, rec.Body.String())
+	}
+	if versions, _ := listPackageVersions(registryDir); len(versions) != 2 {
+		t.Fatalf("GET removed versions")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/retention", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	handler(rec, req)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"dry_run":false`) {
+		t.Errorf("POST response = %d %s", rec.Code, rec.Body.String())
+	}
+	if versions, _ := listPackageVersions(registryDir); len(versions) != 1 || versions[0].Version != "2.0.0" {
+		t.Errorf("versions after POST = %+v, want only 2.0.0", versions)
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{"1024": 1024, "500M": 500 << 20, "50GB": 50 << 30, "1.5GiB": 3 << 29, "2500B": 2500}
+	for value, want := range tests {
+		if got, err := parseSize(value); err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	if _, err := parseSize("lots"); err == nil {
+		t.Error("parseSize(lots) should fail")
+	}
+}
+
+func TestParseAllowlist(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{value: "", want: nil},
+		{value: "10.0.0.0/8", want: []string{"10.0.0.0/8"}},
+		{value: "10.1.2.3/8, 192.168.1.5", want: []string{"10.0.0.0/8", "192.168.1.5/32"}},
+		{value: "fd00::/8,::1", want: []string{"fd00::/8", "::1/128"}},
+		{value: "10.0.0.0/33", wantErr: true},
+		{value: "registry.internal", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			allowlist, err := parseAllowlist(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAllowlist(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			var got []string
+			for _, network := range allowlist {
+				got = append(got, network.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseAllowlist(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowlistHandler(t *testing.T) {
+	allowlist, err := parseAllowlist("10.0.0.0/8, ::1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler := allowlistHandler(allowlist, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		remoteAddr   string
+		forwardedFor string
+		wantStatus   int
+	}{
+		{remoteAddr: "10.20.30.40:51234", wantStatus: http.StatusOK},
+		{remoteAddr: "[::1]:51234", wantStatus: http.StatusOK},
+		{remoteAddr: "[::ffff:10.0.0.7]:51234", wantStatus: http.StatusOK},
+		{remoteAddr: "192.168.1.5:51234", wantStatus: http.StatusForbidden},
+		{remoteAddr: "192.168.1.5:51234", forwardedFor: "10.0.0.1", wantStatus: http.StatusForbidden},
+		{remoteAddr: "garbage", wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/index", nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("request from %s (X-Forwarded-For %q) status = %d, want %d", tt.remoteAddr, tt.forwardedFor, rec.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestLoadAccessConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "unrestricted", env: nil},
+		{name: "tls", env: map[string]string{"AXON_REGISTRY_TLS_CERT": "cert.pem", "AXON_REGISTRY_TLS_KEY": "key.pem"}},
+		{name: "cert without key", env: map[string]string{"AXON_REGISTRY_TLS_CERT": "cert.pem"}, wantErr: true},
+		{name: "client CA without tls", env: map[string]string{"AXON_REGISTRY_CLIENT_CA": "ca.pem"}, wantErr: true},
+		{name: "bad allowlist", env: map[string]string{"AXON_REGISTRY_ALLOW": "nope"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"AXON_REGISTRY_ALLOW", "AXON_REGISTRY_TLS_CERT", "AXON_REGISTRY_TLS_KEY", "AXON_REGISTRY_CLIENT_CA"} {
+				t.Setenv(key, tt.env[key])
+			}
+			if _, err := loadAccessConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("loadAccessConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// newTestCA returns a self-signed CA and a client certificate it signed
+func newTestCA(t *testing.T) (caPEM []byte, client tls.Certificate) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "axon test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "axon client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	clientDER, err := x509.CreateCertificate(rand.Reader, clientTemplate, caCert, &clientKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	caPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	client = tls.Certificate{Certificate: [][]byte{clientDER}, PrivateKey: clientKey}
+	return caPEM, client
+}
+
+func TestMutualTLS(t *testing.T) {
+	caPEM, clientCert := newTestCA(t)
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	access := &accessConfig{certFile: "cert.pem", keyFile: "key.pem", clientCAFile: caFile}
+	tlsConfig, err := access.TLSConfig()
+	if err != nil {
+		t.Fatalf("TLSConfig() error = %v", err)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "ok")
+	}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	get := func(certs []tls.Certificate) error {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}}
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		return nil
+	}
+
+	if err := get(nil); err == nil {
+		t.Error("request without a client certificate succeeded")
+	}
+	if err := get([]tls.Certificate{clientCert}); err != nil {
+		t.Errorf("request with a client certificate failed: %v", err)
+	}
+}
+
+func TestTLSConfigInvalidClientCA(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	access := &accessConfig{certFile: "cert.pem", keyFile: "key.pem", clientCAFile: caFile}
+	if _, err := access.TLSConfig(); err == nil {
+		t.Error("TLSConfig() accepted a CA file without certificates")
+	}
+}
+
+func TestPackageUploadHandler(t *testing.T) {
+	registryDir := t.TempDir()
+	content := []byte("package contents")
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+
+	put := func(handler http.HandlerFunc, file, token, checksum string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/packages/"+file, bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		req.Header.Set("X-Checksum-Sha256", checksum)
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	if rec := put(packageUploadHandler(registryDir, &uploadConfig{}), "m.axon", "secret", digest, content); rec.Code != http.StatusForbidden {
+		t.Errorf("upload with uploads disabled: status = %d, want 403", rec.Code)
+	}
+
+	handler := packageUploadHandler(registryDir, &uploadConfig{token: "secret", quota: 1024})
+	tests := []struct {
+		name     string
+		file     string
+		token    string
+		checksum string
+		body     []byte
+		want     int
+	}{
+		{name: "no token", file: "m.axon", checksum: digest, body: content, want: http.StatusUnauthorized},
+		{name: "wrong token", file: "m.axon", token: "guess", checksum: digest, body: content, want: http.StatusUnauthorized},
+		{name: "no checksum", file: "m.axon", token: "secret", body: content, want: http.StatusBadRequest},
+		{name: "checksum mismatch", file: "m.axon", token: "secret", checksum: strings.Repeat("0", 64), body: content, want: http.StatusBadRequest},
+		{name: "over quota", file: "big.axon", token: "secret", checksum: digest, body: bytes.Repeat([]byte("x"), 2048), want: http.StatusRequestEntityTooLarge},
+		{name: "hidden file", file: ".m.axon", token: "secret", checksum: digest, body: content, want: http.StatusBadRequest},
+		{name: "upload", file: "m.axon", token: "secret", checksum: digest, body: content, want: http.StatusCreated},
+		{name: "same content again", file: "m.axon", token: "secret", checksum: digest, body: content, want: http.StatusOK},
+		{name: "different content", file: "m.axon", token: "secret", checksum: hex.EncodeToString(make([]byte, 32)), body: []byte("other"), want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := put(handler, tt.file, tt.token, tt.checksum, tt.body); rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	data, err := os.ReadFile(filepath.Join(registryDir, "packages", "m.axon"))
+	if err != nil || !bytes.Equal(data, content) {
+		t.Errorf("stored package = %q, %v", data, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(registryDir, "packages", "m.axon.sha256")); strings.TrimSpace(string(data)) != digest {
+		t.Errorf("stored checksum = %q, want %s", data, digest)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(registryDir, "packages")); len(entries) != 2 {
+		t.Errorf("packages directory has %d entries, want the package and its checksum only", len(entries))
+	}
+}
+
+func TestManifestUploadHandler(t *testing.T) {
+	registryDir := t.TempDir()
+	upload := &uploadConfig{token: "secret", quota: 100}
+	writePackage := func(name string, size int) string {
+		t.Helper()
+		content := bytes.Repeat([]byte("p"), size)
+		if err := os.MkdirAll(filepath.Join(registryDir, "packages"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(registryDir, "packages", name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(content)
+		return hex.EncodeToString(sum[:])
+	}
+	smallDigest := writePackage("nlp-bert-1.0.0.axon", 60)
+	largeDigest := writePackage("nlp-bert-2.0.0.axon", 60)
+	manifest := func(version, packageName, digest string) string {
+		return fmt.Sprintf("metadata:\n  namespace: nlp\n  name: bert\n  version: %s\ndistribution:\n  package:\n    url: /packages/%s\n    sha256: %s\n", version, packageName, digest)
+	}
+	handler := manifestUploadHandler(registryDir, upload, nil)
+
+	tests := []struct {
+		name string
+		path string
+		body string
+		want int
+	}{
+		{name: "bad path", path: "nlp/bert", body: manifest("1.0.0", "nlp-bert-1.0.0.axon", smallDigest), want: http.StatusBadRequest},
+		{name: "traversal", path: "nlp/../1.0.0", body: manifest("1.0.0", "nlp-bert-1.0.0.axon", smallDigest), want: http.StatusBadRequest},
+		{name: "other model", path: "nlp/gpt2/1.0.0", body: manifest("1.0.0", "nlp-bert-1.0.0.axon", smallDigest), want: http.StatusBadRequest},
+		{name: "package not uploaded", path: "nlp/bert/1.0.0", body: manifest("1.0.0", "missing.axon", smallDigest), want: http.StatusBadRequest},
+		{name: "external package", path: "nlp/bert/1.0.0", body: manifest("1.0.0", "../x", smallDigest), want: http.StatusBadRequest},
+		{name: "checksum mismatch", path: "nlp/bert/1.0.0", body: manifest("1.0.0", "nlp-bert-1.0.0.axon", strings.Repeat("0", 64)), want: http.StatusBadRequest},
+		{name: "attestation", path: "nlp/bert/1.0.0/attestation.json", body: `{"payloadType": "application/vnd.in-toto+json"}`, want: http.StatusCreated},
+		{name: "publish", path: "nlp/bert/1.0.0", body: manifest("1.0.0", "nlp-bert-1.0.0.axon", smallDigest), want: http.StatusCreated},
+		{name: "republish", path: "nlp/bert/1.0.0", body: manifest("1.0.0", "nlp-bert-1.0.0.axon", smallDigest), want: http.StatusConflict},
+		{name: "over quota", path: "nlp/bert/2.0.0", body: manifest("2.0.0", "nlp-bert-2.0.0.axon", largeDigest), want: http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/models/"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer secret")
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+
+	// The published version is served, with its attestation, and listed
+	get := manifestHandler(registryDir)
+	for _, path := range []string{"nlp/bert/1.0.0/manifest.yaml", "nlp/bert/1.0.0/attestation.json"} {
+		rec := httptest.NewRecorder()
+		get(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/"+path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("GET %s: status = %d", path, rec.Code)
+		}
+	}
+	rec := httptest.NewRecorder()
+	get(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/bert/versions.json", nil))
+	if !strings.Contains(rec.Body.String(), `"1.0.0"`) || strings.Contains(rec.Body.String(), `"2.0.0"`) {
+		t.Errorf("versions = %s, want only 1.0.0", rec.Body.String())
+	}
+}
+
+func TestSearchIndex_UpdatedByHandlers(t *testing.T) {
+	registryDir := t.TempDir()
+	upload := &uploadConfig{token: "secret"}
+	index := openTestIndex(t, registryDir)
+	put := manifestUploadHandler(registryDir, upload, index)
+	del := manifestDeleteHandler(registryDir, upload, index)
+	request := func(handler http.HandlerFunc, method, path, body string) int {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1/models/"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	if err := os.MkdirAll(filepath.Join(registryDir, "packages"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(registryDir, "packages/nlp-bert.axon"), []byte("package"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256([]byte("package"))
+	for _, version := range []string{"1.0.0", "2.0.0", "3.0.0"} {
+		manifest := fmt.Sprintf("metadata:\n  namespace: nlp\n  name: bert\n  version: %s\n  description: Bidirectional encoder\ndistribution:\n  package:\n    url: /packages/nlp-bert.axon\n    sha256: %s\n", version, hex.EncodeToString(sum[:]))
+		if code := request(put, http.MethodPut, "nlp/bert/"+version, manifest); code != http.StatusCreated {
+			t.Fatalf("PUT %s: status = %d", version, code)
+		}
+	}
+
+	// Without a refresh, searches see what the handlers changed
+	if results := index.Search("bidirectional"); len(results) != 3 {
+		t.Fatalf("after publishing: %d results, want 3", len(results))
+	}
+	if code := request(del, http.MethodDelete, "nlp/bert/2.0.0", ""); code != http.StatusNoContent {
+		t.Fatalf("DELETE: status = %d, want 204", code)
+	}
+	if code := request(del, http.MethodDelete, "nlp/bert/2.0.0", ""); code != http.StatusNotFound {
+		t.Errorf("second DELETE: status = %d, want 404", code)
+	}
+	if _, err := os.Stat(filepath.Join(registryDir, "packages/nlp-bert.axon")); err != nil {
+		t.Errorf("package shared with other versions was removed: %v", err)
+	}
+	if results := index.Search("bidirectional"); len(results) != 2 {
+		t.Errorf("after deleting: %d results, want 2", len(results))
+	}
+
+	t.Setenv("AXON_REGISTRY_ADMIN_TOKEN", "admin")
+	req := httptest.NewRequest(http.MethodPost, "/admin/retention", nil)
+	req.Header.Set("Authorization", "Bearer admin")
+	rec := httptest.NewRecorder()
+	retentionHandler(registryDir, &retentionPolicy{KeepLast: 1}, index)(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("retention: status = %d (%s)", rec.Code, rec.Body.String())
+	}
+	if results := index.Search("bidirectional"); len(results) != 1 {
+		t.Errorf("after the retention GC: %d results, want 1", len(results))
+	}
+}
+
+// fakeUpstream serves one model, counting fetches
+type fakeUpstream struct {
+	mu      sync.Mutex
+	fetches int
+	release chan struct{} // Fetches wait for it, if set
+}
+
+func (u *fakeUpstream) Fetch(ctx context.Context, namespace, name, version, destPath string) (*types.Manifest, error) {
+	u.mu.Lock()
+	u.fetches++
+	u.mu.Unlock()
+	if u.release != nil {
+		<-u.release
+	}
+	if namespace != "hf" || name != "org/bert" {
+		return nil, fmt.Errorf("model %s/%s not found", namespace, name)
+	}
+	if err := os.WriteFile(destPath, []byte("package"), 0644); err != nil {
+		return nil, err
+	}
+	manifest := &types.Manifest{}
+	manifest.Metadata.Namespace, manifest.Metadata.Name, manifest.Metadata.Version = namespace, name, version
+	manifest.Metadata.Description = "BERT from upstream"
+	manifest.Distribution.Package.URL = "https://upstream.example.com/bert.axon"
+	return manifest, nil
+}
+
+func TestProxy(t *testing.T) {
+	registryDir := t.TempDir()
+	upstream := &fakeUpstream{release: make(chan struct{})}
+	index := openTestIndex(t, registryDir)
+	proxy := newRegistryProxy(registryDir, []string{"hf"}, upstream, &uploadConfig{}, index)
+	handler := withProxy(registryDir, proxy, manifestHandler(registryDir))
+
+	// Concurrent requests for a missing model fetch it once
+	const requests = 3
+	var wg sync.WaitGroup
+	codes := make([]int, requests)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			rec := httptest.NewRecorder()
+			handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/hf/org/bert/1.0.0/manifest.yaml", nil))
+			codes[i] = rec.Code
+		}(i)
+	}
+	for {
+		proxy.mu.Lock()
+		waiting := len(proxy.inflight)
+		proxy.mu.Unlock()
+		if waiting > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(upstream.release)
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d: status = %d, want 200", i, code)
+		}
+	}
+	if upstream.fetches != 1 {
+		t.Errorf("upstream fetches = %d, want 1", upstream.fetches)
+	}
+
+	// The model is cached, with its package served by the registry
+	data, err := os.ReadFile(filepath.Join(registryDir, "api/v1/models/hf/org/bert/1.0.0/manifest.yaml"))
+	if err != nil {
+		t.Fatalf("manifest not cached: %v", err)
+	}
+	var manifest types.Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		t.Fatal(err)
+	}
+	packageName := "hf-org_bert-1.0.0.axon"
+	sum := sha256.Sum256([]byte("package"))
+	if pkg := manifest.Distribution.Package; pkg.URL != "/packages/"+packageName || pkg.SHA256 != hex.EncodeToString(sum[:]) || pkg.Size != int64(len("package")) {
+		t.Errorf("cached package = %+v", pkg)
+	}
+	if _, err := os.Stat(filepath.Join(registryDir, "packages", packageName)); err != nil {
+		t.Errorf("package not cached: %v", err)
+	}
+
+	// The name of several segments is kept whole by the index, the version listing and
+	// the retention GC
+	if results := index.Search("bert"); len(results) != 1 || results[0].Namespace != "hf" || results[0].Name != "org/bert" || results[0].Version != "1.0.0" {
+		t.Errorf("indexed = %+v, want hf/org/bert@1.0.0", results)
+	}
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/hf/org/bert/versions", nil))
+	if body := rec.Body.String(); !strings.Contains(body, `"namespace":"hf","name":"org/bert","versions":["1.0.0"]`) {
+		t.Errorf("versions = %s, want hf/org/bert with 1.0.0", body)
+	}
+	versions, err := listPackageVersions(registryDir)
+	if err != nil || len(versions) != 1 || versions[0].Namespace != "hf" || versions[0].Name != "org/bert" || versions[0].Bytes != int64(len("package")) {
+		t.Errorf("package versions = %+v, %v; want hf/org/bert with its package", versions, err)
+	}
+
+	// Cached models are served without going upstream
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/hf/org/bert/1.0.0/manifest.yaml", nil))
+	if rec.Code != http.StatusOK || upstream.fetches != 1 {
+		t.Errorf("cached request: status = %d, fetches = %d", rec.Code, upstream.fetches)
+	}
+
+	// Upstream failures are reported as a bad gateway; other namespaces aren't proxied
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/hf/missing/1.0.0/manifest.yaml", nil))
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("missing upstream model: status = %d, want 502", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/v1/models/nlp/bert/1.0.0/manifest.yaml", nil))
+	if rec.Code != http.StatusNotFound || upstream.fetches != 2 {
+		t.Errorf("unproxied namespace: status = %d, fetches = %d, want 404 without a fetch", rec.Code, upstream.fetches)
+	}
+}
+
+func TestProxy_TokenAndQuota(t *testing.T) {
+	registryDir := t.TempDir()
+	upstream := &fakeUpstream{}
+	upload := &uploadConfig{token: "secret", quota: int64(len("package")) - 1}
+	proxy := newRegistryProxy(registryDir, []string{"hf"}, upstream, upload, nil)
+	handler := withProxy(registryDir, proxy, manifestHandler(registryDir))
+	get := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/models/hf/org/bert/1.0.0/manifest.yaml", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	// With uploads enabled, only clients with the upload token can fetch from upstream
+	if code := get(""); code != http.StatusUnauthorized || upstream.fetches != 0 {
+		t.Errorf("without a token: status = %d, fetches = %d; want 401 without a fetch", code, upstream.fetches)
+	}
+
+	// A package that would take the namespace over its quota isn't published
+	if code := get("secret"); code != http.StatusInsufficientStorage {
+		t.Errorf("over quota: status = %d, want 507", code)
+	}
+	if versions, _ := listPackageVersions(registryDir); len(versions) != 0 {
+		t.Errorf("over quota: published %+v", versions)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(registryDir, "packages")); len(entries) != 0 {
+		t.Errorf("over quota: %d files left in packages", len(entries))
+	}
+
+	upload.quota = 0
+	if code := get("secret"); code != http.StatusOK {
+		t.Errorf("within quota: status = %d, want 200", code)
+	}
+	// Cached models are served to everyone
+	if code := get(""); code != http.StatusOK || upstream.fetches != 2 {
+		t.Errorf("cached without a token: status = %d, fetches = %d; want 200 from the cache", code, upstream.fetches)
+	}
+}